@@ -0,0 +1,120 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemVer represents a parsed semantic version (https://semver.org) with an optional leading `v`.
+//
+// Build metadata is ignored.
+type SemVer struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// ParseSemVer parses a semantic version like `v2.1.0` or `v2.1.0-beta.1`.
+func ParseSemVer(s string) (*SemVer, error) {
+	match := semVerTag.FindStringSubmatch(s)
+	if match == nil || len(match) != semVerTag.NumSubexp()+1 {
+		return nil, fmt.Errorf("invalid semantic version %q", s)
+	}
+
+	var res SemVer
+
+	for name, p := range map[string]*int{"major": &res.Major, "minor": &res.Minor, "patch": &res.Patch} {
+		v, err := strconv.Atoi(match[semVerTag.SubexpIndex(name)])
+		if err != nil {
+			return nil, fmt.Errorf("invalid semantic version %q: %w", s, err)
+		}
+
+		*p = v
+	}
+
+	res.Prerelease = match[semVerTag.SubexpIndex("prerelease")]
+
+	return &res, nil
+}
+
+// String returns the version with a leading `v`.
+func (v *SemVer) String() string {
+	res := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		res += "-" + v.Prerelease
+	}
+
+	return res
+}
+
+// Compare returns -1, 0, or +1 depending on whether v is less than, equal to, or greater than other
+// according to the semantic versioning precedence rules.
+func (v *SemVer) Compare(other *SemVer) int {
+	if c := cmp.Compare(v.Major, other.Major); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	// a version without pre-release has higher precedence
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+
+	a, b := strings.Split(v.Prerelease, "."), strings.Split(other.Prerelease, ".")
+
+	for i := range min(len(a), len(b)) {
+		if c := comparePrereleaseIdentifiers(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+// comparePrereleaseIdentifiers compares single pre-release identifiers:
+// numeric identifiers are compared numerically and have lower precedence than alphanumeric ones,
+// that are compared lexically.
+func comparePrereleaseIdentifiers(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return cmp.Compare(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
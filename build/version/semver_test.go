@@ -0,0 +1,66 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemVer(t *testing.T) {
+	t.Parallel()
+
+	v, err := ParseSemVer("v2.1.0-beta.2+build.5")
+	require.NoError(t, err)
+	assert.Equal(t, &SemVer{Major: 2, Minor: 1, Patch: 0, Prerelease: "beta.2"}, v)
+	assert.Equal(t, "v2.1.0-beta.2", v.String())
+
+	for _, s := range []string{"", "unknown", "2.1.0", "v2.1", "v02.1.0"} {
+		_, err = ParseSemVer(s)
+		assert.Error(t, err, "%q", s)
+	}
+
+	// in increasing precedence, from https://semver.org/#spec-item-11
+	ordered := []string{
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+		"v1.0.1",
+		"v1.2.0",
+		"v2.0.0",
+	}
+
+	for i := range ordered {
+		a, err := ParseSemVer(ordered[i])
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, a.Compare(a), "%s", a)
+
+		for _, s := range ordered[i+1:] {
+			b, err := ParseSemVer(s)
+			require.NoError(t, err)
+
+			assert.Equal(t, -1, a.Compare(b), "%s < %s", a, b)
+			assert.Equal(t, 1, b.Compare(a), "%s > %s", b, a)
+		}
+	}
+}
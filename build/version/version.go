@@ -79,6 +79,23 @@ type Info struct {
 
 	// MongoDBVersionArray is MongoDBVersion, but as an array.
 	MongoDBVersionArray [4]int32
+
+	// Capabilities contains features supported (or not) by this build,
+	// so clients and tests could use feature detection instead of relying on version numbers.
+	Capabilities map[string]bool
+}
+
+// capabilities returns features supported by this build.
+//
+// Keep it in sync with the compatibility documentation.
+func capabilities() map[string]bool {
+	return map[string]bool{
+		"changeStreams": false,
+		"geo":           true,
+		"textSearch":    true,
+		"transactions":  false,
+		"vectorSearch":  true,
+	}
 }
 
 // info singleton instance set by init().
@@ -109,16 +126,13 @@ func Get() *Info {
 func initFromFiles() {
 	mongodbTxt := strings.TrimSpace(string(must.NotFail(gen.ReadFile("mongodb.txt"))))
 
-	match := semVerTag.FindStringSubmatch(mongodbTxt)
-	if match == nil || len(match) != semVerTag.NumSubexp()+1 {
+	mv, err := ParseSemVer(mongodbTxt)
+	if err != nil {
 		panic("invalid mongodb.txt")
 	}
 
-	major := must.NotFail(strconv.ParseInt(match[semVerTag.SubexpIndex("major")], 10, 32))
-	minor := must.NotFail(strconv.ParseInt(match[semVerTag.SubexpIndex("minor")], 10, 32))
-	patch := must.NotFail(strconv.ParseInt(match[semVerTag.SubexpIndex("patch")], 10, 32))
-	mongoDBVersion := fmt.Sprintf("%d.%d.%d", major, minor, patch)
-	mongoDBVersionArray := [...]int32{int32(major), int32(minor), int32(patch), int32(0)}
+	mongoDBVersion := fmt.Sprintf("%d.%d.%d", mv.Major, mv.Minor, mv.Patch)
+	mongoDBVersionArray := [...]int32{int32(mv.Major), int32(mv.Minor), int32(mv.Patch), int32(0)}
 
 	info = &Info{
 		Version:  unknown,
@@ -132,6 +146,7 @@ func initFromFiles() {
 		},
 		MongoDBVersion:      mongoDBVersion,
 		MongoDBVersionArray: mongoDBVersionArray,
+		Capabilities:        capabilities(),
 	}
 
 	// Those files are present in two cases:
@@ -32,6 +32,9 @@ func TestCase1(t *testing.T) {
 	assert.Equal(t, "7.0.77", info.MongoDBVersion)
 	assert.Equal(t, [...]int32{int32(7), int32(0), int32(77), int32(0)}, info.MongoDBVersionArray)
 
+	assert.False(t, info.Capabilities["transactions"])
+	assert.True(t, info.Capabilities["textSearch"])
+
 	assert.Equal(t, runtime.Version(), info.BuildEnvironment["go.version"])
 	assert.Equal(t, runtime.Version(), info.BuildEnvironment["go.runtime"])
 	assert.Empty(t, info.BuildEnvironment["vcs.revision"]) // not set for unit tests
@@ -0,0 +1,135 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/alecthomas/kong"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// redactedValue replaces secrets in the `getCmdLineOpts` output.
+const redactedValue = "<redacted>"
+
+// cmdLineOpts returns the effective configuration (including defaults and environment variables)
+// of the parsed command line for the `getCmdLineOpts` command, with secrets redacted.
+// Hidden flags are not included.
+func cmdLineOpts(ctx *kong.Context) *wirebson.Document {
+	res := wirebson.MakeDocument(0)
+
+	for _, f := range ctx.Flags() {
+		if f.Hidden || f.Name == "help" {
+			continue
+		}
+
+		must.NoError(res.Add(f.Name, cmdLineValue(ctx.FlagValue(f))))
+	}
+
+	return res
+}
+
+// cmdLineValue converts the given flag value to BSON, redacting secrets.
+func cmdLineValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return redactSecret(v)
+	case bool:
+		return v
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case time.Duration:
+		return v.String()
+	case []byte:
+		// contents of files with URLs and keys
+		if len(v) == 0 {
+			return ""
+		}
+
+		return redactedValue
+
+	case []string:
+		arr := wirebson.MakeArray(len(v))
+		for _, s := range v {
+			must.NoError(arr.Add(redactSecret(s)))
+		}
+
+		return arr
+
+	case map[string]string:
+		doc := wirebson.MakeDocument(len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			must.NoError(doc.Add(k, redactSecret(v[k])))
+		}
+
+		return doc
+
+	case fmt.Stringer:
+		return v.String()
+
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// redactSecret returns the given value with the password redacted if it is a URL with a password.
+// Other values are returned as is.
+func redactSecret(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+
+	if q := u.Query(); q.Has("password") {
+		q.Set("password", "xxxxx")
+		u.RawQuery = q.Encode()
+		s = u.String()
+	}
+
+	if u.User == nil {
+		return s
+	}
+
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+
+	return u.Redacted()
+}
+
+// redactArgs returns command-line arguments with secrets redacted.
+func redactArgs(args []string) []string {
+	res := make([]string, len(args))
+
+	for i, arg := range args {
+		if name, value, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(name, "-") {
+			res[i] = name + "=" + redactSecret(value)
+			continue
+		}
+
+		res[i] = redactSecret(arg)
+	}
+
+	return res
+}
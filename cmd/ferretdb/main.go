@@ -15,11 +15,13 @@
 package main
 
 import (
+	"bytes"
 	"cmp"
 	"context"
 	"encoding/json"
 	"expvar"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"math"
@@ -31,6 +33,7 @@
 	"sync/atomic"
 	"time"
 
+	"github.com/FerretDB/wire/wirebson"
 	"github.com/alecthomas/kong"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/expfmt"
@@ -38,6 +41,7 @@
 	_ "golang.org/x/crypto/x509roots/fallback" // register root TLS certificates for production Docker image
 
 	"github.com/FerretDB/FerretDB/v2/build/version"
+	"github.com/FerretDB/FerretDB/v2/internal/backup"
 	"github.com/FerretDB/FerretDB/v2/internal/clientconn"
 	"github.com/FerretDB/FerretDB/v2/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/v2/internal/dataapi"
@@ -52,6 +56,7 @@
 	"github.com/FerretDB/FerretDB/v2/internal/util/observability"
 	"github.com/FerretDB/FerretDB/v2/internal/util/state"
 	"github.com/FerretDB/FerretDB/v2/internal/util/telemetry"
+	"github.com/FerretDB/FerretDB/v2/internal/util/update"
 )
 
 // The cli struct represents all command-line commands, fields and flags.
@@ -65,6 +70,74 @@
 	Run  struct{} `cmd:"" default:"1"                             hidden:""`
 	Ping struct{} `cmd:"" help:"Ping existing FerretDB instance."`
 
+	VerifyCompat struct {
+		RecordsDir  string `arg:""              help:"Directory with recorded traffic."                  type:"existingdir"`
+		FerretDBURL string `name:"ferretdb-url" default:"mongodb://127.0.0.1:27017/"                      help:"FerretDB URI to replay traffic against." env:"-"`
+		MongoDBURL  string `name:"mongodb-url"  required:""                                              help:"MongoDB URI to replay traffic against."  env:"-"`
+		Report      string `default:"-"         help:"Path to the JSON report file ('-' for stdout)."    env:"-"`
+	} `cmd:"" help:"Replay recorded traffic against FerretDB and MongoDB and report divergences."`
+
+	Replay struct {
+		RecordsDir string `arg:""            help:"Directory with recorded traffic."                  type:"existingdir"`
+		TargetURL  string `name:"target-url" default:"mongodb://127.0.0.1:27017/"                      help:"MongoDB URI to replay traffic against." env:"-"`
+		Report     string `default:"-"       help:"Path to the JSON report file ('-' for stdout)."    env:"-"`
+	} `cmd:"" help:"Replay recorded traffic against FerretDB or MongoDB and report timings."`
+
+	Backup struct {
+		Out        string `default:"dump" help:"Output directory."                                      env:"-"`
+		DB         string `name:"db"      help:"Database to back up; all databases if not set."          env:"-"`
+		Collection string `               help:"Collection to back up; all collections if not set."      env:"-"`
+		Gzip       bool   `               help:"Compress output files with gzip."                        env:"-"`
+	} `cmd:"" help:"Back up data to a directory in mongodump format."`
+
+	Restore struct {
+		Dir  string `arg:""    help:"Directory with data in mongodump format."                 type:"existingdir"`
+		DB   string `name:"db" help:"Database to restore; all databases if not set."           env:"-"`
+		Drop bool   `          help:"Drop each collection before restoring it."                env:"-"`
+	} `cmd:"" help:"Restore data from a directory in mongodump format."`
+
+	Export struct {
+		DB         string   `name:"db"   required:""                                             help:"Database name."                                      env:"-"`
+		Collection string   `           required:""                                             help:"Collection name."                                    env:"-"`
+		Type       string   `           default:"json"                                          help:"Output format: 'json' or 'csv'."                     env:"-" enum:"json,csv"`
+		Fields     []string `           help:"Comma-separated CSV columns as dotted paths."                                                                env:"-"`
+		Relaxed    bool     `           help:"Use relaxed Extended JSON instead of canonical."                                                             env:"-"`
+		Out        string   `           default:"-"                                             help:"Output file ('-' for stdout)."                       env:"-"`
+	} `cmd:"" help:"Export a collection to Extended JSON or CSV."`
+
+	Import struct {
+		DB         string            `name:"db" required:""                                          help:"Database name."                          env:"-"`
+		Collection string            `          required:""                                          help:"Collection name."                        env:"-"`
+		Type       string            `          default:"json"                                       help:"Input format: 'json' or 'csv'."          env:"-" enum:"json,csv"`
+		Fields     []string          `          help:"Comma-separated CSV columns; the header row is used if not set."                        env:"-"`
+		Types      map[string]string `          help:"CSV column types (e.g. 'age=int32;born=date'); 'auto' by default."                      env:"-"`
+		Drop       bool              `          help:"Drop the collection before import."                                                     env:"-"`
+		File       string            `          default:"-"                                          help:"Input file ('-' for stdin)."             env:"-"`
+	} `cmd:"" help:"Import a collection from Extended JSON or CSV."`
+
+	Migrate struct {
+		SourceURL  string `arg:""    help:"Source MongoDB URI; it should be a replica set."                        env:"-"`
+		DB         string `name:"db" help:"Database to migrate; all databases if not set."                         env:"-"`
+		Checkpoint string `          help:"Checkpoint file used to resume the migration."   default:"migrate.json" env:"-"`
+	} `cmd:"" help:"Copy data from MongoDB and keep it in sync until stopped."`
+
+	ExportToMongo struct {
+		TargetURL string `arg:""    help:"Target MongoDB URI."`
+		DB        string `name:"db" help:"Database to export; all databases if not set."                   env:"-"`
+		Drop      bool   `          help:"Drop each target collection before exporting it."                env:"-"`
+		Users     bool   `          help:"Export users (with random passwords)."            default:"true" env:"-" negatable:""`
+	} `cmd:"" name:"export-to-mongo" help:"Copy data and users to MongoDB."`
+
+	RotateKeys struct {
+		KeyName       string `required:"" help:"Name of the new pg_tde principal key."                        env:"-"`
+		KeyProvider   string `required:"" help:"Name of the configured pg_tde key provider."                  env:"-"`
+		EncryptTables bool   `            help:"Also encrypt collections that are not encrypted at rest yet." env:"-"`
+	} `cmd:"" name:"rotate-keys" help:"Rotate the encryption at rest key of PostgreSQL data."`
+
+	TelemetryCmd struct {
+		Show struct{} `cmd:"" help:"Print the telemetry report that would be sent next."`
+	} `cmd:"" name:"telemetry" help:"Inspect telemetry reports."`
+
 	Version bool `default:"false" help:"Print version to stdout and exit." env:"-"`
 
 	PostgreSQLURL     string `name:"postgresql-url"      default:"postgres://127.0.0.1:5432/postgres"                                                                   help:"PostgreSQL URL." group:"PostgreSQL"`
@@ -87,32 +160,57 @@
 		TLSCaFile   string `default:"" help:"Proxy TLS CA file path."`
 	} `embed:"" prefix:"proxy-" group:"Interfaces"`
 
+	Shadow struct {
+		Addr        string `default:""       help:"Address of the service to mirror commands to (disabled if empty)."`
+		TLSCertFile string `default:""       help:"Shadow TLS cert file path."`
+		TLSKeyFile  string `default:""       help:"Shadow TLS key file path."`
+		TLSCaFile   string `default:""       help:"Shadow TLS CA file path."`
+		Commands    string `default:"writes" help:"Commands to mirror: 'writes' or 'all'."                            enum:"writes,all"`
+	} `embed:"" prefix:"shadow-" group:"Interfaces"`
+
 	DebugAddr string `default:"127.0.0.1:8088" help:"Listen address for HTTP handlers for metrics, pprof, etc." group:"Interfaces"`
 
 	Mode     string `default:"${default_mode}" help:"${help_mode}"                           enum:"${enum_mode}"   group:"Miscellaneous"`
 	StateDir string `default:"."               help:"Process state directory."               group:"Miscellaneous"`
 	Auth     bool   `default:"true"            help:"Enable authentication (on by default)." group:"Miscellaneous" negatable:""`
 
+	KeyFile []byte `help:"Path to a file with the shared secret of instances using the same PostgreSQL (coordination is disabled if empty)." group:"Miscellaneous" type:"filecontent"`
+
 	Log struct {
 		Level  string `default:"${default_log_level}" help:"${help_log_level}"`
 		Format string `default:"console"              help:"${help_log_format}"                     enum:"${enum_log_format}"`
 		UUID   bool   `default:"false"                help:"Add instance UUID to all log messages." negatable:""`
+
+		File           string        `default:""      help:"Log file path (logs are written to stderr if empty)."`
+		FileMaxSize    int64         `default:"0"     help:"Maximum log file size in megabytes before rotation (0 disables)."`
+		FileMaxAge     time.Duration `default:"0s"    help:"Maximum age of rotated log files (0 keeps them regardless of age)."`
+		FileMaxBackups int           `default:"0"     help:"Maximum number of rotated log files to keep (0 keeps all)."`
+		FileCompress   bool          `default:"false" help:"Compress rotated log files with gzip."                               negatable:""`
+
+		SyslogURL string `default:""      help:"Syslog server URL (e.g. 'udp://host:514', 'tcp://host:514', 'unix:///dev/log')."`
+		Journald  bool   `default:"false" help:"Write logs to journald."                                                    negatable:""`
 	} `embed:"" prefix:"log-" group:"Miscellaneous"`
 
 	MetricsUUID bool `default:"false" help:"Add instance UUID to all metrics." group:"Miscellaneous" negatable:""`
 
+	RecordDir string `default:"" help:"Directory to record client traffic to (disabled if empty)." group:"Miscellaneous"`
+
+	SetParameter map[string]string `help:"Server parameters to set at startup (e.g. 'logLevel=1;cursorTimeoutMillis=60000')." group:"Miscellaneous"`
+
 	OTel struct {
 		Traces struct {
 			URL string `default:"" help:"OpenTelemetry OTLP/HTTP traces endpoint URL (e.g. 'http://host:4318/v1/traces')."`
 		} `embed:"" prefix:"traces-"`
 	} `embed:"" prefix:"otel-" group:"Miscellaneous"`
 
-	Telemetry telemetry.Flag `default:"undecided" help:"${help_telemetry}" group:"Miscellaneous"`
+	Telemetry        telemetry.Flag `default:"undecided" help:"${help_telemetry}"                                         group:"Miscellaneous"`
+	TelemetryExclude []string       `help:"Comma-separated telemetry report fields to exclude (e.g. 'command_metrics')." group:"Miscellaneous"`
+
+	UpdateCheck bool `default:"false" help:"Periodically check for new FerretDB releases." group:"Miscellaneous" negatable:""`
 
 	Dev struct {
 		Version     bool   `hidden:""`
 		ReplSetName string `hidden:""`
-		RecordsDir  string `hidden:""`
 
 		Telemetry struct {
 			URL            string        `default:"https://beacon.ferretdb.com/" hidden:""`
@@ -120,9 +218,24 @@
 			ReportInterval time.Duration `default:"24h"                          hidden:""`
 			Package        string        `default:""                             hidden:""`
 		} `embed:"" prefix:"telemetry-"`
+
+		UpdateCheck struct {
+			URL      string        `default:"https://api.github.com/repos/FerretDB/FerretDB/releases/latest" hidden:""`
+			Interval time.Duration `default:"24h"                                                            hidden:""`
+		} `embed:"" prefix:"update-check-"`
 	} `embed:"" prefix:"dev-"`
 }
 
+// logLevel is the current log level that could be changed at runtime with `setParameter` command.
+var logLevel slog.LevelVar
+
+// logFile is the log file that could be rotated at runtime with `logRotate` command.
+// It is nil if logs are not written to a file.
+var logFile *logging.RotatingFile
+
+// logOut is the log output opened by [openLogOutput].
+var logOut io.Writer
+
 // Additional variables for [kong.Parse].
 var (
 	logLevels = []string{
@@ -156,7 +269,7 @@ func main() {
 
 	switch ctx.Command() {
 	case "run":
-		run()
+		run(cmdLineOpts(ctx))
 
 	case "ping":
 		logger := setupDefaultLogger(cli.Log.Format, "")
@@ -173,11 +286,319 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "verify-compat <records-dir>":
+		logger := setupDefaultLogger(cli.Log.Format, "")
+
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		w := os.Stdout
+
+		if r := cli.VerifyCompat.Report; r != "-" {
+			f, err := os.Create(r)
+			if err != nil {
+				logger.LogAttrs(ctx, logging.LevelFatal, "Failed to create report file", logging.Error(err))
+			}
+
+			defer f.Close() //nolint:errcheck // file is synced below
+
+			w = f
+		}
+
+		ok, err := verifyCompat(
+			ctx, cli.VerifyCompat.RecordsDir, cli.VerifyCompat.FerretDBURL, cli.VerifyCompat.MongoDBURL, w, logger,
+		)
+		if err == nil && w != os.Stdout {
+			err = w.Sync()
+		}
+
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to verify compatibility", logging.Error(err))
+		}
+
+		if !ok {
+			stop()
+			os.Exit(1)
+		}
+
+	case "replay <records-dir>":
+		logger := setupDefaultLogger(cli.Log.Format, "")
+
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		w := os.Stdout
+
+		if r := cli.Replay.Report; r != "-" {
+			f, err := os.Create(r)
+			if err != nil {
+				logger.LogAttrs(ctx, logging.LevelFatal, "Failed to create report file", logging.Error(err))
+			}
+
+			defer f.Close() //nolint:errcheck // file is synced below
+
+			w = f
+		}
+
+		err := replay(ctx, cli.Replay.RecordsDir, cli.Replay.TargetURL, w, logger)
+		if err == nil && w != os.Stdout {
+			err = w.Sync()
+		}
+
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to replay", logging.Error(err))
+		}
+
+	case "backup":
+		logger := setupDefaultLogger(cli.Log.Format, "")
+
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		p := backupPool(ctx, logger)
+		defer p.Close()
+
+		err := backup.Dump(ctx, &backup.DumpOpts{
+			Pool:       p,
+			L:          logger,
+			Dir:        cli.Backup.Out,
+			DB:         cli.Backup.DB,
+			Collection: cli.Backup.Collection,
+			Gzip:       cli.Backup.Gzip,
+		})
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to back up", logging.Error(err))
+		}
+
+	case "restore <dir>":
+		logger := setupDefaultLogger(cli.Log.Format, "")
+
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		p := backupPool(ctx, logger)
+		defer p.Close()
+
+		err := backup.Restore(ctx, &backup.RestoreOpts{
+			Pool: p,
+			L:    logger,
+			Dir:  cli.Restore.Dir,
+			DB:   cli.Restore.DB,
+			Drop: cli.Restore.Drop,
+		})
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to restore", logging.Error(err))
+		}
+
+	case "export":
+		logger := setupDefaultLogger(cli.Log.Format, "")
+
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		p := backupPool(ctx, logger)
+		defer p.Close()
+
+		w := os.Stdout
+
+		if out := cli.Export.Out; out != "-" {
+			f, err := os.Create(out)
+			if err != nil {
+				logger.LogAttrs(ctx, logging.LevelFatal, "Failed to create output file", logging.Error(err))
+			}
+
+			defer f.Close() //nolint:errcheck // file is synced below
+
+			w = f
+		}
+
+		n, err := backup.Export(ctx, w, &backup.ExportOpts{
+			Pool:       p,
+			L:          logger,
+			DB:         cli.Export.DB,
+			Collection: cli.Export.Collection,
+			Format:     cli.Export.Type,
+			Fields:     cli.Export.Fields,
+			Relaxed:    cli.Export.Relaxed,
+		})
+		if err == nil && w != os.Stdout {
+			err = w.Sync()
+		}
+
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to export", logging.Error(err))
+		}
+
+		logger.InfoContext(ctx, "Exported", slog.Int("documents", n))
+
+	case "import":
+		logger := setupDefaultLogger(cli.Log.Format, "")
+
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		p := backupPool(ctx, logger)
+		defer p.Close()
+
+		r := os.Stdin
+
+		if file := cli.Import.File; file != "-" {
+			f, err := os.Open(file)
+			if err != nil {
+				logger.LogAttrs(ctx, logging.LevelFatal, "Failed to open input file", logging.Error(err))
+			}
+
+			defer f.Close() //nolint:errcheck // we are only reading
+
+			r = f
+		}
+
+		inserted, failed, err := backup.Import(ctx, r, &backup.ImportOpts{
+			Pool:       p,
+			L:          logger,
+			DB:         cli.Import.DB,
+			Collection: cli.Import.Collection,
+			Format:     cli.Import.Type,
+			Fields:     cli.Import.Fields,
+			Types:      cli.Import.Types,
+			Drop:       cli.Import.Drop,
+		})
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to import", logging.Error(err))
+		}
+
+		logger.InfoContext(ctx, "Imported", slog.Int("documents", inserted), slog.Int("failed", failed))
+
+	case "migrate <source-url>":
+		logger := setupDefaultLogger(cli.Log.Format, "")
+
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		p := backupPool(ctx, logger)
+		defer p.Close()
+
+		err := backup.Migrate(ctx, &backup.MigrateOpts{
+			Pool:       p,
+			L:          logger,
+			SourceURL:  cli.Migrate.SourceURL,
+			DB:         cli.Migrate.DB,
+			Checkpoint: cli.Migrate.Checkpoint,
+		})
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to migrate", logging.Error(err))
+		}
+
+	case "export-to-mongo <target-url>":
+		logger := setupDefaultLogger(cli.Log.Format, "")
+
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		p := backupPool(ctx, logger)
+		defer p.Close()
+
+		err := backup.ExportToMongo(ctx, &backup.ExportToMongoOpts{
+			Pool:      p,
+			L:         logger,
+			TargetURL: cli.ExportToMongo.TargetURL,
+			DB:        cli.ExportToMongo.DB,
+			Drop:      cli.ExportToMongo.Drop,
+			Users:     cli.ExportToMongo.Users,
+		})
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to export to MongoDB", logging.Error(err))
+		}
+
+	case "rotate-keys":
+		logger := setupDefaultLogger(cli.Log.Format, "")
+
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		p := backupPool(ctx, logger)
+		defer p.Close()
+
+		err := rotateKeys(ctx, p, cli.RotateKeys.KeyName, cli.RotateKeys.KeyProvider, cli.RotateKeys.EncryptTables, logger)
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to rotate keys", logging.Error(err))
+		}
+
+	case "telemetry show":
+		logger := setupDefaultLogger(cli.Log.Format, "")
+
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		stateProvider, err := state.NewProviderDir(cli.StateDir)
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to set up state provider", logging.Error(err))
+		}
+
+		// command metrics are collected only by the running instance;
+		// use `ferretTelemetry` command to see them
+		tr := newReporter(ctx, logger, stateProvider, connmetrics.NewListenerMetrics().ConnMetrics)
+
+		b, err := tr.Payload()
+		if err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to make telemetry report", logging.Error(err))
+		}
+
+		logger.InfoContext(ctx, "Telemetry state", slog.String("state", stateProvider.Get().TelemetryString()))
+
+		if _, err = os.Stdout.Write(append(b, '\n')); err != nil {
+			logger.LogAttrs(ctx, logging.LevelFatal, "Failed to print telemetry report", logging.Error(err))
+		}
+
 	default:
 		panic("unknown sub-command")
 	}
 }
 
+// newReporter creates a new telemetry reporter.
+func newReporter(ctx context.Context, logger *slog.Logger, sp *state.Provider, cm *connmetrics.ConnMetrics) *telemetry.Reporter {
+	l := logging.WithName(logger, "telemetry")
+
+	tr, err := telemetry.NewReporter(&telemetry.NewReporterOpts{
+		URL:            cli.Dev.Telemetry.URL,
+		Dir:            cli.StateDir,
+		F:              &cli.Telemetry,
+		DNT:            os.Getenv("DO_NOT_TRACK"),
+		ExecName:       os.Args[0],
+		P:              sp,
+		ConnMetrics:    cm,
+		L:              l,
+		UndecidedDelay: cli.Dev.Telemetry.UndecidedDelay,
+		ReportInterval: cli.Dev.Telemetry.ReportInterval,
+		Exclude:        cli.TelemetryExclude,
+	})
+	if err != nil {
+		l.LogAttrs(ctx, logging.LevelFatal, "Failed to create telemetry reporter", logging.Error(err))
+	}
+
+	return tr
+}
+
+// backupPool returns a new pool for backup and restore sub-commands.
+func backupPool(ctx context.Context, logger *slog.Logger) *documentdb.Pool {
+	if len(cli.PostgreSQLURLFile) > 0 {
+		cli.PostgreSQLURL = strings.TrimSpace(string(cli.PostgreSQLURLFile))
+	}
+
+	// do not touch the state file of the running instance
+	sp, err := state.NewProvider("")
+	if err != nil {
+		logger.LogAttrs(ctx, logging.LevelFatal, "Failed to set up state provider", logging.Error(err))
+	}
+
+	p, err := documentdb.NewPool(cli.PostgreSQLURL, logging.WithName(logger, "pool"), sp)
+	if err != nil {
+		logger.LogAttrs(ctx, logging.LevelFatal, "Failed to construct pool", logging.Error(err))
+	}
+
+	return p
+}
+
 // defaultLogLevel returns the default log level.
 func defaultLogLevel() slog.Level {
 	if devbuild.Enabled {
@@ -237,16 +658,78 @@ func setupDefaultLogger(format string, uuid string) *slog.Logger {
 		log.Fatal(err)
 	}
 
+	logLevel.Set(level)
+
+	out, base := openLogOutput()
+
 	opts := &logging.NewHandlerOpts{
-		Base:       format,
-		Level:      level,
+		Base:       cmp.Or(base, format),
+		Level:      &logLevel,
 		SkipChecks: !devbuild.Enabled,
 	}
-	logging.SetupDefault(opts, uuid)
+	logging.SetupDefaultOut(out, opts, uuid)
 
 	return slog.Default()
 }
 
+// openLogOutput opens the log output selected by flags, if it was not opened yet.
+// It also returns the base handler name required by that output, if any.
+func openLogOutput() (io.Writer, string) {
+	var targets int
+
+	for _, set := range []bool{cli.Log.File != "", cli.Log.SyslogURL != "", cli.Log.Journald} {
+		if set {
+			targets++
+		}
+	}
+
+	if targets > 1 {
+		log.Fatal("Only one of --log-file, --log-syslog-url, and --log-journald flags can be set")
+	}
+
+	var base string
+
+	switch {
+	case cli.Log.SyslogURL != "":
+		base = "syslog"
+	case cli.Log.Journald:
+		base = "journald"
+	}
+
+	if logOut != nil {
+		return logOut, base
+	}
+
+	var err error
+
+	switch {
+	case cli.Log.File != "":
+		logFile, err = logging.OpenRotatingFile(&logging.RotatingFileOpts{
+			Path:       cli.Log.File,
+			MaxSize:    cli.Log.FileMaxSize * 1024 * 1024,
+			MaxAge:     cli.Log.FileMaxAge,
+			MaxBackups: cli.Log.FileMaxBackups,
+			Compress:   cli.Log.FileCompress,
+		})
+		logOut = logFile
+
+	case cli.Log.SyslogURL != "":
+		logOut, err = logging.DialSyslog(cli.Log.SyslogURL)
+
+	case cli.Log.Journald:
+		logOut, err = logging.DialJournald()
+
+	default:
+		logOut = os.Stderr
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return logOut, base
+}
+
 // checkFlags checks that CLI flags are not self-contradictory and produces warnings if needed.
 func checkFlags(logger *slog.Logger) {
 	ctx := context.Background()
@@ -274,7 +757,8 @@ func dumpMetrics() {
 }
 
 // run sets up environment based on provided flags and runs FerretDB.
-func run() {
+// The given effective configuration is returned by the `getCmdLineOpts` command.
+func run(parsedOpts *wirebson.Document) {
 	// to increase a chance of resource finalizers to spot problems
 	if devbuild.Enabled {
 		defer func() {
@@ -437,31 +921,35 @@ func run() {
 
 	lm := connmetrics.NewListenerMetrics()
 
-	{
+	tr := newReporter(ctx, logger, stateProvider, lm.ConnMetrics)
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		tr.Run(ctx)
+	}()
+
+	if cli.UpdateCheck {
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
 
-			l := logging.WithName(logger, "telemetry")
-
-			tr, e := telemetry.NewReporter(&telemetry.NewReporterOpts{
-				URL:            cli.Dev.Telemetry.URL,
-				Dir:            cli.StateDir,
-				F:              &cli.Telemetry,
-				DNT:            os.Getenv("DO_NOT_TRACK"),
-				ExecName:       os.Args[0],
-				P:              stateProvider,
-				ConnMetrics:    lm.ConnMetrics,
-				L:              l,
-				UndecidedDelay: cli.Dev.Telemetry.UndecidedDelay,
-				ReportInterval: cli.Dev.Telemetry.ReportInterval,
+			l := logging.WithName(logger, "update")
+
+			c, e := update.NewChecker(&update.NewCheckerOpts{
+				URL:      cli.Dev.UpdateCheck.URL,
+				P:        stateProvider,
+				L:        l,
+				Interval: cli.Dev.UpdateCheck.Interval,
 			})
 			if e != nil {
-				l.LogAttrs(ctx, logging.LevelFatal, "Failed to create telemetry reporter", logging.Error(e))
+				l.LogAttrs(ctx, logging.LevelFatal, "Failed to create update checker", logging.Error(e))
 			}
 
-			tr.Run(ctx)
+			c.Run(ctx)
 		}()
 	}
 
@@ -470,6 +958,14 @@ func run() {
 		logger.LogAttrs(ctx, logging.LevelFatal, "Failed to construct pool", logging.Error(err))
 	}
 
+	// report DocumentDB installation problems early;
+	// PostgreSQL may be not available yet, so that's not fatal
+	go func() {
+		if e := p.Ping(ctx); e != nil {
+			logger.WarnContext(ctx, "Failed to connect to PostgreSQL", logging.Error(e))
+		}
+	}()
+
 	tcpAddr := cli.Listen.Addr
 	if cmp.Or(tcpAddr, "-") == "-" {
 		tcpAddr = ""
@@ -485,6 +981,11 @@ func run() {
 		tlsAddr = ""
 	}
 
+	clusterKey := bytes.TrimSpace(cli.KeyFile)
+	if len(clusterKey) > 0 && len(clusterKey) < 6 {
+		logger.LogAttrs(ctx, logging.LevelFatal, "Key file should contain at least 6 characters")
+	}
+
 	handlerOpts := &handler.NewOpts{
 		Pool: p,
 		Auth: cli.Auth,
@@ -495,6 +996,21 @@ func run() {
 		L:             logging.WithName(logger, "handler"),
 		ConnMetrics:   lm.ConnMetrics,
 		StateProvider: stateProvider,
+
+		LogLevel:   &logLevel,
+		Parameters: cli.SetParameter,
+		Shutdown:   stop,
+
+		CmdLineArgv:   redactArgs(os.Args),
+		CmdLineParsed: parsedOpts,
+
+		TelemetryPayload: tr.Payload,
+
+		ClusterKey: clusterKey,
+	}
+
+	if logFile != nil {
+		handlerOpts.LogRotate = logFile.Rotate
 	}
 
 	h, err := handler.New(handlerOpts)
@@ -522,7 +1038,13 @@ func run() {
 		ProxyTLSKeyFile:  cli.Proxy.TLSKeyFile,
 		ProxyTLSCAFile:   cli.Proxy.TLSCaFile,
 
-		TestRecordsDir: cli.Dev.RecordsDir,
+		ShadowAddr:        cli.Shadow.Addr,
+		ShadowTLSCertFile: cli.Shadow.TLSCertFile,
+		ShadowTLSKeyFile:  cli.Shadow.TLSKeyFile,
+		ShadowTLSCAFile:   cli.Shadow.TLSCaFile,
+		ShadowAll:         cli.Shadow.Commands == "all",
+
+		TestRecordsDir: cli.RecordDir,
 	})
 	if err != nil {
 		p.Close()
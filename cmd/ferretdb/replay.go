@@ -0,0 +1,256 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/FerretDB/wire"
+	"github.com/FerretDB/wire/wireclient"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+)
+
+// replayReport represents a machine-readable replay report.
+type replayReport struct {
+	TargetURL string `json:"targetUrl"`
+
+	Files     int     `json:"files"`
+	Total     int     `json:"total"`
+	Succeeded int     `json:"succeeded"`
+	Failed    int     `json:"failed"`
+	Skipped   int     `json:"skipped"`
+	Errors    int     `json:"errors"`
+	Duration  float64 `json:"durationMs"`
+
+	Commands map[string]*replayCommandStats `json:"commands"`
+}
+
+// replayCommandStats represents replay statistics of a single command.
+type replayCommandStats struct {
+	Count     int     `json:"count"`
+	Failed    int     `json:"failed"`
+	Errors    int     `json:"errors"`
+	TotalTime float64 `json:"totalMs"`
+	P50       float64 `json:"p50Ms"`
+	P95       float64 `json:"p95Ms"`
+	Max       float64 `json:"maxMs"`
+
+	durations []time.Duration
+}
+
+// finish computes percentiles from collected durations.
+func (s *replayCommandStats) finish() {
+	if len(s.durations) == 0 {
+		return
+	}
+
+	slices.Sort(s.durations)
+
+	percentile := func(p int) float64 {
+		return milliseconds(s.durations[(len(s.durations)-1)*p/100])
+	}
+
+	s.P50 = percentile(50)
+	s.P95 = percentile(95)
+	s.Max = percentile(100)
+}
+
+// milliseconds returns the duration in milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// recordFiles returns sorted paths of all record files in the given directory.
+func recordFiles(dir string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if filepath.Ext(entry.Name()) == ".bin" {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	slices.Sort(files)
+
+	return files, nil
+}
+
+// replay re-executes recorded traffic from the given directory against the target
+// and writes the report with per-command timings to w.
+//
+// Each recorded file is replayed sequentially over a separate connection.
+// Responses are logged on debug level to help reproducing bugs.
+func replay(ctx context.Context, dir, targetURL string, w io.Writer, l *slog.Logger) error {
+	files, err := recordFiles(dir)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	report := &replayReport{
+		TargetURL: redactURL(targetURL),
+		Files:     len(files),
+		Commands:  map[string]*replayCommandStats{},
+	}
+
+	start := time.Now()
+
+	for _, file := range files {
+		l.InfoContext(ctx, "Replaying", slog.String("file", file))
+
+		if err = replayFile(ctx, file, targetURL, report, l); err != nil {
+			return lazyerrors.Errorf("%s: %w", file, err)
+		}
+	}
+
+	report.Duration = milliseconds(time.Since(start))
+
+	for _, s := range report.Commands {
+		s.finish()
+	}
+
+	l.InfoContext(
+		ctx, "Replay finished",
+		slog.Int("total", report.Total), slog.Int("succeeded", report.Succeeded),
+		slog.Int("failed", report.Failed), slog.Int("skipped", report.Skipped),
+		slog.Int("errors", report.Errors), slog.Float64("duration_ms", report.Duration),
+	)
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	if err = e.Encode(report); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// replayFile replays a single recorded file and adds results to the report.
+func replayFile(ctx context.Context, file, targetURL string, report *replayReport, l *slog.Logger) error {
+	records, err := wire.LoadRecords(file, 0)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	conn, err := verifyConnect(ctx, targetURL, l)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer conn.Close() //nolint:errcheck // we are only reading responses
+
+	for _, rec := range records {
+		if rec.Header == nil || rec.Body == nil {
+			continue
+		}
+
+		report.Total++
+
+		command := requestCommand(rec.Body)
+
+		if rec.Header.OpCode != wire.OpCodeMsg && rec.Header.OpCode != wire.OpCodeQuery ||
+			slices.Contains(verifySkippedCommands, command) {
+			report.Skipped++
+			continue
+		}
+
+		s := report.Commands[cmp.Or(command, "unknown")]
+		if s == nil {
+			s = new(replayCommandStats)
+			report.Commands[cmp.Or(command, "unknown")] = s
+		}
+
+		s.Count++
+
+		ok, d, err := replayRequest(ctx, conn, rec.Body, l)
+		if err != nil {
+			l.WarnContext(
+				ctx, "Failed to replay request",
+				slog.Int("request_id", int(rec.Header.RequestID)), logging.Error(err),
+			)
+
+			report.Errors++
+			s.Errors++
+
+			continue
+		}
+
+		s.durations = append(s.durations, d)
+		s.TotalTime += milliseconds(d)
+
+		if !ok {
+			report.Failed++
+			s.Failed++
+
+			continue
+		}
+
+		report.Succeeded++
+	}
+
+	return nil
+}
+
+// replayRequest sends a single request and returns true if the response has `ok: 1`,
+// and the request duration.
+func replayRequest(ctx context.Context, conn *wireclient.Conn, body wire.MsgBody, l *slog.Logger) (bool, time.Duration, error) { //nolint:lll // for readability
+	start := time.Now()
+
+	_, resBody, err := conn.Request(ctx, body)
+	if err != nil {
+		return false, 0, lazyerrors.Error(err)
+	}
+
+	d := time.Since(start)
+
+	l.DebugContext(ctx, "Response:\n"+resBody.StringIndent())
+
+	doc, err := responseDocument(resBody)
+	if err != nil {
+		return false, 0, lazyerrors.Error(err)
+	}
+
+	var ok bool
+
+	switch v := doc.Get("ok").(type) {
+	case float64:
+		ok = v == 1
+	case int32:
+		ok = v == 1
+	case int64:
+		ok = v == 1
+	}
+
+	return ok, d, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// rotateKeys rotates the pg_tde principal key of the PostgreSQL database used by FerretDB,
+// and optionally encrypts collections that are not encrypted yet.
+func rotateKeys(ctx context.Context, p *documentdb.Pool, keyName, provider string, encryptTables bool, l *slog.Logger) error {
+	return p.WithConn(func(conn *pgx.Conn) error {
+		if err := documentdb.RotateEncryptionKey(ctx, conn, keyName, provider); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		l.InfoContext(ctx, "Principal key rotated", slog.String("key", keyName), slog.String("provider", provider))
+
+		if encryptTables {
+			n, err := documentdb.EncryptTables(ctx, conn)
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			l.InfoContext(ctx, "Collections encrypted", slog.Int("collections", n))
+		}
+
+		s, err := documentdb.GetEncryptionStatus(ctx, conn)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		attrs := []slog.Attr{
+			slog.Bool("enabled", s.Enabled()),
+			slog.String("default_access_method", s.DefaultAccessMethod),
+			slog.Int64("encrypted", s.EncryptedTables),
+			slog.Int64("unencrypted", s.UnencryptedTables),
+		}
+
+		if s.Enabled() {
+			l.LogAttrs(ctx, slog.LevelInfo, "Encryption at rest status", attrs...)
+		} else {
+			l.LogAttrs(ctx, slog.LevelWarn, "Some collections are not encrypted at rest", attrs...)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,379 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/wire"
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/FerretDB/wire/wireclient"
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+)
+
+// Results of a single replayed command.
+const (
+	verifyMatch    = "match"
+	verifyMismatch = "mismatch"
+	verifySkipped  = "skipped"
+	verifyError    = "error"
+)
+
+// verifySkippedCommands contains commands that can't be meaningfully replayed.
+//
+// Authentication conversations use random nonces,
+// and cursor commands reference cursor IDs from the original traffic.
+var verifySkippedCommands = []string{
+	"authenticate",
+	"getMore",
+	"killCursors",
+	"saslContinue",
+	"saslStart",
+}
+
+// verifyVolatileFields contains top-level response fields that differ between servers and runs.
+var verifyVolatileFields = []string{
+	"$clusterTime",
+	"connectionId",
+	"electionId",
+	"lastWrite",
+	"localTime",
+	"operationTime",
+	"topologyVersion",
+}
+
+// verifyReport represents a machine-readable compatibility report.
+type verifyReport struct {
+	FerretDBURL string `json:"ferretdbUrl"`
+	MongoDBURL  string `json:"mongodbUrl"`
+
+	Files      int `json:"files"`
+	Total      int `json:"total"`
+	Matched    int `json:"matched"`
+	Mismatched int `json:"mismatched"`
+	Skipped    int `json:"skipped"`
+	Errors     int `json:"errors"`
+
+	Results []verifyResult `json:"results"`
+}
+
+// verifyResult represents a single replayed command in the report.
+type verifyResult struct {
+	File      string `json:"file"`
+	RequestID int32  `json:"requestId"`
+	Command   string `json:"command"`
+	Result    string `json:"result"`
+	Diff      string `json:"diff,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// add adds the result to the report and updates counters.
+func (r *verifyReport) add(res verifyResult) {
+	r.Total++
+
+	switch res.Result {
+	case verifyMatch:
+		r.Matched++
+	case verifyMismatch:
+		r.Mismatched++
+	case verifySkipped:
+		r.Skipped++
+	case verifyError:
+		r.Errors++
+	default:
+		panic("unexpected result " + res.Result)
+	}
+
+	r.Results = append(r.Results, res)
+}
+
+// verifyCompat replays recorded traffic from the given directory against FerretDB and MongoDB,
+// and writes the compatibility report to w.
+//
+// Each recorded file is replayed over a separate pair of connections.
+// It returns true if no mismatches or errors were found.
+func verifyCompat(ctx context.Context, dir, ferretdbURL, mongodbURL string, w io.Writer, l *slog.Logger) (bool, error) {
+	files, err := recordFiles(dir)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	report := &verifyReport{
+		FerretDBURL: redactURL(ferretdbURL),
+		MongoDBURL:  redactURL(mongodbURL),
+		Files:       len(files),
+		Results:     []verifyResult{},
+	}
+
+	for _, file := range files {
+		l.InfoContext(ctx, "Replaying", slog.String("file", file))
+
+		if err = verifyFile(ctx, file, ferretdbURL, mongodbURL, report, l); err != nil {
+			return false, lazyerrors.Errorf("%s: %w", file, err)
+		}
+	}
+
+	l.InfoContext(
+		ctx, "Replay finished",
+		slog.Int("total", report.Total), slog.Int("matched", report.Matched),
+		slog.Int("mismatched", report.Mismatched), slog.Int("skipped", report.Skipped),
+		slog.Int("errors", report.Errors),
+	)
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	if err = e.Encode(report); err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return report.Mismatched == 0 && report.Errors == 0, nil
+}
+
+// verifyFile replays a single recorded file and adds results to the report.
+func verifyFile(ctx context.Context, file, ferretdbURL, mongodbURL string, report *verifyReport, l *slog.Logger) error {
+	records, err := wire.LoadRecords(file, 0)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	ferretdbConn, err := verifyConnect(ctx, ferretdbURL, l)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer ferretdbConn.Close() //nolint:errcheck // we are only reading responses
+
+	mongodbConn, err := verifyConnect(ctx, mongodbURL, l)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer mongodbConn.Close() //nolint:errcheck // we are only reading responses
+
+	name := filepath.Base(file)
+
+	for _, rec := range records {
+		res, ok := verifyRecord(name, &rec)
+		if !ok {
+			if res.Result != "" {
+				report.add(res)
+			}
+
+			continue
+		}
+
+		var ferretdbRes, mongodbRes string
+
+		if ferretdbRes, err = verifyRequest(ctx, ferretdbConn, rec.Body); err == nil {
+			mongodbRes, err = verifyRequest(ctx, mongodbConn, rec.Body)
+		}
+
+		if err != nil {
+			res.Result = verifyError
+			res.Error = err.Error()
+			report.add(res)
+
+			continue
+		}
+
+		res.Result = verifyMatch
+
+		if res.Diff, err = responseDiff(mongodbRes, ferretdbRes); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if res.Diff != "" {
+			res.Result = verifyMismatch
+		}
+
+		report.add(res)
+	}
+
+	return nil
+}
+
+// verifyRecord returns the result template for the given record of the given file,
+// and true if the record should be replayed.
+//
+// If the record should not be replayed, the returned result is either
+// a complete skipped result or empty for invalid records that should be ignored.
+func verifyRecord(file string, rec *wire.Record) (verifyResult, bool) {
+	if rec.Header == nil || rec.Body == nil {
+		return verifyResult{}, false
+	}
+
+	res := verifyResult{
+		File:      file,
+		RequestID: rec.Header.RequestID,
+		Command:   requestCommand(rec.Body),
+	}
+
+	if rec.Header.OpCode != wire.OpCodeMsg && rec.Header.OpCode != wire.OpCodeQuery {
+		res.Result = verifySkipped
+		return res, false
+	}
+
+	if slices.Contains(verifySkippedCommands, res.Command) {
+		res.Result = verifySkipped
+		return res, false
+	}
+
+	return res, true
+}
+
+// responseDiff returns the unified diff between normalized MongoDB and FerretDB responses,
+// or an empty string if they are equal.
+func responseDiff(mongodbRes, ferretdbRes string) (string, error) {
+	if mongodbRes == ferretdbRes {
+		return "", nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(mongodbRes),
+		FromFile: "mongodb",
+		B:        difflib.SplitLines(ferretdbRes),
+		ToFile:   "ferretdb",
+		Context:  1,
+	})
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	return diff, nil
+}
+
+// verifyConnect connects to the given MongoDB URI and authenticates if credentials are present.
+func verifyConnect(ctx context.Context, uri string, l *slog.Logger) (*wireclient.Conn, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	user := u.User
+	u.User = nil
+
+	authDB := "admin"
+
+	q := u.Query()
+	if v := q.Get("authSource"); v != "" {
+		authDB = v
+	}
+
+	q.Del("authSource")
+	u.RawQuery = q.Encode()
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	conn, err := wireclient.Connect(ctx, u.String(), logging.WithName(l, "wireclient"))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if user != nil {
+		password, _ := user.Password()
+
+		if err = conn.Login(ctx, user.Username(), password, authDB); err != nil {
+			_ = conn.Close()
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return conn, nil
+}
+
+// verifyRequest sends a single request and returns the normalized response for comparison.
+func verifyRequest(ctx context.Context, conn *wireclient.Conn, body wire.MsgBody) (string, error) {
+	_, resBody, err := conn.Request(ctx, body)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	doc, err := responseDocument(resBody)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	normalizeResponse(doc)
+
+	return doc.LogMessageIndent(), nil
+}
+
+// responseDocument returns the decoded document of OP_MSG or OP_REPLY response body.
+func responseDocument(body wire.MsgBody) (*wirebson.Document, error) {
+	switch body := body.(type) {
+	case *wire.OpMsg:
+		return body.DocumentDeep()
+	case *wire.OpReply:
+		return body.DocumentDeep()
+	default:
+		return nil, lazyerrors.Errorf("unexpected response body type %T", body)
+	}
+}
+
+// normalizeResponse removes or replaces response fields that are expected to differ.
+func normalizeResponse(doc *wirebson.Document) {
+	for _, f := range verifyVolatileFields {
+		doc.Remove(f)
+	}
+
+	// cursor IDs are random; only their presence matters
+	if cursor, ok := doc.Get("cursor").(*wirebson.Document); ok {
+		if id, ok := cursor.Get("id").(int64); ok && id != 0 {
+			_ = cursor.Replace("id", int64(1))
+		}
+	}
+}
+
+// requestCommand returns the command name of the recorded request, if any.
+func requestCommand(body wire.MsgBody) string {
+	var doc *wirebson.Document
+	var err error
+
+	switch body := body.(type) {
+	case *wire.OpMsg:
+		doc, err = body.Section0()
+	case *wire.OpQuery:
+		doc, err = body.Query()
+	}
+
+	if err != nil || doc == nil {
+		return ""
+	}
+
+	return doc.Command()
+}
+
+// redactURL returns the given URL without password.
+func redactURL(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return strings.Repeat("*", len(uri))
+	}
+
+	return u.Redacted()
+}
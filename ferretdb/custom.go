@@ -0,0 +1,133 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ferretdb
+
+import (
+	"context"
+
+	"github.com/FerretDB/wire"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+)
+
+// CustomCommandPrefix is a required prefix for names of custom commands.
+const CustomCommandPrefix = handler.CustomCommandPrefix
+
+// CommandFunc processes a custom command.
+//
+// The passed context is canceled when the client disconnects.
+// The returned error could be created by [CommandError] to set the MongoDB error code.
+type CommandFunc func(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
+// Command represents a custom command.
+type Command struct {
+	// Handler processes this command. Required.
+	Handler CommandFunc
+
+	// Help is shown in the `listCommands` command output.
+	// If empty, that command is hidden, but still can be used.
+	Help string
+
+	// Anonymous indicates that the command does not require authentication.
+	Anonymous bool
+}
+
+// Hooks represents functions called before and after every OP_MSG command.
+type Hooks struct {
+	// Before is called before the command is handled.
+	// If it returns an error, the command is not handled, and that error is returned to the client.
+	Before func(ctx context.Context, command string, msg *wire.OpMsg) error
+
+	// After is called after the command is handled with its result.
+	// The response is nil if the command returned an error.
+	After func(ctx context.Context, command string, msg, res *wire.OpMsg, err error)
+}
+
+// RegisterCommand registers a custom command with the given name.
+//
+// The name should start with [CustomCommandPrefix].
+// Commands are available in all FerretDB instances created after that call,
+// so it is typically called from init functions of plugin packages
+// or by the embedding application before calling [New].
+func RegisterCommand(name string, cmd *Command) error {
+	if cmd == nil {
+		return handler.RegisterCommand(name, nil)
+	}
+
+	f := cmd.Handler
+	if f == nil {
+		return handler.RegisterCommand(name, new(handler.CustomCommand))
+	}
+
+	return handler.RegisterCommand(name, &handler.CustomCommand{
+		Handler: func(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+			if req.OpMsg == nil {
+				return nil, CommandError(int32(mongoerrors.ErrNotImplemented), "custom commands support only OP_MSG")
+			}
+
+			res, err := f(ctx, req.OpMsg)
+			if err != nil {
+				return nil, err
+			}
+
+			return &middleware.Response{OpMsg: res}, nil
+		},
+		Help:      cmd.Help,
+		Anonymous: cmd.Anonymous,
+	})
+}
+
+// RegisterHooks registers hooks called for every OP_MSG command
+// of all FerretDB instances created after that call.
+//
+// Hooks are called in the registration order.
+func RegisterHooks(hooks *Hooks) {
+	h := &handler.Hook{}
+
+	if before := hooks.Before; before != nil {
+		h.Pre = func(ctx context.Context, command string, req *middleware.Request) error {
+			if req.OpMsg == nil {
+				return nil
+			}
+
+			return before(ctx, command, req.OpMsg)
+		}
+	}
+
+	if after := hooks.After; after != nil {
+		h.Post = func(ctx context.Context, command string, req *middleware.Request, res *middleware.Response, err error) {
+			if req.OpMsg == nil {
+				return
+			}
+
+			var msg *wire.OpMsg
+			if res != nil {
+				msg = res.OpMsg
+			}
+
+			after(ctx, command, req.OpMsg, msg, err)
+		}
+	}
+
+	handler.RegisterHook(h)
+}
+
+// CommandError returns an error with the given MongoDB error code and message
+// that is returned to the client as is.
+func CommandError(code int32, msg string) error {
+	return mongoerrors.New(mongoerrors.Code(code), msg)
+}
@@ -22,6 +22,13 @@
 // Please note that the current version of the embeddable package does not allow [telemetry] configuration –
 // it is always set to the `undecided` state. That limitation will be removed [in the future].
 //
+// # Custom commands
+//
+// Embedding applications and plugin packages can add custom commands with names starting with
+// [CustomCommandPrefix] by calling [RegisterCommand], and observe or reject any command with [RegisterHooks].
+// Plugin packages typically do that in their init functions, so importing them for side effects
+// into the application's main package is enough.
+//
 // [`build/version` package documentation]: https://pkg.go.dev/github.com/FerretDB/FerretDB/v2/build/version
 // [telemetry]: https://docs.ferretdb.io/telemetry/
 // [in the future]: https://github.com/FerretDB/FerretDB/issues/4750
@@ -23,6 +23,7 @@
 	"os/exec"
 	"testing"
 
+	"github.com/FerretDB/wire"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -83,6 +84,30 @@ func Example() {
 	// Output: mongodb://127.0.0.1:17027/
 }
 
+func ExampleRegisterCommand() {
+	err := ferretdb.RegisterCommand("x_hello", &ferretdb.Command{
+		Handler: func(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+			return wire.MustOpMsg("greeting", "Hello from a custom command!", "ok", float64(1)), nil
+		},
+		Help: "Returns a greeting.",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ferretdb.RegisterHooks(&ferretdb.Hooks{
+		Before: func(ctx context.Context, command string, msg *wire.OpMsg) error {
+			if command == "dropDatabase" {
+				return ferretdb.CommandError(13, "dropDatabase is disabled by the organization policy")
+			}
+
+			return nil
+		},
+	})
+
+	// Create FerretDB instance with [ferretdb.New] as usual.
+}
+
 func TestFerretDB(t *testing.T) {
 	f, err := ferretdb.New(&ferretdb.Config{
 		PostgreSQLURL: testutil.PostgreSQLURL(t),
@@ -0,0 +1,83 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/v2/integration"
+	"github.com/FerretDB/FerretDB/v2/integration/setup"
+)
+
+func TestSpecialNamespaces(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	t.Run("SystemCollection", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := collection.Database().Collection("system.test").InsertOne(ctx, bson.D{{"_id", "foo"}})
+		integration.AssertMatchesCommandError(t, mongo.CommandError{Code: 73, Name: "InvalidNamespace"}, err)
+	})
+
+	t.Run("ConfigStubs", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "MongoDB allows writes to config collections on standalone servers")
+
+		t.Parallel()
+
+		config := collection.Database().Client().Database("config")
+
+		for _, name := range []string{"chunks", "collections"} {
+			_, err := config.Collection(name).InsertOne(ctx, bson.D{{"_id", "foo"}})
+			integration.AssertEqualCommandError(t, mongo.CommandError{
+				Code:    73,
+				Name:    "InvalidNamespace",
+				Message: "cannot write to 'config." + name + "'",
+			}, err)
+
+			cursor, err := config.Collection(name).Find(ctx, bson.D{})
+			require.NoError(t, err)
+			assert.Empty(t, integration.FetchAll(t, ctx, cursor))
+		}
+	})
+
+	t.Run("PipelineOutput", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "MongoDB returns stage-specific errors for $out and $merge targets")
+
+		t.Parallel()
+
+		for name, stage := range map[string]bson.D{
+			"OutSystem":   {{"$out", "system.test"}},
+			"OutConfig":   {{"$out", bson.D{{"db", "config"}, {"coll", "chunks"}}}},
+			"MergeSystem": {{"$merge", bson.D{{"into", "system.test"}}}},
+			"MergeConfig": {{"$merge", bson.D{
+				{"into", bson.D{{"db", "config"}, {"coll", "collections"}}},
+			}}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := collection.Aggregate(ctx, bson.A{stage})
+				integration.AssertMatchesCommandError(t, mongo.CommandError{Code: 73, Name: "InvalidNamespace"}, err)
+			})
+		}
+	})
+}
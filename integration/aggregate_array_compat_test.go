@@ -0,0 +1,207 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/v2/integration/shareddata"
+)
+
+func TestAggregateCompatArrayExpressions(t *testing.T) {
+	t.Parallel()
+
+	// numeric arrays only, so arithmetic expressions do not fail on other types
+	providers := []shareddata.Provider{shareddata.ArrayInt32s, shareddata.ArrayInt64s, shareddata.ArrayDoubles}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Map": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$map", bson.D{
+				{"input", "$v"},
+				{"as", "e"},
+				{"in", bson.D{{"$multiply", bson.A{"$$e", int32(2)}}}},
+			}}}}}}}},
+		},
+		"MapDefaultVariable": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$map", bson.D{
+				{"input", "$v"},
+				{"in", bson.D{{"$add", bson.A{"$$this", int32(1)}}}},
+			}}}}}}}},
+		},
+		"MapNotArray": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$map", bson.D{
+				{"input", "foo"},
+				{"in", "$$this"},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+		"Filter": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$filter", bson.D{
+				{"input", "$v"},
+				{"as", "e"},
+				{"cond", bson.D{{"$gt", bson.A{"$$e", int32(42)}}}},
+			}}}}}}}},
+		},
+		"FilterLimit": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$filter", bson.D{
+				{"input", "$v"},
+				{"cond", bson.D{{"$gte", bson.A{"$$this", int32(42)}}}},
+				{"limit", int32(1)},
+			}}}}}}}},
+		},
+		"Reduce": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$reduce", bson.D{
+				{"input", "$v"},
+				{"initialValue", int32(0)},
+				{"in", bson.D{{"$add", bson.A{"$$value", "$$this"}}}},
+			}}}}}}}},
+		},
+		"ReduceDocument": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$reduce", bson.D{
+				{"input", "$v"},
+				{"initialValue", bson.D{{"count", int32(0)}, {"max", nil}}},
+				{"in", bson.D{
+					{"count", bson.D{{"$add", bson.A{"$$value.count", int32(1)}}}},
+					{"max", bson.D{{"$max", bson.A{"$$value.max", "$$this"}}}},
+				}},
+			}}}}}}}},
+		},
+		"Zip": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$zip", bson.D{
+				{"inputs", bson.A{"$v", bson.A{"a", "b"}}},
+			}}}}}}}},
+		},
+		"ZipLongest": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$zip", bson.D{
+				{"inputs", bson.A{"$v", bson.A{"a", "b"}}},
+				{"useLongestLength", true},
+				{"defaults", bson.A{int32(0), "z"}},
+			}}}}}}}},
+		},
+		"ZipDefaultsWithoutLongest": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$zip", bson.D{
+				{"inputs", bson.A{"$v", bson.A{"a", "b"}}},
+				{"defaults", bson.A{int32(0), "z"}},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+		"SortArrayAsc": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$sortArray", bson.D{
+				{"input", "$v"},
+				{"sortBy", int32(1)},
+			}}}}}}}},
+		},
+		"SortArrayDesc": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$sortArray", bson.D{
+				{"input", "$v"},
+				{"sortBy", int32(-1)},
+			}}}}}}}},
+		},
+		"SortArrayBadSortBy": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$sortArray", bson.D{
+				{"input", "$v"},
+				{"sortBy", int32(2)},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+		"FirstN": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$firstN", bson.D{
+				{"input", "$v"},
+				{"n", int32(2)},
+			}}}}}}}},
+		},
+		"LastN": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$lastN", bson.D{
+				{"input", "$v"},
+				{"n", int32(2)},
+			}}}}}}}},
+		},
+		"FirstNZero": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$firstN", bson.D{
+				{"input", "$v"},
+				{"n", int32(0)},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatGroupNAccumulators(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"FirstN": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"res", bson.D{{"$firstN", bson.D{{"input", "$v"}, {"n", int32(3)}}}}},
+				}}},
+			},
+		},
+		"LastN": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"res", bson.D{{"$lastN", bson.D{{"input", "$v"}, {"n", int32(3)}}}}},
+				}}},
+			},
+		},
+		"TopN": {
+			pipeline: bson.A{bson.D{{"$group", bson.D{
+				{"_id", nil},
+				{"res", bson.D{{"$topN", bson.D{
+					{"n", int32(2)},
+					{"sortBy", bson.D{{"_id", 1}}},
+					{"output", bson.A{"$_id", "$v"}},
+				}}}},
+			}}}},
+		},
+		"BottomN": {
+			pipeline: bson.A{bson.D{{"$group", bson.D{
+				{"_id", nil},
+				{"res", bson.D{{"$bottomN", bson.D{
+					{"n", int32(2)},
+					{"sortBy", bson.D{{"_id", -1}}},
+					{"output", "$_id"},
+				}}}},
+			}}}},
+		},
+		"TopNMissingSortBy": {
+			pipeline: bson.A{bson.D{{"$group", bson.D{
+				{"_id", nil},
+				{"res", bson.D{{"$topN", bson.D{
+					{"n", int32(2)},
+					{"output", "$v"},
+				}}}},
+			}}}},
+			resultType: EmptyResult,
+		},
+		"NNegative": {
+			pipeline: bson.A{bson.D{{"$group", bson.D{
+				{"_id", nil},
+				{"res", bson.D{{"$firstN", bson.D{{"input", "$v"}, {"n", int32(-1)}}}}},
+			}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
@@ -368,6 +368,14 @@ func TestAggregateCompatStages(t *testing.T) {
 				bson.D{{"$sort", bson.D{{"_id", 1}}}},
 			},
 		},
+		"SampleNegativeSize": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", int32(-1)}}}}},
+			resultType: EmptyResult,
+		},
+		"SampleMissingSize": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{}}}},
+			resultType: EmptyResult,
+		},
 	}
 
 	testAggregateStagesCompat(t, testCases)
@@ -0,0 +1,143 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/v2/integration/shareddata"
+)
+
+// convertCompatPipeline returns a pipeline projecting the result of the given conversion expression.
+func convertCompatPipeline(expr bson.D) bson.A {
+	return bson.A{bson.D{{"$project", bson.D{{"res", expr}}}}}
+}
+
+func TestAggregateCompatConvert(t *testing.T) {
+	t.Parallel()
+
+	convert := func(to any) bson.D {
+		return bson.D{{"$convert", bson.D{
+			{"input", "$v"},
+			{"to", to},
+			{"onError", "error"},
+			{"onNull", "null"},
+		}}}
+	}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"ToDouble": {
+			pipeline: convertCompatPipeline(convert("double")),
+		},
+		"ToString": {
+			pipeline: convertCompatPipeline(convert("string")),
+		},
+		"ToObjectID": {
+			pipeline: convertCompatPipeline(convert("objectId")),
+		},
+		"ToBool": {
+			pipeline: convertCompatPipeline(convert("bool")),
+		},
+		"ToDate": {
+			pipeline: convertCompatPipeline(convert("date")),
+		},
+		"ToInt": {
+			pipeline: convertCompatPipeline(convert("int")),
+		},
+		"ToLong": {
+			pipeline: convertCompatPipeline(convert("long")),
+		},
+		"ToDecimal": {
+			pipeline: convertCompatPipeline(convert("decimal")),
+		},
+		"ToTypeCode": {
+			pipeline: convertCompatPipeline(convert(int32(16))),
+		},
+		"ToTypeCodeDouble": {
+			pipeline: convertCompatPipeline(convert(float64(2))),
+		},
+		"OnErrorMissing": {
+			pipeline: convertCompatPipeline(bson.D{{"$convert", bson.D{
+				{"input", "foo"},
+				{"to", "int"},
+			}}}),
+			resultType: EmptyResult,
+		},
+		"OnNullMissing": {
+			pipeline: convertCompatPipeline(bson.D{{"$convert", bson.D{
+				{"input", nil},
+				{"to", "int"},
+			}}}),
+		},
+		"OnErrorExpression": {
+			pipeline: convertCompatPipeline(bson.D{{"$convert", bson.D{
+				{"input", "$v"},
+				{"to", "int"},
+				{"onError", bson.D{{"$type", "$v"}}},
+			}}}),
+		},
+		"InvalidTo": {
+			pipeline:   convertCompatPipeline(convert("invalid")),
+			resultType: EmptyResult,
+		},
+		"InvalidTypeCode": {
+			pipeline:   convertCompatPipeline(convert(int32(100))),
+			resultType: EmptyResult,
+		},
+		"MissingTo": {
+			pipeline: convertCompatPipeline(bson.D{{"$convert", bson.D{
+				{"input", "$v"},
+			}}}),
+			resultType: EmptyResult,
+		},
+		"UnknownField": {
+			pipeline: convertCompatPipeline(bson.D{{"$convert", bson.D{
+				{"input", "$v"},
+				{"to", "int"},
+				{"foo", "bar"},
+			}}}),
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatConvertShorthand(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"ToDate": {
+			pipeline: convertCompatPipeline(bson.D{{"$toDate", "$v"}}),
+		},
+		"ToDecimal": {
+			pipeline: convertCompatPipeline(bson.D{{"$toDecimal", "$v"}}),
+		},
+		"ToObjectID": {
+			pipeline:   convertCompatPipeline(bson.D{{"$toObjectId", "$v"}}),
+			resultType: EmptyResult,
+		},
+		"ToObjectIDString": {
+			pipeline: convertCompatPipeline(bson.D{{"$toObjectId", "000102030405060708091011"}}),
+		},
+	}
+
+	// values that can be converted to both dates and decimals, but not ObjectIDs
+	providers := []shareddata.Provider{shareddata.Int64s, shareddata.DateTimes}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
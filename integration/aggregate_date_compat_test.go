@@ -0,0 +1,258 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/FerretDB/FerretDB/v2/integration/shareddata"
+)
+
+// testAggregateDateCompat tests date expressions compatibility test cases
+// by projecting the result of the given expression for all date values.
+func testAggregateDateCompat(t *testing.T, testCases map[string]aggregateStagesCompatTestCase) {
+	t.Helper()
+
+	providers := []shareddata.Provider{shareddata.Scalars, shareddata.DateTimes}
+
+	for name, tc := range testCases {
+		tc.pipeline = append(bson.A{bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "date"}}}}}}}, tc.pipeline...)
+		testCases[name] = tc
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatDateTrunc(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Day": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"trunc", bson.D{{"$dateTrunc", bson.D{
+				{"date", "$v"},
+				{"unit", "day"},
+			}}}}}}}},
+		},
+		"BinSize": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"trunc", bson.D{{"$dateTrunc", bson.D{
+				{"date", "$v"},
+				{"unit", "minute"},
+				{"binSize", int32(15)},
+			}}}}}}}},
+		},
+		"WeekStartOfWeek": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"trunc", bson.D{{"$dateTrunc", bson.D{
+				{"date", "$v"},
+				{"unit", "week"},
+				{"startOfWeek", "monday"},
+			}}}}}}}},
+		},
+		"TimezoneName": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"trunc", bson.D{{"$dateTrunc", bson.D{
+				{"date", "$v"},
+				{"unit", "day"},
+				{"timezone", "America/New_York"},
+			}}}}}}}},
+		},
+		"TimezoneOffset": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"trunc", bson.D{{"$dateTrunc", bson.D{
+				{"date", "$v"},
+				{"unit", "hour"},
+				{"timezone", "+05:30"},
+			}}}}}}}},
+		},
+		"InvalidUnit": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"trunc", bson.D{{"$dateTrunc", bson.D{
+				{"date", "$v"},
+				{"unit", "invalid"},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+		"InvalidTimezone": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"trunc", bson.D{{"$dateTrunc", bson.D{
+				{"date", "$v"},
+				{"unit", "day"},
+				{"timezone", "Invalid/Zone"},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateDateCompat(t, testCases)
+}
+
+func TestAggregateCompatDateDiff(t *testing.T) {
+	t.Parallel()
+
+	end := primitive.NewDateTimeFromTime(time.Date(2024, 2, 29, 23, 30, 0, 0, time.UTC))
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Day": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"diff", bson.D{{"$dateDiff", bson.D{
+				{"startDate", "$v"},
+				{"endDate", end},
+				{"unit", "day"},
+			}}}}}}}},
+		},
+		"Month": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"diff", bson.D{{"$dateDiff", bson.D{
+				{"startDate", "$v"},
+				{"endDate", end},
+				{"unit", "month"},
+			}}}}}}}},
+		},
+		"WeekStartOfWeek": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"diff", bson.D{{"$dateDiff", bson.D{
+				{"startDate", "$v"},
+				{"endDate", end},
+				{"unit", "week"},
+				{"startOfWeek", "sunday"},
+			}}}}}}}},
+		},
+		"Timezone": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"diff", bson.D{{"$dateDiff", bson.D{
+				{"startDate", "$v"},
+				{"endDate", end},
+				{"unit", "day"},
+				{"timezone", "Asia/Tokyo"},
+			}}}}}}}},
+		},
+		"Negative": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"diff", bson.D{{"$dateDiff", bson.D{
+				{"startDate", end},
+				{"endDate", "$v"},
+				{"unit", "year"},
+			}}}}}}}},
+		},
+		"InvalidStartDate": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"diff", bson.D{{"$dateDiff", bson.D{
+				{"startDate", "foo"},
+				{"endDate", "$v"},
+				{"unit", "day"},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateDateCompat(t, testCases)
+}
+
+func TestAggregateCompatDateAdd(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"AddHours": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateAdd", bson.D{
+				{"startDate", "$v"},
+				{"unit", "hour"},
+				{"amount", int32(25)},
+			}}}}}}}},
+		},
+		"AddMonthTimezone": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateAdd", bson.D{
+				{"startDate", "$v"},
+				{"unit", "month"},
+				{"amount", int64(1)},
+				{"timezone", "Europe/Berlin"},
+			}}}}}}}},
+		},
+		"SubtractDays": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateSubtract", bson.D{
+				{"startDate", "$v"},
+				{"unit", "day"},
+				{"amount", int32(3)},
+			}}}}}}}},
+		},
+		"SubtractQuarterOffset": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateSubtract", bson.D{
+				{"startDate", "$v"},
+				{"unit", "quarter"},
+				{"amount", int32(1)},
+				{"timezone", "-03:00"},
+			}}}}}}}},
+		},
+		"AmountNotWhole": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateAdd", bson.D{
+				{"startDate", "$v"},
+				{"unit", "day"},
+				{"amount", 1.5},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateDateCompat(t, testCases)
+}
+
+func TestAggregateCompatDateFromParts(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Calendar": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateFromParts", bson.D{
+				{"year", int32(2021)},
+				{"month", int32(11)},
+				{"day", int32(1)},
+				{"hour", int32(10)},
+				{"minute", int32(18)},
+				{"second", int32(42)},
+				{"millisecond", int32(123)},
+			}}}}}}}},
+		},
+		"CalendarOverflow": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateFromParts", bson.D{
+				{"year", int32(2021)},
+				{"month", int32(14)},
+				{"day", int32(-1)},
+				{"hour", int32(30)},
+			}}}}}}}},
+		},
+		"ISOWeek": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateFromParts", bson.D{
+				{"isoWeekYear", int32(2021)},
+				{"isoWeek", int32(53)},
+				{"isoDayOfWeek", int32(5)},
+			}}}}}}}},
+		},
+		"Timezone": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateFromParts", bson.D{
+				{"year", int32(2021)},
+				{"month", int32(3)},
+				{"day", int32(31)},
+				{"hour", int32(2)},
+				{"timezone", "Europe/Berlin"},
+			}}}}}}}},
+		},
+		"MixedParts": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateFromParts", bson.D{
+				{"year", int32(2021)},
+				{"isoWeek", int32(1)},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+		"MissingYear": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"date", bson.D{{"$dateFromParts", bson.D{
+				{"month", int32(1)},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateDateCompat(t, testCases)
+}
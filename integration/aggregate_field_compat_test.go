@@ -0,0 +1,114 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAggregateCompatFieldAccessors(t *testing.T) {
+	t.Parallel()
+
+	// adds fields that can't be set or accessed by field paths
+	setFields := bson.D{{"$replaceWith", bson.D{{"$setField", bson.D{
+		{"field", "a.b"},
+		{"input", bson.D{{"$setField", bson.D{
+			{"field", bson.D{{"$literal", "$price"}}},
+			{"input", "$$ROOT"},
+			{"value", "$v"},
+		}}}},
+		{"value", "$_id"},
+	}}}}}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"SetField": {
+			pipeline: bson.A{setFields},
+		},
+		"GetFieldDot": {
+			pipeline: bson.A{
+				setFields,
+				bson.D{{"$project", bson.D{{"res", bson.D{{"$getField", "a.b"}}}}}},
+			},
+		},
+		"GetFieldDollar": {
+			pipeline: bson.A{
+				setFields,
+				bson.D{{"$project", bson.D{{"res", bson.D{{"$getField", bson.D{{"$literal", "$price"}}}}}}}},
+			},
+		},
+		"GetFieldInput": {
+			pipeline: bson.A{
+				setFields,
+				bson.D{{"$project", bson.D{{"res", bson.D{{"$getField", bson.D{
+					{"field", "a.b"},
+					{"input", "$$CURRENT"},
+				}}}}}}},
+			},
+		},
+		"GetFieldMissing": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$getField", "missing"}}}}}}},
+		},
+		"GetFieldInputNotDocument": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$getField", bson.D{
+				{"field", "v"},
+				{"input", int32(42)},
+			}}}}}}}},
+		},
+		"GetFieldNotString": {
+			pipeline:   bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$getField", int32(42)}}}}}}},
+			resultType: EmptyResult,
+		},
+		"GetFieldPathExpression": {
+			pipeline:   bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$getField", "$v"}}}}}}},
+			resultType: EmptyResult,
+		},
+		"UnsetField": {
+			pipeline: bson.A{
+				setFields,
+				bson.D{{"$replaceWith", bson.D{{"$unsetField", bson.D{
+					{"field", "a.b"},
+					{"input", "$$ROOT"},
+				}}}}},
+			},
+		},
+		"UnsetFieldDollar": {
+			pipeline: bson.A{
+				setFields,
+				bson.D{{"$replaceWith", bson.D{{"$unsetField", bson.D{
+					{"field", bson.D{{"$literal", "$price"}}},
+					{"input", "$$ROOT"},
+				}}}}},
+			},
+		},
+		"SetFieldRemove": {
+			pipeline: bson.A{bson.D{{"$replaceWith", bson.D{{"$setField", bson.D{
+				{"field", "v"},
+				{"input", "$$ROOT"},
+				{"value", "$$REMOVE"},
+			}}}}}},
+		},
+		"SetFieldMissingValue": {
+			pipeline: bson.A{bson.D{{"$replaceWith", bson.D{{"$setField", bson.D{
+				{"field", "v"},
+				{"input", "$$ROOT"},
+			}}}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
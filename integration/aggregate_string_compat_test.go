@@ -0,0 +1,221 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/FerretDB/FerretDB/v2/integration/shareddata"
+)
+
+// testAggregateStringCompat tests string expressions compatibility test cases
+// by projecting the result of the given expression for all string values.
+func testAggregateStringCompat(t *testing.T, testCases map[string]aggregateStagesCompatTestCase) {
+	t.Helper()
+
+	providers := []shareddata.Provider{shareddata.Scalars, shareddata.Strings}
+
+	for name, tc := range testCases {
+		tc.pipeline = append(bson.A{bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "string"}}}}}}}, tc.pipeline...)
+		testCases[name] = tc
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatRegex(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Find": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$regexFind", bson.D{
+				{"input", "$v"},
+				{"regex", "o+"},
+			}}}}}}}},
+		},
+		"FindCaptures": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$regexFind", bson.D{
+				{"input", "$v"},
+				{"regex", "(\\d+)(\\.(\\d+))?"},
+			}}}}}}}},
+		},
+		"FindOptions": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$regexFind", bson.D{
+				{"input", "$v"},
+				{"regex", "^F"},
+				{"options", "i"},
+			}}}}}}}},
+		},
+		"FindRegexType": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$regexFind", bson.D{
+				{"input", "$v"},
+				{"regex", primitive.Regex{Pattern: "^F", Options: "i"}},
+			}}}}}}}},
+		},
+		"FindAll": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$regexFindAll", bson.D{
+				{"input", "$v"},
+				{"regex", "."},
+			}}}}}}}},
+		},
+		"FindAllEmptyMatches": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$regexFindAll", bson.D{
+				{"input", "$v"},
+				{"regex", "o*"},
+			}}}}}}}},
+		},
+		"Match": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$regexMatch", bson.D{
+				{"input", "$v"},
+				{"regex", "^4"},
+			}}}}}}}},
+		},
+		"MatchNullInput": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$regexMatch", bson.D{
+				{"input", nil},
+				{"regex", "^4"},
+			}}}}}}}},
+		},
+		"InvalidRegex": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$regexMatch", bson.D{
+				{"input", "$v"},
+				{"regex", "("},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+		"DuplicateOptions": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$regexMatch", bson.D{
+				{"input", "$v"},
+				{"regex", primitive.Regex{Pattern: "^F", Options: "i"}},
+				{"options", "i"},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateStringCompat(t, testCases)
+}
+
+func TestAggregateCompatReplace(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"All": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$replaceAll", bson.D{
+				{"input", "$v"},
+				{"find", "o"},
+				{"replacement", "0"},
+			}}}}}}}},
+		},
+		"AllEmptyFind": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$replaceAll", bson.D{
+				{"input", "$v"},
+				{"find", ""},
+				{"replacement", "-"},
+			}}}}}}}},
+		},
+		"One": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$replaceOne", bson.D{
+				{"input", "$v"},
+				{"find", "o"},
+				{"replacement", "0"},
+			}}}}}}}},
+		},
+		"NullReplacement": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$replaceAll", bson.D{
+				{"input", "$v"},
+				{"find", "o"},
+				{"replacement", nil},
+			}}}}}}}},
+		},
+		"FindNotString": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$replaceAll", bson.D{
+				{"input", "$v"},
+				{"find", int32(4)},
+				{"replacement", "0"},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateStringCompat(t, testCases)
+}
+
+func TestAggregateCompatSplit(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Dot": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$split", bson.A{"$v", "."}}}}}}}},
+		},
+		"Repeated": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$split", bson.A{"$v", "o"}}}}}}}},
+		},
+		"NullDelimiter": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$split", bson.A{"$v", nil}}}}}}}},
+		},
+		"EmptyDelimiter": {
+			pipeline:   bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$split", bson.A{"$v", ""}}}}}}}},
+			resultType: EmptyResult,
+		},
+		"DelimiterNotString": {
+			pipeline:   bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$split", bson.A{"$v", int32(4)}}}}}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateStringCompat(t, testCases)
+}
+
+func TestAggregateCompatTrim(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Whitespace": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$trim", bson.D{
+				{"input", bson.D{{"$concat", bson.A{" \t", "$v", "\n "}}}},
+			}}}}}}}},
+		},
+		"Chars": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$trim", bson.D{
+				{"input", "$v"},
+				{"chars", "f4"},
+			}}}}}}}},
+		},
+		"Left": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$ltrim", bson.D{
+				{"input", "$v"},
+				{"chars", "f"},
+			}}}}}}}},
+		},
+		"Right": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$rtrim", bson.D{
+				{"input", "$v"},
+				{"chars", "o3"},
+			}}}}}}}},
+		},
+		"InputNotString": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{{"res", bson.D{{"$trim", bson.D{
+				{"input", int32(42)},
+			}}}}}}}},
+			resultType: EmptyResult,
+		},
+	}
+
+	testAggregateStringCompat(t, testCases)
+}
@@ -733,6 +733,65 @@ func TestAggregateProject(t *testing.T) {
 	}
 }
 
+func TestAggregateSampleRand(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+
+	var allIDs []any
+	for _, doc := range shareddata.Scalars.Docs() {
+		allIDs = append(allIDs, doc.Map()["_id"])
+	}
+
+	t.Run("Sample", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := collection.Aggregate(ctx, bson.A{bson.D{{"$sample", bson.D{{"size", 3}}}}})
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+
+		ids := CollectIDs(t, res)
+		require.Len(t, ids, 3)
+
+		for i, id := range ids {
+			assert.Contains(t, allIDs, id)
+			assert.NotContains(t, ids[i+1:], id, "duplicate document")
+		}
+	})
+
+	t.Run("SampleAll", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := collection.Aggregate(ctx, bson.A{bson.D{{"$sample", bson.D{{"size", len(allIDs) + 1}}}}})
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+		assert.ElementsMatch(t, allIDs, CollectIDs(t, res))
+	})
+
+	t.Run("Rand", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := bson.A{bson.D{{"$project", bson.D{{"r", bson.D{{"$rand", bson.D{}}}}}}}}
+		cursor, err := collection.Aggregate(ctx, pipeline)
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+		require.Len(t, res, len(allIDs))
+
+		for _, doc := range res {
+			r, ok := doc.Map()["r"].(float64)
+			require.True(t, ok, "unexpected type %T", doc.Map()["r"])
+			assert.GreaterOrEqual(t, r, float64(0))
+			assert.Less(t, r, float64(1))
+		}
+	})
+}
+
 func TestAggregateSetErrors(t *testing.T) {
 	t.Parallel()
 
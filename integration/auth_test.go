@@ -1305,3 +1305,72 @@ func TestSASLContinueOpQueryErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthAuthenticationRestrictions(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx, db := s.Ctx, s.Collection.Database()
+	username, password, mechanism := "restricteduser", "testpass", "SCRAM-SHA-256"
+
+	_ = db.RunCommand(ctx, bson.D{{"dropUser", username}})
+
+	err := db.RunCommand(ctx, bson.D{
+		{"createUser", username},
+		{"roles", bson.A{}},
+		{"pwd", password},
+		{"mechanisms", bson.A{mechanism}},
+		{"authenticationRestrictions", bson.A{bson.D{{"clientSource", bson.A{"192.0.2.0/24"}}}}},
+	}).Err()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = db.RunCommand(ctx, bson.D{{"dropUser", username}})
+	})
+
+	var res bson.D
+	err = db.RunCommand(ctx, bson.D{
+		{"usersInfo", username},
+		{"showAuthenticationRestrictions", true},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	users, ok := res.Map()["users"].(bson.A)
+	require.True(t, ok)
+	require.Len(t, users, 1)
+
+	restrictions := users[0].(bson.D).Map()["authenticationRestrictions"]
+	assert.Equal(t, bson.A{bson.D{{"clientSource", bson.A{"192.0.2.0/24"}}}}, restrictions)
+
+	connect := func() error {
+		opts := options.Client().ApplyURI(s.MongoDBURI).SetAuth(options.Credential{
+			AuthMechanism: mechanism,
+			AuthSource:    db.Name(),
+			Username:      username,
+			Password:      password,
+		})
+
+		client, err := mongo.Connect(ctx, opts)
+		require.NoError(t, err)
+
+		defer client.Disconnect(ctx) //nolint:errcheck // we are only interested in ping
+
+		return client.Ping(ctx, nil)
+	}
+
+	require.ErrorContains(t, connect(), "Authentication failed")
+
+	err = db.RunCommand(ctx, bson.D{
+		{"updateUser", username},
+		{"authenticationRestrictions", bson.A{bson.D{{"clientSource", bson.A{"0.0.0.0/0", "::/0"}}}}},
+	}).Err()
+	require.NoError(t, err)
+
+	require.NoError(t, connect())
+
+	err = db.RunCommand(ctx, bson.D{
+		{"updateUser", username},
+		{"authenticationRestrictions", bson.A{bson.D{{"clientSource", bson.A{"10.0.0.0/33"}}}}},
+	}).Err()
+	require.Error(t, err)
+}
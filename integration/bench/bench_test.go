@@ -0,0 +1,157 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/v2/integration/setup"
+)
+
+// Data set parameters.
+const (
+	// benchDocs is the number of documents in the data set.
+	benchDocs = 10_000
+
+	// benchGroups is the number of distinct `v` values.
+	benchGroups = 100
+
+	// benchRange is the number of documents returned by the range scan.
+	benchRange = 100
+)
+
+// setupData returns a collection with the same data set for every run:
+// documents with int32 _id from 0 to benchDocs-1, grouped by v field.
+func setupData(b *testing.B) (context.Context, *mongo.Collection) {
+	b.Helper()
+
+	ctx, coll := setup.Setup(b)
+
+	docs := make([]any, benchDocs)
+	for i := range docs {
+		docs[i] = bson.D{
+			{"_id", int32(i)},
+			{"v", int32(i % benchGroups)},
+			{"s", fmt.Sprintf("string %d", i)},
+			{"n", int64(0)},
+		}
+	}
+
+	_, err := coll.InsertMany(ctx, docs)
+	require.NoError(b, err)
+
+	return ctx, coll
+}
+
+func BenchmarkInsert(b *testing.B) {
+	ctx, coll := setup.Setup(b)
+
+	var id int32
+
+	for b.Loop() {
+		_, err := coll.InsertOne(ctx, bson.D{{"_id", id}, {"v", id % benchGroups}, {"s", fmt.Sprintf("string %d", id)}})
+		require.NoError(b, err)
+
+		id++
+	}
+
+	setup.PushBenchmarkResult(b)
+}
+
+func BenchmarkPointRead(b *testing.B) {
+	ctx, coll := setupData(b)
+
+	var i int32
+
+	for b.Loop() {
+		var doc bson.D
+		err := coll.FindOne(ctx, bson.D{{"_id", i % benchDocs}}).Decode(&doc)
+		require.NoError(b, err)
+
+		i++
+	}
+
+	setup.PushBenchmarkResult(b)
+}
+
+func BenchmarkRangeScan(b *testing.B) {
+	ctx, coll := setupData(b)
+
+	opts := options.Find().SetSort(bson.D{{"_id", 1}})
+
+	var i int32
+
+	for b.Loop() {
+		start := (i * benchRange) % benchDocs
+		filter := bson.D{{"_id", bson.D{{"$gte", start}, {"$lt", start + benchRange}}}}
+
+		cursor, err := coll.Find(ctx, filter, opts)
+		require.NoError(b, err)
+
+		var docs []bson.D
+		require.NoError(b, cursor.All(ctx, &docs))
+		require.Len(b, docs, benchRange)
+
+		i++
+	}
+
+	setup.PushBenchmarkResult(b)
+}
+
+func BenchmarkAggregate(b *testing.B) {
+	ctx, coll := setupData(b)
+
+	pipeline := bson.A{
+		bson.D{{"$match", bson.D{{"v", bson.D{{"$lt", benchGroups / 2}}}}}},
+		bson.D{{"$group", bson.D{{"_id", "$v"}, {"count", bson.D{{"$sum", 1}}}}}},
+		bson.D{{"$sort", bson.D{{"_id", 1}}}},
+	}
+
+	for b.Loop() {
+		cursor, err := coll.Aggregate(ctx, pipeline)
+		require.NoError(b, err)
+
+		var docs []bson.D
+		require.NoError(b, cursor.All(ctx, &docs))
+		require.Len(b, docs, benchGroups/2)
+	}
+
+	setup.PushBenchmarkResult(b)
+}
+
+func BenchmarkFindAndModify(b *testing.B) {
+	ctx, coll := setupData(b)
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var i int32
+
+	for b.Loop() {
+		var doc bson.D
+		err := coll.FindOneAndUpdate(ctx, bson.D{{"_id", i % benchDocs}}, bson.D{{"$inc", bson.D{{"n", 1}}}}, opts).Decode(&doc)
+		require.NoError(b, err)
+
+		i++
+	}
+
+	setup.PushBenchmarkResult(b)
+}
@@ -168,6 +168,18 @@ func TestListDatabases(t *testing.T) {
 				}},
 			},
 		},
+		"ExistsAuthorizedDatabases": {
+			filter: bson.D{{Key: "name", Value: name}},
+			opts: []*options.ListDatabasesOptions{
+				options.ListDatabases().SetNameOnly(true).SetAuthorizedDatabases(true),
+			},
+			expectedNameOnly: true,
+			expected: mongo.ListDatabasesResult{
+				Databases: []mongo.DatabaseSpecification{{
+					Name: name,
+				}},
+			},
+		},
 		"Regex": {
 			filter: bson.D{
 				{Key: "name", Value: name},
@@ -199,7 +211,6 @@ func TestListDatabases(t *testing.T) {
 			expected: mongo.ListDatabasesResult{
 				Databases: []mongo.DatabaseSpecification{},
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB/issues/4862",
 		},
 		"RegexNotFound": {
 			filter: bson.D{
@@ -209,7 +220,6 @@ func TestListDatabases(t *testing.T) {
 			expected: mongo.ListDatabasesResult{
 				Databases: []mongo.DatabaseSpecification{},
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB/issues/4862",
 		},
 		"RegexNotFoundNameOnly": {
 			filter: bson.D{
@@ -374,6 +384,67 @@ func TestListCollectionsUUID(t *testing.T) {
 	AssertEqualDocuments(t, expected, res[0])
 }
 
+func TestListCollectionsNameOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	require.NoError(t, db.CreateCollection(ctx, collection.Name()))
+	require.NoError(t, db.CreateCollection(ctx, collection.Name()+"_capped", options.CreateCollection().SetCapped(true).SetSizeInBytes(1024)))
+	require.NoError(t, db.CreateView(ctx, collection.Name()+"_view", collection.Name(), bson.A{}))
+
+	for name, tc := range map[string]struct {
+		filter   bson.D
+		expected []bson.D
+	}{
+		"All": {
+			filter: bson.D{},
+			expected: []bson.D{
+				{{"name", collection.Name()}, {"type", "collection"}},
+				{{"name", collection.Name() + "_capped"}, {"type", "collection"}},
+				{{"name", collection.Name() + "_view"}, {"type", "view"}},
+			},
+		},
+		"NameRegex": {
+			filter: bson.D{{"name", primitive.Regex{Pattern: "_view$"}}},
+			expected: []bson.D{
+				{{"name", collection.Name() + "_view"}, {"type", "view"}},
+			},
+		},
+		"Type": {
+			filter: bson.D{{"type", "collection"}},
+			expected: []bson.D{
+				{{"name", collection.Name()}, {"type", "collection"}},
+				{{"name", collection.Name() + "_capped"}, {"type", "collection"}},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := db.ListCollections(
+				ctx, tc.filter,
+				options.ListCollections().SetNameOnly(true).SetAuthorizedCollections(true),
+			)
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+
+			assert.ElementsMatch(t, tc.expected, res)
+		})
+	}
+
+	t.Run("Options", func(t *testing.T) {
+		t.Parallel()
+
+		names, err := db.ListCollectionNames(ctx, bson.D{{"options.capped", true}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{collection.Name() + "_capped"}, names)
+	})
+}
+
 func TestGetParameterCommand(t *testing.T) {
 	t.Parallel()
 	s := setup.SetupWithOpts(t, &setup.SetupOpts{
@@ -849,6 +920,218 @@ func TestGetParameterCommandAuthenticationMechanisms(t *testing.T) {
 	})
 }
 
+func TestSetParameterCommand(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		DatabaseName: "admin",
+	})
+
+	db := s.Collection.Database()
+
+	t.Run("Set", func(t *testing.T) {
+		t.Parallel()
+
+		// use the default value to avoid affecting other tests
+		var actual bson.D
+		err := db.RunCommand(s.Ctx, bson.D{
+			{"setParameter", 1},
+			{"internalQueryMaxBlockingSortMemoryUsageBytes", int64(104857600)},
+		}).Decode(&actual)
+		require.NoError(t, err)
+
+		m := actual.Map()
+		assert.Equal(t, float64(1), m["ok"])
+		assert.EqualValues(t, 104857600, m["was"])
+	})
+
+	t.Run("Unrecognized", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(s.Ctx, bson.D{{"setParameter", 1}, {"nonExistentParameter", 1}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    72,
+			Name:    "InvalidOptions",
+			Message: "attempted to set unrecognized parameter [nonExistentParameter], use help:true to see options ",
+		}, err)
+	})
+
+	t.Run("NoParameters", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(s.Ctx, bson.D{{"setParameter", 1}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    72,
+			Name:    "InvalidOptions",
+			Message: "no option found to set, use help:true to see options ",
+		}, err)
+	})
+
+	t.Run("NotAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := s.Collection.Database().Client().Database("test").RunCommand(s.Ctx, bson.D{
+			{"setParameter", 1},
+			{"internalQueryMaxBlockingSortMemoryUsageBytes", int64(104857600)},
+		}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "setParameter may only be run against the admin database.",
+		}, err)
+	})
+}
+
+func TestSetFeatureCompatibilityVersionCommand(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		DatabaseName: "admin",
+	})
+
+	db := s.Collection.Database()
+
+	t.Run("Set", func(t *testing.T) {
+		t.Parallel()
+
+		// use the default value to avoid affecting other tests
+		var actual bson.D
+		err := db.RunCommand(s.Ctx, bson.D{
+			{"setFeatureCompatibilityVersion", "7.0"},
+			{"confirm", true},
+		}).Decode(&actual)
+		require.NoError(t, err)
+
+		assert.Equal(t, float64(1), actual.Map()["ok"])
+
+		err = db.RunCommand(s.Ctx, bson.D{{"getParameter", 1}, {"featureCompatibilityVersion", 1}}).Decode(&actual)
+		require.NoError(t, err)
+
+		assert.Equal(t, bson.D{{"version", "7.0"}}, actual.Map()["featureCompatibilityVersion"])
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(s.Ctx, bson.D{
+			{"setFeatureCompatibilityVersion", "5.0"},
+			{"confirm", true},
+		}).Err()
+		AssertMatchesCommandError(t, mongo.CommandError{
+			Code:    2,
+			Name:    "BadValue",
+			Message: "Invalid feature compatibility version value '5.0'; expected '6.0' or '7.0'.",
+		}, err)
+	})
+
+	t.Run("NoConfirm", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(s.Ctx, bson.D{{"setFeatureCompatibilityVersion", "7.0"}}).Err()
+		AssertMatchesCommandError(t, mongo.CommandError{
+			Code: 7369100,
+			Name: "Location7369100",
+			Message: "Once you have upgraded to 7.0, you will not be able to downgrade FCV and binary version " +
+				"without support assistance. Please re-run this command with 'confirm: true' to acknowledge this " +
+				"and continue with the FCV upgrade.",
+		}, err)
+	})
+
+	t.Run("NotAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := s.Collection.Database().Client().Database("test").RunCommand(s.Ctx, bson.D{
+			{"setFeatureCompatibilityVersion", "7.0"},
+			{"confirm", true},
+		}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "setFeatureCompatibilityVersion may only be run against the admin database.",
+		}, err)
+	})
+}
+
+func TestFerretExportSnapshotCommand(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific command")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}},
+		bson.D{{"_id", int32(2)}},
+		bson.D{{"_id", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	admin := collection.Database().Client().Database("admin")
+
+	sess, err := admin.Client().StartSession()
+	require.NoError(t, err)
+
+	defer sess.EndSession(ctx)
+
+	err = mongo.WithSession(ctx, sess, func(sctx mongo.SessionContext) error {
+		var res bson.D
+		err = admin.RunCommand(sctx, bson.D{
+			{"ferretExportSnapshot", int32(1)},
+			{"namespaces", bson.A{collection.Database().Name() + "." + collection.Name()}},
+			{"batchSize", int32(1)},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		m := res.Map()
+		require.Equal(t, float64(1), m["ok"])
+		assert.NotEmpty(t, m["snapshot"])
+
+		cursors, ok := m["cursors"].(bson.A)
+		require.True(t, ok)
+		require.Len(t, cursors, 1)
+
+		cursor := cursors[0].(bson.D).Map()
+		batch := cursor["firstBatch"].(bson.A)
+		id := cursor["id"].(int64)
+
+		// not visible in the snapshot
+		_, err = collection.InsertOne(ctx, bson.D{{"_id", int32(4)}})
+		require.NoError(t, err)
+
+		for id != 0 {
+			var more bson.D
+			err = collection.Database().RunCommand(sctx, bson.D{
+				{"getMore", id},
+				{"collection", collection.Name()},
+			}).Decode(&more)
+			require.NoError(t, err)
+
+			c := more.Map()["cursor"].(bson.D).Map()
+			batch = append(batch, c["nextBatch"].(bson.A)...)
+			id = c["id"].(int64)
+		}
+
+		assert.Len(t, batch, 3)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("NotAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{
+			{"ferretExportSnapshot", int32(1)},
+			{"namespaces", bson.A{"test.test"}},
+		}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "ferretExportSnapshot may only be run against the admin database.",
+		}, err)
+	})
+}
+
 func TestBuildInfoCommand(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
@@ -867,6 +1150,8 @@ func TestBuildInfoCommand(t *testing.T) {
 		case "ferretdb":
 			value, ok := field.Value.(bson.D)
 			require.True(t, ok)
+			value, capabilities := RemoveKey(t, value, "capabilities")
+			assert.IsType(t, bson.D{}, capabilities)
 			AssertEqualDocuments(t, bson.D{{"package", info.Package}, {"version", info.Version}}, value)
 
 		case "version":
@@ -1881,6 +2166,8 @@ func TestServerStatusCommand(t *testing.T) {
 		case "ferretdb":
 			ferretdb, buildEnvironment := RemoveKey(t, field.Value.(bson.D), "buildEnvironment")
 			assert.IsType(t, bson.D{}, buildEnvironment)
+			ferretdb, capabilities := RemoveKey(t, ferretdb, "capabilities")
+			assert.IsType(t, bson.D{}, capabilities)
 
 			expected := bson.D{
 				{"version", info.Version},
@@ -1889,9 +2176,17 @@ func TestServerStatusCommand(t *testing.T) {
 				{"package", info.Package},
 				{"postgresql", version.PostgreSQLTest},
 				{"documentdb", version.DocumentDB},
+				{"latestVersion", ""},
+				{"updateAvailable", false},
 			}
 			AssertEqualDocuments(t, expected, ferretdb)
 
+		case "encryptionAtRest":
+			// only FerretDB and MongoDB Enterprise report it
+			encryption, ok := field.Value.(bson.D)
+			require.True(t, ok)
+			assert.IsType(t, false, encryption.Map()["encryptionEnabled"])
+
 		case "freeMonitoring":
 			freeMonitoring, ok := field.Value.(bson.D)
 			require.True(t, ok)
@@ -2050,6 +2345,8 @@ func TestServerStatusCommandMetrics(t *testing.T) {
 				case "ferretdb":
 					f, buildEnvironment := RemoveKey(t, field.Value.(bson.D), "buildEnvironment")
 					assert.IsType(t, bson.D{}, buildEnvironment)
+					f, capabilities := RemoveKey(t, f, "capabilities")
+					assert.IsType(t, bson.D{}, capabilities)
 					actualComparable = append(actualComparable, bson.E{Key: field.Key, Value: f})
 
 				case "host":
@@ -2159,6 +2456,8 @@ func TestServerStatusCommandMetrics(t *testing.T) {
 					{"package", info.Package},
 					{"postgresql", version.PostgreSQLTest},
 					{"documentdb", version.DocumentDB},
+					{"latestVersion", ""},
+					{"updateAvailable", false},
 				}},
 				{"freeMonitoring", bson.D{{"state", "undecided"}}},
 				{"host", ""},
@@ -2219,6 +2518,8 @@ func TestServerStatusCommandFreeMonitoring(t *testing.T) {
 				case "ferretdb":
 					f, buildEnvironment := RemoveKey(t, field.Value.(bson.D), "buildEnvironment")
 					assert.IsType(t, bson.D{}, buildEnvironment)
+					f, capabilities := RemoveKey(t, f, "capabilities")
+					assert.IsType(t, bson.D{}, capabilities)
 					actualComparable = append(actualComparable, bson.E{Key: field.Key, Value: f})
 
 				case "host":
@@ -2341,6 +2642,8 @@ func TestServerStatusCommandFreeMonitoring(t *testing.T) {
 					{"package", info.Package},
 					{"postgresql", version.PostgreSQLTest},
 					{"documentdb", version.DocumentDB},
+					{"latestVersion", ""},
+					{"updateAvailable", false},
 				}},
 				{"freeMonitoring", bson.D{{"state", tc.expectedStatus}}},
 				{"host", ""},
@@ -2563,3 +2866,674 @@ func TestCompactCommandErrors(tt *testing.T) {
 		})
 	}
 }
+
+func TestFerretExportImportCommands(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific commands")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", "foo"}, {"n", int64(42)}},
+		bson.D{{"_id", int32(2)}, {"v", "bar"}, {"n", 4.2}},
+	})
+	require.NoError(t, err)
+
+	for _, format := range []string{"json", "csv"} {
+		var res bson.D
+		err = db.RunCommand(ctx, bson.D{
+			{"ferretExport", collection.Name()},
+			{"format", format},
+			{"fields", bson.A{"_id", "v", "n"}},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		m := res.Map()
+		require.Equal(t, int32(2), m["n"], format)
+
+		data, ok := m["data"].(string)
+		require.True(t, ok)
+
+		target := collection.Name() + "_" + format
+
+		err = db.RunCommand(ctx, bson.D{
+			{"ferretImport", target},
+			{"format", format},
+			{"data", data},
+			{"types", bson.D{{"_id", "int32"}}},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		m = res.Map()
+		assert.Equal(t, int32(2), m["n"], format)
+		assert.Equal(t, int32(0), m["failed"], format)
+
+		cursor, err := db.Collection(target).Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+		require.NoError(t, err)
+
+		var actual []bson.D
+		require.NoError(t, cursor.All(ctx, &actual))
+
+		expected := []bson.D{
+			{{"_id", int32(1)}, {"v", "foo"}, {"n", int64(42)}},
+			{{"_id", int32(2)}, {"v", "bar"}, {"n", 4.2}},
+		}
+		if format == "csv" {
+			// CSV loses types; small integers are detected as int32
+			expected[0][2].Value = int32(42)
+		}
+
+		assert.Equal(t, expected, actual, format)
+	}
+}
+
+func TestFerretMigrateCommand(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific commands")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	admin := collection.Database().Client().Database("admin")
+	ns := collection.Database().Name() + "." + collection.Name()
+
+	docs := make([]any, 10)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}, {"v", int32(i)}}
+	}
+
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	var res bson.D
+	err = admin.RunCommand(ctx, bson.D{
+		{"ferretMigrate", ns},
+		{"update", bson.A{bson.D{{"$set", bson.D{{"v", bson.D{{"$toString", "$v"}}}, {"migrated", true}}}}}},
+		{"filter", bson.D{{"_id", bson.D{{"$gte", int32(2)}}}}},
+		{"batchSize", int32(3)},
+		{"resumeAfter", int32(3)},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	id, ok := res.Map()["migrationId"].(int64)
+	require.True(t, ok)
+
+	var op bson.M
+
+	require.Eventually(t, func() bool {
+		err = admin.RunCommand(ctx, bson.D{{"currentOp", int32(1)}, {"$all", true}}).Decode(&res)
+		require.NoError(t, err)
+
+		for _, v := range res.Map()["inprog"].(bson.A) {
+			if m := v.(bson.D).Map(); m["migrationId"] == id {
+				op = m
+			}
+		}
+
+		return op != nil && op["state"] == "completed"
+	}, 10*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, ns, op["ns"])
+	assert.Equal(t, int32(9), op["lastId"])
+	assert.Equal(t, bson.D{{"batches", int64(2)}, {"done", int64(6)}, {"modified", int64(6)}}, op["progress"])
+
+	n, err := collection.CountDocuments(ctx, bson.D{{"migrated", true}, {"v", bson.D{{"$type", "string"}}}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), n)
+
+	err = admin.RunCommand(ctx, bson.D{{"ferretAbortMigration", id}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    20,
+		Name:    "IllegalOperation",
+		Message: fmt.Sprintf("migration %d is not running: completed", id),
+	}, err)
+}
+
+func TestCollectionCompression(t *testing.T) {
+	setup.SkipForMongoDB(t, "PostgreSQL-specific storage engine options")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+	name := collection.Name() + "_compression"
+
+	err := db.RunCommand(ctx, bson.D{
+		{"create", name},
+		{"storageEngine", bson.D{{"postgresql", bson.D{{"compression", "pglz"}}}}},
+	}).Err()
+	require.NoError(t, err)
+
+	compression := func() any {
+		var res bson.D
+		require.NoError(t, db.RunCommand(ctx, bson.D{{"collStats", name}}).Decode(&res))
+
+		return res.Map()["postgresql"].(bson.D).Map()["compression"]
+	}
+
+	assert.Equal(t, "pglz", compression())
+
+	err = db.RunCommand(ctx, bson.D{
+		{"collMod", name},
+		{"storageEngine", bson.D{{"postgresql", bson.D{{"compression", "default"}}}}},
+	}).Err()
+	require.NoError(t, err)
+
+	assert.Equal(t, "default", compression())
+
+	err = db.RunCommand(ctx, bson.D{
+		{"collMod", name},
+		{"storageEngine", bson.D{{"postgresql", bson.D{{"compression", "zstd"}}}}},
+	}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    2,
+		Name:    "BadValue",
+		Message: `invalid compression method zstd, expected one of ["default" "pglz" "lz4"]`,
+	}, err)
+}
+
+func TestDropConnectionsCommand(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		DatabaseName: "admin",
+	})
+
+	db := s.Collection.Database()
+
+	t.Run("UnknownHost", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := db.RunCommand(s.Ctx, bson.D{
+			{"dropConnections", 1},
+			{"hostAndPort", bson.A{"unknown.example.com:27017"}},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		AssertEqualDocuments(t, bson.D{{"ok", float64(1)}}, res)
+	})
+
+	t.Run("MissingHostAndPort", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(s.Ctx, bson.D{{"dropConnections", 1}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    40414,
+			Name:    "Location40414",
+			Message: "BSON field 'dropConnections.hostAndPort' is missing but a required field",
+		}, err)
+	})
+
+	t.Run("WrongType", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(s.Ctx, bson.D{{"dropConnections", 1}, {"hostAndPort", "localhost:27017"}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    14,
+			Name:    "TypeMismatch",
+			Message: "BSON field 'dropConnections.hostAndPort' is the wrong type 'string', expected type 'array'",
+		}, err)
+	})
+
+	t.Run("NotAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.Client().Database("test").RunCommand(s.Ctx, bson.D{
+			{"dropConnections", 1},
+			{"hostAndPort", bson.A{}},
+		}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "dropConnections may only be run against the admin database.",
+		}, err)
+	})
+}
+
+func TestShutdownCommand(t *testing.T) {
+	t.Parallel()
+
+	// successful shutdown is not tested as it would stop the server shared by all tests
+
+	ctx, collection := setup.Setup(t)
+
+	err := collection.Database().RunCommand(ctx, bson.D{{"shutdown", 1}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "shutdown may only be run against the admin database.",
+	}, err)
+}
+
+func TestLogRotateCommand(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	err := collection.Database().RunCommand(ctx, bson.D{{"logRotate", 1}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "logRotate may only be run against the admin database.",
+	}, err)
+}
+
+func TestFerretRefreshViewCommand(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific commands")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+	viewName := collection.Name() + "_totals"
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"k", "a"}, {"v", int32(1)}},
+		bson.D{{"_id", int32(2)}, {"k", "a"}, {"v", int32(2)}},
+		bson.D{{"_id", int32(3)}, {"k", "b"}, {"v", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	pipeline := bson.A{
+		bson.D{{"$group", bson.D{{"_id", "$k"}, {"total", bson.D{{"$sum", "$v"}}}}}},
+		bson.D{{"$merge", bson.D{{"into", viewName}, {"whenMatched", "replace"}}}},
+	}
+
+	var res bson.D
+	err = db.RunCommand(ctx, bson.D{
+		{"ferretRefreshView", viewName},
+		{"viewOn", collection.Name()},
+		{"pipeline", pipeline},
+	}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, db.Name()+"."+viewName, res.Map()["ns"])
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", int32(4)}, {"k", "b"}, {"v", int32(4)}})
+	require.NoError(t, err)
+
+	// the stored definition is used
+	err = db.RunCommand(ctx, bson.D{{"ferretRefreshView", viewName}}).Decode(&res)
+	require.NoError(t, err)
+
+	cursor, err := db.Collection(viewName).Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	require.NoError(t, err)
+
+	expected := []bson.D{
+		{{"_id", "a"}, {"total", int32(3)}},
+		{{"_id", "b"}, {"total", int32(7)}},
+	}
+	AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+
+	err = db.RunCommand(ctx, bson.D{{"ferretRefreshView", collection.Name()}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    26,
+		Name:    "NamespaceNotFound",
+		Message: fmt.Sprintf("no materialized view definition for %s.%s", db.Name(), collection.Name()),
+	}, err)
+
+	err = db.RunCommand(ctx, bson.D{
+		{"ferretRefreshView", viewName},
+		{"viewOn", collection.Name()},
+		{"pipeline", bson.A{bson.D{{"$match", bson.D{}}}}},
+	}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    2,
+		Name:    "BadValue",
+		Message: fmt.Sprintf("ferretRefreshView pipeline must end with $merge stage into '%s.%s'", db.Name(), viewName),
+	}, err)
+}
+
+func TestFerretAnalyzeCommand(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific commands")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"k", "a"}},
+		bson.D{{"_id", int32(2)}, {"k", "a"}},
+		bson.D{{"_id", int32(3)}, {"k", int32(42)}},
+	})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = db.RunCommand(ctx, bson.D{{"ferretAnalyze", collection.Name()}}).Decode(&res)
+	require.NoError(t, err)
+
+	m := res.Map()
+	assert.Equal(t, db.Name()+"."+collection.Name(), m["ns"])
+	assert.Equal(t, int64(3), m["sampleSize"])
+
+	fields, ok := m["fields"].(bson.A)
+	require.True(t, ok)
+	require.Len(t, fields, 2)
+
+	k := fields[1].(bson.D).Map()
+	assert.Equal(t, "k", k["path"])
+	assert.Equal(t, int64(2), k["distinct"])
+	assert.Equal(t, bson.D{{"int", int64(1)}, {"string", int64(2)}}, k["types"])
+
+	var stored bson.D
+	err = db.RunCommand(ctx, bson.D{{"ferretAnalyze", collection.Name()}, {"stored", true}}).Decode(&stored)
+	require.NoError(t, err)
+	assert.Equal(t, m["fields"], stored.Map()["fields"])
+
+	err = db.RunCommand(ctx, bson.D{{"ferretAnalyze", collection.Name()}, {"sampleSize", int32(0)}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    2,
+		Name:    "BadValue",
+		Message: "Invalid value for parameter sampleSize: 0 is not in range [1, 100000]",
+	}, err)
+}
+
+func TestFerretSetMaskingPolicyCommand(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific commands")
+
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx, collection := s.Ctx, s.Collection
+	db := collection.Database()
+	username, password := "maskinguser", "maskingpass"
+
+	_, err := collection.InsertOne(ctx, bson.D{
+		{"_id", int32(1)}, {"name", "a"}, {"ssn", "123-45-6789"}, {"email", "a@example.com"},
+	})
+	require.NoError(t, err)
+
+	_ = db.RunCommand(ctx, bson.D{{"dropUser", username}})
+
+	err = db.RunCommand(ctx, bson.D{
+		{"createUser", username},
+		{"roles", bson.A{bson.D{{"role", "readWriteAnyDatabase"}, {"db", "admin"}}}},
+		{"pwd", password},
+	}).Err()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = db.RunCommand(ctx, bson.D{{"dropUser", username}})
+	})
+
+	var res bson.D
+	err = db.RunCommand(ctx, bson.D{
+		{"ferretSetMaskingPolicy", collection.Name()},
+		{"rules", bson.A{
+			bson.D{{"role", "readWriteAnyDatabase"}, {"fields", bson.A{"ssn"}}},
+			bson.D{{"role", "readWriteAnyDatabase"}, {"fields", bson.A{"email"}}, {"action", "hash"}},
+		}},
+	}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, db.Name()+"."+collection.Name(), res.Map()["ns"])
+
+	opts := options.Client().ApplyURI(s.MongoDBURI).SetAuth(options.Credential{
+		AuthMechanism: "SCRAM-SHA-256",
+		AuthSource:    db.Name(),
+		Username:      username,
+		Password:      password,
+	})
+
+	client, err := mongo.Connect(ctx, opts)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, client.Disconnect(ctx))
+	})
+
+	userColl := client.Database(db.Name()).Collection(collection.Name())
+
+	var doc bson.D
+	err = userColl.FindOne(ctx, bson.D{{"_id", int32(1)}}).Decode(&doc)
+	require.NoError(t, err)
+
+	m := doc.Map()
+	assert.Equal(t, "a", m["name"])
+	assert.NotContains(t, m, "ssn")
+	assert.Len(t, m["email"], 64)
+	assert.NotEqual(t, "a@example.com", m["email"])
+
+	err = userColl.Database().RunCommand(ctx, bson.D{
+		{"find", collection.Name()},
+		{"projection", bson.D{{"copy", "$ssn"}}},
+	}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "projection expression for field 'copy' is not allowed for masked collections",
+	}, err)
+
+	cursor, err := userColl.Aggregate(ctx, bson.A{bson.D{{"$project", bson.D{{"copy", "$ssn"}}}}})
+	require.NoError(t, err)
+	AssertEqualDocumentsSlice(t, []bson.D{{{"_id", int32(1)}}}, FetchAll(t, ctx, cursor))
+
+	// masked values can't be revealed by matching, sorting, or updating documents
+	err = userColl.FindOne(ctx, bson.D{{"ssn", "123-45-6789"}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "filtering by masked field 'ssn' is not allowed",
+	}, err)
+
+	_, err = userColl.UpdateOne(ctx, bson.D{}, bson.D{{"$rename", bson.D{{"ssn", "copy"}}}})
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "update referencing masked field 'ssn' is not allowed",
+	}, err)
+
+	_, err = userColl.UpdateOne(ctx, bson.D{}, bson.A{bson.D{{"$set", bson.D{{"copy", "$ssn"}}}}})
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "update referencing masked field 'ssn' is not allowed",
+	}, err)
+
+	// the default user has other roles that do not mask fields
+	err = collection.FindOne(ctx, bson.D{{"_id", int32(1)}}).Decode(&doc)
+	require.NoError(t, err)
+	assert.Equal(t, "123-45-6789", doc.Map()["ssn"])
+
+	err = db.RunCommand(ctx, bson.D{{"ferretSetMaskingPolicy", collection.Name()}, {"rules", bson.A{}}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.A{}, res.Map()["rules"])
+}
+
+func TestFerretSetDocumentPolicyCommand(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific commands")
+
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx, collection := s.Ctx, s.Collection
+	db := collection.Database()
+	username, password := "tenanta", "tenantpass"
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"tenant", username}, {"v", int32(1)}},
+		bson.D{{"_id", int32(2)}, {"tenant", "tenantb"}, {"v", int32(2)}},
+	})
+	require.NoError(t, err)
+
+	_ = db.RunCommand(ctx, bson.D{{"dropUser", username}})
+
+	err = db.RunCommand(ctx, bson.D{
+		{"createUser", username},
+		{"roles", bson.A{bson.D{{"role", "readWriteAnyDatabase"}, {"db", "admin"}}}},
+		{"pwd", password},
+	}).Err()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = db.RunCommand(ctx, bson.D{{"dropUser", username}})
+	})
+
+	// created before the policy is set
+	viewName := collection.Name() + "_view"
+	require.NoError(t, db.CreateView(ctx, viewName, collection.Name(), bson.A{}))
+
+	err = db.RunCommand(ctx, bson.D{
+		{"ferretSetDocumentPolicy", collection.Name()},
+		{"rules", bson.A{bson.D{{"role", "readWriteAnyDatabase"}, {"filter", bson.D{{"tenant", "$$USER"}}}}}},
+	}).Err()
+	require.NoError(t, err)
+
+	err = db.CreateView(ctx, collection.Name()+"_view2", collection.Name(), bson.A{})
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "View can't read collection " + db.Name() + "." + collection.Name() + " with access policies",
+	}, err)
+
+	opts := options.Client().ApplyURI(s.MongoDBURI).SetAuth(options.Credential{
+		AuthMechanism: "SCRAM-SHA-256",
+		AuthSource:    db.Name(),
+		Username:      username,
+		Password:      password,
+	})
+
+	client, err := mongo.Connect(ctx, opts)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, client.Disconnect(ctx))
+	})
+
+	userColl := client.Database(db.Name()).Collection(collection.Name())
+
+	cursor, err := userColl.Find(ctx, bson.D{})
+	require.NoError(t, err)
+	AssertEqualDocumentsSlice(t, []bson.D{{{"_id", int32(1)}, {"tenant", username}, {"v", int32(1)}}}, FetchAll(t, ctx, cursor))
+
+	n, err := userColl.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+
+	cursor, err = userColl.Aggregate(ctx, bson.A{bson.D{{"$group", bson.D{{"_id", nil}, {"v", bson.D{{"$sum", "$v"}}}}}}})
+	require.NoError(t, err)
+	AssertEqualDocumentsSlice(t, []bson.D{{{"_id", nil}, {"v", int32(1)}}}, FetchAll(t, ctx, cursor))
+
+	// policies of collections read by nested pipelines are applied too
+	cursor, err = userColl.Aggregate(ctx, bson.A{
+		bson.D{{"$facet", bson.D{{"joined", bson.A{
+			bson.D{{"$lookup", bson.D{
+				{"from", collection.Name()},
+				{"pipeline", bson.A{bson.D{{"$project", bson.D{{"_id", 1}}}}}},
+				{"as", "docs"},
+			}}},
+			bson.D{{"$unionWith", bson.D{
+				{"coll", collection.Name()},
+				{"pipeline", bson.A{bson.D{{"$project", bson.D{{"docs", "$_id"}}}}}},
+			}}},
+			bson.D{{"$project", bson.D{{"_id", 0}, {"docs", 1}}}},
+		}}}}},
+	})
+	require.NoError(t, err)
+	AssertEqualDocumentsSlice(t, []bson.D{{{"joined", bson.A{
+		bson.D{{"docs", bson.A{bson.D{{"_id", int32(1)}}}}},
+		bson.D{{"docs", int32(1)}},
+	}}}}, FetchAll(t, ctx, cursor))
+
+	err = client.Database(db.Name()).Collection(viewName).FindOne(ctx, bson.D{}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "View " + db.Name() + "." + viewName + " reads collections with access policies",
+	}, err)
+
+	res, err := userColl.UpdateMany(ctx, bson.D{}, bson.D{{"$inc", bson.D{{"v", int32(10)}}}})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, res.MatchedCount)
+
+	del, err := userColl.DeleteMany(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, del.DeletedCount)
+
+	// documents of other tenants are not changed
+	var doc bson.D
+	err = collection.FindOne(ctx, bson.D{{"_id", int32(2)}}).Decode(&doc)
+	require.NoError(t, err)
+	AssertEqualDocuments(t, bson.D{{"_id", int32(2)}, {"tenant", "tenantb"}, {"v", int32(2)}}, doc)
+}
+
+func TestShardingCommands(t *testing.T) {
+	setup.SkipForMongoDB(t, "MongoDB is not a member of a sharded cluster in tests")
+
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		DatabaseName: "admin",
+	})
+
+	ctx, db := s.Ctx, s.Collection.Database()
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{{"listShards", 1}}).Decode(&res)
+	require.NoError(t, err)
+
+	shards, ok := res.Map()["shards"].(bson.A)
+	require.True(t, ok)
+	require.Len(t, shards, 1)
+	assert.Equal(t, "ferretdb", shards[0].(bson.D).Map()["_id"])
+
+	err = db.RunCommand(ctx, bson.D{{"getShardMap", 1}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Contains(t, res.Map()["map"].(bson.D).Map(), "ferretdb")
+
+	err = db.RunCommand(ctx, bson.D{{"shardingState", 1}}).Decode(&res)
+	require.NoError(t, err)
+	AssertEqualDocuments(t, bson.D{{"enabled", false}, {"ok", float64(1)}}, res)
+
+	err = db.RunCommand(ctx, bson.D{{"shardCollection", "test.test"}, {"key", bson.D{{"_id", "hashed"}}}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code: 238,
+		Name: "NotImplemented",
+		Message: "shardCollection is not supported: " +
+			"FerretDB stores all data in a single PostgreSQL database that acts as a single shard",
+	}, err)
+
+	err = db.Client().Database("test").RunCommand(ctx, bson.D{{"listShards", 1}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "listShards may only be run against the admin database.",
+	}, err)
+}
+
+func TestTopCommand(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(1)}})
+	require.NoError(t, err)
+
+	err = collection.FindOne(ctx, bson.D{}).Err()
+	require.NoError(t, err)
+
+	var res bson.D
+	err = collection.Database().Client().Database("admin").RunCommand(ctx, bson.D{{"top", int32(1)}}).Decode(&res)
+	require.NoError(t, err)
+
+	totals, ok := res.Map()["totals"].(bson.D)
+	require.True(t, ok)
+
+	ns, ok := totals.Map()[collection.Database().Name()+"."+collection.Name()].(bson.D)
+	require.True(t, ok)
+
+	m := ns.Map()
+	for _, f := range []string{"total", "readLock", "writeLock", "queries", "insert"} {
+		counter, ok := m[f].(bson.D)
+		require.True(t, ok, f)
+		assert.GreaterOrEqual(t, counter.Map()["count"], int64(1), f)
+	}
+
+	err = collection.Database().RunCommand(ctx, bson.D{{"top", int32(1)}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "top may only be run against the admin database.",
+	}, err)
+}
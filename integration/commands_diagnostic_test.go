@@ -198,7 +198,6 @@ func TestGetLogCommand(t *testing.T) {
 				Name:    "OperationFailed",
 				Message: `No log named 'nonExistentName'`,
 			},
-			altMessage: `no RecentEntries named: nonExistentName`,
 		},
 		"Nil": {
 			command: bson.D{{"getLog", nil}},
@@ -254,6 +253,17 @@ func TestGetLogCommand(t *testing.T) {
 			AssertEqualDocuments(t, tc.expectedComparable, resComparable)
 		})
 	}
+
+	t.Run("NotAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().Client().Database("test").RunCommand(ctx, bson.D{{"getLog", "global"}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "getLog may only be run against the admin database.",
+		}, err)
+	})
 }
 
 func TestHostInfoCommand(t *testing.T) {
@@ -285,7 +295,15 @@ func TestHostInfoCommand(t *testing.T) {
 					assert.IsType(t, int32(0), subElem.Value)
 					elemForComparsion = append(elemForComparsion, bson.E{subElem.Key, int32(0)})
 
-				case "numPhysicalCores", "numCpuSockets", "numNumaNodes", "numaEnabled", "memSizeMB", "memLimitMB":
+				case "memSizeMB", "memLimitMB":
+					// present only if known
+					assert.IsType(t, int64(0), subElem.Value)
+
+				case "numPhysicalCores", "numCpuSockets":
+					// present only if known
+					assert.IsType(t, int32(0), subElem.Value)
+
+				case "numNumaNodes", "numaEnabled", "numCoresAvailableToProcess":
 					// not implemented in FerretDB, do nothing
 					// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/587
 
@@ -341,6 +359,24 @@ func TestHostInfoCommand(t *testing.T) {
 	AssertEqualDocuments(t, expected, actualComparable)
 }
 
+func TestGetCmdLineOptsCommand(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	var res bson.D
+	err := collection.Database().Client().Database("admin").RunCommand(ctx, bson.D{{"getCmdLineOpts", 1}}).Decode(&res)
+	require.NoError(t, err)
+
+	m := res.Map()
+
+	argv, ok := m["argv"].(bson.A)
+	require.True(t, ok)
+	assert.NotEmpty(t, argv)
+
+	assert.IsType(t, bson.D{}, m["parsed"])
+	assert.Equal(t, float64(1), m["ok"])
+}
+
 func TestListCommandsCommand(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
@@ -0,0 +1,300 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consistency
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/testutil/teststress"
+
+	"github.com/FerretDB/FerretDB/v2/integration/setup"
+)
+
+const (
+	// workers is the number of concurrent writers.
+	workers = 10
+
+	// ops is the number of operations done by each writer.
+	ops = 50
+)
+
+// history contains results of operations done by each writer, in order.
+type history[T any] struct {
+	next    atomic.Int32
+	workers [workers][]T
+}
+
+// run runs f ops times in each of concurrent writers, recording results.
+func (h *history[T]) run(t *testing.T, f func(worker, op int) T) {
+	t.Helper()
+
+	teststress.StressN(t, workers, func(ready chan<- struct{}, start <-chan struct{}) {
+		w := int(h.next.Add(1) - 1)
+
+		ready <- struct{}{}
+		<-start
+
+		for op := range ops {
+			h.workers[w] = append(h.workers[w], f(w, op))
+		}
+	})
+}
+
+// all returns all results.
+func (h *history[T]) all() []T {
+	var res []T
+	for _, w := range h.workers {
+		res = append(res, w...)
+	}
+
+	return res
+}
+
+// TestConsistencyCounter increments a single counter with findAndModify.
+//
+// Each returned value should be unique (no lost updates),
+// values returned to a single writer should increase (monotonicity),
+// and the final value should be equal to the number of increments.
+func TestConsistencyCounter(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "counter"}, {"v", int64(0)}})
+	require.NoError(t, err)
+
+	var h history[int64]
+
+	h.run(t, func(int, int) int64 {
+		var doc struct {
+			V int64 `bson:"v"`
+		}
+
+		err := collection.FindOneAndUpdate(
+			ctx,
+			bson.D{{"_id", "counter"}},
+			bson.D{{"$inc", bson.D{{"v", int64(1)}}}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&doc)
+		require.NoError(t, err)
+
+		return doc.V
+	})
+
+	for w, values := range h.workers {
+		assert.True(t, slices.IsSorted(values), "writer %d observed non-monotonic values: %v", w, values)
+	}
+
+	all := h.all()
+	slices.Sort(all)
+
+	expected := make([]int64, workers*ops)
+	for i := range expected {
+		expected[i] = int64(i + 1)
+	}
+
+	assert.Equal(t, expected, all, "each increment should return a unique value")
+
+	var doc bson.D
+	require.NoError(t, collection.FindOne(ctx, bson.D{{"_id", "counter"}}).Decode(&doc))
+	assert.Equal(t, bson.D{{"_id", "counter"}, {"v", int64(workers * ops)}}, doc)
+}
+
+// TestConsistencyUpdate increments and appends to random documents with update.
+//
+// Final documents should contain all increments and appended values (no lost updates).
+func TestConsistencyUpdate(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	const docs = 5
+
+	for i := range docs {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(i)}, {"n", int32(0)}, {"ops", bson.A{}}})
+		require.NoError(t, err)
+	}
+
+	type op struct {
+		doc int32
+		id  string
+	}
+
+	var h history[op]
+
+	h.run(t, func(w, i int) op {
+		o := op{
+			doc: rand.Int32N(docs),
+			id:  fmt.Sprintf("%d-%d", w, i),
+		}
+
+		res, err := collection.UpdateOne(
+			ctx,
+			bson.D{{"_id", o.doc}},
+			bson.D{
+				{"$inc", bson.D{{"n", int32(1)}}},
+				{"$push", bson.D{{"ops", o.id}}},
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), res.ModifiedCount)
+
+		return o
+	})
+
+	expectedN := make(map[int32]int32, docs)
+	expectedOps := make(map[int32][]string, docs)
+
+	for _, o := range h.all() {
+		expectedN[o.doc]++
+		expectedOps[o.doc] = append(expectedOps[o.doc], o.id)
+	}
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", int32(1)}}))
+	require.NoError(t, err)
+
+	var res []struct {
+		ID  int32    `bson:"_id"`
+		N   int32    `bson:"n"`
+		Ops []string `bson:"ops"`
+	}
+	require.NoError(t, cursor.All(ctx, &res))
+	require.Len(t, res, docs)
+
+	for _, doc := range res {
+		assert.Equal(t, expectedN[doc.ID], doc.N, "document %d", doc.ID)
+		assert.ElementsMatch(t, expectedOps[doc.ID], doc.Ops, "document %d", doc.ID)
+
+		// values appended by a single writer should be in order
+		last := make(map[int]int)
+
+		for _, id := range doc.Ops {
+			var w, i int
+			_, err = fmt.Sscanf(id, "%d-%d", &w, &i)
+			require.NoError(t, err)
+
+			if prev, ok := last[w]; ok {
+				assert.Greater(t, i, prev, "document %d: %v", doc.ID, doc.Ops)
+			}
+
+			last[w] = i
+		}
+	}
+}
+
+// TestConsistencyUpsert upserts the same documents from all writers.
+//
+// A single upsert per document should insert it, and all upserts should be applied.
+// Duplicate key errors are retried by writers, as applications do.
+func TestConsistencyUpsert(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		field string
+		index bool
+	}{
+		"ID":          {field: "_id"},
+		"UniqueIndex": {field: "k", index: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, collection := setup.Setup(t)
+
+			if tc.index {
+				_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{tc.field, int32(1)}},
+					Options: options.Index().SetUnique(true),
+				})
+				require.NoError(t, err)
+			}
+
+			const keys = 10
+
+			type op struct {
+				key      int32
+				inserted bool
+			}
+
+			var h history[op]
+			var retries atomic.Int32
+
+			h.run(t, func(int, int) op {
+				o := op{key: rand.Int32N(keys)}
+
+				for {
+					res, err := collection.UpdateOne(
+						ctx,
+						bson.D{{tc.field, o.key}},
+						bson.D{{"$inc", bson.D{{"n", int32(1)}}}},
+						options.Update().SetUpsert(true),
+					)
+
+					if mongo.IsDuplicateKeyError(err) {
+						retries.Add(1)
+						continue
+					}
+
+					require.NoError(t, err)
+
+					o.inserted = res.UpsertedCount == 1
+
+					return o
+				}
+			})
+
+			t.Logf("Duplicate key errors retried: %d", retries.Load())
+
+			inserted := make(map[int32]int)
+			upserts := make(map[int32]int32)
+
+			for _, o := range h.all() {
+				upserts[o.key]++
+
+				if o.inserted {
+					inserted[o.key]++
+				}
+			}
+
+			for key, n := range upserts {
+				assert.Equal(t, 1, inserted[key], "key %d should be inserted once", key)
+
+				count, err := collection.CountDocuments(ctx, bson.D{{tc.field, key}})
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), count, "key %d", key)
+
+				var doc struct {
+					N int32 `bson:"n"`
+				}
+				require.NoError(t, collection.FindOne(ctx, bson.D{{tc.field, key}}).Decode(&doc))
+				assert.Equal(t, n, doc.N, "key %d", key)
+			}
+
+			count, err := collection.CountDocuments(ctx, bson.D{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(upserts)), count)
+		})
+	}
+}
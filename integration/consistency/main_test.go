@@ -0,0 +1,31 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package consistency contains tests that run concurrent writers and check invariants of the result:
+//   - counter monotonicity for findAndModify;
+//   - no lost updates for update;
+//   - a single insert for concurrent upserts.
+//
+// They look for anomalies introduced by translating MongoDB commands into PostgreSQL transactions.
+package consistency
+
+import (
+	"testing"
+
+	"github.com/FerretDB/FerretDB/v2/integration/setup"
+)
+
+func TestMain(m *testing.M) {
+	setup.Main(m)
+}
@@ -17,8 +17,10 @@
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/v2/integration/setup"
 	"github.com/FerretDB/FerretDB/v2/integration/shareddata"
@@ -59,3 +61,140 @@ func TestCreateCompat(t *testing.T) {
 	require.Equal(t, targetErr, compatErr)
 	require.Equal(t, targetNames, compatNames)
 }
+
+// TestCreateViewCompat tests views created with `viewOn` and `pipeline` options.
+func TestCreateViewCompat(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.Int32s},
+	})
+
+	targetCollection, compatCollection := s.TargetCollections[0], s.CompatCollections[0]
+	targetDB, compatDB := targetCollection.Database(), compatCollection.Database()
+
+	viewName := targetCollection.Name() + "_view"
+	pipeline := bson.A{
+		bson.D{{"$match", bson.D{{"v", bson.D{{"$gt", int32(0)}}}}}},
+		bson.D{{"$sort", bson.D{{"_id", 1}}}},
+	}
+
+	targetErr := targetDB.CreateView(s.Ctx, viewName, targetCollection.Name(), pipeline)
+	compatErr := compatDB.CreateView(s.Ctx, viewName, compatCollection.Name(), pipeline)
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+
+	t.Run("ListCollections", func(t *testing.T) {
+		t.Parallel()
+
+		filter := bson.D{{"name", viewName}}
+
+		targetSpecs, targetErr := targetDB.ListCollectionSpecifications(s.Ctx, filter)
+		compatSpecs, compatErr := compatDB.ListCollectionSpecifications(s.Ctx, filter)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+
+		require.Len(t, compatSpecs, 1)
+		require.Len(t, targetSpecs, 1)
+		assert.Equal(t, compatSpecs[0].Type, targetSpecs[0].Type)
+		assert.Equal(t, "view", targetSpecs[0].Type)
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		t.Parallel()
+
+		opts := options.Find().SetSort(bson.D{{"_id", 1}})
+		filter := bson.D{{"v", bson.D{{"$lt", int32(100)}}}}
+
+		targetCursor, targetErr := targetDB.Collection(viewName).Find(s.Ctx, filter, opts)
+		compatCursor, compatErr := compatDB.Collection(viewName).Find(s.Ctx, filter, opts)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+
+		targetRes := FetchAll(t, s.Ctx, targetCursor)
+		compatRes := FetchAll(t, s.Ctx, compatCursor)
+		AssertEqualDocumentsSlice(t, compatRes, targetRes)
+	})
+
+	t.Run("Insert", func(t *testing.T) {
+		t.Parallel()
+
+		doc := bson.D{{"_id", "view"}}
+
+		_, targetErr := targetDB.Collection(viewName).InsertOne(s.Ctx, doc)
+		_, compatErr := compatDB.Collection(viewName).InsertOne(s.Ctx, doc)
+		require.Error(t, compatErr)
+		AssertMatchesWriteError(t, compatErr, targetErr)
+	})
+}
+
+// TestCreateCollationCompat tests the default collation of the collection set by `create` command.
+func TestCreateCollationCompat(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers:                []shareddata.Provider{},
+		AddNonExistentCollection: true,
+	})
+
+	targetDB := s.TargetCollections[0].Database()
+	compatDB := s.CompatCollections[0].Database()
+	collName := s.TargetCollections[0].Name() + "_collation"
+
+	opts := options.CreateCollection().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+
+	targetErr := targetDB.CreateCollection(s.Ctx, collName, opts)
+	compatErr := compatDB.CreateCollection(s.Ctx, collName, opts)
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+
+	docs := []any{
+		bson.D{{"_id", int32(1)}, {"name", "Alice"}},
+		bson.D{{"_id", int32(2)}, {"name", "alice"}},
+		bson.D{{"_id", int32(3)}, {"name", "Bob"}},
+	}
+
+	_, targetErr = targetDB.Collection(collName).InsertMany(s.Ctx, docs)
+	_, compatErr = compatDB.Collection(collName).InsertMany(s.Ctx, docs)
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+
+	t.Run("Find", func(t *testing.T) {
+		t.Parallel()
+
+		filter := bson.D{{"name", "ALICE"}}
+		findOpts := options.Find().SetSort(bson.D{{"_id", 1}})
+
+		targetCursor, targetErr := targetDB.Collection(collName).Find(s.Ctx, filter, findOpts)
+		compatCursor, compatErr := compatDB.Collection(collName).Find(s.Ctx, filter, findOpts)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+
+		compatRes := FetchAll(t, s.Ctx, compatCursor)
+		require.Len(t, compatRes, 2)
+		AssertEqualDocumentsSlice(t, compatRes, FetchAll(t, s.Ctx, targetCursor))
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		t.Parallel()
+
+		filter := bson.D{{"name", "bob"}}
+
+		targetCount, targetErr := targetDB.Collection(collName).CountDocuments(s.Ctx, filter)
+		compatCount, compatErr := compatDB.Collection(collName).CountDocuments(s.Ctx, filter)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+		assert.Equal(t, compatCount, targetCount)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		t.Parallel()
+
+		otherOpts := options.CreateCollection().SetCollation(&options.Collation{Locale: "fr"})
+
+		targetErr := targetDB.CreateCollection(s.Ctx, collName, otherOpts)
+		compatErr := compatDB.CreateCollection(s.Ctx, collName, otherOpts)
+		require.Error(t, compatErr)
+		AssertMatchesCommandError(t, compatErr, targetErr)
+	})
+}
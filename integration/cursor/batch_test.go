@@ -246,6 +246,82 @@ func TestFindCommandSingleBatch(t *testing.T) {
 	}
 }
 
+func TestFindCommandBoolOptions(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	arr := integration.GenerateDocuments(0, 5)
+	_, err := collection.InsertMany(ctx, arr)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		option string
+		value  any
+
+		err *mongo.CommandError // optional, expected error
+	}{
+		"NoCursorTimeout": {
+			option: "noCursorTimeout",
+			value:  true,
+		},
+		"NoCursorTimeoutInt": {
+			option: "noCursorTimeout",
+			value:  int32(1),
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "Field 'noCursorTimeout' should be a boolean value, but found: int",
+			},
+		},
+		"AllowPartialResults": {
+			option: "allowPartialResults",
+			value:  true,
+		},
+		"AllowPartialResultsString": {
+			option: "allowPartialResults",
+			value:  "true",
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "Field 'allowPartialResults' should be a boolean value, but found: string",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, bson.D{
+				{"find", collection.Name()},
+				{"batchSize", int32(2)},
+				{tc.option, tc.value},
+			}).Decode(&res)
+
+			if tc.err != nil {
+				assert.Nil(t, res)
+				integration.AssertEqualCommandError(t, *tc.err, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			cursor, ok := res.Map()["cursor"].(bson.D)
+			require.True(t, ok)
+
+			cursorID, ok := cursor.Map()["id"].(int64)
+			require.True(t, ok)
+			assert.NotZero(t, cursorID)
+
+			err = collection.Database().RunCommand(ctx, bson.D{
+				{"killCursors", collection.Name()},
+				{"cursors", bson.A{cursorID}},
+			}).Err()
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestFindCommandExhausted(tt *testing.T) {
 	tt.Parallel()
 
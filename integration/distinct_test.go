@@ -16,6 +16,7 @@
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -187,3 +188,27 @@ func TestDistinctDuplicatesDecimal128(t *testing.T) {
 
 	assert.Equal(t, expected, distinct)
 }
+
+func TestDistinctTooBig(t *testing.T) {
+	t.Parallel()
+
+	ctx, coll := setup.Setup(t)
+
+	// 17 distinct values of 1 MiB each exceed the maximum document size
+	docs := make([]any, 17)
+	for i := range docs {
+		docs[i] = bson.D{{"v", strings.Repeat(strconv.Itoa(i%10), 1024*1024-i)}}
+	}
+
+	_, err := coll.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	res := coll.Database().RunCommand(ctx, bson.D{{"distinct", coll.Name()}, {"key", "v"}})
+
+	expected := mongo.CommandError{
+		Code:    17217,
+		Name:    "Location17217",
+		Message: "distinct too big, 16mb cap",
+	}
+	AssertEqualCommandError(t, expected, res.Err())
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dumprestore
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/v2/integration"
+	"github.com/FerretDB/FerretDB/v2/integration/setup"
+	"github.com/FerretDB/FerretDB/v2/integration/shareddata"
+)
+
+func TestDumpRestoreCompat(t *testing.T) {
+	t.Parallel()
+
+	mongodump := lookPath(t, "mongodump")
+	mongorestore := lookPath(t, "mongorestore")
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{
+			shareddata.Scalars,
+			shareddata.Composites,
+			shareddata.DocumentsDocuments,
+		},
+	})
+
+	ctx := s.Ctx
+
+	for i := range s.TargetCollections {
+		targetCollection := s.TargetCollections[i]
+		compatCollection := s.CompatCollections[i]
+
+		t.Run(targetCollection.Name(), func(t *testing.T) {
+			t.Parallel()
+
+			// secondary indexes check index metadata fidelity
+			for _, c := range []*mongo.Collection{targetCollection, compatCollection} {
+				_, err := c.Indexes().CreateMany(ctx, []mongo.IndexModel{
+					{Keys: bson.D{{"v", 1}}},
+					{Keys: bson.D{{"v", -1}, {"_id", 1}}, Options: options.Index().SetName("custom")},
+				})
+				require.NoError(t, err)
+			}
+
+			targetIndexes, targetDocs := dumpRestore(t, ctx, mongodump, mongorestore, s.TargetURI, targetCollection)
+			compatIndexes, compatDocs := dumpRestore(t, ctx, mongodump, mongorestore, s.CompatURI, compatCollection)
+
+			assert.Equal(t, compatIndexes, targetIndexes, "dumped index metadata")
+			integration.AssertEqualDocumentsSlice(t, compatDocs, targetDocs)
+			integration.AssertEqualDocumentsSlice(t, integration.FindAll(t, ctx, compatCollection), compatDocs)
+		})
+	}
+}
+
+// lookPath returns the path of the given tool or skips the test if it is not available.
+func lookPath(tb testing.TB, tool string) string {
+	tb.Helper()
+
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		tb.Skipf("%s is not available: %s", tool, err)
+	}
+
+	return path
+}
+
+// run runs the given tool and fails the test on error.
+func run(tb testing.TB, ctx context.Context, tool string, args ...string) {
+	tb.Helper()
+
+	out, err := exec.CommandContext(ctx, tool, args...).CombinedOutput()
+	require.NoError(tb, err, "%s", out)
+}
+
+// dumpRestore dumps the given collection with mongodump, restores it into a new collection with mongorestore,
+// and returns dumped indexes (without namespaces and versions) and restored documents sorted by _id.
+func dumpRestore(tb testing.TB, ctx context.Context, mongodump, mongorestore, uri string, c *mongo.Collection) ([]string, []bson.D) { //nolint:lll // for readability
+	tb.Helper()
+
+	dir := tb.TempDir()
+	db := c.Database().Name()
+	ns := db + "." + c.Name()
+
+	run(tb, ctx, mongodump, "--uri="+uri, "--db="+db, "--collection="+c.Name(), "--out="+dir)
+
+	b, err := os.ReadFile(filepath.Join(dir, db, c.Name()+".metadata.json"))
+	require.NoError(tb, err)
+
+	var md struct {
+		Indexes []bson.D `bson:"indexes"`
+	}
+	require.NoError(tb, bson.UnmarshalExtJSON(b, true, &md))
+
+	indexes := make([]string, 0, len(md.Indexes))
+
+	for _, index := range md.Indexes {
+		index = slices.DeleteFunc(index, func(e bson.E) bool { return e.Key == "ns" || e.Key == "v" })
+
+		b, err = bson.MarshalExtJSON(index, true, false)
+		require.NoError(tb, err)
+
+		indexes = append(indexes, string(b))
+	}
+
+	slices.Sort(indexes)
+
+	restored := c.Name() + "_restored"
+
+	run(
+		tb, ctx, mongorestore, "--uri="+uri, "--drop",
+		"--nsInclude="+ns, "--nsFrom="+ns, "--nsTo="+db+"."+restored, dir,
+	)
+
+	return indexes, integration.FindAll(tb, ctx, c.Database().Collection(restored))
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/v2/integration/setup"
+)
+
+// setupFailPoint configures the fail point for a new client with the application name unique for the test,
+// so other tests running against the same server are not affected.
+// It returns the collection of that client.
+func setupFailPoint(t *testing.T, name string, mode any, data bson.D) (context.Context, *mongo.Collection) {
+	t.Helper()
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx, admin := s.Ctx, s.Collection.Database().Client().Database("admin")
+
+	appName := strings.ReplaceAll(t.Name(), "/", "_")
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(s.MongoDBURI).SetAppName(appName))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, client.Disconnect(ctx))
+	})
+
+	data = append(data, bson.E{"appName", appName})
+
+	err = admin.RunCommand(ctx, bson.D{{"configureFailPoint", name}, {"mode", mode}, {"data", data}}).Err()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		err := admin.RunCommand(ctx, bson.D{{"configureFailPoint", name}, {"mode", "off"}}).Err()
+		require.NoError(t, err)
+	})
+
+	return ctx, client.Database(s.Collection.Database().Name()).Collection(s.Collection.Name())
+}
+
+func TestFailPointFailCommandErrorCode(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setupFailPoint(t, "failCommand", bson.D{{"times", int32(1)}}, bson.D{
+		{"failCommands", bson.A{"find"}},
+		{"errorCode", int32(2)},
+	})
+
+	err := collection.FindOne(ctx, bson.D{}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    2,
+		Name:    "BadValue",
+		Message: "Failing command via 'failCommand' failpoint",
+	}, err)
+
+	err = collection.FindOne(ctx, bson.D{}).Err()
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+}
+
+func TestFailPointFailCommandSkip(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setupFailPoint(t, "failCommand", bson.D{{"skip", int32(1)}}, bson.D{
+		{"failCommands", bson.A{"count"}},
+		{"errorCode", int32(2)},
+	})
+
+	_, err := collection.EstimatedDocumentCount(ctx)
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err = collection.EstimatedDocumentCount(ctx)
+
+		var ce mongo.CommandError
+		require.ErrorAs(t, err, &ce)
+		assert.Equal(t, int32(2), ce.Code)
+	}
+}
+
+func TestFailPointFailCommandCloseConnection(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setupFailPoint(t, "failCommand", bson.D{{"times", int32(1)}}, bson.D{
+		{"failCommands", bson.A{"find"}},
+		{"closeConnection", true},
+	})
+
+	err := collection.FindOne(ctx, bson.D{}).Err()
+	require.Error(t, err)
+	assert.True(t, mongo.IsNetworkError(err), "%v", err)
+
+	err = collection.FindOne(ctx, bson.D{}).Err()
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+}
+
+func TestFailPointFailCommandBlockConnection(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setupFailPoint(t, "failCommand", bson.D{{"times", int32(1)}}, bson.D{
+		{"failCommands", bson.A{"find"}},
+		{"blockConnection", true},
+		{"blockTimeMS", int32(200)},
+	})
+
+	start := time.Now()
+
+	err := collection.FindOne(ctx, bson.D{}).Err()
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestFailPointFerretBackendError(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific fail point")
+
+	t.Parallel()
+
+	ctx, collection := setupFailPoint(t, "ferretBackendError", bson.D{{"times", int32(1)}}, bson.D{
+		{"failCommands", bson.A{"insert"}},
+		{"sqlState", "40001"},
+	})
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "a"}})
+
+	var ce mongo.CommandError
+	require.ErrorAs(t, err, &ce)
+	assert.Equal(t, int32(112), ce.Code)
+	assert.Equal(t, "WriteConflict", ce.Name)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "a"}})
+	require.NoError(t, err)
+}
+
+func TestFailPointErrors(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{DatabaseName: "admin"})
+	ctx, db := s.Ctx, s.Collection.Database()
+
+	err := db.RunCommand(ctx, bson.D{{"configureFailPoint", "noSuchFailPoint"}, {"mode", "off"}}).Err()
+
+	var ce mongo.CommandError
+	require.ErrorAs(t, err, &ce)
+
+	err = db.Client().Database(s.Collection.Database().Name()+"_other").RunCommand(
+		ctx, bson.D{{"configureFailPoint", "failCommand"}, {"mode", "off"}},
+	).Err()
+	require.ErrorAs(t, err, &ce)
+	assert.Equal(t, int32(13), ce.Code)
+}
@@ -0,0 +1,291 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faults
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/FerretDB/wire"
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/FerretDB/wire/wireclient"
+	"github.com/jackc/pgerrcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+	"github.com/FerretDB/FerretDB/v2/internal/util/testutil/testfaults"
+
+	"github.com/FerretDB/FerretDB/v2/integration/setup"
+)
+
+// Error codes returned to clients.
+const (
+	internalError = 1   // broken PostgreSQL connection
+	writeConflict = 112 // serialization failure or deadlock
+)
+
+// setupFaults setups in-process FerretDB connected to PostgreSQL via a fault-injecting proxy.
+func setupFaults(t *testing.T) (*setup.SetupResult, *testfaults.Proxy) {
+	t.Helper()
+
+	p := setup.NewFaults(t)
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		ListenerOpts: &setup.ListenerOpts{Faults: p},
+		WireConn:     setup.WireConnAuth,
+	})
+
+	return s, p
+}
+
+// retry calls f until it succeeds, checking that all failures have one of the given error codes.
+// It returns the number of failed attempts.
+func retry(t *testing.T, f func() error, codes ...int) int {
+	t.Helper()
+
+	for i := range 10 {
+		err := f()
+		if err == nil {
+			return i
+		}
+
+		var se mongo.ServerError
+		require.True(t, errors.As(err, &se), "%v", err)
+		require.True(t, slices.ContainsFunc(codes, se.HasErrorCode), "%v", err)
+	}
+
+	t.Fatal("too many failed attempts")
+
+	panic("not reached")
+}
+
+// request sends a command via wire connection and returns the response.
+func request(t *testing.T, ctx context.Context, conn *wireclient.Conn, pairs ...any) *wirebson.Document {
+	t.Helper()
+
+	_, resBody, err := conn.Request(ctx, wire.MustOpMsg(pairs...))
+	require.NoError(t, err)
+
+	res, err := must.NotFail(resBody.(*wire.OpMsg).RawDocument()).DecodeDeep()
+	require.NoError(t, err)
+
+	return res
+}
+
+func TestFaultsSerializationFailure(t *testing.T) {
+	t.Parallel()
+
+	s, p := setupFaults(t)
+	ctx, collection := s.Ctx, s.Collection
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "a"}})
+	require.NoError(t, err)
+
+	p.FailNext(1, pgerrcode.SerializationFailure)
+
+	failed := retry(t, func() error {
+		_, err = collection.UpdateOne(ctx, bson.D{{"_id", "a"}}, bson.D{{"$inc", bson.D{{"v", int32(1)}}}})
+		return err
+	}, writeConflict)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, int64(1), p.Stats().Failed)
+
+	p.FailNext(1, pgerrcode.DeadlockDetected)
+
+	failed = retry(t, func() error {
+		_, err = collection.UpdateOne(ctx, bson.D{{"_id", "a"}}, bson.D{{"$inc", bson.D{{"v", int32(1)}}}})
+		return err
+	}, writeConflict)
+	assert.Equal(t, 1, failed)
+
+	var doc bson.D
+	require.NoError(t, collection.FindOne(ctx, bson.D{{"_id", "a"}}).Decode(&doc))
+
+	// failed updates were not applied
+	assert.Equal(t, bson.D{{"_id", "a"}, {"v", int32(2)}}, doc)
+}
+
+func TestFaultsLatency(t *testing.T) {
+	t.Parallel()
+
+	s, p := setupFaults(t)
+	ctx, collection := s.Ctx, s.Collection
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "a"}})
+	require.NoError(t, err)
+
+	p.SetLatency(100 * time.Millisecond)
+
+	start := time.Now()
+	_, err = collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	p.SetLatency(3 * time.Second)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	_, err = collection.CountDocuments(timeoutCtx, bson.D{})
+	require.Error(t, err)
+	assert.True(t, mongo.IsTimeout(err), "%v", err)
+
+	p.SetLatency(0)
+
+	// the client connection was closed on timeout, but FerretDB recovers
+	n, err := collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	assert.Positive(t, p.Stats().Delayed)
+}
+
+func TestFaultsDropConnections(t *testing.T) {
+	t.Parallel()
+
+	s, p := setupFaults(t)
+	ctx, collection := s.Ctx, s.Collection
+
+	_, err := collection.InsertMany(ctx, []any{bson.D{{"_id", "a"}}, bson.D{{"_id", "b"}}})
+	require.NoError(t, err)
+
+	require.Positive(t, p.DropConnections())
+
+	// idle PostgreSQL connections are broken; each of them fails a single attempt
+	retry(t, func() error {
+		_, err = collection.UpdateOne(
+			ctx,
+			bson.D{{"_id", "c"}},
+			bson.D{{"$set", bson.D{{"v", int32(1)}}}},
+			options.Update().SetUpsert(true),
+		)
+
+		return err
+	}, internalError)
+
+	n, err := collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+}
+
+func TestFaultsSchedule(t *testing.T) {
+	t.Parallel()
+
+	s, p := setupFaults(t)
+	ctx, collection := s.Ctx, s.Collection
+
+	p.Schedule(
+		ctx,
+		testfaults.Step{After: 50 * time.Millisecond, Do: func(p *testfaults.Proxy) { p.SetLatency(10 * time.Millisecond) }},
+		testfaults.Step{After: 100 * time.Millisecond, Do: func(p *testfaults.Proxy) { p.DropConnections() }},
+		testfaults.Step{After: 150 * time.Millisecond, Do: func(p *testfaults.Proxy) {
+			p.FailNext(1, pgerrcode.SerializationFailure)
+		}},
+		testfaults.Step{After: 200 * time.Millisecond, Do: func(p *testfaults.Proxy) { p.SetLatency(0) }},
+	)
+
+	// upserts are idempotent, so they could be retried regardless of the fault
+	for i := range 30 {
+		retry(t, func() error {
+			_, err := collection.UpdateOne(
+				ctx,
+				bson.D{{"_id", int32(i)}},
+				bson.D{{"$set", bson.D{{"v", int32(i)}}}},
+				options.Update().SetUpsert(true),
+			)
+
+			return err
+		}, internalError, writeConflict)
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	n, err := collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), n)
+}
+
+func TestFaultsCursor(t *testing.T) {
+	t.Parallel()
+
+	s, p := setupFaults(t)
+	ctx, collection, conn := s.Ctx, s.Collection, s.WireConn
+	db, coll := collection.Database().Name(), collection.Name()
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "a"}},
+		bson.D{{"_id", "b"}},
+		bson.D{{"_id", "c"}},
+	})
+	require.NoError(t, err)
+
+	res := request(t, ctx, conn, "startSession", int32(1), "$db", "admin")
+	lsid := wirebson.MustDocument("id", res.Get("id").(*wirebson.Document).Get("id"))
+
+	res = request(t, ctx, conn,
+		"find", coll,
+		"batchSize", int32(1),
+		"lsid", lsid,
+		"$db", db,
+	)
+	require.Equal(t, float64(1), res.Get("ok"), wirebson.LogMessage(res))
+
+	cursorID := res.Get("cursor").(*wirebson.Document).Get("id")
+	require.NotZero(t, cursorID)
+
+	getMore := func() *wirebson.Document {
+		return request(t, ctx, conn,
+			"getMore", cursorID,
+			"collection", coll,
+			"batchSize", int32(1),
+			"lsid", lsid,
+			"$db", db,
+		)
+	}
+
+	p.FailNext(1, pgerrcode.SerializationFailure)
+
+	res = getMore()
+	require.Equal(t, float64(0), res.Get("ok"), wirebson.LogMessage(res))
+	assert.Equal(t, int32(writeConflict), res.Get("code"))
+
+	// the cursor and the session survive a transient failure
+	res = getMore()
+	require.Equal(t, float64(1), res.Get("ok"), wirebson.LogMessage(res))
+
+	batch := res.Get("cursor").(*wirebson.Document).Get("nextBatch").(*wirebson.Array)
+	assert.Equal(t, "b", batch.Get(0).(*wirebson.Document).Get("_id"))
+
+	require.Positive(t, p.DropConnections())
+
+	var attempts int
+
+	for res = getMore(); res.Get("ok") != float64(1); res = getMore() {
+		assert.Equal(t, int32(internalError), res.Get("code"), wirebson.LogMessage(res))
+
+		attempts++
+		require.Less(t, attempts, 10, "too many failed attempts")
+	}
+
+	batch = res.Get("cursor").(*wirebson.Document).Get("nextBatch").(*wirebson.Array)
+	assert.Equal(t, "c", batch.Get(0).(*wirebson.Document).Get("_id"))
+}
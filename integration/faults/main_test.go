@@ -0,0 +1,32 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package faults contains tests for PostgreSQL connection faults:
+//   - latency;
+//   - dropped connections;
+//   - serialization failures.
+//
+// Faults are injected by a proxy between in-process FerretDB and PostgreSQL,
+// so those tests are skipped for MongoDB and FerretDB running in a separate process.
+package faults
+
+import (
+	"testing"
+
+	"github.com/FerretDB/FerretDB/v2/integration/setup"
+)
+
+func TestMain(m *testing.M) {
+	setup.Main(m)
+}
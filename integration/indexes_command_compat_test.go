@@ -283,8 +283,7 @@ func TestDropIndexesCommandCompat(t *testing.T) {
 				{Keys: bson.D{{"v", 1}, {"foo", 1}}},
 				{Keys: bson.D{{"v.foo", -1}}},
 			},
-			toDrop:           bson.A{"v_-1", "v_1_foo_1"},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB/issues/5119",
+			toDrop: bson.A{"v_-1", "v_1_foo_1"},
 		},
 		"MultipleIndexesByKey": {
 			toCreate: []mongo.IndexModel{
@@ -313,16 +312,14 @@ func TestDropIndexesCommandCompat(t *testing.T) {
 			toCreate: []mongo.IndexModel{
 				{Keys: bson.D{{"v", -1}}},
 			},
-			toDrop:           bson.D{{"v", -1}},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB/issues/5119",
+			toDrop: bson.D{{"v", -1}},
 		},
 		"SimilarIndexes": {
 			toCreate: []mongo.IndexModel{
 				{Keys: bson.D{{"v", 1}, {"foo", 1}}},
 				{Keys: bson.D{{"v", 1}, {"bar", 1}}},
 			},
-			toDrop:           bson.D{{"v", 1}, {"bar", 1}},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB/issues/5119",
+			toDrop: bson.D{{"v", 1}, {"bar", 1}},
 		},
 		"DropAllExpression": {
 			toCreate: []mongo.IndexModel{
@@ -330,8 +327,10 @@ func TestDropIndexesCommandCompat(t *testing.T) {
 				{Keys: bson.D{{"foo.bar", 1}}},
 				{Keys: bson.D{{"foo", 1}, {"bar", 1}}},
 			},
-			toDrop:           "*",
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB/issues/5119",
+			toDrop: "*",
+		},
+		"DropAllNoIndexes": {
+			toDrop: "*",
 		},
 		"WrongExpression": {
 			toCreate: []mongo.IndexModel{
@@ -354,7 +353,6 @@ func TestDropIndexesCommandCompat(t *testing.T) {
 				{"_id", -1},
 				{"v", 1},
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB/issues/5119",
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
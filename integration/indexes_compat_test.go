@@ -219,6 +219,46 @@ func TestCreateIndexesCompat(t *testing.T) {
 			},
 			resultType: EmptyResult,
 		},
+		"Sparse": {
+			models: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{"v", 1}},
+					Options: options.Index().SetSparse(true),
+				},
+			},
+		},
+		"PartialFilterExpression": {
+			models: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{"v", 1}},
+					Options: options.Index().SetPartialFilterExpression(bson.D{{"v", bson.D{{"$gt", int32(0)}}}}),
+				},
+			},
+		},
+		"ExpireAfterSeconds": {
+			models: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{"v", 1}},
+					Options: options.Index().SetExpireAfterSeconds(3600),
+				},
+			},
+		},
+		"Hidden": {
+			models: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{"v", 1}},
+					Options: options.Index().SetHidden(true),
+				},
+			},
+		},
+		"WildcardProjection": {
+			models: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{"$**", 1}},
+					Options: options.Index().SetWildcardProjection(bson.D{{"v", int32(1)}}),
+				},
+			},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			if tc.skip != "" {
@@ -0,0 +1,220 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/v2/integration/setup"
+	"github.com/FerretDB/FerretDB/v2/integration/shareddata"
+)
+
+// TestIndexesCompatUniqueEdgeCases tests unique indexes with missing fields, nulls, compound keys,
+// and sparse and partial options.
+//
+// Documents are inserted one by one, and DuplicateKey errors (including their key pattern and key value)
+// and the resulting collection contents are compared.
+func TestIndexesCompatUniqueEdgeCases(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:vet // for readability
+		keys             bson.D                // required, unique index keys
+		opts             *options.IndexOptions // optional, additional index options
+		docs             []bson.D              // required, documents to insert in that order
+		failsForFerretDB string
+	}{
+		"Missing": {
+			keys:             bson.D{{"v", 1}},
+			docs:             []bson.D{{{"_id", 1}}, {{"_id", 2}}},
+			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/296",
+		},
+		"NullAndMissing": {
+			keys:             bson.D{{"v", 1}},
+			docs:             []bson.D{{{"_id", 1}, {"v", nil}}, {{"_id", 2}}},
+			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/296",
+		},
+		"Null": {
+			keys: bson.D{{"v", 1}},
+			docs: []bson.D{{{"_id", 1}, {"v", nil}}, {{"_id", 2}, {"v", nil}}},
+		},
+		"NumberTypes": {
+			keys: bson.D{{"v", 1}},
+			docs: []bson.D{{{"_id", 1}, {"v", int32(42)}}, {{"_id", 2}, {"v", 42.0}}, {{"_id", 3}, {"v", int64(42)}}},
+		},
+		"Dotted": {
+			keys: bson.D{{"v.foo", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", bson.D{{"foo", "bar"}}}},
+				{{"_id", 2}, {"v", bson.D{{"foo", "baz"}}}},
+				{{"_id", 3}, {"v", bson.D{{"foo", "bar"}}}},
+			},
+		},
+		"Array": {
+			keys: bson.D{{"v", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", bson.A{1, 1, 2}}},
+				{{"_id", 2}, {"v", bson.A{3, 4}}},
+				{{"_id", 3}, {"v", bson.A{2, 5}}},
+				{{"_id", 4}, {"v", 4}},
+			},
+		},
+		"Compound": {
+			keys: bson.D{{"v", 1}, {"foo", -1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", 1}, {"foo", 1}},
+				{{"_id", 2}, {"v", 1}, {"foo", 2}},
+				{{"_id", 3}, {"foo", 1}, {"v", 1}},
+				{{"_id", 4}, {"v", 2}},
+				{{"_id", 5}, {"v", 2}, {"foo", nil}},
+			},
+			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/296",
+		},
+		"CompoundNull": {
+			keys: bson.D{{"v", 1}, {"foo", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", nil}, {"foo", nil}},
+				{{"_id", 2}, {"v", nil}, {"foo", 1}},
+				{{"_id", 3}, {"v", nil}, {"foo", nil}},
+			},
+		},
+		"Sparse": {
+			keys: bson.D{{"v", 1}},
+			opts: options.Index().SetSparse(true),
+			docs: []bson.D{
+				{{"_id", 1}},
+				{{"_id", 2}},
+				{{"_id", 3}, {"v", nil}},
+				{{"_id", 4}, {"v", nil}},
+			},
+		},
+		"SparseCompound": {
+			keys: bson.D{{"v", 1}, {"foo", 1}},
+			opts: options.Index().SetSparse(true),
+			docs: []bson.D{
+				{{"_id", 1}, {"bar", 1}},
+				{{"_id", 2}, {"bar", 2}},
+				{{"_id", 3}, {"v", 1}},
+				{{"_id", 4}, {"v", 1}},
+			},
+			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/296",
+		},
+		"PartialExists": {
+			keys: bson.D{{"v", 1}},
+			opts: options.Index().SetPartialFilterExpression(bson.D{{"v", bson.D{{"$exists", true}}}}),
+			docs: []bson.D{
+				{{"_id", 1}},
+				{{"_id", 2}},
+				{{"_id", 3}, {"v", 1}},
+				{{"_id", 4}, {"v", 1}},
+			},
+		},
+		"PartialType": {
+			keys: bson.D{{"v", 1}},
+			opts: options.Index().SetPartialFilterExpression(bson.D{{"v", bson.D{{"$type", "number"}}}}),
+			docs: []bson.D{
+				{{"_id", 1}, {"v", nil}},
+				{{"_id", 2}, {"v", nil}},
+				{{"_id", 3}, {"v", "foo"}},
+				{{"_id", 4}, {"v", "foo"}},
+				{{"_id", 5}, {"v", 1}},
+				{{"_id", 6}, {"v", 1.0}},
+			},
+		},
+		"PartialCompound": {
+			keys: bson.D{{"v", 1}, {"foo", 1}},
+			opts: options.Index().SetPartialFilterExpression(bson.D{{"foo", bson.D{{"$gt", 0}}}}),
+			docs: []bson.D{
+				{{"_id", 1}, {"v", 1}, {"foo", 0}},
+				{{"_id", 2}, {"v", 1}, {"foo", 0}},
+				{{"_id", 3}, {"v", 1}, {"foo", 1}},
+				{{"_id", 4}, {"v", 1}, {"foo", 1}},
+			},
+		},
+	} {
+		t.Run(name, func(tt *testing.T) {
+			tt.Parallel()
+
+			var t testing.TB = tt
+			if tc.failsForFerretDB != "" {
+				t = setup.FailsForFerretDB(tt, tc.failsForFerretDB)
+			}
+
+			s := setup.SetupCompatWithOpts(tt, &setup.SetupCompatOpts{
+				Providers:                []shareddata.Provider{}, // collections are not needed for this test
+				AddNonExistentCollection: true,
+			})
+			ctx := s.Ctx
+			targetCollection, compatCollection := s.TargetCollections[0], s.CompatCollections[0]
+
+			opts := tc.opts
+			if opts == nil {
+				opts = options.Index()
+			}
+
+			model := mongo.IndexModel{Keys: tc.keys, Options: opts.SetUnique(true)}
+
+			_, targetErr := targetCollection.Indexes().CreateOne(ctx, model)
+			_, compatErr := compatCollection.Indexes().CreateOne(ctx, model)
+			require.NoError(t, compatErr)
+			require.NoError(t, targetErr)
+
+			for _, doc := range tc.docs {
+				_, targetErr = targetCollection.InsertOne(ctx, doc)
+				_, compatErr = compatCollection.InsertOne(ctx, doc)
+
+				if compatErr == nil {
+					require.NoError(t, targetErr, "target error; compat returned no error for %v", doc)
+					continue
+				}
+
+				require.Error(t, targetErr, "target returned no error; compat error: %v", compatErr)
+
+				// error messages are intentionally not compared
+				AssertMatchesWriteError(t, compatErr, targetErr)
+
+				compatRaw, targetRaw := duplicateKeyWriteError(t, compatErr), duplicateKeyWriteError(t, targetErr)
+
+				assert.Equal(t, compatRaw.Lookup("keyPattern"), targetRaw.Lookup("keyPattern"), "keyPattern for %v", doc)
+
+				// FerretDB does not report key values for arrays, as the conflicting element is unknown
+				if _, err := targetRaw.LookupErr("keyValue"); err == nil || setup.IsMongoDB(t) {
+					assert.Equal(t, compatRaw.Lookup("keyValue"), targetRaw.Lookup("keyValue"), "keyValue for %v", doc)
+				}
+			}
+
+			assert.Equal(t, FindAll(t, ctx, compatCollection), FindAll(t, ctx, targetCollection))
+		})
+	}
+}
+
+// duplicateKeyWriteError returns the raw write error of the given error
+// that should be a WriteException containing exactly one DuplicateKey WriteError.
+func duplicateKeyWriteError(t testing.TB, err error) bson.Raw {
+	t.Helper()
+
+	var we mongo.WriteException
+	require.True(t, errors.As(err, &we), "%T is not a WriteException: %v", err, err)
+	require.Len(t, we.WriteErrors, 1)
+	require.Equal(t, 11000, we.WriteErrors[0].Code)
+
+	return we.WriteErrors[0].Raw
+}
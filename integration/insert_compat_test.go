@@ -177,6 +177,26 @@ func TestInsertCompat(t *testing.T) {
 			insert: []any{bson.D{{"_id", int32(42)}}},
 		},
 
+		"DotField": {
+			insert: []any{bson.D{{"_id", "dot"}, {"foo.bar", int32(42)}}},
+		},
+		"DotFieldNested": {
+			insert: []any{bson.D{{"_id", "dot-nested"}, {"foo", bson.D{{"bar.baz", int32(42)}}}}},
+		},
+		"DollarField": {
+			insert: []any{bson.D{{"_id", "dollar"}, {"$foo", int32(42)}}},
+		},
+		"DollarFieldNested": {
+			insert: []any{bson.D{{"_id", "dollar-nested"}, {"foo", bson.D{{"$bar", int32(42)}}}}},
+		},
+		"DollarFieldInArray": {
+			insert: []any{bson.D{{"_id", "dollar-array"}, {"foo", bson.A{bson.D{{"$bar", int32(42)}}}}}},
+		},
+		"IDDollarField": {
+			insert:     []any{bson.D{{"_id", bson.D{{"$foo", int32(42)}}}}},
+			resultType: EmptyResult,
+		},
+
 		"IDArray": {
 			insert:           []any{bson.D{{"_id", bson.A{"foo", "bar"}}}},
 			resultType:       EmptyResult,
@@ -15,7 +15,6 @@
 package query
 
 import (
-	"slices"
 	"strings"
 	"testing"
 
@@ -293,7 +292,6 @@ func TestFindAndModifyCompatDotNotation(t *testing.T) {
 				}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/320",
-			failsProviders:   []shareddata.Provider{shareddata.ArrayAndDocuments},
 		},
 		"ParentConflict": {
 			command: bson.D{
@@ -347,7 +345,6 @@ func TestFindAndModifyCompatUpdateSet(t *testing.T) {
 				{"update", bson.D{{"$set", bson.D{{"v", "foo"}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Strings},
 		},
 		"ExistsTrue": {
 			command: bson.D{
@@ -355,7 +352,6 @@ func TestFindAndModifyCompatUpdateSet(t *testing.T) {
 				{"update", bson.D{{"$set", bson.D{{"v", "foo"}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Strings},
 		},
 		"ExistsFalse": {
 			command: bson.D{
@@ -368,7 +364,6 @@ func TestFindAndModifyCompatUpdateSet(t *testing.T) {
 				{"update", bson.D{{"$set", bson.D{{"_id", "int32"}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Int32s},
 		},
 		"UpdateExistingID": {
 			command: bson.D{
@@ -382,7 +377,6 @@ func TestFindAndModifyCompatUpdateSet(t *testing.T) {
 				{"update", bson.D{{"$set", bson.D{{"_id", "int32"}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Int32s, shareddata.Scalars},
 		},
 	}
 
@@ -405,7 +399,6 @@ func TestFindAndModifyCompatUnset(t *testing.T) {
 				{"update", bson.D{{"$unset", bson.D{{"v", ""}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Unsets},
 		},
 		"ExistsTrue": {
 			command: bson.D{
@@ -413,7 +406,6 @@ func TestFindAndModifyCompatUnset(t *testing.T) {
 				{"update", bson.D{{"$unset", bson.D{{"v", ""}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Unsets},
 		},
 		"ExistsFalse": {
 			command: bson.D{
@@ -427,7 +419,6 @@ func TestFindAndModifyCompatUnset(t *testing.T) {
 				{"update", bson.D{{"$unset", bson.D{{"non-existent-field", ""}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Scalars, shareddata.Doubles, shareddata.SmallDoubles},
 		},
 	}
 
@@ -450,72 +441,28 @@ func TestFindAndModifyCompatUpdateCurrentDate(t *testing.T) {
 				{"query", bson.D{{"_id", "datetime"}}},
 				{"update", bson.D{{"$currentDate", bson.D{{"v", bson.D{{"foo", int32(1)}}}}}}},
 			},
-			resultType:       integration.EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/323",
-			failsProviders: []shareddata.Provider{
-				shareddata.Unsets, shareddata.Nulls, shareddata.DocumentsDocuments, shareddata.Mixed,
-				shareddata.Regexes, shareddata.ArrayDocuments, shareddata.ArrayDoubles, shareddata.Int64s,
-				shareddata.ObjectIDKeys, shareddata.DocumentsDoubles, shareddata.Strings, shareddata.Bools,
-				shareddata.PostgresEdgeCases, shareddata.OverflowVergeDoubles, shareddata.ObjectIDs,
-				shareddata.Composites, shareddata.DocumentsStrings, shareddata.DocumentsDeeplyNested, shareddata.Decimal128s,
-				shareddata.Doubles, shareddata.Int32s, shareddata.Timestamps, shareddata.ArrayInt32s,
-				shareddata.ArrayStrings, shareddata.ArrayRegexes, shareddata.ArrayAndDocuments,
-				shareddata.SmallDoubles, shareddata.Binaries,
-			},
+			resultType: integration.EmptyResult,
 		},
 		"InvalidType": {
 			command: bson.D{
 				{"query", bson.D{{"_id", "datetime"}}},
 				{"update", bson.D{{"$currentDate", bson.D{{"v", bson.D{{"$type", int32(1)}}}}}}},
 			},
-			resultType:       integration.EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/323",
-			failsProviders: []shareddata.Provider{
-				shareddata.Unsets, shareddata.Nulls, shareddata.DocumentsDocuments, shareddata.Mixed,
-				shareddata.Regexes, shareddata.ArrayDocuments, shareddata.ArrayDoubles, shareddata.Int64s,
-				shareddata.ObjectIDKeys, shareddata.DocumentsDoubles, shareddata.Strings, shareddata.Bools,
-				shareddata.PostgresEdgeCases, shareddata.OverflowVergeDoubles, shareddata.ObjectIDs,
-				shareddata.Composites, shareddata.DocumentsStrings, shareddata.DocumentsDeeplyNested, shareddata.Decimal128s,
-				shareddata.Doubles, shareddata.Int32s, shareddata.Timestamps, shareddata.ArrayInt32s,
-				shareddata.ArrayStrings, shareddata.ArrayRegexes, shareddata.ArrayAndDocuments,
-				shareddata.SmallDoubles, shareddata.Binaries,
-			},
+			resultType: integration.EmptyResult,
 		},
 		"UnknownType": {
 			command: bson.D{
 				{"query", bson.D{{"_id", "datetime"}}},
 				{"update", bson.D{{"$currentDate", bson.D{{"v", bson.D{{"$type", "unknown"}}}}}}},
 			},
-			resultType:       integration.EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/323",
-			failsProviders: []shareddata.Provider{
-				shareddata.Unsets, shareddata.Nulls, shareddata.DocumentsDocuments, shareddata.Mixed,
-				shareddata.Regexes, shareddata.ArrayDocuments, shareddata.ArrayDoubles, shareddata.Int64s,
-				shareddata.ObjectIDKeys, shareddata.DocumentsDoubles, shareddata.Strings, shareddata.Bools,
-				shareddata.PostgresEdgeCases, shareddata.OverflowVergeDoubles, shareddata.ObjectIDs,
-				shareddata.Composites, shareddata.DocumentsStrings, shareddata.DocumentsDeeplyNested, shareddata.Decimal128s,
-				shareddata.Doubles, shareddata.Int32s, shareddata.Timestamps, shareddata.ArrayInt32s,
-				shareddata.ArrayStrings, shareddata.ArrayRegexes, shareddata.ArrayAndDocuments,
-				shareddata.SmallDoubles, shareddata.Binaries,
-			},
+			resultType: integration.EmptyResult,
 		},
 		"InvalidValue": {
 			command: bson.D{
 				{"query", bson.D{{"_id", "datetime"}}},
 				{"update", bson.D{{"$currentDate", bson.D{{"v", 1}}}}},
 			},
-			resultType:       integration.EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/323",
-			failsProviders: []shareddata.Provider{
-				shareddata.Unsets, shareddata.Nulls, shareddata.DocumentsDocuments, shareddata.Mixed,
-				shareddata.Regexes, shareddata.ArrayDocuments, shareddata.ArrayDoubles, shareddata.Int64s,
-				shareddata.ObjectIDKeys, shareddata.DocumentsDoubles, shareddata.Strings, shareddata.Bools,
-				shareddata.PostgresEdgeCases, shareddata.OverflowVergeDoubles, shareddata.ObjectIDs,
-				shareddata.Composites, shareddata.DocumentsStrings, shareddata.DocumentsDeeplyNested, shareddata.Decimal128s,
-				shareddata.Doubles, shareddata.Int32s, shareddata.Timestamps, shareddata.ArrayInt32s,
-				shareddata.ArrayStrings, shareddata.ArrayRegexes, shareddata.ArrayAndDocuments,
-				shareddata.SmallDoubles, shareddata.Binaries,
-			},
+			resultType: integration.EmptyResult,
 		},
 	}
 
@@ -545,16 +492,14 @@ func TestFindAndModifyCompatUpdateRename(t *testing.T) {
 				{"query", bson.D{{"_id", "int64"}}},
 				{"update", bson.D{{"$rename", bson.D{{"v", "v"}}}}},
 			},
-			resultType:       integration.EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/324",
+			resultType: integration.EmptyResult,
 		},
 		"DuplicateSource": {
 			command: bson.D{
 				{"query", bson.D{{"_id", "int64"}}},
 				{"update", bson.D{{"$rename", bson.D{{"v", "w"}, {"v", "x"}}}}},
 			},
-			resultType:       integration.EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/324",
+			resultType: integration.EmptyResult,
 		},
 		"DuplicateTarget": {
 			command: bson.D{
@@ -600,8 +545,7 @@ func TestFindAndModifyCompatSort(t *testing.T) {
 				{"update", bson.D{{"$set", bson.D{{"v.0.foo.0.bar", "baz"}}}}},
 				{"sort", bson.D{{"v..foo", 1}, {"_id", 1}}},
 			},
-			resultType:       integration.EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
+			resultType: integration.EmptyResult,
 		},
 		"DollarPrefixedFieldName": {
 			command: bson.D{
@@ -609,8 +553,7 @@ func TestFindAndModifyCompatSort(t *testing.T) {
 				{"update", bson.D{{"$set", bson.D{{"v.0.foo.0.bar", "baz"}}}}},
 				{"sort", bson.D{{"$v.foo", 1}, {"_id", 1}}},
 			},
-			resultType:       integration.EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
+			resultType: integration.EmptyResult,
 		},
 	}
 
@@ -673,7 +616,6 @@ func TestFindAndModifyCompatUpsert(t *testing.T) {
 				{"upsert", true},
 				{"update", bson.D{{"_id", "int32"}, {"v", "replaced"}}},
 			},
-			failsProviders: []shareddata.Provider{shareddata.Int32s},
 		},
 		"UpdateDifferentID": {
 			command: bson.D{
@@ -736,7 +678,6 @@ func TestFindAndModifyCompatUpsertSet(t *testing.T) {
 				{"update", bson.D{{"$set", bson.D{{"v", "foo"}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Strings},
 		},
 		"ExistsTrue": {
 			command: bson.D{
@@ -745,7 +686,6 @@ func TestFindAndModifyCompatUpsertSet(t *testing.T) {
 				{"update", bson.D{{"$set", bson.D{{"v", "foo"}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Strings},
 		},
 		"UpsertID": {
 			command: bson.D{
@@ -760,7 +700,6 @@ func TestFindAndModifyCompatUpsertSet(t *testing.T) {
 				{"upsert", true},
 				{"update", bson.D{{"$set", bson.D{{"_id", "int32"}, {"v", int32(2)}}}}},
 			},
-			failsProviders: []shareddata.Provider{shareddata.Int32s},
 		},
 		"UpsertExistingID": {
 			command: bson.D{
@@ -827,7 +766,6 @@ func TestFindAndModifyCompatSetOnInsert(t *testing.T) {
 			},
 			providers:        []shareddata.Provider{shareddata.Int32s},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Int32s},
 		},
 		"IDNotExists": {
 			command: bson.D{
@@ -894,7 +832,6 @@ func TestFindAndModifyCompatUpsertUnset(t *testing.T) {
 				{"update", bson.D{{"$unset", bson.D{{"v", ""}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Unsets},
 		},
 		"ExistsTrue": {
 			command: bson.D{
@@ -903,7 +840,6 @@ func TestFindAndModifyCompatUpsertUnset(t *testing.T) {
 				{"update", bson.D{{"$unset", bson.D{{"v", ""}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Unsets},
 		},
 		"ExistsFalse": {
 			command: bson.D{
@@ -922,7 +858,6 @@ func TestFindAndModifyCompatUpsertUnset(t *testing.T) {
 				{"update", bson.D{{"$unset", bson.D{{"non-existent-field", ""}}}}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Scalars, shareddata.Doubles, shareddata.SmallDoubles},
 		},
 	}
 
@@ -973,7 +908,6 @@ func TestFindAndModifyCompatReplacementDoc(t *testing.T) {
 				{"update", bson.D{}},
 			},
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/321",
-			failsProviders:   []shareddata.Provider{shareddata.Unsets},
 		},
 		"FilterAndUpsertTrue": {
 			command: bson.D{
@@ -1011,8 +945,7 @@ type findAndModifyCompatTestCase struct {
 	resultType integration.CompatTestCaseResultType // defaults to NonEmptyResult
 	providers  []shareddata.Provider                // defaults to shareddata.AllProviders()
 
-	failsForFerretDB string
-	failsProviders   []shareddata.Provider // use only if failsForFerretDB is set, defaults to all providers
+	failsForFerretDB string // failing providers are listed in testdata/expectations
 }
 
 // testFindAndModifyCompat tests findAndModify compatibility test cases.
@@ -1034,11 +967,6 @@ func testFindAndModifyCompat(t *testing.T, testCases map[string]findAndModifyCom
 
 			ctx, targetCollections, compatCollections := s.Ctx, s.TargetCollections, s.CompatCollections
 
-			failsProviders := make([]string, len(tc.failsProviders))
-			for i, p := range tc.failsProviders {
-				failsProviders[i] = p.Name()
-			}
-
 			var nonEmptyResults bool
 			for i := range targetCollections {
 				targetCollection := targetCollections[i]
@@ -1052,10 +980,8 @@ func testFindAndModifyCompat(t *testing.T, testCases map[string]findAndModifyCom
 					str := strings.Split(targetCollection.Name(), "_")
 					providerName := str[len(str)-1]
 
-					failsForCollection := len(tc.failsProviders) == 0 || slices.Contains(failsProviders, providerName)
-
-					if tc.failsForFerretDB != "" && failsForCollection {
-						t = setup.FailsForFerretDB(tt, tc.failsForFerretDB)
+					if tc.failsForFerretDB != "" {
+						t = setup.FailsForFerretDBProvider(tt, tc.failsForFerretDB, providerName)
 					}
 
 					t.Helper()
@@ -203,6 +203,34 @@ func TestQueryArrayCompatElemMatch(t *testing.T) {
 			resultType:       EmptyResult,
 			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/785",
 		},
+		"GteLteScalars": {
+			filter: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$gte", int32(42)}, {"$lte", int32(43)}}}}}},
+		},
+		"TypeString": {
+			filter: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$type", "string"}}}}}},
+		},
+		"DocumentField": {
+			filter: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"field", int32(42)}}}}}},
+		},
+		"DocumentFieldGt": {
+			filter: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"field", bson.D{{"$gt", int32(42)}}}}}}}},
+		},
+		"DocumentTwoFields": {
+			filter: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"field", int32(42)}, {"foo", int32(44)}}}}}},
+		},
+		"DocumentTwoFieldsDifferentElements": {
+			// fields match different elements, but not the same one
+			filter:     bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"field", int32(42)}, {"foo", int32(42)}}}}}},
+			resultType: EmptyResult,
+		},
+		"DocumentNested": {
+			filter: bson.D{{"v", bson.D{{"$elemMatch", bson.D{
+				{"foo", bson.D{{"$elemMatch", bson.D{{"bar", "world"}}}}},
+			}}}}},
+		},
+		"DocumentDotNotation": {
+			filter: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"foo.bar", "hello"}}}}}},
+		},
 	}
 
 	testQueryCompat(t, testCases)
@@ -302,6 +330,32 @@ func TestQueryArrayCompatAll(t *testing.T) {
 		"NilRepeated": {
 			filter: bson.D{{"v", bson.D{{"$all", bson.A{nil, nil, nil}}}}},
 		},
+		"ElemMatch": {
+			filter: bson.D{{"v", bson.D{{"$all", bson.A{
+				bson.D{{"$elemMatch", bson.D{{"field", int32(42)}}}},
+				bson.D{{"$elemMatch", bson.D{{"foo", int32(42)}}}},
+			}}}}},
+		},
+		"ElemMatchScalars": {
+			filter: bson.D{{"v", bson.D{{"$all", bson.A{
+				bson.D{{"$elemMatch", bson.D{{"$gt", int32(41)}, {"$lt", int32(43)}}}},
+				bson.D{{"$elemMatch", bson.D{{"$type", "string"}}}},
+			}}}}},
+		},
+		"ElemMatchNotFound": {
+			filter: bson.D{{"v", bson.D{{"$all", bson.A{
+				bson.D{{"$elemMatch", bson.D{{"field", int32(42)}}}},
+				bson.D{{"$elemMatch", bson.D{{"field", int32(46)}}}},
+			}}}}},
+			resultType: EmptyResult,
+		},
+		"ElemMatchMixedWithValue": {
+			filter: bson.D{{"v", bson.D{{"$all", bson.A{
+				bson.D{{"$elemMatch", bson.D{{"field", int32(42)}}}},
+				int32(42),
+			}}}}},
+			resultType: EmptyResult,
+		},
 	}
 
 	testQueryCompat(t, testCases)
@@ -0,0 +1,142 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bitwiseCompatTestCases returns test cases for the given bitwise query operator.
+//
+// All bitwise operators accept the same operand types, so the same cases are used for all of them.
+func bitwiseCompatTestCases(operator string) map[string]queryCompatTestCase {
+	filter := func(v any) bson.D {
+		return bson.D{{"v", bson.D{{operator, v}}}}
+	}
+
+	return map[string]queryCompatTestCase{
+		"Int32": {
+			filter: filter(int32(2)),
+		},
+		"Int32Zero": {
+			filter: filter(int32(0)),
+		},
+		"Int32Max": {
+			filter: filter(int32(math.MaxInt32)),
+		},
+		"Int32Negative": {
+			filter:     filter(int32(-1)),
+			resultType: EmptyResult,
+		},
+		"Int64": {
+			filter: filter(int64(1) << 40),
+		},
+		"Int64Max": {
+			filter:           filter(int64(math.MaxInt64)),
+			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/257",
+		},
+		"Int64Negative": {
+			filter:           filter(int64(-1)),
+			resultType:       EmptyResult,
+			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/258",
+		},
+		"DoubleWhole": {
+			filter: filter(float64(2)),
+		},
+		"DoubleNotWhole": {
+			filter:     filter(1.2),
+			resultType: EmptyResult,
+		},
+		"DoubleNegative": {
+			filter:     filter(float64(-1)),
+			resultType: EmptyResult,
+		},
+		"DoubleOverflow": {
+			filter:     filter(math.MaxFloat64),
+			resultType: EmptyResult,
+		},
+
+		"Positions": {
+			filter: filter(bson.A{int32(1), int32(5)}),
+		},
+		"PositionsEmpty": {
+			filter: filter(bson.A{}),
+		},
+		"PositionsHighBit": {
+			// bits above 63 are set for negative numbers only because of the sign extension
+			filter: filter(bson.A{int32(63), int32(100)}),
+		},
+		"PositionsDoubleWhole": {
+			filter: filter(bson.A{float64(1)}),
+		},
+		"PositionsNegative": {
+			filter:           filter(bson.A{int32(-1)}),
+			resultType:       EmptyResult,
+			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
+		},
+		"PositionsString": {
+			filter:           filter(bson.A{"1"}),
+			resultType:       EmptyResult,
+			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
+		},
+
+		"Binary": {
+			filter: filter(primitive.Binary{Data: []byte{2}}),
+		},
+		"BinaryEmpty": {
+			filter: filter(primitive.Binary{Data: []byte{}}),
+		},
+		"BinaryWithZeroBytes": {
+			filter: filter(primitive.Binary{Data: []byte{0, 0, 2}}),
+		},
+		"Binary9Bytes": {
+			filter: filter(primitive.Binary{Data: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9}}),
+		},
+
+		"String": {
+			filter:           filter("2"),
+			resultType:       EmptyResult,
+			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
+		},
+	}
+}
+
+func TestQueryBitwiseCompatAllClear(t *testing.T) {
+	t.Parallel()
+
+	testQueryCompat(t, bitwiseCompatTestCases("$bitsAllClear"))
+}
+
+func TestQueryBitwiseCompatAllSet(t *testing.T) {
+	t.Parallel()
+
+	testQueryCompat(t, bitwiseCompatTestCases("$bitsAllSet"))
+}
+
+func TestQueryBitwiseCompatAnyClear(t *testing.T) {
+	t.Parallel()
+
+	testQueryCompat(t, bitwiseCompatTestCases("$bitsAnyClear"))
+}
+
+func TestQueryBitwiseCompatAnySet(t *testing.T) {
+	t.Parallel()
+
+	testQueryCompat(t, bitwiseCompatTestCases("$bitsAnySet"))
+}
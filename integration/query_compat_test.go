@@ -40,6 +40,10 @@ type queryCompatTestCase struct {
 	limit      *int64                   // defaults to nil to leave unset
 	batchSize  *int32                   // defaults to nil to leave unset
 	projection bson.D                   // nil for leaving projection unset
+	hint       any                      // defaults to nil to leave unset
+	min        bson.D                   // defaults to nil to leave unset
+	max        bson.D                   // defaults to nil to leave unset
+	returnKey  bool                     // defaults to false to leave unset
 	resultType CompatTestCaseResultType // defaults to NonEmptyResult
 
 	skipIDCheck      bool   // skip check collected IDs, use it when no ids returned from query
@@ -99,6 +103,22 @@ func testQueryCompatWithProviders(t *testing.T, providers shareddata.Providers,
 				opts.SetProjection(tc.projection)
 			}
 
+			if tc.hint != nil {
+				opts.SetHint(tc.hint)
+			}
+
+			if tc.min != nil {
+				opts.SetMin(tc.min)
+			}
+
+			if tc.max != nil {
+				opts.SetMax(tc.max)
+			}
+
+			if tc.returnKey {
+				opts.SetReturnKey(true)
+			}
+
 			failsProviders := make([]string, len(tc.failsProviders))
 			for i, p := range tc.failsProviders {
 				failsProviders[i] = p.Name()
@@ -292,17 +312,15 @@ func TestQueryCompatSort(t *testing.T) {
 			sort:   bson.D{{"invalid.foo", 1}, {"_id", 1}},
 		},
 		"DotNotationMissingField": {
-			filter:           bson.D{},
-			sort:             bson.D{{"v..foo", 1}, {"_id", 1}},
-			resultType:       EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
+			filter:     bson.D{},
+			sort:       bson.D{{"v..foo", 1}, {"_id", 1}},
+			resultType: EmptyResult,
 		},
 
 		"BadDollarStart": {
-			filter:           bson.D{},
-			sort:             bson.D{{"$v.foo", 1}},
-			resultType:       EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/265",
+			filter:     bson.D{},
+			sort:       bson.D{{"$v.foo", 1}},
+			resultType: EmptyResult,
 		},
 		"BadDollarMid": {
 			filter:           bson.D{},
@@ -438,6 +456,63 @@ func TestQueryCompatLimit(t *testing.T) {
 	testQueryCompat(t, testCases)
 }
 
+func TestQueryCompatMinMaxReturnKey(t *testing.T) {
+	t.Parallel()
+
+	// arrays are not used, as they produce multiple index keys
+	providers := []shareddata.Provider{shareddata.Int32s, shareddata.Strings}
+
+	testCases := map[string]queryCompatTestCase{
+		"Min": {
+			filter: bson.D{},
+			hint:   bson.D{{"v", 1}},
+			min:    bson.D{{"v", int32(42)}},
+		},
+		"Max": {
+			filter: bson.D{},
+			hint:   bson.D{{"v", 1}},
+			max:    bson.D{{"v", "foo"}},
+		},
+		"MinMax": {
+			filter: bson.D{},
+			hint:   "v_1",
+			min:    bson.D{{"v", int32(0)}},
+			max:    bson.D{{"v", int32(4080)}},
+		},
+		"MinFilter": {
+			filter: bson.D{{"v", bson.D{{"$ne", int32(42)}}}},
+			hint:   bson.D{{"v", 1}},
+			min:    bson.D{{"v", int32(42)}},
+		},
+		"NoHint": {
+			filter:     bson.D{},
+			min:        bson.D{{"v", int32(42)}},
+			resultType: EmptyResult,
+		},
+		"WrongField": {
+			filter:     bson.D{},
+			hint:       bson.D{{"v", 1}},
+			min:        bson.D{{"foo", int32(42)}},
+			resultType: EmptyResult,
+		},
+		"ReturnKey": {
+			filter:      bson.D{},
+			hint:        bson.D{{"v", 1}},
+			returnKey:   true,
+			skipIDCheck: true,
+		},
+		"ReturnKeyProjection": {
+			filter:      bson.D{},
+			hint:        bson.D{{"v", 1}},
+			projection:  bson.D{{"_id", 1}},
+			returnKey:   true,
+			skipIDCheck: true,
+		},
+	}
+
+	testQueryCompatWithProviders(t, providers, testCases)
+}
+
 func TestQueryCompatBatchSize(t *testing.T) {
 	t.Parallel()
 
@@ -71,10 +71,9 @@ func TestQueryProjectionCompat(t *testing.T) {
 			projection: bson.D{{"foo", int32(0)}, {"bar", false}},
 		},
 		"Include1FieldExclude1Field": {
-			filter:           bson.D{},
-			projection:       bson.D{{"foo", int32(0)}, {"bar", true}},
-			resultType:       EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
+			filter:     bson.D{},
+			projection: bson.D{{"foo", int32(0)}, {"bar", true}},
+			resultType: EmptyResult,
 		},
 		"Exclude1FieldInclude1Field": {
 			filter:     bson.D{},
@@ -48,7 +48,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Name:    "Location40352",
 				Message: "FieldPath cannot be constructed with empty string",
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"EmptyPath": {
 			filter:     bson.D{{"v", 42}},
@@ -58,7 +57,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Name:    "Location15998",
 				Message: "FieldPath field names may not be empty strings.",
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"ExcludeInclude": {
 			filter:     bson.D{},
@@ -68,7 +66,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Name:    "Location31253",
 				Message: "Cannot do inclusion on field bar in exclusion projection",
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"IncludeExclude": {
 			filter:     bson.D{},
@@ -96,7 +93,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				"for example: a.b.$. If the query previously used a form " +
 				"like a.b.$.d, remove the parts following the '$' and " +
 				"the results will be equivalent.",
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"PositionalOperatorMiddle": {
 			filter:     bson.D{{"_id", "array-numbers-asc"}},
@@ -114,7 +110,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				"for example: a.b.$. If the query previously used a form " +
 				"like a.b.$.d, remove the parts following the '$' and " +
 				"the results will be equivalent.",
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"PositionalOperatorWrongLocations": {
 			filter:     bson.D{{"v", 42}},
@@ -132,7 +127,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				"used at the end, for example: a.b.$. If the query previously " +
 				"used a form like a.b.$.d, remove the parts following the '$' and " +
 				"the results will be equivalent.",
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"PositionalOperatorEmptyFilter": {
 			filter:     bson.D{},
@@ -185,7 +179,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Name:    "Location40353",
 				Message: "FieldPath must not end with a '.'.",
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"PositionalOperatorDollarKey": {
 			filter:     bson.D{{"v", 42}},
@@ -195,7 +188,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Name:    "Location16410",
 				Message: "FieldPath field names may not start with '$'. Consider using $getField or $setField.",
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"PositionalOperatorDollarInKey": {
 			filter:     bson.D{{"v", 42}},
@@ -205,7 +197,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Name:    "Location16410",
 				Message: "FieldPath field names may not start with '$'. Consider using $getField or $setField.",
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"PositionalOperatorDollarPrefix": {
 			filter:     bson.D{{"v", 42}},
@@ -215,7 +206,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Name:    "Location16410",
 				Message: "FieldPath field names may not start with '$'. Consider using $getField or $setField.",
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"PositionalOperatorDotNotationDollarInKey": {
 			filter:     bson.D{{"v", 42}},
@@ -225,7 +215,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Name:    "Location16410",
 				Message: "FieldPath field names may not start with '$'. Consider using $getField or $setField.",
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"PositionalOperatorPrefixSuffix": {
 			filter:     bson.D{{"_id", "array-numbers-asc"}},
@@ -235,7 +224,6 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Name:    "Location16410",
 				Message: "FieldPath field names may not start with '$'. Consider using $getField or $setField.",
 			},
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/241",
 		},
 		"PositionalOperatorExclusion": {
 			filter:     bson.D{{"v", 42}},
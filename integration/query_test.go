@@ -655,9 +655,9 @@ func TestQueryShowRecordID(t *testing.T) {
 		failsForFerretDB string
 	}{
 		"ShowRecordID": {
-			showRecordID:     true,
-			collection:       collection,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/242",
+			showRecordID:    true,
+			collection:      collection,
+			nonZeroRecordID: true,
 		},
 		"ShowRecordIDFalse": {
 			showRecordID: false,
@@ -772,10 +772,8 @@ func TestQueryShowRecordIDErrors(t *testing.T) {
 			altMessage: "BSON field 'find.showRecordId' is the wrong type 'string', expected type 'bool'",
 		},
 	} {
-		t.Run(name, func(tt *testing.T) {
-			tt.Parallel()
-
-			t := setup.FailsForFerretDB(tt, "https://github.com/FerretDB/FerretDB-DocumentDB/issues/242")
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
 			require.NotNil(t, tc.err, "err must not be nil")
 
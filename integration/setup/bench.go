@@ -0,0 +1,71 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// PushBenchmarkResult pushes the current benchmark result (time per operation)
+// to Prometheus Pushgateway if -bench-push-url flag is set.
+//
+// It should be called at the end of the benchmark function, after the [testing.B.Loop] loop.
+// Results are also printed by `go test` in the usual format that is compatible with benchstat.
+func PushBenchmarkResult(b *testing.B) {
+	b.Helper()
+
+	if *benchPushURLF == "" || b.N == 0 {
+		return
+	}
+
+	backend := *targetBackendF
+	if backend == "" {
+		backend = "ferretdb"
+	}
+
+	u, err := url.Parse(*benchPushURLF)
+	require.NoError(b, err)
+
+	// group results by benchmark and backend, so they do not overwrite each other;
+	// benchmark names contain slashes, so they are encoded
+	name := base64.RawURLEncoding.EncodeToString([]byte(b.Name()))
+	u = u.JoinPath("metrics", "job", "ferretdb_bench", "benchmark@base64", name, "backend", backend)
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "# TYPE ferretdb_bench_ns_per_op gauge\n")
+	fmt.Fprintf(&body, "ferretdb_bench_ns_per_op %d\n", b.Elapsed().Nanoseconds()/int64(b.N))
+	fmt.Fprintf(&body, "# TYPE ferretdb_bench_iterations gauge\n")
+	fmt.Fprintf(&body, "ferretdb_bench_iterations %d\n", b.N)
+
+	client := http.Client{Timeout: 10 * time.Second}
+
+	req, err := http.NewRequestWithContext(b.Context(), http.MethodPut, u.String(), &body)
+	require.NoError(b, err)
+
+	resp, err := client.Do(req)
+	require.NoError(b, err)
+
+	defer resp.Body.Close() //nolint:errcheck // we are only reading it
+
+	require.Less(b, resp.StatusCode, 300, "unexpected Pushgateway status %s", resp.Status)
+}
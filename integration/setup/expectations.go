@@ -0,0 +1,254 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// expectationsDir is the directory with expectation files, relative to the test package directory.
+const expectationsDir = "testdata/expectations"
+
+// expectations contains sorted names of providers failing for FerretDB, keyed by test case name.
+//
+// Each top-level test has a separate file.
+// A test case without an entry is expected to fail for all providers.
+type expectations map[string][]string
+
+// expectationsM protects loaded and recorded expectations, keyed by top-level test name.
+var expectationsM struct {
+	sync.Mutex
+	loaded   map[string]expectations
+	recorded map[string]expectations
+}
+
+// expectationsPath returns the path of the expectation file for the given top-level test.
+func expectationsPath(test string) string {
+	return filepath.Join(expectationsDir, test+".json")
+}
+
+// readExpectations reads the expectation file; nil is returned if it does not exist.
+func readExpectations(test string) (expectations, error) {
+	b, err := os.ReadFile(expectationsPath(test))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res expectations
+	if err = json.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// splitTestName splits the name of the provider subtest into the top-level test name and the test case name.
+func splitTestName(tb testing.TB) (string, string) {
+	parts := strings.Split(tb.Name(), "/")
+	if len(parts) < 3 {
+		return parts[0], ""
+	}
+
+	return parts[0], strings.Join(parts[1:len(parts)-1], "/")
+}
+
+// FailsForFerretDBProvider returns testing.TB for the compat test case subtest of the given provider.
+//
+// The returned value expects the test to fail for FerretDB (see [FailsForFerretDB])
+// if the provider is listed in the expectation file of the test case,
+// or if there is no such entry.
+// That removes the need to maintain lists of failing providers by hand.
+//
+// If -update-expectations flag was passed, failures are recorded instead,
+// and expectation files are updated after all tests are run.
+func FailsForFerretDBProvider(tb testing.TB, url, provider string) testing.TB {
+	tb.Helper()
+
+	ensureIssueURL(url)
+
+	if IsMongoDB(tb) {
+		return tb
+	}
+
+	test, tc := splitTestName(tb)
+
+	if *updateExpectationsF {
+		r := &recorder{TB: tb}
+
+		tb.Cleanup(func() {
+			recordExpectation(test, tc, provider, r.failed.Load())
+		})
+
+		return r
+	}
+
+	expectationsM.Lock()
+
+	if expectationsM.loaded == nil {
+		expectationsM.loaded = make(map[string]expectations)
+	}
+
+	e, ok := expectationsM.loaded[test]
+	if !ok {
+		var err error
+		e, err = readExpectations(test)
+
+		if err != nil {
+			expectationsM.Unlock()
+			require.NoError(tb, err)
+		}
+
+		expectationsM.loaded[test] = e
+	}
+
+	failing, ok := e[tc]
+
+	expectationsM.Unlock()
+
+	if ok && !slices.Contains(failing, provider) {
+		return tb
+	}
+
+	return FailsForFerretDB(tb, url)
+}
+
+// recordExpectation records the result of the provider subtest.
+func recordExpectation(test, tc, provider string, failed bool) {
+	expectationsM.Lock()
+	defer expectationsM.Unlock()
+
+	if expectationsM.recorded == nil {
+		expectationsM.recorded = make(map[string]expectations)
+	}
+
+	e := expectationsM.recorded[test]
+	if e == nil {
+		e = make(expectations)
+		expectationsM.recorded[test] = e
+	}
+
+	// record test cases where all providers pass, too
+	if _, ok := e[tc]; !ok {
+		e[tc] = []string{}
+	}
+
+	if failed {
+		e[tc] = append(e[tc], provider)
+		slices.Sort(e[tc])
+	}
+}
+
+// writeExpectations merges recorded results into expectation files.
+func writeExpectations() error {
+	expectationsM.Lock()
+	defer expectationsM.Unlock()
+
+	for test, recorded := range expectationsM.recorded {
+		e, err := readExpectations(test)
+		if err != nil {
+			return err
+		}
+
+		if e == nil {
+			e = make(expectations)
+		}
+
+		for tc, failing := range recorded {
+			e[tc] = failing
+		}
+
+		b, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err = os.MkdirAll(expectationsDir, 0o777); err != nil {
+			return err
+		}
+
+		if err = os.WriteFile(expectationsPath(test), append(b, '\n'), 0o666); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recorder is a testing.TB that records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	failed atomic.Bool
+}
+
+// Failed implements testing.TB.
+func (r *recorder) Failed() bool {
+	return r.failed.Load()
+}
+
+// Fail implements testing.TB.
+func (r *recorder) Fail() {
+	r.failed.Store(true)
+}
+
+// Error implements testing.TB.
+func (r *recorder) Error(args ...any) {
+	r.Log(args...)
+	r.Fail()
+}
+
+// Errorf implements testing.TB.
+func (r *recorder) Errorf(format string, args ...any) {
+	r.Logf(format, args...)
+	r.Fail()
+}
+
+// FailNow implements testing.TB.
+func (r *recorder) FailNow() {
+	r.Fail()
+
+	// runtime.Goexit would not work
+	r.SkipNow()
+}
+
+// Fatal implements testing.TB.
+func (r *recorder) Fatal(args ...any) {
+	r.Log(args...)
+	r.FailNow()
+}
+
+// Fatalf implements testing.TB.
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Logf(format, args...)
+	r.FailNow()
+}
+
+// check interfaces
+var (
+	_ testing.TB = (*recorder)(nil)
+)
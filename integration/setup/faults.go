@@ -0,0 +1,37 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"testing"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/testutil/testfaults"
+)
+
+// NewFaults returns a new proxy that injects faults into PostgreSQL connections
+// of in-process FerretDB. It should be passed to [SetupWithOpts] via [ListenerOpts].
+//
+// It skips the test for MongoDB and for FerretDB running in a separate process.
+func NewFaults(tb testing.TB) *testfaults.Proxy {
+	tb.Helper()
+
+	SkipForMongoDB(tb, "faults are injected into PostgreSQL connections")
+
+	if *targetURLF != "" {
+		tb.Skip("faults can be injected only into in-process FerretDB")
+	}
+
+	return testfaults.New(tb, *postgreSQLURLF)
+}
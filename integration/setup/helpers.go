@@ -16,6 +16,7 @@
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -38,6 +39,11 @@ func IsMongoDB(tb testing.TB) bool {
 	return *targetBackendF == "mongodb"
 }
 
+// StrictErrors returns true if error messages should be compared in compat tests (-strict-errors flag).
+func StrictErrors() bool {
+	return *strictErrorsF
+}
+
 // ensureIssueURL panics if URL is not a valid FerretDB issue URL.
 func ensureIssueURL(url string) {
 	ferretDB := strings.HasPrefix(url, "https://github.com/FerretDB/FerretDB/issues/")
@@ -128,5 +134,12 @@ func() {
 		code = m.Run()
 	}()
 
+	if *updateExpectationsF {
+		if err := writeExpectations(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write expectations: %s\n", err)
+			code = 1
+		}
+	}
+
 	os.Exit(code)
 }
@@ -31,12 +31,16 @@
 	"github.com/FerretDB/FerretDB/v2/internal/handler"
 	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
 	"github.com/FerretDB/FerretDB/v2/internal/util/state"
+	"github.com/FerretDB/FerretDB/v2/internal/util/testutil/testfaults"
 )
 
 // ListenerOpts represents setup options for in-process FerretDB listener.
 type ListenerOpts struct {
 	// SessionCleanupInterval is a duration between expired session deletion runs.
 	SessionCleanupInterval time.Duration
+
+	// Faults is a proxy that injects faults into PostgreSQL connections, see [NewFaults].
+	Faults *testfaults.Proxy
 }
 
 // unixSocketPath returns temporary Unix domain socket path for that test.
@@ -109,7 +113,12 @@ func setupListener(tb testing.TB, ctx context.Context, opts *ListenerOpts, logge
 		opts = new(ListenerOpts)
 	}
 
-	p, err := documentdb.NewPool(*postgreSQLURLF, logging.WithName(logger, "pool"), sp)
+	postgreSQLURL := *postgreSQLURLF
+	if opts.Faults != nil {
+		postgreSQLURL = opts.Faults.URL()
+	}
+
+	p, err := documentdb.NewPool(postgreSQLURL, logging.WithName(logger, "pool"), sp)
 	require.NoError(tb, err)
 
 	handlerOpts := &handler.NewOpts{
@@ -125,6 +134,8 @@ func setupListener(tb testing.TB, ctx context.Context, opts *ListenerOpts, logge
 		StateProvider: sp,
 
 		SessionCleanupInterval: opts.SessionCleanupInterval,
+
+		Parameters: map[string]string{"enableTestCommands": "1"},
 	}
 
 	h, err := handler.New(handlerOpts)
@@ -53,7 +53,12 @@
 
 	noXFailF = flag.Bool("no-xfail", false, "Disallow expected failures")
 
-	benchDocsF = flag.Int("bench-docs", 1000, "benchmarks: number of documents to generate per iteration")
+	updateExpectationsF = flag.Bool("update-expectations", false, "Record failing providers into expectation files")
+
+	strictErrorsF = flag.Bool("strict-errors", false, "Compare error messages in compat tests")
+
+	benchDocsF    = flag.Int("bench-docs", 1000, "benchmarks: number of documents to generate per iteration")
+	benchPushURLF = flag.String("bench-push-url", "", "benchmarks: Prometheus Pushgateway URL to push results to; if empty, they are not pushed")
 
 	// Disable noisy setup logs by default.
 	debugSetupF = flag.Bool("debug-setup", false, "enable debug logs for tests setup")
@@ -54,6 +54,8 @@ type SetupCompatResult struct {
 	Ctx               context.Context
 	TargetCollections []*mongo.Collection
 	CompatCollections []*mongo.Collection
+	TargetURI         string // without database name
+	CompatURI         string // without database name
 }
 
 // SetupCompatWithOpts setups the compatibility test according to given options.
@@ -113,6 +115,8 @@ func SetupCompatWithOpts(tb testing.TB, opts *SetupCompatOpts) *SetupCompatResul
 		Ctx:               ctx,
 		TargetCollections: targetCollections,
 		CompatCollections: compatCollections,
+		TargetURI:         uri,
+		CompatURI:         *compatURLF,
 	}
 }
 
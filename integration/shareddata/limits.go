@@ -0,0 +1,115 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shareddata
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Limits of MongoDB documents.
+const (
+	// maxDocumentSize is the maximum BSON document size.
+	maxDocumentSize = 16 * 1024 * 1024
+
+	// maxDocumentDepth is the maximum nesting depth of BSON documents, including the top-level one.
+	maxDocumentDepth = 100
+)
+
+// LargeDocuments contains documents near the BSON document size limit for tests.
+var LargeDocuments = &Values[string]{
+	name: "LargeDocuments",
+	data: map[string]any{
+		"string-1mib": strings.Repeat("x", 1024*1024),
+
+		// leave some room for small updates
+		"string-max": stringForSize("string-max", maxDocumentSize-1024),
+
+		"array-strings": bson.A{
+			strings.Repeat("a", 4*1024*1024),
+			strings.Repeat("b", 4*1024*1024),
+			strings.Repeat("c", 4*1024*1024),
+		},
+	},
+}
+
+// LargeArrays contains documents with huge arrays for tests.
+var LargeArrays = &Values[string]{
+	name: "LargeArrays",
+	data: map[string]any{
+		"array-int32s":    largeArray(100_000, func(i int) any { return int32(i) }),
+		"array-documents": largeArray(10_000, func(i int) any { return bson.D{{"v", int32(i)}} }),
+		"array-arrays":    largeArray(10_000, func(i int) any { return bson.A{int32(i)} }),
+	},
+}
+
+// DocumentsMaxDepth contains documents nested up to the BSON document depth limit for tests.
+var DocumentsMaxDepth = &Values[string]{
+	name: "DocumentsMaxDepth",
+	data: map[string]any{
+		// 99 levels including the top-level document, as the limit is not strict on all systems
+		"documents": nestedValue(maxDocumentDepth-2, func(v any) any { return bson.D{{"v", v}} }),
+		"arrays":    nestedValue(maxDocumentDepth-2, func(v any) any { return bson.A{v} }),
+	},
+}
+
+// LongFieldNames contains documents with long field names for tests.
+var LongFieldNames = NewTopLevelFieldsProvider("LongFieldNames", map[string]Fields{
+	"top-level-1kib": {
+		{Key: strings.Repeat("f", 1024), Value: int32(42)},
+	},
+	"top-level-64kib": {
+		{Key: strings.Repeat("f", 64*1024), Value: int32(42)},
+	},
+	"nested-1kib": {
+		{Key: "v", Value: bson.D{{strings.Repeat("f", 1024), int32(42)}}},
+	},
+	"many": {
+		{Key: strings.Repeat("a", 1024), Value: int32(1)},
+		{Key: strings.Repeat("b", 1024), Value: int32(2)},
+		{Key: strings.Repeat("c", 1024), Value: int32(3)},
+	},
+})
+
+// stringForSize returns a string value that makes {_id: id, v: value} document of the given size.
+func stringForSize(id string, size int) string {
+	b, err := bson.Marshal(bson.D{{"_id", id}, {"v", ""}})
+	if err != nil {
+		panic(err)
+	}
+
+	return strings.Repeat("x", size-len(b))
+}
+
+// largeArray returns an array of n values returned by f.
+func largeArray(n int, f func(int) any) bson.A {
+	res := make(bson.A, n)
+	for i := range n {
+		res[i] = f(i)
+	}
+
+	return res
+}
+
+// nestedValue returns a value nested the given number of levels by f, with int32 42 at the bottom.
+func nestedValue(levels int, f func(any) any) any {
+	var res any = int32(42)
+	for range levels {
+		res = f(res)
+	}
+
+	return res
+}
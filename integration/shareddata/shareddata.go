@@ -60,6 +60,11 @@ func AllProviders() Providers {
 
 		Mixed,
 		ArrayAndDocuments,
+
+		LargeDocuments,
+		LargeArrays,
+		DocumentsMaxDepth,
+		LongFieldNames,
 	}
 
 	names := make(map[string]struct{}, len(providers))
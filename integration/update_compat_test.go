@@ -693,6 +693,120 @@ func TestUpdateCommandCompat(t *testing.T) {
 	}
 }
 
+func TestUpdateCommandCompatInvalidStatements(t *testing.T) {
+	t.Parallel()
+
+	updates := bson.A{
+		bson.D{{"q", bson.D{{"_id", "array"}}}, {"u", bson.D{{"$set", bson.D{{"v", "updated"}}}}}},
+		bson.D{{"q", bson.D{{"_id", "non-existent"}}}, {"u", bson.D{{"$currentDate", bson.D{{"v", "date"}}}}}},
+		bson.D{{"q", bson.D{{"_id", "document"}}}, {"u", bson.D{{"$rename", bson.D{{"v", "v"}}}}}},
+		bson.D{{"q", bson.D{{"_id", "upserted"}}}, {"u", bson.D{{"$set", bson.D{{"v", "new"}}}}}, {"upsert", true}},
+		bson.D{{"q", bson.D{{"_id", "document"}}}, {"u", bson.D{{"$currentDate", bson.D{{"v", bson.D{{"$type", "string"}}}}}}}},
+		bson.D{{"q", bson.D{{"_id", "document"}}}, {"u", bson.D{{"$rename", bson.D{{"v", "v.foo"}}}}}},
+	}
+
+	for name, ordered := range map[string]bool{
+		"Ordered":   true,
+		"Unordered": false,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+				Providers: []shareddata.Provider{shareddata.Composites},
+			})
+			ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+			var targetRes, compatRes bson.D
+			err := targetCollection.Database().RunCommand(ctx, bson.D{
+				{"update", targetCollection.Name()},
+				{"updates", updates},
+				{"ordered", ordered},
+			}).Decode(&targetRes)
+			require.NoError(t, err)
+
+			err = compatCollection.Database().RunCommand(ctx, bson.D{
+				{"update", compatCollection.Name()},
+				{"updates", updates},
+				{"ordered", ordered},
+			}).Decode(&compatRes)
+			require.NoError(t, err)
+
+			assert.Equal(t, updateWriteErrors(compatRes), updateWriteErrors(targetRes))
+
+			for _, field := range []string{"n", "nModified", "upserted", "ok"} {
+				assert.Equal(t, compatRes.Map()[field], targetRes.Map()[field], field)
+			}
+
+			AssertEqualDocumentsSlice(t, FindAll(t, ctx, compatCollection), FindAll(t, ctx, targetCollection))
+		})
+	}
+}
+
+func TestUpdateCommandCompatOrderedDuplicateKey(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers:                []shareddata.Provider{},
+		AddNonExistentCollection: true,
+	})
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	var res []bson.D
+
+	for _, c := range []*mongo.Collection{compatCollection, targetCollection} {
+		_, err := c.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{"v", 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		require.NoError(t, err)
+
+		_, err = c.InsertMany(ctx, []any{bson.D{{"_id", int32(1)}, {"v", int32(1)}}, bson.D{{"_id", int32(2)}, {"v", int32(2)}}})
+		require.NoError(t, err)
+
+		// execution stops at the duplicate key error, so the invalid $rename is not reported
+		var r bson.D
+		err = c.Database().RunCommand(ctx, bson.D{
+			{"update", c.Name()},
+			{"updates", bson.A{
+				bson.D{{"q", bson.D{{"_id", int32(2)}}}, {"u", bson.D{{"$set", bson.D{{"v", int32(1)}}}}}},
+				bson.D{{"q", bson.D{{"_id", int32(1)}}}, {"u", bson.D{{"$rename", bson.D{{"v", "v"}}}}}},
+			}},
+			{"ordered", true},
+		}).Decode(&r)
+		require.NoError(t, err)
+
+		res = append(res, r)
+	}
+
+	compatRes, targetRes := res[0], res[1]
+
+	expected := []bson.D{{{"index", int32(0)}, {"code", int32(11000)}}}
+	assert.Equal(t, expected, updateWriteErrors(compatRes))
+	assert.Equal(t, expected, updateWriteErrors(targetRes))
+
+	for _, field := range []string{"n", "nModified", "ok"} {
+		assert.Equal(t, compatRes.Map()[field], targetRes.Map()[field], field)
+	}
+
+	AssertEqualDocumentsSlice(t, FindAll(t, ctx, compatCollection), FindAll(t, ctx, targetCollection))
+}
+
+// updateWriteErrors returns indexes and codes of write errors of the given `update` command response.
+// Error messages are intentionally not returned.
+func updateWriteErrors(res bson.D) []bson.D {
+	var codes []bson.D
+
+	writeErrors, _ := res.Map()["writeErrors"].(bson.A)
+
+	for _, e := range writeErrors {
+		m := e.(bson.D).Map()
+		codes = append(codes, bson.D{{"index", m["index"]}, {"code", m["code"]}})
+	}
+
+	return codes
+}
+
 func TestUpdateCompat(t *testing.T) {
 	t.Parallel()
 
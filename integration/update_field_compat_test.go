@@ -1069,9 +1069,8 @@ func TestUpdateFieldCompatRename(t *testing.T) {
 			update: bson.D{{"$rename", bson.D{{"v", "foo"}}}},
 		},
 		"DuplicateField": {
-			update:           bson.D{{"$rename", bson.D{{"v", "v"}}}},
-			resultType:       EmptyResult,
-			failsForFerretDB: "https://github.com/FerretDB/FerretDB-DocumentDB/issues/429",
+			update:     bson.D{{"$rename", bson.D{{"v", "v"}}}},
+			resultType: EmptyResult,
 		},
 		"NonExistingField": {
 			update:     bson.D{{"$rename", bson.D{{"foo", "bar"}}}},
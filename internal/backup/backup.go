@@ -0,0 +1,232 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup implements backup and restore of FerretDB data
+// in the directory format of mongodump and mongorestore tools.
+//
+// Each database is stored in a separate directory.
+// Each collection is stored in two files in that directory:
+// <collection>.bson with concatenated BSON documents,
+// and <collection>.metadata.json with collection options and indexes in canonical Extended JSON.
+// Both files could be compressed with gzip; in that case, they have an additional .gz extension.
+//
+// It also implements export and import of a single collection in Extended JSON v2 and CSV formats
+// that are compatible with mongoexport and mongoimport tools.
+//
+// Live migration from MongoDB (initial sync followed by change stream tailing) is implemented too.
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// File name suffixes.
+const (
+	bsonExt     = ".bson"
+	metadataExt = ".metadata.json"
+	gzipExt     = ".gz"
+)
+
+// Collection types in metadata.
+const (
+	typeCollection = "collection"
+	typeView       = "view"
+)
+
+// maxDocumentSize is the maximum size of a single BSON document in the .bson file.
+const maxDocumentSize = 16 * 1024 * 1024
+
+// metadata represents the content of the .metadata.json file.
+type metadata struct {
+	Indexes        []*wirebson.Document `json:"indexes"`
+	UUID           string               `json:"uuid,omitempty"`
+	CollectionName string               `json:"collectionName"`
+	Type           string               `json:"type"`
+	Options        *wirebson.Document   `json:"options"`
+}
+
+// writeMetadata writes metadata to the given file.
+func writeMetadata(path string, gz bool, md *metadata) error {
+	b, err := json.Marshal(md)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	w, err := createFile(path, gz)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if _, err = w.Write(b); err != nil {
+		_ = w.Close()
+		return lazyerrors.Error(err)
+	}
+
+	if err = w.Close(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// readMetadata reads metadata from the given file.
+func readMetadata(path string, gz bool) (*metadata, error) {
+	r, err := openFile(path, gz)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer r.Close() //nolint:errcheck // we are only reading
+
+	var md metadata
+	if err = json.NewDecoder(r).Decode(&md); err != nil {
+		return nil, lazyerrors.Errorf("%s: %w", path, err)
+	}
+
+	if md.Options == nil {
+		md.Options = wirebson.MakeDocument(0)
+	}
+
+	return &md, nil
+}
+
+// readDocument reads the next BSON document from r.
+// It returns [io.EOF] if there are no more documents.
+func readDocument(r io.Reader) (wirebson.RawDocument, error) {
+	var size [4]byte
+
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	l := int(binary.LittleEndian.Uint32(size[:]))
+	if l < 5 || l > maxDocumentSize {
+		return nil, lazyerrors.Errorf("invalid document size %d", l)
+	}
+
+	doc := make([]byte, l)
+	copy(doc, size[:])
+
+	if _, err := io.ReadFull(r, doc[4:]); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return doc, nil
+}
+
+// file is a buffered, optionally gzip-compressed file.
+type file struct {
+	f  *os.File
+	gz *gzip.Writer
+	gr *gzip.Reader
+	bw *bufio.Writer
+	br *bufio.Reader
+}
+
+// createFile creates a new file for writing.
+func createFile(path string, gz bool) (*file, error) {
+	if gz {
+		path += gzipExt
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := &file{f: f}
+
+	var w io.Writer = f
+
+	if gz {
+		res.gz = gzip.NewWriter(f)
+		w = res.gz
+	}
+
+	res.bw = bufio.NewWriter(w)
+
+	return res, nil
+}
+
+// openFile opens the file for reading.
+func openFile(path string, gz bool) (*file, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := &file{f: f}
+
+	var r io.Reader = f
+
+	if gz {
+		if res.gr, err = gzip.NewReader(f); err != nil {
+			_ = f.Close()
+			return nil, lazyerrors.Errorf("%s: %w", path, err)
+		}
+
+		r = res.gr
+	}
+
+	res.br = bufio.NewReader(r)
+
+	return res, nil
+}
+
+// Read implements [io.Reader].
+func (f *file) Read(p []byte) (int, error) {
+	return f.br.Read(p)
+}
+
+// Write implements [io.Writer].
+func (f *file) Write(p []byte) (int, error) {
+	return f.bw.Write(p)
+}
+
+// Close flushes buffered data, syncs written files, and closes the file.
+func (f *file) Close() error {
+	if f.br != nil {
+		if f.gr != nil {
+			_ = f.gr.Close()
+		}
+
+		return f.f.Close()
+	}
+
+	err := f.bw.Flush()
+
+	if f.gz != nil {
+		err = errors.Join(err, f.gz.Close())
+	}
+
+	if err == nil {
+		err = f.f.Sync()
+	}
+
+	return errors.Join(err, f.f.Close())
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestFiles(t *testing.T) {
+	t.Parallel()
+
+	for name, gz := range map[string]bool{"Plain": false, "Gzip": true} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			md := &metadata{
+				Indexes: []*wirebson.Document{
+					must.NotFail(wirebson.NewDocument(
+						"v", int32(2),
+						"key", must.NotFail(wirebson.NewDocument("_id", int32(1))),
+						"name", "_id_",
+					)),
+				},
+				UUID:           "0123456789abcdef0123456789abcdef",
+				CollectionName: "test",
+				Type:           typeCollection,
+				Options:        wirebson.MakeDocument(0),
+			}
+
+			path := filepath.Join(dir, "test"+metadataExt)
+			require.NoError(t, writeMetadata(path, gz, md))
+
+			if gz {
+				path += gzipExt
+			}
+
+			actual, err := readMetadata(path, gz)
+			require.NoError(t, err)
+			assert.Equal(t, md.CollectionName, actual.CollectionName)
+			assert.Equal(t, md.UUID, actual.UUID)
+			require.Len(t, actual.Indexes, 1)
+			assert.Equal(t, md.Indexes[0].LogMessage(), actual.Indexes[0].LogMessage())
+
+			docs := []wirebson.RawDocument{
+				must.NotFail(must.NotFail(wirebson.NewDocument("_id", int32(1))).Encode()),
+				must.NotFail(must.NotFail(wirebson.NewDocument("_id", "two", "v", 42.0)).Encode()),
+			}
+
+			path = filepath.Join(dir, "test"+bsonExt)
+
+			w, err := createFile(path, gz)
+			require.NoError(t, err)
+
+			for _, doc := range docs {
+				_, err = w.Write(doc)
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, w.Close())
+
+			if gz {
+				path += gzipExt
+			}
+
+			r, err := openFile(path, gz)
+			require.NoError(t, err)
+
+			t.Cleanup(func() { require.NoError(t, r.Close()) })
+
+			for _, expected := range docs {
+				doc, err := readDocument(r)
+				require.NoError(t, err)
+				assert.Equal(t, expected, doc)
+			}
+
+			_, err = readDocument(r)
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestCSV(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(wirebson.NewDocument(
+		"_id", wirebson.ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0xba, 0xda, 0x55},
+		"s", "foo",
+		"i", int32(42),
+		"l", int64(-1),
+		"d", 42.5,
+		"b", true,
+		"t", time.Date(2024, 1, 2, 3, 4, 5, 6_000_000, time.UTC),
+		"n", wirebson.Null,
+		"doc", must.NotFail(wirebson.NewDocument("a", must.NotFail(wirebson.NewArray("x", int32(1))))),
+	))
+
+	for path, expected := range map[string]string{
+		"_id":     "6256c5ba0badc0ffeebada55",
+		"s":       "foo",
+		"i":       "42",
+		"l":       "-1",
+		"d":       "42.5",
+		"b":       "true",
+		"t":       "2024-01-02T03:04:05.006Z",
+		"n":       "",
+		"missing": "",
+		"doc.a.0": "x",
+		"doc.a":   `["x",{"$numberInt":"1"}]`,
+	} {
+		actual, err := csvValue(getPath(doc, path))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, path)
+	}
+
+	imported := wirebson.MakeDocument(0)
+
+	for path, typ := range map[string]string{
+		"_id":   TypeObjectID,
+		"s":     TypeString,
+		"i":     TypeAuto,
+		"l":     TypeInt64,
+		"d":     TypeAuto,
+		"b":     TypeBoolean,
+		"t":     TypeDate,
+		"doc.x": TypeAuto,
+	} {
+		s, err := csvValue(getPath(doc, path))
+		require.NoError(t, err)
+
+		if path == "doc.x" {
+			s = "NaN"
+		}
+
+		v, err := parseCSVValue(s, typ)
+		require.NoError(t, err, path)
+		require.NoError(t, setPath(imported, path, v))
+	}
+
+	for _, path := range []string{"_id", "s", "i", "l", "d", "b", "t"} {
+		assert.Equal(t, getPath(doc, path), getPath(imported, path), path)
+	}
+
+	assert.Equal(t, "NaN", getPath(imported, "doc.x"))
+
+	_, err := parseCSVValue("foo", "unknown")
+	assert.Error(t, err)
+}
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "migrate.json")
+
+	cp, err := readCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, new(checkpoint), cp)
+
+	cp.ResumeToken = "8263F0A1B2000000012B022C0100296E5A1004"
+	cp.Synced = []string{"db.foo", "db.bar"}
+	require.NoError(t, writeCheckpoint(path, cp))
+
+	actual, err := readCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, cp, actual)
+}
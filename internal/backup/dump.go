@@ -0,0 +1,362 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// DumpOpts represents [Dump] options.
+type DumpOpts struct {
+	Pool *documentdb.Pool
+	L    *slog.Logger
+
+	// Dir is the output directory; it is created if needed.
+	Dir string
+
+	// DB is the database to dump; all databases are dumped if empty.
+	DB string
+
+	// Collection is the collection to dump; all collections are dumped if empty.
+	// It requires DB to be set.
+	Collection string
+
+	// Gzip enables compression of output files.
+	Gzip bool
+}
+
+// Dump writes databases and collections to the directory in mongodump format.
+func Dump(ctx context.Context, opts *DumpOpts) error {
+	if opts.Collection != "" && opts.DB == "" {
+		return lazyerrors.New("collection is set without database")
+	}
+
+	dbs := []string{opts.DB}
+
+	if opts.DB == "" {
+		var err error
+		if dbs, err = listDatabases(ctx, opts.Pool, opts.L); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	for _, db := range dbs {
+		if err := dumpDatabase(ctx, opts, db); err != nil {
+			return lazyerrors.Errorf("%s: %w", db, err)
+		}
+	}
+
+	return nil
+}
+
+// listDatabases returns names of all databases.
+func listDatabases(ctx context.Context, p *documentdb.Pool, l *slog.Logger) ([]string, error) {
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument("listDatabases", int32(1), "nameOnly", true)).Encode())
+
+	var res wirebson.RawDocument
+
+	err := p.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		res, err = documentdb_api.ListDatabases(ctx, conn, l, spec)
+		return err
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	doc, err := res.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	arr, ok := doc.Get("databases").(*wirebson.Array)
+	if !ok {
+		return nil, lazyerrors.Errorf("unexpected listDatabases response: %s", doc.LogMessage())
+	}
+
+	var dbs []string
+
+	for v := range arr.Values() {
+		d, ok := v.(*wirebson.Document)
+		if !ok {
+			return nil, lazyerrors.Errorf("unexpected listDatabases response: %s", doc.LogMessage())
+		}
+
+		name, _ := d.Get("name").(string)
+		if name != "" {
+			dbs = append(dbs, name)
+		}
+	}
+
+	return dbs, nil
+}
+
+// dumpDatabase dumps all or selected collection of the given database.
+func dumpDatabase(ctx context.Context, opts *DumpOpts, db string) error {
+	mds, err := listCollections(ctx, opts.Pool, db, opts.Collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if len(mds) == 0 {
+		return nil
+	}
+
+	dir := filepath.Join(opts.Dir, db)
+	if err = os.MkdirAll(dir, 0o777); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, md := range mds {
+		if err = dumpCollection(ctx, opts, db, dir, md); err != nil {
+			return lazyerrors.Errorf("%s: %w", md.CollectionName, err)
+		}
+	}
+
+	return nil
+}
+
+// listCollections returns metadata without indexes of all or selected collection and views of the given database.
+// System collections are skipped.
+func listCollections(ctx context.Context, p *documentdb.Pool, db, collection string) ([]*metadata, error) {
+	spec := must.NotFail(wirebson.NewDocument("listCollections", int32(1)))
+
+	if collection != "" {
+		must.NoError(spec.Add("filter", must.NotFail(wirebson.NewDocument("name", collection))))
+	}
+
+	page, cursorID, err := p.ListCollections(ctx, db, must.NotFail(spec.Encode()))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var mds []*metadata
+
+	err = iterateCursor(ctx, p, db, "$cmd.listCollections", page, cursorID, func(raw wirebson.RawDocument) error {
+		info, err := raw.DecodeDeep()
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		md := &metadata{
+			Indexes: []*wirebson.Document{},
+			Type:    typeCollection,
+			Options: wirebson.MakeDocument(0),
+		}
+
+		md.CollectionName, _ = info.Get("name").(string)
+		if md.CollectionName == "" || strings.HasPrefix(md.CollectionName, "system.") {
+			return nil
+		}
+
+		if t, _ := info.Get("type").(string); t != "" {
+			md.Type = t
+		}
+
+		if o, _ := info.Get("options").(*wirebson.Document); o != nil {
+			md.Options = o
+		}
+
+		if i, _ := info.Get("info").(*wirebson.Document); i != nil {
+			if uuid, ok := i.Get("uuid").(wirebson.Binary); ok {
+				md.UUID = hex.EncodeToString(uuid.B)
+			}
+		}
+
+		mds = append(mds, md)
+
+		return nil
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return mds, nil
+}
+
+// dumpCollection writes documents and metadata of the given collection or view.
+func dumpCollection(ctx context.Context, opts *DumpOpts, db, dir string, md *metadata) error {
+	base := filepath.Join(dir, md.CollectionName)
+
+	if md.Type != typeCollection {
+		opts.L.InfoContext(ctx, "Dumped view", slog.String("db", db), slog.String("view", md.CollectionName))
+		return writeMetadata(base+metadataExt, opts.Gzip, md)
+	}
+
+	if err := listIndexes(ctx, opts.Pool, db, md); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	w, err := createFile(base+bsonExt, opts.Gzip)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument("find", md.CollectionName)).Encode())
+
+	page, cursorID, err := opts.Pool.Find(ctx, db, spec)
+	if err != nil {
+		_ = w.Close()
+		return lazyerrors.Error(err)
+	}
+
+	var n int
+
+	err = iterateCursor(ctx, opts.Pool, db, md.CollectionName, page, cursorID, func(raw wirebson.RawDocument) error {
+		n++
+
+		_, err := w.Write(raw)
+
+		return err
+	})
+	if err != nil {
+		_ = w.Close()
+		return lazyerrors.Error(err)
+	}
+
+	if err = w.Close(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = writeMetadata(base+metadataExt, opts.Gzip, md); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	opts.L.InfoContext(
+		ctx, "Dumped collection",
+		slog.String("db", db), slog.String("collection", md.CollectionName), slog.Int("documents", n),
+	)
+
+	return nil
+}
+
+// listIndexes adds indexes of the given collection to metadata.
+func listIndexes(ctx context.Context, p *documentdb.Pool, db string, md *metadata) error {
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument("listIndexes", md.CollectionName)).Encode())
+
+	page, cursorID, err := p.ListIndexes(ctx, db, spec)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return iterateCursor(ctx, p, db, md.CollectionName, page, cursorID, func(raw wirebson.RawDocument) error {
+		index, err := raw.DecodeDeep()
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		md.Indexes = append(md.Indexes, index)
+
+		return nil
+	})
+}
+
+// iterateCursor calls f for every document of the cursor, starting from the given first page.
+// The cursor is closed if iteration stops early.
+func iterateCursor(ctx context.Context, p *documentdb.Pool, db, collection string, page wirebson.RawDocument, cursorID int64, f func(wirebson.RawDocument) error) error { //nolint:lll // for readability
+	defer func() {
+		if cursorID != 0 {
+			p.KillCursor(ctx, cursorID)
+		}
+	}()
+
+	for {
+		docs, id, err := cursorPage(page)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		cursorID = id
+
+		for _, doc := range docs {
+			if err = f(doc); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		if cursorID == 0 {
+			return nil
+		}
+
+		spec := must.NotFail(must.NotFail(wirebson.NewDocument("getMore", cursorID, "collection", collection)).Encode())
+
+		if page, err = p.GetMore(ctx, db, spec, cursorID); err != nil {
+			// cursor is already closed on error
+			cursorID = 0
+			return lazyerrors.Error(err)
+		}
+	}
+}
+
+// cursorPage returns documents of the given cursor page and the cursor ID.
+func cursorPage(page wirebson.RawDocument) ([]wirebson.RawDocument, int64, error) {
+	doc, err := page.Decode()
+	if err != nil {
+		return nil, 0, lazyerrors.Error(err)
+	}
+
+	raw, ok := doc.Get("cursor").(wirebson.RawDocument)
+	if !ok {
+		return nil, 0, lazyerrors.Errorf("no cursor in the page: %s", doc.LogMessage())
+	}
+
+	cursor, err := raw.Decode()
+	if err != nil {
+		return nil, 0, lazyerrors.Error(err)
+	}
+
+	id, _ := cursor.Get("id").(int64)
+
+	batch := cursor.Get("firstBatch")
+	if batch == nil {
+		batch = cursor.Get("nextBatch")
+	}
+
+	rawArr, ok := batch.(wirebson.RawArray)
+	if !ok {
+		return nil, 0, lazyerrors.Errorf("no batch in the cursor: %s", cursor.LogMessage())
+	}
+
+	arr, err := rawArr.Decode()
+	if err != nil {
+		return nil, 0, lazyerrors.Error(err)
+	}
+
+	docs := make([]wirebson.RawDocument, 0, arr.Len())
+
+	for v := range arr.Values() {
+		d, ok := v.(wirebson.RawDocument)
+		if !ok {
+			return nil, 0, lazyerrors.Errorf("unexpected batch element type %T", v)
+		}
+
+		docs = append(docs, d)
+	}
+
+	return docs, id, nil
+}
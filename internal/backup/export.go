@@ -0,0 +1,149 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/FerretDB/wire/wirebson"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// ExportOpts represents [Export] options.
+type ExportOpts struct {
+	Pool *documentdb.Pool
+	L    *slog.Logger
+
+	DB         string
+	Collection string
+
+	// Format is one of [Formats]; [FormatJSON] is used if empty.
+	Format string
+
+	// Fields are exported CSV columns as dotted paths. Required for [FormatCSV].
+	Fields []string
+
+	// Relaxed enables relaxed Extended JSON instead of canonical.
+	Relaxed bool
+
+	// Filter, Skip, and Limit select exported documents; they are optional.
+	Filter *wirebson.Document
+	Skip   int64
+	Limit  int64
+}
+
+// Export writes documents of the collection to w
+// as Extended JSON v2 (one document per line) or as CSV with a header row.
+// It returns the number of exported documents.
+func Export(ctx context.Context, w io.Writer, opts *ExportOpts) (int, error) {
+	format := opts.Format
+	if format == "" {
+		format = FormatJSON
+	}
+
+	if !slices.Contains(Formats, format) {
+		return 0, lazyerrors.Errorf("unknown format %q", format)
+	}
+
+	if format == FormatCSV && len(opts.Fields) == 0 {
+		return 0, lazyerrors.New("fields are required for CSV export")
+	}
+
+	spec := must.NotFail(wirebson.NewDocument("find", opts.Collection))
+
+	if opts.Filter != nil {
+		must.NoError(spec.Add("filter", opts.Filter))
+	}
+
+	if opts.Skip > 0 {
+		must.NoError(spec.Add("skip", opts.Skip))
+	}
+
+	if opts.Limit > 0 {
+		must.NoError(spec.Add("limit", opts.Limit))
+	}
+
+	rawSpec, err := spec.Encode()
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	var cw *csv.Writer
+
+	if format == FormatCSV {
+		cw = csv.NewWriter(w)
+
+		if err = cw.Write(opts.Fields); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+	}
+
+	page, cursorID, err := opts.Pool.Find(ctx, opts.DB, rawSpec)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	var n int
+	record := make([]string, len(opts.Fields))
+
+	err = iterateCursor(ctx, opts.Pool, opts.DB, opts.Collection, page, cursorID, func(raw wirebson.RawDocument) error {
+		n++
+
+		if cw == nil {
+			b, err := bson.MarshalExtJSON(bson.Raw(raw), !opts.Relaxed, false)
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			_, err = w.Write(append(b, '\n'))
+
+			return err
+		}
+
+		doc, err := raw.DecodeDeep()
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		for i, f := range opts.Fields {
+			if record[i], err = csvValue(getPath(doc, f)); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		return cw.Write(record)
+	})
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	if cw != nil {
+		cw.Flush()
+
+		if err = cw.Error(); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,298 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// errCodeUserExists is the MongoDB error code returned by `createUser` for existing users.
+const errCodeUserExists = 51003
+
+// ExportToMongoOpts represents [ExportToMongo] options.
+type ExportToMongoOpts struct {
+	Pool *documentdb.Pool
+	L    *slog.Logger
+
+	// TargetURL is the MongoDB URI of the target.
+	TargetURL string
+
+	// DB is the database to export; all databases are exported if empty.
+	DB string
+
+	// Drop drops target collections before exporting them.
+	Drop bool
+
+	// Users enables export of users.
+	Users bool
+}
+
+// ExportToMongo copies databases, collections, views, indexes, and (optionally) users to the target MongoDB.
+//
+// Like [Restore], documents that could not be inserted (for example, due to duplicate _id values)
+// are logged and skipped.
+//
+// User passwords can't be exported, as only PostgreSQL password verifiers are stored;
+// exported users get random passwords that should be changed on the target.
+func ExportToMongo(ctx context.Context, opts *ExportToMongoOpts) error {
+	client, err := mongo.Connect(options.Client().ApplyURI(opts.TargetURL))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer client.Disconnect(context.WithoutCancel(ctx)) //nolint:errcheck // nothing to do on error
+
+	dbs := []string{opts.DB}
+
+	if opts.DB == "" {
+		if dbs, err = listDatabases(ctx, opts.Pool, opts.L); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	for _, db := range dbs {
+		if err = exportDatabaseToMongo(ctx, opts, client.Database(db)); err != nil {
+			return lazyerrors.Errorf("%s: %w", db, err)
+		}
+	}
+
+	if !opts.Users {
+		return nil
+	}
+
+	return exportUsersToMongo(ctx, opts, client)
+}
+
+// exportDatabaseToMongo copies all collections and views of the given database.
+func exportDatabaseToMongo(ctx context.Context, opts *ExportToMongoOpts, target *mongo.Database) error {
+	mds, err := listCollections(ctx, opts.Pool, target.Name(), "")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	// views could depend on collections
+	slices.SortStableFunc(mds, func(a, b *metadata) int {
+		return boolCompare(a.Type == typeView, b.Type == typeView)
+	})
+
+	for _, md := range mds {
+		if err = exportCollectionToMongo(ctx, opts, target, md); err != nil {
+			return lazyerrors.Errorf("%s: %w", md.CollectionName, err)
+		}
+	}
+
+	return nil
+}
+
+// exportCollectionToMongo copies a single collection with its indexes, or a view.
+func exportCollectionToMongo(ctx context.Context, opts *ExportToMongoOpts, target *mongo.Database, md *metadata) error {
+	db, name := target.Name(), md.CollectionName
+
+	if opts.Drop {
+		if err := target.Collection(name).Drop(ctx); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if md.Type == typeView {
+		if err := runCommand(ctx, target, createViewSpec(name, md.Options)); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		opts.L.InfoContext(ctx, "Exported view", slog.String("db", db), slog.String("view", name))
+
+		return nil
+	}
+
+	if err := listIndexes(ctx, opts.Pool, db, md); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument("find", name)).Encode())
+
+	page, cursorID, err := opts.Pool.Find(ctx, db, spec)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	coll := target.Collection(name)
+
+	var inserted, failed, size int
+	var batch []any
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		i, f, err := insertMany(ctx, coll, batch)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		inserted += i
+		failed += f
+		batch, size = batch[:0], 0
+
+		return nil
+	}
+
+	err = iterateCursor(ctx, opts.Pool, db, name, page, cursorID, func(raw wirebson.RawDocument) error {
+		if len(batch) > 0 && (len(batch) == maxBatchDocuments || size+len(raw) > maxBatchSize) {
+			if err := flush(); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		batch = append(batch, bson.Raw(slices.Clone(raw)))
+		size += len(raw)
+
+		return nil
+	})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = flush(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if spec := createIndexesSpec(md); spec != nil {
+		if err = runCommand(ctx, target, spec); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	attrs := []slog.Attr{
+		slog.String("db", db), slog.String("collection", name),
+		slog.Int("documents", inserted), slog.Int("indexes", len(md.Indexes)),
+	}
+
+	if failed > 0 {
+		opts.L.LogAttrs(ctx, slog.LevelWarn, "Some documents were not exported", append(attrs, slog.Int("failed", failed))...)
+		return nil
+	}
+
+	opts.L.LogAttrs(ctx, slog.LevelInfo, "Exported collection", attrs...)
+
+	return nil
+}
+
+// insertMany inserts documents in an unordered batch.
+// It returns numbers of inserted and failed documents.
+func insertMany(ctx context.Context, coll *mongo.Collection, docs []any) (int, int, error) {
+	_, err := coll.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return len(docs), 0, nil
+	}
+
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || bwe.WriteConcernError != nil || len(bwe.WriteErrors) == 0 {
+		return 0, 0, lazyerrors.Error(err)
+	}
+
+	return len(docs) - len(bwe.WriteErrors), len(bwe.WriteErrors), nil
+}
+
+// runCommand runs the given command on the target database.
+func runCommand(ctx context.Context, target *mongo.Database, cmd *wirebson.Document) error {
+	if err := target.RunCommand(ctx, bson.Raw(must.NotFail(cmd.Encode()))).Err(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// exportUsersToMongo creates users with their roles on the target.
+// Existing users are skipped.
+func exportUsersToMongo(ctx context.Context, opts *ExportToMongoOpts, client *mongo.Client) error {
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument("usersInfo", int32(1), "$db", "admin")).Encode())
+
+	var res wirebson.RawDocument
+
+	err := opts.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		res, err = documentdb_api.UsersInfo(ctx, conn, opts.L, spec)
+		return err
+	})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	doc, err := res.DecodeDeep()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	users, _ := doc.Get("users").(*wirebson.Array)
+	if users == nil {
+		return nil
+	}
+
+	for v := range users.Values() {
+		user, ok := v.(*wirebson.Document)
+		if !ok {
+			return lazyerrors.Errorf("unexpected usersInfo response: %s", doc.LogMessage())
+		}
+
+		name, _ := user.Get("user").(string)
+		db, _ := user.Get("db").(string)
+
+		if opts.DB != "" && db != opts.DB {
+			continue
+		}
+
+		roles, _ := user.Get("roles").(*wirebson.Array)
+		if roles == nil {
+			roles = wirebson.MakeArray(0)
+		}
+
+		cmd := must.NotFail(wirebson.NewDocument("createUser", name, "pwd", rand.Text(), "roles", roles))
+
+		err = runCommand(ctx, client.Database(db), cmd)
+
+		var se mongo.ServerError
+		if errors.As(err, &se) && se.HasErrorCode(errCodeUserExists) {
+			opts.L.WarnContext(ctx, "User already exists", slog.String("db", db), slog.String("user", name))
+			continue
+		}
+
+		if err != nil {
+			return lazyerrors.Errorf("%s.%s: %w", db, name, err)
+		}
+
+		opts.L.InfoContext(
+			ctx, "Exported user; change its password on the target",
+			slog.String("db", db), slog.String("user", name),
+		)
+	}
+
+	return nil
+}
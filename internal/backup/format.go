@@ -0,0 +1,203 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// Export and import formats.
+const (
+	// FormatJSON is Extended JSON v2, one document per line.
+	FormatJSON = "json"
+
+	// FormatCSV is CSV with a header row.
+	FormatCSV = "csv"
+)
+
+// Formats contains all export and import formats.
+var Formats = []string{FormatJSON, FormatCSV}
+
+// CSV column types for import.
+const (
+	TypeAuto     = "auto"
+	TypeString   = "string"
+	TypeInt32    = "int32"
+	TypeInt64    = "int64"
+	TypeDouble   = "double"
+	TypeBoolean  = "boolean"
+	TypeDate     = "date"
+	TypeObjectID = "objectId"
+)
+
+// ColumnTypes contains all CSV column types.
+var ColumnTypes = []string{TypeAuto, TypeString, TypeInt32, TypeInt64, TypeDouble, TypeBoolean, TypeDate, TypeObjectID}
+
+// csvDateLayout is the layout of exported dates.
+const csvDateLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// getPath returns the value at the given dotted path, or nil if it is not present.
+func getPath(doc *wirebson.Document, path string) any {
+	var v any = doc
+
+	for _, part := range strings.Split(path, ".") {
+		switch c := v.(type) {
+		case *wirebson.Document:
+			v = c.Get(part)
+
+		case *wirebson.Array:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= c.Len() {
+				return nil
+			}
+
+			v = c.Get(i)
+
+		default:
+			return nil
+		}
+	}
+
+	return v
+}
+
+// setPath sets the value at the given dotted path, creating embedded documents as needed.
+func setPath(doc *wirebson.Document, path string, v any) error {
+	key, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		return setField(doc, key, v)
+	}
+
+	embedded, _ := doc.Get(key).(*wirebson.Document)
+	if embedded == nil {
+		embedded = wirebson.MakeDocument(1)
+
+		if err := setField(doc, key, embedded); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return setPath(embedded, rest, v)
+}
+
+// setField replaces the existing field value or adds a new field.
+func setField(doc *wirebson.Document, key string, v any) error {
+	if doc.Get(key) != nil {
+		return doc.Replace(key, v)
+	}
+
+	return doc.Add(key, v)
+}
+
+// csvValue returns the string representation of the value for CSV export.
+//
+// Scalar values are formatted as plain strings; other values use canonical Extended JSON.
+func csvValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil, wirebson.NullType:
+		return "", nil
+	case string:
+		return v, nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.UTC().Format(csvDateLayout), nil
+	case wirebson.ObjectID:
+		return hex.EncodeToString(v[:]), nil
+	}
+
+	b, err := json.Marshal(must.NotFail(wirebson.NewDocument("v", v)))
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	var m map[string]json.RawMessage
+	if err = json.Unmarshal(b, &m); err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	return string(m["v"]), nil
+}
+
+// parseCSVValue returns the value of the given column type for the string imported from CSV.
+func parseCSVValue(s, typ string) (any, error) {
+	switch typ {
+	case "", TypeAuto:
+		if i, err := strconv.ParseInt(s, 10, 32); err == nil {
+			return int32(i), nil
+		}
+
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+
+		// do not convert strings like "NaN" or "Inf"
+		if f, err := strconv.ParseFloat(s, 64); err == nil && strings.ContainsAny(s, "0123456789") {
+			return f, nil
+		}
+
+		return s, nil
+
+	case TypeString:
+		return s, nil
+
+	case TypeInt32:
+		i, err := strconv.ParseInt(s, 10, 32)
+		return int32(i), err
+
+	case TypeInt64:
+		return strconv.ParseInt(s, 10, 64)
+
+	case TypeDouble:
+		return strconv.ParseFloat(s, 64)
+
+	case TypeBoolean:
+		return strconv.ParseBool(s)
+
+	case TypeDate:
+		return time.Parse(time.RFC3339Nano, s)
+
+	case TypeObjectID:
+		var id wirebson.ObjectID
+
+		b, err := hex.DecodeString(s)
+		if err != nil || len(b) != len(id) {
+			return nil, fmt.Errorf("invalid ObjectId %q", s)
+		}
+
+		copy(id[:], b)
+
+		return id, nil
+
+	default:
+		return nil, fmt.Errorf("unknown column type %q", typ)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/FerretDB/wire/wirebson"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// ImportOpts represents [Import] options.
+type ImportOpts struct {
+	Pool *documentdb.Pool
+	L    *slog.Logger
+
+	DB         string
+	Collection string
+
+	// Format is one of [Formats]; [FormatJSON] is used if empty.
+	Format string
+
+	// Fields are CSV columns as dotted paths; the header row is used if empty.
+	Fields []string
+
+	// Types maps CSV columns to one of [ColumnTypes]; [TypeAuto] is used for other columns.
+	Types map[string]string
+
+	// Drop enables dropping of the existing collection before import.
+	Drop bool
+}
+
+// DataError is returned by [Import] for invalid input data.
+type DataError struct {
+	msg string
+}
+
+// newDataError returns a new DataError with the formatted message.
+func newDataError(format string, args ...any) error {
+	return &DataError{msg: fmt.Sprintf(format, args...)}
+}
+
+// Error implements [error].
+func (e *DataError) Error() string {
+	return e.msg
+}
+
+// Import reads documents from r in Extended JSON (canonical or relaxed) or CSV format
+// and inserts them into the collection.
+// It returns numbers of inserted and failed documents.
+func Import(ctx context.Context, r io.Reader, opts *ImportOpts) (int, int, error) {
+	format := opts.Format
+	if format == "" {
+		format = FormatJSON
+	}
+
+	if !slices.Contains(Formats, format) {
+		return 0, 0, lazyerrors.Errorf("unknown format %q", format)
+	}
+
+	for f, t := range opts.Types {
+		if !slices.Contains(ColumnTypes, t) {
+			return 0, 0, lazyerrors.Errorf("unknown type %q for column %q", t, f)
+		}
+	}
+
+	conn, err := opts.Pool.Acquire()
+	if err != nil {
+		return 0, 0, lazyerrors.Error(err)
+	}
+
+	defer conn.Release()
+
+	if opts.Drop {
+		_, err = documentdb_api.DropCollection(ctx, conn.Conn(), opts.L, opts.DB, opts.Collection, nil, nil, false)
+		if err != nil {
+			return 0, 0, lazyerrors.Error(err)
+		}
+	}
+
+	ins := newInserter(conn, opts.L, opts.DB, opts.Collection)
+
+	switch format {
+	case FormatJSON:
+		err = importJSON(ctx, r, ins)
+	case FormatCSV:
+		err = importCSV(ctx, r, ins, opts.Fields, opts.Types)
+	}
+
+	if err != nil {
+		return 0, 0, lazyerrors.Error(err)
+	}
+
+	if err = ins.flush(ctx); err != nil {
+		return 0, 0, lazyerrors.Error(err)
+	}
+
+	return ins.inserted, ins.failed, nil
+}
+
+// importJSON reads Extended JSON documents separated by whitespace and adds them to the inserter.
+func importJSON(ctx context.Context, r io.Reader, ins *inserter) error {
+	dec := json.NewDecoder(r)
+
+	for i := 1; ; i++ {
+		var b json.RawMessage
+		if err := dec.Decode(&b); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return newDataError("document %d: %s", i, err)
+		}
+
+		var d bson.D
+		if err := bson.UnmarshalExtJSON(b, false, &d); err != nil {
+			return newDataError("document %d: %s", i, err)
+		}
+
+		raw, err := bson.Marshal(d)
+		if err != nil {
+			return newDataError("document %d: %s", i, err)
+		}
+
+		if err = ins.add(ctx, raw); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+}
+
+// importCSV reads CSV records and adds them to the inserter as documents.
+// Empty values are skipped.
+func importCSV(ctx context.Context, r io.Reader, ins *inserter, fields []string, types map[string]string) error {
+	cr := csv.NewReader(r)
+
+	if len(fields) == 0 {
+		header, err := cr.Read()
+		if err != nil {
+			return newDataError("header: %s", err)
+		}
+
+		fields = slices.Clone(header)
+	}
+
+	cr.FieldsPerRecord = len(fields)
+	cr.ReuseRecord = true
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return newDataError("%s", err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		doc := wirebson.MakeDocument(len(fields))
+
+		for i, f := range fields {
+			if record[i] == "" {
+				continue
+			}
+
+			v, err := parseCSVValue(record[i], types[f])
+			if err != nil {
+				return newDataError("line %d, column %q: %s", line, f, err)
+			}
+
+			if err = setPath(doc, f, v); err != nil {
+				return newDataError("line %d, column %q: %s", line, f, err)
+			}
+		}
+
+		raw, err := doc.Encode()
+		if err != nil {
+			return newDataError("line %d: %s", line, err)
+		}
+
+		if err = ins.add(ctx, raw); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+}
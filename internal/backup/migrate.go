@@ -0,0 +1,499 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// checkpointInterval is the minimal interval between checkpoint saves while tailing the change stream.
+const checkpointInterval = 5 * time.Second
+
+// systemDatabases are source databases that are never migrated.
+var systemDatabases = []string{"admin", "config", "local"}
+
+// MigrateOpts represents [Migrate] options.
+type MigrateOpts struct {
+	Pool *documentdb.Pool
+	L    *slog.Logger
+
+	// SourceURL is the MongoDB URI of the source; it should be a replica set for change streams to work.
+	SourceURL string
+
+	// DB is the database to migrate; all databases except system ones are migrated if empty.
+	DB string
+
+	// Checkpoint is the path of the checkpoint file used to resume the migration.
+	Checkpoint string
+}
+
+// checkpoint represents the content of the checkpoint file.
+type checkpoint struct {
+	// ResumeToken is the `_data` field of the last processed change stream resume token.
+	ResumeToken string `json:"resumeToken"`
+
+	// Synced contains namespaces (db.collection) copied by the initial sync.
+	Synced []string `json:"synced"`
+
+	// InitialSyncDone is true when all namespaces were copied.
+	InitialSyncDone bool `json:"initialSyncDone"`
+}
+
+// readCheckpoint reads the checkpoint file; an empty checkpoint is returned if it does not exist.
+func readCheckpoint(path string) (*checkpoint, error) {
+	var cp checkpoint
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &cp, nil
+	}
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = json.Unmarshal(b, &cp); err != nil {
+		return nil, lazyerrors.Errorf("%s: %w", path, err)
+	}
+
+	return &cp, nil
+}
+
+// writeCheckpoint atomically writes the checkpoint file.
+func writeCheckpoint(path string, cp *checkpoint) error {
+	b, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	tmp := path + ".tmp"
+
+	if err = os.WriteFile(tmp, b, 0o666); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = os.Rename(tmp, path); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// Migrate copies databases, collections, views, and indexes from the source MongoDB
+// and then applies changes from the source change stream until ctx is canceled (cutover).
+//
+// The progress is saved to the checkpoint file, so an interrupted migration is resumed
+// from the last processed change, skipping already copied collections.
+// The change stream is opened before the initial sync,
+// so changes made while collections are copied are applied afterwards.
+func Migrate(ctx context.Context, opts *MigrateOpts) error {
+	cp, err := readCheckpoint(opts.Checkpoint)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	client, err := mongo.Connect(options.Client().ApplyURI(opts.SourceURL))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer client.Disconnect(context.WithoutCancel(ctx)) //nolint:errcheck // nothing to do on error
+
+	var pipeline mongo.Pipeline
+	if opts.DB != "" {
+		pipeline = mongo.Pipeline{{{Key: "$match", Value: bson.D{{Key: "ns.db", Value: opts.DB}}}}}
+	}
+
+	csOpts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if cp.ResumeToken != "" {
+		csOpts.SetResumeAfter(bson.D{{Key: "_data", Value: cp.ResumeToken}})
+	}
+
+	cs, err := client.Watch(ctx, pipeline, csOpts)
+	if err != nil {
+		return lazyerrors.Errorf("failed to open change stream (is the source a replica set?): %w", err)
+	}
+
+	defer cs.Close(context.WithoutCancel(ctx)) //nolint:errcheck // nothing to do on error
+
+	if cp.ResumeToken == "" {
+		if cp.ResumeToken, err = resumeTokenData(cs.ResumeToken()); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if err = writeCheckpoint(opts.Checkpoint, cp); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if !cp.InitialSyncDone {
+		if err = migrateInitialSync(ctx, opts, client, cp); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	opts.L.InfoContext(ctx, "Tailing source change stream until cutover")
+
+	return migrateTail(ctx, opts, cs, cp)
+}
+
+// resumeTokenData returns the `_data` field of the resume token.
+func resumeTokenData(token bson.Raw) (string, error) {
+	data, ok := token.Lookup("_data").StringValueOK()
+	if !ok {
+		return "", lazyerrors.Errorf("unexpected resume token %s", token)
+	}
+
+	return data, nil
+}
+
+// migrateInitialSync copies all collections and views not yet copied according to the checkpoint.
+func migrateInitialSync(ctx context.Context, opts *MigrateOpts, client *mongo.Client, cp *checkpoint) error {
+	dbs := []string{opts.DB}
+
+	if opts.DB == "" {
+		var err error
+		if dbs, err = client.ListDatabaseNames(ctx, bson.D{}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	for _, db := range dbs {
+		if slices.Contains(systemDatabases, db) {
+			continue
+		}
+
+		specs, err := client.Database(db).ListCollectionSpecifications(ctx, bson.D{})
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		// views could depend on collections
+		slices.SortStableFunc(specs, func(a, b mongo.CollectionSpecification) int {
+			return boolCompare(a.Type == typeView, b.Type == typeView)
+		})
+
+		for _, spec := range specs {
+			ns := db + "." + spec.Name
+			if slices.Contains(cp.Synced, ns) {
+				continue
+			}
+
+			if err = migrateCollection(ctx, opts, client.Database(db).Collection(spec.Name), &spec); err != nil {
+				return lazyerrors.Errorf("%s: %w", ns, err)
+			}
+
+			cp.Synced = append(cp.Synced, ns)
+
+			if err = writeCheckpoint(opts.Checkpoint, cp); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+	}
+
+	cp.InitialSyncDone = true
+
+	return writeCheckpoint(opts.Checkpoint, cp)
+}
+
+// boolCompare orders false before true.
+func boolCompare(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// migrateCollection copies a single collection with its indexes, or a view.
+func migrateCollection(ctx context.Context, opts *MigrateOpts, coll *mongo.Collection, spec *mongo.CollectionSpecification) error { //nolint:lll // for readability
+	db := coll.Database().Name()
+
+	conn, err := opts.Pool.Acquire()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer conn.Release()
+
+	if spec.Type == typeView {
+		var viewOpts *wirebson.Document
+		if viewOpts, err = wirebson.RawDocument(spec.Options).DecodeDeep(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if err = createView(ctx, conn, opts.L, db, spec.Name, viewOpts); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		opts.L.InfoContext(ctx, "Migrated view", slog.String("db", db), slog.String("view", spec.Name))
+
+		return nil
+	}
+
+	if _, err = documentdb_api.CreateCollection(ctx, conn.Conn(), opts.L, db, spec.Name); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	cur, err := coll.Find(ctx, bson.D{})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer cur.Close(ctx) //nolint:errcheck // we are only reading
+
+	ins := newInserter(conn, opts.L, db, spec.Name)
+
+	for cur.Next(ctx) {
+		if err = ins.add(ctx, wirebson.RawDocument(cur.Current)); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if err = cur.Err(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = ins.flush(ctx); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	md := &metadata{CollectionName: spec.Name}
+
+	icur, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer icur.Close(ctx) //nolint:errcheck // we are only reading
+
+	for icur.Next(ctx) {
+		var index *wirebson.Document
+		if index, err = wirebson.RawDocument(icur.Current).DecodeDeep(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		md.Indexes = append(md.Indexes, index)
+	}
+
+	if err = icur.Err(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = restoreIndexes(ctx, conn, opts.L, db, md); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	// documents that already exist (for example, after resuming) are not replaced,
+	// but changes to them are applied from the change stream later
+	opts.L.InfoContext(
+		ctx, "Migrated collection",
+		slog.String("db", db), slog.String("collection", spec.Name),
+		slog.Int("documents", ins.inserted), slog.Int("existing", ins.failed), slog.Int("indexes", len(md.Indexes)),
+	)
+
+	return nil
+}
+
+// changeEvent represents the fields of the change stream event used by [migrateTail].
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	NS            struct {
+		DB   string `bson:"db"`
+		Coll string `bson:"coll"`
+	} `bson:"ns"`
+	DocumentKey  bson.Raw `bson:"documentKey"`
+	FullDocument bson.Raw `bson:"fullDocument"`
+}
+
+// migrateTail applies change stream events until ctx is canceled.
+func migrateTail(ctx context.Context, opts *MigrateOpts, cs *mongo.ChangeStream, cp *checkpoint) error {
+	var applied int
+	saved := time.Now()
+
+	save := func() error {
+		data, err := resumeTokenData(cs.ResumeToken())
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		cp.ResumeToken = data
+		saved = time.Now()
+
+		return writeCheckpoint(opts.Checkpoint, cp)
+	}
+
+	for {
+		if !cs.TryNext(ctx) {
+			if ctx.Err() != nil {
+				break
+			}
+
+			if err := cs.Err(); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			if time.Since(saved) > checkpointInterval {
+				if err := save(); err != nil {
+					return lazyerrors.Error(err)
+				}
+			}
+
+			continue
+		}
+
+		var ev changeEvent
+		if err := cs.Decode(&ev); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if err := applyChange(ctx, opts, &ev); err != nil {
+			return lazyerrors.Errorf("%s.%s: %s: %w", ev.NS.DB, ev.NS.Coll, ev.OperationType, err)
+		}
+
+		applied++
+
+		if time.Since(saved) > checkpointInterval {
+			if err := save(); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	if err := save(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	opts.L.InfoContext(ctx, "Migration stopped", slog.Int("applied", applied), slog.String("checkpoint", opts.Checkpoint))
+
+	return nil
+}
+
+// applyChange applies a single change stream event to the target.
+//
+// Inserts, updates, and replaces are applied as upserts of the full document,
+// so applying the same event more than once is safe.
+func applyChange(ctx context.Context, opts *MigrateOpts, ev *changeEvent) error {
+	if slices.Contains(systemDatabases, ev.NS.DB) {
+		return nil
+	}
+
+	conn, err := opts.Pool.Acquire()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer conn.Release()
+
+	db, coll := ev.NS.DB, ev.NS.Coll
+	key := wirebson.RawDocument(ev.DocumentKey)
+
+	switch ev.OperationType {
+	case "insert", "update", "replace":
+		// the document was deleted after the change; the delete event follows
+		if ev.FullDocument == nil {
+			return nil
+		}
+
+		update := must.NotFail(wirebson.NewDocument(
+			"q", key,
+			"u", wirebson.RawDocument(ev.FullDocument),
+			"upsert", true,
+		))
+		spec := must.NotFail(wirebson.NewDocument("update", coll, "updates", wirebson.MustArray(update)))
+
+		res, _, err := documentdb_api.Update(ctx, conn.Conn(), opts.L, db, must.NotFail(spec.Encode()), nil)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return checkWriteResult(res)
+
+	case "delete":
+		del := must.NotFail(wirebson.NewDocument("q", key, "limit", int32(1)))
+		spec := must.NotFail(wirebson.NewDocument("delete", coll, "deletes", wirebson.MustArray(del)))
+
+		res, _, err := documentdb_api.Delete(ctx, conn.Conn(), opts.L, db, must.NotFail(spec.Encode()), nil)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return checkWriteResult(res)
+
+	case "drop":
+		_, err = documentdb_api.DropCollection(ctx, conn.Conn(), opts.L, db, coll, nil, nil, false)
+		return err
+
+	case "dropDatabase":
+		return documentdb_api.DropDatabase(ctx, conn.Conn(), opts.L, db, nil)
+
+	case "invalidate":
+		return lazyerrors.New("change stream invalidated")
+
+	default:
+		opts.L.WarnContext(
+			ctx, "Change event is not applied",
+			slog.String("db", db), slog.String("collection", coll), slog.String("operation", ev.OperationType),
+		)
+
+		return nil
+	}
+}
+
+// checkWriteResult returns an error if the DocumentDB write command result is not successful
+// or contains write errors.
+func checkWriteResult(res wirebson.RawDocument) error {
+	if err := checkResult(res); err != nil {
+		return err
+	}
+
+	doc, err := res.DecodeDeep()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	writeErrors, _ := doc.Get("writeErrors").(*wirebson.Array)
+	if writeErrors == nil || writeErrors.Len() == 0 {
+		return nil
+	}
+
+	var msg string
+	if we, _ := writeErrors.Get(0).(*wirebson.Document); we != nil {
+		msg, _ = we.Get("errmsg").(string)
+	}
+
+	return lazyerrors.Errorf("write error: %s", msg)
+}
@@ -0,0 +1,397 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api_internal"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// Limits of a single insert batch.
+const (
+	maxBatchDocuments = 1000
+	maxBatchSize      = 8 * 1024 * 1024
+)
+
+// RestoreOpts represents [Restore] options.
+type RestoreOpts struct {
+	Pool *documentdb.Pool
+	L    *slog.Logger
+
+	// Dir is the directory with databases in mongodump format.
+	Dir string
+
+	// DB is the database to restore; all databases are restored if empty.
+	DB string
+
+	// Drop enables dropping of existing collections before restoring them.
+	Drop bool
+}
+
+// Restore restores databases and collections from the directory in mongodump format.
+//
+// Documents that could not be inserted (for example, due to duplicate _id values)
+// are logged and skipped, like mongorestore does by default.
+func Restore(ctx context.Context, opts *RestoreOpts) error {
+	entries, err := os.ReadDir(opts.Dir)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		db := e.Name()
+		if opts.DB != "" && db != opts.DB {
+			continue
+		}
+
+		if err = restoreDatabase(ctx, opts, db, filepath.Join(opts.Dir, db)); err != nil {
+			return lazyerrors.Errorf("%s: %w", db, err)
+		}
+	}
+
+	return nil
+}
+
+// restoreDatabase restores all collections and views of the given database directory.
+func restoreDatabase(ctx context.Context, opts *RestoreOpts, db, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var collections, views []*metadata
+
+	for _, e := range entries {
+		name := e.Name()
+		gz := strings.HasSuffix(name, gzipExt)
+
+		if !strings.HasSuffix(strings.TrimSuffix(name, gzipExt), metadataExt) {
+			continue
+		}
+
+		md, err := readMetadata(filepath.Join(dir, name), gz)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if md.CollectionName == "" {
+			md.CollectionName = strings.TrimSuffix(strings.TrimSuffix(name, gzipExt), metadataExt)
+		}
+
+		switch md.Type {
+		case typeView:
+			views = append(views, md)
+		default:
+			collections = append(collections, md)
+		}
+	}
+
+	// views could depend on collections
+	for _, md := range slices.Concat(collections, views) {
+		if err = restoreCollection(ctx, opts, db, dir, md); err != nil {
+			return lazyerrors.Errorf("%s: %w", md.CollectionName, err)
+		}
+	}
+
+	return nil
+}
+
+// restoreCollection restores a single collection or view.
+func restoreCollection(ctx context.Context, opts *RestoreOpts, db, dir string, md *metadata) error {
+	conn, err := opts.Pool.Acquire()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer conn.Release()
+
+	name := md.CollectionName
+
+	if opts.Drop {
+		if _, err = documentdb_api.DropCollection(ctx, conn.Conn(), opts.L, db, name, nil, nil, false); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if md.Type == typeView {
+		if err = createView(ctx, conn, opts.L, db, name, md.Options); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		opts.L.InfoContext(ctx, "Restored view", slog.String("db", db), slog.String("view", name))
+
+		return nil
+	}
+
+	if md.Options.Len() > 0 {
+		opts.L.WarnContext(
+			ctx, "Collection options are not restored",
+			slog.String("db", db), slog.String("collection", name), slog.Any("options", md.Options),
+		)
+	}
+
+	if _, err = documentdb_api.CreateCollection(ctx, conn.Conn(), opts.L, db, name); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	inserted, failed, err := restoreDocuments(ctx, conn, opts.L, db, filepath.Join(dir, name+bsonExt), name)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = restoreIndexes(ctx, conn, opts.L, db, md); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	attrs := []slog.Attr{
+		slog.String("db", db), slog.String("collection", name),
+		slog.Int("documents", inserted), slog.Int("indexes", len(md.Indexes)),
+	}
+
+	if failed > 0 {
+		opts.L.LogAttrs(ctx, slog.LevelWarn, "Some documents were not restored", append(attrs, slog.Int("failed", failed))...)
+		return nil
+	}
+
+	opts.L.LogAttrs(ctx, slog.LevelInfo, "Restored collection", attrs...)
+
+	return nil
+}
+
+// createViewSpec returns `create` command for a view with the given options (viewOn, pipeline, etc).
+func createViewSpec(name string, options *wirebson.Document) *wirebson.Document {
+	spec := must.NotFail(wirebson.NewDocument("create", name))
+
+	for k, v := range options.All() {
+		must.NoError(spec.Add(k, v))
+	}
+
+	return spec
+}
+
+// createView creates a view with the given options (viewOn, pipeline, etc).
+func createView(ctx context.Context, conn *documentdb.Conn, l *slog.Logger, db, name string, options *wirebson.Document) error {
+	spec := createViewSpec(name, options)
+
+	res, err := documentdb_api.CreateCollectionView(ctx, conn.Conn(), l, db, must.NotFail(spec.Encode()))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return checkResult(res)
+}
+
+// restoreDocuments inserts documents from the .bson file (compressed or not) in batches.
+// It returns numbers of inserted and failed documents.
+func restoreDocuments(ctx context.Context, conn *documentdb.Conn, l *slog.Logger, db, path, collection string) (int, int, error) {
+	gz := false
+
+	if _, err := os.Stat(path); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return 0, 0, lazyerrors.Error(err)
+		}
+
+		path += gzipExt
+		gz = true
+
+		if _, err = os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return 0, 0, nil
+		}
+	}
+
+	r, err := openFile(path, gz)
+	if err != nil {
+		return 0, 0, lazyerrors.Error(err)
+	}
+
+	defer r.Close() //nolint:errcheck // we are only reading
+
+	ins := newInserter(conn, l, db, collection)
+
+	for {
+		doc, err := readDocument(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return 0, 0, lazyerrors.Errorf("%s: %w", path, err)
+		}
+
+		if err = ins.add(ctx, doc); err != nil {
+			return 0, 0, lazyerrors.Error(err)
+		}
+	}
+
+	if err = ins.flush(ctx); err != nil {
+		return 0, 0, lazyerrors.Error(err)
+	}
+
+	return ins.inserted, ins.failed, nil
+}
+
+// inserter inserts documents into a single collection in unordered batches.
+//
+// Write errors (for example, due to duplicate _id values) are only counted,
+// as all other documents of the batch are inserted.
+type inserter struct {
+	conn *documentdb.Conn
+	l    *slog.Logger
+	db   string
+	spec wirebson.RawDocument
+
+	seq      []byte
+	batchLen int
+
+	inserted int
+	failed   int
+}
+
+// newInserter creates a new inserter.
+func newInserter(conn *documentdb.Conn, l *slog.Logger, db, collection string) *inserter {
+	return &inserter{
+		conn: conn,
+		l:    l,
+		db:   db,
+		spec: must.NotFail(must.NotFail(wirebson.NewDocument("insert", collection, "ordered", false)).Encode()),
+	}
+}
+
+// add adds a document to the current batch, inserting the batch first if it is full.
+func (ins *inserter) add(ctx context.Context, doc wirebson.RawDocument) error {
+	if ins.batchLen > 0 && (ins.batchLen == maxBatchDocuments || len(ins.seq)+len(doc) > maxBatchSize) {
+		if err := ins.flush(ctx); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	ins.seq = append(ins.seq, doc...)
+	ins.batchLen++
+
+	return nil
+}
+
+// flush inserts the current batch.
+func (ins *inserter) flush(ctx context.Context) error {
+	if ins.batchLen == 0 {
+		return nil
+	}
+
+	res, _, err := documentdb_api.Insert(ctx, ins.conn.Conn(), ins.l, ins.db, ins.spec, ins.seq)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	doc, err := res.Decode()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	n, _ := doc.Get("n").(int32)
+
+	ins.inserted += int(n)
+	ins.failed += ins.batchLen - int(n)
+
+	ins.seq = ins.seq[:0]
+	ins.batchLen = 0
+
+	return nil
+}
+
+// createIndexesSpec returns `createIndexes` command for indexes from metadata, except the default _id index.
+// It returns nil if there are no such indexes.
+func createIndexesSpec(md *metadata) *wirebson.Document {
+	indexes := wirebson.MakeArray(len(md.Indexes))
+
+	for _, index := range md.Indexes {
+		if name, _ := index.Get("name").(string); name == "_id_" {
+			continue
+		}
+
+		// namespace could be different on restore
+		index.Remove("ns")
+
+		must.NoError(indexes.Add(index))
+	}
+
+	if indexes.Len() == 0 {
+		return nil
+	}
+
+	return must.NotFail(wirebson.NewDocument("createIndexes", md.CollectionName, "indexes", indexes))
+}
+
+// restoreIndexes creates indexes from metadata, except the default _id index.
+func restoreIndexes(ctx context.Context, conn *documentdb.Conn, l *slog.Logger, db string, md *metadata) error {
+	spec := createIndexesSpec(md)
+	if spec == nil {
+		return nil
+	}
+
+	res, err := documentdb_api_internal.CreateIndexesNonConcurrently(ctx, conn.Conn(), l, db, must.NotFail(spec.Encode()), true)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	doc, err := res.DecodeDeep()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if raw, _ := doc.Get("raw").(*wirebson.Document); raw != nil {
+		if shard, _ := raw.Get("defaultShard").(*wirebson.Document); shard != nil {
+			if code, _ := shard.Get("code").(int32); code != 0 {
+				msg, _ := shard.Get("errmsg").(string)
+				return lazyerrors.Errorf("failed to create indexes: %s (%d)", msg, code)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkResult returns an error if the DocumentDB command result is not successful.
+func checkResult(res wirebson.RawDocument) error {
+	doc, err := res.Decode()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if ok, _ := doc.Get("ok").(float64); ok == 1 {
+		return nil
+	}
+
+	msg, _ := doc.Get("errmsg").(string)
+
+	return lazyerrors.Errorf("command failed: %s", msg)
+}
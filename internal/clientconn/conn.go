@@ -20,6 +20,7 @@
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash"
@@ -29,13 +30,11 @@
 	"net/netip"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/FerretDB/wire"
 	"github.com/FerretDB/wire/wirebson"
-	"github.com/pmezard/go-difflib/difflib"
 	"go.opentelemetry.io/otel"
 	otelattribute "go.opentelemetry.io/otel/attribute"
 	otelcodes "go.opentelemetry.io/otel/codes"
@@ -47,6 +46,7 @@
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
 	"github.com/FerretDB/FerretDB/v2/internal/handler/proxy"
 	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/bsondiff"
 	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
 	"github.com/FerretDB/FerretDB/v2/internal/util/must"
@@ -85,6 +85,8 @@ type conn struct {
 	h              *handler.Handler
 	m              *connmetrics.ConnMetrics
 	proxy          *proxy.Handler
+	shadow         *proxy.Shadow
+	shadowAll      bool
 	lastRequestID  atomic.Int32
 	testRecordsDir string // if empty, no records are created
 }
@@ -102,6 +104,12 @@ type newConnOpts struct {
 	proxyTLSKeyFile  string
 	proxyTLSCAFile   string
 
+	shadowAddr        string // if empty, requests are not shadowed
+	shadowTLSCertFile string
+	shadowTLSKeyFile  string
+	shadowTLSCAFile   string
+	shadowAll         bool
+
 	testRecordsDir string // if empty, no records are created
 }
 
@@ -122,6 +130,20 @@ func newConn(opts *newConnOpts) (*conn, error) {
 		}
 	}
 
+	var s *proxy.Shadow
+	if opts.shadowAddr != "" {
+		s = proxy.NewShadow(&proxy.ShadowOpts{
+			Addr:        opts.shadowAddr,
+			TLSCertFile: opts.shadowTLSCertFile,
+			TLSKeyFile:  opts.shadowTLSKeyFile,
+			TLSCAFile:   opts.shadowTLSCAFile,
+			L:           opts.l,
+			Result: func(command, result string) {
+				opts.connMetrics.Shadowed.WithLabelValues(command, result).Inc()
+			},
+		})
+	}
+
 	return &conn{
 		netConn:        opts.netConn,
 		mode:           opts.mode,
@@ -129,6 +151,8 @@ func newConn(opts *newConnOpts) (*conn, error) {
 		h:              opts.handler,
 		m:              opts.connMetrics,
 		proxy:          p,
+		shadow:         s,
+		shadowAll:      opts.shadowAll,
 		testRecordsDir: opts.testRecordsDir,
 	}, nil
 }
@@ -151,6 +175,11 @@ func (c *conn) run(ctx context.Context) (err error) {
 		if err != nil {
 			return
 		}
+
+		connInfo.Local, err = netip.ParseAddrPort(c.netConn.LocalAddr().String())
+		if err != nil {
+			return
+		}
 	}
 
 	ctx = conninfo.Ctx(ctx, connInfo)
@@ -162,6 +191,10 @@ func (c *conn) run(ctx context.Context) (err error) {
 		go c.proxy.Run(ctx)
 	}
 
+	if c.shadow != nil {
+		go c.shadow.Run(ctx)
+	}
+
 	done := make(chan struct{})
 
 	// handle ctx cancellation
@@ -249,6 +282,21 @@ func (c *conn) processMessage(ctx context.Context, bufr *bufio.Reader, bufw *buf
 	// It is set to the highest level of logging used to log response.
 	diffLogLevel := slog.LevelDebug
 
+	diffMode := c.mode == DiffNormalMode || c.mode == DiffProxyMode
+
+	// get command name before FerretDB's handling could modify reqBody's documents
+	var command string
+	if diffMode || c.shadow != nil {
+		command = requestCommand(reqHeader, reqBody)
+	}
+
+	// shadow a copy of the request without waiting for the response
+	if c.shadow != nil && (reqHeader.OpCode == wire.OpCodeMsg || reqHeader.OpCode == wire.OpCodeQuery) {
+		if c.shadowAll || c.h.IsWrite(command) {
+			c.shadow.Send(ctx, command, reqHeader, reqBody)
+		}
+	}
+
 	// send request to proxy first (unless we are in normal mode)
 	// because FerretDB's handling could modify reqBody's documents,
 	// creating a data race
@@ -284,6 +332,14 @@ func (c *conn) processMessage(ctx context.Context, bufr *bufio.Reader, bufw *buf
 
 	if c.mode != ProxyMode {
 		resHeader, resBody, resCloseConn = c.route(ctx, reqHeader, reqBody)
+
+		if resCloseConn && resBody == nil {
+			err = errors.New("closed by handler")
+			c.l.DebugContext(ctx, "Connection closed without response", logging.Error(err))
+
+			return err
+		}
+
 		if level := c.logResponse(ctx, "Response", resHeader, resBody, resCloseConn); level > diffLogLevel {
 			diffLogLevel = level
 		}
@@ -297,8 +353,8 @@ func (c *conn) processMessage(ctx context.Context, bufr *bufio.Reader, bufw *buf
 	}
 
 	// diff in diff mode
-	if c.l.Enabled(ctx, diffLogLevel) && (c.mode == DiffNormalMode || c.mode == DiffProxyMode) {
-		if err = c.logDiff(ctx, resHeader, proxyHeader, resBody, proxyBody, diffLogLevel); err != nil {
+	if diffMode {
+		if err = c.logDiff(ctx, command, resHeader, proxyHeader, resBody, proxyBody, diffLogLevel); err != nil {
 			return err
 		}
 	}
@@ -456,6 +512,13 @@ func (c *conn) route(connCtx context.Context, reqHeader *wire.MsgHeader, reqBody
 
 	c.m.Requests.WithLabelValues(reqHeader.OpCode.String(), command).Inc()
 
+	if errors.Is(err, middleware.ErrCloseConnection) {
+		closeConn = true
+		result = "closed"
+
+		return
+	}
+
 	// set body for error
 	if err != nil {
 		switch resHeader.OpCode {
@@ -609,47 +672,120 @@ func (c *conn) logResponse(ctx context.Context, who string, resHeader *wire.MsgH
 	return level
 }
 
-// logDiff logs the diff between the response and the proxy response.
-func (c *conn) logDiff(ctx context.Context, resHeader, proxyHeader *wire.MsgHeader, resBody, proxyBody wire.MsgBody, logLevel slog.Level) error { //nolint:lll // for readability
-	diffHeader, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
-		A:        difflib.SplitLines(resHeader.String()),
-		FromFile: "res header",
-		B:        difflib.SplitLines(proxyHeader.String()),
-		ToFile:   "proxy header",
-		Context:  1,
-	})
-	if err != nil {
-		return err
-	}
+// diffVolatileFields contains top-level response fields that differ between servers and runs.
+var diffVolatileFields = []string{
+	"$clusterTime",
+	"connectionId",
+	"electionId",
+	"lastWrite",
+	"localTime",
+	"operationTime",
+	"topologyVersion",
+}
+
+// responseDiff represents a structured diff between the response and the proxy response.
+type responseDiff struct {
+	OpCode      string                `json:"opcode"`
+	Command     string                `json:"command"`
+	Differences []bsondiff.Difference `json:"differences"`
+}
+
+// logDiff compares the response with the proxy response, updates diff metrics,
+// and logs differences as JSON.
+//
+// The proxy response is expected; the response is actual.
+func (c *conn) logDiff(ctx context.Context, command string, resHeader, proxyHeader *wire.MsgHeader, resBody, proxyBody wire.MsgBody, logLevel slog.Level) error { //nolint:lll // for readability
+	opcode := proxyHeader.OpCode.String()
+
+	var diffs []bsondiff.Difference
 
 	// resBody can be nil if we got a message we could not handle at all, like unsupported OpQuery.
-	var resBodyString, proxyBodyString string
+	switch {
+	case resHeader.OpCode != proxyHeader.OpCode:
+		diffs = []bsondiff.Difference{{
+			Kind:         bsondiff.KindType,
+			ActualType:   resHeader.OpCode.String(),
+			ExpectedType: opcode,
+		}}
+
+	case resBody == nil:
+		diffs = []bsondiff.Difference{{
+			Kind:         bsondiff.KindMissing,
+			ExpectedType: opcode,
+		}}
+
+	default:
+		resDoc, err := responseDocument(resBody)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		proxyDoc, err := responseDocument(proxyBody)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		diffs = bsondiff.Compare(resDoc, proxyDoc, diffVolatileFields...)
+	}
+
+	if len(diffs) == 0 {
+		c.m.Diffs.WithLabelValues(opcode, command, "match").Inc()
+		c.l.DebugContext(ctx, "Responses match")
+
+		return nil
+	}
+
+	c.m.Diffs.WithLabelValues(opcode, command, "mismatch").Inc()
 
-	if resBody != nil {
-		resBodyString = resBody.StringIndent()
+	for _, d := range diffs {
+		c.m.Differences.WithLabelValues(opcode, command, string(d.Kind)).Inc()
 	}
 
-	if proxyBody != nil {
-		proxyBodyString = proxyBody.StringIndent()
+	if !c.l.Enabled(ctx, logLevel) {
+		return nil
 	}
 
-	diffBody, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
-		A:        difflib.SplitLines(resBodyString),
-		FromFile: "res body",
-		B:        difflib.SplitLines(proxyBodyString),
-		ToFile:   "proxy body",
-		Context:  1,
+	b, err := json.Marshal(&responseDiff{
+		OpCode:      opcode,
+		Command:     command,
+		Differences: diffs,
 	})
 	if err != nil {
-		return err
+		return lazyerrors.Error(err)
 	}
 
-	if len(diffBody) > 0 {
-		// the diff control lines (those with ---, +++, or @@) are created with a trailing newline
-		diffBody = "\n" + strings.TrimSpace(diffBody)
+	c.l.Log(ctx, logLevel, "Response diff: "+string(b))
+
+	return nil
+}
+
+// responseDocument returns the decoded document of OP_MSG or OP_REPLY response body.
+func responseDocument(body wire.MsgBody) (*wirebson.Document, error) {
+	switch body := body.(type) {
+	case *wire.OpMsg:
+		return body.DocumentDeep()
+	case *wire.OpReply:
+		return body.DocumentDeep()
+	default:
+		return nil, lazyerrors.Errorf("unexpected response body type %T", body)
 	}
+}
 
-	c.l.Log(ctx, logLevel, "Header diff:\n"+diffHeader+"\nBody diff:"+diffBody)
+// requestCommand returns the command name of OP_MSG or OP_QUERY request, or "unknown".
+func requestCommand(reqHeader *wire.MsgHeader, reqBody wire.MsgBody) string {
+	var doc *wirebson.Document
+	var err error
 
-	return nil
+	switch reqHeader.OpCode { //nolint:exhaustive // other opcodes have no command
+	case wire.OpCodeMsg:
+		doc, err = reqBody.(*wire.OpMsg).Section0()
+	case wire.OpCodeQuery:
+		doc, err = reqBody.(*wire.OpQuery).Query()
+	}
+
+	if err != nil || doc == nil || doc.Command() == "" {
+		return "unknown"
+	}
+
+	return doc.Command()
 }
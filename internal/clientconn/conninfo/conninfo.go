@@ -28,6 +28,8 @@ type ConnInfo struct {
 
 	conv         *scram.Conv    // protected by rw
 	Peer         netip.AddrPort // invalid for Unix domain sockets
+	Local        netip.AddrPort // invalid for Unix domain sockets
+	appName      string         // protected by rw
 	rw           sync.RWMutex   // rw
 	metadataRecv bool           // protected by rw
 	steps        int            // protected by rw
@@ -66,12 +68,21 @@ func (ci *ConnInfo) MetadataRecv() bool {
 	return ci.metadataRecv
 }
 
-// SetMetadataRecv marks client metadata as received.
-func (ci *ConnInfo) SetMetadataRecv() {
+// SetMetadataRecv marks client metadata as received and stores the client application name (that may be empty).
+func (ci *ConnInfo) SetMetadataRecv(appName string) {
 	ci.rw.Lock()
 	defer ci.rw.Unlock()
 
 	ci.metadataRecv = true
+	ci.appName = appName
+}
+
+// AppName returns the client application name from client metadata.
+func (ci *ConnInfo) AppName() string {
+	ci.rw.RLock()
+	defer ci.rw.RUnlock()
+
+	return ci.appName
 }
 
 // DecrementSteps decreases the steps counter and returns the number of steps left
@@ -28,6 +28,13 @@
 type ConnMetrics struct {
 	Requests  *prometheus.CounterVec
 	Responses *prometheus.CounterVec
+
+	// Diffs and Differences are used only in diff modes.
+	Diffs       *prometheus.CounterVec
+	Differences *prometheus.CounterVec
+
+	// Shadowed is used only when shadowing is enabled.
+	Shadowed *prometheus.CounterVec
 }
 
 // commandMetrics represents command results metrics.
@@ -57,6 +64,33 @@ func newConnMetrics() *ConnMetrics {
 			},
 			[]string{"opcode", "command", "argument", "result"},
 		),
+		Diffs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "diffs_total",
+				Help:      "Total number of compared responses in diff modes.",
+			},
+			[]string{"opcode", "command", "result"},
+		),
+		Differences: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "differences_total",
+				Help:      "Total number of response differences in diff modes.",
+			},
+			[]string{"opcode", "command", "kind"},
+		),
+		Shadowed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "shadowed_total",
+				Help:      "Total number of shadowed requests.",
+			},
+			[]string{"command", "result"},
+		),
 	}
 
 	cm.Requests.WithLabelValues("OP_MSG", "find")
@@ -69,12 +103,18 @@ func newConnMetrics() *ConnMetrics {
 func (cm *ConnMetrics) Describe(ch chan<- *prometheus.Desc) {
 	cm.Requests.Describe(ch)
 	cm.Responses.Describe(ch)
+	cm.Diffs.Describe(ch)
+	cm.Differences.Describe(ch)
+	cm.Shadowed.Describe(ch)
 }
 
 // Collect implements [prometheus.Collector].
 func (cm *ConnMetrics) Collect(ch chan<- prometheus.Metric) {
 	cm.Requests.Collect(ch)
 	cm.Responses.Collect(ch)
+	cm.Diffs.Collect(ch)
+	cm.Differences.Collect(ch)
+	cm.Shadowed.Collect(ch)
 }
 
 // GetResponses returns a map with all response metrics:
@@ -0,0 +1,105 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FerretDB/wire"
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// fuzzRecordsDir is the directory with recorded wire protocol messages (see --record-dir flag)
+// that are added to the seed corpus if present.
+var fuzzRecordsDir = filepath.Join("..", "..", "tmp", "records")
+
+// fuzzMessage returns a wire protocol message with the given opcode and body.
+func fuzzMessage(opCode wire.OpCode, body []byte) []byte {
+	header := &wire.MsgHeader{
+		MessageLength: int32(wire.MsgHeaderLen + len(body)),
+		RequestID:     1,
+		OpCode:        opCode,
+	}
+
+	return append(must.NotFail(header.MarshalBinary()), body...)
+}
+
+func FuzzReadMessage(f *testing.F) {
+	msg := wire.MustOpMsg("hello", int32(1), "$db", "admin")
+	f.Add(fuzzMessage(wire.OpCodeMsg, must.NotFail(msg.MarshalBinary())))
+
+	msg = wire.MustOpMsg(
+		"insert", "values",
+		"documents", wirebson.MustArray(wirebson.MustDocument("_id", int32(1), "v", wirebson.MustArray("foo", 42.13))),
+		"$db", "test",
+	)
+	f.Add(fuzzMessage(wire.OpCodeMsg, must.NotFail(msg.MarshalBinary())))
+
+	query := wire.MustOpQuery("isMaster", int32(1))
+	f.Add(fuzzMessage(wire.OpCodeQuery, must.NotFail(query.MarshalBinary())))
+
+	// OP_COMPRESSED is not supported, but should be rejected gracefully
+	f.Add(fuzzMessage(wire.OpCodeCompressed, must.NotFail(msg.MarshalBinary())))
+
+	if _, err := os.Stat(fuzzRecordsDir); err == nil {
+		records, err := wire.LoadRecords(fuzzRecordsDir, 1000)
+		require.NoError(f, err)
+
+		for _, r := range records {
+			f.Add(append(bytes.Clone(r.HeaderB), r.BodyB...))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		t.Parallel()
+
+		header, body, err := wire.ReadMessage(bufio.NewReader(bytes.NewReader(b)))
+		if err != nil {
+			return
+		}
+
+		_ = header.String()
+		_ = body.String()
+		_ = requestCommand(header, body)
+
+		switch body := body.(type) {
+		case *wire.OpMsg:
+			if _, err = body.DocumentDeep(); err != nil {
+				return
+			}
+
+			req := middleware.RequestWire(header, body)
+			_, err = req.OpMsg.MarshalBinary()
+			require.NoError(t, err)
+
+		case *wire.OpQuery:
+			if _, err = body.QueryDeep(); err != nil {
+				return
+			}
+
+			req := middleware.RequestWire(header, body)
+			_, err = req.OpQuery.MarshalBinary()
+			require.NoError(t, err)
+		}
+	})
+}
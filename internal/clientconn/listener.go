@@ -70,6 +70,12 @@ type ListenerOpts struct {
 	ProxyTLSKeyFile  string
 	ProxyTLSCAFile   string
 
+	ShadowAddr        string // empty value disables shadowing
+	ShadowTLSCertFile string
+	ShadowTLSKeyFile  string
+	ShadowTLSCAFile   string
+	ShadowAll         bool // shadow all commands, not only writes
+
 	TestRecordsDir string // if empty, no records are created
 }
 
@@ -288,6 +294,12 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 				proxyTLSKeyFile:  l.ProxyTLSKeyFile,
 				proxyTLSCAFile:   l.ProxyTLSCAFile,
 
+				shadowAddr:        l.ShadowAddr,
+				shadowTLSCertFile: l.ShadowTLSCertFile,
+				shadowTLSKeyFile:  l.ShadowTLSKeyFile,
+				shadowTLSCAFile:   l.ShadowTLSCAFile,
+				shadowAll:         l.ShadowAll,
+
 				testRecordsDir: l.TestRecordsDir,
 			}
 
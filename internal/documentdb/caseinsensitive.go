@@ -0,0 +1,209 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// caseInsensitiveIndexComment is a prefix of PostgreSQL comments of case-insensitive expression indexes;
+// the rest of the comment is the indexed field name.
+const caseInsensitiveIndexComment = "ferretdb case-insensitive index on "
+
+// caseInsensitiveUncertain is the value of [caseInsensitiveExpr] for values
+// that can't be compared by lowercasing ASCII letters:
+// strings with characters other than printable ASCII,
+// and arrays that DocumentDB converts to text starting with "[".
+const caseInsensitiveUncertain = `E'\x01'`
+
+// caseInsensitiveExpr returns an SQL expression for the lowercased string value of the given top-level field,
+// [caseInsensitiveUncertain] for values that can't be compared that way, or NULL if the field is missing.
+func caseInsensitiveExpr(field string) string {
+	v := "documentdb_core.bson_get_value_text(document, " + quoteLiteral(field) + ")"
+
+	return fmt.Sprintf(
+		`(CASE WHEN %[1]s IS NULL THEN NULL WHEN %[1]s ~ '^[ -~]*$' AND NOT starts_with(%[1]s, '[') `+
+			`THEN lower(%[1]s COLLATE "C") ELSE %[2]s END)`,
+		v, caseInsensitiveUncertain,
+	)
+}
+
+// quoteLiteral returns the given string as an SQL string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// caseInsensitiveIndexName returns the name of the case-insensitive expression index
+// for the given collection table and field.
+func caseInsensitiveIndexName(table, field string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(field))
+
+	return fmt.Sprintf("%s_ci_%016x", strings.TrimPrefix(table, "documentdb_data."), h.Sum64())
+}
+
+// CaseInsensitiveIndexes returns top-level fields of the given collection
+// that have case-insensitive expression indexes created by [CreateCaseInsensitiveIndex].
+func CaseInsensitiveIndexes(ctx context.Context, conn *pgx.Conn, db, collection string) ([]string, error) {
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+		SELECT substr(d.description, $2) FROM pg_index i
+		JOIN pg_description d ON d.objoid = i.indexrelid AND d.classoid = 'pg_class'::regclass
+		WHERE i.indrelid = $1::regclass AND starts_with(d.description, $3)
+		ORDER BY 1
+	`
+
+	rows, err := conn.Query(ctx, q, table, len(caseInsensitiveIndexComment)+1, caseInsensitiveIndexComment)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// CreateCaseInsensitiveIndex creates a PostgreSQL expression index on the lowercased string value
+// of the given top-level field, if it does not exist.
+//
+// The index is used by [FindCaseInsensitive] for both equality and prefix lookups.
+func CreateCaseInsensitiveIndex(ctx context.Context, conn *pgx.Conn, db, collection, field string) error {
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		return err
+	}
+
+	name := caseInsensitiveIndexName(table, field)
+
+	// text_pattern_ops supports LIKE 'prefix%' in addition to equality
+	q := fmt.Sprintf(
+		`CREATE INDEX IF NOT EXISTS %s ON %s (%s text_pattern_ops); COMMENT ON INDEX documentdb_data.%s IS %s`,
+		name, table, caseInsensitiveExpr(field), name, quoteLiteral(caseInsensitiveIndexComment+field),
+	)
+
+	if _, err = conn.Exec(ctx, q); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// DropCaseInsensitiveIndex drops the case-insensitive expression index on the given top-level field, if it exists.
+func DropCaseInsensitiveIndex(ctx context.Context, conn *pgx.Conn, db, collection, field string) error {
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		return err
+	}
+
+	q := `DROP INDEX IF EXISTS documentdb_data.` + caseInsensitiveIndexName(table, field)
+
+	if _, err = conn.Exec(ctx, q); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// FindCaseInsensitive returns up to limit documents of the given collection
+// where the string value of the given top-level field is equal to the given value
+// (or starts with it, if prefix is true), ignoring case of ASCII letters.
+//
+// Documents with values that can't be compared that way (see [caseInsensitiveUncertain])
+// are returned too, so the caller could check them.
+// The caller should also check other returned documents,
+// as non-string values are converted to text by DocumentDB.
+func FindCaseInsensitive(ctx context.Context, conn *pgx.Conn, db, collection, field, value string, prefix bool, limit int64) ([]wirebson.RawDocument, error) { //nolint:lll // for readability
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	cond := `= lower($1::text COLLATE "C")`
+
+	if prefix {
+		value = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+		cond = `LIKE lower($1::text COLLATE "C") || '%'`
+	}
+
+	// the field is inlined, so the expression matches the index one for generic plans too
+	expr := caseInsensitiveExpr(field)
+	q := fmt.Sprintf(
+		`SELECT document::bytea FROM %s WHERE %s %s OR %s = %s LIMIT $2`,
+		table, expr, cond, expr, caseInsensitiveUncertain,
+	)
+
+	rows, err := conn.Query(ctx, q, value, limit)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (wirebson.RawDocument, error) {
+		var b []byte
+		if err := row.Scan(&b); err != nil {
+			return nil, err
+		}
+
+		return wirebson.RawDocument(b), nil
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// ScanDocuments returns at most limit documents of the given collection in the physical order.
+//
+// It is used to filter documents of small collections by FerretDB itself.
+func ScanDocuments(ctx context.Context, conn *pgx.Conn, db, collection string, limit int64) ([]wirebson.RawDocument, error) {
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(ctx, fmt.Sprintf(`SELECT document::bytea FROM %s LIMIT $1`, table), limit)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (wirebson.RawDocument, error) {
+		var b []byte
+		if err := row.Scan(&b); err != nil {
+			return nil, err
+		}
+
+		return wirebson.RawDocument(b), nil
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
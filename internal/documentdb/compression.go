@@ -0,0 +1,122 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// Compression methods of collection documents.
+//
+// They are PostgreSQL TOAST compression methods of the documents column;
+// the default method is set by the `default_toast_compression` PostgreSQL parameter.
+const (
+	CompressionDefault = "default"
+	CompressionPGLZ    = "pglz"
+	CompressionLZ4     = "lz4"
+)
+
+// CompressionMethods contains all supported compression methods.
+var CompressionMethods = []string{CompressionDefault, CompressionPGLZ, CompressionLZ4}
+
+var (
+	// ErrCollectionNotFound is returned by compression functions for non-existent collections and views.
+	ErrCollectionNotFound = errors.New("collection not found")
+
+	// ErrCompressionNotSupported is returned if PostgreSQL was built without support for the compression method.
+	ErrCompressionNotSupported = errors.New("compression method is not supported")
+)
+
+// collectionTable returns the name of the table that stores documents of the given collection.
+func collectionTable(ctx context.Context, conn *pgx.Conn, db, collection string) (string, error) {
+	q := `
+		SELECT collection_id FROM documentdb_api_catalog.collections
+		WHERE database_name = $1 AND collection_name = $2 AND view_definition IS NULL
+	`
+
+	var id int64
+	if err := conn.QueryRow(ctx, q, db, collection).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrCollectionNotFound
+		}
+
+		return "", lazyerrors.Error(err)
+	}
+
+	return fmt.Sprintf("documentdb_data.documents_%d", id), nil
+}
+
+// CollectionCompression returns the compression method of the given collection documents.
+func CollectionCompression(ctx context.Context, conn *pgx.Conn, db, collection string) (string, error) {
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		return "", err
+	}
+
+	q := `SELECT attcompression::text FROM pg_attribute WHERE attrelid = $1::regclass AND attname = 'document'`
+
+	var c string
+	if err = conn.QueryRow(ctx, q, table).Scan(&c); err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	switch c {
+	case "":
+		return CompressionDefault, nil
+	case "p":
+		return CompressionPGLZ, nil
+	case "l":
+		return CompressionLZ4, nil
+	default:
+		return "", lazyerrors.Errorf("unexpected compression method %q", c)
+	}
+}
+
+// SetCollectionCompression sets the compression method of the given collection documents.
+//
+// It affects only new and updated documents; existing documents are not recompressed.
+func SetCollectionCompression(ctx context.Context, conn *pgx.Conn, db, collection, method string) error {
+	if !slices.Contains(CompressionMethods, method) {
+		return lazyerrors.Errorf("unsupported compression method %q", method)
+	}
+
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		return err
+	}
+
+	// table name is generated and method is validated above, so they are safe to use
+	q := fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN document SET COMPRESSION %s`, table, method)
+
+	if _, err = conn.Exec(ctx, q); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.FeatureNotSupported {
+			return ErrCompressionNotSupported
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
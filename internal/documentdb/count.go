@@ -0,0 +1,54 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// CollectionEstimatedCount returns the estimated number of documents in the given collection
+// from PostgreSQL planner statistics, without scanning the table.
+//
+// It returns false if the collection does not exist, is a view,
+// or if statistics were not collected yet.
+func CollectionEstimatedCount(ctx context.Context, conn *pgx.Conn, db, collection string) (int64, bool, error) {
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		if errors.Is(err, ErrCollectionNotFound) {
+			return 0, false, nil
+		}
+
+		return 0, false, err
+	}
+
+	q := `SELECT reltuples FROM pg_class WHERE oid = $1::regclass`
+
+	var n float64
+	if err = conn.QueryRow(ctx, q, table).Scan(&n); err != nil {
+		return 0, false, lazyerrors.Error(err)
+	}
+
+	// -1 means that the table was never vacuumed or analyzed
+	if n < 0 {
+		return 0, false, nil
+	}
+
+	return int64(n), true, nil
+}
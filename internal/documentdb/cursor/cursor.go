@@ -31,9 +31,12 @@ type cursor struct {
 	// the order of fields is weird to make the struct smaller due to alignment
 
 	created      time.Time
+	lastUsed     time.Time
 	token        *resource.Token
 	conn         *pgx.Conn // only if persisted/hijacked
+	data         any       // set by the handler
 	continuation wirebson.RawDocument
+	noTimeout    bool
 }
 
 // newCursor creates a new cursor for the given continuation and connection (if any).
@@ -47,6 +50,8 @@ func newCursor(continuation wirebson.RawDocument, conn *pgx.Conn) *cursor {
 		created:      time.Now(),
 	}
 
+	res.lastUsed = res.created
+
 	resource.Track(res, res.token)
 
 	return res
@@ -182,6 +182,38 @@ func (r *Registry) GetCursor(id int64) (wirebson.RawDocument, *pgx.Conn) {
 	return nil, nil
 }
 
+// SetData associates the given data with the cursor, if it exists.
+func (r *Registry) SetData(id int64, data any) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	if c := r.cursors[id]; c != nil {
+		c.data = data
+	}
+}
+
+// Data returns data associated with the given cursor by [Registry.SetData], if any.
+func (r *Registry) Data(id int64) any {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	if c := r.cursors[id]; c != nil {
+		return c.data
+	}
+
+	return nil
+}
+
+// SetNoTimeout prevents the cursor from being closed by [Registry.CloseIdle], if it exists.
+func (r *Registry) SetNoTimeout(id int64) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	if c := r.cursors[id]; c != nil {
+		c.noTimeout = true
+	}
+}
+
 // UpdateCursor updates existing cursor with given continuation.
 func (r *Registry) UpdateCursor(id int64, continuation wirebson.RawDocument) {
 	// to have better logging for now
@@ -217,6 +249,32 @@ func (r *Registry) UpdateCursor(id int64, continuation wirebson.RawDocument) {
 		slog.Int64("id", id), slog.Any("continuation", cont), slog.Bool("persist", persist),
 	)
 	c.continuation = continuation
+	c.lastUsed = time.Now()
+}
+
+// CloseIdle closes cursors that were not used for longer than the given timeout
+// and removes them from the registry.
+// Cursors marked by [Registry.SetNoTimeout] are not closed.
+// It returns IDs of closed cursors.
+func (r *Registry) CloseIdle(ctx context.Context, timeout time.Duration) []int64 {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	var res []int64
+
+	for id, c := range r.cursors {
+		if c.noTimeout || time.Since(c.lastUsed) <= timeout {
+			continue
+		}
+
+		r.l.DebugContext(ctx, "Closing idle cursor", slog.Int64("id", id), slog.Duration("timeout", timeout))
+
+		if r.closeCursor(ctx, id) {
+			res = append(res, id)
+		}
+	}
+
+	return res
 }
 
 // CloseCursor closes the cursor with the given id and removes it from the registry.
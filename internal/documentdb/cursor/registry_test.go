@@ -0,0 +1,50 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cursor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+	"github.com/FerretDB/FerretDB/v2/internal/util/testutil"
+)
+
+func TestRegistryCloseIdle(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	r := NewRegistry(testutil.Logger(t))
+	defer r.Close(ctx)
+
+	continuation := must.NotFail(must.NotFail(wirebson.NewDocument("continuation", int32(1))).Encode())
+
+	r.NewCursor(1, continuation, nil)
+	r.NewCursor(2, continuation, nil)
+	r.SetNoTimeout(2)
+
+	assert.Empty(t, r.CloseIdle(ctx, time.Hour))
+	assert.Equal(t, []int64{1}, r.CloseIdle(ctx, 0))
+
+	c, _ := r.GetCursor(1)
+	assert.Nil(t, c)
+
+	c, _ = r.GetCursor(2)
+	assert.NotNil(t, c)
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// encryptedAccessMethod is the table access method of pg_tde extension that encrypts data at rest.
+const encryptedAccessMethod = "tde_heap"
+
+// ErrEncryptionNotAvailable is returned by [RotateEncryptionKey] if pg_tde extension is not installed.
+var ErrEncryptionNotAvailable = errors.New("pg_tde extension is not installed")
+
+// EncryptionStatus represents the state of PostgreSQL data at rest encryption.
+//
+// PostgreSQL itself does not encrypt data; that is done by pg_tde extension
+// (https://github.com/percona/pg_tde) with the tde_heap table access method.
+type EncryptionStatus struct {
+	ExtensionVersion    string // empty if pg_tde is not installed
+	DefaultAccessMethod string // used for new collections
+	EncryptedTables     int64  // collection tables using tde_heap
+	UnencryptedTables   int64  // other collection tables
+}
+
+// Enabled returns true if all existing and new collections are encrypted.
+func (s *EncryptionStatus) Enabled() bool {
+	return s.ExtensionVersion != "" && s.DefaultAccessMethod == encryptedAccessMethod && s.UnencryptedTables == 0
+}
+
+// GetEncryptionStatus returns the state of data at rest encryption.
+func GetEncryptionStatus(ctx context.Context, conn *pgx.Conn) (*EncryptionStatus, error) {
+	q := `
+		SELECT
+			(SELECT extversion FROM pg_extension WHERE extname = 'pg_tde'),
+			current_setting('default_table_access_method'),
+			count(*) FILTER (WHERE am.amname = $1),
+			count(*) FILTER (WHERE am.amname IS DISTINCT FROM $1)
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_am am ON am.oid = c.relam
+		WHERE n.nspname = 'documentdb_data' AND c.relkind = 'r' AND c.relname LIKE 'documents\_%'
+	`
+
+	var res EncryptionStatus
+	var ext *string
+
+	err := conn.QueryRow(ctx, q, encryptedAccessMethod).Scan(
+		&ext, &res.DefaultAccessMethod, &res.EncryptedTables, &res.UnencryptedTables,
+	)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if ext != nil {
+		res.ExtensionVersion = *ext
+	}
+
+	return &res, nil
+}
+
+// RotateEncryptionKey creates a new principal key with the given name in the given pg_tde key provider,
+// and re-encrypts table keys of the current database with it.
+//
+// The key provider should be configured beforehand as described in pg_tde documentation.
+func RotateEncryptionKey(ctx context.Context, conn *pgx.Conn, keyName, provider string) error {
+	s, err := GetEncryptionStatus(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if s.ExtensionVersion == "" {
+		return ErrEncryptionNotAvailable
+	}
+
+	if _, err = conn.Exec(ctx, `SELECT pg_tde_set_key_using_database_key_provider($1, $2)`, keyName, provider); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// EncryptTables converts unencrypted collection tables to the tde_heap access method.
+// Tables are rewritten one by one; each of them is locked while rewritten.
+// It returns the number of converted tables.
+func EncryptTables(ctx context.Context, conn *pgx.Conn) (int, error) {
+	q := `
+		SELECT c.relname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_am am ON am.oid = c.relam
+		WHERE n.nspname = 'documentdb_data' AND c.relkind = 'r' AND c.relname LIKE 'documents\_%'
+			AND am.amname IS DISTINCT FROM $1
+		ORDER BY c.relname
+	`
+
+	rows, err := conn.Query(ctx, q, encryptedAccessMethod)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	tables, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	for i, t := range tables {
+		q = fmt.Sprintf(
+			`ALTER TABLE documentdb_data.%s SET ACCESS METHOD %s`,
+			pgx.Identifier{t}.Sanitize(), encryptedAccessMethod,
+		)
+		if _, err = conn.Exec(ctx, q); err != nil {
+			return i, lazyerrors.Error(err)
+		}
+	}
+
+	return len(tables), nil
+}
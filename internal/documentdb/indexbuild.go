@@ -0,0 +1,80 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// IndexBuildProgress represents the progress of index builds on a collection
+// as reported by the pg_stat_progress_create_index PostgreSQL view.
+type IndexBuildProgress struct {
+	Phase       string
+	BlocksDone  int64
+	BlocksTotal int64
+	TuplesDone  int64
+	TuplesTotal int64
+}
+
+// CollectionIndexBuildProgress returns the progress of index builds on the given collection.
+//
+// It returns nil if PostgreSQL does not build any indexes for that collection at the moment,
+// for example, if the build is queued or has not started the table scan yet.
+// Progress of concurrent builds is summed up; the phase is reported for one of them.
+func CollectionIndexBuildProgress(ctx context.Context, conn *pgx.Conn, db, collection string) (*IndexBuildProgress, error) {
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+		SELECT phase, blocks_done, blocks_total, tuples_done, tuples_total
+		FROM pg_stat_progress_create_index WHERE relid = $1::regclass
+	`
+
+	rows, err := conn.Query(ctx, q, table)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	var res *IndexBuildProgress
+
+	for rows.Next() {
+		var p IndexBuildProgress
+		if err = rows.Scan(&p.Phase, &p.BlocksDone, &p.BlocksTotal, &p.TuplesDone, &p.TuplesTotal); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if res == nil {
+			res = &IndexBuildProgress{Phase: p.Phase}
+		}
+
+		res.BlocksDone += p.BlocksDone
+		res.BlocksTotal += p.BlocksTotal
+		res.TuplesDone += p.TuplesDone
+		res.TuplesTotal += p.TuplesTotal
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
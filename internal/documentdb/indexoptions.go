@@ -0,0 +1,39 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+)
+
+// indexOptionsMetadata is a kind of collection metadata with index options.
+const indexOptionsMetadata = "indexOptions"
+
+// IndexOptions returns index options stored by [UpdateIndexOptions] for the given collection.
+// It returns nil if there are none, or if the collection does not exist.
+func IndexOptions(ctx context.Context, conn *pgx.Conn, db, collection string) (*wirebson.Document, error) {
+	return Metadata(ctx, conn, db, collection, indexOptionsMetadata)
+}
+
+// UpdateIndexOptions updates index options stored for the given collection with the given function.
+// The function gets a document with the current options (possibly empty) and modifies it in place.
+//
+// Concurrent updates for the same collection are serialized.
+func UpdateIndexOptions(ctx context.Context, conn *pgx.Conn, db, collection string, update func(*wirebson.Document) error) error { //nolint:lll // for readability
+	return UpdateMetadata(ctx, conn, db, collection, indexOptionsMetadata, update)
+}
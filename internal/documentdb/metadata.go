@@ -0,0 +1,226 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// metadataComment is a prefix of PostgreSQL comments of collection tables with stored FerretDB metadata;
+// the rest of the comment is base64-encoded BSON document with a field per metadata kind.
+const metadataComment = "ferretdb metadata: "
+
+// CollectionMetadata represents metadata of a single kind stored for a collection.
+type CollectionMetadata struct {
+	DB         string
+	Collection string
+	Metadata   *wirebson.Document
+}
+
+// Metadata returns metadata of the given kind stored by [UpdateMetadata] for the given collection.
+// It returns nil if there is none, or if the collection does not exist.
+func Metadata(ctx context.Context, conn *pgx.Conn, db, collection, kind string) (*wirebson.Document, error) {
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		if errors.Is(err, ErrCollectionNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	md, err := tableMetadata(ctx, conn, table)
+	if err != nil || md == nil {
+		return nil, err
+	}
+
+	res, _ := md.Get(kind).(*wirebson.Document)
+
+	return res, nil
+}
+
+// AllMetadata returns metadata of the given kind stored for all collections.
+func AllMetadata(ctx context.Context, conn *pgx.Conn, kind string) ([]CollectionMetadata, error) {
+	q := `
+		SELECT database_name, collection_name,
+			obj_description(format('documentdb_data.documents_%s', collection_id)::regclass, 'pg_class')
+		FROM documentdb_api_catalog.collections
+		WHERE view_definition IS NULL
+	`
+
+	rows, err := conn.Query(ctx, q)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var res []CollectionMetadata
+
+	var db, collection string
+	var comment *string
+
+	_, err = pgx.ForEachRow(rows, []any{&db, &collection, &comment}, func() error {
+		md, err := decodeMetadata(comment)
+		if err != nil || md == nil {
+			return err
+		}
+
+		if d, _ := md.Get(kind).(*wirebson.Document); d != nil {
+			res = append(res, CollectionMetadata{DB: db, Collection: collection, Metadata: d})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// AllCollections returns databases and names of all collections, but not views.
+// Metadata fields of returned values are nil.
+func AllCollections(ctx context.Context, conn *pgx.Conn) ([]CollectionMetadata, error) {
+	q := `SELECT database_name, collection_name FROM documentdb_api_catalog.collections WHERE view_definition IS NULL`
+
+	rows, err := conn.Query(ctx, q)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (CollectionMetadata, error) {
+		var c CollectionMetadata
+		err := row.Scan(&c.DB, &c.Collection)
+
+		return c, err
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// tableMetadata returns all metadata stored in the comment of the given collection table.
+func tableMetadata(ctx context.Context, conn *pgx.Conn, table string) (*wirebson.Document, error) {
+	var comment *string
+	if err := conn.QueryRow(ctx, `SELECT obj_description($1::regclass, 'pg_class')`, table).Scan(&comment); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return decodeMetadata(comment)
+}
+
+// decodeMetadata decodes metadata from the given table comment.
+// It returns nil if the comment is not set or does not contain metadata.
+func decodeMetadata(comment *string) (*wirebson.Document, error) {
+	if comment == nil {
+		return nil, nil
+	}
+
+	encoded, ok := strings.CutPrefix(*comment, metadataComment)
+	if !ok {
+		return nil, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := wirebson.RawDocument(b).DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// encodeMetadata returns the SQL literal for the comment with the given metadata,
+// or NULL if it is empty.
+func encodeMetadata(md *wirebson.Document) (string, error) {
+	if md.Len() == 0 {
+		return "NULL", nil
+	}
+
+	raw, err := md.Encode()
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	return quoteLiteral(metadataComment + base64.StdEncoding.EncodeToString(raw)), nil
+}
+
+// UpdateMetadata updates metadata of the given kind stored for the given collection with the given function.
+// The function gets a document with the current metadata (possibly empty) and modifies it in place;
+// empty document removes metadata of that kind.
+//
+// Concurrent updates for the same collection are serialized.
+func UpdateMetadata(ctx context.Context, conn *pgx.Conn, db, collection, kind string, update func(*wirebson.Document) error) error { //nolint:lll // for readability
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, metadataComment+table); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		var md *wirebson.Document
+
+		if md, err = tableMetadata(ctx, tx.Conn(), table); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if md == nil {
+			md = wirebson.MakeDocument(1)
+		}
+
+		d, _ := md.Get(kind).(*wirebson.Document)
+		if d == nil {
+			d = wirebson.MakeDocument(0)
+		}
+
+		if err = update(d); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		md.Remove(kind)
+
+		if d.Len() > 0 {
+			must.NoError(md.Add(kind, d))
+		}
+
+		var comment string
+		if comment, err = encodeMetadata(md); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if _, err = tx.Exec(ctx, `COMMENT ON TABLE `+table+` IS `+comment); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// Notify sends a notification with the given payload to the given PostgreSQL channel.
+// Payload should be shorter than 8000 bytes.
+func (p *Pool) Notify(ctx context.Context, channel, payload string) error {
+	return p.WithConn(func(conn *pgx.Conn) error {
+		if _, err := conn.Exec(ctx, `SELECT pg_notify($1, $2)`, channel, payload); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+}
+
+// Listen listens for notifications on the given PostgreSQL channel
+// and calls f with the payload of each of them.
+// It uses a dedicated connection that is removed from the pool.
+//
+// The listening function is called once the connection starts listening;
+// notifications sent before that are not received.
+//
+// It blocks until ctx is canceled (then it returns nil) or the connection fails.
+func (p *Pool) Listen(ctx context.Context, channel string, listening func(), f func(payload string)) error {
+	pooled, err := p.Acquire()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer pooled.Release()
+
+	conn := pooled.hijack()
+	defer conn.Close(context.WithoutCancel(ctx)) //nolint:errcheck // nothing to do on error
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	listening()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return lazyerrors.Error(err)
+		}
+
+		f(n.Payload)
+	}
+}
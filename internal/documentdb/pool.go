@@ -15,7 +15,12 @@
 package documentdb
 
 import (
+	"context"
 	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"sync/atomic"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -41,6 +46,11 @@ type Pool struct {
 	r     *cursor.Registry
 	l     *slog.Logger
 	token *resource.Token
+
+	sharedCursorsTable atomic.Bool // true if the shared cursors table was created
+	indexStatsTable    atomic.Bool // true if the index usage counters table was created
+
+	health poolHealth
 }
 
 // NewPool creates a new pool of PostgreSQL connections.
@@ -86,6 +96,16 @@ func (p *Pool) Acquire() (*Conn, error) {
 	return newConn(conn), nil
 }
 
+// Ping establishes a connection to PostgreSQL if there are none,
+// running DocumentDB installation checks.
+func (p *Pool) Ping(ctx context.Context) error {
+	if err := p.p.Ping(ctx); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
 // WithConn acquires a connection from the pool and calls the provided function with it.
 // The connection is automatically released after the function returns.
 func (p *Pool) WithConn(f func(*pgx.Conn) error) error {
@@ -103,6 +123,29 @@ func (p *Pool) WithConn(f func(*pgx.Conn) error) error {
 	return nil
 }
 
+// DropConnections closes all pooled connections if one of the given "host:port" addresses
+// matches the PostgreSQL server (or one of fallback servers) address.
+// Connections that are currently in use are closed when they are released;
+// connections used by cursors are not affected.
+// It returns true if connections were dropped.
+func (p *Pool) DropConnections(hostAndPort []string) bool {
+	cc := p.p.Config().ConnConfig
+
+	addrs := []string{net.JoinHostPort(cc.Host, strconv.Itoa(int(cc.Port)))}
+	for _, fb := range cc.Fallbacks {
+		addrs = append(addrs, net.JoinHostPort(fb.Host, strconv.Itoa(int(fb.Port))))
+	}
+
+	for _, addr := range hostAndPort {
+		if slices.Contains(addrs, addr) {
+			p.p.Reset()
+			return true
+		}
+	}
+
+	return false
+}
+
 // Describe implements [prometheus.Collector].
 func (p *Pool) Describe(ch chan<- *prometheus.Desc) {
 	prometheus.DescribeByCollect(p, ch)
@@ -16,10 +16,16 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
+	"time"
 
 	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.opentelemetry.io/otel"
 
 	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
@@ -42,7 +48,9 @@ func (p *Pool) GetMore(ctx context.Context, db string, spec wirebson.RawDocument
 		)
 	}
 
-	if conn == nil {
+	persisted := conn != nil
+
+	if !persisted {
 		poolConn, err := p.Acquire()
 		if err != nil {
 			return nil, lazyerrors.Error(err)
@@ -55,7 +63,12 @@ func (p *Pool) GetMore(ctx context.Context, db string, spec wirebson.RawDocument
 
 	page, continuation, err := documentdb_api.CursorGetMore(ctx, conn, p.l, db, spec, continuation)
 	if err != nil {
-		p.r.CloseCursor(ctx, cursorID)
+		// the continuation was not updated, so a cursor without a persisted connection
+		// could be resumed by retrying getMore after a transient error
+		if persisted || !isTransient(err) {
+			p.r.CloseCursor(ctx, cursorID)
+		}
+
 		return nil, lazyerrors.Error(err)
 	}
 
@@ -83,6 +96,33 @@ func (p *Pool) KillCursor(ctx context.Context, id int64) bool {
 	return p.r.CloseCursor(ctx, id)
 }
 
+// SetCursorData associates the given data with the cursor, if it exists.
+// The handler uses it to store cursor options it implements itself.
+func (p *Pool) SetCursorData(id int64, data any) {
+	p.r.SetData(id, data)
+}
+
+// CursorData returns data associated with the given cursor by [Pool.SetCursorData], if any.
+func (p *Pool) CursorData(id int64) any {
+	return p.r.Data(id)
+}
+
+// SetCursorNoTimeout prevents the cursor from being closed by [Pool.KillIdleCursors].
+// It is a part of the implementation of `noCursorTimeout` option.
+func (p *Pool) SetCursorNoTimeout(id int64) {
+	p.r.SetNoTimeout(id)
+}
+
+// KillIdleCursors closes cursors that were not used for longer than the given timeout,
+// except cursors created with `noCursorTimeout` option.
+// It returns IDs of closed cursors.
+func (p *Pool) KillIdleCursors(ctx context.Context, timeout time.Duration) []int64 {
+	ctx, span := otel.Tracer("").Start(ctx, "pool.KillIdleCursors")
+	defer span.End()
+
+	return p.r.CloseIdle(ctx, timeout)
+}
+
 // ListCollections returns the first page of the `listCollections` cursor and the cursor ID.
 func (p *Pool) ListCollections(ctx context.Context, db string, spec wirebson.RawDocument) (wirebson.RawDocument, int64, error) {
 	ctx, span := otel.Tracer("").Start(ctx, "pool.ListCollections")
@@ -223,3 +263,16 @@ func (p *Pool) ListIndexes(ctx context.Context, db string, spec wirebson.RawDocu
 
 	return page, cursorID, nil
 }
+
+// isTransient returns true if the error was caused by a serialization failure, a deadlock,
+// or a broken PostgreSQL connection, so the operation could be retried.
+func isTransient(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgerrcode.SerializationFailure || pgErr.Code == pgerrcode.DeadlockDetected
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
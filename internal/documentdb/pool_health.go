@@ -0,0 +1,245 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+)
+
+// healthCheckTimeout is the maximal duration of a single health check.
+const healthCheckTimeout = 5 * time.Second
+
+// poolHealth tracks the availability of the PostgreSQL primary.
+type poolHealth struct {
+	rw sync.RWMutex
+
+	unhealthy bool // true if the primary is not available
+	failback  bool // true if the pool could be connected to a fallback host
+
+	// version is incremented each time the primary changes availability or host;
+	// changed is closed and replaced at the same time.
+	version int64
+	changed chan struct{}
+}
+
+// topology returns the current version and the channel that is closed when it changes.
+func (ph *poolHealth) topology() (int64, <-chan struct{}) {
+	ph.rw.Lock()
+	defer ph.rw.Unlock()
+
+	if ph.changed == nil {
+		ph.changed = make(chan struct{})
+	}
+
+	return ph.version, ph.changed
+}
+
+// bump increments the version and notifies waiters.
+// The caller should hold the lock.
+func (ph *poolHealth) bump() {
+	ph.version++
+
+	if ph.changed != nil {
+		close(ph.changed)
+	}
+
+	ph.changed = make(chan struct{})
+}
+
+// Healthy returns false if the last health check could not reach a writable PostgreSQL primary.
+func (p *Pool) Healthy() bool {
+	p.health.rw.RLock()
+	defer p.health.rw.RUnlock()
+
+	return !p.health.unhealthy
+}
+
+// Topology returns the version of PostgreSQL topology and the channel that is closed when it changes
+// (on failover, failback, or when the primary becomes unavailable or available again).
+// It is used for `topologyVersion` of `hello` responses.
+func (p *Pool) Topology() (int64, <-chan struct{}) {
+	return p.health.topology()
+}
+
+// CheckHealth checks that a connection to a writable PostgreSQL primary could be established.
+//
+// If the primary becomes unavailable (for example, it was stopped or demoted),
+// all pooled connections are closed, so new connections are established to the next available host
+// listed in the PostgreSQL URL (failover).
+// If the pool uses a fallback host and the first host becomes available as a primary again,
+// pooled connections are closed too, so new connections are established to it (failback).
+//
+// It returns true if the primary is available.
+func (p *Pool) CheckHealth(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	var addr string
+
+	err := p.WithConn(func(conn *pgx.Conn) error {
+		var recovery bool
+		if err := conn.QueryRow(ctx, `SELECT pg_is_in_recovery()`).Scan(&recovery); err != nil {
+			// the connection is broken; close it instead of returning to the pool
+			_ = conn.Close(ctx)
+			return lazyerrors.Error(err)
+		}
+
+		if recovery {
+			return lazyerrors.New("PostgreSQL server is in recovery mode (standby)")
+		}
+
+		addr = conn.PgConn().Conn().RemoteAddr().String()
+
+		return nil
+	})
+
+	p.health.rw.Lock()
+	defer p.health.rw.Unlock()
+
+	if err != nil {
+		if !p.health.unhealthy {
+			p.l.WarnContext(ctx, "PostgreSQL primary is not available, failing over", logging.Error(err))
+
+			p.health.unhealthy = true
+			p.health.bump()
+			p.p.Reset()
+		}
+
+		return false
+	}
+
+	if p.health.unhealthy {
+		p.l.InfoContext(ctx, "PostgreSQL primary is available", slog.String("addr", addr))
+
+		p.health.unhealthy = false
+		p.health.failback = true
+		p.health.bump()
+	}
+
+	if len(p.p.Config().ConnConfig.Fallbacks) == 0 {
+		p.health.failback = false
+		return true
+	}
+
+	// check the first host on start and after failover
+	if !p.health.failback && p.health.version > 0 {
+		return true
+	}
+
+	preferred, err := p.preferredPrimary(ctx)
+	if err != nil {
+		p.l.DebugContext(ctx, "Preferred PostgreSQL host is not available as primary", logging.Error(err))
+		p.health.failback = true
+
+		return true
+	}
+
+	p.health.failback = false
+
+	if preferred != addr {
+		p.l.InfoContext(
+			ctx, "Preferred PostgreSQL host is available as primary, failing back",
+			slog.String("addr", addr), slog.String("preferred", preferred),
+		)
+
+		p.health.bump()
+		p.p.Reset()
+	}
+
+	return true
+}
+
+// preferredPrimary connects directly to the first host of the PostgreSQL URL
+// and returns its address if it is a primary.
+func (p *Pool) preferredPrimary(ctx context.Context) (string, error) {
+	config := p.p.Config().ConnConfig.Copy()
+	config.Fallbacks = nil
+	config.Tracer = nil
+
+	conn, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	defer conn.Close(ctx) //nolint:errcheck // nothing to do on error
+
+	var recovery bool
+	if err = conn.QueryRow(ctx, `SELECT pg_is_in_recovery()`).Scan(&recovery); err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	if recovery {
+		return "", lazyerrors.New("PostgreSQL server is in recovery mode (standby)")
+	}
+
+	return conn.PgConn().Conn().RemoteAddr().String(), nil
+}
+
+// WaitHealthy waits until a writable PostgreSQL primary is available, checking it periodically.
+// It returns an error if it is not available after the given timeout or when ctx is canceled.
+func (p *Pool) WaitHealthy(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		if p.CheckHealth(ctx) {
+			return nil
+		}
+
+		ctxutil.Sleep(ctx, 500*time.Millisecond)
+
+		if err := ctx.Err(); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+}
+
+// IsFailoverError returns true if the error could be caused by PostgreSQL failover:
+// a broken connection, a server shutdown, or a write to a demoted primary.
+func IsFailoverError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.AdminShutdown, pgerrcode.CrashShutdown, pgerrcode.CannotConnectNow,
+			pgerrcode.ReadOnlySQLTransaction, pgerrcode.ConnectionFailure:
+			return true
+		default:
+			return false
+		}
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,195 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// indexStatsTable is the PostgreSQL table that stores index usage counters persisted by FerretDB,
+// so they survive PostgreSQL statistics resets, crashes, and failovers.
+const indexStatsTable = "ferretdb_index_stats"
+
+// IndexUsage represents usage counters of a single index.
+type IndexUsage struct {
+	Index string
+
+	// Ops is the total number of operations that used the index for [Pool.IndexUsage],
+	// and the current value of the `$indexStats` counter for [Pool.SaveIndexUsage].
+	Ops int64
+
+	// Last is the value of the `$indexStats` counter at the time of the last save.
+	// It is set only by [Pool.IndexUsage].
+	Last int64
+
+	// Since is the time when counting started.
+	Since time.Time
+}
+
+// ensureIndexStats creates the index usage counters table if needed.
+func (p *Pool) ensureIndexStats(ctx context.Context, conn *pgx.Conn) error {
+	if p.indexStatsTable.Load() {
+		return nil
+	}
+
+	q := `CREATE TABLE IF NOT EXISTS ` + indexStatsTable + ` (
+		database_name text NOT NULL,
+		collection_name text NOT NULL,
+		index_name text NOT NULL,
+		ops bigint NOT NULL,
+		last_ops bigint NOT NULL,
+		since timestamptz NOT NULL,
+		updated timestamptz NOT NULL,
+		PRIMARY KEY (database_name, collection_name, index_name)
+	)`
+
+	if _, err := conn.Exec(ctx, q); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	p.indexStatsTable.Store(true)
+
+	return nil
+}
+
+// withIndexStats calls the given function with a connection after ensuring that the index usage counters table exists.
+func (p *Pool) withIndexStats(ctx context.Context, f func(*pgx.Conn) error) error {
+	return p.WithConn(func(conn *pgx.Conn) error {
+		if err := p.ensureIndexStats(ctx, conn); err != nil {
+			return err
+		}
+
+		return f(conn)
+	})
+}
+
+// LockIndexUsage calls the given function while holding the lock
+// that prevents concurrent [Pool.SaveIndexUsage] calls by other FerretDB instances.
+// That way, counters are read and saved in order, and decreased counters always mean statistics resets.
+//
+// It returns false without calling the function if the lock is held by another instance.
+func (p *Pool) LockIndexUsage(ctx context.Context, f func() error) (bool, error) {
+	var locked bool
+
+	err := p.withIndexStats(ctx, func(conn *pgx.Conn) error {
+		q := `SELECT pg_try_advisory_lock(hashtext($1))`
+		if err := conn.QueryRow(ctx, q, indexStatsTable).Scan(&locked); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if !locked {
+			return nil
+		}
+
+		defer func() {
+			// use a separate context, as the lock should be released even if ctx is canceled
+			_, _ = conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock(hashtext($1))`, indexStatsTable)
+		}()
+
+		return f()
+	})
+
+	return locked, err
+}
+
+// SaveIndexUsage adds the increase of `$indexStats` counters of the given collection since the last save
+// to the persisted ones.
+// Counters that decreased since then (because PostgreSQL statistics were reset) are added as is.
+// Counters of indexes not in the given list (dropped ones) are deleted.
+func (p *Pool) SaveIndexUsage(ctx context.Context, db, collection string, usage []IndexUsage) error {
+	return p.withIndexStats(ctx, func(conn *pgx.Conn) error {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			names := make([]string, len(usage))
+
+			q := `INSERT INTO ` + indexStatsTable + ` AS s
+				(database_name, collection_name, index_name, ops, last_ops, since, updated)
+				VALUES ($1, $2, $3, $4, $4, $5, now())
+				ON CONFLICT (database_name, collection_name, index_name) DO UPDATE SET
+					ops = s.ops + CASE
+						WHEN EXCLUDED.last_ops >= s.last_ops THEN EXCLUDED.last_ops - s.last_ops
+						ELSE EXCLUDED.last_ops
+					END,
+					last_ops = EXCLUDED.last_ops,
+					updated = EXCLUDED.updated`
+
+			for i, u := range usage {
+				names[i] = u.Index
+
+				if _, err := tx.Exec(ctx, q, db, collection, u.Index, u.Ops, u.Since); err != nil {
+					return lazyerrors.Error(err)
+				}
+			}
+
+			q = `DELETE FROM ` + indexStatsTable + `
+				WHERE database_name = $1 AND collection_name = $2 AND index_name <> ALL($3)`
+
+			if _, err := tx.Exec(ctx, q, db, collection, names); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			return nil
+		})
+	})
+}
+
+// IndexUsage returns counters of the given collection indexes saved by [Pool.SaveIndexUsage].
+func (p *Pool) IndexUsage(ctx context.Context, db, collection string) ([]IndexUsage, error) {
+	var res []IndexUsage
+
+	err := p.withIndexStats(ctx, func(conn *pgx.Conn) error {
+		q := `SELECT index_name, ops, last_ops, since FROM ` + indexStatsTable + `
+			WHERE database_name = $1 AND collection_name = $2 ORDER BY index_name`
+
+		rows, err := conn.Query(ctx, q, db, collection)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		res, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (IndexUsage, error) {
+			var u IndexUsage
+			err := row.Scan(&u.Index, &u.Ops, &u.Last, &u.Since)
+
+			return u, err
+		})
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+
+	return res, err
+}
+
+// DeleteDroppedIndexUsage deletes counters of collections that no longer exist.
+func (p *Pool) DeleteDroppedIndexUsage(ctx context.Context) error {
+	return p.withIndexStats(ctx, func(conn *pgx.Conn) error {
+		q := `DELETE FROM ` + indexStatsTable + ` AS s WHERE NOT EXISTS (
+			SELECT FROM documentdb_api_catalog.collections AS c
+			WHERE c.database_name = s.database_name AND c.collection_name = s.collection_name
+		)`
+
+		if _, err := conn.Exec(ctx, q); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+}
@@ -18,6 +18,7 @@
 	"context"
 	"log/slog"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -45,6 +46,11 @@ func newPgxPool(uri string, l *slog.Logger, sp *state.Provider) (*pgxpool.Pool,
 
 	q := u.Query()
 	newPgxPoolSetDefaults(q)
+
+	// connect only to the primary, trying other hosts on failover
+	if strings.Contains(u.Host, ",") && !q.Has("target_session_attrs") {
+		q.Set("target_session_attrs", "read-write")
+	}
 	u.RawQuery = q.Encode()
 
 	config, err := pgxpool.ParseConfig(u.String())
@@ -119,13 +125,28 @@ func newPgxPoolCheckConn(ctx context.Context, conn *pgx.Conn, l *slog.Logger, sp
 
 	row := conn.QueryRow(ctx, `SELECT version(), documentdb_api.binary_extended_version()`)
 	if err := row.Scan(&postgresqlVersion, &documentdbVersion); err != nil {
+		// try to return a more actionable error
+		if _, e := preflight(ctx, conn); e != nil {
+			return lazyerrors.Error(e)
+		}
+
 		return lazyerrors.Errorf("%w (please check DocumentDB installation)", err)
 	}
 
 	if s := sp.Get(); s.PostgreSQLVersion != postgresqlVersion || s.DocumentDBVersion != documentdbVersion {
-		err := sp.Update(func(s *state.State) {
+		warnings, err := preflight(ctx, conn)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		for _, w := range warnings {
+			l.WarnContext(ctx, "Preflight check found a problem", slog.String("warning", w))
+		}
+
+		err = sp.Update(func(s *state.State) {
 			s.PostgreSQLVersion = postgresqlVersion
 			s.DocumentDBVersion = documentdbVersion
+			s.BackendWarnings = warnings
 		})
 		if err != nil {
 			l.ErrorContext(ctx, "newPgxPoolCheckConn: failed to update state", logging.Error(err))
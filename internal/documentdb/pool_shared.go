@@ -0,0 +1,204 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// sharedCursorsTable is the PostgreSQL table that stores cursors shared by FerretDB instances.
+// It is unlogged, as cursors do not survive PostgreSQL restarts anyway.
+const sharedCursorsTable = "ferretdb_shared_cursors"
+
+// SharedCursor represents a cursor stored in PostgreSQL,
+// so it could be resumed by any FerretDB instance using the same PostgreSQL.
+type SharedCursor struct {
+	UserID       []byte
+	SessionID    []byte
+	Continuation wirebson.RawDocument
+	Data         wirebson.RawDocument // set by the handler, may be nil
+	NoTimeout    bool
+}
+
+// ensureSharedCursors creates the shared cursors table if needed.
+func (p *Pool) ensureSharedCursors(ctx context.Context, conn *pgx.Conn) error {
+	if p.sharedCursorsTable.Load() {
+		return nil
+	}
+
+	q := `CREATE UNLOGGED TABLE IF NOT EXISTS ` + sharedCursorsTable + ` (
+		id bigint PRIMARY KEY,
+		user_id bytea NOT NULL,
+		session_id bytea NOT NULL,
+		continuation bytea NOT NULL,
+		data bytea,
+		no_timeout boolean NOT NULL,
+		updated timestamptz NOT NULL
+	)`
+
+	if _, err := conn.Exec(ctx, q); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	p.sharedCursorsTable.Store(true)
+
+	return nil
+}
+
+// withSharedCursors calls the given function with a connection after ensuring that the shared cursors table exists.
+func (p *Pool) withSharedCursors(ctx context.Context, f func(*pgx.Conn) error) error {
+	return p.WithConn(func(conn *pgx.Conn) error {
+		if err := p.ensureSharedCursors(ctx, conn); err != nil {
+			return err
+		}
+
+		return f(conn)
+	})
+}
+
+// ShareableContinuation returns the current continuation of the cursor with the given ID
+// if it could be resumed by another FerretDB instance.
+// It returns nil if the cursor does not exist or uses a persisted connection.
+func (p *Pool) ShareableContinuation(id int64) wirebson.RawDocument {
+	continuation, conn := p.r.GetCursor(id)
+	if conn != nil {
+		return nil
+	}
+
+	return continuation
+}
+
+// ResumeCursor stores the cursor with the given ID and continuation loaded by [Pool.LoadSharedCursor],
+// replacing the continuation of the local cursor with the same ID (that could be stale) if it exists.
+// Cursors with persisted connections are not changed.
+// It returns true if the cursor existed locally.
+func (p *Pool) ResumeCursor(id int64, continuation wirebson.RawDocument) bool {
+	current, conn := p.r.GetCursor(id)
+
+	switch {
+	case conn != nil:
+		return true
+
+	case current != nil:
+		p.r.UpdateCursor(id, continuation)
+		return true
+
+	default:
+		p.r.NewCursor(id, continuation, nil)
+		return false
+	}
+}
+
+// SaveSharedCursor stores the cursor with the given ID, so it could be resumed by other FerretDB instances.
+func (p *Pool) SaveSharedCursor(ctx context.Context, id int64, c *SharedCursor) error {
+	return p.withSharedCursors(ctx, func(conn *pgx.Conn) error {
+		q := `INSERT INTO ` + sharedCursorsTable + `
+			(id, user_id, session_id, continuation, data, no_timeout, updated)
+			VALUES ($1, $2, $3, $4, $5, $6, now())
+			ON CONFLICT (id) DO UPDATE SET continuation = EXCLUDED.continuation, updated = EXCLUDED.updated`
+
+		_, err := conn.Exec(ctx, q, id, c.UserID, c.SessionID, []byte(c.Continuation), []byte(c.Data), c.NoTimeout)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+}
+
+// LoadSharedCursor returns the cursor with the given ID stored by [Pool.SaveSharedCursor].
+// It returns nil if there is none.
+func (p *Pool) LoadSharedCursor(ctx context.Context, id int64) (*SharedCursor, error) {
+	var res *SharedCursor
+
+	err := p.withSharedCursors(ctx, func(conn *pgx.Conn) error {
+		var c SharedCursor
+		var continuation, data []byte
+
+		q := `SELECT user_id, session_id, continuation, data, no_timeout FROM ` + sharedCursorsTable + ` WHERE id = $1`
+
+		err := conn.QueryRow(ctx, q, id).Scan(&c.UserID, &c.SessionID, &continuation, &data, &c.NoTimeout)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+
+			return lazyerrors.Error(err)
+		}
+
+		c.Continuation = continuation
+		c.Data = data
+		res = &c
+
+		return nil
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// DeleteSharedCursors deletes shared cursors with the given IDs.
+func (p *Pool) DeleteSharedCursors(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return p.withSharedCursors(ctx, func(conn *pgx.Conn) error {
+		if _, err := conn.Exec(ctx, `DELETE FROM `+sharedCursorsTable+` WHERE id = ANY($1)`, ids); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+}
+
+// DeleteUserSharedCursors deletes shared cursors of the given sessions of the given users.
+// If sessionIDs is nil, cursors of all sessions of those users are deleted.
+// If userIDs is nil, all shared cursors are deleted.
+func (p *Pool) DeleteUserSharedCursors(ctx context.Context, userIDs, sessionIDs [][]byte) error {
+	return p.withSharedCursors(ctx, func(conn *pgx.Conn) error {
+		q := `DELETE FROM ` + sharedCursorsTable + `
+			WHERE ($1::bytea[] IS NULL OR user_id = ANY($1)) AND ($2::bytea[] IS NULL OR session_id = ANY($2))`
+
+		if _, err := conn.Exec(ctx, q, userIDs, sessionIDs); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+}
+
+// DeleteIdleSharedCursors deletes shared cursors that were not used for longer than the given timeout,
+// except cursors created with `noCursorTimeout` option.
+func (p *Pool) DeleteIdleSharedCursors(ctx context.Context, timeout time.Duration) error {
+	return p.withSharedCursors(ctx, func(conn *pgx.Conn) error {
+		q := `DELETE FROM ` + sharedCursorsTable + ` WHERE NOT no_timeout AND updated < now() - $1::interval`
+
+		if _, err := conn.Exec(ctx, q, timeout); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+}
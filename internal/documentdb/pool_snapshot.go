@@ -0,0 +1,138 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// SnapshotQuery represents a single `find` query of [Pool.FindSnapshot].
+type SnapshotQuery struct {
+	DB   string
+	Spec wirebson.RawDocument
+}
+
+// SnapshotCursor represents the first page and the ID of a single cursor returned by [Pool.FindSnapshot].
+type SnapshotCursor struct {
+	Page     wirebson.RawDocument
+	CursorID int64
+}
+
+// FindSnapshot returns first pages of `find` cursors for the given queries and the exported snapshot ID.
+//
+// All cursors see the same transactionally consistent snapshot of data without blocking writes,
+// and could be iterated in parallel with [Pool.GetMore].
+// Each cursor holds a separate connection with a read-only transaction until it is exhausted or closed.
+func (p *Pool) FindSnapshot(ctx context.Context, queries []SnapshotQuery) ([]SnapshotCursor, string, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "pool.FindSnapshot")
+	defer span.End()
+
+	exporter, err := p.Acquire()
+	if err != nil {
+		return nil, "", lazyerrors.Error(err)
+	}
+	defer exporter.Release()
+
+	tx, err := exporter.Conn().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, "", lazyerrors.Error(err)
+	}
+
+	// the exported snapshot is needed only until all cursor transactions import it
+	defer tx.Rollback(ctx) //nolint:errcheck // nothing was written
+
+	var snapshot string
+	if err = tx.QueryRow(ctx, "SELECT pg_export_snapshot()").Scan(&snapshot); err != nil {
+		return nil, "", lazyerrors.Error(err)
+	}
+
+	res := make([]SnapshotCursor, 0, len(queries))
+
+	for _, q := range queries {
+		var c SnapshotCursor
+
+		if c, err = p.findInSnapshot(ctx, snapshot, q); err != nil {
+			for _, c := range res {
+				if c.CursorID != 0 {
+					p.r.CloseCursor(ctx, c.CursorID)
+				}
+			}
+
+			return nil, "", lazyerrors.Error(err)
+		}
+
+		res = append(res, c)
+	}
+
+	return res, snapshot, nil
+}
+
+// findInSnapshot returns the first page of the `find` cursor in a new transaction
+// that imports the given snapshot.
+func (p *Pool) findInSnapshot(ctx context.Context, snapshot string, q SnapshotQuery) (SnapshotCursor, error) {
+	poolConn, err := p.Acquire()
+	if err != nil {
+		return SnapshotCursor{}, lazyerrors.Error(err)
+	}
+	defer poolConn.Release()
+
+	// the transaction should live as long as the cursor
+	conn := poolConn.hijack()
+
+	page, continuation, cursorID, err := findInSnapshotConn(ctx, conn, p.l, snapshot, q)
+	if err != nil || len(continuation) == 0 {
+		_ = conn.Close(ctx)
+		return SnapshotCursor{Page: page, CursorID: cursorID}, err
+	}
+
+	p.l.DebugContext(
+		ctx, "FindSnapshot result",
+		slog.String("snapshot", snapshot), slog.Any("continuation", continuation), slog.Int64("cursor", cursorID),
+	)
+
+	p.r.NewCursor(cursorID, continuation, conn)
+
+	return SnapshotCursor{Page: page, CursorID: cursorID}, nil
+}
+
+// findInSnapshotConn starts a transaction with the given snapshot on conn,
+// and returns the first page, the continuation and the cursor ID of the `find` cursor.
+func findInSnapshotConn(ctx context.Context, conn *pgx.Conn, l *slog.Logger, snapshot string, q SnapshotQuery) (wirebson.RawDocument, wirebson.RawDocument, int64, error) { //nolint:lll // for readability
+	if _, err := conn.Exec(ctx, "BEGIN ISOLATION LEVEL REPEATABLE READ READ ONLY"); err != nil {
+		return nil, nil, 0, lazyerrors.Error(err)
+	}
+
+	// parameters are not supported there
+	sql := "SET TRANSACTION SNAPSHOT '" + strings.ReplaceAll(snapshot, "'", "''") + "'"
+	if _, err := conn.Exec(ctx, sql); err != nil {
+		return nil, nil, 0, lazyerrors.Error(err)
+	}
+
+	page, continuation, _, cursorID, err := documentdb_api.FindCursorFirstPage(ctx, conn, l, q.DB, q.Spec, 0)
+	if err != nil {
+		return nil, nil, 0, lazyerrors.Error(err)
+	}
+
+	return page, continuation, cursorID, nil
+}
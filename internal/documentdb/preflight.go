@@ -0,0 +1,138 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/build/version"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// minPostgreSQLVersionNum is the minimal supported PostgreSQL version in `server_version_num` format.
+const minPostgreSQLVersionNum = 150000
+
+// preflightLibraries are libraries that should be listed in `shared_preload_libraries`.
+var preflightLibraries = []string{"pg_documentdb_core", "pg_documentdb"}
+
+// preflight checks that PostgreSQL server and DocumentDB extension are usable by FerretDB.
+//
+// It returns an error with an actionable message for problems that prevent FerretDB from working,
+// and warnings for problems that only affect some features.
+func preflight(ctx context.Context, conn *pgx.Conn) ([]string, error) {
+	var versionNum int
+	var serverVersion, libraries, encoding string
+	var extVersion *string
+	var icu bool
+
+	q := `SELECT
+		current_setting('server_version_num')::int,
+		current_setting('server_version'),
+		current_setting('shared_preload_libraries'),
+		current_setting('server_encoding'),
+		(SELECT extversion FROM pg_extension WHERE extname = 'documentdb'),
+		EXISTS (SELECT 1 FROM pg_collation WHERE collprovider = 'i')`
+
+	err := conn.QueryRow(ctx, q).Scan(&versionNum, &serverVersion, &libraries, &encoding, &extVersion, &icu)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if versionNum < minPostgreSQLVersionNum {
+		return nil, lazyerrors.Errorf(
+			"PostgreSQL %s is not supported, version %d or later is required",
+			serverVersion, minPostgreSQLVersionNum/10000,
+		)
+	}
+
+	if extVersion == nil {
+		return nil, lazyerrors.Errorf(
+			"DocumentDB extension is not installed in database %q; run `CREATE EXTENSION documentdb CASCADE`",
+			conn.Config().Database,
+		)
+	}
+
+	if encoding != "UTF8" {
+		return nil, lazyerrors.Errorf("PostgreSQL server encoding %s is not supported, UTF8 is required", encoding)
+	}
+
+	var usage, admin bool
+
+	q = `SELECT
+		has_schema_privilege('documentdb_api', 'USAGE'),
+		pg_has_role('documentdb_admin_role', 'MEMBER')`
+
+	if err = conn.QueryRow(ctx, q).Scan(&usage, &admin); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !usage {
+		return nil, lazyerrors.Errorf(
+			"PostgreSQL user %q has no USAGE privilege on documentdb_api schema; "+
+				"run `GRANT documentdb_admin_role TO %q` as a superuser",
+			conn.Config().User, conn.Config().User,
+		)
+	}
+
+	var warnings []string
+
+	loaded := strings.Split(libraries, ",")
+	for i, l := range loaded {
+		loaded[i] = strings.Trim(strings.TrimSpace(l), `"`)
+	}
+
+	// they could be loaded in some other way on managed services, so that's not an error
+	for _, l := range preflightLibraries {
+		if !slices.Contains(loaded, l) {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s library is not listed in shared_preload_libraries. "+
+					"Add %q to it in postgresql.conf and restart PostgreSQL; see %s.",
+				l, strings.Join(preflightLibraries, ","), version.DocumentDBURL,
+			))
+		}
+	}
+
+	if !admin {
+		warnings = append(warnings, fmt.Sprintf(
+			"PostgreSQL user %q is not a member of documentdb_admin_role. "+
+				"Administrative commands such as user management may fail.",
+			conn.Config().User,
+		))
+	}
+
+	if !icu {
+		warnings = append(warnings, "ICU collations are not available in PostgreSQL. Collation support is limited.")
+	}
+
+	enc, err := GetEncryptionStatus(ctx, conn)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if enc.ExtensionVersion != "" && enc.DefaultAccessMethod != encryptedAccessMethod {
+		warnings = append(warnings, fmt.Sprintf(
+			"pg_tde extension is installed, but default_table_access_method is %q. "+
+				"New collections are not encrypted at rest; set it to %q.",
+			enc.DefaultAccessMethod, encryptedAccessMethod,
+		))
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// RecordIDs returns record IDs of the given collection documents
+// derived from physical locations (ctid) of their rows.
+//
+// Documents are specified by their `{"": <_id>}` object IDs;
+// keys of the returned map are the same object IDs.
+// Record IDs are positive, but they change when documents are moved, for example, by updates or VACUUM FULL.
+func RecordIDs(ctx context.Context, conn *pgx.Conn, db, collection string, objectIDs []wirebson.RawDocument) (map[string]int64, error) { //nolint:lll // for readability
+	table, err := collectionTable(ctx, conn, db, collection)
+	if err != nil {
+		if errors.Is(err, ErrCollectionNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	ids := make([][]byte, len(objectIDs))
+	for i, id := range objectIDs {
+		ids[i] = id
+	}
+
+	// block number and offset within the block
+	q := `
+		SELECT object_id::bytea, (ctid::text::point)[0]::bigint * 65536 + (ctid::text::point)[1]::bigint FROM ` + table + `
+		WHERE object_id IN (SELECT documentdb_core.bson_from_bytea(b) FROM unnest($1::bytea[]) AS b)
+	`
+
+	rows, err := conn.Query(ctx, q, ids)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	res := make(map[string]int64, len(objectIDs))
+
+	for rows.Next() {
+		var id []byte
+		var recordID int64
+
+		if err = rows.Scan(&id, &recordID); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res[string(id)] = recordID
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// ShardNode represents a PostgreSQL node that stores shards of distributed collections.
+type ShardNode struct {
+	Group int32  // Citus group ID; 0 for the coordinator
+	Host  string // "host:port"
+}
+
+// ShardNodes returns active primary nodes of the Citus cluster used by DocumentDB for sharded collections,
+// ordered by group ID.
+// It returns nil if Citus is not installed.
+func ShardNodes(ctx context.Context, conn *pgx.Conn) ([]ShardNode, error) {
+	q := `SELECT groupid, nodename, nodeport FROM pg_dist_node WHERE isactive AND noderole = 'primary' ORDER BY groupid`
+
+	rows, err := conn.Query(ctx, q)
+	if err != nil {
+		if isUndefinedTable(err) {
+			return nil, nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer rows.Close()
+
+	var res []ShardNode
+
+	for rows.Next() {
+		var n ShardNode
+		var host string
+		var port int32
+
+		if err = rows.Scan(&n.Group, &host, &port); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		n.Host = net.JoinHostPort(host, strconv.Itoa(int(port)))
+		res = append(res, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		if isUndefinedTable(err) {
+			return nil, nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// isUndefinedTable returns true if err is PostgreSQL error about a missing table.
+func isUndefinedTable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UndefinedTable
+}
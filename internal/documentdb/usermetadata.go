@@ -0,0 +1,109 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package documentdb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// ErrUserNotFound indicates that the user (PostgreSQL role) does not exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// UserMetadata returns metadata of the given kind stored by [UpdateUserMetadata] for the given user.
+// It returns nil if there is none.
+func UserMetadata(ctx context.Context, conn *pgx.Conn, username, kind string) (*wirebson.Document, error) {
+	var comment *string
+
+	q := `SELECT shobj_description(oid, 'pg_authid') FROM pg_roles WHERE rolname = $1`
+	if err := conn.QueryRow(ctx, q, username).Scan(&comment); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	md, err := decodeMetadata(comment)
+	if err != nil || md == nil {
+		return nil, err
+	}
+
+	res, _ := md.Get(kind).(*wirebson.Document)
+
+	return res, nil
+}
+
+// UpdateUserMetadata updates metadata of the given kind stored for the given user
+// in the comment of the PostgreSQL role, the same way as [UpdateMetadata] does for collections.
+func UpdateUserMetadata(ctx context.Context, conn *pgx.Conn, username, kind string, update func(*wirebson.Document) error) error { //nolint:lll // for readability
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, metadataComment+"role "+username); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		var comment *string
+
+		q := `SELECT shobj_description(oid, 'pg_authid') FROM pg_roles WHERE rolname = $1`
+		if err := tx.QueryRow(ctx, q, username).Scan(&comment); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+
+			return lazyerrors.Error(err)
+		}
+
+		md, err := decodeMetadata(comment)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if md == nil {
+			md = wirebson.MakeDocument(1)
+		}
+
+		d, _ := md.Get(kind).(*wirebson.Document)
+		if d == nil {
+			d = wirebson.MakeDocument(0)
+		}
+
+		if err = update(d); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		md.Remove(kind)
+
+		if d.Len() > 0 {
+			must.NoError(md.Add(kind, d))
+		}
+
+		var c string
+		if c, err = encodeMetadata(md); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if _, err = tx.Exec(ctx, `COMMENT ON ROLE `+pgx.Identifier{username}.Sanitize()+` IS `+c); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,780 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// accessPolicyTTL is the time access policies of collections and roles of users are cached for.
+const accessPolicyTTL = 10 * time.Second
+
+// maxAccessPolicyEntries is the maximal number of cached access policies and user roles.
+const maxAccessPolicyEntries = 10000
+
+// maxViewDepth is the maximal depth of views defined on other views, as in MongoDB.
+const maxViewDepth = 20
+
+// accessPolicy represents per-role access policies of the collection.
+type accessPolicy struct {
+	masking   []maskingRule
+	documents []documentRule
+
+	// true for views; rules are merged from all collections the view reads,
+	// and are used only to reject reads
+	view bool
+}
+
+// merge adds rules of the given policy.
+func (p *accessPolicy) merge(other accessPolicy) {
+	p.masking = append(p.masking, other.masking...)
+	p.documents = append(p.documents, other.documents...)
+}
+
+// empty returns true if there are no rules.
+func (p *accessPolicy) empty() bool {
+	return len(p.masking) == 0 && len(p.documents) == 0
+}
+
+// joinedCollection represents a collection read by a join stage.
+type joinedCollection struct {
+	db         string
+	collection string
+}
+
+// accessPolicyEntry represents cached access policy of the collection or roles of the user.
+type accessPolicyEntry struct {
+	policy  accessPolicy
+	roles   []string
+	expires time.Time
+}
+
+// accessPolicyCache caches access policies of collections and roles of users.
+//
+// The zero value is ready to use.
+type accessPolicyCache struct {
+	mu      sync.Mutex
+	entries map[string]accessPolicyEntry // by namespace or "user:" + username
+}
+
+// get returns the cached entry for the given key.
+func (c *accessPolicyCache) get(key string, now time.Time) (accessPolicyEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || now.After(e.expires) {
+		return accessPolicyEntry{}, false
+	}
+
+	return e, true
+}
+
+// set caches the entry for the given key.
+func (c *accessPolicyCache) set(key string, e accessPolicyEntry, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil || len(c.entries) >= maxAccessPolicyEntries {
+		c.entries = make(map[string]accessPolicyEntry)
+	}
+
+	e.expires = now.Add(accessPolicyTTL)
+	c.entries[key] = e
+}
+
+// reset removes all cached entries.
+func (c *accessPolicyCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = nil
+}
+
+// accessPolicy returns access policies of the given collection.
+//
+// For views, it returns rules of all collections they read, directly or through other views.
+func (h *Handler) accessPolicy(ctx context.Context, dbName, collection string) (accessPolicy, error) {
+	ns := dbName + "." + collection
+	now := time.Now()
+
+	if e, ok := h.accessPolicies.get(ns, now); ok {
+		return e.policy, nil
+	}
+
+	res, err := h.collectionAccessPolicy(ctx, dbName, collection)
+	if err != nil {
+		return accessPolicy{}, lazyerrors.Error(err)
+	}
+
+	// views have no metadata of their own
+	if res.empty() {
+		if res, err = h.viewAccessPolicy(ctx, dbName, collection); err != nil {
+			return accessPolicy{}, lazyerrors.Error(err)
+		}
+	}
+
+	h.accessPolicies.set(ns, accessPolicyEntry{policy: res}, now)
+
+	return res, nil
+}
+
+// collectionAccessPolicy returns access policies stored in metadata of the given collection.
+func (h *Handler) collectionAccessPolicy(ctx context.Context, dbName, collection string) (accessPolicy, error) {
+	var masking, documents *wirebson.Document
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		if masking, err = documentdb.Metadata(ctx, conn, dbName, collection, maskingPolicyMetadata); err != nil {
+			return err
+		}
+
+		documents, err = documentdb.Metadata(ctx, conn, dbName, collection, documentPolicyMetadata)
+
+		return err
+	})
+	if err != nil {
+		return accessPolicy{}, lazyerrors.Error(err)
+	}
+
+	var res accessPolicy
+
+	if masking != nil {
+		if res.masking, err = getMaskingRules(maskingPolicyMetadata, masking.Get("rules")); err != nil {
+			return accessPolicy{}, lazyerrors.Error(err)
+		}
+	}
+
+	if documents != nil {
+		if res.documents, err = getDocumentRules(documentPolicyMetadata, documents.Get("rules")); err != nil {
+			return accessPolicy{}, lazyerrors.Error(err)
+		}
+	}
+
+	return res, nil
+}
+
+// viewAccessPolicy returns merged access policies of all collections read by the given view:
+// the collection at the end of the `viewOn` chain and collections joined by pipelines of views in that chain.
+// It returns an empty policy if the given collection is not a view.
+func (h *Handler) viewAccessPolicy(ctx context.Context, dbName, view string) (accessPolicy, error) {
+	var res accessPolicy
+
+	source := view
+
+	for range maxViewDepth {
+		viewOn, pipeline, err := h.viewDefinition(ctx, dbName, source)
+		if err != nil {
+			return accessPolicy{}, lazyerrors.Error(err)
+		}
+
+		if viewOn == "" {
+			break
+		}
+
+		res.view = true
+
+		joined, err := joinedCollections(dbName, pipeline)
+		if err != nil {
+			return accessPolicy{}, lazyerrors.Error(err)
+		}
+
+		for _, c := range joined {
+			var p accessPolicy
+			if p, err = h.accessPolicy(ctx, c.db, c.collection); err != nil {
+				return accessPolicy{}, lazyerrors.Error(err)
+			}
+
+			res.merge(p)
+		}
+
+		source = viewOn
+	}
+
+	if !res.view {
+		return res, nil
+	}
+
+	p, err := h.collectionAccessPolicy(ctx, dbName, source)
+	if err != nil {
+		return accessPolicy{}, lazyerrors.Error(err)
+	}
+
+	res.merge(p)
+
+	return res, nil
+}
+
+// viewDefinition returns the source collection and pipeline of the given view,
+// or an empty string if there is no such view.
+func (h *Handler) viewDefinition(ctx context.Context, dbName, view string) (string, *wirebson.Array, error) {
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument(
+		"listCollections", int32(1),
+		"filter", must.NotFail(wirebson.NewDocument("name", view, "type", "view")),
+		"$db", dbName,
+	)).Encode())
+
+	var page wirebson.RawDocument
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		page, _, _, _, err = documentdb_api.ListCollectionsCursorFirstPage(ctx, conn, h.L, dbName, spec, 0)
+
+		return err
+	})
+	if err != nil {
+		return "", nil, lazyerrors.Error(err)
+	}
+
+	res, err := page.DecodeDeep()
+	if err != nil {
+		return "", nil, lazyerrors.Error(err)
+	}
+
+	cursor, _ := res.Get("cursor").(*wirebson.Document)
+	if cursor == nil {
+		return "", nil, nil
+	}
+
+	batch, _ := cursor.Get("firstBatch").(*wirebson.Array)
+	if batch == nil || batch.Len() == 0 {
+		return "", nil, nil
+	}
+
+	var options *wirebson.Document
+	if info, _ := batch.Get(0).(*wirebson.Document); info != nil {
+		options, _ = info.Get("options").(*wirebson.Document)
+	}
+
+	if options == nil {
+		return "", nil, nil
+	}
+
+	viewOn, _ := options.Get("viewOn").(string)
+	pipeline, _ := options.Get("pipeline").(*wirebson.Array)
+
+	return viewOn, pipeline, nil
+}
+
+// checkViewSource returns an error if the view created or modified by the given command
+// would read collections with access policies.
+//
+// Rules of those collections can't be applied to the view's pipeline,
+// so such views would return all documents and fields.
+func (h *Handler) checkViewSource(ctx context.Context, dbName string, doc *wirebson.Document) error {
+	command := doc.Command()
+
+	var collections []joinedCollection
+
+	if viewOn, _ := doc.Get("viewOn").(string); viewOn != "" {
+		collections = append(collections, joinedCollection{db: dbName, collection: viewOn})
+	}
+
+	pipeline, err := decodeArray(doc.Get("pipeline"))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	joined, err := joinedCollections(dbName, pipeline)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, c := range append(collections, joined...) {
+		p, err := h.accessPolicy(ctx, c.db, c.collection)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if !p.empty() {
+			msg := fmt.Sprintf("View can't read collection %s.%s with access policies", c.db, c.collection)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+		}
+	}
+
+	return nil
+}
+
+// setAccessPolicy stores rules of the given metadata kind for the given collection.
+// Empty rules remove the policy.
+func (h *Handler) setAccessPolicy(ctx context.Context, command, dbName, collection, kind string, rules *wirebson.Array) error {
+	defer h.accessPolicies.reset()
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		return documentdb.UpdateMetadata(ctx, conn, dbName, collection, kind, func(md *wirebson.Document) error {
+			md.Remove("rules")
+
+			if rules.Len() > 0 {
+				must.NoError(md.Add("rules", rules))
+			}
+
+			return nil
+		})
+	})
+
+	switch {
+	case err == nil:
+		h.notifyCluster(ctx, clusterMessage{Type: clusterResetAccessPolicies})
+		return nil
+
+	case errors.Is(err, documentdb.ErrCollectionNotFound):
+		msg := fmt.Sprintf("ns does not exist: %s.%s", dbName, collection)
+		return mongoerrors.NewWithArgument(mongoerrors.ErrNamespaceNotFound, msg, command)
+
+	default:
+		return lazyerrors.Error(err)
+	}
+}
+
+// userRoles returns names of roles granted to the given user.
+func (h *Handler) userRoles(ctx context.Context, username string) ([]string, error) {
+	key := "user:" + username
+	now := time.Now()
+
+	if e, ok := h.accessPolicies.get(key, now); ok {
+		return e.roles, nil
+	}
+
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument("usersInfo", username, "$db", "admin")).Encode())
+
+	var raw wirebson.RawDocument
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		raw, err = documentdb_api.UsersInfo(ctx, conn, h.L, spec)
+
+		return err
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := raw.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var roles []string
+
+	users, _ := res.Get("users").(*wirebson.Array)
+	if users != nil && users.Len() > 0 {
+		user, _ := users.Get(0).(*wirebson.Document)
+
+		if arr, _ := user.Get("roles").(*wirebson.Array); arr != nil {
+			for v := range arr.Values() {
+				if r, _ := v.(*wirebson.Document); r != nil {
+					if name, _ := r.Get("role").(string); name != "" {
+						roles = append(roles, name)
+					}
+				}
+			}
+		}
+	}
+
+	h.accessPolicies.set(key, accessPolicyEntry{roles: roles}, now)
+
+	return roles, nil
+}
+
+// userAccessPolicy returns access policies of the given collection,
+// and the name and roles of the authenticated user.
+// Without authentication, policies are empty.
+func (h *Handler) userAccessPolicy(ctx context.Context, dbName, collection string) (accessPolicy, []string, string, error) { //nolint:lll // for readability
+	username := conninfo.Get(ctx).Conv().Username()
+	if username == "" || collection == "" {
+		return accessPolicy{}, nil, "", nil
+	}
+
+	policy, err := h.accessPolicy(ctx, dbName, collection)
+	if err != nil || (len(policy.masking) == 0 && len(policy.documents) == 0) {
+		return accessPolicy{}, nil, "", err
+	}
+
+	roles, err := h.userRoles(ctx, username)
+	if err != nil {
+		return accessPolicy{}, nil, "", err
+	}
+
+	if policy.view {
+		if masksForRoles(policy.masking, roles) == nil && documentFilterForRoles(policy.documents, roles, username) == nil {
+			return accessPolicy{}, nil, "", nil
+		}
+
+		msg := fmt.Sprintf("View %s.%s reads collections with access policies", dbName, collection)
+
+		return accessPolicy{}, nil, "", mongoerrors.New(mongoerrors.ErrUnauthorized, msg)
+	}
+
+	return policy, roles, username, nil
+}
+
+// joinStages contains aggregation stages reading other collections,
+// and fields of their specifications with collection names.
+var joinStages = map[string]string{
+	"$graphLookup": "from",
+	"$lookup":      "from",
+	"$unionWith":   "coll",
+}
+
+// walkJoins calls the given function for each join stage of the given pipeline,
+// including stages of nested pipelines of join and `$facet` stages,
+// and returns the pipeline with stages replaced by function results.
+//
+// The function gets the stage name and its specification with nested pipeline already walked;
+// `$unionWith` specification is always a document.
+func walkJoins(pipeline *wirebson.Array, f func(name string, spec *wirebson.Document) (any, error)) (*wirebson.Array, error) {
+	res := wirebson.MakeArray(pipeline.Len())
+
+	for v := range pipeline.Values() {
+		stage, err := decodeDocument(v)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if stage == nil || stage.Len() != 1 {
+			must.NoError(res.Add(v))
+			continue
+		}
+
+		name := stage.Command()
+
+		switch _, join := joinStages[name]; {
+		case name == "$facet":
+			var facets *wirebson.Document
+			if facets, err = decodeDocument(stage.Get(name)); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if facets == nil {
+				break
+			}
+
+			walked := wirebson.MakeDocument(facets.Len())
+
+			for field, p := range facets.All() {
+				var sub *wirebson.Array
+				if sub, err = decodeArray(p); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+
+				if sub != nil {
+					if p, err = walkJoins(sub, f); err != nil {
+						return nil, err
+					}
+				}
+
+				must.NoError(walked.Add(field, p))
+			}
+
+			v = must.NotFail(wirebson.NewDocument(name, walked))
+
+		case join:
+			var spec *wirebson.Document
+
+			if coll, ok := stage.Get(name).(string); ok {
+				spec = must.NotFail(wirebson.NewDocument("coll", coll))
+			} else if spec, err = decodeDocument(stage.Get(name)); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			// invalid specifications are rejected by DocumentDB
+			if spec == nil {
+				break
+			}
+
+			var sub *wirebson.Array
+			if sub, err = decodeArray(spec.Get("pipeline")); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if sub != nil {
+				if sub, err = walkJoins(sub, f); err != nil {
+					return nil, err
+				}
+
+				must.NoError(spec.Replace("pipeline", sub))
+			}
+
+			if v, err = f(name, spec); err != nil {
+				return nil, err
+			}
+		}
+
+		must.NoError(res.Add(v))
+	}
+
+	return res, nil
+}
+
+// joinSource returns the database and collection read by the join stage with the given specification.
+// The collection is empty if the stage does not read one (for example, `$lookup` with `$documents`).
+func joinSource(dbName, name string, spec *wirebson.Document) (joinedCollection, error) {
+	res := joinedCollection{db: dbName}
+
+	switch from := spec.Get(joinStages[name]).(type) {
+	case string:
+		res.collection = from
+
+	case wirebson.AnyDocument:
+		d, err := from.Decode()
+		if err != nil {
+			return joinedCollection{}, lazyerrors.Error(err)
+		}
+
+		if db, _ := d.Get("db").(string); db != "" {
+			res.db = db
+		}
+
+		res.collection, _ = d.Get("coll").(string)
+	}
+
+	return res, nil
+}
+
+// joinedCollections returns collections read by join stages of the given pipeline (that may be nil),
+// including stages of nested pipelines.
+func joinedCollections(dbName string, pipeline *wirebson.Array) ([]joinedCollection, error) {
+	if pipeline == nil {
+		return nil, nil
+	}
+
+	var res []joinedCollection
+
+	_, err := walkJoins(pipeline, func(name string, spec *wirebson.Document) (any, error) {
+		c, err := joinSource(dbName, name, spec)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.collection != "" {
+			res = append(res, c)
+		}
+
+		return spec, nil
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// statsStages contains aggregation stages that must be the first stage of the pipeline
+// and do not return documents.
+var statsStages = []string{"$collStats", "$indexStats"}
+
+// insertStage inserts the given stage before the first stage of the pipeline that returns documents
+// (after `$geoNear` that should be the first one).
+// Pipelines starting with stages returning statistics are returned as is.
+//
+// Stages inserted later are placed before stages inserted earlier.
+func insertStage(pipeline *wirebson.Array, stage *wirebson.Document) (*wirebson.Array, error) {
+	var first string
+
+	if pipeline.Len() > 0 {
+		if s, _ := pipeline.Get(0).(wirebson.AnyDocument); s != nil {
+			d, err := s.Decode()
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			first = d.Command()
+		}
+	}
+
+	if slices.Contains(statsStages, first) {
+		return pipeline, nil
+	}
+
+	pos := 0
+	if first == "$geoNear" {
+		pos = 1
+	}
+
+	res := wirebson.MakeArray(pipeline.Len() + 1)
+
+	for i, v := range pipeline.All() {
+		if i == pos {
+			must.NoError(res.Add(stage))
+		}
+
+		must.NoError(res.Add(v))
+	}
+
+	if pipeline.Len() == pos {
+		must.NoError(res.Add(stage))
+	}
+
+	return res, nil
+}
+
+// restrictPipeline returns the given pipeline with documents filtered by the given filter
+// and masked fields removed before the first stage that returns documents.
+// Nil masks and filter are ignored.
+func restrictPipeline(pipeline *wirebson.Array, masks fieldMasks, filter *wirebson.Document) (*wirebson.Array, error) {
+	var err error
+
+	if masks != nil {
+		if pipeline, err = insertStage(pipeline, masks.unsetStage()); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	// filter documents before masked fields are removed
+	if filter != nil {
+		if pipeline, err = insertStage(pipeline, must.NotFail(wirebson.NewDocument("$match", filter))); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return pipeline, nil
+}
+
+// restrictJoin returns the join stage with the given name and specification
+// with access policies of the joined collection for the authenticated user applied.
+//
+// Documents are filtered and masked fields are removed by the stage's pipeline;
+// `$graphLookup` filters documents with `restrictSearchWithMatch` and can't read collections with masked fields.
+func (h *Handler) restrictJoin(ctx context.Context, command, dbName, name string, spec *wirebson.Document) (*wirebson.Document, error) { //nolint:lll // for readability
+	res := must.NotFail(wirebson.NewDocument(name, spec))
+
+	c, err := joinSource(dbName, name, spec)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	policy, roles, username, err := h.userAccessPolicy(ctx, c.db, c.collection)
+	if err != nil {
+		return nil, err
+	}
+
+	masks := masksForRoles(policy.masking, roles)
+	filter := documentFilterForRoles(policy.documents, roles, username)
+
+	if masks == nil && filter == nil {
+		return res, nil
+	}
+
+	if name == "$graphLookup" {
+		if masks != nil {
+			msg := fmt.Sprintf("%s stage can't read collection %s.%s with masked fields", name, c.db, c.collection)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+		}
+
+		andFilter(spec, "restrictSearchWithMatch", filter)
+
+		return res, nil
+	}
+
+	// documents are joined by foreignField before the pipeline is executed
+	if foreignField, _ := spec.Get("foreignField").(string); foreignField != "" {
+		if masked, ok := masks.covers(foreignField); ok {
+			msg := fmt.Sprintf("%s stage can't join on masked field '%s' of %s.%s", name, masked, c.db, c.collection)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+		}
+	}
+
+	pipeline, _ := spec.Get("pipeline").(*wirebson.Array)
+	if pipeline == nil {
+		pipeline = wirebson.MakeArray(2)
+	}
+
+	if err = masks.checkGeoNear(command, pipeline); err != nil {
+		return nil, err
+	}
+
+	if pipeline, err = restrictPipeline(pipeline, masks, filter); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if spec.Get("pipeline") == nil {
+		must.NoError(spec.Add("pipeline", pipeline))
+	} else {
+		must.NoError(spec.Replace("pipeline", pipeline))
+	}
+
+	return res, nil
+}
+
+// applyAccessPolicy returns the given `aggregate` command with access policies of the collection
+// for the authenticated user applied: documents are filtered and masked fields are removed
+// before the first pipeline stage that returns documents.
+// Policies of collections read by join stages (including ones in nested pipelines)
+// are applied to those stages by [Handler.restrictJoin].
+// The spec is returned as is without authentication.
+func (h *Handler) applyAccessPolicy(ctx context.Context, dbName string, spec wirebson.RawDocument, doc *wirebson.Document) (wirebson.RawDocument, error) { //nolint:lll // for readability
+	if conninfo.Get(ctx).Conv().Username() == "" {
+		return spec, nil
+	}
+
+	command := doc.Command()
+	collection, _ := doc.Get(command).(string)
+
+	pipeline, err := decodeArray(doc.Get("pipeline"))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if pipeline == nil {
+		return spec, nil
+	}
+
+	pipeline, err = walkJoins(pipeline, func(name string, spec *wirebson.Document) (any, error) {
+		return h.restrictJoin(ctx, command, dbName, name, spec)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	policy, roles, username, err := h.userAccessPolicy(ctx, dbName, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	masks := masksForRoles(policy.masking, roles)
+	filter := documentFilterForRoles(policy.documents, roles, username)
+
+	if err = masks.checkGeoNear(command, pipeline); err != nil {
+		return nil, err
+	}
+
+	if pipeline, err = restrictPipeline(pipeline, masks, filter); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	must.NoError(doc.Replace("pipeline", pipeline))
+
+	res, err := doc.Encode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
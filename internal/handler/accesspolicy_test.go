@@ -0,0 +1,100 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinedCollections(t *testing.T) {
+	t.Parallel()
+
+	pipeline := wirebson.MustArray(
+		wirebson.MustDocument("$match", wirebson.MustDocument("v", int32(1))),
+		wirebson.MustDocument("$unionWith", "a"),
+		wirebson.MustDocument("$facet", wirebson.MustDocument(
+			"f", wirebson.MustArray(
+				wirebson.MustDocument("$lookup", wirebson.MustDocument(
+					"from", "b",
+					"pipeline", wirebson.MustArray(
+						wirebson.MustDocument("$graphLookup", wirebson.MustDocument("from", "c")),
+					),
+					"as", "x",
+				)),
+			),
+		)),
+		wirebson.MustDocument("$unionWith", wirebson.MustDocument(
+			"coll", wirebson.MustDocument("db", "other", "coll", "d"),
+		)),
+		wirebson.MustDocument("$lookup", wirebson.MustDocument(
+			"pipeline", wirebson.MustArray(wirebson.MustDocument("$documents", wirebson.MakeArray(0))),
+			"as", "y",
+		)),
+	)
+
+	raw, err := pipeline.Encode()
+	require.NoError(t, err)
+
+	decoded, err := raw.Decode()
+	require.NoError(t, err)
+
+	for name, p := range map[string]*wirebson.Array{"Decoded": pipeline, "Raw": decoded} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := joinedCollections("db", p)
+			require.NoError(t, err)
+
+			expected := []joinedCollection{
+				{db: "db", collection: "a"},
+				{db: "db", collection: "c"},
+				{db: "db", collection: "b"},
+				{db: "other", collection: "d"},
+			}
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestRestrictPipeline(t *testing.T) {
+	t.Parallel()
+
+	masks := fieldMasks{"ssn": maskRemove}
+	filter := wirebson.MustDocument("tenant", "alice")
+
+	pipeline := wirebson.MustArray(
+		wirebson.MustDocument("$geoNear", wirebson.MakeDocument(0)),
+		wirebson.MustDocument("$limit", int32(1)),
+	)
+
+	actual, err := restrictPipeline(pipeline, masks, filter)
+	require.NoError(t, err)
+
+	expected := wirebson.MustArray(
+		wirebson.MustDocument("$geoNear", wirebson.MakeDocument(0)),
+		wirebson.MustDocument("$match", filter),
+		wirebson.MustDocument("$unset", wirebson.MustArray("ssn")),
+		wirebson.MustDocument("$limit", int32(1)),
+	)
+	assert.Equal(t, expected.LogMessage(), actual.LogMessage())
+
+	actual, err = restrictPipeline(pipeline, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, pipeline.LogMessage(), actual.LogMessage())
+}
@@ -0,0 +1,236 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// authRestrictionsMetadata is a kind of user metadata with `authenticationRestrictions`
+// set by `createUser` and `updateUser` commands.
+const authRestrictionsMetadata = "authenticationRestrictions"
+
+// authRestriction represents a single element of `authenticationRestrictions`.
+// The user can authenticate if the connection matches all fields of any element.
+type authRestriction struct {
+	clientSource  []netip.Prefix // nil if not set
+	serverAddress []netip.Prefix // nil if not set
+}
+
+// getAuthRestrictionsParam returns the validated `authenticationRestrictions` field of the given command
+// as an array of documents and parsed restrictions.
+func getAuthRestrictionsParam(command string, v any) (*wirebson.Array, []authRestriction, error) {
+	var arr *wirebson.Array
+
+	switch v := v.(type) {
+	case *wirebson.Array:
+		arr = v
+	case wirebson.RawArray:
+		var err error
+		if arr, err = v.DecodeDeep(); err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+	default:
+		msg := fmt.Sprintf(
+			"BSON field '%s.authenticationRestrictions' is the wrong type '%s', expected type 'array'",
+			command, aliasFromType(v),
+		)
+
+		return nil, nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+	}
+
+	res := make([]authRestriction, 0, arr.Len())
+
+	for v := range arr.Values() {
+		d, _ := v.(*wirebson.Document)
+		if d == nil {
+			msg := fmt.Sprintf("BSON field '%s.authenticationRestrictions' must be an array of objects", command)
+			return nil, nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+		}
+
+		var r authRestriction
+
+		for f, fv := range d.All() {
+			prefixes, err := getCIDRs(command, f, fv)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			switch f {
+			case "clientSource":
+				r.clientSource = prefixes
+			case "serverAddress":
+				r.serverAddress = prefixes
+			default:
+				msg := fmt.Sprintf("Unknown field '%s' in authenticationRestrictions", f)
+				return nil, nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+			}
+		}
+
+		res = append(res, r)
+	}
+
+	return arr, res, nil
+}
+
+// getCIDRs returns IP address ranges of the given `authenticationRestrictions` field.
+// Single addresses are accepted too.
+func getCIDRs(command, field string, v any) ([]netip.Prefix, error) {
+	arr, _ := v.(*wirebson.Array)
+	if arr == nil {
+		msg := fmt.Sprintf("authenticationRestrictions.%s must be an array of CIDR strings", field)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+	}
+
+	res := make([]netip.Prefix, 0, arr.Len())
+
+	for e := range arr.Values() {
+		s, _ := e.(string)
+
+		var p netip.Prefix
+		var err error
+
+		if strings.Contains(s, "/") {
+			p, err = netip.ParsePrefix(s)
+		} else {
+			var addr netip.Addr
+			if addr, err = netip.ParseAddr(s); err == nil {
+				p = netip.PrefixFrom(addr, addr.BitLen())
+			}
+		}
+
+		if err != nil {
+			msg := fmt.Sprintf("'%v' is not a valid CIDR range in authenticationRestrictions.%s", e, field)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+		}
+
+		res = append(res, p.Masked())
+	}
+
+	return res, nil
+}
+
+// matchCIDRs returns true if the given address is in any of the given ranges,
+// or if ranges are not set.
+func matchCIDRs(prefixes []netip.Prefix, addr netip.Addr) bool {
+	if prefixes == nil {
+		return true
+	}
+
+	addr = addr.Unmap()
+
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authRestrictionsAllow returns true if the connection with given client and server addresses
+// satisfies given restrictions.
+// Invalid addresses (of Unix domain socket connections) do not match any range.
+func authRestrictionsAllow(restrictions []authRestriction, client, server netip.Addr) bool {
+	if len(restrictions) == 0 {
+		return true
+	}
+
+	for _, r := range restrictions {
+		if matchCIDRs(r.clientSource, client) && matchCIDRs(r.serverAddress, server) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setAuthRestrictions stores `authenticationRestrictions` of the given user.
+// Empty array removes them.
+func setAuthRestrictions(ctx context.Context, conn *pgx.Conn, command, username, dbName string, arr *wirebson.Array) error { //nolint:lll // for readability
+	err := documentdb.UpdateUserMetadata(ctx, conn, username, authRestrictionsMetadata, func(md *wirebson.Document) error {
+		md.Remove("restrictions")
+
+		if arr.Len() > 0 {
+			must.NoError(md.Add("restrictions", arr))
+		}
+
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		return nil
+
+	case errors.Is(err, documentdb.ErrUserNotFound):
+		msg := fmt.Sprintf("Could not find user \"%s\" for db \"%s\"", username, dbName)
+		return mongoerrors.NewWithArgument(mongoerrors.ErrUserNotFound, msg, command)
+
+	default:
+		return lazyerrors.Error(err)
+	}
+}
+
+// authRestrictions returns stored `authenticationRestrictions` of the given user as an array of documents.
+// It returns an empty array if there are none.
+func authRestrictions(ctx context.Context, conn *pgx.Conn, username string) (*wirebson.Array, error) {
+	md, err := documentdb.UserMetadata(ctx, conn, username, authRestrictionsMetadata)
+	if err != nil && !errors.Is(err, documentdb.ErrUserNotFound) {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if md != nil {
+		if arr, _ := md.Get("restrictions").(*wirebson.Array); arr != nil {
+			return arr, nil
+		}
+	}
+
+	return wirebson.MakeArray(0), nil
+}
+
+// checkAuthRestrictions returns true if the user can authenticate
+// from the given client address to the given server address.
+func (h *Handler) checkAuthRestrictions(ctx context.Context, username string, client, server netip.Addr) (bool, error) {
+	var arr *wirebson.Array
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		arr, err = authRestrictions(ctx, conn, username)
+
+		return err
+	})
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	_, restrictions, err := getAuthRestrictionsParam(authRestrictionsMetadata, arr)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return authRestrictionsAllow(restrictions, client, server), nil
+}
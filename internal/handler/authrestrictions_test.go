@@ -0,0 +1,80 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthRestrictions(t *testing.T) {
+	t.Parallel()
+
+	arr := wirebson.MustArray(
+		wirebson.MustDocument("clientSource", wirebson.MustArray("10.0.0.0/8", "192.168.1.5")),
+		wirebson.MustDocument(
+			"clientSource", wirebson.MustArray("2001:db8::/32"),
+			"serverAddress", wirebson.MustArray("2001:db8::1"),
+		),
+	)
+
+	_, restrictions, err := getAuthRestrictionsParam("createUser", arr)
+	require.NoError(t, err)
+	require.Len(t, restrictions, 2)
+
+	server := netip.MustParseAddr("127.0.0.1")
+
+	for name, tc := range map[string]struct {
+		client   string
+		server   netip.Addr
+		expected bool
+	}{
+		"Range":          {client: "10.1.2.3", server: server, expected: true},
+		"Mapped":         {client: "::ffff:10.1.2.3", server: server, expected: true},
+		"Address":        {client: "192.168.1.5", server: server, expected: true},
+		"OtherAddress":   {client: "192.168.1.6", server: server},
+		"Server":         {client: "2001:db8::5", server: netip.MustParseAddr("2001:db8::1"), expected: true},
+		"OtherServer":    {client: "2001:db8::5", server: netip.MustParseAddr("2001:db8::2")},
+		"UnixSocketLike": {client: "", server: netip.Addr{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var client netip.Addr
+			if tc.client != "" {
+				client = netip.MustParseAddr(tc.client)
+			}
+
+			assert.Equal(t, tc.expected, authRestrictionsAllow(restrictions, client, tc.server))
+		})
+	}
+
+	assert.True(t, authRestrictionsAllow(nil, netip.Addr{}, netip.Addr{}))
+
+	for name, v := range map[string]any{
+		"NotArray":     "10.0.0.0/8",
+		"NotDocuments": wirebson.MustArray("10.0.0.0/8"),
+		"BadCIDR":      wirebson.MustArray(wirebson.MustDocument("clientSource", wirebson.MustArray("10.0.0.0/33"))),
+		"BadAddress":   wirebson.MustArray(wirebson.MustDocument("clientSource", wirebson.MustArray("localhost"))),
+		"UnknownField": wirebson.MustArray(wirebson.MustDocument("clientAddress", wirebson.MustArray("10.0.0.1"))),
+	} {
+		_, _, err = getAuthRestrictionsParam("createUser", v)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// batchTargetSize is the size of `getMore` batches FerretDB aims for.
+// It leaves a margin below the maximal reply size, as documents in the next batch could be larger on average.
+const batchTargetSize = int64(maxBsonObjectSize) * 3 / 4
+
+// cursorData contains data FerretDB associates with DocumentDB cursors.
+type cursorData struct {
+	opts *findOptions // nil if documents are returned as is
+
+	// number and total size of returned documents
+	docs  atomic.Int64
+	bytes atomic.Int64
+}
+
+// trackCursor associates data with the new cursor and records its first batch.
+// It does nothing if the cursor was exhausted.
+func (h *Handler) trackCursor(ctx context.Context, cursorID int64, opts *findOptions, page wirebson.AnyDocument) {
+	if cursorID == 0 {
+		return
+	}
+
+	data := &cursorData{opts: opts}
+	if err := data.record(page); err != nil {
+		h.L.WarnContext(ctx, "Failed to record batch size", slog.Int64("cursor", cursorID), logging.Error(err))
+	}
+
+	h.Pool.SetCursorData(cursorID, data)
+}
+
+// record updates statistics with the batch of the given `find`, `aggregate`, or `getMore` response page.
+func (c *cursorData) record(page wirebson.AnyDocument) error {
+	res, err := page.Decode()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	cursor, _ := res.Get("cursor").(wirebson.AnyDocument)
+	if cursor == nil {
+		return nil
+	}
+
+	cd, err := cursor.Decode()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	b, _ := cd.Get("firstBatch").(wirebson.AnyArray)
+	if b == nil {
+		b, _ = cd.Get("nextBatch").(wirebson.AnyArray)
+	}
+
+	if b == nil {
+		return nil
+	}
+
+	raw, err := b.Encode()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	batch, err := raw.Decode()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	c.docs.Add(int64(batch.Len()))
+	c.bytes.Add(int64(len(raw)))
+
+	return nil
+}
+
+// batchSize returns the number of documents for the next `getMore` batch
+// that fits into [batchTargetSize] given the average size of already returned documents.
+// The requested batch size (zero if not set) is never exceeded.
+//
+// It returns zero if the batch size should not be set.
+func (c *cursorData) batchSize(requested int64) int64 {
+	docs, bytes := c.docs.Load(), c.bytes.Load()
+	if docs == 0 || bytes == 0 {
+		return requested
+	}
+
+	n := max(batchTargetSize*docs/bytes, 1)
+
+	if requested > 0 {
+		return min(requested, n)
+	}
+
+	return n
+}
+
+// applyBatchSize sets the `batchSize` field of the given `getMore` command according to [cursorData.batchSize].
+func (c *cursorData) applyBatchSize(spec wirebson.RawDocument) (wirebson.RawDocument, error) {
+	doc, err := spec.Decode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var requested int64
+
+	switch v := doc.Get("batchSize").(type) {
+	case int32:
+		requested = int64(v)
+	case int64:
+		requested = v
+	case float64:
+		requested = int64(v)
+	}
+
+	// invalid values are rejected by DocumentDB
+	if requested < 0 {
+		return spec, nil
+	}
+
+	n := c.batchSize(requested)
+	if n == 0 || n == requested {
+		return spec, nil
+	}
+
+	if doc.Get("batchSize") == nil {
+		must.NoError(doc.Add("batchSize", n))
+	} else {
+		must.NoError(doc.Replace("batchSize", n))
+	}
+
+	if spec, err = doc.Encode(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return spec, nil
+}
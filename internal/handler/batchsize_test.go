@@ -0,0 +1,63 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestCursorDataBatchSize(t *testing.T) {
+	t.Parallel()
+
+	page := func(field string, docSize, n int) wirebson.RawDocument {
+		batch := wirebson.MakeArray(n)
+		for range n {
+			must.NoError(batch.Add(must.NotFail(wirebson.NewDocument("v", strings.Repeat("x", docSize)))))
+		}
+
+		return must.NotFail(must.NotFail(wirebson.NewDocument(
+			"cursor", must.NotFail(wirebson.NewDocument(field, batch, "id", int64(1), "ns", "db.c")),
+			"ok", float64(1),
+		)).Encode())
+	}
+
+	var c cursorData
+	assert.Equal(t, int64(0), c.batchSize(0))
+	assert.Equal(t, int64(10), c.batchSize(10))
+
+	require.NoError(t, c.record(page("firstBatch", 1024*1024, 2)))
+	assert.Equal(t, int64(11), c.batchSize(0))
+	assert.Equal(t, int64(10), c.batchSize(10))
+	assert.Equal(t, int64(11), c.batchSize(1000))
+
+	require.NoError(t, c.record(page("nextBatch", 10, 1000)))
+	assert.Equal(t, int64(5932), c.batchSize(0), "average size decreases")
+
+	var large cursorData
+	require.NoError(t, large.record(page("firstBatch", 15*1024*1024, 1)))
+	assert.Equal(t, int64(1), large.batchSize(101))
+
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument("getMore", int64(1), "collection", "c", "$db", "db")).Encode())
+	spec, err := c.applyBatchSize(spec)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5932), must.NotFail(spec.Decode()).Get("batchSize"))
+}
@@ -0,0 +1,428 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// defaultBatchSize is the default number of documents in the first batch of `find` command.
+const defaultBatchSize = 101
+
+// caseInsensitiveFindFields contains `find` command fields supported by the case-insensitive fast path.
+var caseInsensitiveFindFields = []string{
+	"find", "filter", "collation", "limit", "singleBatch", "batchSize", "comment",
+	"$db", "lsid", "$clusterTime", "$readPreference",
+}
+
+// caseInsensitiveExcludedLanguages contains languages of collation locales
+// where case mapping of ASCII letters differs from the default one (dotted and dotless i).
+var caseInsensitiveExcludedLanguages = []string{"az", "tr"}
+
+// caseInsensitiveQuery represents a case-insensitive lookup on a single top-level field.
+type caseInsensitiveQuery struct {
+	field  string
+	value  string
+	prefix bool
+}
+
+// matches returns true if the given document matches the query.
+//
+// The second returned value is false if that can't be determined by FerretDB,
+// and the query should be handled by DocumentDB.
+// That's the case for array and regular expression values that DocumentDB matches differently,
+// and for strings with characters other than printable ASCII,
+// as collation and case-insensitive regular expressions handle them differently than lowercasing.
+func (q *caseInsensitiveQuery) matches(doc wirebson.RawDocument) (bool, bool) {
+	d, err := doc.Decode()
+	if err != nil {
+		return false, false
+	}
+
+	switch v := d.Get(q.field).(type) {
+	case string:
+		if !isPrintableASCII(v) {
+			return false, false
+		}
+
+		s, value := strings.ToLower(v), strings.ToLower(q.value)
+
+		if q.prefix {
+			return strings.HasPrefix(s, value), true
+		}
+
+		return s == value, true
+
+	case wirebson.AnyArray, wirebson.Regex:
+		return false, false
+
+	default:
+		// missing fields and other types never match strings
+		return false, true
+	}
+}
+
+// isPrintableASCII returns true if the given string contains only printable ASCII characters.
+func isPrintableASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] < ' ' || s[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isCaseInsensitiveCollation returns true if the given collation ignores case, but not diacritics.
+func isCaseInsensitiveCollation(v any) bool {
+	collation, _ := v.(*wirebson.Document)
+	if collation == nil {
+		return false
+	}
+
+	for f, v := range collation.All() {
+		switch f {
+		case "locale":
+			l, ok := v.(string)
+			if !ok || l == "simple" {
+				return false
+			}
+
+			if lang, _, _ := strings.Cut(l, "_"); slices.Contains(caseInsensitiveExcludedLanguages, lang) {
+				return false
+			}
+		case "strength":
+			if s, ok := v.(int32); !ok || s != 2 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return collation.Get("locale") != nil && collation.Get("strength") != nil
+}
+
+// caseInsensitiveField returns the indexed top-level field
+// if the given `listIndexes` specification is a single-field index with case-insensitive collation.
+func caseInsensitiveField(index *wirebson.Document) (string, bool) {
+	if !isCaseInsensitiveCollation(index.Get("collation")) {
+		return "", false
+	}
+
+	key, _ := index.Get("key").(*wirebson.Document)
+	if key == nil || key.Len() != 1 {
+		return "", false
+	}
+
+	field := key.FieldNames()[0]
+	if field == "_id" || strings.ContainsAny(field, ".$") {
+		return "", false
+	}
+
+	switch key.Get(field).(type) {
+	case int32, int64, float64:
+		return field, true
+	default:
+		return "", false
+	}
+}
+
+// caseInsensitiveRegexPrefix returns the literal prefix of anchored case-insensitive regular expression.
+func caseInsensitiveRegexPrefix(v any) (string, bool) {
+	var re wirebson.Regex
+
+	switch v := v.(type) {
+	case wirebson.Regex:
+		re = v
+	case *wirebson.Document:
+		if v.Len() != 2 {
+			return "", false
+		}
+
+		pattern, ok := v.Get("$regex").(string)
+		if !ok {
+			return "", false
+		}
+
+		options, ok := v.Get("$options").(string)
+		if !ok {
+			return "", false
+		}
+
+		re = wirebson.Regex{Pattern: pattern, Options: options}
+	default:
+		return "", false
+	}
+
+	prefix, ok := strings.CutPrefix(re.Pattern, "^")
+	if !ok || prefix == "" || re.Options != "i" || strings.ContainsAny(prefix, `\.+*?()|[]{}^$`) {
+		return "", false
+	}
+
+	return prefix, true
+}
+
+// caseInsensitiveFind returns the query of the given `find` command
+// if it is an equality with case-insensitive collation or a case-insensitive prefix regex on a single top-level field,
+// with a value containing only printable ASCII characters.
+// It also returns the number of documents to fetch and whether more documents require a cursor.
+func caseInsensitiveFind(doc *wirebson.Document) (*caseInsensitiveQuery, int64, bool) {
+	for f := range doc.Fields() {
+		if !slices.Contains(caseInsensitiveFindFields, f) {
+			return nil, 0, false
+		}
+	}
+
+	filter, _ := doc.Get("filter").(*wirebson.Document)
+	if filter == nil || filter.Len() != 1 {
+		return nil, 0, false
+	}
+
+	q := &caseInsensitiveQuery{field: filter.FieldNames()[0]}
+	if q.field == "_id" || strings.ContainsAny(q.field, ".$") {
+		return nil, 0, false
+	}
+
+	var ok bool
+
+	if q.value, ok = filter.Get(q.field).(string); ok {
+		if !isCaseInsensitiveCollation(doc.Get("collation")) {
+			return nil, 0, false
+		}
+	} else {
+		if doc.Get("collation") != nil {
+			return nil, 0, false
+		}
+
+		if q.value, ok = caseInsensitiveRegexPrefix(filter.Get(q.field)); !ok {
+			return nil, 0, false
+		}
+
+		q.prefix = true
+	}
+
+	if !isPrintableASCII(q.value) {
+		return nil, 0, false
+	}
+
+	batchSize := int64(defaultBatchSize)
+	if v := doc.Get("batchSize"); v != nil {
+		n, ok := v.(int32)
+		if !ok || n <= 0 {
+			return nil, 0, false
+		}
+
+		batchSize = int64(n)
+	}
+
+	var limit int64
+
+	switch v := doc.Get("limit").(type) {
+	case nil:
+	case int32:
+		limit = int64(v)
+	case int64:
+		limit = v
+	default:
+		return nil, 0, false
+	}
+
+	singleBatch, _ := doc.Get("singleBatch").(bool)
+
+	if limit < 0 {
+		limit = -limit
+		singleBatch = true
+	}
+
+	switch {
+	case limit > 0 && limit <= batchSize:
+		return q, limit, false
+	case singleBatch:
+		return q, batchSize, false
+	default:
+		return q, batchSize, true
+	}
+}
+
+// findCaseInsensitive executes the given `find` command using the case-insensitive expression index
+// or in-handler filtering, if the cost model prefers them.
+// It returns false if the command should be handled by DocumentDB.
+//
+// Only results that fit into the first batch are returned that way, without creating a cursor.
+func (h *Handler) findCaseInsensitive(ctx context.Context, dbName string, doc *wirebson.Document) (*wirebson.Document, bool, error) { //nolint:lll // for readability
+	collection, ok := doc.Get("find").(string)
+	if !ok {
+		return nil, false, nil
+	}
+
+	q, n, cursor := caseInsensitiveFind(doc)
+	if q == nil {
+		return nil, false, nil
+	}
+
+	plan, err := h.caseInsensitivePlan(ctx, dbName, collection, q, n, cursor)
+	if err != nil {
+		return nil, false, lazyerrors.Error(err)
+	}
+
+	var limit int64
+
+	switch plan.strategy {
+	case strategyIndexScan:
+		limit = n
+		if cursor {
+			// fetch one more document to check if the cursor is needed
+			limit++
+		}
+
+	case strategyHandlerFilter:
+		limit = handlerFilterLimit
+
+	default:
+		return nil, false, nil
+	}
+
+	var docs []wirebson.RawDocument
+
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+
+		if plan.strategy == strategyIndexScan {
+			docs, err = documentdb.FindCaseInsensitive(ctx, conn, dbName, collection, q.field, q.value, q.prefix, limit)
+		} else {
+			docs, err = documentdb.ScanDocuments(ctx, conn, dbName, collection, limit)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, false, lazyerrors.Error(err)
+	}
+
+	// matched documents could be skipped by LIMIT, or statistics could be outdated
+	if int64(len(docs)) == limit && (plan.strategy == strategyHandlerFilter || cursor) {
+		return nil, false, nil
+	}
+
+	batch := wirebson.MakeArray(min(len(docs), int(n)))
+
+	for _, d := range docs {
+		matched, ok := q.matches(d)
+		if !ok {
+			return nil, false, nil
+		}
+
+		if !matched {
+			if plan.strategy == strategyIndexScan && int64(len(docs)) == limit {
+				return nil, false, nil
+			}
+
+			continue
+		}
+
+		if int64(batch.Len()) == n {
+			if cursor {
+				return nil, false, nil
+			}
+
+			break
+		}
+
+		must.NoError(batch.Add(d))
+	}
+
+	return must.NotFail(wirebson.NewDocument(
+		"cursor", must.NotFail(wirebson.NewDocument(
+			"firstBatch", batch,
+			"id", int64(0),
+			"ns", dbName+"."+collection,
+		)),
+		"ok", float64(1),
+	)), true, nil
+}
+
+// syncCaseInsensitiveIndexes creates and drops case-insensitive expression indexes of the collection
+// to match indexes with case-insensitive collation.
+// Indexes are created only if create is true.
+//
+// Errors are logged, as those indexes are only used as an optimization.
+func (h *Handler) syncCaseInsensitiveIndexes(ctx context.Context, dbName, collection string, create bool) {
+	ns := dbName + "." + collection
+	defer h.queryCosts.invalidate(ns)
+
+	l := h.L.With(slog.String("ns", ns))
+
+	indexes, err := h.listIndexSpecs(ctx, dbName, collection)
+	if err != nil {
+		l.WarnContext(ctx, "Failed to list indexes", logging.Error(err))
+		return
+	}
+
+	var want []string
+
+	for _, index := range indexes {
+		if field, ok := caseInsensitiveField(index); ok {
+			want = append(want, field)
+		}
+	}
+
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		have, err := documentdb.CaseInsensitiveIndexes(ctx, conn, dbName, collection)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		for _, field := range have {
+			if slices.Contains(want, field) {
+				continue
+			}
+
+			if err = documentdb.DropCaseInsensitiveIndex(ctx, conn, dbName, collection, field); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		if !create {
+			return nil
+		}
+
+		for _, field := range want {
+			if slices.Contains(have, field) {
+				continue
+			}
+
+			if err = documentdb.CreateCaseInsensitiveIndex(ctx, conn, dbName, collection, field); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		l.WarnContext(ctx, "Failed to sync case-insensitive indexes", logging.Error(err))
+	}
+}
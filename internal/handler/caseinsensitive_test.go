@@ -0,0 +1,201 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestCaseInsensitiveFind(t *testing.T) {
+	t.Parallel()
+
+	collation := must.NotFail(wirebson.NewDocument("locale", "en", "strength", int32(2)))
+	eq := must.NotFail(wirebson.NewDocument("email", "Foo@Example.com"))
+
+	for name, tc := range map[string]struct {
+		doc      *wirebson.Document
+		expected *caseInsensitiveQuery
+		limit    int64
+		cursor   bool
+	}{
+		"Equality": {
+			doc:      must.NotFail(wirebson.NewDocument("find", "c", "filter", eq, "collation", collation, "$db", "db")),
+			expected: &caseInsensitiveQuery{field: "email", value: "Foo@Example.com"},
+			limit:    101,
+			cursor:   true,
+		},
+		"EqualityLimit": {
+			doc: must.NotFail(wirebson.NewDocument(
+				"find", "c", "filter", eq, "limit", int64(1), "singleBatch", true, "collation", collation, "$db", "db",
+			)),
+			expected: &caseInsensitiveQuery{field: "email", value: "Foo@Example.com"},
+			limit:    1,
+		},
+		"EqualityNoCollation": {
+			doc: must.NotFail(wirebson.NewDocument("find", "c", "filter", eq, "$db", "db")),
+		},
+		"EqualityStrength1": {
+			doc: must.NotFail(wirebson.NewDocument(
+				"find", "c", "filter", eq,
+				"collation", must.NotFail(wirebson.NewDocument("locale", "en", "strength", int32(1))),
+				"$db", "db",
+			)),
+		},
+		"Prefix": {
+			doc: must.NotFail(wirebson.NewDocument(
+				"find", "c",
+				"filter", must.NotFail(wirebson.NewDocument("email", wirebson.Regex{Pattern: "^foo", Options: "i"})),
+				"batchSize", int32(10),
+				"$db", "db",
+			)),
+			expected: &caseInsensitiveQuery{field: "email", value: "foo", prefix: true},
+			limit:    10,
+			cursor:   true,
+		},
+		"PrefixOperator": {
+			doc: must.NotFail(wirebson.NewDocument(
+				"find", "c",
+				"filter", must.NotFail(wirebson.NewDocument("email", must.NotFail(wirebson.NewDocument(
+					"$regex", "^foo", "$options", "i",
+				)))),
+				"limit", int32(-5),
+				"$db", "db",
+			)),
+			expected: &caseInsensitiveQuery{field: "email", value: "foo", prefix: true},
+			limit:    5,
+		},
+		"PrefixNotLiteral": {
+			doc: must.NotFail(wirebson.NewDocument(
+				"find", "c",
+				"filter", must.NotFail(wirebson.NewDocument("email", wirebson.Regex{Pattern: "^fo.", Options: "i"})),
+				"$db", "db",
+			)),
+		},
+		"PrefixCaseSensitive": {
+			doc: must.NotFail(wirebson.NewDocument(
+				"find", "c",
+				"filter", must.NotFail(wirebson.NewDocument("email", wirebson.Regex{Pattern: "^foo"})),
+				"$db", "db",
+			)),
+		},
+		"Dotted": {
+			doc: must.NotFail(wirebson.NewDocument(
+				"find", "c",
+				"filter", must.NotFail(wirebson.NewDocument("a.email", "foo")),
+				"collation", collation,
+				"$db", "db",
+			)),
+		},
+		"EqualityNotASCII": {
+			doc: must.NotFail(wirebson.NewDocument(
+				"find", "c",
+				"filter", must.NotFail(wirebson.NewDocument("email", "straße@example.com")),
+				"collation", collation,
+				"$db", "db",
+			)),
+		},
+		"EqualityTurkish": {
+			doc: must.NotFail(wirebson.NewDocument(
+				"find", "c", "filter", eq,
+				"collation", must.NotFail(wirebson.NewDocument("locale", "tr", "strength", int32(2))),
+				"$db", "db",
+			)),
+		},
+		"Sort": {
+			doc: must.NotFail(wirebson.NewDocument(
+				"find", "c", "filter", eq, "sort", must.NotFail(wirebson.NewDocument("email", int32(1))),
+				"collation", collation, "$db", "db",
+			)),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			q, limit, cursor := caseInsensitiveFind(tc.doc)
+			assert.Equal(t, tc.expected, q)
+			assert.Equal(t, tc.limit, limit)
+			assert.Equal(t, tc.cursor, cursor)
+		})
+	}
+}
+
+func TestCaseInsensitiveQueryMatches(t *testing.T) {
+	t.Parallel()
+
+	eq := &caseInsensitiveQuery{field: "v", value: "Foo"}
+	prefix := &caseInsensitiveQuery{field: "v", value: "fo", prefix: true}
+
+	for name, tc := range map[string]struct {
+		v       any // nil for missing field
+		eq      bool
+		prefix  bool
+		unknown bool
+	}{
+		"Equal": {
+			v:      "fOO",
+			eq:     true,
+			prefix: true,
+		},
+		"Prefix": {
+			v:      "FOObar",
+			prefix: true,
+		},
+		"Other": {
+			v: "bar",
+		},
+		"Missing": {},
+		"Int": {
+			v: int32(42),
+		},
+		"Array": {
+			v:       wirebson.MustArray("foo"),
+			unknown: true,
+		},
+		"Regex": {
+			v:       wirebson.Regex{Pattern: "foo"},
+			unknown: true,
+		},
+		"NotASCII": {
+			v:       "ｆｏｏ",
+			unknown: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := wirebson.MakeDocument(1)
+			if tc.v != nil {
+				must.NoError(doc.Add("v", tc.v))
+			}
+
+			raw, err := doc.Encode()
+			require.NoError(t, err)
+
+			matched, ok := eq.matches(raw)
+			assert.Equal(t, !tc.unknown, ok)
+			assert.Equal(t, tc.eq, matched)
+
+			matched, ok = prefix.matches(raw)
+			assert.Equal(t, !tc.unknown, ok)
+			assert.Equal(t, tc.prefix, matched)
+		})
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/session"
+	"github.com/FerretDB/FerretDB/v2/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// clusterChannel is the PostgreSQL channel used for coordination of FerretDB instances.
+const clusterChannel = "ferretdb_cluster"
+
+// clusterMaxSkew is the maximal allowed difference between the time of the message and the current time.
+// Older messages are rejected, and nonces of newer ones are remembered to reject replayed messages.
+const clusterMaxSkew = time.Minute
+
+// clusterMaxPayload is the maximal size of the encoded message.
+// PostgreSQL limits notification payloads to 8000 bytes.
+const clusterMaxPayload = 7900
+
+// Cluster message types.
+const (
+	clusterResetAccessPolicies = "resetAccessPolicies"
+	clusterKillSessions        = "killSessions"
+	clusterEndSessions         = "endSessions"
+	clusterKillCursors         = "killCursors"
+
+	clusterInvalidateResultCache = "invalidateResultCache"
+)
+
+// clusterMessage represents a message sent between FerretDB instances.
+type clusterMessage struct {
+	Instance string `json:"instance"`
+	Type     string `json:"type"`
+	Time     int64  `json:"time"`  // Unix milliseconds
+	Nonce    string `json:"nonce"` // unique for each message
+
+	// All is true when all sessions should be killed.
+	All        bool             `json:"all,omitempty"`
+	UserIDs    []session.UserID `json:"userIDs,omitempty"`
+	SessionIDs []uuid.UUID      `json:"sessionIDs,omitempty"`
+	CursorIDs  []int64          `json:"cursorIDs,omitempty"`
+	Databases  []string         `json:"databases,omitempty"`
+
+	// MAC is HMAC-SHA256 of the message without that field, computed with the shared key.
+	MAC []byte `json:"mac,omitempty"`
+}
+
+// clusterMAC returns HMAC-SHA256 of the given message without MAC field.
+func clusterMAC(key []byte, msg clusterMessage) []byte {
+	msg.MAC = nil
+
+	b := must.NotFail(json.Marshal(msg))
+
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(b)
+
+	return mac.Sum(nil)
+}
+
+// encodeClusterMessage signs and encodes the given message.
+func encodeClusterMessage(key []byte, msg clusterMessage) (string, error) {
+	msg.MAC = clusterMAC(key, msg)
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	return string(b), nil
+}
+
+// encodeClusterMessages assigns nonces, signs, and encodes the given message.
+// Lists of users, sessions, cursors, and databases are split between several messages
+// if the payload does not fit into [clusterMaxPayload].
+func encodeClusterMessages(key []byte, msg clusterMessage) ([]string, error) {
+	msg.Nonce = uuid.NewString()
+
+	payload, err := encodeClusterMessage(key, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) <= clusterMaxPayload {
+		return []string{payload}, nil
+	}
+
+	first, second, ok := splitClusterMessage(msg)
+	if !ok {
+		return nil, lazyerrors.Errorf("message is too large: %d bytes", len(payload))
+	}
+
+	res, err := encodeClusterMessages(key, first)
+	if err != nil {
+		return nil, err
+	}
+
+	rest, err := encodeClusterMessages(key, second)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(res, rest...), nil
+}
+
+// splitClusterMessage splits the longest list of the given message in halves.
+// It returns false if there is nothing to split.
+//
+// Session IDs are split first, as `killSessions` messages with them should have a single user ID.
+func splitClusterMessage(msg clusterMessage) (clusterMessage, clusterMessage, bool) {
+	first, second := msg, msg
+
+	switch l := max(len(msg.UserIDs), len(msg.SessionIDs), len(msg.CursorIDs), len(msg.Databases)); {
+	case l < 2:
+		return msg, msg, false
+
+	case l == len(msg.SessionIDs):
+		first.SessionIDs, second.SessionIDs = msg.SessionIDs[:l/2], msg.SessionIDs[l/2:]
+
+	case l == len(msg.UserIDs):
+		first.UserIDs, second.UserIDs = msg.UserIDs[:l/2], msg.UserIDs[l/2:]
+
+	case l == len(msg.CursorIDs):
+		first.CursorIDs, second.CursorIDs = msg.CursorIDs[:l/2], msg.CursorIDs[l/2:]
+
+	default:
+		first.Databases, second.Databases = msg.Databases[:l/2], msg.Databases[l/2:]
+	}
+
+	return first, second, true
+}
+
+// decodeClusterMessage decodes the given payload and verifies its signature and time.
+func decodeClusterMessage(key []byte, payload string, now time.Time) (*clusterMessage, error) {
+	var msg clusterMessage
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !hmac.Equal(msg.MAC, clusterMAC(key, msg)) {
+		return nil, lazyerrors.New("invalid message signature")
+	}
+
+	if d := now.Sub(time.UnixMilli(msg.Time)).Abs(); d > clusterMaxSkew {
+		return nil, lazyerrors.Errorf("message time differs by %s", d)
+	}
+
+	if msg.Nonce == "" {
+		return nil, lazyerrors.New("message nonce is missing")
+	}
+
+	return &msg, nil
+}
+
+// clusterNonces tracks nonces of received messages to reject replayed ones.
+//
+// The zero value is ready to use.
+type clusterNonces struct {
+	mu sync.Mutex
+	m  map[string]time.Time // nonce -> message time
+}
+
+// add remembers the nonce of the given message received at the given time.
+// It returns false if that nonce was already received.
+//
+// Nonces are forgotten when messages with them are rejected by the time check anyway.
+func (cn *clusterNonces) add(msg *clusterMessage, now time.Time) bool {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+
+	for nonce, t := range cn.m {
+		if now.Sub(t) > clusterMaxSkew {
+			delete(cn.m, nonce)
+		}
+	}
+
+	if _, ok := cn.m[msg.Nonce]; ok {
+		return false
+	}
+
+	if cn.m == nil {
+		cn.m = make(map[string]time.Time)
+	}
+
+	cn.m[msg.Nonce] = time.UnixMilli(msg.Time)
+
+	return true
+}
+
+// notifyCluster sends the given message to other FerretDB instances.
+// Large messages are sent in several notifications.
+// It does nothing if the coordination is disabled.
+// Errors are logged, not returned, as the local operation already succeeded.
+func (h *Handler) notifyCluster(ctx context.Context, msg clusterMessage) {
+	if len(h.ClusterKey) == 0 {
+		return
+	}
+
+	msg.Instance = h.instanceID
+	msg.Time = time.Now().UnixMilli()
+
+	payloads, err := encodeClusterMessages(h.ClusterKey, msg)
+
+	for _, payload := range payloads {
+		if err = h.Pool.Notify(ctx, clusterChannel, payload); err != nil {
+			break
+		}
+	}
+
+	if err != nil {
+		h.L.WarnContext(ctx, "Failed to notify other instances", slog.String("type", msg.Type), logging.Error(err))
+	}
+}
+
+// killRemoteSessions deletes shared cursors of sessions killed by this instance
+// and kills those sessions on other instances.
+// The message describes sessions the same way as for [clusterKillSessions] type.
+func (h *Handler) killRemoteSessions(ctx context.Context, msg clusterMessage) {
+	msg.Type = clusterKillSessions
+
+	h.deleteSessionSharedCursors(ctx, &msg)
+	h.notifyCluster(ctx, msg)
+}
+
+// runCluster receives messages from other FerretDB instances until ctx is canceled.
+//
+// Messages sent while this instance is not listening are lost,
+// so state they could invalidate is reset when listening fails and when it starts again.
+func (h *Handler) runCluster(ctx context.Context) {
+	var attempt int64
+
+	for ctx.Err() == nil {
+		err := h.Pool.Listen(ctx, clusterChannel, h.resetClusterState, func(payload string) {
+			attempt = 0
+			h.handleClusterMessage(ctx, payload)
+		})
+		if err == nil {
+			return
+		}
+
+		h.resetClusterState()
+
+		h.L.WarnContext(ctx, "Failed to listen for messages from other instances", logging.Error(err))
+
+		attempt++
+		ctxutil.SleepWithJitter(ctx, 30*time.Second, attempt)
+	}
+}
+
+// resetClusterState drops cached results and access policies
+// that could be invalidated by messages from other instances.
+func (h *Handler) resetClusterState() {
+	h.resultCache.invalidateAll()
+	h.accessPolicies.reset()
+}
+
+// handleClusterMessage verifies and applies a message received from another FerretDB instance.
+func (h *Handler) handleClusterMessage(ctx context.Context, payload string) {
+	now := time.Now()
+
+	msg, err := decodeClusterMessage(h.ClusterKey, payload, now)
+	if err != nil {
+		h.L.WarnContext(ctx, "Rejected message from another instance", logging.Error(err))
+		return
+	}
+
+	if !h.clusterNonces.add(msg, now) {
+		h.L.WarnContext(ctx, "Rejected replayed message from another instance", slog.String("type", msg.Type))
+		return
+	}
+
+	if msg.Instance == h.instanceID {
+		return
+	}
+
+	h.L.DebugContext(
+		ctx, "Received message from another instance",
+		slog.String("instance", msg.Instance), slog.String("type", msg.Type),
+	)
+
+	var cursorIDs []int64
+
+	switch msg.Type {
+	case clusterResetAccessPolicies:
+		h.accessPolicies.reset()
+
+	case clusterKillSessions:
+		switch {
+		case msg.All:
+			cursorIDs = h.s.DeleteAllSessions()
+		case len(msg.SessionIDs) > 0 && len(msg.UserIDs) == 1:
+			cursorIDs = h.s.DeleteSessionsByIDs(msg.UserIDs[0], msg.SessionIDs)
+		default:
+			cursorIDs = h.s.DeleteSessionsByUserIDs(msg.UserIDs)
+		}
+
+	case clusterEndSessions:
+		for _, userID := range msg.UserIDs {
+			h.s.EndUserSessions(userID, msg.SessionIDs)
+		}
+
+	case clusterKillCursors:
+		cursorIDs = msg.CursorIDs
+
+	case clusterInvalidateResultCache:
+		if msg.All {
+			h.resultCache.invalidateAll()
+		} else {
+			h.resultCache.invalidate(msg.Databases...)
+		}
+
+	default:
+		h.L.WarnContext(ctx, "Unknown message type from another instance", slog.String("type", msg.Type))
+	}
+
+	for _, cursorID := range cursorIDs {
+		_ = h.Pool.KillCursor(ctx, cursorID)
+	}
+}
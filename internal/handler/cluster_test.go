@@ -0,0 +1,115 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/session"
+)
+
+func TestClusterMessage(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+	now := time.Now()
+
+	msg := clusterMessage{
+		Instance:   "instance",
+		Type:       clusterKillSessions,
+		Time:       now.UnixMilli(),
+		Nonce:      uuid.NewString(),
+		UserIDs:    []session.UserID{session.GetUIDFromUsername("admin", "user")},
+		SessionIDs: []uuid.UUID{uuid.New()},
+	}
+
+	payload, err := encodeClusterMessage(key, msg)
+	require.NoError(t, err)
+
+	actual, err := decodeClusterMessage(key, payload, now)
+	require.NoError(t, err)
+	assert.Equal(t, msg.UserIDs, actual.UserIDs)
+	assert.Equal(t, msg.SessionIDs, actual.SessionIDs)
+
+	_, err = decodeClusterMessage([]byte("other"), payload, now)
+	assert.Error(t, err)
+
+	_, err = decodeClusterMessage(key, payload, now.Add(2*clusterMaxSkew))
+	assert.Error(t, err)
+
+	tampered := strings.Replace(payload, clusterKillSessions, clusterEndSessions, 1)
+	_, err = decodeClusterMessage(key, tampered, now)
+	assert.Error(t, err)
+
+	var nonces clusterNonces
+	assert.True(t, nonces.add(actual, now))
+	assert.False(t, nonces.add(actual, now.Add(clusterMaxSkew/2)), "replayed message is rejected")
+
+	other := *actual
+	other.Nonce = uuid.NewString()
+	assert.True(t, nonces.add(&other, now))
+
+	// nonces of messages rejected by the time check are forgotten
+	nonces.add(&clusterMessage{Time: now.UnixMilli(), Nonce: uuid.NewString()}, now.Add(2*clusterMaxSkew))
+	assert.Len(t, nonces.m, 1)
+}
+
+func TestClusterMessageSplit(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+	now := time.Now()
+
+	msg := clusterMessage{
+		Instance: "instance",
+		Type:     clusterKillCursors,
+		Time:     now.UnixMilli(),
+	}
+
+	for i := range 5000 {
+		msg.CursorIDs = append(msg.CursorIDs, int64(i)+1<<40)
+	}
+
+	payloads, err := encodeClusterMessages(key, msg)
+	require.NoError(t, err)
+	require.Greater(t, len(payloads), 1)
+
+	var cursorIDs []int64
+	nonces := make(map[string]struct{})
+
+	for _, payload := range payloads {
+		assert.LessOrEqual(t, len(payload), clusterMaxPayload)
+
+		var actual *clusterMessage
+		actual, err = decodeClusterMessage(key, payload, now)
+		require.NoError(t, err)
+		assert.Equal(t, clusterKillCursors, actual.Type)
+
+		nonces[actual.Nonce] = struct{}{}
+		cursorIDs = append(cursorIDs, actual.CursorIDs...)
+	}
+
+	assert.Equal(t, msg.CursorIDs, cursorIDs)
+	assert.Len(t, nonces, len(payloads), "each message has its own nonce")
+
+	_, err = encodeClusterMessages(key, clusterMessage{Type: clusterKillSessions, Instance: strings.Repeat("x", 8000)})
+	assert.Error(t, err)
+}
@@ -0,0 +1,291 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// defaultCollationMetadata is a kind of collection metadata with the default collation set by `create` command.
+const defaultCollationMetadata = "defaultCollation"
+
+// defaultCollationTTL is the time default collations of collections are cached for.
+const defaultCollationTTL = 10 * time.Second
+
+// maxDefaultCollations is the maximal number of cached default collations.
+const maxDefaultCollations = 10000
+
+// collationStatements contains fields of commands with statements that have their own collation,
+// such as `update` command updates.
+// Other commands have the top-level collation.
+var collationStatements = map[string]string{
+	"createIndexes": "indexes",
+	"delete":        "deletes",
+	"update":        "updates",
+}
+
+// defaultCollationEntry represents a cached default collation of the collection.
+type defaultCollationEntry struct {
+	collation *wirebson.Document // nil if not set
+	expires   time.Time
+}
+
+// defaultCollations caches default collations of collections.
+//
+// The zero value is ready to use.
+type defaultCollations struct {
+	mu      sync.Mutex
+	entries map[string]defaultCollationEntry // by namespace
+}
+
+// get returns the cached default collation for the given namespace.
+func (c *defaultCollations) get(ns string, now time.Time) (*wirebson.Document, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[ns]
+	if !ok || now.After(e.expires) {
+		return nil, false
+	}
+
+	return e.collation, true
+}
+
+// set caches the default collation (possibly nil) for the given namespace.
+func (c *defaultCollations) set(ns string, collation *wirebson.Document, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil || len(c.entries) >= maxDefaultCollations {
+		c.entries = make(map[string]defaultCollationEntry)
+	}
+
+	c.entries[ns] = defaultCollationEntry{collation: collation, expires: now.Add(defaultCollationTTL)}
+}
+
+// reset removes all cached collations.
+// It is called when collections are created or dropped.
+func (c *defaultCollations) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = nil
+}
+
+// getCollationParam returns the validated `collation` option of the given command, or nil if it is not set.
+// It also returns nil for the simple collation.
+func getCollationParam(doc *wirebson.Document, command string) (*wirebson.Document, error) {
+	v := doc.Get("collation")
+	if v == nil {
+		return nil, nil
+	}
+
+	var collation *wirebson.Document
+
+	switch v := v.(type) {
+	case *wirebson.Document:
+		collation = v
+	case wirebson.RawDocument:
+		var err error
+		if collation, err = v.DecodeDeep(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	default:
+		msg := fmt.Sprintf(
+			"BSON field '%s.collation' is the wrong type '%s', expected type 'object'",
+			command, aliasFromType(v),
+		)
+
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+	}
+
+	locale, ok := collation.Get("locale").(string)
+	if !ok {
+		msg := "BSON field 'locale' is missing but a required field"
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrLocation40414, msg, command)
+	}
+
+	if locale == "simple" {
+		return nil, nil
+	}
+
+	return collation, nil
+}
+
+// defaultCollation returns the default collation of the given collection, or nil if it is not set.
+func (h *Handler) defaultCollation(ctx context.Context, dbName, collection string) (*wirebson.Document, error) {
+	ns := dbName + "." + collection
+	now := time.Now()
+
+	if collation, ok := h.defaultCollations.get(ns, now); ok {
+		return collation, nil
+	}
+
+	var collation *wirebson.Document
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		collation, err = documentdb.Metadata(ctx, conn, dbName, collection, defaultCollationMetadata)
+
+		return err
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	h.defaultCollations.set(ns, collation, now)
+
+	return collation, nil
+}
+
+// setDefaultCollation stores the default collation of the given collection in its metadata.
+func (h *Handler) setDefaultCollation(ctx context.Context, conn *pgx.Conn, dbName, collection string, collation *wirebson.Document) error { //nolint:lll // for readability
+	defer h.defaultCollations.reset()
+
+	return documentdb.UpdateMetadata(ctx, conn, dbName, collection, defaultCollationMetadata, func(md *wirebson.Document) error {
+		for _, f := range md.FieldNames() {
+			md.Remove(f)
+		}
+
+		for f, v := range collation.All() {
+			must.NoError(md.Add(f, v))
+		}
+
+		return nil
+	})
+}
+
+// applyDefaultCollation adds the default collation of the collection to the given command
+// (and statements in the given document sequence, if any) without explicit collation.
+// It returns the command and sequence as is if the collection has no default collation.
+func (h *Handler) applyDefaultCollation(ctx context.Context, dbName string, spec wirebson.RawDocument, seq []byte) (wirebson.RawDocument, []byte, error) { //nolint:lll // for readability
+	doc, err := spec.Decode()
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	collection, ok := doc.Get(doc.Command()).(string)
+	if !ok {
+		return spec, seq, nil
+	}
+
+	field, statements := collationStatements[doc.Command()]
+	if !statements && doc.Get("collation") != nil {
+		return spec, seq, nil
+	}
+
+	collation, err := h.defaultCollation(ctx, dbName, collection)
+	if err != nil || collation == nil {
+		return spec, seq, err
+	}
+
+	if !statements {
+		must.NoError(doc.Add("collation", collation))
+
+		if spec, err = doc.Encode(); err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		return spec, seq, nil
+	}
+
+	deep, err := spec.DecodeDeep()
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	if arr, _ := deep.Get(field).(*wirebson.Array); arr != nil {
+		for v := range arr.Values() {
+			if d, ok := v.(*wirebson.Document); ok && d.Get("collation") == nil {
+				must.NoError(d.Add("collation", collation))
+			}
+		}
+
+		if spec, err = deep.Encode(); err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+	}
+
+	seq, err = updateSequence(seq, false, func(d *wirebson.Document) bool {
+		if d.Get("collation") != nil {
+			return false
+		}
+
+		must.NoError(d.Add("collation", collation))
+
+		return true
+	})
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	return spec, seq, nil
+}
+
+// updateSequence calls the given function for each document of the given document sequence,
+// decoded deeply or not.
+// Documents are re-encoded only if the function returns true.
+func updateSequence(seq []byte, deep bool, update func(*wirebson.Document) bool) ([]byte, error) {
+	var res []byte
+
+	for len(seq) > 0 {
+		if len(seq) < 4 {
+			return nil, lazyerrors.New("invalid document sequence")
+		}
+
+		n := int(binary.LittleEndian.Uint32(seq))
+		if n < 5 || n > len(seq) {
+			return nil, lazyerrors.New("invalid document sequence")
+		}
+
+		raw := wirebson.RawDocument(seq[:n])
+		seq = seq[n:]
+
+		var d *wirebson.Document
+		var err error
+
+		if deep {
+			d, err = raw.DecodeDeep()
+		} else {
+			d, err = raw.Decode()
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if update(d) {
+			if raw, err = d.Encode(); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		res = append(res, raw...)
+	}
+
+	return res, nil
+}
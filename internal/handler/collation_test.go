@@ -0,0 +1,110 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestGetCollationParam(t *testing.T) {
+	t.Parallel()
+
+	en := must.NotFail(wirebson.NewDocument("locale", "en", "strength", int32(2)))
+
+	for name, tc := range map[string]struct {
+		collation any // nil if not set
+		expected  *wirebson.Document
+		err       bool
+	}{
+		"NotSet": {},
+		"Locale": {
+			collation: en,
+			expected:  en,
+		},
+		"Simple": {
+			collation: must.NotFail(wirebson.NewDocument("locale", "simple")),
+		},
+		"NoLocale": {
+			collation: must.NotFail(wirebson.NewDocument("strength", int32(2))),
+			err:       true,
+		},
+		"WrongType": {
+			collation: "en",
+			err:       true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(wirebson.NewDocument("create", "c"))
+			if tc.collation != nil {
+				must.NoError(doc.Add("collation", tc.collation))
+			}
+
+			actual, err := getCollationParam(doc, "create")
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tc.expected == nil {
+				assert.Nil(t, actual)
+				return
+			}
+
+			assert.Equal(t, tc.expected.LogMessage(), actual.LogMessage())
+		})
+	}
+}
+
+func TestDefaultCollations(t *testing.T) {
+	t.Parallel()
+
+	var c defaultCollations
+
+	now := time.Now()
+	en := must.NotFail(wirebson.NewDocument("locale", "en"))
+
+	_, ok := c.get("db.c", now)
+	assert.False(t, ok)
+
+	c.set("db.c", en, now)
+	c.set("db.other", nil, now)
+
+	actual, ok := c.get("db.c", now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, en, actual)
+
+	actual, ok = c.get("db.other", now)
+	assert.True(t, ok)
+	assert.Nil(t, actual)
+
+	_, ok = c.get("db.c", now.Add(defaultCollationTTL+time.Second))
+	assert.False(t, ok)
+
+	c.reset()
+
+	_, ok = c.get("db.other", now)
+	assert.False(t, ok)
+}
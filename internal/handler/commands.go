@@ -30,6 +30,13 @@ type command struct {
 	// anonymous indicates that the command does not require authentication.
 	anonymous bool
 
+	// write indicates that the command modifies data or metadata,
+	// so it waits while the fsync lock is held and invalidates the result cache.
+	write bool
+
+	// cached indicates that the command is an idempotent read, so its results could be cached.
+	cached bool
+
 	// handler processes this command.
 	//
 	// The passed context is canceled when the client disconnects.
@@ -46,8 +53,13 @@ type command struct {
 func (h *Handler) initCommands() {
 	commands := map[string]*command{
 		// sorted alphabetically
+		"addShard": {
+			handler: h.msgShardingNotImplemented,
+			Help:    "Returns an error, as sharding is not supported.",
+		},
 		"aggregate": {
 			handler: h.msgAggregate,
+			cached:  true,
 			Help:    "Returns aggregated data.",
 		},
 		"authenticate": {
@@ -72,6 +84,7 @@ func (h *Handler) initCommands() {
 		},
 		"collMod": {
 			handler: h.msgCollMod,
+			write:   true,
 			Help:    "Adds options to a collection or modify view definitions.",
 		},
 		"collStats": {
@@ -80,8 +93,13 @@ func (h *Handler) initCommands() {
 		},
 		"compact": {
 			handler: h.msgCompact,
+			write:   true,
 			Help:    "Reduces the disk space collection takes and refreshes its statistics.",
 		},
+		"configureFailPoint": {
+			handler: h.msgConfigureFailPoint,
+			Help:    "", // hidden as available only with enableTestCommands
+		},
 		"connPoolStats": {
 			// TODO https://github.com/FerretDB/FerretDB/issues/4909
 			anonymous: true,
@@ -95,18 +113,22 @@ func (h *Handler) initCommands() {
 		},
 		"count": {
 			handler: h.msgCount,
+			cached:  true,
 			Help:    "Returns the count of documents that's matched by the query.",
 		},
 		"create": {
 			handler: h.msgCreate,
+			write:   true,
 			Help:    "Creates the collection.",
 		},
 		"createIndexes": {
 			handler: h.msgCreateIndexes,
+			write:   true,
 			Help:    "Creates indexes on a collection.",
 		},
 		"createUser": {
 			handler: h.msgCreateUser,
+			write:   true,
 			Help:    "Creates a new user.",
 		},
 		"currentOp": {
@@ -127,32 +149,47 @@ func (h *Handler) initCommands() {
 		},
 		"delete": {
 			handler: h.msgDelete,
+			write:   true,
 			Help:    "Deletes documents matched by the query.",
 		},
 		"distinct": {
 			handler: h.msgDistinct,
+			cached:  true,
 			Help:    "Returns an array of distinct values for the given field.",
 		},
 		"drop": {
 			handler: h.msgDrop,
+			write:   true,
 			Help:    "Drops the collection.",
 		},
 		"dropAllUsersFromDatabase": {
 			handler: h.msgDropAllUsersFromDatabase,
+			write:   true,
 			Help:    "Drops all user from database.",
 		},
+		"dropConnections": {
+			handler: h.msgDropConnections,
+			Help:    "Drops outgoing connections to the specified hosts.",
+		},
 		"dropDatabase": {
 			handler: h.msgDropDatabase,
+			write:   true,
 			Help:    "Drops production database.",
 		},
 		"dropIndexes": {
 			handler: h.msgDropIndexes,
+			write:   true,
 			Help:    "Drops indexes on a collection.",
 		},
 		"dropUser": {
 			handler: h.msgDropUser,
+			write:   true,
 			Help:    "Drops user.",
 		},
+		"enableSharding": {
+			handler: h.msgEnableSharding,
+			Help:    "Does nothing with experimental sharding.",
+		},
 		"endSessions": {
 			handler: h.msgEndSessions,
 			Help:    "Marks sessions as expired.",
@@ -161,22 +198,82 @@ func (h *Handler) initCommands() {
 			handler: h.msgExplain,
 			Help:    "Returns the execution plan.",
 		},
+		"ferretAbortMigration": {
+			handler: h.msgFerretAbortMigration,
+			Help:    "Stops a background migration.",
+		},
+		"ferretAnalyze": {
+			handler: h.msgFerretAnalyze,
+			write:   true,
+			Help:    "Samples a collection and stores its field statistics.",
+		},
 		"ferretDebugError": {
 			handler: h.msgFerretDebugError,
 			Help:    "Returns error for debugging.",
 		},
+		"ferretExport": {
+			handler: h.msgFerretExport,
+			Help:    "Returns collection documents as Extended JSON or CSV.",
+		},
+		"ferretExportSnapshot": {
+			handler: h.msgFerretExportSnapshot,
+			Help:    "Returns cursors over a consistent snapshot of the given collections.",
+		},
+		"ferretImport": {
+			handler: h.msgFerretImport,
+			write:   true,
+			Help:    "Inserts documents from Extended JSON or CSV into a collection.",
+		},
+		"ferretIndexSuggestions": {
+			handler: h.msgFerretIndexSuggestions,
+			Help:    "Returns indexes suggested for queries that scan collections.",
+		},
+		"ferretMigrate": {
+			handler: h.msgFerretMigrate,
+			Help:    "Starts a background migration of collection documents.",
+		},
+		"ferretRefreshView": {
+			handler: h.msgFerretRefreshView,
+			write:   true,
+			Help:    "Re-runs the $merge pipeline of a materialized view.",
+		},
+		"ferretSetDocumentPolicy": {
+			handler: h.msgFerretSetDocumentPolicy,
+			write:   true,
+			Help:    "Sets or returns per-role document filters of a collection.",
+		},
+		"ferretSetMaskingPolicy": {
+			handler: h.msgFerretSetMaskingPolicy,
+			write:   true,
+			Help:    "Sets or returns per-role field masking rules of a collection.",
+		},
+		"ferretTelemetry": {
+			handler: h.msgFerretTelemetry,
+			Help:    "Returns the telemetry report that would be sent next.",
+		},
 		"find": {
 			handler: h.msgFind,
+			cached:  true,
 			Help:    "Returns documents matched by the query.",
 		},
 		"findAndModify": {
 			handler: h.msgFindAndModify,
+			write:   true,
 			Help:    "Updates or deletes, and returns a document matched by the query.",
 		},
 		"findandmodify": { // old lowercase variant
 			handler: h.msgFindAndModify,
+			write:   true,
 			Help:    "", // hidden
 		},
+		"fsync": {
+			handler: h.msgFsync,
+			Help:    "Blocks writes with the lock option for backups.",
+		},
+		"fsyncUnlock": {
+			handler: h.msgFsyncUnlock,
+			Help:    "Releases the fsync lock.",
+		},
 		"getCmdLineOpts": {
 			handler: h.msgGetCmdLineOpts,
 			Help:    "Returns a summary of all runtime and configuration options.",
@@ -197,6 +294,10 @@ func (h *Handler) initCommands() {
 			handler: h.msgGetParameter,
 			Help:    "Returns the value of the parameter.",
 		},
+		"getShardMap": {
+			handler: h.msgGetShardMap,
+			Help:    "Returns the single logical shard.",
+		},
 		"hello": {
 			handler:   h.msgHello,
 			anonymous: true,
@@ -208,6 +309,7 @@ func (h *Handler) initCommands() {
 		},
 		"insert": {
 			handler: h.msgInsert,
+			write:   true,
 			Help:    "Inserts documents into the database.",
 		},
 		"isMaster": {
@@ -252,11 +354,19 @@ func (h *Handler) initCommands() {
 			handler: h.msgListIndexes,
 			Help:    "Returns a summary of indexes of the specified collection.",
 		},
+		"listShards": {
+			handler: h.msgListShards,
+			Help:    "Returns the single logical shard.",
+		},
 		"logout": {
 			handler:   h.msgLogout,
 			anonymous: true,
 			Help:      "Logs out from the current session.",
 		},
+		"logRotate": {
+			handler: h.msgLogRotate,
+			Help:    "Rotates the log file.",
+		},
 		"ping": {
 			handler:   h.msgPing,
 			anonymous: true,
@@ -268,12 +378,22 @@ func (h *Handler) initCommands() {
 		},
 		"reIndex": {
 			handler: h.msgReIndex,
+			write:   true,
 			Help:    "Drops and recreates all indexes except default _id index of a collection.",
 		},
+		"removeShard": {
+			handler: h.msgShardingNotImplemented,
+			Help:    "Returns an error, as sharding is not supported.",
+		},
 		"renameCollection": {
 			handler: h.msgRenameCollection,
+			write:   true,
 			Help:    "Changes the name of an existing collection.",
 		},
+		"reshardCollection": {
+			handler: h.msgShardCollection,
+			Help:    "Changes the shard key of a collection with experimental sharding.",
+		},
 		"saslStart": {
 			handler:   h.msgSASLStart,
 			anonymous: true,
@@ -288,20 +408,50 @@ func (h *Handler) initCommands() {
 			handler: h.msgServerStatus,
 			Help:    "Returns an overview of the databases state.",
 		},
+		"setFeatureCompatibilityVersion": {
+			handler: h.msgSetFeatureCompatibilityVersion,
+			Help:    "Sets the feature compatibility version.",
+		},
 		"setFreeMonitoring": {
 			handler: h.msgSetFreeMonitoring,
 			Help:    "Toggles free monitoring.",
 		},
+		"setParameter": {
+			handler: h.msgSetParameter,
+			Help:    "Sets the value of the parameter.",
+		},
+		"shardCollection": {
+			handler: h.msgShardCollection,
+			Help:    "Shards a collection with experimental sharding.",
+		},
+		"shardingState": {
+			handler: h.msgShardingState,
+			Help:    "Returns the sharding state (always disabled).",
+		},
+		"shutdown": {
+			handler: h.msgShutdown,
+			Help:    "Shuts down the FerretDB instance.",
+		},
 		"startSession": {
 			handler: h.msgStartSession,
 			Help:    "Returns a session.",
 		},
+		"top": {
+			handler: h.msgTop,
+			Help:    "Returns usage statistics for each collection.",
+		},
+		"unshardCollection": {
+			handler: h.msgShardCollection,
+			Help:    "Unshards a collection with experimental sharding.",
+		},
 		"update": {
 			handler: h.msgUpdate,
+			write:   true,
 			Help:    "Updates documents that are matched by the query.",
 		},
 		"updateUser": {
 			handler: h.msgUpdateUser,
+			write:   true,
 			Help:    "Updates user.",
 		},
 		"usersInfo": {
@@ -320,6 +470,11 @@ func (h *Handler) initCommands() {
 		// please keep sorted alphabetically
 	}
 
+	custom, hooks := registered()
+	for name, cmd := range custom {
+		commands[name] = cmd
+	}
+
 	h.commands = make(map[string]*command, len(commands))
 
 	for name, cmd := range commands {
@@ -327,6 +482,30 @@ func (h *Handler) initCommands() {
 			cmd.handler = notImplemented(name)
 		}
 
+		// hooks and fail points apply to cached results too
+		if cmd.cached {
+			cmd.handler = h.withResultCache(cmd.handler)
+		}
+
+		cmd.handler = withHooks(cmd.handler, hooks, name)
+
+		// fail points could be always disabled
+		if name != "configureFailPoint" {
+			cmd.handler = h.withFailPoints(cmd.handler, name)
+		}
+
+		if cmd.write {
+			cmd.handler = h.invalidatingResultCache(cmd.handler)
+			cmd.handler = blockedProtectedNamespaces(cmd.handler)
+			cmd.handler = blockedByFsyncLock(cmd.handler, &h.fsync)
+		}
+
+		if name == "aggregate" {
+			cmd.handler = h.writingPipelines(cmd.handler)
+		}
+
+		cmd.handler = blockedIntermediates(cmd.handler)
+
 		if h.Auth && !cmd.anonymous {
 			cmd.handler = auth(cmd.handler, logging.WithName(h.L, "auth"), name)
 		}
@@ -335,6 +514,12 @@ func (h *Handler) initCommands() {
 	}
 }
 
+// IsWrite returns true if the given command modifies data or metadata.
+func (h *Handler) IsWrite(command string) bool {
+	cmd, ok := h.commands[command]
+	return ok && cmd.write
+}
+
 // auth is a middleware that wraps the command handler with authentication check.
 //
 // Context must contain [*conninfo.ConnInfo].
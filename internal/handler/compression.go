@@ -0,0 +1,115 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// storageEngineName is the name of the `storageEngine` option field with PostgreSQL-specific options.
+// Options of other storage engines (like `wiredTiger` in mongodump metadata) are ignored.
+const storageEngineName = "postgresql"
+
+// getCompressionParam returns the compression method set by
+// `storageEngine: {postgresql: {compression: <method>}}` option of `create` and `collMod` commands.
+// It returns an empty string if it is not set.
+func getCompressionParam(doc *wirebson.Document, command string) (string, error) {
+	v := doc.Get("storageEngine")
+	if v == nil {
+		return "", nil
+	}
+
+	se, err := decodeDocument(v)
+	if err != nil {
+		return "", err
+	}
+
+	if se == nil {
+		msg := fmt.Sprintf(
+			"BSON field '%s.storageEngine' is the wrong type '%s', expected type 'object'",
+			command, aliasFromType(v),
+		)
+
+		return "", mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+	}
+
+	v = se.Get(storageEngineName)
+	if v == nil {
+		return "", nil
+	}
+
+	opts, err := decodeDocument(v)
+	if err != nil {
+		return "", err
+	}
+
+	if opts == nil {
+		msg := fmt.Sprintf("'storageEngine.%s' has to be an embedded document", storageEngineName)
+		return "", mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	var res string
+
+	for k, v := range opts.All() {
+		if k != "compression" {
+			msg := fmt.Sprintf("unknown '%s' storage engine option: %s", storageEngineName, k)
+			return "", mongoerrors.NewWithArgument(mongoerrors.ErrInvalidOptions, msg, command)
+		}
+
+		var ok bool
+
+		if res, ok = v.(string); !ok || !slices.Contains(documentdb.CompressionMethods, res) {
+			msg := fmt.Sprintf(
+				"invalid compression method %v, expected one of %q",
+				v, documentdb.CompressionMethods,
+			)
+
+			return "", mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+		}
+	}
+
+	return res, nil
+}
+
+// setCompression sets the compression method of the collection documents.
+func setCompression(ctx context.Context, conn *pgx.Conn, command, dbName, collection, method string) error {
+	err := documentdb.SetCollectionCompression(ctx, conn, dbName, collection, method)
+
+	switch {
+	case err == nil:
+		return nil
+
+	case errors.Is(err, documentdb.ErrCollectionNotFound):
+		msg := fmt.Sprintf("ns does not exist: %s.%s", dbName, collection)
+		return mongoerrors.NewWithArgument(mongoerrors.ErrNamespaceNotFound, msg, command)
+
+	case errors.Is(err, documentdb.ErrCompressionNotSupported):
+		msg := fmt.Sprintf("compression method %s is not supported by PostgreSQL", method)
+		return mongoerrors.NewWithArgument(mongoerrors.ErrInvalidOptions, msg, command)
+
+	default:
+		return lazyerrors.Error(err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+)
+
+// CustomCommandPrefix is a required prefix for names of custom commands.
+//
+// It prevents clashes with current and future MongoDB commands.
+const CustomCommandPrefix = "x_"
+
+// CustomCommand represents a command registered by an embedding application or a plugin package.
+type CustomCommand struct {
+	// Handler processes this command.
+	//
+	// The passed context is canceled when the client disconnects.
+	Handler middleware.HandleFunc
+
+	// Help is shown in the `listCommands` command output.
+	// If empty, that command is hidden, but still can be used.
+	Help string
+
+	// Anonymous indicates that the command does not require authentication.
+	Anonymous bool
+}
+
+// Hook represents functions called before and after every command.
+type Hook struct {
+	// Pre is called before the command is handled, after the authentication check.
+	// If it returns an error, the command is not handled, and that error is returned to the client.
+	Pre func(ctx context.Context, command string, req *middleware.Request) error
+
+	// Post is called after the command is handled with its result.
+	Post func(ctx context.Context, command string, req *middleware.Request, res *middleware.Response, err error)
+}
+
+// registry contains registered custom commands and hooks.
+var registry struct {
+	rw       sync.RWMutex
+	commands map[string]*CustomCommand
+	hooks    []*Hook
+}
+
+// RegisterCommand registers a custom command that will be available in all handlers created after that call.
+//
+// It is typically called from init functions of plugin packages
+// or by the embedding application before FerretDB is created.
+func RegisterCommand(name string, cmd *CustomCommand) error {
+	if !strings.HasPrefix(name, CustomCommandPrefix) || len(name) == len(CustomCommandPrefix) {
+		return fmt.Errorf("custom command name %q should start with %q", name, CustomCommandPrefix)
+	}
+
+	if cmd == nil || cmd.Handler == nil {
+		return fmt.Errorf("custom command %q has no handler", name)
+	}
+
+	registry.rw.Lock()
+	defer registry.rw.Unlock()
+
+	if _, ok := registry.commands[name]; ok {
+		return fmt.Errorf("custom command %q is already registered", name)
+	}
+
+	if registry.commands == nil {
+		registry.commands = map[string]*CustomCommand{}
+	}
+
+	registry.commands[name] = cmd
+
+	return nil
+}
+
+// RegisterHook registers a hook that will be called for all commands of handlers created after that call.
+//
+// Hooks are called in the registration order.
+func RegisterHook(hook *Hook) {
+	if hook == nil {
+		panic("hook is nil")
+	}
+
+	registry.rw.Lock()
+	defer registry.rw.Unlock()
+
+	registry.hooks = append(registry.hooks, hook)
+}
+
+// registered returns custom commands and hooks registered so far.
+func registered() (map[string]*command, []*Hook) {
+	registry.rw.RLock()
+	defer registry.rw.RUnlock()
+
+	commands := make(map[string]*command, len(registry.commands))
+	for name, cmd := range registry.commands {
+		commands[name] = &command{
+			anonymous: cmd.Anonymous,
+			handler:   cmd.Handler,
+			Help:      cmd.Help,
+		}
+	}
+
+	return commands, append([]*Hook(nil), registry.hooks...)
+}
+
+// withHooks wraps the command handler with registered hooks.
+func withHooks(next middleware.HandleFunc, hooks []*Hook, command string) middleware.HandleFunc {
+	if len(hooks) == 0 {
+		return next
+	}
+
+	return func(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+		for _, hook := range hooks {
+			if hook.Pre == nil {
+				continue
+			}
+
+			if err := hook.Pre(ctx, command, req); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := next(ctx, req)
+
+		for _, hook := range hooks {
+			if hook.Post != nil {
+				hook.Post(ctx, command, req, res, err)
+			}
+		}
+
+		return res, err
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/testutil"
+)
+
+func TestCustomCommands(t *testing.T) {
+	// registry is global
+	handle := func(context.Context, *middleware.Request) (*middleware.Response, error) {
+		return middleware.ResponseMsg(wirebson.MustDocument("ok", float64(1)))
+	}
+
+	require.Error(t, RegisterCommand("ping", &CustomCommand{Handler: handle}))
+	require.Error(t, RegisterCommand("x_", &CustomCommand{Handler: handle}))
+	require.Error(t, RegisterCommand("x_noHandler", &CustomCommand{}))
+
+	require.NoError(t, RegisterCommand("x_testCustom", &CustomCommand{Handler: handle, Help: "Test."}))
+	require.Error(t, RegisterCommand("x_testCustom", &CustomCommand{Handler: handle}))
+
+	var calls []string
+
+	RegisterHook(&Hook{
+		Pre: func(_ context.Context, command string, _ *middleware.Request) error {
+			calls = append(calls, "pre "+command)
+
+			if command == "x_testCustomDenied" {
+				return mongoerrors.New(mongoerrors.ErrUnauthorized, "denied")
+			}
+
+			return nil
+		},
+		Post: func(_ context.Context, command string, _ *middleware.Request, _ *middleware.Response, err error) {
+			calls = append(calls, "post "+command)
+		},
+	})
+
+	require.NoError(t, RegisterCommand("x_testCustomDenied", &CustomCommand{Handler: handle}))
+
+	h := &Handler{NewOpts: &NewOpts{L: testutil.Logger(t)}}
+	h.initCommands()
+
+	cmd := h.commands["x_testCustom"]
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Test.", cmd.Help)
+
+	_, err := cmd.handler(testutil.Ctx(t), nil)
+	require.NoError(t, err)
+
+	_, err = h.commands["x_testCustomDenied"].handler(testutil.Ctx(t), nil)
+	require.Error(t, err)
+
+	expected := []string{"pre x_testCustom", "post x_testCustom", "pre x_testCustomDenied"}
+	assert.Equal(t, expected, calls)
+}
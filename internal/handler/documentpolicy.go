@@ -0,0 +1,281 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// documentPolicyMetadata is a kind of collection metadata with document filters
+// set by `ferretSetDocumentPolicy` command.
+const documentPolicyMetadata = "documentPolicy"
+
+// documentPolicyUser is the value in document filters replaced by the name of the authenticated user.
+const documentPolicyUser = "$$USER"
+
+// documentRule restricts documents of the collection to ones matching the filter
+// for users with the given role.
+type documentRule struct {
+	role   string
+	filter *wirebson.Document
+}
+
+// documentFilterFields contains fields with query filters of commands.
+var documentFilterFields = map[string]string{
+	"count":         "query",
+	"distinct":      "query",
+	"find":          "filter",
+	"findAndModify": "query",
+	"findandmodify": "query", // old lowercase variant
+}
+
+// documentFilterStatements contains fields of commands with statements that have their own query filters
+// in `q` field.
+var documentFilterStatements = map[string]string{
+	"delete": "deletes",
+	"update": "updates",
+}
+
+// getDocumentRules returns validated document rules from the given array.
+func getDocumentRules(command string, v any) ([]documentRule, error) {
+	arr, ok := v.(*wirebson.Array)
+	if !ok {
+		msg := fmt.Sprintf(
+			"BSON field '%s.rules' is the wrong type '%s', expected type 'array'",
+			command, aliasFromType(v),
+		)
+
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+	}
+
+	res := make([]documentRule, 0, arr.Len())
+
+	for v := range arr.Values() {
+		d, _ := v.(*wirebson.Document)
+		if d == nil {
+			msg := fmt.Sprintf("BSON field '%s.rules' must be an array of objects", command)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+		}
+
+		var rule documentRule
+
+		if rule.role, _ = d.Get("role").(string); rule.role == "" {
+			msg := fmt.Sprintf("BSON field '%s.rules.role' must be a non-empty string", command)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+		}
+
+		if rule.filter, _ = d.Get("filter").(*wirebson.Document); rule.filter == nil {
+			msg := fmt.Sprintf("BSON field '%s.rules.filter' must be an object", command)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+		}
+
+		res = append(res, rule)
+	}
+
+	return res, nil
+}
+
+// documentRulesArray returns document rules as an array of documents,
+// as they are stored in collection metadata and accepted by [getDocumentRules].
+func documentRulesArray(rules []documentRule) *wirebson.Array {
+	res := wirebson.MakeArray(len(rules))
+
+	for _, rule := range rules {
+		must.NoError(res.Add(must.NotFail(wirebson.NewDocument(
+			"role", rule.role,
+			"filter", rule.filter,
+		))))
+	}
+
+	return res
+}
+
+// documentFilterForRoles returns the document filter for a user with the given name and roles,
+// or nil if all documents are visible.
+//
+// A user sees documents matching a filter of any role;
+// roles without filters grant access to all documents.
+// Filters of the same role are also combined with `$or`.
+func documentFilterForRoles(rules []documentRule, roles []string, username string) *wirebson.Document {
+	if len(rules) == 0 || len(roles) == 0 {
+		return nil
+	}
+
+	filters := wirebson.MakeArray(len(rules))
+
+	for _, role := range roles {
+		found := false
+
+		for _, rule := range rules {
+			if rule.role == role {
+				found = true
+				must.NoError(filters.Add(substituteUser(rule.filter, username)))
+			}
+		}
+
+		if !found {
+			return nil
+		}
+	}
+
+	if filters.Len() == 1 {
+		return filters.Get(0).(*wirebson.Document)
+	}
+
+	return must.NotFail(wirebson.NewDocument("$or", filters))
+}
+
+// substituteUser returns a deep copy of the given value
+// with [documentPolicyUser] strings replaced by the given username.
+func substituteUser(v any, username string) any {
+	switch v := v.(type) {
+	case *wirebson.Document:
+		res := wirebson.MakeDocument(v.Len())
+		for f, fv := range v.All() {
+			must.NoError(res.Add(f, substituteUser(fv, username)))
+		}
+
+		return res
+
+	case *wirebson.Array:
+		res := wirebson.MakeArray(v.Len())
+		for e := range v.Values() {
+			must.NoError(res.Add(substituteUser(e, username)))
+		}
+
+		return res
+
+	case string:
+		if v == documentPolicyUser {
+			return username
+		}
+
+		return v
+
+	default:
+		return v
+	}
+}
+
+// andFilter combines the query filter in the given field of the document (possibly missing)
+// with the document filter.
+func andFilter(doc *wirebson.Document, field string, documentFilter *wirebson.Document) {
+	filter := doc.Get(field)
+	if filter == nil {
+		must.NoError(doc.Add(field, documentFilter))
+		return
+	}
+
+	if d, _ := filter.(*wirebson.Document); d != nil && d.Len() == 0 {
+		must.NoError(doc.Replace(field, documentFilter))
+		return
+	}
+
+	must.NoError(doc.Replace(field, must.NotFail(wirebson.NewDocument(
+		"$and", must.NotFail(wirebson.NewArray(filter, documentFilter)),
+	))))
+}
+
+// documentFilter returns the document filter of the given collection for the authenticated user,
+// or nil if all documents are visible.
+func (h *Handler) documentFilter(ctx context.Context, dbName, collection string) (*wirebson.Document, error) {
+	policy, roles, username, err := h.userAccessPolicy(ctx, dbName, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return documentFilterForRoles(policy.documents, roles, username), nil
+}
+
+// applyDocumentFilter combines query filters of the given command
+// (and statements in the given document sequence, if any) with the document filter of the collection
+// for the authenticated user.
+// It returns the command and sequence as is if all documents are visible.
+//
+// `aggregate` command is handled by [Handler.applyAccessPolicy].
+func (h *Handler) applyDocumentFilter(ctx context.Context, dbName string, spec wirebson.RawDocument, seq []byte) (wirebson.RawDocument, []byte, error) { //nolint:lll // for readability
+	doc, err := spec.DecodeDeep()
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	collection, ok := doc.Get(doc.Command()).(string)
+	if !ok {
+		return spec, seq, nil
+	}
+
+	filter, err := h.documentFilter(ctx, dbName, collection)
+	if err != nil || filter == nil {
+		return spec, seq, err
+	}
+
+	return addDocumentFilter(doc, seq, filter)
+}
+
+// addDocumentFilter combines query filters of the given command
+// (and statements in the given document sequence, if any) with the given document filter.
+func addDocumentFilter(doc *wirebson.Document, seq []byte, filter *wirebson.Document) (wirebson.RawDocument, []byte, error) { //nolint:lll // for readability
+	command := doc.Command()
+
+	spec, err := doc.Encode()
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	if field, ok := documentFilterFields[command]; ok {
+		andFilter(doc, field, filter)
+
+		if spec, err = doc.Encode(); err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		return spec, seq, nil
+	}
+
+	field, ok := documentFilterStatements[command]
+	if !ok {
+		return spec, seq, nil
+	}
+
+	update := func(d *wirebson.Document) bool {
+		andFilter(d, "q", filter)
+		return true
+	}
+
+	if arr, _ := doc.Get(field).(*wirebson.Array); arr != nil {
+		for v := range arr.Values() {
+			if d, ok := v.(*wirebson.Document); ok {
+				update(d)
+			}
+		}
+
+		if spec, err = doc.Encode(); err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+	}
+
+	if seq, err = updateSequence(seq, true, update); err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	return spec, seq, nil
+}
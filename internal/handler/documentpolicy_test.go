@@ -0,0 +1,117 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentFilterForRoles(t *testing.T) {
+	t.Parallel()
+
+	tenant := wirebson.MustDocument("tenant", documentPolicyUser)
+	public := wirebson.MustDocument("public", true)
+
+	rules := []documentRule{
+		{role: "tenant", filter: tenant},
+		{role: "reader", filter: public},
+	}
+
+	for name, tc := range map[string]struct {
+		roles    []string
+		expected *wirebson.Document
+	}{
+		"NoRoles": {},
+		"Single": {
+			roles:    []string{"tenant"},
+			expected: wirebson.MustDocument("tenant", "alice"),
+		},
+		"Any": {
+			roles: []string{"tenant", "reader"},
+			expected: wirebson.MustDocument("$or", wirebson.MustArray(
+				wirebson.MustDocument("tenant", "alice"),
+				public,
+			)),
+		},
+		"Unrestricted": {
+			roles: []string{"tenant", "root"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := documentFilterForRoles(rules, tc.roles, "alice")
+			if tc.expected == nil {
+				assert.Nil(t, actual)
+				return
+			}
+
+			require.NotNil(t, actual)
+			assert.Equal(t, tc.expected.LogMessage(), actual.LogMessage())
+		})
+	}
+
+	// the stored filter is not modified
+	assert.Equal(t, documentPolicyUser, tenant.Get("tenant"))
+}
+
+func TestAndFilter(t *testing.T) {
+	t.Parallel()
+
+	filter := wirebson.MustDocument("tenant", "alice")
+
+	doc := wirebson.MustDocument("find", "c")
+	andFilter(doc, "filter", filter)
+	assert.Equal(t, wirebson.MustDocument("find", "c", "filter", filter).LogMessage(), doc.LogMessage())
+
+	doc = wirebson.MustDocument("q", wirebson.MakeDocument(0))
+	andFilter(doc, "q", filter)
+	assert.Equal(t, wirebson.MustDocument("q", filter).LogMessage(), doc.LogMessage())
+
+	q := wirebson.MustDocument("v", int32(1))
+	doc = wirebson.MustDocument("q", q)
+	andFilter(doc, "q", filter)
+
+	expected := wirebson.MustDocument("q", wirebson.MustDocument("$and", wirebson.MustArray(q, filter)))
+	assert.Equal(t, expected.LogMessage(), doc.LogMessage())
+}
+
+func TestAddDocumentFilter(t *testing.T) {
+	t.Parallel()
+
+	filter := wirebson.MustDocument("tenant", "alice")
+	q := wirebson.MustDocument("_id", int32(1))
+	expected := wirebson.MustDocument("$and", wirebson.MustArray(q, filter))
+
+	for _, command := range []string{"findAndModify", "findandmodify"} {
+		t.Run(command, func(t *testing.T) {
+			t.Parallel()
+
+			doc := wirebson.MustDocument(command, "c", "query", q, "remove", true)
+
+			spec, seq, err := addDocumentFilter(doc, nil, filter)
+			require.NoError(t, err)
+			assert.Nil(t, seq)
+
+			actual, err := spec.DecodeDeep()
+			require.NoError(t, err)
+			assert.Equal(t, expected.LogMessage(), actual.Get("query").(*wirebson.Document).LogMessage())
+		})
+	}
+}
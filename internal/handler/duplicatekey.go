@@ -0,0 +1,226 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/FerretDB/wire"
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// duplicateKeyIndexRe matches the index name in DocumentDB duplicate key error messages.
+var duplicateKeyIndexRe = regexp.MustCompile(`Index '(.+)'$`)
+
+// addDuplicateKeyInfo adds `keyPattern` and `keyValue` fields to DuplicateKey write errors
+// of the given insert or update response, as MongoDB does.
+//
+// Key values are added only for `insert` command (when insert is not nil)
+// and only for indexes on non-array values.
+// Errors are logged and the response is returned as is.
+//
+// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/895
+func (h *Handler) addDuplicateKeyInfo(ctx context.Context, dbName, collection string, insert *wire.OpMsg, res wirebson.AnyDocument) wirebson.AnyDocument { //nolint:lll // for readability
+	// fast path for responses without write errors
+	if doc, err := res.Decode(); err == nil && doc.Get("writeErrors") == nil {
+		return res
+	}
+
+	raw, err := res.Encode()
+	if err != nil {
+		h.L.WarnContext(ctx, "Failed to encode write response", logging.Error(err))
+		return res
+	}
+
+	resDoc, err := raw.DecodeDeep()
+	if err != nil {
+		h.L.WarnContext(ctx, "Failed to decode write response", logging.Error(err))
+		return res
+	}
+
+	writeErrors, _ := resDoc.Get("writeErrors").(*wirebson.Array)
+	if writeErrors == nil {
+		return res
+	}
+
+	var keyPatterns map[string]*wirebson.Document
+	var docs *wirebson.Array
+
+	for v := range writeErrors.Values() {
+		writeError, _ := v.(*wirebson.Document)
+		if writeError == nil {
+			continue
+		}
+
+		if code, _ := writeError.Get("code").(int32); code != int32(mongoerrors.ErrDuplicateKey) {
+			continue
+		}
+
+		errmsg, _ := writeError.Get("errmsg").(string)
+
+		match := duplicateKeyIndexRe.FindStringSubmatch(errmsg)
+		if match == nil {
+			continue
+		}
+
+		if keyPatterns == nil {
+			if keyPatterns, err = h.indexKeyPatterns(ctx, dbName, collection); err != nil {
+				h.L.WarnContext(
+					ctx, "Failed to get index key patterns",
+					logging.Error(err), slog.String("ns", dbName+"."+collection),
+				)
+				return res
+			}
+		}
+
+		keyPattern := keyPatterns[match[1]]
+		if keyPattern == nil {
+			continue
+		}
+
+		must.NoError(writeError.Add("keyPattern", keyPattern))
+
+		if insert == nil {
+			continue
+		}
+
+		if docs == nil {
+			msg, err := insert.DocumentDeep()
+			if err != nil {
+				h.L.WarnContext(ctx, "Failed to decode insert command", logging.Error(err))
+				return resDoc
+			}
+
+			if docs, _ = msg.Get("documents").(*wirebson.Array); docs == nil {
+				return resDoc
+			}
+		}
+
+		i, _ := writeError.Get("index").(int32)
+
+		doc, _ := docs.Get(int(i)).(*wirebson.Document)
+		if doc == nil {
+			continue
+		}
+
+		if keyValue := duplicateKeyValue(keyPattern, doc); keyValue != nil {
+			must.NoError(writeError.Add("keyValue", keyValue))
+		}
+	}
+
+	return resDoc
+}
+
+// indexKeyPatterns returns key patterns of the collection indexes by their names.
+func (h *Handler) indexKeyPatterns(ctx context.Context, dbName, collection string) (map[string]*wirebson.Document, error) {
+	indexes, err := h.listIndexSpecs(ctx, dbName, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := make(map[string]*wirebson.Document, len(indexes))
+
+	for _, index := range indexes {
+		name, _ := index.Get("name").(string)
+		key, _ := index.Get("key").(*wirebson.Document)
+
+		if name != "" && key != nil {
+			res[name] = key
+		}
+	}
+
+	return res, nil
+}
+
+// listIndexSpecs returns specifications of the collection indexes as returned by `listIndexes` command.
+func (h *Handler) listIndexSpecs(ctx context.Context, dbName, collection string) ([]*wirebson.Document, error) {
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument("listIndexes", collection)).Encode())
+
+	page, cursorID, err := h.Pool.ListIndexes(ctx, dbName, spec)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if cursorID != 0 {
+		h.Pool.KillCursor(ctx, cursorID)
+	}
+
+	doc, err := page.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	cursor, ok := doc.Get("cursor").(*wirebson.Document)
+	if !ok {
+		return nil, lazyerrors.Errorf("no cursor in the page: %s", doc.LogMessage())
+	}
+
+	batch, ok := cursor.Get("firstBatch").(*wirebson.Array)
+	if !ok {
+		return nil, lazyerrors.Errorf("no firstBatch in the page: %s", doc.LogMessage())
+	}
+
+	res := make([]*wirebson.Document, 0, batch.Len())
+
+	for v := range batch.Values() {
+		if index, _ := v.(*wirebson.Document); index != nil {
+			res = append(res, index)
+		}
+	}
+
+	return res, nil
+}
+
+// duplicateKeyValue returns values of the document for the given index key pattern.
+// Missing values are null, as they are indexed as null.
+//
+// It returns nil if some value is an array, as the conflicting array element is unknown.
+func duplicateKeyValue(keyPattern, doc *wirebson.Document) *wirebson.Document {
+	res := wirebson.MakeDocument(keyPattern.Len())
+
+	for path := range keyPattern.Fields() {
+		var v any = doc
+
+		for _, f := range strings.Split(path, ".") {
+			switch d := v.(type) {
+			case *wirebson.Document:
+				v = d.Get(f)
+			case *wirebson.Array:
+				return nil
+			default:
+				v = nil
+			}
+		}
+
+		switch v.(type) {
+		case nil:
+			v = wirebson.Null
+		case *wirebson.Array:
+			return nil
+		}
+
+		must.NoError(res.Add(path, v))
+	}
+
+	return res
+}
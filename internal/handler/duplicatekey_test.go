@@ -0,0 +1,65 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestDuplicateKeyValue(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(wirebson.NewDocument(
+		"_id", int32(1),
+		"v", int32(42),
+		"foo", must.NotFail(wirebson.NewDocument("bar", "baz")),
+		"arr", must.NotFail(wirebson.NewArray(int32(1), int32(2))),
+	))
+
+	for name, tc := range map[string]struct {
+		keyPattern *wirebson.Document
+		expected   *wirebson.Document
+	}{
+		"Compound": {
+			keyPattern: must.NotFail(wirebson.NewDocument("v", int32(1), "foo.bar", int32(-1))),
+			expected:   must.NotFail(wirebson.NewDocument("v", int32(42), "foo.bar", "baz")),
+		},
+		"Missing": {
+			keyPattern: must.NotFail(wirebson.NewDocument("v", int32(1), "missing", int32(1), "v.missing", int32(1))),
+			expected: must.NotFail(wirebson.NewDocument(
+				"v", int32(42),
+				"missing", wirebson.Null,
+				"v.missing", wirebson.Null,
+			)),
+		},
+		"Array": {
+			keyPattern: must.NotFail(wirebson.NewDocument("v", int32(1), "arr", int32(1))),
+		},
+		"ArrayPath": {
+			keyPattern: must.NotFail(wirebson.NewDocument("arr.0", int32(1))),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, duplicateKeyValue(tc.keyPattern, doc))
+		})
+	}
+}
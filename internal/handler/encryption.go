@@ -0,0 +1,193 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// encryptedFieldsMetadata is a kind of collection metadata with Queryable Encryption `encryptedFields` option.
+const encryptedFieldsMetadata = "encryptedFields"
+
+// getEncryptedFieldsParam returns the validated `encryptedFields` option of `create` command
+// with default names of state collections, or nil if it is not set.
+//
+// Encrypted values are opaque binary values (subtype 6) for FerretDB;
+// encryption and decryption are done by drivers.
+func getEncryptedFieldsParam(doc *wirebson.Document, collection string) (*wirebson.Document, error) {
+	v := doc.Get("encryptedFields")
+	if v == nil {
+		return nil, nil
+	}
+
+	raw, ok := v.(wirebson.RawDocument)
+	if !ok {
+		msg := fmt.Sprintf(
+			"BSON field 'create.encryptedFields' is the wrong type '%s', expected type 'object'",
+			aliasFromType(v),
+		)
+
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, "create")
+	}
+
+	ef, err := raw.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	fields, ok := ef.Get("fields").(*wirebson.Array)
+	if !ok {
+		msg := "BSON field 'create.encryptedFields.fields' is missing but a required field"
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrLocation40414, msg, "create")
+	}
+
+	for v := range fields.Values() {
+		field, _ := v.(*wirebson.Document)
+		if field == nil {
+			msg := "BSON field 'create.encryptedFields.fields' must be an array of objects"
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, "create")
+		}
+
+		if _, ok = field.Get("path").(string); !ok {
+			msg := "BSON field 'create.encryptedFields.fields.path' is missing but a required field"
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrLocation40414, msg, "create")
+		}
+
+		if id, ok := field.Get("keyId").(wirebson.Binary); !ok || id.Subtype != wirebson.BinaryUUID {
+			msg := "BSON field 'create.encryptedFields.fields.keyId' must be a UUID"
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, "create")
+		}
+	}
+
+	for _, state := range []string{"esc", "ecoc"} {
+		f := state + "Collection"
+
+		switch name := ef.Get(f).(type) {
+		case nil:
+			must.NoError(ef.Add(f, "enxcol_."+collection+"."+state))
+		case string:
+			if !collectionNameRe.MatchString(name) {
+				msg := fmt.Sprintf("Invalid collection name: %s", name)
+				return nil, mongoerrors.NewWithArgument(mongoerrors.ErrInvalidNamespace, msg, "create")
+			}
+		default:
+			msg := fmt.Sprintf(
+				"BSON field 'create.encryptedFields.%s' is the wrong type '%s', expected type 'string'",
+				f, aliasFromType(name),
+			)
+
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, "create")
+		}
+	}
+
+	return ef, nil
+}
+
+// setEncryptedFields creates state collections of the given encrypted collection
+// and stores its `encryptedFields` in collection metadata.
+func (h *Handler) setEncryptedFields(ctx context.Context, conn *pgx.Conn, dbName, collection string, ef *wirebson.Document) error { //nolint:lll // for readability
+	for _, f := range []string{"escCollection", "ecocCollection"} {
+		if _, err := documentdb_api.CreateCollection(ctx, conn, h.L, dbName, ef.Get(f).(string)); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return documentdb.UpdateMetadata(ctx, conn, dbName, collection, encryptedFieldsMetadata, func(md *wirebson.Document) error {
+		for _, f := range md.FieldNames() {
+			md.Remove(f)
+		}
+
+		for f, v := range ef.All() {
+			must.NoError(md.Add(f, v))
+		}
+
+		return nil
+	})
+}
+
+// applyCollectionOptions adds stored `encryptedFields` options to the given `listCollections` response page.
+func (h *Handler) applyCollectionOptions(ctx context.Context, dbName string, page wirebson.RawDocument) (wirebson.AnyDocument, error) { //nolint:lll // for readability
+	var all []documentdb.CollectionMetadata
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		all, err = documentdb.AllMetadata(ctx, conn, encryptedFieldsMetadata)
+
+		return err
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	encrypted := make(map[string]*wirebson.Document, len(all))
+
+	for _, md := range all {
+		if md.DB == dbName {
+			encrypted[md.Collection] = md.Metadata
+		}
+	}
+
+	if len(encrypted) == 0 {
+		return page, nil
+	}
+
+	res, err := page.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	cursor, _ := res.Get("cursor").(*wirebson.Document)
+	if cursor == nil {
+		return page, nil
+	}
+
+	batch, _ := cursor.Get("firstBatch").(*wirebson.Array)
+	if batch == nil {
+		return page, nil
+	}
+
+	for v := range batch.Values() {
+		coll, _ := v.(*wirebson.Document)
+		if coll == nil {
+			continue
+		}
+
+		name, _ := coll.Get("name").(string)
+
+		ef := encrypted[name]
+		if ef == nil {
+			continue
+		}
+
+		opts, _ := coll.Get("options").(*wirebson.Document)
+		if opts == nil || opts.Get("encryptedFields") != nil {
+			continue
+		}
+
+		must.NoError(opts.Add("encryptedFields", ef))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestGetEncryptedFieldsParam(t *testing.T) {
+	t.Parallel()
+
+	keyID := wirebson.Binary{Subtype: wirebson.BinaryUUID, B: make([]byte, 16)}
+	field := must.NotFail(wirebson.NewDocument("path", "ssn", "bsonType", "string", "keyId", keyID))
+
+	for name, tc := range map[string]struct {
+		ef       *wirebson.Document
+		expected *wirebson.Document
+		err      bool
+	}{
+		"Defaults": {
+			ef: must.NotFail(wirebson.NewDocument("fields", must.NotFail(wirebson.NewArray(field)))),
+			expected: must.NotFail(wirebson.NewDocument(
+				"fields", must.NotFail(wirebson.NewArray(field)),
+				"escCollection", "enxcol_.c.esc",
+				"ecocCollection", "enxcol_.c.ecoc",
+			)),
+		},
+		"Names": {
+			ef: must.NotFail(wirebson.NewDocument(
+				"escCollection", "esc",
+				"ecocCollection", "ecoc",
+				"fields", wirebson.MakeArray(0),
+			)),
+			expected: must.NotFail(wirebson.NewDocument(
+				"escCollection", "esc",
+				"ecocCollection", "ecoc",
+				"fields", wirebson.MakeArray(0),
+			)),
+		},
+		"NoFields": {
+			ef:  wirebson.MakeDocument(0),
+			err: true,
+		},
+		"NoKeyID": {
+			ef: must.NotFail(wirebson.NewDocument("fields", must.NotFail(wirebson.NewArray(
+				must.NotFail(wirebson.NewDocument("path", "ssn")),
+			)))),
+			err: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(wirebson.NewDocument("create", "c", "encryptedFields", must.NotFail(tc.ef.Encode())))
+
+			actual, err := getEncryptedFieldsParam(doc, "c")
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected.LogMessage(), actual.LogMessage())
+		})
+	}
+}
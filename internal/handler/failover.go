@@ -0,0 +1,173 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+)
+
+// failoverTimeout is the maximal duration commands wait for PostgreSQL primary before retrying after failover.
+const failoverTimeout = 30 * time.Second
+
+// runHealthChecks periodically checks the availability of PostgreSQL primary until ctx is canceled.
+//
+// See [documentdb.Pool.CheckHealth] for details.
+func (h *Handler) runHealthChecks(ctx context.Context) {
+	for ctx.Err() == nil {
+		// the interval could be changed with the server parameter
+		d := h.healthCheckInterval()
+		if d == 0 {
+			ctxutil.Sleep(ctx, time.Second)
+			continue
+		}
+
+		h.Pool.CheckHealth(ctx)
+
+		ctxutil.Sleep(ctx, d)
+	}
+}
+
+// retryAfterFailover waits for PostgreSQL primary and retries the command once
+// if the given error was caused by failover and the command could be safely retried.
+// Otherwise, it returns the given response and error.
+func (h *Handler) retryAfterFailover(
+	ctx context.Context, cmd *command, req *middleware.Request, doc *wirebson.Document,
+	res *middleware.Response, err error,
+) (*middleware.Response, error) {
+	if !documentdb.IsFailoverError(err) || !retryableAfterFailover(doc) {
+		return res, err
+	}
+
+	h.L.WarnContext(ctx, "Command failed due to PostgreSQL failover", slog.String("command", doc.Command()), logging.Error(err))
+
+	if waitErr := h.Pool.WaitHealthy(ctx, failoverTimeout); waitErr != nil {
+		h.L.WarnContext(ctx, "PostgreSQL primary is not available", logging.Error(waitErr))
+		return res, err
+	}
+
+	return cmd.handler(ctx, req)
+}
+
+// retryableAfterFailover returns true if the command could be retried after failover:
+// it either does not modify data, or it is a retryable write with a transaction number.
+func retryableAfterFailover(doc *wirebson.Document) bool {
+	switch doc.Command() {
+	case "count", "collStats", "dbStats", "distinct", "find", "listCollections", "listDatabases", "listIndexes":
+		return true
+
+	case "aggregate":
+		pipeline, _ := doc.Get("pipeline").(wirebson.AnyArray)
+		if pipeline == nil {
+			return false
+		}
+
+		arr, err := pipeline.Decode()
+		if err != nil {
+			return false
+		}
+
+		for v := range arr.Values() {
+			stage, _ := v.(wirebson.AnyDocument)
+			if stage == nil {
+				continue
+			}
+
+			d, err := stage.Decode()
+			if err != nil {
+				return false
+			}
+
+			if c := d.Command(); c == "$out" || c == "$merge" {
+				return false
+			}
+		}
+
+		return true
+
+	case "insert", "update", "delete", "findAndModify", "findandmodify":
+		return doc.Get("txnNumber") != nil && doc.Get("autocommit") == nil
+
+	default:
+		return false
+	}
+}
+
+// topologyVersion returns the `topologyVersion` field of `hello` responses.
+func (h *Handler) topologyVersion() *wirebson.Document {
+	counter, _ := h.Pool.Topology()
+
+	return wirebson.MustDocument(
+		"processId", h.processID,
+		"counter", counter,
+	)
+}
+
+// awaitTopologyChange implements awaitable `hello` used by drivers for server monitoring:
+// if the given `topologyVersion` is current, it waits until PostgreSQL topology changes,
+// `maxAwaitTimeMS` passes, or ctx is canceled.
+func (h *Handler) awaitTopologyChange(ctx context.Context, doc *wirebson.Document) {
+	tv, _ := doc.Get("topologyVersion").(wirebson.AnyDocument)
+	if tv == nil {
+		return
+	}
+
+	var maxAwait time.Duration
+
+	switch v := doc.Get("maxAwaitTimeMS").(type) {
+	case int32:
+		maxAwait = time.Duration(v) * time.Millisecond
+	case int64:
+		maxAwait = time.Duration(v) * time.Millisecond
+	case float64:
+		maxAwait = time.Duration(v) * time.Millisecond
+	}
+
+	if maxAwait <= 0 {
+		return
+	}
+
+	tvDoc, err := tv.Decode()
+	if err != nil {
+		return
+	}
+
+	version, changed := h.Pool.Topology()
+
+	if processID, _ := tvDoc.Get("processId").(wirebson.ObjectID); processID != h.processID {
+		return
+	}
+
+	if counter, _ := tvDoc.Get("counter").(int64); counter != version {
+		return
+	}
+
+	t := time.NewTimer(maxAwait)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-changed:
+	case <-t.C:
+	}
+}
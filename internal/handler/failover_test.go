@@ -0,0 +1,81 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryableAfterFailover(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		doc      *wirebson.Document
+		expected bool
+	}{
+		"Find": {
+			doc:      wirebson.MustDocument("find", "c", "filter", wirebson.MustDocument()),
+			expected: true,
+		},
+		"Aggregate": {
+			doc: wirebson.MustDocument("aggregate", "c", "pipeline", wirebson.MustArray(
+				wirebson.MustDocument("$match", wirebson.MustDocument()),
+			)),
+			expected: true,
+		},
+		"AggregateOut": {
+			doc: wirebson.MustDocument("aggregate", "c", "pipeline", wirebson.MustArray(
+				wirebson.MustDocument("$match", wirebson.MustDocument()),
+				wirebson.MustDocument("$out", "other"),
+			)),
+			expected: false,
+		},
+		"Insert": {
+			doc:      wirebson.MustDocument("insert", "c", "documents", wirebson.MustArray()),
+			expected: false,
+		},
+		"RetryableInsert": {
+			doc:      wirebson.MustDocument("insert", "c", "documents", wirebson.MustArray(), "txnNumber", int64(1)),
+			expected: true,
+		},
+		"RetryableFindAndModify": {
+			doc:      wirebson.MustDocument("findAndModify", "c", "remove", true, "txnNumber", int64(1)),
+			expected: true,
+		},
+		"RetryableFindAndModifyLowercase": {
+			doc:      wirebson.MustDocument("findandmodify", "c", "remove", true, "txnNumber", int64(1)),
+			expected: true,
+		},
+		"Transaction": {
+			doc: wirebson.MustDocument(
+				"insert", "c", "documents", wirebson.MustArray(), "txnNumber", int64(1), "autocommit", false,
+			),
+			expected: false,
+		},
+		"Drop": {
+			doc:      wirebson.MustDocument("drop", "c"),
+			expected: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, retryableAfterFailover(tc.doc))
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/FerretDB/FerretDB/v2/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// Names of fail points supported by `configureFailPoint` command.
+const (
+	// failPointFailCommand fails, blocks, or closes the connection for the given commands, like in MongoDB.
+	failPointFailCommand = "failCommand"
+
+	// failPointFerretBackendError fails the given commands as if PostgreSQL returned an error
+	// with the given SQLSTATE code, so its mapping to MongoDB error codes could be tested.
+	failPointFerretBackendError = "ferretBackendError"
+)
+
+// failPointNames contains names of supported fail points in the order they are checked.
+var failPointNames = []string{failPointFailCommand, failPointFerretBackendError}
+
+// backendErrorStates contains SQLSTATE codes supported by [failPointFerretBackendError].
+var backendErrorStates = []string{
+	pgerrcode.SerializationFailure,
+	pgerrcode.DeadlockDetected,
+	pgerrcode.QueryCanceled,
+	pgerrcode.ConnectionFailure,
+}
+
+// failPoint represents a configured fail point.
+type failPoint struct {
+	skip  int64 // number of activations to skip
+	times int64 // number of remaining activations; negative for unlimited
+
+	commands        []string
+	appName         string // if set, only connections with that client application name are affected
+	blockTime       time.Duration
+	closeConnection bool
+	errorCode       mongoerrors.Code
+	sqlState        string
+}
+
+// failPoints is a registry of fail points set by `configureFailPoint` command.
+//
+// The zero value has no fail points and is ready to use.
+type failPoints struct {
+	mu      sync.Mutex
+	enabled map[string]*failPoint
+	counts  map[string]int64 // number of times each fail point was activated
+}
+
+// Set enables or disables (if fp is nil) the fail point with the given name.
+// It returns the number of times it was activated so far.
+func (fps *failPoints) Set(name string, fp *failPoint) int64 {
+	fps.mu.Lock()
+	defer fps.mu.Unlock()
+
+	if fps.enabled == nil {
+		fps.enabled = map[string]*failPoint{}
+		fps.counts = map[string]int64{}
+	}
+
+	if fp == nil {
+		delete(fps.enabled, name)
+	} else {
+		fps.enabled[name] = fp
+	}
+
+	return fps.counts[name]
+}
+
+// activate returns the name and the fail point that should be activated for the given command, if any.
+func (fps *failPoints) activate(ctx context.Context, command string) (string, *failPoint) {
+	fps.mu.Lock()
+	defer fps.mu.Unlock()
+
+	if len(fps.enabled) == 0 {
+		return "", nil
+	}
+
+	for _, name := range failPointNames {
+		fp := fps.enabled[name]
+		if fp == nil || !slices.Contains(fp.commands, command) {
+			continue
+		}
+
+		if fp.appName != "" && conninfo.Get(ctx).AppName() != fp.appName {
+			continue
+		}
+
+		if fp.skip > 0 {
+			fp.skip--
+			continue
+		}
+
+		if fp.times > 0 {
+			fp.times--
+
+			if fp.times == 0 {
+				delete(fps.enabled, name)
+			}
+		}
+
+		fps.counts[name]++
+
+		return name, fp
+	}
+
+	return "", nil
+}
+
+// withFailPoints is a middleware that wraps the command handler with enabled fail points.
+func (h *Handler) withFailPoints(next middleware.HandleFunc, command string) middleware.HandleFunc {
+	return func(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+		name, fp := h.failPoints.activate(ctx, command)
+		if fp == nil {
+			return next(ctx, req)
+		}
+
+		h.L.DebugContext(ctx, "Fail point activated", slog.String("name", name), slog.String("command", command))
+
+		if fp.blockTime > 0 {
+			t := time.NewTimer(fp.blockTime)
+			defer t.Stop()
+
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return nil, lazyerrors.Error(context.Cause(ctx))
+			}
+		}
+
+		switch {
+		case fp.closeConnection:
+			return nil, middleware.ErrCloseConnection
+
+		case fp.errorCode != 0:
+			return nil, mongoerrors.New(fp.errorCode, fmt.Sprintf("Failing command via '%s' failpoint", name))
+
+		case fp.sqlState != "":
+			pgErr := &pgconn.PgError{
+				Severity:            "ERROR",
+				SeverityUnlocalized: "ERROR",
+				Code:                fp.sqlState,
+				Message:             fmt.Sprintf("Failing command via '%s' failpoint", name),
+			}
+
+			return nil, mongoerrors.Make(ctx, pgErr, name, h.L)
+
+		default:
+			return next(ctx, req)
+		}
+	}
+}
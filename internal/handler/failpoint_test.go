@@ -0,0 +1,63 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailPoints(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var fps failPoints
+
+	name, fp := fps.activate(ctx, "find")
+	assert.Empty(t, name)
+	assert.Nil(t, fp)
+
+	assert.Zero(t, fps.Set(failPointFailCommand, &failPoint{skip: 1, times: 2, commands: []string{"find"}}))
+
+	_, fp = fps.activate(ctx, "insert")
+	assert.Nil(t, fp, "other command")
+
+	_, fp = fps.activate(ctx, "find")
+	assert.Nil(t, fp, "skipped")
+
+	for range 2 {
+		name, fp = fps.activate(ctx, "find")
+		assert.Equal(t, failPointFailCommand, name)
+		assert.NotNil(t, fp)
+	}
+
+	_, fp = fps.activate(ctx, "find")
+	assert.Nil(t, fp, "disabled after times")
+
+	assert.Equal(t, int64(2), fps.Set(failPointFailCommand, &failPoint{times: -1, commands: []string{"find"}}))
+
+	for range 3 {
+		_, fp = fps.activate(ctx, "find")
+		assert.NotNil(t, fp, "always on")
+	}
+
+	assert.Equal(t, int64(5), fps.Set(failPointFailCommand, nil))
+
+	_, fp = fps.activate(ctx, "find")
+	assert.Nil(t, fp, "off")
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// fcvGates maps aggregation stages and operators to the feature compatibility version
+// that allows them, in the same way as MongoDB does during upgrades.
+// Features added in versions before [minAdvertisedMongoDBVersion] are always allowed.
+var fcvGates = map[string][2]int32{
+	"$densify":    {6, 0},
+	"$documents":  {6, 0},
+	"$fill":       {6, 0},
+	"$linearFill": {6, 0},
+	"$locf":       {6, 0},
+
+	"$bottom":  {6, 0},
+	"$bottomN": {6, 0},
+	"$firstN":  {6, 0},
+	"$lastN":   {6, 0},
+	"$maxN":    {6, 0},
+	"$minN":    {6, 0},
+	"$top":     {6, 0},
+	"$topN":    {6, 0},
+
+	"$bitAnd":     {7, 0},
+	"$bitNot":     {7, 0},
+	"$bitOr":      {7, 0},
+	"$bitXor":     {7, 0},
+	"$median":     {7, 0},
+	"$percentile": {7, 0},
+}
+
+// fcvString returns major.minor version as a string.
+func fcvString(v [2]int32) string {
+	return fmt.Sprintf("%d.%d", v[0], v[1])
+}
+
+// allowedFCVs returns feature compatibility versions that could be set
+// for the advertised MongoDB version: the last LTS version and the latest version.
+func (h *Handler) allowedFCVs() [][2]int32 {
+	v := h.mongoDBVersion()
+	latest := [2]int32{v[0], v[1]}
+
+	lastLTS := [2]int32{v[0], 0}
+	if v[1] == 0 {
+		lastLTS[0]--
+	}
+
+	if cmpVersions(lastLTS, minAdvertisedMongoDBVersion) < 0 || lastLTS == latest {
+		return [][2]int32{latest}
+	}
+
+	return [][2]int32{lastLTS, latest}
+}
+
+// parseFCV returns major and minor version from the feature compatibility version string.
+// It returns false if the string is not a major.minor version.
+func parseFCV(s string) ([2]int32, bool) {
+	match := advertisedMongoDBVersionRe.FindStringSubmatch(s)
+	if match == nil {
+		return [2]int32{}, false
+	}
+
+	major := must.NotFail(strconv.ParseInt(match[1], 10, 32))
+	minor := must.NotFail(strconv.ParseInt(match[2], 10, 32))
+
+	return [2]int32{int32(major), int32(minor)}, true
+}
+
+// featureCompatibilityVersion returns the current feature compatibility version.
+//
+// It is the value set by `setFeatureCompatibilityVersion` command
+// if it is allowed for the advertised MongoDB version, and the advertised major.minor version otherwise.
+func (h *Handler) featureCompatibilityVersion() [2]int32 {
+	allowed := h.allowedFCVs()
+
+	if fcv, ok := parseFCV(h.StateProvider.Get().FeatureCompatibilityVersion); ok && slices.Contains(allowed, fcv) {
+		return fcv
+	}
+
+	return allowed[len(allowed)-1]
+}
+
+// checkFCVGates returns QueryFeatureNotAllowed protocol error
+// if the given aggregation pipeline uses features not allowed by the current feature compatibility version.
+func (h *Handler) checkFCVGates(command string, pipeline any) error {
+	allowed := h.allowedFCVs()
+
+	fcv := h.featureCompatibilityVersion()
+	if fcv == allowed[len(allowed)-1] {
+		return nil
+	}
+
+	var check func(v any) error
+
+	check = func(v any) error {
+		switch v := v.(type) {
+		case wirebson.RawDocument:
+			doc, err := v.Decode()
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			return check(doc)
+
+		case wirebson.RawArray:
+			arr, err := v.Decode()
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			return check(arr)
+
+		case *wirebson.Document:
+			for k, fv := range v.All() {
+				if gate, ok := fcvGates[k]; ok && cmpVersions(fcv, gate) < 0 {
+					msg := fmt.Sprintf(
+						"%s is not allowed in the current feature compatibility version (%s), "+
+							"it requires feature compatibility version %s",
+						k, fcvString(fcv), fcvString(gate),
+					)
+
+					return mongoerrors.NewWithArgument(mongoerrors.ErrQueryFeatureNotAllowed, msg, command)
+				}
+
+				if err := check(fv); err != nil {
+					return err
+				}
+			}
+
+		case *wirebson.Array:
+			for _, ev := range v.All() {
+				if err := check(ev); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	return check(pipeline)
+}
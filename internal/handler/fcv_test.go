@@ -0,0 +1,74 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+	"github.com/FerretDB/FerretDB/v2/internal/util/state"
+)
+
+func TestFeatureCompatibilityVersion(t *testing.T) {
+	t.Parallel()
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	h := &Handler{NewOpts: &NewOpts{StateProvider: sp}}
+	h.paramValues.mongoDBVersion.Store(&[2]int32{7, 0})
+
+	assert.Equal(t, [][2]int32{{6, 0}, {7, 0}}, h.allowedFCVs())
+	assert.Equal(t, [2]int32{7, 0}, h.featureCompatibilityVersion())
+
+	pipeline := must.NotFail(wirebson.NewArray(
+		must.NotFail(wirebson.NewDocument("$group", must.NotFail(wirebson.NewDocument(
+			"_id", wirebson.Null,
+			"m", must.NotFail(wirebson.NewDocument("$median", must.NotFail(wirebson.NewDocument(
+				"input", "$v",
+				"method", "approximate",
+			)))),
+		)))),
+	))
+
+	require.NoError(t, h.checkFCVGates("aggregate", pipeline))
+
+	require.NoError(t, sp.Update(func(s *state.State) { s.FeatureCompatibilityVersion = "6.0" }))
+	assert.Equal(t, [2]int32{6, 0}, h.featureCompatibilityVersion())
+
+	err = h.checkFCVGates("aggregate", pipeline)
+	require.Error(t, err)
+
+	var e *mongoerrors.Error
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, int32(mongoerrors.ErrQueryFeatureNotAllowed), e.Code)
+
+	// stored value is ignored if it is not allowed for the advertised version
+	h.paramValues.mongoDBVersion.Store(&[2]int32{8, 0})
+	assert.Equal(t, [][2]int32{{7, 0}, {8, 0}}, h.allowedFCVs())
+	assert.Equal(t, [2]int32{8, 0}, h.featureCompatibilityVersion())
+
+	h.paramValues.mongoDBVersion.Store(&[2]int32{5, 0})
+	assert.Equal(t, [][2]int32{{5, 0}}, h.allowedFCVs())
+
+	h.paramValues.mongoDBVersion.Store(&[2]int32{6, 2})
+	assert.Equal(t, [][2]int32{{6, 0}, {6, 2}}, h.allowedFCVs())
+	assert.Equal(t, [2]int32{6, 0}, h.featureCompatibilityVersion())
+}
@@ -0,0 +1,261 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"cmp"
+	"context"
+	"hash/maphash"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// fieldStatsMetadata is a kind of collection metadata with field statistics.
+const fieldStatsMetadata = "fieldStats"
+
+const (
+	// defaultAnalyzeSampleSize is the default number of sampled documents.
+	defaultAnalyzeSampleSize = 1000
+
+	// maxAnalyzeSampleSize is the maximal number of sampled documents.
+	maxAnalyzeSampleSize = 100000
+
+	// maxAnalyzedFields is the maximal number of fields with statistics.
+	maxAnalyzedFields = 1000
+
+	// maxAnalyzedDepth is the maximal depth of nested fields with statistics.
+	maxAnalyzedDepth = 5
+)
+
+// fieldStats represents statistics of a single field of sampled documents.
+type fieldStats struct {
+	path    string
+	present int64            // number of sampled documents with that field
+	types   map[string]int64 // number of values by type alias
+	values  map[uint64]int64 // number of sampled documents by value hash
+}
+
+// distinct returns the estimated number of distinct values of the field
+// in the given total number of collection documents with that field,
+// using the Haas and Stokes estimator (the same as PostgreSQL's ANALYZE).
+func (fs *fieldStats) distinct(total int64) float64 {
+	n := float64(fs.present)
+	d := float64(len(fs.values))
+	N := max(float64(total), n)
+
+	if n == 0 {
+		return 0
+	}
+
+	var f1 float64
+
+	for _, c := range fs.values {
+		if c == 1 {
+			f1++
+		}
+	}
+
+	if f1 == n {
+		// all sampled values are unique
+		return N
+	}
+
+	res := n * d / (n - f1 + f1*n/N)
+
+	return math.Round(min(max(res, d), N))
+}
+
+// collectionStats collects statistics of sampled documents.
+type collectionStats struct {
+	seed   maphash.Seed
+	n      int64
+	fields map[string]*fieldStats
+}
+
+// newCollectionStats returns new empty statistics.
+func newCollectionStats() *collectionStats {
+	return &collectionStats{
+		seed:   maphash.MakeSeed(),
+		fields: map[string]*fieldStats{},
+	}
+}
+
+// add adds the given sampled document to statistics.
+func (cs *collectionStats) add(doc *wirebson.Document) error {
+	cs.n++
+	return cs.addFields("", doc, 1)
+}
+
+// addFields adds fields of the given document with the given path prefix.
+func (cs *collectionStats) addFields(prefix string, doc *wirebson.Document, depth int) error {
+	for k, v := range doc.All() {
+		path := prefix + k
+
+		fs := cs.fields[path]
+		if fs == nil {
+			if len(cs.fields) >= maxAnalyzedFields {
+				continue
+			}
+
+			fs = &fieldStats{
+				path:   path,
+				types:  map[string]int64{},
+				values: map[uint64]int64{},
+			}
+			cs.fields[path] = fs
+		}
+
+		fs.present++
+		fs.types[aliasFromType(v)]++
+
+		var raw []byte
+
+		switch v := v.(type) {
+		case *wirebson.Document:
+			if depth < maxAnalyzedDepth {
+				if err := cs.addFields(path+".", v, depth+1); err != nil {
+					return err
+				}
+			}
+
+			b, err := v.Encode()
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			raw = b
+
+		case *wirebson.Array:
+			b, err := v.Encode()
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			raw = b
+
+		default:
+			b, err := wirebson.MustDocument("", v).Encode()
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			raw = b
+		}
+
+		fs.values[maphash.Bytes(cs.seed, raw)]++
+	}
+
+	return nil
+}
+
+// document returns statistics as a document for the collection of the given estimated size.
+func (cs *collectionStats) document(total int64, sampledAt time.Time) *wirebson.Document {
+	fields := wirebson.MakeArray(len(cs.fields))
+
+	for _, path := range slices.Sorted(maps.Keys(cs.fields)) {
+		fs := cs.fields[path]
+
+		types := wirebson.MakeDocument(len(fs.types))
+		for _, t := range slices.Sorted(maps.Keys(fs.types)) {
+			must.NoError(types.Add(t, fs.types[t]))
+		}
+
+		fieldTotal := total
+		if cs.n > 0 {
+			fieldTotal = int64(math.Round(float64(total) * float64(fs.present) / float64(cs.n)))
+		}
+
+		distinct := fs.distinct(fieldTotal)
+
+		var selectivity float64
+		if distinct > 0 {
+			selectivity = 1 / distinct
+		}
+
+		must.NoError(fields.Add(wirebson.MustDocument(
+			"path", path,
+			"present", fs.present,
+			"types", types,
+			"sampleDistinct", int64(len(fs.values)),
+			"distinct", int64(distinct),
+			"selectivity", selectivity,
+		)))
+	}
+
+	return wirebson.MustDocument(
+		"sampledAt", sampledAt,
+		"sampleSize", cs.n,
+		"count", total,
+		"fields", fields,
+	)
+}
+
+// fieldCardinalities returns estimated numbers of distinct values by field path
+// stored by `ferretAnalyze` command for the given collection.
+// It returns nil if there are no statistics.
+func (h *Handler) fieldCardinalities(ctx context.Context, dbName, collection string) (map[string]float64, error) {
+	var md *wirebson.Document
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		md, err = documentdb.Metadata(ctx, conn, dbName, collection, fieldStatsMetadata)
+
+		return err
+	})
+	if err != nil || md == nil {
+		return nil, err
+	}
+
+	fields, _ := md.Get("fields").(*wirebson.Array)
+	if fields == nil {
+		return nil, nil
+	}
+
+	res := make(map[string]float64, fields.Len())
+
+	for v := range fields.Values() {
+		f, _ := v.(*wirebson.Document)
+		if f == nil {
+			continue
+		}
+
+		path, _ := f.Get("path").(string)
+		distinct, _ := f.Get("distinct").(int64)
+		res[path] = float64(distinct)
+	}
+
+	return res, nil
+}
+
+// sortBySelectivity sorts the given fields by the estimated number of distinct values, the largest first.
+// Fields without statistics keep their order after fields with statistics.
+func sortBySelectivity(fields []string, cardinalities map[string]float64) {
+	if len(cardinalities) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(fields, func(a, b string) int {
+		return cmp.Compare(cardinalities[b], cardinalities[a])
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectionStats(t *testing.T) {
+	t.Parallel()
+
+	cs := newCollectionStats()
+
+	for i := range 100 {
+		doc := wirebson.MustDocument(
+			"_id", int32(i),
+			"status", []string{"active", "inactive"}[i%2],
+			"address", wirebson.MustDocument("city", "Berlin"),
+		)
+
+		if i%10 == 0 {
+			require.NoError(t, doc.Add("note", int64(i)))
+		}
+
+		require.NoError(t, cs.add(doc))
+	}
+
+	stats := cs.document(1000, time.Now())
+	assert.Equal(t, int64(100), stats.Get("sampleSize"))
+	assert.Equal(t, int64(1000), stats.Get("count"))
+
+	fields := map[string]*wirebson.Document{}
+
+	for v := range stats.Get("fields").(*wirebson.Array).Values() {
+		f := v.(*wirebson.Document)
+		fields[f.Get("path").(string)] = f
+	}
+
+	require.Contains(t, fields, "address.city")
+
+	assert.Equal(t, int64(1000), fields["_id"].Get("distinct"), "unique values")
+	assert.Equal(t, int64(2), fields["status"].Get("distinct"))
+	assert.Equal(t, int64(1), fields["address.city"].Get("distinct"))
+
+	assert.Equal(t, int64(10), fields["note"].Get("present"))
+	assert.Equal(t, int64(100), fields["note"].Get("distinct"), "unique values of documents with that field")
+	assert.Equal(t, int64(10), fields["note"].Get("types").(*wirebson.Document).Get("long"))
+}
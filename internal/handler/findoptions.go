@@ -0,0 +1,392 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// findOptions contains `find` command options implemented by FerretDB itself.
+// They are applied to the first batch and to batches returned by `getMore`.
+//
+// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/242
+type findOptions struct {
+	db           string
+	collection   string
+	keyPattern   *wirebson.Document // hinted index key pattern, nil if unknown
+	masks        fieldMasks         // nil if nothing is masked
+	returnKey    bool
+	showRecordID bool
+}
+
+// findOptionsFields contains `find` command fields handled by [Handler.findOptions].
+var findOptionsFields = []string{"returnKey", "showRecordId", "min", "max"}
+
+// findOptions validates `returnKey`, `showRecordId`, `min`, and `max` fields of the given `find` command,
+// removes them, and adds `min`/`max` index bounds to the filter.
+// The command should be decoded deeply.
+//
+// It returns nil if documents should be returned as is.
+func (h *Handler) findOptions(ctx context.Context, dbName string, doc *wirebson.Document) (*findOptions, error) {
+	opts := &findOptions{db: dbName}
+	opts.collection, _ = doc.Get("find").(string)
+
+	var err error
+
+	if opts.returnKey, err = findBoolOption(doc, "returnKey"); err != nil {
+		return nil, err
+	}
+
+	if opts.showRecordID, err = findBoolOption(doc, "showRecordId"); err != nil {
+		return nil, err
+	}
+
+	var bounds [2]*wirebson.Document
+
+	for i, name := range []string{"min", "max"} {
+		v := doc.Get(name)
+		if v == nil {
+			continue
+		}
+
+		d, ok := v.(*wirebson.Document)
+		if !ok {
+			msg := fmt.Sprintf("BSON field 'find.%s' is the wrong type '%s', expected type 'object'", name, aliasFromType(v))
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, "find")
+		}
+
+		if d.Len() > 0 {
+			bounds[i] = d
+		}
+	}
+
+	if (opts.returnKey || bounds[0] != nil || bounds[1] != nil) && doc.Get("hint") != nil {
+		if opts.keyPattern, err = h.hintKeyPattern(ctx, dbName, opts.collection, doc.Get("hint")); err != nil {
+			return nil, err
+		}
+	}
+
+	if bounds[0] != nil || bounds[1] != nil {
+		if opts.keyPattern == nil {
+			return nil, mongoerrors.NewWithArgument(
+				mongoerrors.ErrLocation51173,
+				"When using min()/max() a hint of which index to use must be specified",
+				"find",
+			)
+		}
+
+		var exprs []any
+
+		for i, bound := range bounds {
+			if bound == nil {
+				continue
+			}
+
+			expr, ok := indexBoundExpr(opts.keyPattern, bound, i == 0)
+			if !ok {
+				return nil, mongoerrors.NewWithArgument(
+					mongoerrors.ErrLocation51174,
+					"The index chosen is not equal to min/max bounds",
+					"find",
+				)
+			}
+
+			exprs = append(exprs, expr)
+		}
+
+		boundsFilter := must.NotFail(wirebson.NewDocument(
+			"$expr", must.NotFail(wirebson.NewDocument("$and", must.NotFail(wirebson.NewArray(exprs...)))),
+		))
+
+		filter := boundsFilter
+		if f, _ := doc.Get("filter").(*wirebson.Document); f != nil && f.Len() > 0 {
+			filter = must.NotFail(wirebson.NewDocument("$and", must.NotFail(wirebson.NewArray(f, boundsFilter))))
+		}
+
+		if doc.Get("filter") == nil {
+			must.NoError(doc.Add("filter", filter))
+		} else {
+			must.NoError(doc.Replace("filter", filter))
+		}
+	}
+
+	for _, f := range findOptionsFields {
+		doc.Remove(f)
+	}
+
+	if !opts.returnKey && !opts.showRecordID {
+		return nil, nil
+	}
+
+	// projection is ignored, as keys are taken from the whole document
+	if opts.returnKey {
+		doc.Remove("projection")
+	}
+
+	return opts, nil
+}
+
+// findBoolOption returns the value of the given boolean `find` command option, false if it is not set.
+func findBoolOption(doc *wirebson.Document, name string) (bool, error) {
+	v := doc.Get(name)
+	if v == nil {
+		return false, nil
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		msg := fmt.Sprintf("Field '%s' should be a boolean value, but found: %s", name, aliasFromType(v))
+		return false, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, "find")
+	}
+
+	return b, nil
+}
+
+// hintKeyPattern returns the key pattern of the index specified by the given hint.
+// It returns nil for `$natural` hint.
+func (h *Handler) hintKeyPattern(ctx context.Context, dbName, collection string, hint any) (*wirebson.Document, error) {
+	switch hint := hint.(type) {
+	case *wirebson.Document:
+		// `$natural` hint means a collection scan
+		if hint.Len() == 0 || strings.HasPrefix(hint.FieldNames()[0], "$") {
+			return nil, nil
+		}
+
+		return hint, nil
+
+	case string:
+		keyPatterns, err := h.indexKeyPatterns(ctx, dbName, collection)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if keyPattern := keyPatterns[hint]; keyPattern != nil {
+			return keyPattern, nil
+		}
+	}
+
+	return nil, mongoerrors.NewWithArgument(
+		mongoerrors.ErrBadValue,
+		"hint provided does not correspond to an existing index",
+		"find",
+	)
+}
+
+// indexBoundExpr returns an aggregation expression that matches documents
+// at or after (if lower is true) or before the given bound in the index order.
+// Like the index order, it uses the total BSON order of values.
+//
+// It returns false if the bound fields do not match the key pattern.
+func indexBoundExpr(keyPattern, bound *wirebson.Document, lower bool) (*wirebson.Document, bool) {
+	fields := keyPattern.FieldNames()
+	if slices.Compare(fields, bound.FieldNames()) != 0 {
+		return nil, false
+	}
+
+	// (f1 op v1) or (f1 == v1 and f2 op v2) or ... or (f1 == v1 and ... fn op= vn)
+	or := wirebson.MakeArray(len(fields))
+
+	for i, f := range fields {
+		and := wirebson.MakeArray(i + 1)
+
+		for _, prev := range fields[:i] {
+			must.NoError(and.Add(boundCmp("$eq", prev, bound.Get(prev))))
+		}
+
+		var descending bool
+
+		switch dir := keyPattern.Get(f).(type) {
+		case int32:
+			descending = dir < 0
+		case int64:
+			descending = dir < 0
+		case float64:
+			descending = dir < 0
+		default:
+			return nil, false
+		}
+
+		op := "$gt"
+		if lower == descending {
+			op = "$lt"
+		}
+
+		// min is inclusive, max is exclusive
+		if i == len(fields)-1 && lower {
+			op += "e"
+		}
+
+		must.NoError(and.Add(boundCmp(op, f, bound.Get(f))))
+		must.NoError(or.Add(must.NotFail(wirebson.NewDocument("$and", and))))
+	}
+
+	return must.NotFail(wirebson.NewDocument("$or", or)), true
+}
+
+// boundCmp returns a comparison expression for the given field and literal value.
+func boundCmp(op, field string, v any) *wirebson.Document {
+	return must.NotFail(wirebson.NewDocument(
+		op, must.NotFail(wirebson.NewArray("$"+field, must.NotFail(wirebson.NewDocument("$literal", v)))),
+	))
+}
+
+// applyFindOptions applies the given options to documents of the `find` or `getMore` response page.
+func (h *Handler) applyFindOptions(ctx context.Context, opts *findOptions, page wirebson.AnyDocument) (*wirebson.Document, error) { //nolint:lll // for readability
+	res, err := page.Decode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	c, _ := res.Get("cursor").(wirebson.AnyDocument)
+	if c == nil {
+		return nil, lazyerrors.Errorf("no cursor in the page: %s", res.LogMessage())
+	}
+
+	cursor, err := c.Decode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	batchField := "firstBatch"
+	if cursor.Get(batchField) == nil {
+		batchField = "nextBatch"
+	}
+
+	b, _ := cursor.Get(batchField).(wirebson.AnyArray)
+	if b == nil {
+		return nil, lazyerrors.Errorf("no batch in the page: %s", res.LogMessage())
+	}
+
+	raw, err := b.Encode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	batch, err := raw.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var recordIDs map[string]int64
+
+	if opts.showRecordID && batch.Len() > 0 {
+		if recordIDs, err = h.recordIDs(ctx, opts, batch); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	for i, v := range batch.All() {
+		doc, _ := v.(*wirebson.Document)
+		if doc == nil {
+			continue
+		}
+
+		out := doc
+
+		if opts.returnKey {
+			out = returnKeyValue(opts.keyPattern, doc)
+		}
+
+		opts.masks.apply(out)
+
+		if id := doc.Get("_id"); id != nil && opts.showRecordID {
+			objectID := must.NotFail(must.NotFail(wirebson.NewDocument("", id)).Encode())
+
+			if recordID, ok := recordIDs[string(objectID)]; ok {
+				must.NoError(out.Add("$recordId", recordID))
+			}
+		}
+
+		must.NoError(batch.Replace(i, out))
+	}
+
+	must.NoError(cursor.Replace(batchField, batch))
+	must.NoError(res.Replace("cursor", cursor))
+
+	return res, nil
+}
+
+// recordIDs returns record IDs of the given documents by their `{"": <_id>}` object IDs.
+func (h *Handler) recordIDs(ctx context.Context, opts *findOptions, batch *wirebson.Array) (map[string]int64, error) {
+	objectIDs := make([]wirebson.RawDocument, 0, batch.Len())
+
+	for v := range batch.Values() {
+		if doc, _ := v.(*wirebson.Document); doc != nil && doc.Get("_id") != nil {
+			objectIDs = append(objectIDs, must.NotFail(must.NotFail(wirebson.NewDocument("", doc.Get("_id"))).Encode()))
+		}
+	}
+
+	var res map[string]int64
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		res, err = documentdb.RecordIDs(ctx, conn, opts.db, opts.collection, objectIDs)
+
+		return err
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// returnKeyValue returns values of the document for the given index key pattern,
+// as returned by `find` with `returnKey` option.
+// Missing values are null; arrays are returned as is.
+//
+// Without a known index, it returns an empty document, as MongoDB does for a collection scan.
+func returnKeyValue(keyPattern, doc *wirebson.Document) *wirebson.Document {
+	if keyPattern == nil {
+		return wirebson.MakeDocument(1)
+	}
+
+	res := wirebson.MakeDocument(keyPattern.Len() + 1)
+
+	for path := range keyPattern.Fields() {
+		var v any = doc
+
+	walk:
+		for _, f := range strings.Split(path, ".") {
+			switch d := v.(type) {
+			case *wirebson.Document:
+				v = d.Get(f)
+			case *wirebson.Array:
+				break walk
+			default:
+				v = nil
+			}
+		}
+
+		if v == nil {
+			v = wirebson.Null
+		}
+
+		must.NoError(res.Add(path, v))
+	}
+
+	return res
+}
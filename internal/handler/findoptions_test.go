@@ -0,0 +1,98 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestIndexBoundExpr(t *testing.T) {
+	t.Parallel()
+
+	keyPattern := must.NotFail(wirebson.NewDocument("a", int32(1), "b", int32(-1)))
+	bound := must.NotFail(wirebson.NewDocument("a", int32(1), "b", "$x"))
+
+	cmp := func(op, field string, v any) *wirebson.Document {
+		return must.NotFail(wirebson.NewDocument(
+			op, must.NotFail(wirebson.NewArray("$"+field, must.NotFail(wirebson.NewDocument("$literal", v)))),
+		))
+	}
+
+	and := func(exprs ...any) *wirebson.Document {
+		return must.NotFail(wirebson.NewDocument("$and", must.NotFail(wirebson.NewArray(exprs...))))
+	}
+
+	or := func(exprs ...any) *wirebson.Document {
+		return must.NotFail(wirebson.NewDocument("$or", must.NotFail(wirebson.NewArray(exprs...))))
+	}
+
+	actual, ok := indexBoundExpr(keyPattern, bound, true)
+	require.True(t, ok)
+
+	expected := or(
+		and(cmp("$gt", "a", int32(1))),
+		and(cmp("$eq", "a", int32(1)), cmp("$lte", "b", "$x")),
+	)
+	assert.Equal(t, expected.LogMessage(), actual.LogMessage())
+
+	actual, ok = indexBoundExpr(keyPattern, bound, false)
+	require.True(t, ok)
+
+	expected = or(
+		and(cmp("$lt", "a", int32(1))),
+		and(cmp("$eq", "a", int32(1)), cmp("$gt", "b", "$x")),
+	)
+	assert.Equal(t, expected.LogMessage(), actual.LogMessage())
+
+	_, ok = indexBoundExpr(keyPattern, must.NotFail(wirebson.NewDocument("b", int32(1), "a", int32(1))), true)
+	assert.False(t, ok)
+
+	_, ok = indexBoundExpr(must.NotFail(wirebson.NewDocument("a", "text")), must.NotFail(wirebson.NewDocument("a", "x")), true)
+	assert.False(t, ok)
+}
+
+func TestReturnKeyValue(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(wirebson.NewDocument(
+		"_id", int32(1),
+		"v", int32(42),
+		"foo", must.NotFail(wirebson.NewDocument("bar", "baz")),
+		"arr", must.NotFail(wirebson.NewArray(int32(1), int32(2))),
+	))
+
+	keyPattern := must.NotFail(wirebson.NewDocument(
+		"foo.bar", int32(1),
+		"missing", int32(1),
+		"v.missing", int32(-1),
+		"arr", int32(1),
+	))
+
+	expected := must.NotFail(wirebson.NewDocument(
+		"foo.bar", "baz",
+		"missing", wirebson.Null,
+		"v.missing", wirebson.Null,
+		"arr", must.NotFail(wirebson.NewArray(int32(1), int32(2))),
+	))
+	assert.Equal(t, expected.LogMessage(), returnKeyValue(keyPattern, doc).LogMessage())
+
+	assert.Equal(t, 0, returnKeyValue(nil, doc).Len())
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// fsyncLock implements a global write-blocking mode set by `fsync` command with `lock: true`.
+//
+// While it is locked, new writes wait until it is fully unlocked by `fsyncUnlock` commands.
+// Locking waits for in-progress writes to finish.
+//
+// The zero value is unlocked and ready to use.
+type fsyncLock struct {
+	mu       sync.Mutex
+	count    int32         // number of fsync locks held
+	writes   int           // number of in-progress writes
+	unlocked chan struct{} // closed when count drops to zero
+	idle     chan struct{} // closed when writes drops to zero
+}
+
+// Lock increments the lock count and waits for in-progress writes to finish.
+// It returns the new lock count.
+//
+// If ctx is canceled while waiting, the lock count is decremented back, and the error is returned.
+func (l *fsyncLock) Lock(ctx context.Context) (int32, error) {
+	l.mu.Lock()
+
+	if l.count == 0 {
+		l.unlocked = make(chan struct{})
+	}
+
+	l.count++
+
+	for l.writes > 0 {
+		if l.idle == nil {
+			l.idle = make(chan struct{})
+		}
+
+		idle := l.idle
+		l.mu.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			l.Unlock()
+			return 0, lazyerrors.Error(context.Cause(ctx))
+		}
+
+		l.mu.Lock()
+	}
+
+	count := l.count
+	l.mu.Unlock()
+
+	return count, nil
+}
+
+// Unlock decrements the lock count and returns the new value.
+// It returns false if it was not locked.
+func (l *fsyncLock) Unlock() (int32, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.count == 0 {
+		return 0, false
+	}
+
+	l.count--
+
+	if l.count == 0 {
+		close(l.unlocked)
+		l.unlocked = nil
+	}
+
+	return l.count, true
+}
+
+// Count returns the current lock count.
+func (l *fsyncLock) Count() int32 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.count
+}
+
+// startWrite waits until the lock is released and registers an in-progress write.
+// The returned function should be called when the write is finished.
+func (l *fsyncLock) startWrite(ctx context.Context) (func(), error) {
+	for {
+		l.mu.Lock()
+
+		if l.count == 0 {
+			l.writes++
+			l.mu.Unlock()
+
+			return l.endWrite, nil
+		}
+
+		unlocked := l.unlocked
+		l.mu.Unlock()
+
+		select {
+		case <-unlocked:
+		case <-ctx.Done():
+			return nil, lazyerrors.Error(context.Cause(ctx))
+		}
+	}
+}
+
+// endWrite unregisters an in-progress write.
+func (l *fsyncLock) endWrite() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.writes--
+
+	if l.writes == 0 && l.idle != nil {
+		close(l.idle)
+		l.idle = nil
+	}
+}
+
+// blockedByFsyncLock is a middleware that wraps the write command handler
+// with a wait for the fsync lock release.
+func blockedByFsyncLock(next middleware.HandleFunc, l *fsyncLock) middleware.HandleFunc {
+	return func(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+		done, err := l.startWrite(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		defer done()
+
+		return next(ctx, req)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFsyncLock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var l fsyncLock
+
+	_, ok := l.Unlock()
+	assert.False(t, ok)
+
+	done, err := l.startWrite(ctx)
+	require.NoError(t, err)
+
+	locked := make(chan int32)
+
+	go func() {
+		count, lockErr := l.Lock(ctx)
+		assert.NoError(t, lockErr)
+		locked <- count
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock acquired while write is in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	done()
+	assert.Equal(t, int32(1), <-locked)
+
+	writeCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	_, err = l.startWrite(writeCtx)
+	require.Error(t, err)
+
+	count, ok := l.Unlock()
+	assert.True(t, ok)
+	assert.Equal(t, int32(0), count)
+
+	done, err = l.startWrite(ctx)
+	require.NoError(t, err)
+	done()
+}
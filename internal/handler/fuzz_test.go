@@ -0,0 +1,102 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// FuzzValidation feeds arbitrary documents through command validation
+// that runs before requests are sent to DocumentDB.
+// Invalid input should produce protocol errors, not panics or internal errors.
+func FuzzValidation(f *testing.F) {
+	for _, doc := range []*wirebson.Document{
+		wirebson.MustDocument(
+			"find", "values",
+			"projection", wirebson.MustDocument("v.$", true, "foo", int32(0)),
+			"sort", wirebson.MustDocument("v", int32(1), "$natural", int32(-1)),
+		),
+		wirebson.MustDocument(
+			"update", wirebson.MustDocument(
+				"$currentDate", wirebson.MustDocument("v", wirebson.MustDocument("$type", "timestamp")),
+				"$rename", wirebson.MustDocument("v", "w"),
+			),
+		),
+		wirebson.MustDocument(
+			"create", "values",
+			"storageEngine", wirebson.MustDocument("postgresql", wirebson.MustDocument("compression", "lz4")),
+		),
+		wirebson.MustDocument(
+			"endSessions", wirebson.MustArray(wirebson.MustDocument("id", wirebson.Binary{Subtype: wirebson.BinaryUUID})),
+			"lsid", wirebson.MustDocument("id", wirebson.Binary{Subtype: wirebson.BinaryUUID, B: make([]byte, 16)}),
+		),
+	} {
+		f.Add([]byte(must.NotFail(doc.Encode())))
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		t.Parallel()
+
+		doc, err := wirebson.RawDocument(b).DecodeDeep()
+		if err != nil {
+			return
+		}
+
+		command := doc.Command()
+
+		check := func(err error) {
+			t.Helper()
+
+			if err == nil {
+				return
+			}
+
+			_, ok := err.(*mongoerrors.Error) //nolint:errorlint // do not inspect error chain
+			require.True(t, ok, "expected protocol error, got %v", err)
+		}
+
+		for _, v := range []any{doc.Get("projection"), doc.Get("fields")} {
+			if v != nil {
+				check(validateProjection(v))
+			}
+		}
+
+		if v := doc.Get("sort"); v != nil {
+			check(validateSort(v))
+		}
+
+		if v := doc.Get("update"); v != nil {
+			check(validateCurrentDate(v))
+			check(validateRename(v))
+		}
+
+		_, err = getCompressionParam(doc, command)
+		check(err)
+
+		_, err = getSessionIDsParam(doc, command)
+		check(err)
+
+		if v := doc.Get("lsid"); v != nil {
+			_, _, err = getLSIDParam(v, command, "lsid")
+			check(err)
+		}
+	})
+}
@@ -18,9 +18,13 @@
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/v2/bson"
 
 	"github.com/FerretDB/FerretDB/v2/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
@@ -59,6 +63,33 @@ type Handler struct {
 	*NewOpts
 	commands map[string]*command
 	s        *session.Registry
+
+	// instanceID identifies this instance in messages sent to other instances.
+	instanceID string
+
+	// clusterNonces rejects replayed messages received from other instances.
+	clusterNonces clusterNonces
+
+	// processID identifies this instance in `topologyVersion` of `hello` responses.
+	processID wirebson.ObjectID
+
+	params      map[string]*parameter
+	paramValues parameterValues
+
+	fsync        fsyncLock
+	migrations   migrations
+	indexBuilds  indexBuilds
+	failPoints   failPoints
+	indexAdvisor indexAdvisor
+	indexStats   indexStats
+	top          top
+
+	materializedViews materializedViews
+	intermediates     intermediates
+	defaultCollations defaultCollations
+	accessPolicies    accessPolicyCache
+	queryCosts        queryCostCache
+	resultCache       *resultCache
 }
 
 // NewOpts represents handler configuration.
@@ -76,6 +107,39 @@ type NewOpts struct {
 	StateProvider *state.Provider
 
 	SessionCleanupInterval time.Duration
+
+	// LogLevel allows changing the log level with the `logLevel` server parameter.
+	// If nil, it can't be changed.
+	LogLevel *slog.LevelVar
+
+	// LogRotate rotates the log file with the `logRotate` command.
+	// If nil, logs are not written to a file, and that command does nothing.
+	LogRotate func() error
+
+	// Shutdown starts the graceful shutdown of the whole instance with the `shutdown` command.
+	// If nil, that command is not supported.
+	Shutdown func()
+
+	// TelemetryPayload returns the telemetry report payload for the `ferretTelemetry` command.
+	// If nil, that command is not supported.
+	TelemetryPayload func() ([]byte, error)
+
+	// Parameters contains server parameters set at startup.
+	Parameters map[string]string
+
+	// CmdLineArgv contains command-line arguments returned by the `getCmdLineOpts` command.
+	// Secrets should be redacted.
+	CmdLineArgv []string
+
+	// CmdLineParsed contains the effective configuration returned by the `getCmdLineOpts` command.
+	// Secrets should be redacted. If nil, an empty document is returned.
+	CmdLineParsed *wirebson.Document
+
+	// ClusterKey is the shared secret of FerretDB instances using the same PostgreSQL.
+	// If set, instances coordinate with each other (for example, to kill sessions and cursors
+	// and reset cached access policies) using signed PostgreSQL notifications.
+	// If empty, that coordination is disabled.
+	ClusterKey []byte
 }
 
 // New returns a new handler.
@@ -91,9 +155,19 @@ func New(opts *NewOpts) (*Handler, error) {
 	h := &Handler{
 		NewOpts: opts,
 		s:       session.NewRegistry(sessionTimeout, opts.L),
+
+		instanceID: uuid.NewString(),
+		processID:  wirebson.ObjectID(bson.NewObjectID()),
+
+		resultCache: newResultCache(),
 	}
 
 	h.initCommands()
+	h.initParameters()
+
+	if err := h.setStartupParameters(opts.Parameters); err != nil {
+		return nil, err
+	}
 
 	return h, nil
 }
@@ -102,16 +176,33 @@ func New(opts *NewOpts) (*Handler, error) {
 //
 // When this method returns, handler is stopped and pool is closed.
 func (h *Handler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		h.runHealthChecks(ctx)
+	}()
+
+	if len(h.ClusterKey) > 0 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			h.runCluster(ctx)
+		}()
+	}
+
 	defer func() {
+		wg.Wait()
+		h.migrations.abortAll()
 		h.s.Stop()
 		h.Pool.Close()
 		h.L.InfoContext(ctx, "Handler stopped")
 	}()
 
-	sessionCleanupInterval := h.SessionCleanupInterval
-	if sessionCleanupInterval == 0 {
-		sessionCleanupInterval = time.Minute
-	}
+	sessionCleanupInterval := h.sessionCleanupInterval()
 
 	ticker := time.NewTicker(sessionCleanupInterval)
 
@@ -129,6 +220,24 @@ func (h *Handler) Run(ctx context.Context) {
 			for _, cursorID := range cursorIDs {
 				_ = h.Pool.KillCursor(ctx, cursorID)
 			}
+
+			_ = h.Pool.KillIdleCursors(ctx, h.cursorTimeout())
+
+			if h.paramValues.sharedCursors.Load() {
+				if err := h.Pool.DeleteIdleSharedCursors(ctx, h.cursorTimeout()); err != nil {
+					h.L.WarnContext(ctx, "Failed to delete idle shared cursors", logging.Error(err))
+				}
+			}
+
+			h.refreshScheduledViews(ctx)
+			h.dropExpiredIntermediates(ctx)
+			h.persistIndexStats(ctx)
+
+			// the interval could be changed with the server parameter
+			if d := h.sessionCleanupInterval(); d != sessionCleanupInterval {
+				sessionCleanupInterval = d
+				ticker.Reset(d)
+			}
 		}
 	}
 }
@@ -146,7 +255,16 @@ func (h *Handler) Handle(ctx context.Context, req *middleware.Request) (*middlew
 
 		cmd, ok := h.commands[msgCmd]
 		if ok && cmd.handler != nil {
-			return cmd.handler(ctx, req)
+			start := time.Now()
+
+			res, err := cmd.handler(ctx, req)
+			if err != nil {
+				res, err = h.retryAfterFailover(ctx, cmd, req, doc, res, err)
+			}
+
+			h.top.record(doc, cmd.write, time.Since(start))
+
+			return res, err
 		}
 
 		return notFound(msgCmd)(ctx, req)
@@ -161,12 +279,14 @@ func (h *Handler) Handle(ctx context.Context, req *middleware.Request) (*middlew
 func (h *Handler) Describe(ch chan<- *prometheus.Desc) {
 	h.Pool.Describe(ch)
 	h.s.Describe(ch)
+	h.resultCache.Describe(ch)
 }
 
 // Collect implements [prometheus.Collector].
 func (h *Handler) Collect(ch chan<- prometheus.Metric) {
 	h.Pool.Collect(ch)
 	h.s.Collect(ch)
+	h.resultCache.Collect(ch)
 }
 
 // check interfaces
@@ -0,0 +1,472 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+const (
+	// indexAdvisorInterval is the minimal interval between analyses of the same query shape.
+	indexAdvisorInterval = time.Minute
+
+	// maxIndexSuggestions is the maximal number of kept index suggestions.
+	maxIndexSuggestions = 100
+
+	// maxAnalyzedShapes is the maximal number of query shapes remembered as recently analyzed.
+	maxAnalyzedShapes = 10000
+)
+
+// indexSuggestion represents an index that would speed up a query shape executed with a collection scan.
+//
+//nolint:vet // for readability
+type indexSuggestion struct {
+	db         string
+	collection string
+	shape      *wirebson.Document
+	key        *wirebson.Document
+	scanRatio  float64
+	count      int64
+	firstSeen  time.Time
+	lastSeen   time.Time
+}
+
+// indexAdvisor tracks query shapes executed with collection scans and suggests indexes for them.
+//
+// The zero value is ready to use.
+type indexAdvisor struct {
+	running atomic.Bool // analysis is in progress
+
+	mu          sync.Mutex
+	suggestions map[string]*indexSuggestion // by namespace and shape
+	analyzed    map[string]time.Time        // last analysis time by namespace and shape
+}
+
+// seen updates the suggestion for the given query shape if there is one,
+// and returns true if that shape should be analyzed now.
+func (a *indexAdvisor) seen(id string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if s := a.suggestions[id]; s != nil {
+		s.count++
+		s.lastSeen = now
+	}
+
+	if t, ok := a.analyzed[id]; ok && now.Sub(t) < indexAdvisorInterval {
+		return false
+	}
+
+	if a.analyzed == nil || len(a.analyzed) >= maxAnalyzedShapes {
+		a.analyzed = make(map[string]time.Time)
+	}
+
+	a.analyzed[id] = now
+
+	return true
+}
+
+// suggest adds or updates the suggestion for the given query shape.
+// It returns true if the suggestion is new.
+func (a *indexAdvisor) suggest(id string, s *indexSuggestion) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if old := a.suggestions[id]; old != nil {
+		old.key = s.key
+		old.scanRatio = s.scanRatio
+		old.lastSeen = s.lastSeen
+
+		return false
+	}
+
+	if a.suggestions == nil {
+		a.suggestions = make(map[string]*indexSuggestion)
+	}
+
+	if len(a.suggestions) >= maxIndexSuggestions {
+		var oldest string
+
+		for id, s := range a.suggestions {
+			if oldest == "" || s.lastSeen.Before(a.suggestions[oldest].lastSeen) {
+				oldest = id
+			}
+		}
+
+		delete(a.suggestions, oldest)
+	}
+
+	a.suggestions[id] = s
+
+	return true
+}
+
+// remove removes the suggestion for the given query shape, if any.
+func (a *indexAdvisor) remove(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.suggestions, id)
+}
+
+// list returns suggestions for the given database (or all databases if empty),
+// the most frequent first.
+func (a *indexAdvisor) list(db string) []indexSuggestion {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	res := make([]indexSuggestion, 0, len(a.suggestions))
+
+	for _, s := range a.suggestions {
+		if db == "" || s.db == db {
+			res = append(res, *s)
+		}
+	}
+
+	slices.SortFunc(res, func(a, b indexSuggestion) int {
+		return cmp.Or(
+			-cmp.Compare(a.count, b.count),
+			cmp.Compare(a.db, b.db),
+			cmp.Compare(a.collection, b.collection),
+			cmp.Compare(a.shape.LogMessage(), b.shape.LogMessage()),
+		)
+	})
+
+	return res
+}
+
+// queryShape returns the given filter with values replaced by "?".
+// Field names and operators are kept.
+func queryShape(filter *wirebson.Document) *wirebson.Document {
+	res := wirebson.MakeDocument(filter.Len())
+
+	for f, v := range filter.All() {
+		switch f {
+		case "$and", "$or", "$nor":
+			if arr, ok := v.(*wirebson.Array); ok {
+				shapes := wirebson.MakeArray(arr.Len())
+
+				for e := range arr.Values() {
+					if d, ok := e.(*wirebson.Document); ok {
+						must.NoError(shapes.Add(queryShape(d)))
+					} else {
+						must.NoError(shapes.Add("?"))
+					}
+				}
+
+				must.NoError(res.Add(f, shapes))
+
+				continue
+			}
+		}
+
+		if d, ok := v.(*wirebson.Document); ok && isOperatorDocument(d) {
+			must.NoError(res.Add(f, queryShape(d)))
+			continue
+		}
+
+		must.NoError(res.Add(f, "?"))
+	}
+
+	return res
+}
+
+// isOperatorDocument returns true if the given filter value is a document of query operators.
+func isOperatorDocument(d *wirebson.Document) bool {
+	if d.Len() == 0 {
+		return false
+	}
+
+	for f := range d.All() {
+		if len(f) == 0 || f[0] != '$' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// suggestedIndexKey returns the index key that would serve the given filter and sort,
+// following the equality, sort, range rule.
+// Equality fields are ordered by the given estimated numbers of distinct values, if any, the most selective first.
+// It returns nil if no index could be suggested.
+func suggestedIndexKey(filter, sort *wirebson.Document, cardinalities map[string]float64) *wirebson.Document {
+	var equality, ranges []string
+
+	var collect func(filter *wirebson.Document)
+	collect = func(filter *wirebson.Document) {
+		for f, v := range filter.All() {
+			if f == "$and" {
+				arr, _ := v.(*wirebson.Array)
+				if arr == nil {
+					continue
+				}
+
+				for e := range arr.Values() {
+					if d, ok := e.(*wirebson.Document); ok {
+						collect(d)
+					}
+				}
+
+				continue
+			}
+
+			// $or, $nor, $expr, $where, $text, etc. are not considered
+			if len(f) == 0 || f[0] == '$' || f == "_id" {
+				continue
+			}
+
+			switch v := v.(type) {
+			case *wirebson.Document:
+				if !isOperatorDocument(v) {
+					equality = append(equality, f)
+					continue
+				}
+
+				for op := range v.All() {
+					switch op {
+					case "$eq", "$in":
+						equality = append(equality, f)
+					case "$gt", "$gte", "$lt", "$lte", "$regex":
+						ranges = append(ranges, f)
+					}
+				}
+
+			case wirebson.Regex:
+				ranges = append(ranges, f)
+
+			default:
+				equality = append(equality, f)
+			}
+		}
+	}
+
+	collect(filter)
+	sortBySelectivity(equality, cardinalities)
+
+	res := wirebson.MakeDocument(0)
+
+	add := func(f string, v int32) {
+		if res.Get(f) == nil {
+			must.NoError(res.Add(f, v))
+		}
+	}
+
+	for _, f := range equality {
+		add(f, 1)
+	}
+
+	if sort != nil {
+		for f, v := range sort.All() {
+			if len(f) == 0 || f[0] == '$' {
+				continue
+			}
+
+			dir := int32(1)
+
+			switch v := v.(type) {
+			case int32:
+				dir = int32(cmp.Compare(v, 0))
+			case int64:
+				dir = int32(cmp.Compare(v, 0))
+			case float64:
+				dir = int32(cmp.Compare(v, 0))
+			}
+
+			if dir != 0 {
+				add(f, dir)
+			}
+		}
+	}
+
+	for _, f := range ranges {
+		add(f, 1)
+	}
+
+	if res.Len() == 0 {
+		return nil
+	}
+
+	return res
+}
+
+// explainPlanNode represents a node of PostgreSQL EXPLAIN (FORMAT JSON) output.
+type explainPlanNode struct {
+	NodeType     string            `json:"Node Type"`
+	RelationName string            `json:"Relation Name"`
+	PlanRows     float64           `json:"Plan Rows"`
+	Plans        []explainPlanNode `json:"Plans"`
+}
+
+// seqScan returns the sequential scan node of the collection table, if any.
+func (n *explainPlanNode) seqScan() *explainPlanNode {
+	if n.NodeType == "Seq Scan" && n.RelationName != "" {
+		return n
+	}
+
+	for i := range n.Plans {
+		if s := n.Plans[i].seqScan(); s != nil {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// adviseIndex analyzes the given `find` command in the background if the index advisor is enabled.
+//
+// It does not block; if another analysis is in progress, the command is skipped.
+func (h *Handler) adviseIndex(connCtx context.Context, dbName string, spec wirebson.RawDocument) {
+	threshold := h.paramValues.indexAdvisorScanRatio.Load()
+	if threshold <= 0 {
+		return
+	}
+
+	doc, err := spec.DecodeDeep()
+	if err != nil {
+		return
+	}
+
+	collection, _ := doc.Get(doc.Command()).(string)
+	filter, _ := doc.Get("filter").(*wirebson.Document)
+	sort, _ := doc.Get("sort").(*wirebson.Document)
+
+	if collection == "" || filter == nil {
+		return
+	}
+
+	key := suggestedIndexKey(filter, sort, nil)
+	if key == nil {
+		return
+	}
+
+	shape := queryShape(filter)
+	if sort != nil && sort.Len() > 0 {
+		shape = must.NotFail(wirebson.NewDocument("filter", shape, "sort", sort))
+	}
+
+	id := dbName + "." + collection + " " + shape.LogMessage()
+	now := time.Now()
+
+	if !h.indexAdvisor.seen(id, now) {
+		return
+	}
+
+	if !h.indexAdvisor.running.CompareAndSwap(false, true) {
+		return
+	}
+
+	s := &indexSuggestion{
+		db:         dbName,
+		collection: collection,
+		shape:      shape,
+		key:        key,
+		count:      1,
+		firstSeen:  now,
+		lastSeen:   now,
+	}
+
+	go func() {
+		defer h.indexAdvisor.running.Store(false)
+
+		ctx := context.WithoutCancel(connCtx)
+		l := h.L.With(slog.String("ns", dbName+"."+collection))
+
+		ratio, err := h.scanRatio(ctx, dbName, collection, spec)
+		if err != nil {
+			l.DebugContext(ctx, "Failed to analyze query", logging.Error(err))
+			return
+		}
+
+		if ratio < float64(threshold) {
+			h.indexAdvisor.remove(id)
+			return
+		}
+
+		s.scanRatio = ratio
+
+		// statistics collected by `ferretAnalyze` are optional
+		if cardinalities, _ := h.fieldCardinalities(ctx, dbName, collection); cardinalities != nil {
+			s.key = suggestedIndexKey(filter, sort, cardinalities)
+		}
+
+		if h.indexAdvisor.suggest(id, s) {
+			l.WarnContext(
+				ctx, "Query uses a collection scan; consider creating an index",
+				slog.String("shape", shape.LogMessage()),
+				slog.String("index", s.key.LogMessage()),
+				slog.Float64("scan_ratio", ratio),
+			)
+		}
+	}()
+}
+
+// scanRatio returns the estimated ratio of scanned to returned documents for the given `find` command.
+// It returns 0 if the collection is not scanned sequentially or has no statistics.
+func (h *Handler) scanRatio(ctx context.Context, dbName, collection string, spec wirebson.RawDocument) (float64, error) {
+	conn, err := h.Pool.Acquire()
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+	defer conn.Release()
+
+	q := `
+		EXPLAIN (FORMAT JSON)
+			SELECT document
+		FROM documentdb_api_catalog.bson_aggregation_find($1, $2::bytea)`
+
+	var dest []byte
+	if err = conn.Conn().QueryRow(ctx, q, dbName, spec).Scan(&dest); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	var plans []struct {
+		Plan explainPlanNode `json:"Plan"`
+	}
+
+	if err = json.Unmarshal(dest, &plans); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	if len(plans) == 0 {
+		return 0, nil
+	}
+
+	scan := plans[0].Plan.seqScan()
+	if scan == nil {
+		return 0, nil
+	}
+
+	count, ok, err := documentdb.CollectionEstimatedCount(ctx, conn.Conn(), dbName, collection)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	return float64(count) / max(scan.PlanRows, 1), nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestSuggestedIndexKey(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		filter        *wirebson.Document
+		sort          *wirebson.Document
+		cardinalities map[string]float64
+		shape         *wirebson.Document
+		expected      *wirebson.Document // nil if no index is suggested
+	}{
+		"EqualitySortRange": {
+			filter: must.NotFail(wirebson.NewDocument(
+				"age", must.NotFail(wirebson.NewDocument("$gt", int32(18))),
+				"status", "active",
+			)),
+			sort: must.NotFail(wirebson.NewDocument("created", int32(-1))),
+			shape: must.NotFail(wirebson.NewDocument(
+				"age", must.NotFail(wirebson.NewDocument("$gt", "?")),
+				"status", "?",
+			)),
+			expected: must.NotFail(wirebson.NewDocument("status", int32(1), "created", int32(-1), "age", int32(1))),
+		},
+		"Selectivity": {
+			filter:        must.NotFail(wirebson.NewDocument("status", "active", "email", "a@example.com")),
+			cardinalities: map[string]float64{"status": 3, "email": 10000},
+			shape:         must.NotFail(wirebson.NewDocument("status", "?", "email", "?")),
+			expected:      must.NotFail(wirebson.NewDocument("email", int32(1), "status", int32(1))),
+		},
+		"And": {
+			filter: must.NotFail(wirebson.NewDocument("$and", must.NotFail(wirebson.NewArray(
+				must.NotFail(wirebson.NewDocument("a", must.NotFail(wirebson.NewDocument("$in", wirebson.MakeArray(0))))),
+				must.NotFail(wirebson.NewDocument("b", wirebson.Regex{Pattern: "^x"})),
+			)))),
+			shape: must.NotFail(wirebson.NewDocument("$and", must.NotFail(wirebson.NewArray(
+				must.NotFail(wirebson.NewDocument("a", must.NotFail(wirebson.NewDocument("$in", "?")))),
+				must.NotFail(wirebson.NewDocument("b", "?")),
+			)))),
+			expected: must.NotFail(wirebson.NewDocument("a", int32(1), "b", int32(1))),
+		},
+		"IDOnly": {
+			filter: must.NotFail(wirebson.NewDocument("_id", int32(1))),
+			shape:  must.NotFail(wirebson.NewDocument("_id", "?")),
+		},
+		"Or": {
+			filter: must.NotFail(wirebson.NewDocument("$or", must.NotFail(wirebson.NewArray(
+				must.NotFail(wirebson.NewDocument("a", int32(1))),
+			)))),
+			shape: must.NotFail(wirebson.NewDocument("$or", must.NotFail(wirebson.NewArray(
+				must.NotFail(wirebson.NewDocument("a", "?")),
+			)))),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.shape.LogMessage(), queryShape(tc.filter).LogMessage())
+
+			actual := suggestedIndexKey(tc.filter, tc.sort, tc.cardinalities)
+			if tc.expected == nil {
+				assert.Nil(t, actual)
+				return
+			}
+
+			assert.Equal(t, tc.expected.LogMessage(), actual.LogMessage())
+		})
+	}
+}
+
+func TestIndexAdvisor(t *testing.T) {
+	t.Parallel()
+
+	var a indexAdvisor
+
+	now := time.Now()
+
+	assert.True(t, a.seen("db.c {}", now))
+	assert.False(t, a.seen("db.c {}", now.Add(time.Second)))
+	assert.True(t, a.seen("db.c {}", now.Add(indexAdvisorInterval)))
+
+	s := &indexSuggestion{db: "db", collection: "c", shape: wirebson.MakeDocument(0), count: 1, lastSeen: now}
+	assert.True(t, a.suggest("db.c {}", s))
+	assert.False(t, a.suggest("db.c {}", &indexSuggestion{lastSeen: now}))
+
+	a.seen("db.c {}", now.Add(2*time.Second))
+
+	list := a.list("db")
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(2), list[0].count)
+	assert.Empty(t, a.list("other"))
+
+	a.remove("db.c {}")
+	assert.Empty(t, a.list(""))
+}
@@ -0,0 +1,476 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api_internal"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// indexBuildPollInterval is the interval of checking the status of concurrent index builds.
+const indexBuildPollInterval = 100 * time.Millisecond
+
+// errIndexBuildAborted is used as a cancellation cause for index builds aborted by `dropIndexes` command.
+var errIndexBuildAborted = errors.New("index build aborted")
+
+// indexBuild represents an in-progress index build started by `createIndexes` or `reIndex` command.
+//
+//nolint:vet // for readability
+type indexBuild struct {
+	id         int64
+	db         string
+	collection string
+	indexes    *wirebson.Array    // index specifications
+	command    *wirebson.Document // original command for reporting
+	concurrent bool
+	started    time.Time
+
+	cancel  context.CancelCauseFunc
+	done    chan struct{} // closed when the build is finished
+	aborted bool          // set before done is closed if indexes of the aborted build do not exist
+}
+
+// newIndexBuild returns a new index build for the given `createIndexes` command.
+func newIndexBuild(db string, spec wirebson.RawDocument, concurrent bool) (*indexBuild, error) {
+	doc, err := spec.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	collection, _ := doc.Get(doc.Command()).(string)
+
+	indexes, _ := doc.Get("indexes").(*wirebson.Array)
+	if indexes == nil {
+		indexes = wirebson.MakeArray(0)
+	}
+
+	return &indexBuild{
+		db:         db,
+		collection: collection,
+		indexes:    indexes,
+		command:    doc,
+		concurrent: concurrent,
+		started:    time.Now(),
+		done:       make(chan struct{}),
+	}, nil
+}
+
+// names returns names of indexes being built.
+func (b *indexBuild) names() []string {
+	res := make([]string, 0, b.indexes.Len())
+
+	for v := range b.indexes.Values() {
+		if spec, ok := v.(*wirebson.Document); ok {
+			if name, ok := spec.Get("name").(string); ok {
+				res = append(res, name)
+			}
+		}
+	}
+
+	return res
+}
+
+// matches returns true if the given `index` field value of `dropIndexes` command
+// specifies any index of that build.
+func (b *indexBuild) matches(index any) bool {
+	switch index := index.(type) {
+	case wirebson.RawDocument:
+		doc, err := index.DecodeDeep()
+		return err == nil && b.matches(doc)
+
+	case wirebson.RawArray:
+		arr, err := index.DecodeDeep()
+		return err == nil && b.matches(arr)
+
+	case string:
+		return index == "*" || slices.Contains(b.names(), index)
+
+	case *wirebson.Array:
+		for v := range index.Values() {
+			if name, ok := v.(string); ok && slices.Contains(b.names(), name) {
+				return true
+			}
+		}
+
+	case *wirebson.Document:
+		for v := range b.indexes.Values() {
+			spec, ok := v.(*wirebson.Document)
+			if !ok {
+				continue
+			}
+
+			key, ok := spec.Get("key").(*wirebson.Document)
+			if !ok {
+				continue
+			}
+
+			if k, err := key.Encode(); err == nil {
+				if i, err := index.Encode(); err == nil && string(k) == string(i) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// currentOp returns the build description for the `currentOp` command output.
+// Progress may be nil if it is unknown.
+func (b *indexBuild) currentOp(p *documentdb.IndexBuildProgress) *wirebson.Document {
+	running := time.Since(b.started)
+
+	res := must.NotFail(wirebson.NewDocument(
+		"type", "op",
+		"desc", "IndexBuildsCoordinator",
+		"active", true,
+		"indexBuildId", b.id,
+		"ns", b.db+"."+b.collection,
+		"secs_running", int64(running.Seconds()),
+		"microsecs_running", running.Microseconds(),
+		"command", b.command,
+		"concurrent", b.concurrent,
+	))
+
+	if p == nil {
+		must.NoError(res.Add("msg", "Index Build: waiting"))
+		return res
+	}
+
+	done, total := p.BlocksDone, p.BlocksTotal
+	if total == 0 {
+		done, total = p.TuplesDone, p.TuplesTotal
+	}
+
+	must.NoError(res.Add("msg", "Index Build: "+p.Phase))
+	must.NoError(res.Add("progress", must.NotFail(wirebson.NewDocument(
+		"done", done,
+		"total", total,
+	))))
+
+	return res
+}
+
+// indexBuilds is a registry of in-progress index builds.
+//
+// The zero value is ready to use.
+type indexBuilds struct {
+	mu     sync.Mutex
+	lastID int64
+	b      []*indexBuild // ordered by id
+}
+
+// add assigns an ID to the given build and stores it.
+func (ibs *indexBuilds) add(b *indexBuild) {
+	ibs.mu.Lock()
+	defer ibs.mu.Unlock()
+
+	ibs.lastID++
+	b.id = ibs.lastID
+
+	ibs.b = append(ibs.b, b)
+}
+
+// remove removes the given build from the registry and marks it as finished.
+func (ibs *indexBuilds) remove(b *indexBuild) {
+	ibs.mu.Lock()
+	defer ibs.mu.Unlock()
+
+	ibs.b = slices.DeleteFunc(ibs.b, func(e *indexBuild) bool { return e == b })
+	close(b.done)
+}
+
+// all returns all in-progress builds.
+func (ibs *indexBuilds) all() []*indexBuild {
+	ibs.mu.Lock()
+	defer ibs.mu.Unlock()
+
+	return slices.Clone(ibs.b)
+}
+
+// forCollection returns in-progress builds of the given collection.
+func (ibs *indexBuilds) forCollection(db, collection string) []*indexBuild {
+	return slices.DeleteFunc(ibs.all(), func(b *indexBuild) bool {
+		return b.db != db || b.collection != collection
+	})
+}
+
+// abort aborts in-progress builds of the given collection matching the `index` field value
+// of `dropIndexes` command, and waits for them to finish.
+// It returns names of indexes of aborted builds.
+//
+// Builds that were finished or could not be cleaned up before the abort took effect are not included,
+// so their indexes are dropped as usual.
+func (ibs *indexBuilds) abort(db, collection string, index any) []string {
+	var res []string
+
+	for _, b := range ibs.forCollection(db, collection) {
+		if !b.matches(index) {
+			continue
+		}
+
+		b.cancel(errIndexBuildAborted)
+		<-b.done
+
+		if b.aborted {
+			res = append(res, b.names()...)
+		}
+	}
+
+	return res
+}
+
+// markBuildingIndexes adds `building: true` field to specifications of indexes being built
+// in the first batch of `listIndexes` response.
+// Specifications missing from that batch are added.
+func markBuildingIndexes(page wirebson.AnyDocument, builds []*indexBuild) (wirebson.AnyDocument, error) {
+	raw, err := page.Encode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	doc, err := raw.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	cursor, _ := doc.Get("cursor").(*wirebson.Document)
+	if cursor == nil {
+		return page, nil
+	}
+
+	batch, _ := cursor.Get("firstBatch").(*wirebson.Array)
+	if batch == nil {
+		return page, nil
+	}
+
+	listed := make(map[string]*wirebson.Document, batch.Len())
+
+	for v := range batch.Values() {
+		if spec, ok := v.(*wirebson.Document); ok {
+			if name, ok := spec.Get("name").(string); ok {
+				listed[name] = spec
+			}
+		}
+	}
+
+	for _, b := range builds {
+		for v := range b.indexes.Values() {
+			spec, ok := v.(*wirebson.Document)
+			if !ok {
+				continue
+			}
+
+			name, _ := spec.Get("name").(string)
+
+			if l := listed[name]; l != nil {
+				if l.Get("building") == nil {
+					must.NoError(l.Add("building", true))
+				}
+
+				continue
+			}
+
+			// do not modify the build's specification that could be used concurrently
+			res := must.NotFail(wirebson.NewDocument("v", int32(2)))
+
+			for k, v := range spec.All() {
+				if k == "v" {
+					must.NoError(res.Replace(k, v))
+					continue
+				}
+
+				must.NoError(res.Add(k, v))
+			}
+
+			must.NoError(res.Add("building", true))
+			must.NoError(batch.Add(res))
+		}
+	}
+
+	return doc, nil
+}
+
+// buildIndexes runs the given `createIndexes` command and tracks its progress until it is finished or aborted.
+//
+// Indexes are created with CREATE INDEX CONCURRENTLY if `ferretdbConcurrentIndexBuilds` parameter is set.
+func (h *Handler) buildIndexes(connCtx context.Context, conn *pgx.Conn, dbName string, spec wirebson.RawDocument) (wirebson.RawDocument, error) { //nolint:lll // for readability
+	b, err := newIndexBuild(dbName, spec, h.paramValues.concurrentIndexBuilds.Load())
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	ctx, cancel := context.WithCancelCause(connCtx)
+	defer cancel(nil)
+
+	b.cancel = cancel
+
+	h.indexBuilds.add(b)
+	defer h.indexBuilds.remove(b)
+
+	var res wirebson.RawDocument
+
+	// concurrent builds are not used by default
+	// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/1147
+	if b.concurrent {
+		res, err = h.buildIndexesConcurrently(ctx, conn, b, spec)
+	} else {
+		res, err = documentdb_api_internal.CreateIndexesNonConcurrently(ctx, conn, h.L, dbName, spec, true)
+	}
+
+	if err != nil {
+		// non-concurrent builds are rolled back on cancellation;
+		// concurrent builds return the cancellation cause only after their indexes are dropped
+		if errors.Is(context.Cause(ctx), errIndexBuildAborted) && (!b.concurrent || errors.Is(err, errIndexBuildAborted)) {
+			b.aborted = true
+
+			msg := fmt.Sprintf("Index build aborted: %d: dropIndexes command on %s.%s", b.id, b.db, b.collection)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrIndexBuildAborted, msg, "createIndexes")
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// buildIndexesConcurrently submits the `createIndexes` command to DocumentDB background index build queue
+// and waits for it to finish.
+//
+// If the build is aborted, the queued request still could create indexes, so they are dropped;
+// the cancellation cause is returned only if that succeeds.
+func (h *Handler) buildIndexesConcurrently(ctx context.Context, conn *pgx.Conn, b *indexBuild, spec wirebson.RawDocument) (wirebson.RawDocument, error) { //nolint:lll // for readability
+	res, ok, requests, err := documentdb_api.CreateIndexesBackground(ctx, conn, h.L, b.db, spec)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !ok || requests == nil {
+		return res, nil
+	}
+
+	t := time.NewTicker(indexBuildPollInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			if errors.Is(context.Cause(ctx), errIndexBuildAborted) {
+				if err = h.dropAbortedIndexes(context.WithoutCancel(ctx), conn, b, requests); err != nil {
+					return nil, err
+				}
+			}
+
+			return nil, lazyerrors.Error(context.Cause(ctx))
+		}
+
+		status, ok, complete, err := documentdb_api_internal.CheckBuildIndexStatus(ctx, conn, h.L, requests)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if !ok {
+			return status, nil
+		}
+
+		if complete {
+			return res, nil
+		}
+	}
+}
+
+// dropAbortedIndexes drops indexes of the aborted concurrent build.
+//
+// Dropping them does not necessarily remove the build request from DocumentDB queue,
+// so indexes are dropped again until the request is finished.
+func (h *Handler) dropAbortedIndexes(ctx context.Context, conn *pgx.Conn, b *indexBuild, requests wirebson.RawDocument) error { //nolint:lll // for readability
+	t := time.NewTicker(indexBuildPollInterval)
+	defer t.Stop()
+
+	for {
+		_, ok, complete, err := documentdb_api_internal.CheckBuildIndexStatus(ctx, conn, h.L, requests)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		for _, name := range b.names() {
+			spec := must.NotFail(must.NotFail(wirebson.NewDocument(
+				"dropIndexes", b.collection,
+				"index", name,
+			)).Encode())
+
+			if _, err = documentdb_api.DropIndexes(ctx, conn, h.L, b.db, spec, nil); err != nil {
+				var e *mongoerrors.Error
+				if errors.As(err, &e) && e.Code == int32(mongoerrors.ErrIndexNotFound) {
+					continue
+				}
+
+				return lazyerrors.Error(err)
+			}
+		}
+
+		// the request failed or completed before indexes were dropped
+		if !ok || complete {
+			return nil
+		}
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return lazyerrors.Error(ctx.Err())
+		}
+	}
+}
+
+// indexBuildsCurrentOp returns descriptions of in-progress index builds for the `currentOp` command output.
+func (h *Handler) indexBuildsCurrentOp(ctx context.Context) []*wirebson.Document {
+	builds := h.indexBuilds.all()
+	res := make([]*wirebson.Document, 0, len(builds))
+
+	for _, b := range builds {
+		var p *documentdb.IndexBuildProgress
+
+		err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+			var err error
+			p, err = documentdb.CollectionIndexBuildProgress(ctx, conn, b.db, b.collection)
+
+			return err
+		})
+		if err != nil {
+			h.L.WarnContext(ctx, "Failed to get index build progress", logging.Error(err), slog.Int64("build", b.id))
+		}
+
+		res = append(res, b.currentOp(p))
+	}
+
+	return res
+}
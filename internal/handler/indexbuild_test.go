@@ -0,0 +1,149 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestIndexBuild(t *testing.T) {
+	t.Parallel()
+
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument(
+		"createIndexes", "coll",
+		"indexes", must.NotFail(wirebson.NewArray(
+			must.NotFail(wirebson.NewDocument(
+				"key", must.NotFail(wirebson.NewDocument("v", int32(1))),
+				"name", "v_1",
+			)),
+			must.NotFail(wirebson.NewDocument(
+				"key", must.NotFail(wirebson.NewDocument("a", int32(1), "b", int32(-1))),
+				"name", "a_1_b_-1",
+				"unique", true,
+			)),
+		)),
+	)).Encode())
+
+	b, err := newIndexBuild("db", spec, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "coll", b.collection)
+	assert.Equal(t, []string{"v_1", "a_1_b_-1"}, b.names())
+
+	t.Run("Matches", func(t *testing.T) {
+		t.Parallel()
+
+		assert.True(t, b.matches("*"))
+		assert.True(t, b.matches("v_1"))
+		assert.False(t, b.matches("foo_1"))
+		assert.True(t, b.matches(must.NotFail(wirebson.NewArray("foo_1", "a_1_b_-1"))))
+		assert.False(t, b.matches(must.NotFail(wirebson.NewArray("foo_1"))))
+		assert.True(t, b.matches(must.NotFail(wirebson.NewDocument("a", int32(1), "b", int32(-1)))))
+		assert.False(t, b.matches(must.NotFail(wirebson.NewDocument("b", int32(-1), "a", int32(1)))))
+	})
+
+	t.Run("ListIndexes", func(t *testing.T) {
+		t.Parallel()
+
+		page := must.NotFail(must.NotFail(wirebson.NewDocument(
+			"cursor", must.NotFail(wirebson.NewDocument(
+				"id", int64(0),
+				"ns", "db.coll",
+				"firstBatch", must.NotFail(wirebson.NewArray(
+					must.NotFail(wirebson.NewDocument(
+						"v", int32(2),
+						"key", must.NotFail(wirebson.NewDocument("_id", int32(1))),
+						"name", "_id_",
+					)),
+					must.NotFail(wirebson.NewDocument(
+						"v", int32(2),
+						"key", must.NotFail(wirebson.NewDocument("v", int32(1))),
+						"name", "v_1",
+					)),
+				)),
+			)),
+			"ok", float64(1),
+		)).Encode())
+
+		res, err := markBuildingIndexes(page, []*indexBuild{b})
+		require.NoError(t, err)
+
+		doc, err := res.Decode()
+		require.NoError(t, err)
+
+		batch := doc.Get("cursor").(*wirebson.Document).Get("firstBatch").(*wirebson.Array)
+		require.Equal(t, 3, batch.Len())
+
+		assert.Nil(t, batch.Get(0).(*wirebson.Document).Get("building"))
+		assert.Equal(t, true, batch.Get(1).(*wirebson.Document).Get("building"))
+
+		added := batch.Get(2).(*wirebson.Document)
+		assert.Equal(t, int32(2), added.Get("v"))
+		assert.Equal(t, "a_1_b_-1", added.Get("name"))
+		assert.Equal(t, true, added.Get("unique"))
+		assert.Equal(t, true, added.Get("building"))
+
+		// the build's specification is not modified
+		assert.Nil(t, b.indexes.Get(1).(*wirebson.Document).Get("building"))
+	})
+}
+
+func TestIndexBuildsAbort(t *testing.T) {
+	t.Parallel()
+
+	var ibs indexBuilds
+
+	// start builds the index with the given name;
+	// it is aborted only if cleanup succeeds
+	start := func(name string, cleanup bool) {
+		spec := must.NotFail(must.NotFail(wirebson.NewDocument(
+			"createIndexes", "coll",
+			"indexes", must.NotFail(wirebson.NewArray(
+				must.NotFail(wirebson.NewDocument(
+					"key", must.NotFail(wirebson.NewDocument(name, int32(1))),
+					"name", name+"_1",
+				)),
+			)),
+		)).Encode())
+
+		b, err := newIndexBuild("db", spec, true)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		b.cancel = cancel
+
+		ibs.add(b)
+
+		go func() {
+			<-ctx.Done()
+
+			b.aborted = cleanup
+			ibs.remove(b)
+		}()
+	}
+
+	start("a", true)
+	start("b", false)
+
+	assert.Equal(t, []string{"a_1"}, ibs.abort("db", "coll", "*"))
+	assert.Empty(t, ibs.all())
+}
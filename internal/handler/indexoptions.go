@@ -0,0 +1,234 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// indexOptionsFields contains index options that are returned by `listIndexes` exactly as created.
+var indexOptionsFields = []string{
+	"v", "unique", "sparse", "partialFilterExpression", "expireAfterSeconds", "collation", "wildcardProjection", "hidden",
+}
+
+// missingIndexOptions returns options of the given `createIndexes` index specification
+// that are missing in the given `listIndexes` specification of the created index.
+// The index version is returned if it differs.
+//
+// It returns nil if there are no such options.
+func missingIndexOptions(created, listed *wirebson.Document) *wirebson.Document {
+	var res *wirebson.Document
+
+	for _, f := range indexOptionsFields {
+		v := created.Get(f)
+		if v == nil {
+			continue
+		}
+
+		if l := listed.Get(f); l != nil {
+			if f != "v" {
+				continue
+			}
+
+			// v could be double, int, or long
+			if vn, ln := numberAsInt64(v), numberAsInt64(l); vn == nil || ln == nil || *vn == *ln {
+				continue
+			}
+		}
+
+		if res == nil {
+			res = must.NotFail(wirebson.NewDocument("key", listed.Get("key")))
+		}
+
+		must.NoError(res.Add(f, v))
+	}
+
+	return res
+}
+
+// numberAsInt64 returns the given integer number as int64, or nil if it is not a number.
+func numberAsInt64(v any) *int64 {
+	var res int64
+
+	switch v := v.(type) {
+	case int32:
+		res = int64(v)
+	case int64:
+		res = v
+	case float64:
+		res = int64(v)
+	default:
+		return nil
+	}
+
+	return &res
+}
+
+// saveIndexOptions stores options of the indexes created by the given `createIndexes` command
+// that DocumentDB does not return from `listIndexes`.
+// Options of dropped indexes are removed.
+// The command should be decoded deeply.
+//
+// Errors are logged, as indexes are already created.
+func (h *Handler) saveIndexOptions(ctx context.Context, conn *pgx.Conn, dbName string, doc *wirebson.Document) {
+	collection, _ := doc.Get(doc.Command()).(string)
+	indexes, _ := doc.Get("indexes").(*wirebson.Array)
+
+	if collection == "" || indexes == nil {
+		return
+	}
+
+	l := h.L.With(slog.String("ns", dbName+"."+collection))
+
+	listed, err := h.listIndexSpecs(ctx, dbName, collection)
+	if err != nil {
+		l.WarnContext(ctx, "Failed to list indexes", logging.Error(err))
+		return
+	}
+
+	byName := make(map[string]*wirebson.Document, len(listed))
+
+	for _, index := range listed {
+		if name, _ := index.Get("name").(string); name != "" {
+			byName[name] = index
+		}
+	}
+
+	missing := map[string]*wirebson.Document{}
+
+	for v := range indexes.Values() {
+		created, _ := v.(*wirebson.Document)
+		if created == nil {
+			continue
+		}
+
+		name, _ := created.Get("name").(string)
+		if byName[name] == nil {
+			continue
+		}
+
+		// nil value removes previously stored options
+		missing[name] = missingIndexOptions(created, byName[name])
+	}
+
+	err = documentdb.UpdateIndexOptions(ctx, conn, dbName, collection, func(opts *wirebson.Document) error {
+		for _, name := range opts.FieldNames() {
+			if byName[name] == nil {
+				opts.Remove(name)
+			}
+		}
+
+		for name, o := range missing {
+			opts.Remove(name)
+
+			if o != nil {
+				must.NoError(opts.Add(name, o))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		l.WarnContext(ctx, "Failed to save index options", logging.Error(err))
+	}
+}
+
+// applyIndexOptions adds stored index options to the given `listIndexes` response page.
+// Options are added only to indexes with the same name and key as stored.
+func (h *Handler) applyIndexOptions(ctx context.Context, dbName, collection string, page wirebson.AnyDocument) (wirebson.AnyDocument, error) { //nolint:lll // for readability
+	var opts *wirebson.Document
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		opts, err = documentdb.IndexOptions(ctx, conn, dbName, collection)
+
+		return err
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if opts == nil {
+		return page, nil
+	}
+
+	raw, err := page.Encode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := raw.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	cursor, _ := res.Get("cursor").(*wirebson.Document)
+	if cursor == nil {
+		return page, nil
+	}
+
+	batch, _ := cursor.Get("firstBatch").(*wirebson.Array)
+	if batch == nil {
+		return page, nil
+	}
+
+	for v := range batch.Values() {
+		index, _ := v.(*wirebson.Document)
+		if index == nil {
+			continue
+		}
+
+		name, _ := index.Get("name").(string)
+
+		o, _ := opts.Get(name).(*wirebson.Document)
+		if o == nil {
+			continue
+		}
+
+		storedKey, _ := o.Get("key").(*wirebson.Document)
+		key, _ := index.Get("key").(*wirebson.Document)
+
+		if storedKey == nil || key == nil {
+			continue
+		}
+
+		if sk, k := must.NotFail(storedKey.Encode()), must.NotFail(key.Encode()); string(sk) != string(k) {
+			continue
+		}
+
+		for f, v := range o.All() {
+			if f == "key" {
+				continue
+			}
+
+			if index.Get(f) == nil {
+				must.NoError(index.Add(f, v))
+			} else {
+				must.NoError(index.Replace(f, v))
+			}
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestMissingIndexOptions(t *testing.T) {
+	t.Parallel()
+
+	key := must.NotFail(wirebson.NewDocument("v", int32(1)))
+
+	created := must.NotFail(wirebson.NewDocument(
+		"key", key,
+		"name", "v_1",
+		"v", int32(1),
+		"sparse", true,
+		"hidden", true,
+		"background", true,
+	))
+
+	listed := must.NotFail(wirebson.NewDocument(
+		"v", int32(2),
+		"key", key,
+		"name", "v_1",
+		"sparse", true,
+	))
+
+	actual := missingIndexOptions(created, listed)
+	require.NotNil(t, actual)
+
+	expected := must.NotFail(wirebson.NewDocument("key", key, "v", int32(1), "hidden", true))
+	assert.Equal(t, expected.LogMessage(), actual.LogMessage())
+
+	must.NoError(listed.Replace("v", float64(1)))
+	must.NoError(listed.Add("hidden", true))
+	assert.Nil(t, missingIndexOptions(created, listed))
+}
@@ -0,0 +1,266 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// maxIndexesPerCollection is the maximal number of indexes per collection.
+const maxIndexesPerCollection = 64
+
+// indexStats tracks persistence of `$indexStats` counters.
+//
+// The zero value is ready to use.
+type indexStats struct {
+	running  atomic.Bool  // persistence is in progress
+	lastSave atomic.Int64 // Unix milliseconds
+}
+
+// persistIndexStats saves `$indexStats` counters of all collections in the background
+// if `ferretdbIndexStatsPersistIntervalMillis` passed since the last save.
+// Only one FerretDB instance saves them at a time.
+func (h *Handler) persistIndexStats(ctx context.Context) {
+	interval := h.indexStatsPersistInterval()
+	if interval <= 0 || time.Since(time.UnixMilli(h.indexStats.lastSave.Load())) < interval {
+		return
+	}
+
+	if !h.indexStats.running.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer h.indexStats.running.Store(false)
+
+		h.indexStats.lastSave.Store(time.Now().UnixMilli())
+
+		locked, err := h.Pool.LockIndexUsage(ctx, func() error {
+			return h.saveIndexStats(ctx)
+		})
+
+		switch {
+		case err != nil:
+			h.L.WarnContext(ctx, "Failed to persist index usage counters", logging.Error(err))
+		case !locked:
+			h.L.DebugContext(ctx, "Index usage counters are persisted by another instance")
+		default:
+			h.L.DebugContext(ctx, "Index usage counters persisted")
+		}
+	}()
+}
+
+// saveIndexStats saves `$indexStats` counters of all collections.
+// Errors of individual collections are logged.
+func (h *Handler) saveIndexStats(ctx context.Context) error {
+	var all []documentdb.CollectionMetadata
+
+	err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var err error
+		all, err = documentdb.AllCollections(ctx, conn)
+
+		return err
+	})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, c := range all {
+		usage, err := h.currentIndexUsage(ctx, c.DB, c.Collection)
+		if err == nil {
+			err = h.Pool.SaveIndexUsage(ctx, c.DB, c.Collection, usage)
+		}
+
+		if err != nil {
+			l := h.L.With(slog.String("ns", c.DB+"."+c.Collection))
+			l.WarnContext(ctx, "Failed to persist index usage counters", logging.Error(err))
+		}
+	}
+
+	return h.Pool.DeleteDroppedIndexUsage(ctx)
+}
+
+// currentIndexUsage returns `$indexStats` counters of the given collection as reported by DocumentDB.
+func (h *Handler) currentIndexUsage(ctx context.Context, dbName, collection string) ([]documentdb.IndexUsage, error) {
+	spec := must.NotFail(wirebson.MustDocument(
+		"aggregate", collection,
+		"pipeline", wirebson.MustArray(wirebson.MustDocument("$indexStats", wirebson.MakeDocument(0))),
+		"cursor", wirebson.MustDocument("batchSize", int32(maxIndexesPerCollection)),
+		"$db", dbName,
+	).Encode())
+
+	page, cursorID, err := h.Pool.Aggregate(ctx, dbName, spec)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if cursorID != 0 {
+		h.Pool.KillCursor(ctx, cursorID)
+		return nil, lazyerrors.Errorf("too many indexes in %s.%s", dbName, collection)
+	}
+
+	pageDoc, err := page.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	cursor, _ := pageDoc.Get("cursor").(*wirebson.Document)
+	if cursor == nil {
+		return nil, lazyerrors.Errorf("no cursor in the page: %s", pageDoc.LogMessage())
+	}
+
+	batch, _ := cursor.Get("firstBatch").(*wirebson.Array)
+	if batch == nil {
+		return nil, nil
+	}
+
+	res := make([]documentdb.IndexUsage, 0, batch.Len())
+
+	for v := range batch.Values() {
+		d, _ := v.(*wirebson.Document)
+		if d == nil {
+			continue
+		}
+
+		accesses, _ := d.Get("accesses").(*wirebson.Document)
+		if accesses == nil {
+			continue
+		}
+
+		u := documentdb.IndexUsage{
+			Since: time.Now(),
+		}
+
+		u.Index, _ = d.Get("name").(string)
+
+		switch ops := accesses.Get("ops").(type) {
+		case int64:
+			u.Ops = ops
+		case int32:
+			u.Ops = int64(ops)
+		}
+
+		if since, ok := accesses.Get("since").(time.Time); ok {
+			u.Since = since
+		}
+
+		res = append(res, u)
+	}
+
+	return res, nil
+}
+
+// applyIndexUsage adds persisted counters to the results of the aggregation pipeline
+// that starts with `$indexStats` stage, if counters are persisted.
+func (h *Handler) applyIndexUsage(ctx context.Context, dbName string, spec wirebson.RawDocument) (wirebson.RawDocument, error) { //nolint:lll // for readability
+	if h.indexStatsPersistInterval() <= 0 {
+		return spec, nil
+	}
+
+	doc, err := spec.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	pipeline, _ := doc.Get("pipeline").(*wirebson.Array)
+	if pipeline == nil || pipeline.Len() == 0 {
+		return spec, nil
+	}
+
+	if first, _ := pipeline.Get(0).(*wirebson.Document); first == nil || first.Command() != "$indexStats" {
+		return spec, nil
+	}
+
+	collection, _ := doc.Get(doc.Command()).(string)
+
+	usage, err := h.Pool.IndexUsage(ctx, dbName, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	stage := indexUsageStage(usage)
+	if stage == nil {
+		return spec, nil
+	}
+
+	res := wirebson.MakeArray(pipeline.Len() + 1)
+
+	for i, s := range pipeline.All() {
+		must.NoError(res.Add(s))
+
+		if i == 0 {
+			must.NoError(res.Add(stage))
+		}
+	}
+
+	must.NoError(doc.Replace("pipeline", res))
+
+	if spec, err = doc.Encode(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return spec, nil
+}
+
+// indexUsageStage returns `$addFields` stage that replaces `$indexStats` counters with persisted ones,
+// increased by the current counter growth since the last save.
+// It returns nil if there are no persisted counters.
+func indexUsageStage(usage []documentdb.IndexUsage) *wirebson.Document {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	ops := wirebson.MakeArray(len(usage))
+	since := wirebson.MakeArray(len(usage))
+
+	for _, u := range usage {
+		isIndex := wirebson.MustDocument("$eq", wirebson.MustArray("$name", wirebson.MustDocument("$literal", u.Index)))
+
+		// the counter decreases only if PostgreSQL statistics were reset after the last save
+		growth := wirebson.MustDocument("$cond", wirebson.MustArray(
+			wirebson.MustDocument("$gte", wirebson.MustArray("$accesses.ops", u.Last)),
+			wirebson.MustDocument("$subtract", wirebson.MustArray("$accesses.ops", u.Last)),
+			"$accesses.ops",
+		))
+
+		must.NoError(ops.Add(wirebson.MustDocument(
+			"case", isIndex,
+			"then", wirebson.MustDocument("$add", wirebson.MustArray(u.Ops, growth)),
+		)))
+
+		must.NoError(since.Add(wirebson.MustDocument(
+			"case", isIndex,
+			"then", wirebson.MustDocument("$literal", u.Since),
+		)))
+	}
+
+	return wirebson.MustDocument("$addFields", wirebson.MustDocument(
+		"accesses", wirebson.MustDocument(
+			"ops", wirebson.MustDocument("$switch", wirebson.MustDocument("branches", ops, "default", "$accesses.ops")),
+			"since", wirebson.MustDocument("$switch", wirebson.MustDocument("branches", since, "default", "$accesses.since")),
+		),
+	))
+}
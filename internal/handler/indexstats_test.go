@@ -0,0 +1,52 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+)
+
+func TestIndexUsageStage(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, indexUsageStage(nil))
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	stage := indexUsageStage([]documentdb.IndexUsage{
+		{Index: "_id_", Ops: 100, Last: 10, Since: since},
+		{Index: "a_1", Ops: 5, Last: 5, Since: since},
+	})
+	require.NotNil(t, stage)
+	assert.Equal(t, "$addFields", stage.Command())
+
+	accesses := stage.Get("$addFields").(*wirebson.Document).Get("accesses").(*wirebson.Document)
+	assert.Equal(t, []string{"ops", "since"}, accesses.FieldNames())
+
+	ops := accesses.Get("ops").(*wirebson.Document).Get("$switch").(*wirebson.Document)
+	assert.Equal(t, 2, ops.Get("branches").(*wirebson.Array).Len())
+	assert.Equal(t, "$accesses.ops", ops.Get("default"))
+
+	branch := ops.Get("branches").(*wirebson.Array).Get(0).(*wirebson.Document)
+	add := branch.Get("then").(*wirebson.Document).Get("$add").(*wirebson.Array)
+	assert.Equal(t, int64(100), add.Get(0))
+}
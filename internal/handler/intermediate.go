@@ -0,0 +1,520 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// materializeStage is a FerretDB-specific aggregation stage that flags the pipeline:
+// results of stages before it are stored in an intermediate collection
+// and reused by the following executions of the same pipeline until they expire.
+const materializeStage = "$ferretdbMaterialize"
+
+// intermediatePrefix is the name prefix of intermediate collections.
+const intermediatePrefix = "ferretdb_intermediate_"
+
+// intermediateMetadata is a kind of collection metadata of intermediate collections.
+const intermediateMetadata = "intermediate"
+
+// defaultIntermediateTTL is the default lifetime of intermediate collections.
+const defaultIntermediateTTL = 5 * time.Minute
+
+// intermediates tracks materialization and cleanup of intermediate collections.
+//
+// The zero value is ready to use.
+type intermediates struct {
+	cleanup atomic.Bool // cleanup of expired collections is in progress
+
+	mu      sync.Mutex
+	running map[string]struct{} // namespaces of collections being materialized
+}
+
+// start marks the given intermediate collection as being materialized.
+// It returns false if it is already being materialized.
+func (im *intermediates) start(ns string) bool {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if _, ok := im.running[ns]; ok {
+		return false
+	}
+
+	if im.running == nil {
+		im.running = make(map[string]struct{})
+	}
+
+	im.running[ns] = struct{}{}
+
+	return true
+}
+
+// finish marks the given intermediate collection as not being materialized.
+func (im *intermediates) finish(ns string) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	delete(im.running, ns)
+}
+
+// intermediatePipeline represents the aggregation pipeline split by [materializeStage].
+type intermediatePipeline struct {
+	prefix *wirebson.Array // stages before the marker
+	rest   *wirebson.Array // stages after the marker
+	ttl    time.Duration
+}
+
+// whole returns the pipeline without the marker.
+func (ip *intermediatePipeline) whole() *wirebson.Array {
+	res := wirebson.MakeArray(ip.prefix.Len() + ip.rest.Len())
+
+	for v := range ip.prefix.Values() {
+		must.NoError(res.Add(v))
+	}
+
+	for v := range ip.rest.Values() {
+		must.NoError(res.Add(v))
+	}
+
+	return res
+}
+
+// splitIntermediatePipeline returns the pipeline split by [materializeStage], or nil if there is no such stage.
+func splitIntermediatePipeline(pipeline *wirebson.Array) (*intermediatePipeline, error) {
+	var res *intermediatePipeline
+
+	for i := range pipeline.Len() {
+		stage, _ := pipeline.Get(i).(wirebson.AnyDocument)
+		if stage == nil {
+			return nil, nil
+		}
+
+		d, err := stage.Decode()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if d.Command() != materializeStage {
+			if res != nil {
+				must.NoError(res.rest.Add(d))
+			}
+
+			continue
+		}
+
+		if res != nil {
+			msg := fmt.Sprintf("%s stage could be used only once in the pipeline", materializeStage)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, materializeStage)
+		}
+
+		res = &intermediatePipeline{
+			prefix: wirebson.MakeArray(i),
+			rest:   wirebson.MakeArray(pipeline.Len() - i - 1),
+			ttl:    defaultIntermediateTTL,
+		}
+
+		for j := range i {
+			must.NoError(res.prefix.Add(pipeline.Get(j)))
+		}
+
+		opts, _ := d.Get(materializeStage).(wirebson.AnyDocument)
+		if opts == nil {
+			msg := fmt.Sprintf("%s stage specification must be an object", materializeStage)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, materializeStage)
+		}
+
+		optsDoc, err := opts.Decode()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for k, v := range optsDoc.All() {
+			if k != "ttlSecs" {
+				msg := fmt.Sprintf("unknown option to %s stage: %s", materializeStage, k)
+				return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, materializeStage)
+			}
+
+			secs, err := parameterInt64("ttlSecs", v, 1, math.MaxInt64/int64(time.Second))
+			if err != nil {
+				return nil, err
+			}
+
+			res.ttl = time.Duration(secs) * time.Second
+		}
+	}
+
+	return res, nil
+}
+
+// intermediateCollection returns the name of the intermediate collection for the given source collection,
+// pipeline prefix, and other fields of the aggregate command that affect results.
+func intermediateCollection(source string, prefix *wirebson.Array, collation, let any) (string, error) {
+	key := wirebson.MustDocument("aggregate", source, "pipeline", prefix)
+
+	if collation != nil {
+		must.NoError(key.Add("collation", collation))
+	}
+
+	if let != nil {
+		must.NoError(key.Add("let", let))
+	}
+
+	raw, err := key.Encode()
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	h := sha256.Sum256(raw)
+
+	return intermediatePrefix + hex.EncodeToString(h[:16]), nil
+}
+
+// applyIntermediate handles [materializeStage] of the aggregate command.
+//
+// If the intermediate collection for stages before that stage exists and has not expired,
+// the returned command runs only the following stages on it.
+// Otherwise, the collection is (re)created first.
+// If that is not possible (for example, when the collection is being created by another command,
+// or when collections have access policies), the returned command runs the whole pipeline without the marker.
+func (h *Handler) applyIntermediate(ctx context.Context, dbName string, spec wirebson.RawDocument) (wirebson.RawDocument, error) { //nolint:lll // for readability
+	doc, err := spec.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	pipeline, _ := doc.Get("pipeline").(*wirebson.Array)
+	if pipeline == nil {
+		return spec, nil
+	}
+
+	ip, err := splitIntermediatePipeline(pipeline)
+	if err != nil || ip == nil {
+		return spec, err
+	}
+
+	source, _ := doc.Get(doc.Command()).(string)
+
+	if source == "" || ip.prefix.Len() == 0 || !h.intermediateAllowed(ctx, dbName, source, ip.prefix) {
+		return replaceAggregate(doc, source, ip.whole())
+	}
+
+	collection, err := intermediateCollection(source, ip.prefix, doc.Get("collation"), doc.Get("let"))
+	if err != nil {
+		return nil, err
+	}
+
+	l := h.L.With(slog.String("ns", dbName+"."+collection))
+
+	var md *wirebson.Document
+
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		var e error
+		md, e = documentdb.Metadata(ctx, conn, dbName, collection, intermediateMetadata)
+
+		return e
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if md != nil {
+		if expires, _ := md.Get("expiresAt").(time.Time); time.Now().Before(expires) {
+			l.DebugContext(ctx, "Reusing intermediate collection")
+			return replaceAggregate(doc, collection, ip.rest)
+		}
+	}
+
+	ns := dbName + "." + collection
+
+	if !h.intermediates.start(ns) {
+		l.DebugContext(ctx, "Intermediate collection is being materialized, running the whole pipeline")
+		return replaceAggregate(doc, source, ip.whole())
+	}
+	defer h.intermediates.finish(ns)
+
+	if err = h.materializeIntermediate(ctx, doc, dbName, collection, ip); err != nil {
+		return nil, err
+	}
+
+	l.DebugContext(ctx, "Intermediate collection materialized", slog.Duration("ttl", ip.ttl))
+
+	return replaceAggregate(doc, collection, ip.rest)
+}
+
+// intermediateAllowed returns true if the intermediate collection could be created for the pipeline prefix.
+//
+// Collections with access policies (including collections read by views and nested join stages)
+// are not materialized, as other users could reuse the intermediate collection without those policies.
+// Pipelines joining collections of other databases are not materialized for the same reason.
+func (h *Handler) intermediateAllowed(ctx context.Context, dbName, source string, prefix *wirebson.Array) bool {
+	joined, err := joinedCollections(dbName, prefix)
+	if err != nil {
+		h.L.WarnContext(ctx, "Failed to get joined collections", logging.Error(err))
+		return false
+	}
+
+	collections := []string{source}
+
+	for _, c := range joined {
+		if c.db != dbName {
+			return false
+		}
+
+		collections = append(collections, c.collection)
+	}
+
+	for _, c := range collections {
+		var policy accessPolicy
+
+		if policy, err = h.accessPolicy(ctx, dbName, c); err != nil {
+			h.L.WarnContext(ctx, "Failed to get access policy", logging.Error(err))
+			return false
+		}
+
+		if !policy.empty() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// blockedIntermediates is a middleware that wraps the command handler
+// with a check that the command does not access intermediate collections directly.
+//
+// They are read only by `aggregate` commands with [materializeStage]
+// after access policies are applied.
+func blockedIntermediates(next middleware.HandleFunc) middleware.HandleFunc {
+	return func(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+		if req == nil || req.OpMsg == nil {
+			return next(ctx, req)
+		}
+
+		doc, err := req.OpMsg.Document()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		command := doc.Command()
+
+		namespaces := commandNamespaces(doc)
+
+		if command == "aggregate" {
+			var pipeline *wirebson.Array
+			if pipeline, err = decodeArray(doc.Get("pipeline")); err != nil {
+				return nil, err
+			}
+
+			dbName, _ := doc.Get("$db").(string)
+
+			var joined []joinedCollection
+			if joined, err = joinedCollections(dbName, pipeline); err != nil {
+				return nil, err
+			}
+
+			for _, c := range joined {
+				namespaces = append(namespaces, [2]string{c.db, c.collection})
+			}
+
+			var outDB, outCollection string
+			var output bool
+
+			if outDB, outCollection, output, err = pipelineOutput(dbName, pipeline); err != nil {
+				return nil, err
+			}
+
+			if output {
+				namespaces = append(namespaces, [2]string{outDB, outCollection})
+			}
+		}
+
+		for _, ns := range namespaces {
+			if strings.HasPrefix(ns[1], intermediatePrefix) {
+				msg := fmt.Sprintf("intermediate collection '%s.%s' can't be accessed directly", ns[0], ns[1])
+				return nil, mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+			}
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// materializeIntermediate stores results of the pipeline prefix in the given collection
+// with its expiration time in collection metadata.
+func (h *Handler) materializeIntermediate(ctx context.Context, doc *wirebson.Document, dbName, collection string, ip *intermediatePipeline) error { //nolint:lll // for readability
+	prefix := wirebson.MakeArray(ip.prefix.Len() + 1)
+
+	for v := range ip.prefix.Values() {
+		must.NoError(prefix.Add(v))
+	}
+
+	must.NoError(prefix.Add(wirebson.MustDocument("$out", collection)))
+
+	spec := wirebson.MustDocument(
+		"aggregate", doc.Get(doc.Command()),
+		"pipeline", prefix,
+		"cursor", wirebson.MakeDocument(0),
+		"$db", dbName,
+	)
+
+	for _, f := range []string{"collation", "let", "allowDiskUse"} {
+		if v := doc.Get(f); v != nil {
+			must.NoError(spec.Add(f, v))
+		}
+	}
+
+	raw, err := spec.Encode()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	// $out does not create the collection if the pipeline returns no documents,
+	// but metadata is stored in it
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		_, e := documentdb_api.CreateCollection(ctx, conn, h.L, dbName, collection)
+		return e
+	})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	_, cursorID, err := h.Pool.Aggregate(ctx, dbName, raw)
+
+	// invalidate even on errors, as some documents could be written
+	h.invalidateResultCache(ctx, dbName)
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if cursorID != 0 {
+		_ = h.Pool.KillCursor(ctx, cursorID)
+	}
+
+	expires := time.Now().Add(ip.ttl)
+
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		return documentdb.UpdateMetadata(ctx, conn, dbName, collection, intermediateMetadata, func(md *wirebson.Document) error {
+			for _, f := range md.FieldNames() {
+				md.Remove(f)
+			}
+
+			must.NoError(md.Add("viewOn", doc.Get(doc.Command())))
+			must.NoError(md.Add("expiresAt", expires))
+
+			return nil
+		})
+	})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// replaceAggregate returns the aggregate command with the given collection and pipeline.
+func replaceAggregate(doc *wirebson.Document, collection string, pipeline *wirebson.Array) (wirebson.RawDocument, error) {
+	res := wirebson.MakeDocument(doc.Len())
+
+	for k, v := range doc.All() {
+		switch {
+		case k == doc.Command() && collection != "":
+			v = collection
+		case k == "pipeline":
+			v = pipeline
+		}
+
+		must.NoError(res.Add(k, v))
+	}
+
+	raw, err := res.Encode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return raw, nil
+}
+
+// dropExpiredIntermediates drops expired intermediate collections in the background.
+//
+// It does not block; if the previous call is still in progress, it does nothing.
+func (h *Handler) dropExpiredIntermediates(ctx context.Context) {
+	if !h.intermediates.cleanup.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer h.intermediates.cleanup.Store(false)
+
+		var all []documentdb.CollectionMetadata
+
+		err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+			var err error
+			all, err = documentdb.AllMetadata(ctx, conn, intermediateMetadata)
+
+			return err
+		})
+		if err != nil {
+			h.L.WarnContext(ctx, "Failed to list intermediate collections", logging.Error(err))
+			return
+		}
+
+		for _, md := range all {
+			expires, _ := md.Metadata.Get("expiresAt").(time.Time)
+			if !strings.HasPrefix(md.Collection, intermediatePrefix) || time.Now().Before(expires) {
+				continue
+			}
+
+			ns := md.DB + "." + md.Collection
+			if !h.intermediates.start(ns) {
+				continue
+			}
+
+			err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+				_, e := documentdb_api.DropCollection(ctx, conn, h.L, md.DB, md.Collection, nil, nil, false)
+				return e
+			})
+
+			h.intermediates.finish(ns)
+
+			if err != nil {
+				h.L.WarnContext(ctx, "Failed to drop intermediate collection", slog.String("ns", ns), logging.Error(err))
+				continue
+			}
+
+			h.L.DebugContext(ctx, "Expired intermediate collection dropped", slog.String("ns", ns))
+		}
+	}()
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/FerretDB/wire"
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+)
+
+func TestSplitIntermediatePipeline(t *testing.T) {
+	t.Parallel()
+
+	match := wirebson.MustDocument("$match", wirebson.MustDocument("a", int32(1)))
+	group := wirebson.MustDocument("$group", wirebson.MustDocument("_id", "$b"))
+
+	ip, err := splitIntermediatePipeline(wirebson.MustArray(match, group))
+	require.NoError(t, err)
+	assert.Nil(t, ip)
+
+	ip, err = splitIntermediatePipeline(wirebson.MustArray(
+		match,
+		wirebson.MustDocument("$ferretdbMaterialize", wirebson.MustDocument("ttlSecs", int32(60))),
+		group,
+	))
+	require.NoError(t, err)
+	require.NotNil(t, ip)
+	assert.Equal(t, 1, ip.prefix.Len())
+	assert.Equal(t, 1, ip.rest.Len())
+	assert.Equal(t, time.Minute, ip.ttl)
+	assert.Equal(t, 2, ip.whole().Len())
+
+	_, err = splitIntermediatePipeline(wirebson.MustArray(
+		wirebson.MustDocument("$ferretdbMaterialize", wirebson.MustDocument("ttl", int32(60))),
+	))
+	assert.Error(t, err)
+
+	_, err = splitIntermediatePipeline(wirebson.MustArray(
+		wirebson.MustDocument("$ferretdbMaterialize", wirebson.MustDocument()),
+		wirebson.MustDocument("$ferretdbMaterialize", wirebson.MustDocument()),
+	))
+	assert.Error(t, err)
+}
+
+func TestIntermediateCollection(t *testing.T) {
+	t.Parallel()
+
+	prefix := wirebson.MustArray(wirebson.MustDocument("$match", wirebson.MustDocument("a", int32(1))))
+
+	name, err := intermediateCollection("c", prefix, nil, nil)
+	require.NoError(t, err)
+	assert.Regexp(t, `^ferretdb_intermediate_[0-9a-f]{32}$`, name)
+
+	other, err := intermediateCollection("c", prefix, wirebson.MustDocument("locale", "en"), nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, name, other)
+}
+
+func TestBlockedIntermediates(t *testing.T) {
+	t.Parallel()
+
+	next := func(context.Context, *middleware.Request) (*middleware.Response, error) {
+		return nil, nil
+	}
+
+	handler := blockedIntermediates(next)
+
+	lookup := wirebson.MustDocument("$lookup", wirebson.MustDocument(
+		"from", "other",
+		"as", "joined",
+		"pipeline", wirebson.MustArray(
+			wirebson.MustDocument("$unionWith", intermediatePrefix+"hash"),
+		),
+	))
+
+	for name, tc := range map[string]struct {
+		msg     *wire.OpMsg
+		blocked bool
+	}{
+		"Find": {
+			msg:     wire.MustOpMsg("find", intermediatePrefix+"hash", "$db", "db"),
+			blocked: true,
+		},
+		"FindOther": {
+			msg: wire.MustOpMsg("find", "test", "$db", "db"),
+		},
+		"Rename": {
+			msg: wire.MustOpMsg(
+				"renameCollection", "db."+intermediatePrefix+"hash",
+				"to", "db.test",
+				"$db", "admin",
+			),
+			blocked: true,
+		},
+		"NestedJoin": {
+			msg: wire.MustOpMsg(
+				"aggregate", "test",
+				"pipeline", wirebson.MustArray(lookup),
+				"$db", "db",
+			),
+			blocked: true,
+		},
+		"Out": {
+			msg: wire.MustOpMsg(
+				"aggregate", "test",
+				"pipeline", wirebson.MustArray(wirebson.MustDocument("$out", intermediatePrefix+"hash")),
+				"$db", "db",
+			),
+			blocked: true,
+		},
+		"CreateUser": {
+			msg: wire.MustOpMsg("createUser", intermediatePrefix+"user", "$db", "db"),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := handler(context.Background(), &middleware.Request{OpMsg: tc.msg})
+			if !tc.blocked {
+				assert.NoError(t, err)
+				return
+			}
+
+			var e *mongoerrors.Error
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, int32(mongoerrors.ErrUnauthorized), e.Code)
+		})
+	}
+}
@@ -0,0 +1,676 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// maskingPolicyMetadata is a kind of collection metadata with field masking rules
+// set by `ferretSetMaskingPolicy` command.
+const maskingPolicyMetadata = "maskingPolicy"
+
+// Masking actions.
+const (
+	maskRemove = "remove" // the field is removed from results
+	maskHash   = "hash"   // the field value is replaced by the SHA-256 hash of its BSON encoding
+)
+
+// maskingRule masks given fields of the collection for users with the given role.
+type maskingRule struct {
+	role   string
+	action string
+	fields []string
+}
+
+// fieldMasks maps masked field paths to masking actions for a single user.
+type fieldMasks map[string]string
+
+// getMaskingRules returns validated masking rules from the given array.
+func getMaskingRules(command string, v any) ([]maskingRule, error) {
+	arr, ok := v.(*wirebson.Array)
+	if !ok {
+		msg := fmt.Sprintf(
+			"BSON field '%s.rules' is the wrong type '%s', expected type 'array'",
+			command, aliasFromType(v),
+		)
+
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+	}
+
+	res := make([]maskingRule, 0, arr.Len())
+
+	for v := range arr.Values() {
+		d, _ := v.(*wirebson.Document)
+		if d == nil {
+			msg := fmt.Sprintf("BSON field '%s.rules' must be an array of objects", command)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+		}
+
+		rule := maskingRule{action: maskRemove}
+
+		if rule.role, _ = d.Get("role").(string); rule.role == "" {
+			msg := fmt.Sprintf("BSON field '%s.rules.role' must be a non-empty string", command)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+		}
+
+		if a := d.Get("action"); a != nil {
+			if rule.action, _ = a.(string); rule.action != maskRemove && rule.action != maskHash {
+				msg := fmt.Sprintf(
+					"BSON field '%s.rules.action' must be either %q or %q",
+					command, maskRemove, maskHash,
+				)
+
+				return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+			}
+		}
+
+		fields, _ := d.Get("fields").(*wirebson.Array)
+		if fields == nil || fields.Len() == 0 {
+			msg := fmt.Sprintf("BSON field '%s.rules.fields' must be a non-empty array", command)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+		}
+
+		for f := range fields.Values() {
+			path, _ := f.(string)
+			if path == "" || strings.HasPrefix(path, "$") || slices.Contains(strings.Split(path, "."), "") {
+				msg := fmt.Sprintf("BSON field '%s.rules.fields' contains invalid field path %v", command, f)
+				return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+			}
+
+			rule.fields = append(rule.fields, path)
+		}
+
+		res = append(res, rule)
+	}
+
+	return res, nil
+}
+
+// maskingRulesArray returns masking rules as an array of documents,
+// as they are stored in collection metadata and accepted by [getMaskingRules].
+func maskingRulesArray(rules []maskingRule) *wirebson.Array {
+	res := wirebson.MakeArray(len(rules))
+
+	for _, rule := range rules {
+		fields := wirebson.MakeArray(len(rule.fields))
+		for _, f := range rule.fields {
+			must.NoError(fields.Add(f))
+		}
+
+		must.NoError(res.Add(must.NotFail(wirebson.NewDocument(
+			"role", rule.role,
+			"fields", fields,
+			"action", rule.action,
+		))))
+	}
+
+	return res
+}
+
+// masksForRoles returns field masks for a user with the given roles.
+//
+// A field is masked only if every role of the user masks it,
+// so additional roles can only grant access.
+// Hashing wins over removal for the same reason.
+func masksForRoles(rules []maskingRule, roles []string) fieldMasks {
+	if len(rules) == 0 || len(roles) == 0 {
+		return nil
+	}
+
+	var res fieldMasks
+
+	for i, role := range roles {
+		masks := make(fieldMasks)
+
+		for _, rule := range rules {
+			if rule.role != role {
+				continue
+			}
+
+			for _, f := range rule.fields {
+				if masks[f] != maskHash {
+					masks[f] = rule.action
+				}
+			}
+		}
+
+		if i == 0 {
+			res = masks
+			continue
+		}
+
+		for f := range res {
+			switch masks[f] {
+			case "":
+				delete(res, f)
+			case maskHash:
+				res[f] = maskHash
+			}
+		}
+	}
+
+	if len(res) == 0 {
+		return nil
+	}
+
+	return res
+}
+
+// apply masks fields of the given deeply decoded document in place.
+// Arrays on the path are traversed, masking fields of all embedded documents.
+func (m fieldMasks) apply(doc *wirebson.Document) {
+	for path, action := range m {
+		maskPath(doc, strings.Split(path, "."), action)
+	}
+}
+
+// maskPath masks the given path of the document or array value.
+func maskPath(v any, path []string, action string) {
+	switch v := v.(type) {
+	case *wirebson.Document:
+		f := v.Get(path[0])
+		if f == nil {
+			return
+		}
+
+		if len(path) > 1 {
+			maskPath(f, path[1:], action)
+			return
+		}
+
+		if action == maskRemove {
+			v.Remove(path[0])
+			return
+		}
+
+		must.NoError(v.Replace(path[0], maskHashValue(f)))
+
+	case *wirebson.Array:
+		for e := range v.Values() {
+			maskPath(e, path, action)
+		}
+	}
+}
+
+// maskHashValue returns the hex-encoded SHA-256 hash of the BSON encoding of the given value.
+// Equal values have equal hashes, so hashed fields can still be used for grouping and joining.
+func maskHashValue(v any) string {
+	raw := must.NotFail(must.NotFail(wirebson.NewDocument("", v)).Encode())
+	h := sha256.Sum256(raw)
+
+	return hex.EncodeToString(h[:])
+}
+
+// covers returns the first masked path that is equal to the given path,
+// or is a prefix or a suffix of it, if any.
+func (m fieldMasks) covers(path string) (string, bool) {
+	for f := range m {
+		if f == path || strings.HasPrefix(path, f+".") || strings.HasPrefix(f, path+".") {
+			return f, true
+		}
+	}
+
+	return "", false
+}
+
+// references returns the first masked path covering the given dot notation path
+// with array indexes and positional operators removed, if any.
+func (m fieldMasks) references(path string) (string, bool) {
+	parts := strings.Split(path, ".")
+
+	parts = slices.DeleteFunc(parts, func(part string) bool {
+		if strings.HasPrefix(part, "$") {
+			return true
+		}
+
+		_, err := strconv.Atoi(part)
+
+		return err == nil
+	})
+
+	if len(parts) == 0 {
+		return "", false
+	}
+
+	return m.covers(strings.Join(parts, "."))
+}
+
+// first returns the first masked path in sorted order.
+// It is used for values that can read any field.
+func (m fieldMasks) first() string {
+	return slices.Min(slices.Collect(maps.Keys(m)))
+}
+
+// filterReference returns the first masked path referenced by the given deeply decoded query filter, if any.
+// Field names of the filter are passed through the given function (if not nil) to get paths.
+func (m fieldMasks) filterReference(filter any, paths func(string) []string) (string, bool) {
+	d, _ := filter.(*wirebson.Document)
+	if d == nil {
+		return "", false
+	}
+
+	for f, v := range d.All() {
+		switch f {
+		case "$and", "$or", "$nor":
+			if arr, _ := v.(*wirebson.Array); arr != nil {
+				for e := range arr.Values() {
+					if masked, ok := m.filterReference(e, paths); ok {
+						return masked, true
+					}
+				}
+			}
+
+		case "$expr":
+			if masked, ok := m.exprReference(v); ok {
+				return masked, true
+			}
+
+		case "$where", "$jsonSchema":
+			return m.first(), true
+
+		default:
+			// other operators such as $comment and $text
+			if strings.HasPrefix(f, "$") {
+				continue
+			}
+
+			candidates := []string{f}
+			if paths != nil {
+				candidates = paths(f)
+			}
+
+			for _, p := range candidates {
+				if masked, ok := m.references(p); ok {
+					return masked, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// exprReference returns the first masked path referenced by the given deeply decoded aggregation expression,
+// if any.
+func (m fieldMasks) exprReference(expr any) (string, bool) {
+	switch expr := expr.(type) {
+	case string:
+		var path string
+
+		switch {
+		case expr == "$$ROOT" || expr == "$$CURRENT":
+			return m.first(), true
+		case strings.HasPrefix(expr, "$$ROOT."):
+			path = strings.TrimPrefix(expr, "$$ROOT.")
+		case strings.HasPrefix(expr, "$$CURRENT."):
+			path = strings.TrimPrefix(expr, "$$CURRENT.")
+		case strings.HasPrefix(expr, "$$"):
+			return "", false
+		case strings.HasPrefix(expr, "$"):
+			path = expr[1:]
+		default:
+			return "", false
+		}
+
+		return m.references(path)
+
+	case *wirebson.Document:
+		for f, v := range expr.All() {
+			if f == "$literal" {
+				continue
+			}
+
+			// field name is not a field path expression
+			if f == "$getField" {
+				field := v
+				if d, _ := v.(*wirebson.Document); d != nil {
+					field = d.Get("field")
+				}
+
+				if name, _ := field.(string); name != "" && !strings.HasPrefix(name, "$") {
+					if masked, ok := m.references(name); ok {
+						return masked, true
+					}
+				}
+			}
+
+			if masked, ok := m.exprReference(v); ok {
+				return masked, true
+			}
+		}
+
+	case *wirebson.Array:
+		for v := range expr.Values() {
+			if masked, ok := m.exprReference(v); ok {
+				return masked, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// updateReference returns the first masked path referenced by the given deeply decoded update document
+// or pipeline and array filters (that may be nil), if any.
+//
+// Updates of masked fields are rejected too, as the number of modified documents reveals
+// whether the new value is equal to the old one.
+func (m fieldMasks) updateReference(update any, arrayFilters *wirebson.Array) (string, bool) {
+	if pipeline, _ := update.(*wirebson.Array); pipeline != nil {
+		return m.exprReference(pipeline)
+	}
+
+	d, _ := update.(*wirebson.Document)
+	if d == nil {
+		return "", false
+	}
+
+	// array filter identifiers to paths of arrays they match elements of
+	identifiers := map[string][]string{}
+
+	for op, v := range d.All() {
+		// replacement document
+		if !strings.HasPrefix(op, "$") {
+			return "", false
+		}
+
+		fields, _ := v.(*wirebson.Document)
+		if fields == nil {
+			continue
+		}
+
+		for path, fv := range fields.All() {
+			if masked, ok := m.references(path); ok {
+				return masked, true
+			}
+
+			if to, _ := fv.(string); op == "$rename" && to != "" {
+				if masked, ok := m.references(to); ok {
+					return masked, true
+				}
+			}
+
+			parts := strings.Split(path, ".")
+
+			for i, part := range parts {
+				if strings.HasPrefix(part, "$[") && part != "$[]" {
+					id := strings.TrimSuffix(strings.TrimPrefix(part, "$["), "]")
+					identifiers[id] = append(identifiers[id], strings.Join(parts[:i], "."))
+				}
+			}
+		}
+	}
+
+	if arrayFilters == nil {
+		return "", false
+	}
+
+	paths := func(f string) []string {
+		id, rest, _ := strings.Cut(f, ".")
+
+		var res []string
+
+		for _, prefix := range identifiers[id] {
+			if rest != "" {
+				prefix += "." + rest
+			}
+
+			res = append(res, prefix)
+		}
+
+		return res
+	}
+
+	for v := range arrayFilters.Values() {
+		if masked, ok := m.filterReference(v, paths); ok {
+			return masked, true
+		}
+	}
+
+	return "", false
+}
+
+// checkQuery returns an error if the given command or `update` and `delete` statement
+// filters, sorts, or updates documents by masked fields.
+// Results of such commands could reveal masked values.
+func (m fieldMasks) checkQuery(command string, doc *wirebson.Document) error {
+	if m == nil {
+		return nil
+	}
+
+	get := func(field string) (any, error) {
+		switch v := doc.Get(field).(type) {
+		case wirebson.RawDocument:
+			return v.DecodeDeep()
+		case wirebson.RawArray:
+			return v.DecodeDeep()
+		default:
+			return v, nil
+		}
+	}
+
+	for _, field := range []string{"filter", "query", "q"} {
+		v, err := get(field)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if masked, ok := m.filterReference(v, nil); ok {
+			msg := fmt.Sprintf("filtering by masked field '%s' is not allowed", masked)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+		}
+	}
+
+	for _, field := range []string{"sort", "min", "max"} {
+		v, err := get(field)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		d, _ := v.(*wirebson.Document)
+		if d == nil {
+			continue
+		}
+
+		for f := range d.All() {
+			if masked, ok := m.references(f); ok {
+				msg := fmt.Sprintf("%s by masked field '%s' is not allowed", field, masked)
+				return mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+			}
+		}
+	}
+
+	arrayFilters, err := get("arrayFilters")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	af, _ := arrayFilters.(*wirebson.Array)
+
+	for _, field := range []string{"update", "u"} {
+		v, err := get(field)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if masked, ok := m.updateReference(v, af); ok {
+			msg := fmt.Sprintf("update referencing masked field '%s' is not allowed", masked)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+		}
+	}
+
+	return nil
+}
+
+// checkGeoNear returns an error if the given pipeline starts with `$geoNear` stage
+// that filters documents by masked fields;
+// masked fields are removed only after that stage.
+func (m fieldMasks) checkGeoNear(command string, pipeline *wirebson.Array) error {
+	if m == nil || pipeline.Len() == 0 {
+		return nil
+	}
+
+	var stage *wirebson.Document
+
+	switch s := pipeline.Get(0).(type) {
+	case *wirebson.Document:
+		stage = s
+	case wirebson.RawDocument:
+		var err error
+		if stage, err = s.DecodeDeep(); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if stage == nil || stage.Command() != "$geoNear" {
+		return nil
+	}
+
+	spec, err := decodeDocument(stage.Get("$geoNear"))
+	if spec == nil || err != nil {
+		return err
+	}
+
+	if key, _ := spec.Get("key").(string); key != "" {
+		if masked, ok := m.references(key); ok {
+			msg := fmt.Sprintf("$geoNear stage can't use masked field '%s'", masked)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+		}
+	}
+
+	query := spec.Get("query")
+	if raw, ok := query.(wirebson.RawDocument); ok {
+		if query, err = raw.DecodeDeep(); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if masked, ok := m.filterReference(query, nil); ok {
+		msg := fmt.Sprintf("filtering by masked field '%s' is not allowed", masked)
+		return mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+	}
+
+	return nil
+}
+
+// checkStatements returns an error if any statement of the given `update` or `delete` command
+// references masked fields of the collection for the authenticated user; see [fieldMasks.checkQuery].
+func (h *Handler) checkStatements(ctx context.Context, dbName string, doc *wirebson.Document, seq []byte) error {
+	command := doc.Command()
+	collection, _ := doc.Get(command).(string)
+
+	masks, err := h.fieldMasks(ctx, dbName, collection)
+	if err != nil || masks == nil {
+		return err
+	}
+
+	statements, err := commandStatements(doc, documentFilterStatements[command], seq)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, s := range statements {
+		if err = masks.checkQuery(command, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkProjection returns an error if the given `find` projection uses expressions
+// that could read masked fields under other names.
+func (m fieldMasks) checkProjection(command string, projection any) error {
+	p, _ := projection.(wirebson.AnyDocument)
+	if m == nil || p == nil {
+		return nil
+	}
+
+	doc, err := p.Decode()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for f, v := range doc.All() {
+		switch v.(type) {
+		case float64, int32, int64, bool:
+			continue
+		}
+
+		if masked, ok := m.references(f); ok {
+			msg := fmt.Sprintf("projection of masked field '%s' is not allowed", masked)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+		}
+
+		if _, ok := v.(string); ok {
+			msg := fmt.Sprintf("projection expression for field '%s' is not allowed for masked collections", f)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+		}
+
+		if d, ok := v.(wirebson.AnyDocument); ok {
+			if dd, _ := d.Decode(); dd != nil && !slices.Contains([]string{"$slice", "$elemMatch", "$meta"}, dd.Command()) {
+				msg := fmt.Sprintf("projection expression for field '%s' is not allowed for masked collections", f)
+				return mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+			}
+		}
+	}
+
+	return nil
+}
+
+// unsetStage returns `$unset` aggregation stage for all masked fields.
+//
+// Aggregation pipelines can rename and compute fields,
+// so masked fields are removed before the first stage, including hashed ones.
+func (m fieldMasks) unsetStage() *wirebson.Document {
+	fields := make([]string, 0, len(m))
+	for f := range m {
+		fields = append(fields, f)
+	}
+
+	slices.Sort(fields)
+
+	arr := wirebson.MakeArray(len(fields))
+	for _, f := range fields {
+		must.NoError(arr.Add(f))
+	}
+
+	return must.NotFail(wirebson.NewDocument("$unset", arr))
+}
+
+// fieldMasks returns field masks of the given collection for the authenticated user,
+// or nil if nothing is masked.
+func (h *Handler) fieldMasks(ctx context.Context, dbName, collection string) (fieldMasks, error) {
+	policy, roles, _, err := h.userAccessPolicy(ctx, dbName, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return masksForRoles(policy.masking, roles), nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestMasksForRoles(t *testing.T) {
+	t.Parallel()
+
+	rules := []maskingRule{
+		{role: "analyst", action: maskRemove, fields: []string{"ssn", "email"}},
+		{role: "support", action: maskHash, fields: []string{"email"}},
+	}
+
+	for name, tc := range map[string]struct {
+		roles    []string
+		expected fieldMasks
+	}{
+		"NoRoles":   {},
+		"Unrelated": {roles: []string{"readWriteAnyDatabase"}},
+		"Single": {
+			roles:    []string{"analyst"},
+			expected: fieldMasks{"ssn": maskRemove, "email": maskRemove},
+		},
+		"Intersection": {
+			roles:    []string{"analyst", "support"},
+			expected: fieldMasks{"email": maskHash},
+		},
+		"Granted": {
+			roles: []string{"analyst", "root"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, masksForRoles(rules, tc.roles))
+		})
+	}
+}
+
+func TestFieldMasksApply(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(wirebson.NewDocument(
+		"_id", int32(1),
+		"ssn", "123-45-6789",
+		"contacts", must.NotFail(wirebson.NewArray(
+			must.NotFail(wirebson.NewDocument("email", "a@example.com", "name", "a")),
+			must.NotFail(wirebson.NewDocument("name", "b")),
+		)),
+	))
+
+	fieldMasks{"ssn": maskRemove, "contacts.email": maskHash, "missing": maskRemove}.apply(doc)
+
+	assert.Equal(t, []string{"_id", "contacts"}, doc.FieldNames())
+
+	contacts := doc.Get("contacts").(*wirebson.Array)
+	email := contacts.Get(0).(*wirebson.Document).Get("email")
+	assert.Equal(t, maskHashValue("a@example.com"), email)
+	assert.Len(t, email, 64)
+	assert.Nil(t, contacts.Get(1).(*wirebson.Document).Get("email"))
+}
+
+func TestGetMaskingRules(t *testing.T) {
+	t.Parallel()
+
+	rules := must.NotFail(wirebson.NewArray(
+		must.NotFail(wirebson.NewDocument("role", "analyst", "fields", must.NotFail(wirebson.NewArray("ssn")))),
+		must.NotFail(wirebson.NewDocument(
+			"role", "support", "fields", must.NotFail(wirebson.NewArray("a.b")), "action", maskHash,
+		)),
+	))
+
+	actual, err := getMaskingRules("ferretSetMaskingPolicy", rules)
+	require.NoError(t, err)
+
+	expected := []maskingRule{
+		{role: "analyst", action: maskRemove, fields: []string{"ssn"}},
+		{role: "support", action: maskHash, fields: []string{"a.b"}},
+	}
+	assert.Equal(t, expected, actual)
+
+	roundtrip, err := getMaskingRules("ferretSetMaskingPolicy", maskingRulesArray(actual))
+	require.NoError(t, err)
+	assert.Equal(t, expected, roundtrip)
+
+	for name, rule := range map[string]*wirebson.Document{
+		"NoRole":    wirebson.MustDocument("fields", wirebson.MustArray("a")),
+		"NoFields":  wirebson.MustDocument("role", "r"),
+		"BadPath":   wirebson.MustDocument("role", "r", "fields", wirebson.MustArray("$a")),
+		"BadAction": wirebson.MustDocument("role", "r", "fields", wirebson.MustArray("a"), "action", "x"),
+	} {
+		_, err = getMaskingRules("ferretSetMaskingPolicy", must.NotFail(wirebson.NewArray(rule)))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestFieldMasksCheckProjection(t *testing.T) {
+	t.Parallel()
+
+	masks := fieldMasks{"ssn": maskRemove}
+
+	for name, tc := range map[string]struct {
+		projection *wirebson.Document
+		err        bool
+	}{
+		"Inclusion": {projection: must.NotFail(wirebson.NewDocument("ssn", int32(1), "name", true))},
+		"Slice":     {projection: wirebson.MustDocument("tags", wirebson.MustDocument("$slice", int32(1)))},
+		"Rename":    {projection: must.NotFail(wirebson.NewDocument("copy", "$ssn")), err: true},
+		"Expression": {
+			projection: must.NotFail(wirebson.NewDocument("copy", must.NotFail(wirebson.NewDocument("$concat", "$ssn")))),
+			err:        true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := masks.checkProjection("find", tc.projection)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	assert.NoError(t, fieldMasks(nil).checkProjection("find", must.NotFail(wirebson.NewDocument("copy", "$ssn"))))
+}
+
+func TestFieldMasksCheckQuery(t *testing.T) {
+	t.Parallel()
+
+	masks := fieldMasks{"ssn": maskRemove, "cards.number": maskHash}
+
+	for name, tc := range map[string]struct {
+		doc *wirebson.Document
+		err bool
+	}{
+		"Filter": {
+			doc: wirebson.MustDocument("filter", wirebson.MustDocument("name", "a", "tags", "x")),
+		},
+		"FilterMasked": {
+			doc: wirebson.MustDocument("filter", wirebson.MustDocument("ssn", "123-45-6789")),
+			err: true,
+		},
+		"FilterIndex": {
+			doc: wirebson.MustDocument("q", wirebson.MustDocument("cards.0.number", "4111")),
+			err: true,
+		},
+		"FilterParent": {
+			doc: wirebson.MustDocument("query", wirebson.MustDocument(
+				"cards", wirebson.MustDocument("$elemMatch", wirebson.MustDocument("number", "4111")),
+			)),
+			err: true,
+		},
+		"FilterOr": {
+			doc: wirebson.MustDocument("filter", wirebson.MustDocument("$or", wirebson.MustArray(
+				wirebson.MustDocument("name", "a"),
+				wirebson.MustDocument("ssn", "123-45-6789"),
+			))),
+			err: true,
+		},
+		"FilterExpr": {
+			doc: wirebson.MustDocument("filter", wirebson.MustDocument("$expr", wirebson.MustDocument(
+				"$eq", wirebson.MustArray("$ssn", "123-45-6789"),
+			))),
+			err: true,
+		},
+		"FilterExprGetField": {
+			doc: wirebson.MustDocument("filter", wirebson.MustDocument("$expr", wirebson.MustDocument(
+				"$eq", wirebson.MustArray(wirebson.MustDocument("$getField", "ssn"), "123-45-6789"),
+			))),
+			err: true,
+		},
+		"FilterExprRoot": {
+			doc: wirebson.MustDocument("filter", wirebson.MustDocument("$expr", wirebson.MustDocument(
+				"$gt", wirebson.MustArray("$$ROOT", wirebson.MakeDocument(0)),
+			))),
+			err: true,
+		},
+		"Sort": {
+			doc: wirebson.MustDocument("sort", wirebson.MustDocument("name", int32(1), "ssn", int32(-1))),
+			err: true,
+		},
+		"Update": {
+			doc: wirebson.MustDocument("u", wirebson.MustDocument("$set", wirebson.MustDocument("name", "b"))),
+		},
+		"UpdateMasked": {
+			doc: wirebson.MustDocument("u", wirebson.MustDocument("$set", wirebson.MustDocument("ssn", "0"))),
+			err: true,
+		},
+		"Rename": {
+			doc: wirebson.MustDocument("update", wirebson.MustDocument("$rename", wirebson.MustDocument("ssn", "copy"))),
+			err: true,
+		},
+		"Pipeline": {
+			doc: wirebson.MustDocument("u", wirebson.MustArray(
+				wirebson.MustDocument("$set", wirebson.MustDocument("copy", "$ssn")),
+			)),
+			err: true,
+		},
+		"ArrayFilters": {
+			doc: wirebson.MustDocument(
+				"u", wirebson.MustDocument("$set", wirebson.MustDocument("cards.$[c].label", "x")),
+				"arrayFilters", wirebson.MustArray(wirebson.MustDocument("c.number", "4111")),
+			),
+			err: true,
+		},
+		"ArrayFiltersUnmasked": {
+			doc: wirebson.MustDocument(
+				"u", wirebson.MustDocument("$set", wirebson.MustDocument("cards.$[c].label", "x")),
+				"arrayFilters", wirebson.MustArray(wirebson.MustDocument("c.label", "y")),
+			),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := masks.checkQuery("update", tc.doc)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	assert.NoError(t, fieldMasks(nil).checkQuery("find", wirebson.MustDocument("filter", wirebson.MustDocument("ssn", "x"))))
+}
@@ -0,0 +1,265 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// materializedViewMetadata is a kind of collection metadata with materialized view definition.
+const materializedViewMetadata = "materializedView"
+
+// materializedView represents a definition of a collection populated by an aggregation pipeline with `$merge` stage.
+type materializedView struct {
+	viewOn      string          // source collection
+	pipeline    *wirebson.Array // ends with `$merge` into the view collection
+	interval    time.Duration   // 0 if not refreshed on schedule
+	lastRefresh time.Time       // zero if never refreshed
+}
+
+// materializedViewFromMetadata returns a materialized view definition stored in collection metadata.
+func materializedViewFromMetadata(md *wirebson.Document) *materializedView {
+	viewOn, _ := md.Get("viewOn").(string)
+	pipeline, _ := md.Get("pipeline").(*wirebson.Array)
+
+	if viewOn == "" || pipeline == nil {
+		return nil
+	}
+
+	secs, _ := md.Get("refreshIntervalSecs").(int64)
+	lastRefresh, _ := md.Get("lastRefresh").(time.Time)
+
+	return &materializedView{
+		viewOn:      viewOn,
+		pipeline:    pipeline,
+		interval:    time.Duration(secs) * time.Second,
+		lastRefresh: lastRefresh,
+	}
+}
+
+// checkMergeStage checks that the given pipeline ends with `$merge` stage into the given collection
+// of the same database.
+func checkMergeStage(command, dbName, collection string, pipeline *wirebson.Array) error {
+	msg := fmt.Sprintf("%s pipeline must end with $merge stage into '%s.%s'", command, dbName, collection)
+	errMerge := mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+
+	if pipeline.Len() == 0 {
+		return errMerge
+	}
+
+	last, _ := pipeline.Get(pipeline.Len() - 1).(*wirebson.Document)
+	if last == nil || last.Len() != 1 {
+		return errMerge
+	}
+
+	merge := last.Get("$merge")
+	if d, ok := merge.(*wirebson.Document); ok {
+		merge = d.Get("into")
+	}
+
+	switch into := merge.(type) {
+	case string:
+		if into == collection {
+			return nil
+		}
+
+	case *wirebson.Document:
+		if db, ok := into.Get("db").(string); ok && db != dbName {
+			return errMerge
+		}
+
+		if into.Get("coll") == collection {
+			return nil
+		}
+	}
+
+	return errMerge
+}
+
+// materializedViews tracks refreshes of materialized views.
+//
+// The zero value is ready to use.
+type materializedViews struct {
+	scheduled atomic.Bool // scheduled refreshes are in progress
+
+	mu      sync.Mutex
+	running map[string]struct{} // namespaces of views being refreshed
+}
+
+// start marks the given view as being refreshed.
+// It returns false if it is already being refreshed.
+func (mv *materializedViews) start(ns string) bool {
+	mv.mu.Lock()
+	defer mv.mu.Unlock()
+
+	if _, ok := mv.running[ns]; ok {
+		return false
+	}
+
+	if mv.running == nil {
+		mv.running = make(map[string]struct{})
+	}
+
+	mv.running[ns] = struct{}{}
+
+	return true
+}
+
+// finish marks the given view as not being refreshed.
+func (mv *materializedViews) finish(ns string) {
+	mv.mu.Lock()
+	defer mv.mu.Unlock()
+
+	delete(mv.running, ns)
+}
+
+// refreshMaterializedView re-runs the pipeline of the given materialized view
+// and stores its definition with the refresh time in collection metadata.
+// It returns the refresh time.
+func (h *Handler) refreshMaterializedView(ctx context.Context, dbName, collection string, view *materializedView) (time.Time, error) { //nolint:lll // for readability
+	ns := dbName + "." + collection
+
+	if !h.materializedViews.start(ns) {
+		msg := fmt.Sprintf("materialized view %s is already being refreshed", ns)
+		return time.Time{}, mongoerrors.New(mongoerrors.ErrConflictingOperationInProgress, msg)
+	}
+	defer h.materializedViews.finish(ns)
+
+	spec, err := must.NotFail(wirebson.NewDocument(
+		"aggregate", view.viewOn,
+		"pipeline", view.pipeline,
+		"cursor", wirebson.MakeDocument(0),
+		"$db", dbName,
+	)).Encode()
+	if err != nil {
+		return time.Time{}, lazyerrors.Error(err)
+	}
+
+	// $merge does not create the collection if the pipeline returns no documents,
+	// but metadata is stored in it
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		_, err := documentdb_api.CreateCollection(ctx, conn, h.L, dbName, collection)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, lazyerrors.Error(err)
+	}
+
+	started := time.Now()
+
+	_, cursorID, err := h.Pool.Aggregate(ctx, dbName, spec)
+	if err != nil {
+		return time.Time{}, lazyerrors.Error(err)
+	}
+
+	if cursorID != 0 {
+		_ = h.Pool.KillCursor(ctx, cursorID)
+	}
+
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		return documentdb.UpdateMetadata(ctx, conn, dbName, collection, materializedViewMetadata, func(md *wirebson.Document) error { //nolint:lll // for readability
+			for _, f := range md.FieldNames() {
+				md.Remove(f)
+			}
+
+			must.NoError(md.Add("viewOn", view.viewOn))
+			must.NoError(md.Add("pipeline", view.pipeline))
+			must.NoError(md.Add("refreshIntervalSecs", int64(view.interval/time.Second)))
+			must.NoError(md.Add("lastRefresh", started))
+			must.NoError(md.Add("lastRefreshDurationMillis", time.Since(started).Milliseconds()))
+
+			return nil
+		})
+	})
+	if err != nil {
+		return time.Time{}, lazyerrors.Error(err)
+	}
+
+	return started, nil
+}
+
+// refreshScheduledViews refreshes materialized views with expired refresh intervals in the background.
+//
+// It does not block; if the previous call is still in progress, it does nothing.
+func (h *Handler) refreshScheduledViews(ctx context.Context) {
+	if !h.materializedViews.scheduled.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer h.materializedViews.scheduled.Store(false)
+
+		var all []documentdb.CollectionMetadata
+
+		err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+			var err error
+			all, err = documentdb.AllMetadata(ctx, conn, materializedViewMetadata)
+
+			return err
+		})
+		if err != nil {
+			h.L.WarnContext(ctx, "Failed to list materialized views", logging.Error(err))
+			return
+		}
+
+		for _, md := range all {
+			view := materializedViewFromMetadata(md.Metadata)
+			if view == nil || view.interval <= 0 || time.Since(view.lastRefresh) < view.interval {
+				continue
+			}
+
+			l := h.L.With(slog.String("ns", md.DB+"."+md.Collection))
+
+			if err = h.refreshScheduledView(ctx, md.DB, md.Collection, view); err != nil {
+				l.WarnContext(ctx, "Failed to refresh materialized view", logging.Error(err))
+				continue
+			}
+
+			l.DebugContext(ctx, "Materialized view refreshed")
+		}
+	}()
+}
+
+// refreshScheduledView refreshes the given materialized view in the background
+// like `ferretRefreshView` command does, waiting for the fsync lock release.
+func (h *Handler) refreshScheduledView(ctx context.Context, dbName, collection string, view *materializedView) error {
+	done, err := h.fsync.startWrite(ctx)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer done()
+
+	if _, err = h.refreshMaterializedView(ctx, dbName, collection, view); err != nil {
+		return err
+	}
+
+	return nil
+}
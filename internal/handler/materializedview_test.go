@@ -0,0 +1,76 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestCheckMergeStage(t *testing.T) {
+	t.Parallel()
+
+	match := must.NotFail(wirebson.NewDocument("$match", wirebson.MakeDocument(0)))
+
+	for name, tc := range map[string]struct {
+		merge any // nil for no $merge stage
+		ok    bool
+	}{
+		"String": {
+			merge: "view",
+			ok:    true,
+		},
+		"Into": {
+			merge: must.NotFail(wirebson.NewDocument("into", "view", "whenMatched", "replace")),
+			ok:    true,
+		},
+		"IntoSameDB": {
+			merge: must.NotFail(wirebson.NewDocument(
+				"into", must.NotFail(wirebson.NewDocument("db", "db", "coll", "view")),
+			)),
+			ok: true,
+		},
+		"IntoOtherDB": {
+			merge: must.NotFail(wirebson.NewDocument(
+				"into", must.NotFail(wirebson.NewDocument("db", "other", "coll", "view")),
+			)),
+		},
+		"OtherCollection": {
+			merge: "other",
+		},
+		"NoMerge": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := must.NotFail(wirebson.NewArray(match))
+			if tc.merge != nil {
+				must.NoError(pipeline.Add(must.NotFail(wirebson.NewDocument("$merge", tc.merge))))
+			}
+
+			err := checkMergeStage("ferretRefreshView", "db", "view", pipeline)
+			if tc.ok {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.Error(t, err)
+		})
+	}
+}
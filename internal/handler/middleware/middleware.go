@@ -15,7 +15,13 @@
 // Package middleware provides wrappers for command handlers.
 package middleware
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrCloseConnection is returned by handlers to close the client connection without sending a response.
+var ErrCloseConnection = errors.New("close connection")
 
 // HandleFunc represents a function/method that processes a single request.
 //
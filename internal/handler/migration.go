@@ -0,0 +1,427 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// States of background migrations.
+const (
+	migrationRunning   = "running"
+	migrationCompleted = "completed"
+	migrationAborted   = "aborted"
+	migrationFailed    = "failed"
+)
+
+// maxFinishedMigrations is the number of finished migrations kept for reporting.
+const maxFinishedMigrations = 100
+
+// errMigrationAborted is used as a cancellation cause for aborted migrations.
+var errMigrationAborted = errors.New("migration aborted")
+
+// migration represents a background migration started by `ferretMigrate` command.
+//
+// It rewrites documents of a single collection in batches ordered by _id.
+// The last processed _id is tracked, so the migration could be resumed after it
+// with a new command even if documents still match the filter after the update.
+//
+//nolint:vet // for readability
+type migration struct {
+	id         int64
+	db         string
+	collection string
+	command    *wirebson.Document // original command for reporting
+	filter     *wirebson.Document // may be nil
+	update     any                // *wirebson.Document or *wirebson.Array
+	batchSize  int32
+	throttle   time.Duration
+	started    time.Time
+
+	cancel context.CancelCauseFunc
+	done   chan struct{} // closed when the migration is finished
+
+	mu       sync.Mutex
+	state    string
+	lastID   any // nil if no documents were processed yet
+	batches  int64
+	matched  int64
+	modified int64
+	err      error
+	finished time.Time
+}
+
+// migrationStatus represents a snapshot of the migration state.
+type migrationStatus struct {
+	state    string
+	lastID   any
+	batches  int64
+	matched  int64
+	modified int64
+	err      error
+}
+
+// status returns the current state of the migration.
+func (m *migration) status() migrationStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return migrationStatus{
+		state:    m.state,
+		lastID:   m.lastID,
+		batches:  m.batches,
+		matched:  m.matched,
+		modified: m.modified,
+		err:      m.err,
+	}
+}
+
+// currentOp returns the migration description for the `currentOp` command output.
+func (m *migration) currentOp() *wirebson.Document {
+	s := m.status()
+
+	end := time.Now()
+	if s.state != migrationRunning {
+		m.mu.Lock()
+		end = m.finished
+		m.mu.Unlock()
+	}
+
+	running := end.Sub(m.started)
+
+	res := must.NotFail(wirebson.NewDocument(
+		"type", "op",
+		"desc", "ferretMigrate",
+		"active", s.state == migrationRunning,
+		"migrationId", m.id,
+		"ns", m.db+"."+m.collection,
+		"secs_running", int64(running.Seconds()),
+		"microsecs_running", running.Microseconds(),
+		"command", m.command,
+		"state", s.state,
+		"progress", must.NotFail(wirebson.NewDocument(
+			"batches", s.batches,
+			"done", s.matched,
+			"modified", s.modified,
+		)),
+	))
+
+	if s.lastID != nil {
+		must.NoError(res.Add("lastId", s.lastID))
+	}
+
+	if s.err != nil {
+		must.NoError(res.Add("errmsg", s.err.Error()))
+	}
+
+	return res
+}
+
+// migrations is a registry of background migrations.
+//
+// The zero value is ready to use.
+type migrations struct {
+	mu     sync.Mutex
+	lastID int64
+	m      []*migration // ordered by id
+}
+
+// add assigns an ID to the given migration and stores it,
+// removing the oldest finished migrations if there are too many of them.
+func (ms *migrations) add(m *migration) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.lastID++
+	m.id = ms.lastID
+
+	var finished int
+
+	for _, m := range ms.m {
+		if m.status().state != migrationRunning {
+			finished++
+		}
+	}
+
+	ms.m = slices.DeleteFunc(ms.m, func(m *migration) bool {
+		if finished < maxFinishedMigrations || m.status().state == migrationRunning {
+			return false
+		}
+
+		finished--
+
+		return true
+	})
+
+	ms.m = append(ms.m, m)
+}
+
+// get returns the migration with the given ID, or nil.
+func (ms *migrations) get(id int64) *migration {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, m := range ms.m {
+		if m.id == id {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// all returns all known migrations.
+func (ms *migrations) all() []*migration {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return slices.Clone(ms.m)
+}
+
+// abortAll aborts all running migrations and waits for them to finish.
+func (ms *migrations) abortAll() {
+	for _, m := range ms.all() {
+		m.cancel(errMigrationAborted)
+		<-m.done
+	}
+}
+
+// runMigration runs the given migration until it is completed, failed, or aborted.
+func (h *Handler) runMigration(ctx context.Context, m *migration) {
+	defer close(m.done)
+
+	l := h.L.With(slog.Int64("migration", m.id), slog.String("ns", m.db+"."+m.collection))
+	l.InfoContext(ctx, "Migration started")
+
+	err := h.migrate(ctx, m)
+
+	m.mu.Lock()
+
+	switch {
+	case err == nil:
+		m.state = migrationCompleted
+	case errors.Is(context.Cause(ctx), errMigrationAborted):
+		m.state = migrationAborted
+	default:
+		m.state = migrationFailed
+		m.err = err
+	}
+
+	m.finished = time.Now()
+	m.mu.Unlock()
+
+	s := m.status()
+	attrs := []slog.Attr{
+		slog.String("state", s.state),
+		slog.Any("last_id", s.lastID),
+		slog.Int64("matched", s.matched),
+		slog.Int64("modified", s.modified),
+	}
+
+	if s.err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Migration failed", append(attrs, slog.String("error", s.err.Error()))...)
+		return
+	}
+
+	l.LogAttrs(ctx, slog.LevelInfo, "Migration finished", attrs...)
+}
+
+// migrate processes batches of the migration until there are no more documents.
+func (h *Handler) migrate(ctx context.Context, m *migration) error {
+	for {
+		ids, err := h.migrationBatchIDs(ctx, m)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		matched, modified, err := h.migrationUpdate(ctx, m, ids)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		m.mu.Lock()
+		m.lastID = ids[len(ids)-1]
+		m.batches++
+		m.matched += matched
+		m.modified += modified
+		m.mu.Unlock()
+
+		if len(ids) < int(m.batchSize) {
+			return nil
+		}
+
+		if m.throttle > 0 {
+			t := time.NewTimer(m.throttle)
+
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return lazyerrors.Error(context.Cause(ctx))
+			}
+		}
+	}
+}
+
+// migrationFilter returns the filter for the next batch of the migration.
+func migrationFilter(filter *wirebson.Document, lastID any) *wirebson.Document {
+	var conds []any
+
+	if filter != nil {
+		conds = append(conds, filter)
+	}
+
+	if lastID != nil {
+		// $expr uses the total BSON order, while $gt would only match _id values of the same type
+		conds = append(conds, must.NotFail(wirebson.NewDocument(
+			"$expr", must.NotFail(wirebson.NewDocument(
+				"$gt", must.NotFail(wirebson.NewArray(
+					"$_id",
+					must.NotFail(wirebson.NewDocument("$literal", lastID)),
+				)),
+			)),
+		)))
+	}
+
+	switch len(conds) {
+	case 0:
+		return wirebson.MakeDocument(0)
+	case 1:
+		return conds[0].(*wirebson.Document)
+	default:
+		return must.NotFail(wirebson.NewDocument("$and", must.NotFail(wirebson.NewArray(conds...))))
+	}
+}
+
+// migrationBatchIDs returns _id values of the next batch of the migration.
+func (h *Handler) migrationBatchIDs(ctx context.Context, m *migration) ([]any, error) {
+	s := m.status()
+
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument(
+		"find", m.collection,
+		"filter", migrationFilter(m.filter, s.lastID),
+		"sort", must.NotFail(wirebson.NewDocument("_id", int32(1))),
+		"projection", must.NotFail(wirebson.NewDocument("_id", int32(1))),
+		"limit", int64(m.batchSize),
+		"batchSize", m.batchSize,
+		"singleBatch", true,
+	)).Encode())
+
+	page, cursorID, err := h.Pool.Find(ctx, m.db, spec)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if cursorID != 0 {
+		h.Pool.KillCursor(ctx, cursorID)
+	}
+
+	doc, err := page.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	cursor, ok := doc.Get("cursor").(*wirebson.Document)
+	if !ok {
+		return nil, lazyerrors.Errorf("no cursor in the page: %s", doc.LogMessage())
+	}
+
+	batch, ok := cursor.Get("firstBatch").(*wirebson.Array)
+	if !ok {
+		return nil, lazyerrors.Errorf("no firstBatch in the page: %s", doc.LogMessage())
+	}
+
+	res := make([]any, 0, batch.Len())
+
+	for v := range batch.Values() {
+		res = append(res, v.(*wirebson.Document).Get("_id"))
+	}
+
+	return res, nil
+}
+
+// migrationUpdate applies the migration update to documents with given _id values.
+// It returns the number of matched and modified documents.
+func (h *Handler) migrationUpdate(ctx context.Context, m *migration, ids []any) (int64, int64, error) {
+	q := must.NotFail(wirebson.NewDocument(
+		"_id", must.NotFail(wirebson.NewDocument("$in", must.NotFail(wirebson.NewArray(ids...)))),
+	))
+
+	if m.filter != nil {
+		q = must.NotFail(wirebson.NewDocument("$and", must.NotFail(wirebson.NewArray(m.filter, q))))
+	}
+
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument(
+		"update", m.collection,
+		"updates", must.NotFail(wirebson.NewArray(must.NotFail(wirebson.NewDocument(
+			"q", q,
+			"u", m.update,
+			"multi", true,
+		)))),
+		"ordered", true,
+	)).Encode())
+
+	done, err := h.fsync.startWrite(ctx)
+	if err != nil {
+		return 0, 0, lazyerrors.Error(err)
+	}
+
+	defer done()
+
+	var res wirebson.RawDocument
+
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		res, _, err = documentdb_api.Update(ctx, conn, h.L, m.db, spec, nil)
+		return err
+	})
+
+	// invalidate even on errors, as some documents could be modified
+	h.invalidateResultCache(ctx, m.db)
+
+	if err != nil {
+		return 0, 0, lazyerrors.Error(err)
+	}
+
+	doc, err := res.DecodeDeep()
+	if err != nil {
+		return 0, 0, lazyerrors.Error(err)
+	}
+
+	if we, ok := doc.Get("writeErrors").(*wirebson.Array); ok && we.Len() > 0 {
+		msg, _ := we.Get(0).(*wirebson.Document).Get("errmsg").(string)
+		return 0, 0, lazyerrors.Errorf("update failed: %s", msg)
+	}
+
+	matched, _ := doc.Get("n").(int32)
+	modified, _ := doc.Get("nModified").(int32)
+
+	return int64(matched), int64(modified), nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	t.Parallel()
+
+	var ms migrations
+
+	running := &migration{state: migrationRunning}
+	ms.add(running)
+
+	for range maxFinishedMigrations + 10 {
+		ms.add(&migration{state: migrationCompleted})
+	}
+
+	all := ms.all()
+	assert.Len(t, all, maxFinishedMigrations+1)
+	assert.Same(t, running, all[0])
+	assert.Equal(t, int64(1), running.id)
+
+	assert.Nil(t, ms.get(2))
+	assert.Equal(t, int64(maxFinishedMigrations+11), all[len(all)-1].id)
+	assert.Same(t, all[len(all)-1], ms.get(maxFinishedMigrations+11))
+}
@@ -41,17 +41,39 @@ func (h *Handler) msgAggregate(connCtx context.Context, req *middleware.Request)
 		return nil, err
 	}
 
+	if err = h.checkFCVGates(doc.Command(), doc.Get("pipeline")); err != nil {
+		return nil, err
+	}
+
 	userID, sessionID, err := h.s.CreateOrUpdateByLSID(connCtx, doc)
 	if err != nil {
 		return nil, err
 	}
 
+	if spec, err = h.applyAccessPolicy(connCtx, dbName, spec, doc); err != nil {
+		return nil, err
+	}
+
+	if spec, _, err = h.applyDefaultCollation(connCtx, dbName, spec, nil); err != nil {
+		return nil, err
+	}
+
+	if spec, err = h.applyIntermediate(connCtx, dbName, spec); err != nil {
+		return nil, err
+	}
+
+	if spec, err = h.applyIndexUsage(connCtx, dbName, spec); err != nil {
+		return nil, err
+	}
+
 	page, cursorID, err := h.Pool.Aggregate(connCtx, dbName, spec)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
 	h.s.AddCursor(connCtx, userID, sessionID, cursorID)
+	h.shareCursor(connCtx, userID, sessionID, cursorID, nil, false)
+	h.trackCursor(connCtx, cursorID, nil, page)
 
 	return middleware.ResponseMsg(page)
 }
@@ -48,13 +48,15 @@ func (h *Handler) msgBuildInfo(connCtx context.Context, req *middleware.Request)
 		must.NoError(buildEnvironment.Add(k, info.BuildEnvironment[k]))
 	}
 
-	versionArray := wirebson.MakeArray(len(info.MongoDBVersionArray))
-	for _, v := range info.MongoDBVersionArray {
+	mongoDBVersion := h.mongoDBVersion()
+
+	versionArray := wirebson.MakeArray(len(mongoDBVersion))
+	for _, v := range mongoDBVersion {
 		must.NoError(versionArray.Add(v))
 	}
 
 	return middleware.ResponseMsg(wirebson.MustDocument(
-		"version", info.MongoDBVersion,
+		"version", h.mongoDBVersionString(),
 		"gitVersion", info.Commit,
 		"modules", wirebson.MakeArray(0),
 		"sysInfo", "deprecated",
@@ -68,8 +70,19 @@ func (h *Handler) msgBuildInfo(connCtx context.Context, req *middleware.Request)
 		"ferretdb", wirebson.MustDocument(
 			"version", info.Version,
 			"package", info.Package,
+			"capabilities", capabilitiesDoc(info),
 		),
 
 		"ok", float64(1),
 	))
 }
+
+// capabilitiesDoc returns build capabilities as a document with sorted keys.
+func capabilitiesDoc(info *version.Info) *wirebson.Document {
+	res := wirebson.MakeDocument(len(info.Capabilities))
+	for _, k := range slices.Sorted(maps.Keys(info.Capabilities)) {
+		must.NoError(res.Add(k, info.Capabilities[k]))
+	}
+
+	return res
+}
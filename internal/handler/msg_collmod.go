@@ -53,14 +53,41 @@ func (h *Handler) msgCollMod(connCtx context.Context, req *middleware.Request) (
 		return nil, lazyerrors.Error(err)
 	}
 
+	compression, err := getCompressionParam(doc, doc.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Get("viewOn") != nil || doc.Get("pipeline") != nil {
+		if err = h.checkViewSource(connCtx, dbName, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	// DocumentDB does not support storage engine options
+	if doc.Get("storageEngine") != nil {
+		doc.Remove("storageEngine")
+
+		if spec, err = doc.Encode(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
 	var res wirebson.RawDocument
 
 	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
-		res, err = documentdb_api.CollMod(connCtx, conn, h.L, dbName, collName, spec)
-		return err
+		if res, err = documentdb_api.CollMod(connCtx, conn, h.L, dbName, collName, spec); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if compression == "" {
+			return nil
+		}
+
+		return setCompression(connCtx, conn, doc.Command(), dbName, collName, compression)
 	})
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, err
 	}
 
 	return middleware.ResponseMsg(res)
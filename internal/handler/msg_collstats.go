@@ -16,10 +16,12 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/FerretDB/wire/wirebson"
 
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
 	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
 	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
@@ -90,5 +92,34 @@ func (h *Handler) msgCollStats(connCtx context.Context, req *middleware.Request)
 		return nil, lazyerrors.Error(err)
 	}
 
-	return middleware.ResponseMsg(page)
+	compression, err := documentdb.CollectionCompression(connCtx, conn.Conn(), dbName, collection)
+	if errors.Is(err, documentdb.ErrCollectionNotFound) {
+		return middleware.ResponseMsg(page)
+	}
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := page.Decode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	ok := res.Get("ok")
+	res.Remove("ok")
+
+	// storage engine-specific information, like `wiredTiger` field in MongoDB
+	err = res.Add("postgresql", wirebson.MustDocument("compression", compression))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if ok != nil {
+		if err = res.Add("ok", ok); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return middleware.ResponseMsg(res)
 }
@@ -0,0 +1,208 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgConfigureFailPoint implements `configureFailPoint` command.
+//
+// It is available only if `enableTestCommands` server parameter is set at startup.
+// See [failPointNames] for supported fail points.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgConfigureFailPoint(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.DocumentDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := doc.Command()
+
+	if !h.paramValues.enableTestCommands.Load() {
+		return notFound(command)(connCtx, req)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	name, err := getRequiredParam[string](doc, command)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(failPointNames, name) {
+		msg := fmt.Sprintf("Cannot find FailPoint named: %s", name)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	fp, err := getFailPoint(doc, command, name)
+	if err != nil {
+		return nil, err
+	}
+
+	count := h.failPoints.Set(name, fp)
+
+	return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+		"count", count,
+		"ok", float64(1),
+	)))
+}
+
+// getFailPoint returns the fail point configured by `mode` and `data` fields of `configureFailPoint` command,
+// or nil if it should be disabled.
+func getFailPoint(doc *wirebson.Document, command, name string) (*failPoint, error) {
+	fp := &failPoint{times: -1}
+
+	switch mode := doc.Get("mode").(type) {
+	case string:
+		switch mode {
+		case "alwaysOn":
+		case "off":
+			return nil, nil
+		default:
+			msg := fmt.Sprintf("unknown mode %q, expected 'alwaysOn', 'off', {times: n}, or {skip: n}", mode)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+		}
+
+	case *wirebson.Document:
+		switch {
+		case mode.Get("times") != nil:
+			times, err := parameterInt64("times", mode.Get("times"), 0, math.MaxInt32)
+			if err != nil {
+				return nil, err
+			}
+
+			if times == 0 {
+				return nil, nil
+			}
+
+			fp.times = times
+
+		case mode.Get("skip") != nil:
+			skip, err := parameterInt64("skip", mode.Get("skip"), 0, math.MaxInt32)
+			if err != nil {
+				return nil, err
+			}
+
+			fp.skip = skip
+
+		default:
+			msg := "mode document must contain 'times' or 'skip' field"
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+		}
+
+	case nil:
+		msg := fmt.Sprintf("BSON field '%s.mode' is missing but a required field", command)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrLocation40414, msg, command)
+
+	default:
+		msg := fmt.Sprintf(
+			"BSON field '%s.mode' is the wrong type '%s', expected types '[string, object]'",
+			command, aliasFromType(mode),
+		)
+
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+	}
+
+	data, _ := doc.Get("data").(*wirebson.Document)
+	if data == nil {
+		msg := fmt.Sprintf("BSON field '%s.data' must be a document with 'failCommands' field", command)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	var err error
+
+	if fp.commands, err = getStringsParam(data, command, "failCommands"); err != nil {
+		return nil, err
+	}
+
+	if len(fp.commands) == 0 {
+		msg := fmt.Sprintf("BSON field '%s.data.failCommands' must be a non-empty array", command)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	if fp.appName, err = getOptionalParam(data, "appName", ""); err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case failPointFailCommand:
+		if v := data.Get("errorCode"); v != nil {
+			code, err := parameterInt64("errorCode", v, 1, math.MaxInt32)
+			if err != nil {
+				return nil, err
+			}
+
+			fp.errorCode = mongoerrors.Code(code)
+		}
+
+		if fp.closeConnection, err = getBoolParam("closeConnection", data.Get("closeConnection")); err != nil {
+			return nil, err
+		}
+
+		block, err := getBoolParam("blockConnection", data.Get("blockConnection"))
+		if err != nil {
+			return nil, err
+		}
+
+		if block {
+			ms, err := parameterInt64("blockTimeMS", data.Get("blockTimeMS"), 0, math.MaxInt32)
+			if err != nil {
+				return nil, err
+			}
+
+			fp.blockTime = time.Duration(ms) * time.Millisecond
+		}
+
+	case failPointFerretBackendError:
+		if fp.sqlState, err = getRequiredParam[string](data, "sqlState"); err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(backendErrorStates, fp.sqlState) {
+			msg := fmt.Sprintf("unsupported sqlState %q, expected one of %q", fp.sqlState, backendErrorStates)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+		}
+	}
+
+	return fp, nil
+}
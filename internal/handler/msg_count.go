@@ -16,13 +16,16 @@
 
 import (
 	"context"
+	"math"
 
 	"github.com/FerretDB/wire/wirebson"
 	"github.com/jackc/pgx/v5"
 
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
 	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
 	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
 )
 
 // msgCount implements `count` command.
@@ -43,14 +46,50 @@ func (h *Handler) msgCount(connCtx context.Context, req *middleware.Request) (*m
 		return nil, err
 	}
 
+	collection, _ := doc.Get(doc.Command()).(string)
+
+	masks, err := h.fieldMasks(connCtx, dbName, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = masks.checkQuery(doc.Command(), doc); err != nil {
+		return nil, err
+	}
+
 	spec, err := req.OpMsg.DocumentRaw()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	var res wirebson.RawDocument
+	if spec, _, err = h.applyDefaultCollation(connCtx, dbName, spec, nil); err != nil {
+		return nil, err
+	}
+
+	if spec, _, err = h.applyDocumentFilter(connCtx, dbName, spec, nil); err != nil {
+		return nil, err
+	}
+
+	// the query might be changed by the document policy
+	if doc, err = spec.Decode(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var res wirebson.AnyDocument
 
 	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		if h.paramValues.estimatedCount.Load() && countWithoutQuery(doc) {
+			n, ok, estimateErr := h.estimatedCount(connCtx, conn, dbName, doc)
+			if estimateErr != nil {
+				return estimateErr
+			}
+
+			if ok {
+				res = countResponse(n)
+				return nil
+			}
+		}
+
 		res, err = documentdb_api.CountQuery(connCtx, conn, h.L, dbName, spec)
 		return err
 	})
@@ -60,3 +99,51 @@ func (h *Handler) msgCount(connCtx context.Context, req *middleware.Request) (*m
 
 	return middleware.ResponseMsg(res)
 }
+
+// countWithoutQuery returns true if the given `count` command counts all documents of the collection.
+func countWithoutQuery(doc *wirebson.Document) bool {
+	for _, field := range []string{"skip", "limit", "hint", "collation"} {
+		if doc.Get(field) != nil {
+			return false
+		}
+	}
+
+	switch q := doc.Get("query").(type) {
+	case nil:
+		return true
+	case wirebson.AnyDocument:
+		d, err := q.Decode()
+		return err == nil && d.Len() == 0
+	default:
+		return false
+	}
+}
+
+// estimatedCount returns the number of documents in the collection from PostgreSQL statistics.
+// It returns false if statistics are not available.
+func (h *Handler) estimatedCount(ctx context.Context, conn *pgx.Conn, dbName string, doc *wirebson.Document) (int64, bool, error) { //nolint:lll // for readability
+	collection, ok := doc.Get(doc.Command()).(string)
+	if !ok {
+		return 0, false, nil
+	}
+
+	n, ok, err := documentdb.CollectionEstimatedCount(ctx, conn, dbName, collection)
+	if err != nil {
+		return 0, false, lazyerrors.Error(err)
+	}
+
+	return n, ok, nil
+}
+
+// countResponse returns `count` command response with the given number of documents.
+func countResponse(n int64) *wirebson.Document {
+	var v any = n
+	if n <= math.MaxInt32 {
+		v = int32(n)
+	}
+
+	return must.NotFail(wirebson.NewDocument(
+		"n", v,
+		"ok", float64(1),
+	))
+}
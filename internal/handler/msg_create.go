@@ -22,6 +22,7 @@
 
 	"github.com/FerretDB/wire/wirebson"
 
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
 	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
 	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
@@ -67,6 +68,11 @@ func (h *Handler) msgCreate(connCtx context.Context, req *middleware.Request) (*
 		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrInvalidNamespace, msg, "create")
 	}
 
+	compression, err := getCompressionParam(doc, "create")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := h.Pool.Acquire()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -74,11 +80,75 @@ func (h *Handler) msgCreate(connCtx context.Context, req *middleware.Request) (*
 
 	defer conn.Release()
 
-	_, err = documentdb_api.CreateCollection(connCtx, conn.Conn(), h.L, dbName, collectionName)
+	// views are created by DocumentDB that also validates their options;
+	// it handles reads through them and rejects writes
+	if doc.Get("viewOn") != nil || doc.Get("pipeline") != nil {
+		if compression != "" {
+			return nil, mongoerrors.NewWithArgument(
+				mongoerrors.ErrInvalidOptions,
+				"storageEngine option is not supported for views",
+				"create",
+			)
+		}
+
+		if err = h.checkViewSource(connCtx, dbName, doc); err != nil {
+			return nil, err
+		}
+
+		var res wirebson.RawDocument
+
+		if res, err = documentdb_api.CreateCollectionView(connCtx, conn.Conn(), h.L, dbName, spec); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return middleware.ResponseMsg(res)
+	}
+
+	collation, err := getCollationParam(doc, "create")
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedFields, err := getEncryptedFieldsParam(doc, collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := documentdb_api.CreateCollection(connCtx, conn.Conn(), h.L, dbName, collectionName)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	if collation != nil {
+		if !created {
+			var existing *wirebson.Document
+
+			existing, err = documentdb.Metadata(connCtx, conn.Conn(), dbName, collectionName, defaultCollationMetadata)
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if existing == nil || existing.LogMessage() != collation.LogMessage() {
+				msg := fmt.Sprintf("Collection %s.%s already exists with different options", dbName, collectionName)
+				return nil, mongoerrors.NewWithArgument(mongoerrors.ErrNamespaceExists, msg, "create")
+			}
+		} else if err = h.setDefaultCollation(connCtx, conn.Conn(), dbName, collectionName, collation); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if encryptedFields != nil && created {
+		if err = h.setEncryptedFields(connCtx, conn.Conn(), dbName, collectionName, encryptedFields); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if compression != "" {
+		if err = setCompression(connCtx, conn.Conn(), "create", dbName, collectionName, compression); err != nil {
+			return nil, err
+		}
+	}
+
 	return middleware.ResponseMsg(wirebson.MustDocument(
 		"ok", float64(1),
 	))
@@ -21,7 +21,6 @@
 	"github.com/FerretDB/wire/wirebson"
 
 	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
-	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api_internal"
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
 	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
 	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
@@ -62,6 +61,10 @@ func (h *Handler) msgCreateIndexes(connCtx context.Context, req *middleware.Requ
 		)
 	}
 
+	if spec, _, err = h.applyDefaultCollation(connCtx, dbName, spec, nil); err != nil {
+		return nil, err
+	}
+
 	conn, err := h.Pool.Acquire()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -73,15 +76,21 @@ func (h *Handler) msgCreateIndexes(connCtx context.Context, req *middleware.Requ
 		return nil, lazyerrors.Error(err)
 	}
 
+	if deep, err := spec.DecodeDeep(); err == nil {
+		h.saveIndexOptions(connCtx, conn.Conn(), dbName, deep)
+	}
+
+	if collection, ok := doc.Get(doc.Command()).(string); ok && h.paramValues.caseInsensitiveIndexes.Load() {
+		h.syncCaseInsensitiveIndexes(connCtx, dbName, collection, true)
+	}
+
 	return middleware.ResponseMsg(res)
 }
 
 // createIndexes calls DocumentDB API to create indexes, decodes and maps embedded error to command error if any.
 // It returns a document for createIndexes response.
 func (h *Handler) createIndexes(connCtx context.Context, conn *documentdb.Conn, command, dbName string, spec wirebson.RawDocument) (wirebson.AnyDocument, error) { //nolint:lll // for readability
-	// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/1147
-	// resRaw, _, _, err := documentdb_api.CreateIndexesBackground(connCtx, conn.Conn(), h.L, dbName, spec)
-	resRaw, err := documentdb_api_internal.CreateIndexesNonConcurrently(connCtx, conn.Conn(), h.L, dbName, spec, true)
+	resRaw, err := h.buildIndexes(connCtx, conn.Conn(), dbName, spec)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
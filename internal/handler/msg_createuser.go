@@ -48,6 +48,42 @@ func (h *Handler) msgCreateUser(connCtx context.Context, req *middleware.Request
 	// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/913
 	doc.Remove("mechanisms")
 
+	username, err := getRequiredParam[string](doc, doc.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if pwd, ok := doc.Get("pwd").(string); ok {
+		if err = h.checkPassword(doc.Command(), pwd); err != nil {
+			return nil, err
+		}
+	}
+
+	if doc.Get("passwordDigestor") != nil {
+		if err = checkPasswordDigestor(doc.Command(), doc); err != nil {
+			return nil, err
+		}
+
+		spec = must.NotFail(doc.Encode())
+	}
+
+	// authentication restrictions are stored and enforced by FerretDB
+	var restrictions *wirebson.Array
+
+	if v := doc.Get("authenticationRestrictions"); v != nil {
+		if restrictions, _, err = getAuthRestrictionsParam(doc.Command(), v); err != nil {
+			return nil, err
+		}
+
+		doc.Remove("authenticationRestrictions")
+		spec = must.NotFail(doc.Encode())
+	}
+
 	// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/911
 	roles, _ := doc.Get("roles").(*wirebson.Array)
 	if roles == nil || roles.Len() == 0 {
@@ -68,8 +104,19 @@ func (h *Handler) msgCreateUser(connCtx context.Context, req *middleware.Request
 	var res wirebson.RawDocument
 
 	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
-		res, err = documentdb_api.CreateUser(connCtx, conn, h.L, spec)
-		return err
+		err = h.withSCRAMIterations(connCtx, conn, func(conn *pgx.Conn) error {
+			res, err = documentdb_api.CreateUser(connCtx, conn, h.L, spec)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if restrictions == nil {
+			return nil
+		}
+
+		return setAuthRestrictions(connCtx, conn, doc.Command(), username, dbName, restrictions)
 	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -50,5 +50,91 @@ func (h *Handler) msgCurrentOp(connCtx context.Context, req *middleware.Request)
 		return nil, lazyerrors.Error(err)
 	}
 
-	return middleware.ResponseMsg(res)
+	all, err := currentOpAll(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []*wirebson.Document
+
+	for _, m := range h.migrations.all() {
+		if all || m.status().state == migrationRunning {
+			ops = append(ops, m.currentOp())
+		}
+	}
+
+	ops = append(ops, h.indexBuildsCurrentOp(connCtx)...)
+
+	locked := h.fsync.Count() > 0
+
+	if !locked && len(ops) == 0 {
+		return middleware.ResponseMsg(res)
+	}
+
+	doc, err := res.Decode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(ops) > 0 {
+		raw, _ := doc.Get("inprog").(wirebson.RawArray)
+
+		inprog := wirebson.MakeArray(len(ops))
+
+		if raw != nil {
+			if inprog, err = raw.Decode(); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		for _, op := range ops {
+			if err = inprog.Add(op); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		if raw == nil {
+			err = doc.Add("inprog", inprog)
+		} else {
+			err = doc.Replace("inprog", inprog)
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	ok := doc.Get("ok")
+	doc.Remove("ok")
+
+	// make the fsync lock visible to backup tools, as MongoDB does
+	if locked {
+		if err = doc.Add("fsyncLock", true); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if ok != nil {
+		if err = doc.Add("ok", ok); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return middleware.ResponseMsg(doc)
+}
+
+// currentOpAll returns the value of the `$all` field of the `currentOp` command.
+// Background migrations are reported only while they are running unless it is set.
+func currentOpAll(spec wirebson.RawDocument) (bool, error) {
+	doc, err := spec.Decode()
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	v := doc.Get("$all")
+	if v == nil {
+		return false, nil
+	}
+
+	return getBoolParam("$all", v)
 }
@@ -44,6 +44,18 @@ func (h *Handler) msgDelete(connCtx context.Context, req *middleware.Request) (*
 		return nil, err
 	}
 
+	if spec, seq, err = h.applyDefaultCollation(connCtx, dbName, spec, seq); err != nil {
+		return nil, err
+	}
+
+	if err = h.checkStatements(connCtx, dbName, doc, seq); err != nil {
+		return nil, err
+	}
+
+	if spec, seq, err = h.applyDocumentFilter(connCtx, dbName, spec, seq); err != nil {
+		return nil, err
+	}
+
 	var res wirebson.RawDocument
 
 	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
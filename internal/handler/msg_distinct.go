@@ -65,6 +65,30 @@ func (h *Handler) msgDistinct(connCtx context.Context, req *middleware.Request)
 		)
 	}
 
+	masks, err := h.fieldMasks(connCtx, dbName, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if key, _ := doc.Get("key").(string); key != "" {
+		if masked, ok := masks.references(key); ok {
+			msg := fmt.Sprintf("distinct values of masked field '%s' are not allowed", masked)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, doc.Command())
+		}
+	}
+
+	if err = masks.checkQuery(doc.Command(), doc); err != nil {
+		return nil, err
+	}
+
+	if spec, _, err = h.applyDefaultCollation(connCtx, dbName, spec, nil); err != nil {
+		return nil, err
+	}
+
+	if spec, _, err = h.applyDocumentFilter(connCtx, dbName, spec, nil); err != nil {
+		return nil, err
+	}
+
 	conn, err := h.Pool.Acquire()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -76,5 +100,10 @@ func (h *Handler) msgDistinct(connCtx context.Context, req *middleware.Request)
 		return nil, lazyerrors.Error(err)
 	}
 
+	// distinct has no cursor, so all values should fit into a single response document
+	if len(res) > int(maxBsonObjectSize) {
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrLocation17217, "distinct too big, 16mb cap", doc.Command())
+	}
+
 	return middleware.ResponseMsg(res)
 }
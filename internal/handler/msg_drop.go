@@ -78,6 +78,8 @@ func (h *Handler) msgDrop(connCtx context.Context, req *middleware.Request) (*mi
 		return nil, lazyerrors.Error(err)
 	}
 
+	h.defaultCollations.reset()
+
 	res := must.NotFail(wirebson.NewDocument())
 	if dropped {
 		must.NoError(res.Add("nIndexesWas", int32(1))) // TODO https://github.com/FerretDB/FerretDB/issues/2337
@@ -21,6 +21,7 @@
 
 	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
 	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
 )
 
@@ -58,11 +59,29 @@ func (h *Handler) msgDropDatabase(connCtx context.Context, req *middleware.Reque
 
 	defer conn.Release()
 
+	if dbName == "admin" {
+		var exist bool
+
+		if exist, err = h.usersExist(connCtx, conn); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if exist {
+			return nil, mongoerrors.NewWithArgument(
+				mongoerrors.ErrIllegalOperation,
+				"Cannot drop the admin database while users exist; drop all users first",
+				doc.Command(),
+			)
+		}
+	}
+
 	err = documentdb_api.DropDatabase(connCtx, conn.Conn(), h.L, dbName, nil)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	h.defaultCollations.reset()
+
 	return middleware.ResponseMsg(wirebson.MustDocument(
 		"ok", float64(1),
 	))
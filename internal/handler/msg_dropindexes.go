@@ -16,15 +16,28 @@
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
 
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
 	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
 	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
 	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
 )
 
 // msgDropIndexes implements `dropIndexes` command.
 //
+// Indexes specified by "*", a name, an array of names, or a key pattern document
+// are resolved to names and dropped one by one.
+// Invalid specifications are passed to DocumentDB as is, so it returns the right error.
+//
 // The passed context is canceled when the client connection is closed.
 func (h *Handler) msgDropIndexes(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
 	spec, err := req.OpMsg.RawDocument()
@@ -33,7 +46,7 @@ func (h *Handler) msgDropIndexes(connCtx context.Context, req *middleware.Reques
 	}
 
 	// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/78
-	doc, err := spec.Decode()
+	doc, err := spec.DecodeDeep()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -47,7 +60,8 @@ func (h *Handler) msgDropIndexes(connCtx context.Context, req *middleware.Reques
 		return nil, err
 	}
 
-	if index := doc.Get("index"); index == nil {
+	index := doc.Get("index")
+	if index == nil {
 		return nil, mongoerrors.NewWithArgument(
 			mongoerrors.ErrLocation40414,
 			"BSON field 'dropIndexes.index' is missing but a required field",
@@ -55,16 +69,161 @@ func (h *Handler) msgDropIndexes(connCtx context.Context, req *middleware.Reques
 		)
 	}
 
+	// abort in-progress builds first, as they hold the collection table lock
+	collection, _ := doc.Get(doc.Command()).(string)
+	aborted := h.indexBuilds.abort(dbName, collection, index)
+
 	conn, err := h.Pool.Acquire()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 	defer conn.Release()
 
-	res, err := documentdb_api.DropIndexes(connCtx, conn.Conn(), h.L, dbName, spec, nil)
-	if err != nil {
-		return nil, lazyerrors.Error(err)
+	names, nIndexesWas := h.dropIndexesNames(connCtx, dbName, collection, index, aborted)
+	if names == nil {
+		res, err := documentdb_api.DropIndexes(connCtx, conn.Conn(), h.L, dbName, spec, nil)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		h.syncCaseInsensitiveIndexes(connCtx, dbName, collection, false)
+
+		return middleware.ResponseMsg(res)
 	}
 
+	for i, name := range names {
+		if err = h.dropIndex(connCtx, conn, dbName, collection, name); err != nil {
+			var e *mongoerrors.Error
+			if !errors.As(err, &e) {
+				return nil, lazyerrors.Error(err)
+			}
+
+			// report which index failed, as previous ones are already dropped
+			msg := fmt.Sprintf("failed to drop index '%s' (%d of %d): %s", name, i+1, len(names), e.Message)
+
+			return nil, mongoerrors.NewWithArgument(mongoerrors.Code(e.Code), msg, "dropIndexes")
+		}
+	}
+
+	h.syncCaseInsensitiveIndexes(connCtx, dbName, collection, false)
+
+	res := must.NotFail(wirebson.NewDocument("nIndexesWas", nIndexesWas))
+
+	if index == "*" {
+		must.NoError(res.Add("msg", "non-_id indexes dropped for collection"))
+	}
+
+	must.NoError(res.Add("ok", float64(1)))
+
 	return middleware.ResponseMsg(res)
 }
+
+// dropIndexesNames returns names of indexes specified by the `index` field of `dropIndexes` command
+// and the number of collection indexes before dropping.
+// Indexes of aborted builds are skipped if they do not exist.
+//
+// It returns nil names if the specification is invalid, does not match existing indexes,
+// or if indexes can't be listed; DocumentDB should handle that case.
+func (h *Handler) dropIndexesNames(ctx context.Context, dbName, collection string, index any, aborted []string) ([]string, int32) { //nolint:lll // for readability
+	keyPatterns, err := h.indexKeyPatterns(ctx, dbName, collection)
+	if err != nil {
+		h.L.DebugContext(ctx, "Failed to list indexes for dropIndexes", logging.Error(err))
+		return nil, 0
+	}
+
+	nIndexesWas := int32(len(keyPatterns))
+
+	// resolve checks that the given name is droppable and returns names to drop
+	resolve := func(names ...string) []string {
+		res := []string{}
+
+		for _, name := range names {
+			if name == "_id_" || name == "*" {
+				return nil
+			}
+
+			if keyPatterns[name] != nil {
+				if !slices.Contains(res, name) {
+					res = append(res, name)
+				}
+
+				continue
+			}
+
+			if !slices.Contains(aborted, name) {
+				return nil
+			}
+		}
+
+		return res
+	}
+
+	switch index := index.(type) {
+	case string:
+		if index != "*" {
+			return resolve(index), nIndexesWas
+		}
+
+		res := []string{}
+
+		for name := range keyPatterns {
+			if name != "_id_" {
+				res = append(res, name)
+			}
+		}
+
+		sort.Strings(res)
+
+		return res, nIndexesWas
+
+	case *wirebson.Array:
+		names := make([]string, 0, index.Len())
+
+		for v := range index.Values() {
+			name, ok := v.(string)
+			if !ok {
+				return nil, 0
+			}
+
+			names = append(names, name)
+		}
+
+		return resolve(names...), nIndexesWas
+
+	case *wirebson.Document:
+		i, err := index.Encode()
+		if err != nil {
+			return nil, 0
+		}
+
+		var matched []string
+
+		for name, key := range keyPatterns {
+			if k, err := key.Encode(); err == nil && string(k) == string(i) {
+				matched = append(matched, name)
+			}
+		}
+
+		// ambiguous key patterns (indexes with different collations) are handled by DocumentDB
+		if len(matched) != 1 {
+			return nil, 0
+		}
+
+		return resolve(matched[0]), nIndexesWas
+
+	default:
+		return nil, 0
+	}
+}
+
+// dropIndex drops a single index by name.
+func (h *Handler) dropIndex(ctx context.Context, conn *documentdb.Conn, dbName, collection, name string) error {
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument(
+		"dropIndexes", collection,
+		"index", name,
+	)).Encode())
+
+	_, err := documentdb_api.DropIndexes(ctx, conn.Conn(), h.L, dbName, spec, nil)
+
+	return err
+}
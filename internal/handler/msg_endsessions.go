@@ -20,6 +20,7 @@
 	"github.com/FerretDB/wire/wirebson"
 
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/session"
 	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
 )
 
@@ -38,7 +39,8 @@ func (h *Handler) msgEndSessions(connCtx context.Context, req *middleware.Reques
 		return nil, lazyerrors.Error(err)
 	}
 
-	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+	userID, _, err := h.s.CreateOrUpdateByLSID(connCtx, doc)
+	if err != nil {
 		return nil, err
 	}
 
@@ -49,6 +51,8 @@ func (h *Handler) msgEndSessions(connCtx context.Context, req *middleware.Reques
 
 	h.s.EndSessions(connCtx, ids)
 
+	h.notifyCluster(connCtx, clusterMessage{Type: clusterEndSessions, UserIDs: []session.UserID{userID}, SessionIDs: ids})
+
 	return middleware.ResponseMsg(wirebson.MustDocument(
 		"ok", float64(1),
 	))
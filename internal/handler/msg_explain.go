@@ -127,6 +127,17 @@ func (h *Handler) msgExplain(connCtx context.Context, req *middleware.Request) (
 		return nil, lazyerrors.Error(err)
 	}
 
+	if cmd == "find" && h.paramValues.caseInsensitiveIndexes.Load() {
+		var plan *findPlan
+		if plan, err = h.explainCaseInsensitive(connCtx, dbName, explainSpec); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if plan != nil {
+			must.NoError(queryPlan.Add("ferretdbPlan", plan.document()))
+		}
+	}
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -146,7 +157,7 @@ func (h *Handler) msgExplain(connCtx context.Context, req *middleware.Request) (
 	serverInfo := must.NotFail(wirebson.NewDocument(
 		"host", hostname,
 		"port", int32(port),
-		"version", version.Get().MongoDBVersion,
+		"version", h.mongoDBVersionString(),
 		"gitVersion", version.Get().Commit,
 
 		// our extensions
@@ -169,6 +180,24 @@ func (h *Handler) msgExplain(connCtx context.Context, req *middleware.Request) (
 	return middleware.ResponseMsg(res)
 }
 
+// explainCaseInsensitive returns the plan chosen by the cost model for the given `find` command,
+// or nil if the command is always handled by DocumentDB.
+func (h *Handler) explainCaseInsensitive(ctx context.Context, dbName string, spec wirebson.RawDocument) (*findPlan, error) {
+	doc, err := spec.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	collection, _ := doc.Get("find").(string)
+
+	q, n, cursor := caseInsensitiveFind(doc)
+	if q == nil {
+		return nil, nil
+	}
+
+	return h.caseInsensitivePlan(ctx, dbName, collection, q, n, cursor)
+}
+
 // unmarshalExplain unmarshalls the plan from EXPLAIN postgreSQL command.
 func unmarshalExplain(b []byte) (*wirebson.Document, error) {
 	var plans []map[string]any
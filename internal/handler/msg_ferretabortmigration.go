@@ -0,0 +1,101 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgFerretAbortMigration implements `ferretAbortMigration` command.
+//
+// It stops the background migration started by `ferretMigrate` command after the current batch,
+// and returns the last processed _id that could be used to resume it.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFerretAbortMigration(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	id, err := parameterInt64(command, doc.Get(command), 1, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+
+	m := h.migrations.get(id)
+	if m == nil {
+		msg := fmt.Sprintf("migration %d not found", id)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	if s := m.status(); s.state != migrationRunning {
+		msg := fmt.Sprintf("migration %d is not running: %s", id, s.state)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrIllegalOperation, msg, command)
+	}
+
+	m.cancel(errMigrationAborted)
+
+	select {
+	case <-m.done:
+	case <-connCtx.Done():
+		return nil, lazyerrors.Error(context.Cause(connCtx))
+	}
+
+	s := m.status()
+
+	res := must.NotFail(wirebson.NewDocument(
+		"migrationId", m.id,
+		"state", s.state,
+		"nMatched", s.matched,
+		"nModified", s.modified,
+	))
+
+	if s.lastID != nil {
+		must.NoError(res.Add("lastId", s.lastID))
+	}
+
+	must.NoError(res.Add("ok", float64(1)))
+
+	return middleware.ResponseMsg(res)
+}
@@ -0,0 +1,223 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgFerretAnalyze implements `ferretAnalyze` command.
+//
+// It samples documents of the collection and stores per-field type histograms
+// and estimated numbers of distinct values in collection metadata.
+// They are used by the index advisor to order equality fields of suggested indexes.
+// With `stored: true`, the stored statistics are returned without sampling.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFerretAnalyze(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.DocumentDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := getRequiredParam[string](doc, command)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := getOptionalParam(doc, "stored", false)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleSize := int64(defaultAnalyzeSampleSize)
+	if v := doc.Get("sampleSize"); v != nil {
+		if sampleSize, err = parameterInt64("sampleSize", v, 1, maxAnalyzeSampleSize); err != nil {
+			return nil, err
+		}
+	}
+
+	// statistics could reveal filtered documents and masked fields
+	policy, _, _, err := h.userAccessPolicy(connCtx, dbName, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(policy.masking) > 0 || len(policy.documents) > 0 {
+		msg := fmt.Sprintf("not authorized to analyze %s.%s with access policies", dbName, collection)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrUnauthorized, msg, command)
+	}
+
+	var stats *wirebson.Document
+
+	if stored {
+		err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+			var e error
+			stats, e = documentdb.Metadata(connCtx, conn, dbName, collection, fieldStatsMetadata)
+
+			return e
+		})
+	} else {
+		stats, err = h.analyzeCollection(connCtx, dbName, collection, sampleSize)
+	}
+
+	switch {
+	case errors.Is(err, documentdb.ErrCollectionNotFound):
+		msg := fmt.Sprintf("ns does not exist: %s.%s", dbName, collection)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrNamespaceNotFound, msg, command)
+	case err != nil:
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := wirebson.MustDocument("ns", dbName+"."+collection)
+
+	if stats != nil {
+		for k, v := range stats.All() {
+			must.NoError(res.Add(k, v))
+		}
+	}
+
+	must.NoError(res.Add("ok", float64(1)))
+
+	return middleware.ResponseMsg(res)
+}
+
+// analyzeCollection samples documents of the given collection
+// and stores their statistics in collection metadata.
+func (h *Handler) analyzeCollection(ctx context.Context, dbName, collection string, sampleSize int64) (*wirebson.Document, error) { //nolint:lll // for readability
+	spec := must.NotFail(wirebson.MustDocument(
+		"aggregate", collection,
+		"pipeline", wirebson.MustArray(wirebson.MustDocument("$sample", wirebson.MustDocument("size", sampleSize))),
+		"cursor", wirebson.MakeDocument(0),
+		"$db", dbName,
+	).Encode())
+
+	sampledAt := time.Now()
+
+	page, cursorID, err := h.Pool.Aggregate(ctx, dbName, spec)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer func() {
+		if cursorID != 0 {
+			h.Pool.KillCursor(ctx, cursorID)
+		}
+	}()
+
+	cs := newCollectionStats()
+
+	for {
+		var pageDoc, cursor *wirebson.Document
+
+		if pageDoc, err = page.DecodeDeep(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if cursor, _ = pageDoc.Get("cursor").(*wirebson.Document); cursor == nil {
+			return nil, lazyerrors.Errorf("no cursor in the page: %s", pageDoc.LogMessage())
+		}
+
+		batch, _ := cursor.Get("firstBatch").(*wirebson.Array)
+		if batch == nil {
+			batch, _ = cursor.Get("nextBatch").(*wirebson.Array)
+		}
+
+		if batch != nil {
+			for v := range batch.Values() {
+				if d, ok := v.(*wirebson.Document); ok {
+					if err = cs.add(d); err != nil {
+						return nil, lazyerrors.Error(err)
+					}
+				}
+			}
+		}
+
+		if cursorID, _ = cursor.Get("id").(int64); cursorID == 0 {
+			break
+		}
+
+		getMore := must.NotFail(wirebson.MustDocument("getMore", cursorID, "collection", collection).Encode())
+
+		if page, err = h.Pool.GetMore(ctx, dbName, getMore, cursorID); err != nil {
+			// cursor is already closed on error
+			cursorID = 0
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	total := cs.n
+
+	// the whole collection was sampled otherwise
+	if cs.n >= sampleSize {
+		err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+			n, ok, e := documentdb.CollectionEstimatedCount(ctx, conn, dbName, collection)
+			if ok {
+				total = max(n, cs.n)
+			}
+
+			return e
+		})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	stats := cs.document(total, sampledAt)
+
+	defer h.queryCosts.invalidate(dbName + "." + collection)
+
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		return documentdb.UpdateMetadata(ctx, conn, dbName, collection, fieldStatsMetadata, func(md *wirebson.Document) error {
+			for _, f := range md.FieldNames() {
+				md.Remove(f)
+			}
+
+			for k, v := range stats.All() {
+				must.NoError(md.Add(k, v))
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/backup"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// maxExportSize is the maximum size of `ferretExport` data,
+// leaving some room for other fields of the response.
+const maxExportSize = 16*1024*1024 - 16*1024
+
+// errExportTooLarge is returned by exportBuffer when the data exceeds maxExportSize.
+var errExportTooLarge = errors.New("export is too large")
+
+// exportBuffer is a buffer that fails writes exceeding maxExportSize.
+type exportBuffer struct {
+	bytes.Buffer
+}
+
+// Write implements [io.Writer].
+func (b *exportBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > maxExportSize {
+		return 0, errExportTooLarge
+	}
+
+	return b.Buffer.Write(p)
+}
+
+// msgFerretExport implements `ferretExport` command.
+//
+// It returns documents of the collection as Extended JSON v2 (one document per line) or CSV in the `data` field.
+// Large collections should be exported in parts with `skip` and `limit` options.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFerretExport(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.DocumentDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := getRequiredParam[string](doc, command)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &backup.ExportOpts{
+		Pool:       h.Pool,
+		L:          h.L,
+		DB:         dbName,
+		Collection: collection,
+	}
+
+	if opts.Format, err = getFormatParam(doc, command); err != nil {
+		return nil, err
+	}
+
+	if opts.Fields, err = getStringsParam(doc, command, "fields"); err != nil {
+		return nil, err
+	}
+
+	if opts.Format == backup.FormatCSV && len(opts.Fields) == 0 {
+		msg := fmt.Sprintf("BSON field '%s.fields' is required for CSV format", command)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	jsonFormat, err := getOptionalParam(doc, "jsonFormat", "canonical")
+	if err != nil {
+		return nil, err
+	}
+
+	switch jsonFormat {
+	case "canonical":
+	case "relaxed":
+		opts.Relaxed = true
+	default:
+		msg := fmt.Sprintf("unknown jsonFormat %q, expected 'canonical' or 'relaxed'", jsonFormat)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	if v := doc.Get("filter"); v != nil {
+		var ok bool
+		if opts.Filter, ok = v.(*wirebson.Document); !ok {
+			msg := fmt.Sprintf(
+				"BSON field '%s.filter' is the wrong type '%s', expected type 'object'",
+				command, aliasFromType(v),
+			)
+
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+		}
+	}
+
+	for name, p := range map[string]*int64{"skip": &opts.Skip, "limit": &opts.Limit} {
+		if v := doc.Get(name); v != nil {
+			if *p, err = parameterInt64(name, v, 0, math.MaxInt64); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var buf exportBuffer
+
+	n, err := backup.Export(connCtx, &buf, opts)
+	if err != nil {
+		if errors.Is(err, errExportTooLarge) {
+			msg := fmt.Sprintf("exported data exceeds %d bytes, use skip and limit options", maxExportSize)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBsonObjectTooLarge, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+		"data", buf.String(),
+		"n", int32(n),
+		"ok", float64(1),
+	)))
+}
+
+// getFormatParam returns the value of the `format` field of `ferretExport` and `ferretImport` commands.
+func getFormatParam(doc *wirebson.Document, command string) (string, error) {
+	format, err := getOptionalParam(doc, "format", backup.FormatJSON)
+	if err != nil {
+		return "", err
+	}
+
+	if !slices.Contains(backup.Formats, format) {
+		msg := fmt.Sprintf("unknown format %q, expected one of %q", format, backup.Formats)
+		return "", mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	return format, nil
+}
+
+// getStringsParam returns the optional array of strings of the given field.
+func getStringsParam(doc *wirebson.Document, command, field string) ([]string, error) {
+	v := doc.Get(field)
+	if v == nil {
+		return nil, nil
+	}
+
+	arr, ok := v.(*wirebson.Array)
+	if !ok {
+		msg := fmt.Sprintf(
+			"BSON field '%s.%s' is the wrong type '%s', expected type 'array'",
+			command, field, aliasFromType(v),
+		)
+
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+	}
+
+	res := make([]string, 0, arr.Len())
+
+	for i, v := range arr.All() {
+		s, ok := v.(string)
+		if !ok {
+			msg := fmt.Sprintf(
+				"BSON field '%s.%s.%d' is the wrong type '%s', expected type 'string'",
+				command, field, i, aliasFromType(v),
+			)
+
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+		}
+
+		res = append(res, s)
+	}
+
+	return res, nil
+}
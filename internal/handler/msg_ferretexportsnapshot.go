@@ -0,0 +1,153 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgFerretExportSnapshot implements `ferretExportSnapshot` command.
+//
+// It opens cursors over all documents of the given collections ("db.collection" namespaces)
+// that see the same consistent snapshot of data without blocking writes.
+// Cursors could be iterated in parallel with `getMore` commands in the same session.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFerretExportSnapshot(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	userID, sessionID, err := h.s.CreateOrUpdateByLSID(connCtx, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	v, err := getRequiredParamAny(doc, "namespaces")
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := v.(wirebson.AnyArray)
+	if !ok {
+		msg := fmt.Sprintf(
+			"BSON field '%s.namespaces' is the wrong type '%s', expected type 'array'",
+			command, aliasFromType(v),
+		)
+
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+	}
+
+	namespaces, err := arr.Decode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if namespaces.Len() == 0 {
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, "namespaces must not be empty", command)
+	}
+
+	var batchSize any
+
+	if v := doc.Get("batchSize"); v != nil {
+		var n int64
+
+		if n, err = parameterInt64("batchSize", v, 0, math.MaxInt32); err != nil {
+			return nil, err
+		}
+
+		batchSize = int32(n)
+	}
+
+	queries := make([]documentdb.SnapshotQuery, 0, namespaces.Len())
+
+	for i, v := range namespaces.All() {
+		ns, ok := v.(string)
+		db, collection, found := strings.Cut(ns, ".")
+
+		if !ok || !found || db == "" || collection == "" {
+			msg := fmt.Sprintf("invalid namespace at index %d: %v", i, v)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		spec := must.NotFail(wirebson.NewDocument("find", collection))
+
+		if batchSize != nil {
+			must.NoError(spec.Add("batchSize", batchSize))
+		}
+
+		queries = append(queries, documentdb.SnapshotQuery{
+			DB:   db,
+			Spec: must.NotFail(spec.Encode()),
+		})
+	}
+
+	cursors, snapshot, err := h.Pool.FindSnapshot(connCtx, queries)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	h.L.InfoContext(connCtx, "Snapshot exported", slog.String("snapshot", snapshot), slog.Int("cursors", len(cursors)))
+
+	res := wirebson.MakeArray(len(cursors))
+
+	for _, c := range cursors {
+		h.s.AddCursor(connCtx, userID, sessionID, c.CursorID)
+
+		var page *wirebson.Document
+
+		if page, err = c.Page.Decode(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if err = res.Add(page.Get("cursor")); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+		"snapshot", snapshot,
+		"cursors", res,
+		"ok", float64(1),
+	)))
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/backup"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgFerretImport implements `ferretImport` command.
+//
+// It inserts documents from Extended JSON or CSV in the `data` field into the collection.
+// Documents that could not be inserted (for example, due to duplicate _id values) are counted as failed.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFerretImport(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.DocumentDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := getRequiredParam[string](doc, command)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := getRequiredParam[string](doc, "data")
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &backup.ImportOpts{
+		Pool:       h.Pool,
+		L:          h.L,
+		DB:         dbName,
+		Collection: collection,
+		Types:      map[string]string{},
+	}
+
+	if opts.Format, err = getFormatParam(doc, command); err != nil {
+		return nil, err
+	}
+
+	if opts.Fields, err = getStringsParam(doc, command, "fields"); err != nil {
+		return nil, err
+	}
+
+	if v := doc.Get("types"); v != nil {
+		types, ok := v.(*wirebson.Document)
+		if !ok {
+			msg := fmt.Sprintf(
+				"BSON field '%s.types' is the wrong type '%s', expected type 'object'",
+				command, aliasFromType(v),
+			)
+
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+		}
+
+		for f, v := range types.All() {
+			t, ok := v.(string)
+			if !ok || !slices.Contains(backup.ColumnTypes, t) {
+				msg := fmt.Sprintf("invalid type %v for column %q, expected one of %q", v, f, backup.ColumnTypes)
+				return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+			}
+
+			opts.Types[f] = t
+		}
+	}
+
+	if v := doc.Get("drop"); v != nil {
+		if opts.Drop, err = getBoolParam("drop", v); err != nil {
+			return nil, err
+		}
+	}
+
+	inserted, failed, err := backup.Import(connCtx, strings.NewReader(data), opts)
+	if err != nil {
+		if de := new(backup.DataError); errors.As(err, &de) {
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrFailedToParse, de.Error(), command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+		"n", int32(inserted),
+		"failed", int32(failed),
+		"ok", float64(1),
+	)))
+}
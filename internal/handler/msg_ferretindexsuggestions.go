@@ -0,0 +1,68 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgFerretIndexSuggestions implements `ferretIndexSuggestions` command.
+//
+// It returns indexes suggested by the index advisor for the current database,
+// or for all databases if run against the admin database.
+// Suggestions are collected only if `ferretdbIndexAdvisorScanRatio` parameter is positive.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFerretIndexSuggestions(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName == "admin" {
+		dbName = ""
+	}
+
+	suggestions := wirebson.MakeArray(0)
+
+	for _, s := range h.indexAdvisor.list(dbName) {
+		must.NoError(suggestions.Add(must.NotFail(wirebson.NewDocument(
+			"ns", s.db+"."+s.collection,
+			"shape", s.shape,
+			"index", must.NotFail(wirebson.NewDocument("key", s.key)),
+			"scanRatio", s.scanRatio,
+			"count", s.count,
+			"firstSeen", s.firstSeen,
+			"lastSeen", s.lastSeen,
+		))))
+	}
+
+	return middleware.ResponseMsg(wirebson.MustDocument(
+		"enabled", h.paramValues.indexAdvisorScanRatio.Load() > 0,
+		"suggestions", suggestions,
+		"ok", float64(1),
+	))
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// defaultMigrationBatchSize is the default number of documents updated by a single migration batch.
+const defaultMigrationBatchSize = int32(1000)
+
+// msgFerretMigrate implements `ferretMigrate` command.
+//
+// It starts a background migration that applies the given update (document or pipeline)
+// to all documents of the collection ("db.collection" namespace) matching the optional filter,
+// in batches ordered by _id with an optional pause between them.
+// The progress is reported by the `currentOp` command; the migration could be stopped by
+// `ferretAbortMigration` command and resumed later with the `resumeAfter` option set to the last processed _id.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFerretMigrate(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.DocumentDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	ns, err := getRequiredParam[string](doc, command)
+	if err != nil {
+		return nil, err
+	}
+
+	db, collection, found := strings.Cut(ns, ".")
+	if !found || db == "" || collection == "" {
+		msg := fmt.Sprintf("Invalid namespace specified '%s'", ns)
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrInvalidNamespace, msg, command)
+	}
+
+	if err = protectedNamespace(command, db, collection); err != nil {
+		return nil, err
+	}
+
+	m := &migration{
+		db:         db,
+		collection: collection,
+		batchSize:  defaultMigrationBatchSize,
+		started:    time.Now(),
+		state:      migrationRunning,
+		done:       make(chan struct{}),
+	}
+
+	if m.update, err = getRequiredParamAny(doc, "update"); err != nil {
+		return nil, err
+	}
+
+	switch u := m.update.(type) {
+	case *wirebson.Document:
+	case *wirebson.Array:
+		if u.Len() == 0 {
+			msg := fmt.Sprintf("BSON field '%s.update' must not be an empty pipeline", command)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+		}
+	default:
+		msg := fmt.Sprintf(
+			"BSON field '%s.update' is the wrong type '%s', expected types '[object, array]'",
+			command, aliasFromType(u),
+		)
+
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+	}
+
+	if v := doc.Get("filter"); v != nil {
+		var ok bool
+
+		if m.filter, ok = v.(*wirebson.Document); !ok {
+			msg := fmt.Sprintf(
+				"BSON field '%s.filter' is the wrong type '%s', expected type 'object'",
+				command, aliasFromType(v),
+			)
+
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+		}
+	}
+
+	if v := doc.Get("batchSize"); v != nil {
+		var n int64
+
+		if n, err = parameterInt64("batchSize", v, 1, int64(maxWriteBatchSize)); err != nil {
+			return nil, err
+		}
+
+		m.batchSize = int32(n)
+	}
+
+	if v := doc.Get("throttleMS"); v != nil {
+		var ms int64
+
+		if ms, err = parameterInt64("throttleMS", v, 0, int64(time.Hour/time.Millisecond)); err != nil {
+			return nil, err
+		}
+
+		m.throttle = time.Duration(ms) * time.Millisecond
+	}
+
+	m.lastID = doc.Get("resumeAfter")
+
+	m.command = wirebson.MakeDocument(doc.Len())
+
+	for k, v := range doc.All() {
+		if k == "lsid" || strings.HasPrefix(k, "$") {
+			continue
+		}
+
+		must.NoError(m.command.Add(k, v))
+	}
+
+	// the migration outlives the connection; it is canceled on abort or handler shutdown
+	ctx, cancel := context.WithCancelCause(context.WithoutCancel(connCtx))
+	m.cancel = cancel
+
+	h.migrations.add(m)
+
+	go h.runMigration(ctx, m)
+
+	return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+		"migrationId", m.id,
+		"ok", float64(1),
+	)))
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgFerretRefreshView implements `ferretRefreshView` command.
+//
+// It re-runs the aggregation pipeline that populates the given collection with `$merge` stage
+// (materialized view). The first call defines the view with `viewOn` and `pipeline` fields;
+// the definition and the last refresh time are stored in collection metadata,
+// so later calls need only the collection name.
+// Views with positive `refreshIntervalSecs` are also refreshed on schedule.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFerretRefreshView(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.DocumentDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := getRequiredParam[string](doc, command)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = protectedNamespace(command, dbName, collection); err != nil {
+		return nil, err
+	}
+
+	var view *materializedView
+
+	if doc.Get("pipeline") != nil || doc.Get("viewOn") != nil {
+		view = new(materializedView)
+
+		if view.viewOn, err = getRequiredParam[string](doc, "viewOn"); err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		if view.pipeline, ok = doc.Get("pipeline").(*wirebson.Array); !ok {
+			msg := fmt.Sprintf(
+				"BSON field '%s.pipeline' is the wrong type '%s', expected type 'array'",
+				command, aliasFromType(doc.Get("pipeline")),
+			)
+
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrTypeMismatch, msg, command)
+		}
+
+		if err = checkMergeStage(command, dbName, collection, view.pipeline); err != nil {
+			return nil, err
+		}
+	} else {
+		err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+			md, err := documentdb.Metadata(connCtx, conn, dbName, collection, materializedViewMetadata)
+			if md != nil {
+				view = materializedViewFromMetadata(md)
+			}
+
+			return err
+		})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if view == nil {
+			msg := fmt.Sprintf("no materialized view definition for %s.%s", dbName, collection)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrNamespaceNotFound, msg, command)
+		}
+	}
+
+	if v := doc.Get("refreshIntervalSecs"); v != nil {
+		var secs int64
+
+		if secs, err = parameterInt64("refreshIntervalSecs", v, 0, math.MaxInt64/int64(time.Second)); err != nil {
+			return nil, err
+		}
+
+		view.interval = time.Duration(secs) * time.Second
+	}
+
+	started := time.Now()
+
+	lastRefresh, err := h.refreshMaterializedView(connCtx, dbName, collection, view)
+	if err != nil {
+		return nil, err
+	}
+
+	return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+		"ns", dbName+"."+collection,
+		"lastRefresh", lastRefresh,
+		"durationMillis", time.Since(started).Milliseconds(),
+		"refreshIntervalSecs", int64(view.interval/time.Second),
+		"ok", float64(1),
+	)))
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgFerretSetDocumentPolicy implements `ferretSetDocumentPolicy` command.
+//
+// It sets rules that restrict documents of the collection visible to users with given roles
+// to ones matching the filter; filters are combined with filters of all queries, updates, and deletes.
+// Empty `rules` array removes the policy; without `rules` field, the current rules are returned.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFerretSetDocumentPolicy(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.DocumentDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := getRequiredParam[string](doc, command)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = protectedNamespace(command, dbName, collection); err != nil {
+		return nil, err
+	}
+
+	var rules []documentRule
+
+	if v := doc.Get("rules"); v != nil {
+		if rules, err = getDocumentRules(command, v); err != nil {
+			return nil, err
+		}
+
+		err = h.setAccessPolicy(connCtx, command, dbName, collection, documentPolicyMetadata, documentRulesArray(rules))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var policy accessPolicy
+		if policy, err = h.accessPolicy(connCtx, dbName, collection); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		rules = policy.documents
+	}
+
+	return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+		"ns", dbName+"."+collection,
+		"rules", documentRulesArray(rules),
+		"ok", float64(1),
+	)))
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// msgFerretTelemetry implements `ferretTelemetry` command.
+//
+// It returns the exact JSON payload of the telemetry report that would be sent next
+// (without excluded fields), regardless of the telemetry state.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFerretTelemetry(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	if h.TelemetryPayload == nil {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrIllegalOperation,
+			"telemetry reporting is not available for this instance",
+			command,
+		)
+	}
+
+	payload, err := h.TelemetryPayload()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return middleware.ResponseMsg(wirebson.MustDocument(
+		"state", h.StateProvider.Get().TelemetryString(),
+		"payload", string(payload),
+		"ok", float64(1),
+	))
+}
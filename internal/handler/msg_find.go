@@ -16,6 +16,9 @@
 
 import (
 	"context"
+	"slices"
+
+	"github.com/FerretDB/wire/wirebson"
 
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
 	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
@@ -40,11 +43,95 @@ func (h *Handler) msgFind(connCtx context.Context, req *middleware.Request) (*mi
 		return nil, err
 	}
 
+	if err = validateSort(doc.Get("sort")); err != nil {
+		return nil, err
+	}
+
+	if err = validateProjection(doc.Get("projection")); err != nil {
+		return nil, err
+	}
+
+	collection, _ := doc.Get("find").(string)
+
+	masks, err := h.fieldMasks(connCtx, dbName, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = masks.checkProjection(doc.Command(), doc.Get("projection")); err != nil {
+		return nil, err
+	}
+
+	if err = masks.checkQuery(doc.Command(), doc); err != nil {
+		return nil, err
+	}
+
+	// singleBatch is handled by DocumentDB; allowPartialResults has no effect without sharding
+	for _, f := range []string{"singleBatch", "allowPartialResults"} {
+		if _, err = findBoolOption(doc, f); err != nil {
+			return nil, err
+		}
+	}
+
+	noCursorTimeout, err := findBoolOption(doc, "noCursorTimeout")
+	if err != nil {
+		return nil, err
+	}
+
 	spec, err := req.OpMsg.DocumentRaw()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	if spec, _, err = h.applyDefaultCollation(connCtx, dbName, spec, nil); err != nil {
+		return nil, err
+	}
+
+	if spec, _, err = h.applyDocumentFilter(connCtx, dbName, spec, nil); err != nil {
+		return nil, err
+	}
+
+	var opts *findOptions
+
+	if slices.ContainsFunc(findOptionsFields, func(f string) bool { return doc.Get(f) != nil }) {
+		var deep *wirebson.Document
+		if deep, err = spec.DecodeDeep(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if opts, err = h.findOptions(connCtx, dbName, deep); err != nil {
+			return nil, err
+		}
+
+		if spec, err = deep.Encode(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if masks != nil {
+		if opts == nil {
+			opts = &findOptions{db: dbName, collection: collection}
+		}
+
+		opts.masks = masks
+	}
+
+	if opts == nil && h.paramValues.caseInsensitiveIndexes.Load() {
+		var deep *wirebson.Document
+		if deep, err = spec.DecodeDeep(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res, ok, err := h.findCaseInsensitive(connCtx, dbName, deep)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if ok {
+			return middleware.ResponseMsg(res)
+		}
+	}
+
 	page, cursorID, err := h.Pool.Find(connCtx, dbName, spec)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -52,5 +139,24 @@ func (h *Handler) msgFind(connCtx context.Context, req *middleware.Request) (*mi
 
 	h.s.AddCursor(connCtx, userID, sessionID, cursorID)
 
-	return middleware.ResponseMsg(page)
+	h.adviseIndex(connCtx, dbName, spec)
+
+	if noCursorTimeout && cursorID != 0 {
+		h.Pool.SetCursorNoTimeout(cursorID)
+	}
+
+	h.shareCursor(connCtx, userID, sessionID, cursorID, opts, noCursorTimeout)
+
+	h.trackCursor(connCtx, cursorID, opts, page)
+
+	if opts == nil {
+		return middleware.ResponseMsg(page)
+	}
+
+	res, err := h.applyFindOptions(connCtx, opts, page)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return middleware.ResponseMsg(res)
 }
@@ -50,6 +50,37 @@ func (h *Handler) msgFindAndModify(connCtx context.Context, req *middleware.Requ
 		return nil, err
 	}
 
+	if err = validateSort(doc.Get("sort")); err != nil {
+		return nil, err
+	}
+
+	if err = validateProjection(doc.Get("fields")); err != nil {
+		return nil, err
+	}
+
+	collection, _ := doc.Get(doc.Command()).(string)
+
+	masks, err := h.fieldMasks(connCtx, dbName, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = masks.checkProjection(doc.Command(), doc.Get("fields")); err != nil {
+		return nil, err
+	}
+
+	if err = masks.checkQuery(doc.Command(), doc); err != nil {
+		return nil, err
+	}
+
+	if err = validateCurrentDate(doc.Get("update")); err != nil {
+		return nil, err
+	}
+
+	if err = validateRename(doc.Get("update")); err != nil {
+		return nil, err
+	}
+
 	// TODO https://github.com/microsoft/documentdb/issues/148
 	if v := doc.Get("bypassEmptyTsReplacement"); v != nil {
 		h.L.WarnContext(connCtx, "bypassEmptyTsReplacement is not supported by DocumentDB yet", slog.Any("value", v))
@@ -57,6 +88,14 @@ func (h *Handler) msgFindAndModify(connCtx context.Context, req *middleware.Requ
 		spec = must.NotFail(doc.Encode())
 	}
 
+	if spec, _, err = h.applyDefaultCollation(connCtx, dbName, spec, nil); err != nil {
+		return nil, err
+	}
+
+	if spec, _, err = h.applyDocumentFilter(connCtx, dbName, spec, nil); err != nil {
+		return nil, err
+	}
+
 	var res wirebson.RawDocument
 
 	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
@@ -67,5 +106,18 @@ func (h *Handler) msgFindAndModify(connCtx context.Context, req *middleware.Requ
 		return nil, lazyerrors.Error(err)
 	}
 
-	return middleware.ResponseMsg(res)
+	if masks == nil {
+		return middleware.ResponseMsg(res)
+	}
+
+	out, err := res.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if value, _ := out.Get("value").(*wirebson.Document); value != nil {
+		masks.apply(value)
+	}
+
+	return middleware.ResponseMsg(out)
 }
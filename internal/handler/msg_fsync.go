@@ -0,0 +1,87 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgFsync implements `fsync` command.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgFsync(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	var lock bool
+
+	if v := doc.Get("lock"); v != nil {
+		if lock, err = getBoolParam("lock", v); err != nil {
+			return nil, err
+		}
+	}
+
+	// PostgreSQL makes committed transactions durable by itself,
+	// so there is nothing to flush; only the lock requires work
+	if !lock {
+		return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+			"numFiles", int32(1),
+			"ok", float64(1),
+		)))
+	}
+
+	count, err := h.fsync.Lock(connCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	h.L.WarnContext(connCtx, "Writes are blocked by fsync lock", slog.Int("lockCount", int(count)))
+
+	return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+		"info", "now locked against writes, use db.fsyncUnlock() to unlock",
+		"lockCount", int64(count),
+		"seeAlso", "http://dochub.mongodb.org/core/fsynccommand",
+		"ok", float64(1),
+	)))
+}
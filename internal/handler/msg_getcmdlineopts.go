@@ -37,9 +37,23 @@ func (h *Handler) msgGetCmdLineOpts(connCtx context.Context, req *middleware.Req
 		return nil, err
 	}
 
+	argv := wirebson.MakeArray(len(h.CmdLineArgv))
+	for _, arg := range h.CmdLineArgv {
+		must.NoError(argv.Add(arg))
+	}
+
+	if argv.Len() == 0 {
+		must.NoError(argv.Add("ferretdb"))
+	}
+
+	parsed := h.CmdLineParsed
+	if parsed == nil {
+		parsed = wirebson.MakeDocument(0)
+	}
+
 	return middleware.ResponseMsg(wirebson.MustDocument(
-		"argv", must.NotFail(wirebson.NewArray("ferretdb")),
-		"parsed", must.NotFail(wirebson.NewDocument()),
+		"argv", argv,
+		"parsed", parsed,
 		"ok", float64(1),
 	))
 }
@@ -18,6 +18,7 @@
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
 	"strings"
 	"time"
 
@@ -52,6 +53,20 @@ func (h *Handler) msgGetLog(connCtx context.Context, req *middleware.Request) (*
 	}
 
 	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
 	getLog := doc.Get(command)
 
 	if _, ok := getLog.(wirebson.NullType); ok {
@@ -84,13 +99,18 @@ func (h *Handler) msgGetLog(connCtx context.Context, req *middleware.Request) (*
 		var log *wirebson.Array
 
 		// TODO https://github.com/FerretDB/FerretDB/issues/4750
-		if log, err = h.L.Handler().(*logging.Handler).RecentEntries(); err != nil {
+		lh := h.L.Handler().(*logging.Handler)
+
+		if log, err = lh.RecentEntries(); err != nil {
 			return nil, lazyerrors.Error(err)
 		}
 
+		// like MongoDB, count all lines, not only returned ones
+		total := max(int(lh.TotalEntries()), log.Len())
+
 		res = must.NotFail(wirebson.NewDocument(
 			"log", log,
-			"totalLinesWritten", int32(log.Len()),
+			"totalLinesWritten", int32(min(total, math.MaxInt32)),
 			"ok", float64(1),
 		))
 
@@ -128,15 +148,18 @@ func (h *Handler) msgGetLog(connCtx context.Context, req *middleware.Request) (*
 			)
 		}
 
-		switch {
-		case state.Telemetry == nil:
+		startupWarnings = append(startupWarnings, state.BackendWarnings...)
+
+		if state.Telemetry == nil {
 			startupWarnings = append(
 				startupWarnings,
 				"The telemetry state is undecided. "+
 					"Read more about FerretDB telemetry and how to opt out at https://beacon.ferretdb.com.",
 			)
+		}
 
-		case state.UpdateInfo != "", state.UpdateAvailable:
+		// update availability could be set by the update checker even if telemetry is undecided
+		if state.UpdateInfo != "" || state.UpdateAvailable {
 			msg := state.UpdateInfo
 			if msg == "" {
 				msg = fmt.Sprintf(
@@ -148,6 +171,14 @@ func (h *Handler) msgGetLog(connCtx context.Context, req *middleware.Request) (*
 			startupWarnings = append(startupWarnings, msg)
 		}
 
+		if !h.Auth {
+			startupWarnings = append(
+				startupWarnings,
+				"Access control is not enabled for the database. "+
+					"Read and write access to data and configuration is unrestricted.",
+			)
+		}
+
 		if devbuild.Enabled {
 			startupWarnings = append(
 				startupWarnings,
@@ -195,7 +226,7 @@ func (h *Handler) msgGetLog(connCtx context.Context, req *middleware.Request) (*
 	default:
 		return nil, mongoerrors.New(
 			mongoerrors.ErrOperationFailed,
-			fmt.Sprintf("no RecentEntries named: %s", getLog),
+			fmt.Sprintf("No log named '%s'", getLog),
 		)
 	}
 
@@ -17,10 +17,12 @@
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
 	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
 	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
 )
 
 // msgGetMore implements `getMore` command.
@@ -56,14 +58,47 @@ func (h *Handler) msgGetMore(connCtx context.Context, req *middleware.Request) (
 		return nil, err
 	}
 
+	// the cursor could be created or advanced by another instance
+	if err = h.resumeSharedCursor(connCtx, cursorID); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	if err = h.s.ValidateCursor(userID, sessionID, cursorID); err != nil {
 		return nil, err
 	}
 
+	// get options before the last page closes the cursor
+	data, _ := h.Pool.CursorData(cursorID).(*cursorData)
+	if data == nil {
+		data = new(cursorData)
+		h.Pool.SetCursorData(cursorID, data)
+	}
+
+	opts := data.opts
+
+	if spec, err = data.applyBatchSize(spec); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	page, err := h.Pool.GetMore(connCtx, dbName, spec, cursorID)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	return middleware.ResponseMsg(page)
+	if err = data.record(page); err != nil {
+		h.L.WarnContext(connCtx, "Failed to record batch size", slog.Int64("cursor", cursorID), logging.Error(err))
+	}
+
+	h.updateSharedCursor(connCtx, userID, sessionID, cursorID)
+
+	if opts == nil {
+		return middleware.ResponseMsg(page)
+	}
+
+	res, err := h.applyFindOptions(connCtx, opts, page)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return middleware.ResponseMsg(res)
 }
@@ -16,6 +16,8 @@
 
 import (
 	"context"
+	"maps"
+	"slices"
 
 	"github.com/FerretDB/wire/wirebson"
 
@@ -51,36 +53,17 @@ func (h *Handler) msgGetParameter(connCtx context.Context, req *middleware.Reque
 		return nil, lazyerrors.Error(err)
 	}
 
-	parameters := must.NotFail(wirebson.NewDocument(
-		// to add a new parameter, fill template and place it in the alphabetical order position
-		//"<name>", must.NotFail(bson.NewDocument(
-		//	"value", <value>,
-		//	"settableAtRuntime", <bool>,
-		//	"settableAtStartup", <bool>,
-		//)),
-		"authenticationMechanisms", must.NotFail(wirebson.NewDocument(
-			"value", must.NotFail(wirebson.NewArray("SCRAM-SHA-1", "SCRAM-SHA-256")),
-			"settableAtRuntime", false,
-			"settableAtStartup", true,
-		)),
-		"authSchemaVersion", must.NotFail(wirebson.NewDocument(
-			"value", int32(5),
-			"settableAtRuntime", true,
-			"settableAtStartup", true,
-		)),
-		"featureCompatibilityVersion", must.NotFail(wirebson.NewDocument(
-			// TODO https://github.com/FerretDB/FerretDB/issues/5073
-			"value", must.NotFail(wirebson.NewDocument("version", "7.0")),
-			"settableAtRuntime", false,
-			"settableAtStartup", false,
-		)),
-		"quiet", must.NotFail(wirebson.NewDocument(
-			"value", false,
-			"settableAtRuntime", true,
-			"settableAtStartup", true,
-		)),
-		// parameters are alphabetically ordered
-	))
+	parameters := wirebson.MakeDocument(len(h.params))
+
+	for _, name := range slices.Sorted(maps.Keys(h.params)) {
+		p := h.params[name]
+
+		must.NoError(parameters.Add(name, must.NotFail(wirebson.NewDocument(
+			"value", p.get(),
+			"settableAtRuntime", p.settableAtRuntime,
+			"settableAtStartup", p.settableAtStartup,
+		))))
+	}
 
 	res, err := selectParameters(doc, parameters, showDetails, allParameters)
 	if err != nil {
@@ -0,0 +1,53 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgGetShardMap implements `getShardMap` command.
+//
+// It returns a single logical shard for tools that probe sharding,
+// or Citus nodes with experimental sharding.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgGetShardMap(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	if _, err := h.shardingRequest(connCtx, req); err != nil {
+		return nil, err
+	}
+
+	m := wirebson.MakeDocument(1)
+	hosts := wirebson.MakeDocument(1)
+	connStrings := wirebson.MakeDocument(1)
+
+	for _, s := range h.shards(connCtx) {
+		must.NoError(m.Add(s.name, s.host))
+		must.NoError(hosts.Add(s.host, s.name))
+		must.NoError(connStrings.Add(s.host, s.name))
+	}
+
+	return middleware.ResponseMsg(wirebson.MustDocument(
+		"map", m,
+		"hosts", hosts,
+		"connStrings", connStrings,
+		"ok", float64(1),
+	))
+}
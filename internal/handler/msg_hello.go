@@ -50,6 +50,8 @@ func (h *Handler) msgHello(connCtx context.Context, req *middleware.Request) (*m
 		return nil, err
 	}
 
+	h.awaitTopologyChange(connCtx, doc)
+
 	res, err := h.hello(connCtx, doc, h.TCPHost, h.ReplSetName)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -75,14 +77,14 @@ func (h *Handler) hello(ctx context.Context, spec wirebson.AnyDocument, tcpHost,
 
 	switch doc.Command() {
 	case "hello":
-		must.NoError(res.Add("isWritablePrimary", true))
+		must.NoError(res.Add("isWritablePrimary", h.Pool.Healthy()))
 
 	case "isMaster", "ismaster":
 		if helloOk := doc.Get("helloOk"); helloOk != nil {
 			must.NoError(res.Add("helloOk", true))
 		}
 
-		must.NoError(res.Add("ismaster", true))
+		must.NoError(res.Add("ismaster", h.Pool.Healthy()))
 
 	default:
 		panic(fmt.Sprintf("unexpected command: %q", doc.Command()))
@@ -100,6 +102,7 @@ func (h *Handler) hello(ctx context.Context, spec wirebson.AnyDocument, tcpHost,
 		must.NoError(res.Add("hosts", must.NotFail(wirebson.NewArray(tcpHost))))
 	}
 
+	must.NoError(res.Add("topologyVersion", h.topologyVersion()))
 	must.NoError(res.Add("maxBsonObjectSize", maxBsonObjectSize))
 	must.NoError(res.Add("maxMessageSizeBytes", int32(wire.MaxMsgLen)))
 	must.NoError(res.Add("maxWriteBatchSize", maxWriteBatchSize))
@@ -52,8 +52,9 @@ func (h *Handler) msgHostInfo(connCtx context.Context, req *middleware.Request)
 	}
 
 	var osName, osVersion string
+	var info linuxHostInfo
 
-	// try to parse Linux distro name and version, but do not fail if they are not present
+	// try to parse Linux distro name, version, and host details, but do not fail if they are not present
 	if runtime.GOOS == "linux" {
 		file, err := os.Open("/etc/os-release")
 		if err != nil {
@@ -64,37 +65,272 @@ func (h *Handler) msgHostInfo(connCtx context.Context, req *middleware.Request)
 			defer file.Close() //nolint:errcheck // we are only reading it
 			osName, osVersion, _ = parseOSRelease(file)
 		}
+
+		info = readLinuxHostInfo()
 	}
 
-	os := "unknown"
+	osType := "unknown"
 
 	switch runtime.GOOS {
 	case "linux":
-		os = "Linux"
+		osType = "Linux"
 	case "darwin":
-		os = "macOS"
+		osType = "macOS"
 	case "windows":
-		os = "Windows"
+		osType = "Windows"
+	}
+
+	system := must.NotFail(wirebson.NewDocument(
+		"currentTime", now,
+		"hostname", hostname,
+		"cpuAddrSize", int32(strconv.IntSize),
+	))
+
+	if info.memSizeMB > 0 {
+		memLimitMB := info.memSizeMB
+		if info.memLimitMB > 0 {
+			memLimitMB = min(memLimitMB, info.memLimitMB)
+		}
+
+		must.NoError(system.Add("memSizeMB", info.memSizeMB))
+		must.NoError(system.Add("memLimitMB", memLimitMB))
 	}
 
+	must.NoError(system.Add("numCores", int32(runtime.NumCPU())))
+
+	if info.physicalCores > 0 {
+		must.NoError(system.Add("numPhysicalCores", info.physicalCores))
+		must.NoError(system.Add("numCpuSockets", info.sockets))
+	}
+
+	must.NoError(system.Add("cpuArch", runtime.GOARCH))
+
 	return middleware.ResponseMsg(wirebson.MustDocument(
-		"system", must.NotFail(wirebson.NewDocument(
-			"currentTime", now,
-			"hostname", hostname,
-			"cpuAddrSize", int32(strconv.IntSize),
-			"numCores", int32(runtime.GOMAXPROCS(-1)),
-			"cpuArch", runtime.GOARCH,
-		)),
+		"system", system,
 		"os", must.NotFail(wirebson.NewDocument(
-			"type", os,
+			"type", osType,
 			"name", osName,
 			"version", osVersion,
 		)),
-		"extra", must.NotFail(wirebson.NewDocument()),
+		"extra", info.extra(),
 		"ok", float64(1),
 	))
 }
 
+// linuxHostInfo contains host details read from Linux procfs and cgroup files.
+// Zero values mean that details are not known.
+type linuxHostInfo struct {
+	versionString string
+	kernelVersion string
+	cpu           cpuInfo
+	memSizeMB     int64
+	memLimitMB    int64 // cgroup memory limit
+	physicalCores int32
+	sockets       int32
+	pageSize      int64
+	maxOpenFiles  int64
+}
+
+// cpuInfo contains processor details parsed from /proc/cpuinfo.
+type cpuInfo struct {
+	model         string
+	frequencyMHz  string
+	features      string
+	physicalCores int32
+	sockets       int32
+}
+
+// readLinuxHostInfo reads host details, skipping files that can't be read.
+func readLinuxHostInfo() linuxHostInfo {
+	info := linuxHostInfo{
+		pageSize: int64(os.Getpagesize()),
+	}
+
+	if b, err := os.ReadFile("/proc/version"); err == nil {
+		info.versionString = strings.TrimSpace(string(b))
+	}
+
+	if b, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		info.kernelVersion = strings.TrimSpace(string(b))
+	}
+
+	if f, err := os.Open("/proc/meminfo"); err == nil {
+		kb, _ := parseMemInfo(f)
+		_ = f.Close()
+
+		info.memSizeMB = kb / 1024
+	}
+
+	// cgroup v2, then v1
+	for _, p := range []string{"/sys/fs/cgroup/memory.max", "/sys/fs/cgroup/memory/memory.limit_in_bytes"} {
+		if b, err := os.ReadFile(p); err == nil {
+			if limit, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64); err == nil {
+				info.memLimitMB = limit / 1024 / 1024
+				break
+			}
+		}
+	}
+
+	if f, err := os.Open("/proc/cpuinfo"); err == nil {
+		info.cpu, _ = parseCPUInfo(f)
+		_ = f.Close()
+
+		info.physicalCores = info.cpu.physicalCores
+		info.sockets = info.cpu.sockets
+	}
+
+	if f, err := os.Open("/proc/self/limits"); err == nil {
+		info.maxOpenFiles, _ = parseMaxOpenFiles(f)
+		_ = f.Close()
+	}
+
+	return info
+}
+
+// extra returns the `extra` field of `hostInfo` response.
+func (info *linuxHostInfo) extra() *wirebson.Document {
+	res := wirebson.MakeDocument(0)
+
+	for _, f := range []struct {
+		name  string
+		value string
+	}{
+		{"versionString", info.versionString},
+		{"kernelVersion", info.kernelVersion},
+		{"cpuString", info.cpu.model},
+		{"cpuFrequencyMHz", info.cpu.frequencyMHz},
+		{"cpuFeatures", info.cpu.features},
+	} {
+		if f.value != "" {
+			must.NoError(res.Add(f.name, f.value))
+		}
+	}
+
+	if info.pageSize > 0 {
+		must.NoError(res.Add("pageSize", info.pageSize))
+
+		if info.memSizeMB > 0 {
+			must.NoError(res.Add("numPages", info.memSizeMB*1024*1024/info.pageSize))
+		}
+	}
+
+	if info.maxOpenFiles > 0 {
+		must.NoError(res.Add("maxOpenFiles", info.maxOpenFiles))
+	}
+
+	return res
+}
+
+// parseMemInfo parses the /proc/meminfo file content, returning the total memory size in kilobytes.
+func parseMemInfo(r io.Reader) (int64, error) {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "MemTotal:")
+		if !ok {
+			continue
+		}
+
+		kb, err := strconv.ParseInt(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "kB")), 10, 64)
+		if err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		return kb, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return 0, nil
+}
+
+// parseCPUInfo parses the /proc/cpuinfo file content.
+// Physical cores and sockets are counted only if the content includes their IDs (not on all architectures).
+func parseCPUInfo(r io.Reader) (cpuInfo, error) {
+	var res cpuInfo
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024) // flags lines are long
+
+	cores := map[[2]string]struct{}{}
+	sockets := map[string]struct{}{}
+
+	var socket string
+
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch key {
+		case "model name":
+			if res.model == "" {
+				res.model = value
+			}
+		case "cpu MHz":
+			if res.frequencyMHz == "" {
+				res.frequencyMHz = value
+			}
+		case "flags", "Features":
+			if res.features == "" {
+				res.features = value
+			}
+		case "physical id":
+			socket = value
+			sockets[socket] = struct{}{}
+		case "core id":
+			cores[[2]string{socket, value}] = struct{}{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return res, lazyerrors.Error(err)
+	}
+
+	if len(sockets) > 0 {
+		res.physicalCores = int32(len(cores))
+		res.sockets = int32(len(sockets))
+	}
+
+	return res, nil
+}
+
+// parseMaxOpenFiles parses the /proc/self/limits file content, returning the soft limit of open files.
+// It returns zero if the limit is not set.
+func parseMaxOpenFiles(r io.Reader) (int64, error) {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "Max open files")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(value)
+		if len(fields) == 0 || fields[0] == "unlimited" {
+			return 0, nil
+		}
+
+		n, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		return n, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return 0, nil
+}
+
 // parseOSRelease parses the /etc/os-release or /usr/lib/os-release file content,
 // returning the OS name and version.
 func parseOSRelease(r io.Reader) (string, string, error) {
@@ -16,6 +16,8 @@
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -112,3 +114,48 @@ func TestParseOSRelease(t *testing.T) {
 		assert.Equal(t, testCase["VERSION"], osVersion)
 	}
 }
+
+func TestParseHostInfo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("MemInfo", func(t *testing.T) {
+		t.Parallel()
+
+		kb, err := parseMemInfo(strings.NewReader("MemTotal:       16318684 kB\nMemFree:         1024 kB\n"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(16318684), kb)
+	})
+
+	t.Run("CPUInfo", func(t *testing.T) {
+		t.Parallel()
+
+		var b strings.Builder
+		for i := range 4 {
+			fmt.Fprintf(&b, "processor\t: %d\nmodel name\t: Test CPU\ncpu MHz\t\t: 2400.000\n", i)
+			fmt.Fprintf(&b, "flags\t\t: fpu sse2\nphysical id\t: 0\ncore id\t\t: %d\n\n", i/2)
+		}
+
+		info, err := parseCPUInfo(strings.NewReader(b.String()))
+		require.NoError(t, err)
+
+		expected := cpuInfo{
+			model:         "Test CPU",
+			frequencyMHz:  "2400.000",
+			features:      "fpu sse2",
+			physicalCores: 2,
+			sockets:       1,
+		}
+		assert.Equal(t, expected, info)
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		t.Parallel()
+
+		limits := "Limit                     Soft Limit           Hard Limit           Units\n" +
+			"Max open files            1024                 524288               files\n"
+
+		n, err := parseMaxOpenFiles(strings.NewReader(limits))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1024), n)
+	})
+}
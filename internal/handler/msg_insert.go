@@ -63,5 +63,8 @@ func (h *Handler) msgInsert(connCtx context.Context, req *middleware.Request) (*
 		return nil, lazyerrors.Error(err)
 	}
 
-	return middleware.ResponseMsg(mongoerrors.MapWriteErrors(connCtx, res))
+	collection, _ := doc.Get(doc.Command()).(string)
+	mapped := mongoerrors.MapWriteErrors(connCtx, res)
+
+	return middleware.ResponseMsg(h.addDuplicateKeyInfo(connCtx, dbName, collection, req.OpMsg, mapped))
 }
@@ -67,7 +67,35 @@ func checkClientMetadata(ctx context.Context, doc *wirebson.Document) error {
 		)
 	}
 
-	connInfo.SetMetadataRecv()
+	connInfo.SetMetadataRecv(clientAppName(c))
 
 	return nil
 }
+
+// clientAppName returns the application name from the client metadata,
+// or empty string if it is not present or invalid.
+func clientAppName(client any) string {
+	c, ok := client.(wirebson.AnyDocument)
+	if !ok {
+		return ""
+	}
+
+	cd, err := c.Decode()
+	if err != nil {
+		return ""
+	}
+
+	a, ok := cd.Get("application").(wirebson.AnyDocument)
+	if !ok {
+		return ""
+	}
+
+	ad, err := a.Decode()
+	if err != nil {
+		return ""
+	}
+
+	name, _ := ad.Get("name").(string)
+
+	return name
+}
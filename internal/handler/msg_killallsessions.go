@@ -59,6 +59,8 @@ func (h *Handler) msgKillAllSessions(connCtx context.Context, req *middleware.Re
 			_ = h.Pool.KillCursor(connCtx, cursorID)
 		}
 
+		h.killRemoteSessions(connCtx, clusterMessage{All: true})
+
 		return middleware.ResponseMsg(wirebson.MustDocument(
 			"ok", float64(1),
 		))
@@ -70,6 +72,8 @@ func (h *Handler) msgKillAllSessions(connCtx context.Context, req *middleware.Re
 		_ = h.Pool.KillCursor(connCtx, cursorID)
 	}
 
+	h.killRemoteSessions(connCtx, clusterMessage{UserIDs: userIDs})
+
 	return middleware.ResponseMsg(wirebson.MustDocument(
 		"ok", float64(1),
 	))
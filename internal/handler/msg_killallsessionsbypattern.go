@@ -144,6 +144,20 @@ func (h *Handler) msgKillAllSessionsByPattern(connCtx context.Context, req *midd
 		_ = h.Pool.KillCursor(connCtx, cursorID)
 	}
 
+	switch {
+	case allSessions:
+		h.killRemoteSessions(connCtx, clusterMessage{All: true})
+
+	default:
+		if len(userIDs) > 0 {
+			h.killRemoteSessions(connCtx, clusterMessage{UserIDs: userIDs})
+		}
+
+		for userID, sessionIDs := range lsids {
+			h.killRemoteSessions(connCtx, clusterMessage{UserIDs: []session.UserID{userID}, SessionIDs: sessionIDs})
+		}
+	}
+
 	return middleware.ResponseMsg(wirebson.MustDocument(
 		"ok", float64(1),
 	))
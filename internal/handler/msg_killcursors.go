@@ -77,7 +77,7 @@ func (h *Handler) msgKillCursors(connCtx context.Context, req *middleware.Reques
 		return nil, lazyerrors.Error(err)
 	}
 
-	var ids []int64
+	var ids, notFound []int64
 	cursorsKilled := wirebson.MakeArray(0)
 	cursorsNotFound := wirebson.MakeArray(0)
 	cursorsAlive := wirebson.MakeArray(0)
@@ -107,18 +107,37 @@ func (h *Handler) msgKillCursors(connCtx context.Context, req *middleware.Reques
 		// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/17
 		_, _, _ = db, collection, username
 
+		// load the owner of the cursor shared by another instance
+		if err = h.resumeSharedCursor(connCtx, id); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
 		if err = h.s.DeleteCursor(userID, id, db); err != nil {
 			return nil, err
 		}
 
 		if deleted := h.Pool.KillCursor(connCtx, id); !deleted {
 			must.NoError(cursorsNotFound.Add(id))
+			notFound = append(notFound, id)
+
 			continue
 		}
 
 		must.NoError(cursorsKilled.Add(id))
 	}
 
+	h.deleteSharedCursors(connCtx, ids)
+
+	// cursors could be created by other instances,
+	// and shared cursors could be resumed by them
+	if h.paramValues.sharedCursors.Load() {
+		notFound = ids
+	}
+
+	if len(notFound) > 0 {
+		h.notifyCluster(connCtx, clusterMessage{Type: clusterKillCursors, CursorIDs: notFound})
+	}
+
 	return middleware.ResponseMsg(wirebson.MustDocument(
 		"cursorsKilled", cursorsKilled,
 		"cursorsNotFound", cursorsNotFound,
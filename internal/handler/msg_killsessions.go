@@ -58,6 +58,8 @@ func (h *Handler) msgKillSessions(connCtx context.Context, req *middleware.Reque
 			_ = h.Pool.KillCursor(connCtx, cursorID)
 		}
 
+		h.killRemoteSessions(connCtx, clusterMessage{UserIDs: []session.UserID{userID}})
+
 		return middleware.ResponseMsg(wirebson.MustDocument(
 			"ok", float64(1),
 		))
@@ -69,6 +71,8 @@ func (h *Handler) msgKillSessions(connCtx context.Context, req *middleware.Reque
 		_ = h.Pool.KillCursor(connCtx, cursorID)
 	}
 
+	h.killRemoteSessions(connCtx, clusterMessage{UserIDs: []session.UserID{userID}, SessionIDs: ids})
+
 	return middleware.ResponseMsg(wirebson.MustDocument(
 		"ok", float64(1),
 	))
@@ -52,6 +52,12 @@ func (h *Handler) msgListCollections(connCtx context.Context, req *middleware.Re
 	}
 
 	h.s.AddCursor(connCtx, userID, sessionID, cursorID)
+	h.shareCursor(connCtx, userID, sessionID, cursorID, nil, false)
 
-	return middleware.ResponseMsg(page)
+	res, err := h.applyCollectionOptions(connCtx, dbName, page)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return middleware.ResponseMsg(res)
 }
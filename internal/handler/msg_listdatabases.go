@@ -41,7 +41,6 @@ func (h *Handler) msgListDatabases(connCtx context.Context, req *middleware.Requ
 	var res wirebson.RawDocument
 
 	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
-		// TODO https://github.com/FerretDB/FerretDB/issues/4862
 		// TODO https://github.com/microsoft/documentdb/issues/121
 		res, err = documentdb_api.ListDatabases(connCtx, conn, h.L, spec)
 		return err
@@ -50,5 +49,74 @@ func (h *Handler) msgListDatabases(connCtx context.Context, req *middleware.Requ
 		return nil, lazyerrors.Error(err)
 	}
 
-	return middleware.ResponseMsg(res)
+	doc, err := res.Decode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = fixListDatabasesTotalSize(doc); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return middleware.ResponseMsg(doc)
+}
+
+// fixListDatabasesTotalSize sets `totalSize` and `totalSizeMb` fields of `listDatabases` response
+// to the total size of returned databases only, like MongoDB does for filtered results.
+// Responses without those fields (for example, for `nameOnly`) are not modified.
+func fixListDatabasesTotalSize(doc *wirebson.Document) error {
+	if doc.Get("totalSize") == nil {
+		return nil
+	}
+
+	databases, ok := doc.Get("databases").(wirebson.AnyArray)
+	if !ok {
+		return nil
+	}
+
+	arr, err := databases.Decode()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var total int64
+
+	for v := range arr.Values() {
+		db, ok := v.(wirebson.AnyDocument)
+		if !ok {
+			continue
+		}
+
+		var dbDoc *wirebson.Document
+
+		if dbDoc, err = db.Decode(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		switch size := dbDoc.Get("sizeOnDisk").(type) {
+		case int64:
+			total += size
+		case int32:
+			total += int64(size)
+		case float64:
+			total += int64(size)
+		}
+	}
+
+	var totalSize any = total
+	if _, ok = doc.Get("totalSize").(float64); ok {
+		totalSize = float64(total)
+	}
+
+	if err = doc.Replace("totalSize", totalSize); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if doc.Get("totalSizeMb") != nil {
+		if err = doc.Replace("totalSizeMb", total/(1024*1024)); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
 }
@@ -52,6 +52,20 @@ func (h *Handler) msgListIndexes(connCtx context.Context, req *middleware.Reques
 	}
 
 	h.s.AddCursor(connCtx, userID, sessionID, cursorID)
+	h.shareCursor(connCtx, userID, sessionID, cursorID, nil, false)
 
-	return middleware.ResponseMsg(page)
+	collection, _ := doc.Get(doc.Command()).(string)
+
+	res, err := h.applyIndexOptions(connCtx, dbName, collection, page)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if builds := h.indexBuilds.forCollection(dbName, collection); len(builds) > 0 {
+		if res, err = markBuildingIndexes(res, builds); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return middleware.ResponseMsg(res)
 }
@@ -0,0 +1,79 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// msgLogRotate implements `logRotate` command.
+//
+// It rotates the log file if logs are written to a file; otherwise, it does nothing.
+// There is no audit log, so `{logRotate: "audit"}` does nothing too.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgLogRotate(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	rotateServer := true
+
+	if logType, ok := doc.Get(command).(string); ok {
+		switch logType {
+		case "server":
+		case "audit":
+			rotateServer = false
+		default:
+			msg := fmt.Sprintf("Unknown log type for rotate: %s", logType)
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+		}
+	}
+
+	if rotateServer && h.LogRotate != nil {
+		if err = h.LogRotate(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		h.L.InfoContext(connCtx, "Log file rotated")
+	}
+
+	return middleware.ResponseMsg(wirebson.MustDocument(
+		"ok", float64(1),
+	))
+}
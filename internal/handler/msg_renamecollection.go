@@ -145,6 +145,8 @@ func (h *Handler) msgRenameCollection(connCtx context.Context, req *middleware.R
 		return nil, lazyerrors.Error(err)
 	}
 
+	h.defaultCollations.reset()
+
 	return middleware.ResponseMsg(wirebson.MustDocument(
 		"ok", float64(1),
 	))
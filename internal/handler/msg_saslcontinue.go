@@ -146,6 +146,29 @@ func (h *Handler) saslContinue(ctx context.Context, doc *wirebson.Document) (*wi
 		)
 	}
 
+	ci := conninfo.Get(ctx)
+
+	allowed, err := h.checkAuthRestrictions(ctx, username, ci.Peer.Addr(), ci.Local.Addr())
+	if err != nil {
+		conninfo.Get(ctx).SetConv(nil)
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !allowed {
+		h.L.WarnContext(
+			ctx, "saslContinue: authentication restrictions are not satisfied",
+			slog.String("username", username), slog.String("peer", ci.Peer.String()),
+		)
+
+		conninfo.Get(ctx).SetConv(nil)
+
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrAuthenticationFailed,
+			"Authentication failed.",
+			"saslContinue",
+		)
+	}
+
 	return wirebson.MustDocument(
 		"conversationId", int32(1),
 		"done", done,
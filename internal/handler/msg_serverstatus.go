@@ -23,10 +23,13 @@
 	"time"
 
 	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/FerretDB/FerretDB/v2/build/version"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
 	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
 	"github.com/FerretDB/FerretDB/v2/internal/util/must"
 )
 
@@ -84,7 +87,7 @@ func (h *Handler) msgServerStatus(connCtx context.Context, req *middleware.Reque
 
 	res := must.NotFail(wirebson.NewDocument(
 		"host", host,
-		"version", info.MongoDBVersion,
+		"version", h.mongoDBVersionString(),
 		"process", filepath.Base(exec),
 		"pid", int64(os.Getpid()),
 		"uptime", uptime.Seconds(),
@@ -115,10 +118,32 @@ func (h *Handler) msgServerStatus(connCtx context.Context, req *middleware.Reque
 			"package", info.Package,
 			"postgresql", state.PostgreSQLVersion,
 			"documentdb", state.DocumentDBVersion,
+			"capabilities", capabilitiesDoc(info),
+			"latestVersion", state.LatestVersion,
+			"updateAvailable", state.UpdateAvailable,
 		)),
-
-		"ok", float64(1),
 	))
 
+	// encryption status is informational; serverStatus should work even if it could not be fetched
+	var enc *documentdb.EncryptionStatus
+
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		enc, err = documentdb.GetEncryptionStatus(connCtx, conn)
+		return err
+	})
+	if err != nil {
+		h.L.DebugContext(connCtx, "Failed to get encryption status", logging.Error(err))
+	} else {
+		must.NoError(res.Add("encryptionAtRest", must.NotFail(wirebson.NewDocument(
+			"encryptionEnabled", enc.Enabled(),
+			"extensionVersion", enc.ExtensionVersion,
+			"defaultAccessMethod", enc.DefaultAccessMethod,
+			"encryptedCollections", enc.EncryptedTables,
+			"unencryptedCollections", enc.UnencryptedTables,
+		))))
+	}
+
+	must.NoError(res.Add("ok", float64(1)))
+
 	return middleware.ResponseMsg(res)
 }
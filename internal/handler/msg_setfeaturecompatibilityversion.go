@@ -0,0 +1,111 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+	"github.com/FerretDB/FerretDB/v2/internal/util/state"
+)
+
+// msgSetFeatureCompatibilityVersion implements `setFeatureCompatibilityVersion` command.
+//
+// The value is persisted in the state file; see [Handler.featureCompatibilityVersion].
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgSetFeatureCompatibilityVersion(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) { //nolint:lll // for readability
+	doc, err := req.OpMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	s, err := getRequiredParam[string](doc, command)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := h.allowedFCVs()
+
+	fcv, ok := parseFCV(s)
+	if !ok || !slices.Contains(allowed, fcv) {
+		expected := make([]string, len(allowed))
+		for i, v := range allowed {
+			expected[i] = "'" + fcvString(v) + "'"
+		}
+
+		msg := fmt.Sprintf(
+			"Invalid feature compatibility version value '%s'; expected %s.",
+			s, strings.Join(expected, " or "),
+		)
+
+		return nil, mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	// MongoDB 7.0+ requires an explicit confirmation because downgrades are not supported without assistance
+	if v := h.mongoDBVersion(); v[0] >= 7 {
+		confirm, err := getOptionalParam(doc, "confirm", false)
+		if err != nil {
+			return nil, err
+		}
+
+		if !confirm {
+			msg := "Once you have upgraded to " + fcvString(fcv) + ", you will not be able to downgrade FCV " +
+				"and binary version without support assistance. " +
+				"Please re-run this command with 'confirm: true' to acknowledge this and continue with the FCV upgrade."
+
+			return nil, mongoerrors.NewWithArgument(mongoerrors.ErrLocation7369100, msg, command)
+		}
+	}
+
+	if err = h.StateProvider.Update(func(s *state.State) {
+		s.FeatureCompatibilityVersion = fcvString(fcv)
+	}); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	h.L.InfoContext(connCtx, "Feature compatibility version set", "version", fcvString(fcv))
+
+	return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+		"ok", float64(1),
+	)))
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// msgSetParameter implements `setParameter` command.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgSetParameter(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	var was any
+
+	for name, v := range doc.All() {
+		switch {
+		case name == command, name == "lsid", name == "comment", strings.HasPrefix(name, "$"):
+			continue
+		}
+
+		p := h.params[name]
+		if p == nil {
+			return nil, mongoerrors.NewWithArgument(
+				mongoerrors.ErrInvalidOptions,
+				fmt.Sprintf("attempted to set unrecognized parameter [%s], use help:true to see options ", name),
+				command,
+			)
+		}
+
+		if !p.settableAtRuntime || p.set == nil {
+			return nil, mongoerrors.NewWithArgument(
+				mongoerrors.ErrIllegalOperation,
+				fmt.Sprintf("not allowed to change [%s] at runtime", name),
+				command,
+			)
+		}
+
+		old := p.get()
+
+		if err = p.set(v); err != nil {
+			return nil, err
+		}
+
+		h.L.InfoContext(connCtx, "Server parameter set", slog.String("name", name), slog.Any("value", v))
+
+		if was == nil {
+			was = old
+		}
+	}
+
+	if was == nil {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrInvalidOptions,
+			"no option found to set, use help:true to see options ",
+			command,
+		)
+	}
+
+	return middleware.ResponseMsg(must.NotFail(wirebson.NewDocument(
+		"was", was,
+		"ok", float64(1),
+	)))
+}
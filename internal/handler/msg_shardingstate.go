@@ -0,0 +1,40 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+)
+
+// msgShardingState implements `shardingState` command.
+//
+// FerretDB is not a member of a sharded cluster, so sharding is reported as disabled
+// unless experimental sharding is enabled.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgShardingState(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	if _, err := h.shardingRequest(connCtx, req); err != nil {
+		return nil, err
+	}
+
+	return middleware.ResponseMsg(wirebson.MustDocument(
+		"enabled", h.paramValues.experimentalSharding.Load(),
+		"ok", float64(1),
+	))
+}
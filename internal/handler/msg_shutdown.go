@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"math"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// msgShutdown implements `shutdown` command.
+//
+// It starts the graceful shutdown of the whole FerretDB instance;
+// already connected clients are given a few seconds to finish their requests.
+// `force` and `timeoutSecs` options are validated but otherwise ignored,
+// as there are no secondaries to wait for.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgShutdown(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := req.OpMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	var force bool
+
+	if v := doc.Get("force"); v != nil {
+		if force, err = getBoolParam(command+".force", v); err != nil {
+			return nil, err
+		}
+	}
+
+	var timeoutSecs int64
+
+	if v := doc.Get("timeoutSecs"); v != nil {
+		if timeoutSecs, err = parameterInt64("timeoutSecs", v, 0, math.MaxInt64); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.Shutdown == nil {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrIllegalOperation,
+			"shutdown is not supported for this instance",
+			command,
+		)
+	}
+
+	h.L.WarnContext(
+		connCtx, "Shutdown requested by client",
+		slog.Bool("force", force), slog.Int64("timeoutSecs", timeoutSecs),
+	)
+
+	h.Shutdown()
+
+	return middleware.ResponseMsg(wirebson.MustDocument(
+		"ok", float64(1),
+	))
+}
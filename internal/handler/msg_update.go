@@ -53,6 +53,61 @@ func (h *Handler) msgUpdate(connCtx context.Context, req *middleware.Request) (*
 		spec = must.NotFail(doc.Encode())
 	}
 
+	statements, err := commandStatements(doc, "updates", seq)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	ordered, ok := doc.Get("ordered").(bool)
+	if !ok {
+		ordered = true
+	}
+
+	valid, indexes, writeErrors, err := validateUpdateStatements(statements, ordered)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(writeErrors) > 0 {
+		if len(valid) == 0 {
+			empty := wirebson.MustDocument("n", int32(0), "nModified", int32(0), "ok", float64(1))
+
+			var resDoc *wirebson.Document
+			if resDoc, err = addWriteErrors(empty, nil, writeErrors, ordered); err != nil {
+				return nil, err
+			}
+
+			return middleware.ResponseMsg(resDoc)
+		}
+
+		// only valid statements are executed by DocumentDB
+		updates := wirebson.MakeArray(len(valid))
+		for _, st := range valid {
+			must.NoError(updates.Add(st))
+		}
+
+		doc.Remove("updates")
+		must.NoError(doc.Add("updates", updates))
+
+		if spec, err = doc.Encode(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		seq = nil
+	}
+
+	if spec, seq, err = h.applyDefaultCollation(connCtx, dbName, spec, seq); err != nil {
+		return nil, err
+	}
+
+	if err = h.checkStatements(connCtx, dbName, doc, seq); err != nil {
+		return nil, err
+	}
+
+	if spec, seq, err = h.applyDocumentFilter(connCtx, dbName, spec, seq); err != nil {
+		return nil, err
+	}
+
 	var res wirebson.RawDocument
 
 	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
@@ -63,5 +118,14 @@ func (h *Handler) msgUpdate(connCtx context.Context, req *middleware.Request) (*
 		return nil, lazyerrors.Error(err)
 	}
 
-	return middleware.ResponseMsg(mongoerrors.MapWriteErrors(connCtx, res))
+	collection, _ := doc.Get(doc.Command()).(string)
+	mapped := mongoerrors.MapWriteErrors(connCtx, res)
+
+	if len(writeErrors) > 0 {
+		if mapped, err = addWriteErrors(mapped, indexes, writeErrors, ordered); err != nil {
+			return nil, err
+		}
+	}
+
+	return middleware.ResponseMsg(h.addDuplicateKeyInfo(connCtx, dbName, collection, nil, mapped))
 }
@@ -63,19 +63,30 @@ func (h *Handler) msgUpdateUser(connCtx context.Context, req *middleware.Request
 	}
 
 	if userPassword := doc.Get("pwd"); userPassword != nil {
+		if pwd, ok := userPassword.(string); ok {
+			if err = h.checkPassword(doc.Command(), pwd); err != nil {
+				return nil, err
+			}
+		}
+
 		must.NoError(updateSpec.Add("pwd", userPassword))
 	}
 
-	if authRestrictions := doc.Get("authenticationRestrictions"); authRestrictions != nil {
-		must.NoError(updateSpec.Add("authenticationRestrictions", authRestrictions))
+	// authentication restrictions are stored and enforced by FerretDB
+	var restrictions *wirebson.Array
+
+	if v := doc.Get("authenticationRestrictions"); v != nil {
+		if restrictions, _, err = getAuthRestrictionsParam(doc.Command(), v); err != nil {
+			return nil, err
+		}
 	}
 
 	if mechanisms := doc.Get("mechanisms"); mechanisms != nil {
 		must.NoError(updateSpec.Add("mechanisms", mechanisms))
 	}
 
-	if passwordDigestor := doc.Get("passwordDigestor"); passwordDigestor != nil {
-		must.NoError(updateSpec.Add("passwordDigestor", passwordDigestor))
+	if err = checkPasswordDigestor(doc.Command(), doc); err != nil {
+		return nil, err
 	}
 
 	dbName, err := getRequiredParam[string](doc, "$db")
@@ -83,14 +94,30 @@ func (h *Handler) msgUpdateUser(connCtx context.Context, req *middleware.Request
 		return nil, err
 	}
 
+	// DocumentDB requires at least one field to update
+	onlyRestrictions := updateSpec.Len() == 1 && restrictions != nil
+
 	must.NoError(updateSpec.Add("$db", dbName))
 
-	var res wirebson.RawDocument
+	res := must.NotFail(must.NotFail(wirebson.NewDocument("ok", float64(1))).Encode())
 
 	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
-		// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/859
-		res, err = documentdb_api.UpdateUser(connCtx, conn, h.L, must.NotFail(updateSpec.Encode()))
-		return err
+		if !onlyRestrictions {
+			// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/859
+			err = h.withSCRAMIterations(connCtx, conn, func(conn *pgx.Conn) error {
+				res, err = documentdb_api.UpdateUser(connCtx, conn, h.L, must.NotFail(updateSpec.Encode()))
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if restrictions == nil {
+			return nil
+		}
+
+		return setAuthRestrictions(connCtx, conn, doc.Command(), user, dbName, restrictions)
 	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
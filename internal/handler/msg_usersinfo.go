@@ -23,6 +23,7 @@
 	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
 	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
 	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
 )
 
 // msgUsersInfo implements `usersInfo` command.
@@ -38,15 +39,68 @@ func (h *Handler) msgUsersInfo(connCtx context.Context, req *middleware.Request)
 		return nil, err
 	}
 
+	doc, err := spec.Decode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// authentication restrictions are stored by FerretDB
+	var showRestrictions bool
+
+	if v := doc.Get("showAuthenticationRestrictions"); v != nil {
+		if showRestrictions, err = getBoolParam("showAuthenticationRestrictions", v); err != nil {
+			return nil, err
+		}
+
+		doc.Remove("showAuthenticationRestrictions")
+
+		if spec, err = doc.Encode(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
 	var res wirebson.RawDocument
+	var out *wirebson.Document
 
 	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
-		res, err = documentdb_api.UsersInfo(connCtx, conn, h.L, spec)
-		return err
+		if res, err = documentdb_api.UsersInfo(connCtx, conn, h.L, spec); err != nil || !showRestrictions {
+			return err
+		}
+
+		if out, err = res.DecodeDeep(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		users, _ := out.Get("users").(*wirebson.Array)
+		if users == nil {
+			return nil
+		}
+
+		for v := range users.Values() {
+			user, _ := v.(*wirebson.Document)
+			if user == nil {
+				continue
+			}
+
+			username, _ := user.Get("user").(string)
+
+			var restrictions *wirebson.Array
+			if restrictions, err = authRestrictions(connCtx, conn, username); err != nil {
+				return err
+			}
+
+			must.NoError(user.Add("authenticationRestrictions", restrictions))
+		}
+
+		return nil
 	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	if out != nil {
+		return middleware.ResponseMsg(out)
+	}
+
 	return middleware.ResponseMsg(res)
 }
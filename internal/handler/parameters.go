@@ -0,0 +1,703 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/build/version"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// Default values of server parameters.
+const (
+	defaultCursorTimeout                      = 10 * time.Minute
+	defaultResultCacheTTL                     = time.Minute
+	defaultMaxBlockingSortMemoryUsageBytes    = int64(100 * 1024 * 1024)
+	defaultMaxTransactionLockRequestTimeoutMS = int32(5)
+)
+
+// Bounds of MongoDB major.minor version that could be advertised with `ferretdbMongoDBVersion` parameter.
+var (
+	minAdvertisedMongoDBVersion = [2]int32{5, 0}
+	maxAdvertisedMongoDBVersion = [2]int32{8, 0}
+)
+
+// advertisedMongoDBVersionRe matches `ferretdbMongoDBVersion` parameter values.
+var advertisedMongoDBVersionRe = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})$`)
+
+// parameter represents a server parameter available via `getParameter` and `setParameter` commands.
+type parameter struct {
+	// get returns the current value.
+	get func() any
+
+	// set validates and sets a new value.
+	// It is nil for read-only parameters.
+	set func(v any) error
+
+	settableAtRuntime bool
+	settableAtStartup bool
+}
+
+// parameterValues contains values of server parameters that can be changed.
+type parameterValues struct {
+	quiet                              atomic.Bool
+	enableTestCommands                 atomic.Bool
+	caseInsensitiveIndexes             atomic.Bool
+	concurrentIndexBuilds              atomic.Bool
+	estimatedCount                     atomic.Bool
+	experimentalSharding               atomic.Bool
+	passwordComplexity                 atomic.Bool
+	sharedCursors                      atomic.Bool
+	cursorTimeoutMS                    atomic.Int64
+	healthCheckIntervalMS              atomic.Int64
+	indexAdvisorScanRatio              atomic.Int64
+	indexStatsPersistIntervalMS        atomic.Int64
+	maxBlockingSortMemoryUsageBytes    atomic.Int64
+	maxTransactionLockRequestTimeoutMS atomic.Int32
+	passwordMinLength                  atomic.Int64
+	resultCacheSizeBytes               atomic.Int64
+	resultCacheTTLMS                   atomic.Int64
+	scramSHA256IterationCount          atomic.Int64
+	sessionCleanupIntervalMS           atomic.Int64
+	mongoDBVersion                     atomic.Pointer[[2]int32] // major and minor; nil for the default
+}
+
+// initParameters initializes server parameters for that handler instance.
+func (h *Handler) initParameters() {
+	sessionCleanupInterval := h.SessionCleanupInterval
+	if sessionCleanupInterval == 0 {
+		sessionCleanupInterval = time.Minute
+	}
+
+	h.paramValues.cursorTimeoutMS.Store(defaultCursorTimeout.Milliseconds())
+	h.paramValues.resultCacheTTLMS.Store(defaultResultCacheTTL.Milliseconds())
+	h.paramValues.maxBlockingSortMemoryUsageBytes.Store(defaultMaxBlockingSortMemoryUsageBytes)
+	h.paramValues.maxTransactionLockRequestTimeoutMS.Store(defaultMaxTransactionLockRequestTimeoutMS)
+	h.paramValues.sessionCleanupIntervalMS.Store(sessionCleanupInterval.Milliseconds())
+
+	h.params = map[string]*parameter{
+		// sorted alphabetically
+		"authenticationMechanisms": {
+			get: func() any {
+				return must.NotFail(wirebson.NewArray("SCRAM-SHA-1", "SCRAM-SHA-256"))
+			},
+			settableAtStartup: true,
+		},
+		"authSchemaVersion": {
+			get: func() any {
+				return int32(5)
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"cursorTimeoutMillis": {
+			get: func() any {
+				return h.paramValues.cursorTimeoutMS.Load()
+			},
+			set: func(v any) error {
+				ms, err := parameterInt64("cursorTimeoutMillis", v, 1, math.MaxInt64)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.cursorTimeoutMS.Store(ms)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"enableTestCommands": {
+			// enables commands such as `configureFailPoint`
+			get: func() any {
+				return h.paramValues.enableTestCommands.Load()
+			},
+			set: func(v any) error {
+				b, err := getBoolParam("enableTestCommands", v)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.enableTestCommands.Store(b)
+
+				return nil
+			},
+			settableAtStartup: true,
+		},
+		"featureCompatibilityVersion": {
+			get: func() any {
+				// set by `setFeatureCompatibilityVersion` command
+				return must.NotFail(wirebson.NewDocument("version", fcvString(h.featureCompatibilityVersion())))
+			},
+		},
+		"ferretdbCaseInsensitiveIndexes": {
+			// if set, indexes with case-insensitive collation are backed by PostgreSQL expression indexes
+			// used for case-insensitive equality and prefix lookups
+			get: func() any {
+				return h.paramValues.caseInsensitiveIndexes.Load()
+			},
+			set: func(v any) error {
+				b, err := getBoolParam("ferretdbCaseInsensitiveIndexes", v)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.caseInsensitiveIndexes.Store(b)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbConcurrentIndexBuilds": {
+			// if set, indexes are built with CREATE INDEX CONCURRENTLY that does not block writes
+			get: func() any {
+				return h.paramValues.concurrentIndexBuilds.Load()
+			},
+			set: func(v any) error {
+				b, err := getBoolParam("ferretdbConcurrentIndexBuilds", v)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.concurrentIndexBuilds.Store(b)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbEstimatedCount": {
+			// if set, `count` without query uses PostgreSQL statistics instead of scanning the collection
+			get: func() any {
+				return h.paramValues.estimatedCount.Load()
+			},
+			set: func(v any) error {
+				b, err := getBoolParam("ferretdbEstimatedCount", v)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.estimatedCount.Store(b)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbExperimentalSharding": {
+			// if true, `shardCollection` distributes collections across Citus nodes using DocumentDB
+			get: func() any {
+				return h.paramValues.experimentalSharding.Load()
+			},
+			set: func(v any) error {
+				b, err := getBoolParam("ferretdbExperimentalSharding", v)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.experimentalSharding.Store(b)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbHealthCheckIntervalMillis": {
+			// interval of PostgreSQL primary availability checks used for failover and failback; 0 disables them
+			get: func() any {
+				return h.paramValues.healthCheckIntervalMS.Load()
+			},
+			set: func(v any) error {
+				ms, err := parameterInt64("ferretdbHealthCheckIntervalMillis", v, 0, math.MaxInt64)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.healthCheckIntervalMS.Store(ms)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbIndexAdvisorScanRatio": {
+			// if positive, `find` queries scanning that many times more documents than they return
+			// are reported by `ferretIndexSuggestions` command and logged; 0 disables the analysis
+			get: func() any {
+				return h.paramValues.indexAdvisorScanRatio.Load()
+			},
+			set: func(v any) error {
+				ratio, err := parameterInt64("ferretdbIndexAdvisorScanRatio", v, 0, math.MaxInt64)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.indexAdvisorScanRatio.Store(ratio)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbIndexStatsPersistIntervalMillis": {
+			// interval of `$indexStats` counters persistence; 0 disables it
+			get: func() any {
+				return h.paramValues.indexStatsPersistIntervalMS.Load()
+			},
+			set: func(v any) error {
+				ms, err := parameterInt64("ferretdbIndexStatsPersistIntervalMillis", v, 0, math.MaxInt64)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.indexStatsPersistIntervalMS.Store(ms)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbMongoDBVersion": {
+			// MongoDB major.minor version advertised to clients by `buildInfo` and other commands
+			get: func() any {
+				v := h.mongoDBVersion()
+				return fmt.Sprintf("%d.%d", v[0], v[1])
+			},
+			set: func(v any) error {
+				mm, err := parseAdvertisedMongoDBVersion(v)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.mongoDBVersion.Store(mm)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbPasswordComplexity": {
+			// if true, `createUser` and `updateUser` require passwords with at least three of
+			// lowercase letters, uppercase letters, digits, and other characters
+			get: func() any {
+				return h.paramValues.passwordComplexity.Load()
+			},
+			set: func(v any) error {
+				b, err := getBoolParam("ferretdbPasswordComplexity", v)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.passwordComplexity.Store(b)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbPasswordMinLength": {
+			// minimal length of passwords set by `createUser` and `updateUser`; 0 disables the check
+			get: func() any {
+				return h.paramValues.passwordMinLength.Load()
+			},
+			set: func(v any) error {
+				n, err := parameterInt64("ferretdbPasswordMinLength", v, 0, maxPasswordLength)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.passwordMinLength.Store(n)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbResultCacheSizeBytes": {
+			// maximal size of cached read command results; 0 disables the cache
+			get: func() any {
+				return h.paramValues.resultCacheSizeBytes.Load()
+			},
+			set: func(v any) error {
+				size, err := parameterInt64("ferretdbResultCacheSizeBytes", v, 0, math.MaxInt64)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.resultCacheSizeBytes.Store(size)
+				h.resultCache.invalidateAll()
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbResultCacheTTLMillis": {
+			// maximal lifetime of cached read command results
+			get: func() any {
+				return h.paramValues.resultCacheTTLMS.Load()
+			},
+			set: func(v any) error {
+				ms, err := parameterInt64("ferretdbResultCacheTTLMillis", v, 1, math.MaxInt64)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.resultCacheTTLMS.Store(ms)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbSessionCleanupIntervalMillis": {
+			get: func() any {
+				return h.paramValues.sessionCleanupIntervalMS.Load()
+			},
+			set: func(v any) error {
+				ms, err := parameterInt64("ferretdbSessionCleanupIntervalMillis", v, 1, math.MaxInt64)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.sessionCleanupIntervalMS.Store(ms)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"ferretdbSharedCursors": {
+			// if true, cursors are stored in PostgreSQL, so `getMore` could be handled by any instance;
+			// all instances using the same PostgreSQL should use the same value
+			get: func() any {
+				return h.paramValues.sharedCursors.Load()
+			},
+			set: func(v any) error {
+				b, err := getBoolParam("ferretdbSharedCursors", v)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.sharedCursors.Store(b)
+
+				return nil
+			},
+			settableAtStartup: true,
+		},
+		"internalQueryMaxBlockingSortMemoryUsageBytes": {
+			// accepted for compatibility; sorting memory is managed by PostgreSQL
+			get: func() any {
+				return h.paramValues.maxBlockingSortMemoryUsageBytes.Load()
+			},
+			set: func(v any) error {
+				b, err := parameterInt64("internalQueryMaxBlockingSortMemoryUsageBytes", v, 0, math.MaxInt64)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.maxBlockingSortMemoryUsageBytes.Store(b)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"logLevel": {
+			get: func() any {
+				if h.LogLevel != nil && h.LogLevel.Level() <= slog.LevelDebug {
+					return int32(1)
+				}
+
+				return int32(0)
+			},
+			set: func(v any) error {
+				if h.LogLevel == nil {
+					return mongoerrors.NewWithArgument(
+						mongoerrors.ErrIllegalOperation,
+						"logLevel can't be changed for this instance",
+						"logLevel",
+					)
+				}
+
+				level, err := parameterInt64("logLevel", v, 0, 5)
+				if err != nil {
+					return err
+				}
+
+				// MongoDB verbosity levels 1-5 are all mapped to the debug level
+				if level > 0 {
+					h.LogLevel.Set(slog.LevelDebug)
+				} else {
+					h.LogLevel.Set(slog.LevelInfo)
+				}
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"maxTransactionLockRequestTimeoutMillis": {
+			// accepted for compatibility; transactions are not supported yet
+			get: func() any {
+				return h.paramValues.maxTransactionLockRequestTimeoutMS.Load()
+			},
+			set: func(v any) error {
+				ms, err := parameterInt64("maxTransactionLockRequestTimeoutMillis", v, math.MinInt32, math.MaxInt32)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.maxTransactionLockRequestTimeoutMS.Store(int32(ms))
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"quiet": {
+			get: func() any {
+				return h.paramValues.quiet.Load()
+			},
+			set: func(v any) error {
+				b, err := getBoolParam("quiet", v)
+				if err != nil {
+					return err
+				}
+
+				h.paramValues.quiet.Store(b)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		"scramSHA256IterationCount": {
+			// PBKDF2 iteration count of SCRAM-SHA-256 verifiers of new passwords;
+			// 0 uses the PostgreSQL default (`scram_iterations` setting)
+			get: func() any {
+				return h.paramValues.scramSHA256IterationCount.Load()
+			},
+			set: func(v any) error {
+				n, err := parameterInt64("scramSHA256IterationCount", v, 0, math.MaxInt32)
+				if err != nil {
+					return err
+				}
+
+				if n != 0 && n < minSCRAMIterationCount {
+					return mongoerrors.NewWithArgument(
+						mongoerrors.ErrBadValue,
+						fmt.Sprintf("scramSHA256IterationCount must be 0 or at least %d", minSCRAMIterationCount),
+						"scramSHA256IterationCount",
+					)
+				}
+
+				h.paramValues.scramSHA256IterationCount.Store(n)
+
+				return nil
+			},
+			settableAtRuntime: true,
+			settableAtStartup: true,
+		},
+		// please keep sorted alphabetically
+	}
+}
+
+// setStartupParameters sets server parameters passed at startup.
+func (h *Handler) setStartupParameters(params map[string]string) error {
+	for name, s := range params {
+		p := h.params[name]
+		if p == nil {
+			return fmt.Errorf("unknown server parameter %q", name)
+		}
+
+		if !p.settableAtStartup || p.set == nil {
+			return fmt.Errorf("server parameter %q can't be set at startup", name)
+		}
+
+		if err := p.set(parseParameterValue(s)); err != nil {
+			return fmt.Errorf("invalid value %q for server parameter %q: %w", s, name, err)
+		}
+	}
+
+	return nil
+}
+
+// parseParameterValue converts a string value passed at startup to a BSON value.
+func parseParameterValue(s string) any {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+
+	return s
+}
+
+// parseAdvertisedMongoDBVersion returns major and minor version from `ferretdbMongoDBVersion` parameter value
+// or protocol error for invalid type or a value out of the supported range.
+func parseAdvertisedMongoDBVersion(v any) (*[2]int32, error) {
+	const name = "ferretdbMongoDBVersion"
+
+	var s string
+
+	switch v := v.(type) {
+	case string:
+		s = v
+	case float64:
+		// values like 6.0 passed at startup or by shell
+		s = strconv.FormatFloat(v, 'f', 1, 64)
+	default:
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrTypeMismatch,
+			fmt.Sprintf("Invalid value type %s for parameter %s", aliasFromType(v), name),
+			name,
+		)
+	}
+
+	match := advertisedMongoDBVersionRe.FindStringSubmatch(s)
+	if match == nil {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrBadValue,
+			fmt.Sprintf("Invalid value for parameter %s: %q is not a major.minor version", name, s),
+			name,
+		)
+	}
+
+	major := must.NotFail(strconv.ParseInt(match[1], 10, 32))
+	minor := must.NotFail(strconv.ParseInt(match[2], 10, 32))
+	res := [2]int32{int32(major), int32(minor)}
+
+	if cmpVersions(res, minAdvertisedMongoDBVersion) < 0 || cmpVersions(res, maxAdvertisedMongoDBVersion) > 0 {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrBadValue,
+			fmt.Sprintf(
+				"Invalid value for parameter %s: %s is not in range [%d.%d, %d.%d]",
+				name, s,
+				minAdvertisedMongoDBVersion[0], minAdvertisedMongoDBVersion[1],
+				maxAdvertisedMongoDBVersion[0], maxAdvertisedMongoDBVersion[1],
+			),
+			name,
+		)
+	}
+
+	return &res, nil
+}
+
+// cmpVersions compares major.minor versions.
+func cmpVersions(a, b [2]int32) int {
+	if c := cmp.Compare(a[0], b[0]); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(a[1], b[1])
+}
+
+// mongoDBVersion returns MongoDB version advertised to clients as an array of major, minor, patch, and 0.
+// By default, it is the fake version from the build information.
+func (h *Handler) mongoDBVersion() [4]int32 {
+	res := version.Get().MongoDBVersionArray
+
+	if mm := h.paramValues.mongoDBVersion.Load(); mm != nil {
+		res[0], res[1] = mm[0], mm[1]
+	}
+
+	return res
+}
+
+// mongoDBVersionString returns [Handler.mongoDBVersion] as a string.
+func (h *Handler) mongoDBVersionString() string {
+	v := h.mongoDBVersion()
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// parameterInt64 returns int64 value of the parameter v
+// or protocol error for invalid type or a value out of the given range.
+func parameterInt64(name string, v any, minValue, maxValue int64) (int64, error) {
+	var res int64
+
+	switch v := v.(type) {
+	case int32:
+		res = int64(v)
+	case int64:
+		res = v
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 { // float64(math.MaxInt64) is 2^63
+			return 0, mongoerrors.NewWithArgument(
+				mongoerrors.ErrBadValue,
+				fmt.Sprintf("Invalid value for parameter %s: %v is not an integer", name, v),
+				name,
+			)
+		}
+
+		res = int64(v)
+	default:
+		return 0, mongoerrors.NewWithArgument(
+			mongoerrors.ErrTypeMismatch,
+			fmt.Sprintf("Invalid value type %s for parameter %s", aliasFromType(v), name),
+			name,
+		)
+	}
+
+	if res < minValue || res > maxValue {
+		return 0, mongoerrors.NewWithArgument(
+			mongoerrors.ErrBadValue,
+			fmt.Sprintf("Invalid value for parameter %s: %d is not in range [%d, %d]", name, res, minValue, maxValue),
+			name,
+		)
+	}
+
+	return res, nil
+}
+
+// cursorTimeout returns the current idle cursor timeout.
+func (h *Handler) cursorTimeout() time.Duration {
+	return time.Duration(h.paramValues.cursorTimeoutMS.Load()) * time.Millisecond
+}
+
+// healthCheckInterval returns the current interval of PostgreSQL primary availability checks.
+// Zero means that checks are disabled.
+func (h *Handler) healthCheckInterval() time.Duration {
+	return time.Duration(h.paramValues.healthCheckIntervalMS.Load()) * time.Millisecond
+}
+
+// indexStatsPersistInterval returns the current interval of `$indexStats` counters persistence.
+// Zero means that counters are not persisted.
+func (h *Handler) indexStatsPersistInterval() time.Duration {
+	return time.Duration(h.paramValues.indexStatsPersistIntervalMS.Load()) * time.Millisecond
+}
+
+// sessionCleanupInterval returns the current interval of expired sessions and idle cursors cleanup.
+func (h *Handler) sessionCleanupInterval() time.Duration {
+	return time.Duration(h.paramValues.sessionCleanupIntervalMS.Load()) * time.Millisecond
+}
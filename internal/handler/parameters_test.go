@@ -0,0 +1,99 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartupParameters(t *testing.T) {
+	t.Parallel()
+
+	var level slog.LevelVar
+
+	h := &Handler{NewOpts: &NewOpts{LogLevel: &level}}
+	h.initParameters()
+
+	err := h.setStartupParameters(map[string]string{
+		"cursorTimeoutMillis":    "60000",
+		"ferretdbEstimatedCount": "true",
+		"ferretdbMongoDBVersion": "6.0",
+		"logLevel":               "2",
+		"quiet":                  "true",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Minute, h.cursorTimeout())
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	assert.Equal(t, int32(1), h.params["logLevel"].get())
+	assert.Equal(t, true, h.params["quiet"].get())
+	assert.Equal(t, true, h.params["ferretdbEstimatedCount"].get())
+	assert.Equal(t, "6.0", h.params["ferretdbMongoDBVersion"].get())
+	assert.Equal(t, int32(6), h.mongoDBVersion()[0])
+
+	for name, params := range map[string]map[string]string{
+		"Unknown":   {"nonExistent": "1"},
+		"ReadOnly":  {"featureCompatibilityVersion": "7.0"},
+		"WrongType": {"cursorTimeoutMillis": "abc"},
+		"Range":     {"logLevel": "6"},
+		"Version":   {"ferretdbMongoDBVersion": "9.0"},
+		"Negative":  {"cursorTimeoutMillis": "-1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Error(t, h.setStartupParameters(params))
+		})
+	}
+}
+
+func TestParameterInt64(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		v        any
+		expected int64
+		err      bool
+	}{
+		"Int32":         {v: int32(42), expected: 42},
+		"Int64":         {v: int64(math.MaxInt64), expected: math.MaxInt64},
+		"Float":         {v: float64(42), expected: 42},
+		"FloatMin":      {v: float64(math.MinInt64), expected: math.MinInt64},
+		"FloatFraction": {v: 4.2, err: true},
+		"FloatMax":      {v: 9.223372036854775808e18, err: true},
+		"FloatInf":      {v: math.Inf(1), err: true},
+		"FloatNaN":      {v: math.NaN(), err: true},
+		"String":        {v: "42", err: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := parameterInt64("test", tc.v, math.MinInt64, math.MaxInt64)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
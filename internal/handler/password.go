@@ -0,0 +1,114 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"unicode"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// minSCRAMIterationCount is the minimal non-default value of `scramSHA256IterationCount` parameter,
+// as recommended by RFC 7677.
+const minSCRAMIterationCount = 4096
+
+// maxPasswordLength is the maximal value of `ferretdbPasswordMinLength` parameter.
+const maxPasswordLength = 1024
+
+// checkPassword returns an error if the given password does not satisfy the configured password policy.
+func (h *Handler) checkPassword(command, pwd string) error {
+	if n := h.paramValues.passwordMinLength.Load(); int64(len([]rune(pwd))) < n {
+		msg := fmt.Sprintf("Password must be at least %d characters long", n)
+		return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	if h.paramValues.passwordComplexity.Load() && passwordClasses(pwd) < 3 {
+		msg := "Password must contain at least three of the following: " +
+			"lowercase letters, uppercase letters, digits, and other characters"
+
+		return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+
+	return nil
+}
+
+// passwordClasses returns the number of character classes (lowercase letters, uppercase letters, digits, others)
+// used in the given password.
+func passwordClasses(pwd string) int {
+	var lower, upper, digit, other int
+
+	for _, r := range pwd {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+
+	return lower + upper + digit + other
+}
+
+// checkPasswordDigestor validates and removes `passwordDigestor` field of the given command.
+// Only server-side digestion is supported, as SCRAM-SHA-256 requires undigested passwords.
+func checkPasswordDigestor(command string, doc *wirebson.Document) error {
+	v := doc.Get("passwordDigestor")
+	if v == nil {
+		return nil
+	}
+
+	switch v {
+	case "server":
+		doc.Remove("passwordDigestor")
+		return nil
+
+	case "client":
+		msg := "Use of SCRAM-SHA-256 requires undigested passwords"
+		return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+
+	default:
+		msg := fmt.Sprintf(`passwordDigestor must be either "server" or "client", not %v`, v)
+		return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, command)
+	}
+}
+
+// withSCRAMIterations calls the given function that sets passwords
+// with the configured `scramSHA256IterationCount`.
+// If it is set, the function is called in a transaction.
+func (h *Handler) withSCRAMIterations(ctx context.Context, conn *pgx.Conn, f func(*pgx.Conn) error) error {
+	n := h.paramValues.scramSHA256IterationCount.Load()
+	if n == 0 {
+		return f(conn)
+	}
+
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT set_config('scram_iterations', $1, true)`, strconv.FormatInt(n, 10)); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return f(tx.Conn())
+	})
+}
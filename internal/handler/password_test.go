@@ -0,0 +1,56 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordClasses(t *testing.T) {
+	t.Parallel()
+
+	for pwd, expected := range map[string]int{
+		"":            0,
+		"password":    1,
+		"Password":    2,
+		"Passw0rd":    3,
+		"Passw0rd!":   4,
+		"пароль123":   2,
+		"PAROL_12345": 3,
+	} {
+		assert.Equal(t, expected, passwordClasses(pwd), "%q", pwd)
+	}
+}
+
+func TestCheckPasswordDigestor(t *testing.T) {
+	t.Parallel()
+
+	doc := wirebson.MustDocument("createUser", "user", "passwordDigestor", "server")
+	require.NoError(t, checkPasswordDigestor("createUser", doc))
+	assert.Nil(t, doc.Get("passwordDigestor"))
+
+	doc = wirebson.MustDocument("createUser", "user", "passwordDigestor", "client")
+	assert.Error(t, checkPasswordDigestor("createUser", doc))
+
+	doc = wirebson.MustDocument("createUser", "user", "passwordDigestor", int32(1))
+	assert.Error(t, checkPasswordDigestor("createUser", doc))
+
+	doc = wirebson.MustDocument("createUser", "user")
+	assert.NoError(t, checkPasswordDigestor("createUser", doc))
+}
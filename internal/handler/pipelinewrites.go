@@ -0,0 +1,167 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// pipelineOutput returns the database and collection written by `$out` or `$merge` stage
+// at the end of the given pipeline.
+// It returns false if the pipeline does not end with such stage.
+//
+// Both stages accept a collection name or `{db, coll}` document;
+// for the name, the given database is returned.
+func pipelineOutput(dbName string, pipeline *wirebson.Array) (string, string, bool, error) {
+	if pipeline == nil || pipeline.Len() == 0 {
+		return "", "", false, nil
+	}
+
+	last, err := decodeDocument(pipeline.Get(pipeline.Len() - 1))
+	if err != nil {
+		return "", "", false, lazyerrors.Error(err)
+	}
+
+	if last == nil || last.Len() != 1 {
+		return "", "", false, nil
+	}
+
+	var target any
+
+	switch last.Command() {
+	case "$out":
+		target = last.Get("$out")
+
+	case "$merge":
+		target = last.Get("$merge")
+
+		merge, err := decodeDocument(target)
+		if err != nil {
+			return "", "", false, lazyerrors.Error(err)
+		}
+
+		if merge != nil {
+			target = merge.Get("into")
+		}
+
+	default:
+		return "", "", false, nil
+	}
+
+	if collection, ok := target.(string); ok {
+		return dbName, collection, true, nil
+	}
+
+	ns, err := decodeDocument(target)
+	if err != nil {
+		return "", "", false, lazyerrors.Error(err)
+	}
+
+	if ns == nil {
+		return dbName, "", true, nil
+	}
+
+	if db, _ := ns.Get("db").(string); db != "" {
+		dbName = db
+	}
+
+	collection, _ := ns.Get("coll").(string)
+
+	return dbName, collection, true, nil
+}
+
+// writingPipelines is a middleware that wraps `aggregate` command handler
+// so that pipelines with `$out` or `$merge` stage are handled like write commands:
+// their target namespace is checked, they wait for the fsync lock release,
+// and cached results of the target database are invalidated.
+//
+// Pipelines with [materializeStage] could write intermediate collections, so they wait too.
+func (h *Handler) writingPipelines(next middleware.HandleFunc) middleware.HandleFunc {
+	write := blockedByFsyncLock(next, &h.fsync)
+
+	return func(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+		if req.OpMsg == nil {
+			return next(ctx, req)
+		}
+
+		doc, err := req.OpMsg.Document()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		pipeline, err := decodeArray(doc.Get("pipeline"))
+		if err != nil {
+			return nil, err
+		}
+
+		dbName, _ := doc.Get("$db").(string)
+
+		outDB, outCollection, output, err := pipelineOutput(dbName, pipeline)
+		if err != nil {
+			return nil, err
+		}
+
+		if output {
+			if err = protectedNamespace(doc.Command(), outDB, outCollection); err != nil {
+				return nil, err
+			}
+		}
+
+		materialize, err := hasStage(pipeline, materializeStage)
+		if err != nil {
+			return nil, err
+		}
+
+		if !output && !materialize {
+			return next(ctx, req)
+		}
+
+		if !output {
+			return write(ctx, req)
+		}
+
+		res, err := write(ctx, req)
+
+		// invalidate even on errors, as some documents could be written
+		h.invalidateResultCache(ctx, outDB)
+
+		return res, err
+	}
+}
+
+// hasStage returns true if the given pipeline contains a stage with the given name at the top level.
+func hasStage(pipeline *wirebson.Array, name string) (bool, error) {
+	if pipeline == nil {
+		return false, nil
+	}
+
+	for v := range pipeline.Values() {
+		stage, err := decodeDocument(v)
+		if err != nil {
+			return false, lazyerrors.Error(err)
+		}
+
+		if stage != nil && stage.Command() == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestPipelineOutput(t *testing.T) {
+	t.Parallel()
+
+	match := must.NotFail(wirebson.NewDocument("$match", wirebson.MakeDocument(0)))
+
+	for name, tc := range map[string]struct {
+		stage      *wirebson.Document // nil for no last stage
+		db         string
+		collection string
+		ok         bool
+	}{
+		"OutString": {
+			stage:      must.NotFail(wirebson.NewDocument("$out", "out")),
+			db:         "db",
+			collection: "out",
+			ok:         true,
+		},
+		"OutDB": {
+			stage: must.NotFail(wirebson.NewDocument(
+				"$out", must.NotFail(wirebson.NewDocument("db", "other", "coll", "out")),
+			)),
+			db:         "other",
+			collection: "out",
+			ok:         true,
+		},
+		"MergeString": {
+			stage:      must.NotFail(wirebson.NewDocument("$merge", "out")),
+			db:         "db",
+			collection: "out",
+			ok:         true,
+		},
+		"MergeInto": {
+			stage: must.NotFail(wirebson.NewDocument(
+				"$merge", must.NotFail(wirebson.NewDocument("into", "out", "whenMatched", "replace")),
+			)),
+			db:         "db",
+			collection: "out",
+			ok:         true,
+		},
+		"MergeIntoDB": {
+			stage: must.NotFail(wirebson.NewDocument(
+				"$merge", must.NotFail(wirebson.NewDocument(
+					"into", must.NotFail(wirebson.NewDocument("db", "config", "coll", "chunks")),
+				)),
+			)),
+			db:         "config",
+			collection: "chunks",
+			ok:         true,
+		},
+		"Match": {
+			stage: match,
+		},
+		"Empty": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := wirebson.MakeArray(2)
+			if tc.stage != nil {
+				must.NoError(pipeline.Add(match))
+				must.NoError(pipeline.Add(tc.stage))
+			}
+
+			db, collection, ok, err := pipelineOutput("db", pipeline)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.db, db)
+			assert.Equal(t, tc.collection, collection)
+		})
+	}
+}
+
+func TestHasStage(t *testing.T) {
+	t.Parallel()
+
+	pipeline := must.NotFail(wirebson.NewArray(
+		must.NotFail(wirebson.NewDocument("$match", wirebson.MakeDocument(0))),
+		must.NotFail(wirebson.NewDocument(materializeStage, wirebson.MakeDocument(0))),
+	))
+
+	raw, err := pipeline.Encode()
+	require.NoError(t, err)
+
+	arr, err := decodeArray(raw)
+	require.NoError(t, err)
+
+	ok, err := hasStage(arr, materializeStage)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasStage(arr, "$out")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = hasStage(nil, "$out")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+)
+
+// validateProjection returns a protocol error if the given projection has invalid field paths,
+// misplaced positional operators, or mixes inclusions and exclusions.
+// Projection operators and their values are validated by DocumentDB.
+//
+// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/241
+func validateProjection(projection any) error {
+	doc, err := decodeDocument(projection)
+	if doc == nil || err != nil {
+		return err
+	}
+
+	// true for inclusion, false for exclusion, nil while unknown
+	var inclusion *bool
+
+	for key, v := range doc.All() {
+		path := key
+		positional := false
+
+		switch {
+		case strings.Contains(key, ".$."):
+			return mongoerrors.NewWithArgument(
+				mongoerrors.ErrLocation31394,
+				"As of 4.4, it's illegal to specify positional operator in the middle of a path."+
+					"Positional projection may only be used at the end, for example: a.b.$. "+
+					"If the query previously used a form like a.b.$.d, remove the parts following the '$' "+
+					"and the results will be equivalent.",
+				"projection",
+			)
+
+		case strings.HasSuffix(key, ".$"):
+			path = strings.TrimSuffix(key, ".$")
+			positional = true
+		}
+
+		if err = validateFieldPath(path, "projection"); err != nil {
+			return err
+		}
+
+		if key == "_id" {
+			continue
+		}
+
+		var include bool
+
+		switch v := v.(type) {
+		case bool:
+			include = v
+		case int32:
+			include = v != 0
+		case int64:
+			include = v != 0
+		case float64:
+			include = v != 0
+		default:
+			// projection operators and expressions are handled by DocumentDB
+			continue
+		}
+
+		if positional && !include {
+			return mongoerrors.NewWithArgument(
+				mongoerrors.ErrLocation31395,
+				"positional projection cannot be used with exclusion",
+				"projection",
+			)
+		}
+
+		switch {
+		case inclusion == nil:
+			inclusion = &include
+		case *inclusion && !include:
+			msg := fmt.Sprintf("Cannot do exclusion on field %s in inclusion projection", key)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrLocation31254, msg, "projection")
+		case !*inclusion && include:
+			msg := fmt.Sprintf("Cannot do inclusion on field %s in exclusion projection", key)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrLocation31253, msg, "projection")
+		}
+	}
+
+	return nil
+}
+
+// validateFieldPath returns a protocol error if the given dot notation path is not a valid field path.
+// The argument is used for error reporting.
+func validateFieldPath(path, argument string) error {
+	if path == "" {
+		return mongoerrors.NewWithArgument(
+			mongoerrors.ErrLocation40352,
+			"FieldPath cannot be constructed with empty string",
+			argument,
+		)
+	}
+
+	if strings.HasSuffix(path, ".") {
+		return mongoerrors.NewWithArgument(
+			mongoerrors.ErrLocation40353,
+			"FieldPath must not end with a '.'.",
+			argument,
+		)
+	}
+
+	for part := range strings.SplitSeq(path, ".") {
+		if part == "" {
+			return mongoerrors.NewWithArgument(
+				mongoerrors.ErrLocation15998,
+				"FieldPath field names may not be empty strings.",
+				argument,
+			)
+		}
+
+		if strings.HasPrefix(part, "$") {
+			return mongoerrors.NewWithArgument(
+				mongoerrors.ErrLocation16410,
+				"FieldPath field names may not start with '$'. Consider using $getField or $setField.",
+				argument,
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/FerretDB/wire"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+)
+
+// shadowQueueSize is the maximum number of requests waiting to be shadowed for a single connection.
+const shadowQueueSize = 100
+
+// Results of shadowed requests passed to [ShadowOpts.Result].
+const (
+	ShadowSent    = "sent"
+	ShadowFailed  = "failed"
+	ShadowDropped = "dropped"
+)
+
+// shadowRequest represents a copy of the request to be shadowed.
+type shadowRequest struct {
+	command string
+	header  wire.MsgHeader
+	body    wire.MsgBody
+}
+
+// Shadow asynchronously duplicates requests to another wire protocol compatible service,
+// discarding responses.
+//
+// It never blocks or affects the client:
+// requests are dropped if the service is too slow,
+// and the connection is re-established after errors.
+type Shadow struct {
+	*ShadowOpts
+	reqs chan *shadowRequest
+}
+
+// ShadowOpts represents shadow options.
+type ShadowOpts struct {
+	Addr        string
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
+
+	L *slog.Logger
+
+	// Result is called for each request with the command name and one of ShadowXXX results.
+	// If nil, results are not reported.
+	Result func(command, result string)
+}
+
+// NewShadow creates a new Shadow.
+// The connection is established lazily by [Shadow.Run].
+func NewShadow(opts *ShadowOpts) *Shadow {
+	return &Shadow{
+		ShadowOpts: opts,
+		reqs:       make(chan *shadowRequest, shadowQueueSize),
+	}
+}
+
+// Send schedules a copy of the request to be sent without waiting.
+// The passed body could be modified after that call.
+func (s *Shadow) Send(ctx context.Context, command string, header *wire.MsgHeader, body wire.MsgBody) {
+	req, err := copyRequest(command, header, body)
+	if err != nil {
+		s.L.DebugContext(ctx, "Failed to copy request for shadowing", logging.Error(err))
+		s.result(command, ShadowFailed)
+
+		return
+	}
+
+	select {
+	case s.reqs <- req:
+	default:
+		s.result(command, ShadowDropped)
+	}
+}
+
+// Run sends scheduled requests until ctx is canceled.
+//
+// When this method returns, shadow is stopped.
+func (s *Shadow) Run(ctx context.Context) {
+	var h *Handler
+
+	defer func() {
+		if h != nil {
+			_ = h.conn.Close()
+		}
+	}()
+
+	for {
+		var req *shadowRequest
+
+		select {
+		case <-ctx.Done():
+			return
+		case req = <-s.reqs:
+		}
+
+		if h == nil {
+			var err error
+			if h, err = New(s.Addr, s.TLSCertFile, s.TLSKeyFile, s.TLSCAFile); err != nil {
+				s.L.DebugContext(ctx, "Failed to connect for shadowing", logging.Error(err))
+				s.result(req.command, ShadowFailed)
+
+				continue
+			}
+		}
+
+		if _, err := h.Handle(ctx, middleware.RequestWire(&req.header, req.body)); err != nil {
+			s.L.DebugContext(ctx, "Failed to shadow request", logging.Error(err))
+			s.result(req.command, ShadowFailed)
+
+			// the connection state is unknown; reconnect for the next request
+			_ = h.conn.Close()
+			h = nil
+
+			continue
+		}
+
+		s.result(req.command, ShadowSent)
+	}
+}
+
+// result reports the result of the shadowed request.
+func (s *Shadow) result(command, result string) {
+	if s.Result != nil {
+		s.Result(command, result)
+	}
+}
+
+// copyRequest returns a deep copy of OP_MSG or OP_QUERY request.
+func copyRequest(command string, header *wire.MsgHeader, body wire.MsgBody) (*shadowRequest, error) {
+	b, err := body.MarshalBinary()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var c wire.MsgBody
+
+	switch body.(type) {
+	case *wire.OpMsg:
+		var msg wire.OpMsg
+		err = msg.UnmarshalBinaryNocopy(b)
+		c = &msg
+
+	case *wire.OpQuery:
+		var query wire.OpQuery
+		err = query.UnmarshalBinaryNocopy(b)
+		c = &query
+
+	default:
+		return nil, lazyerrors.Errorf("unexpected request body type %T", body)
+	}
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &shadowRequest{
+		command: command,
+		header:  *header,
+		body:    c,
+	}, nil
+}
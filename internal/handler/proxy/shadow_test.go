@@ -0,0 +1,91 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"bufio"
+	"net"
+	"testing"
+
+	"github.com/FerretDB/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/util/testutil"
+)
+
+func TestShadow(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = ln.Close() })
+
+	received := make(chan string, 1)
+
+	go func() {
+		conn, e := ln.Accept()
+		if e != nil {
+			return
+		}
+
+		defer conn.Close() //nolint:errcheck // that's fine for test
+
+		bufr := bufio.NewReader(conn)
+		bufw := bufio.NewWriter(conn)
+
+		header, body, e := wire.ReadMessage(bufr)
+		if e != nil {
+			return
+		}
+
+		doc, e := body.(*wire.OpMsg).Section0()
+		if e != nil {
+			return
+		}
+
+		received <- doc.Command()
+
+		res := middleware.RequestWire(nil, wire.MustOpMsg("ok", float64(1))).WireHeader()
+		res.ResponseTo = header.RequestID
+
+		_ = wire.WriteMessage(bufw, res, wire.MustOpMsg("ok", float64(1)))
+		_ = bufw.Flush()
+	}()
+
+	results := make(chan string, 1)
+
+	s := NewShadow(&ShadowOpts{
+		Addr: ln.Addr().String(),
+		L:    testutil.Logger(t),
+		Result: func(command, result string) {
+			results <- command + ":" + result
+		},
+	})
+
+	ctx := testutil.Ctx(t)
+
+	go s.Run(ctx)
+
+	msg := wire.MustOpMsg("insert", "values", "$db", "test")
+	header := middleware.RequestWire(nil, msg).WireHeader()
+
+	s.Send(ctx, "insert", header, msg)
+
+	assert.Equal(t, "insert", <-received)
+	assert.Equal(t, "insert:"+ShadowSent, <-results)
+}
@@ -0,0 +1,281 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// Query strategies chosen by the cost model.
+const (
+	// strategyDocumentDB pushes the whole query down to DocumentDB.
+	strategyDocumentDB = "documentdb"
+
+	// strategyIndexScan reads the case-insensitive expression index directly.
+	strategyIndexScan = "caseInsensitiveIndex"
+
+	// strategyHandlerFilter reads all documents of a small collection and filters them in FerretDB.
+	strategyHandlerFilter = "handlerFilter"
+)
+
+// Query cost model parameters, in units of reading one document sequentially.
+const (
+	costQuery          = 50.0 // a single SQL query round trip
+	costDocumentDB     = 20.0 // additional DocumentDB query planning and cursor setup
+	costCollationRow   = 2.0  // comparing one document with collation in DocumentDB
+	costIndexRow       = 4.0  // random read of one document found by the index
+	costTransferRow    = 1.5  // sending one document to FerretDB and decoding it there
+	handlerFilterLimit = 1000 // the maximal collection size for in-handler filtering
+
+	// default selectivity of equality and prefix conditions on fields without statistics
+	defaultEqualitySelectivity = 0.005
+	defaultPrefixSelectivity   = 0.05
+
+	// prefix conditions are assumed to match that many distinct values
+	prefixDistinctValues = 10
+)
+
+// queryCostTTL is the time query cost model inputs of collections are cached for.
+const queryCostTTL = 10 * time.Second
+
+// maxQueryCostEntries is the maximal number of collections with cached query cost model inputs.
+const maxQueryCostEntries = 10000
+
+// queryCostInputs represents query cost model inputs of a single collection.
+type queryCostInputs struct {
+	expires       time.Time
+	cardinalities map[string]float64 // see [Handler.fieldCardinalities]
+	indexed       []string           // fields with case-insensitive expression indexes
+	count         int64              // estimated number of documents, negative if unknown
+}
+
+// queryCostCache caches query cost model inputs of collections,
+// so queries do not need additional SQL queries to choose the strategy.
+//
+// The zero value is ready to use.
+type queryCostCache struct {
+	mu      sync.Mutex
+	entries map[string]*queryCostInputs // by namespace
+}
+
+// get returns cached inputs for the given namespace.
+func (c *queryCostCache) get(ns string, now time.Time) (*queryCostInputs, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[ns]
+	if !ok || now.After(e.expires) {
+		return nil, false
+	}
+
+	return e, true
+}
+
+// set caches inputs for the given namespace.
+func (c *queryCostCache) set(ns string, e *queryCostInputs, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil || len(c.entries) >= maxQueryCostEntries {
+		c.entries = make(map[string]*queryCostInputs)
+	}
+
+	e.expires = now.Add(queryCostTTL)
+	c.entries[ns] = e
+}
+
+// invalidate removes cached inputs for the given namespace.
+func (c *queryCostCache) invalidate(ns string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, ns)
+}
+
+// findPlan represents the strategy chosen by the cost model for a single query.
+type findPlan struct {
+	strategy string
+
+	// estimates are set only if collection statistics are available
+	statistics     bool
+	collectionDocs int64
+	estimatedDocs  float64
+	costs          map[string]float64
+}
+
+// document returns the plan representation for `explain` output.
+func (p *findPlan) document() *wirebson.Document {
+	res := must.NotFail(wirebson.NewDocument("strategy", p.strategy))
+
+	if !p.statistics {
+		return res
+	}
+
+	must.NoError(res.Add("collectionDocuments", p.collectionDocs))
+	must.NoError(res.Add("estimatedDocuments", math.Round(p.estimatedDocs)))
+
+	costs := wirebson.MakeDocument(len(p.costs))
+
+	for _, s := range []string{strategyDocumentDB, strategyIndexScan, strategyHandlerFilter} {
+		if c, ok := p.costs[s]; ok {
+			must.NoError(costs.Add(s, math.Round(c)))
+		}
+	}
+
+	must.NoError(res.Add("costs", costs))
+
+	return res
+}
+
+// planCaseInsensitive chooses the strategy for the given case-insensitive query
+// that returns at most n documents in the first batch, with more documents requiring a cursor.
+//
+// The indexed parameter is true if the case-insensitive expression index exists.
+// The count is the estimated number of documents in the collection, negative if unknown,
+// and distinct is the estimated number of distinct values of the queried field, zero if unknown.
+//
+// Strategies other than DocumentDB return only the first batch;
+// if the query returns more documents, the work is wasted, and DocumentDB is queried anyway.
+func planCaseInsensitive(q *caseInsensitiveQuery, n int64, cursor, indexed bool, count int64, distinct float64) *findPlan {
+	if count < 0 {
+		// without statistics, use the index if it exists
+		if indexed {
+			return &findPlan{strategy: strategyIndexScan}
+		}
+
+		return &findPlan{strategy: strategyDocumentDB}
+	}
+
+	docs := float64(count)
+
+	var selectivity float64
+
+	switch {
+	case distinct > 0 && q.prefix:
+		selectivity = min(prefixDistinctValues/distinct, 1)
+	case distinct > 0:
+		selectivity = 1 / distinct
+	case q.prefix:
+		selectivity = defaultPrefixSelectivity
+	default:
+		selectivity = defaultEqualitySelectivity
+	}
+
+	matched := docs * selectivity
+	returned := min(matched, float64(n))
+
+	plan := &findPlan{
+		strategy:       strategyDocumentDB,
+		statistics:     true,
+		collectionDocs: count,
+		estimatedDocs:  matched,
+		costs: map[string]float64{
+			strategyDocumentDB: costQuery + costDocumentDB + docs*costCollationRow + returned*costTransferRow,
+		},
+	}
+
+	// the cost of falling back to DocumentDB when results do not fit into the first batch
+	var fallback float64
+	if cursor && matched > float64(n) {
+		fallback = plan.costs[strategyDocumentDB]
+	}
+
+	if indexed {
+		plan.costs[strategyIndexScan] = costQuery + returned*(costIndexRow+costTransferRow) + fallback
+	}
+
+	if count < handlerFilterLimit {
+		plan.costs[strategyHandlerFilter] = costQuery + docs*costTransferRow + fallback
+	}
+
+	for _, s := range []string{strategyIndexScan, strategyHandlerFilter} {
+		if c, ok := plan.costs[s]; ok && c < plan.costs[plan.strategy] {
+			plan.strategy = s
+		}
+	}
+
+	return plan
+}
+
+// caseInsensitivePlan returns the plan for the given case-insensitive query on the collection.
+func (h *Handler) caseInsensitivePlan(ctx context.Context, dbName, collection string, q *caseInsensitiveQuery, n int64, cursor bool) (*findPlan, error) { //nolint:lll // for readability
+	in, err := h.queryCostInputs(ctx, dbName, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	indexed := slices.Contains(in.indexed, q.field)
+
+	return planCaseInsensitive(q, n, cursor, indexed, in.count, in.cardinalities[q.field]), nil
+}
+
+// queryCostInputs returns query cost model inputs of the given collection, using cached ones if possible.
+func (h *Handler) queryCostInputs(ctx context.Context, dbName, collection string) (*queryCostInputs, error) {
+	ns := dbName + "." + collection
+	now := time.Now()
+
+	if in, ok := h.queryCosts.get(ns, now); ok {
+		return in, nil
+	}
+
+	cardinalities, err := h.fieldCardinalities(ctx, dbName, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	in := &queryCostInputs{
+		cardinalities: cardinalities,
+		count:         -1,
+	}
+
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		fields, err := documentdb.CaseInsensitiveIndexes(ctx, conn, dbName, collection)
+		if err != nil {
+			// the collection does not exist or is a view; DocumentDB handles that
+			return nil
+		}
+
+		in.indexed = fields
+
+		c, ok, err := documentdb.CollectionEstimatedCount(ctx, conn, dbName, collection)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if ok {
+			in.count = c
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	h.queryCosts.set(ns, in, now)
+
+	return in, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	eq := &caseInsensitiveQuery{field: "email", value: "foo@example.com"}
+	prefix := &caseInsensitiveQuery{field: "email", value: "foo", prefix: true}
+
+	for name, tc := range map[string]struct {
+		q        *caseInsensitiveQuery
+		cursor   bool
+		indexed  bool
+		count    int64
+		distinct float64
+		expected string
+	}{
+		"NoStatistics": {
+			q:        eq,
+			indexed:  true,
+			count:    -1,
+			expected: strategyIndexScan,
+		},
+		"NoStatisticsNoIndex": {
+			q:        eq,
+			count:    -1,
+			expected: strategyDocumentDB,
+		},
+		"SmallCollection": {
+			q:        eq,
+			indexed:  true,
+			count:    10,
+			expected: strategyIndexScan,
+		},
+		"SmallCollectionNoIndex": {
+			q:        eq,
+			count:    500,
+			expected: strategyHandlerFilter,
+		},
+		"Selective": {
+			q:        eq,
+			cursor:   true,
+			indexed:  true,
+			count:    1_000_000,
+			distinct: 1_000_000,
+			expected: strategyIndexScan,
+		},
+		"NotSelective": {
+			q:        eq,
+			cursor:   true,
+			indexed:  true,
+			count:    1_000_000,
+			distinct: 2,
+			expected: strategyDocumentDB,
+		},
+		"NotSelectiveSingleBatch": {
+			q:        eq,
+			indexed:  true,
+			count:    1_000_000,
+			distinct: 2,
+			expected: strategyIndexScan,
+		},
+		"Prefix": {
+			q:        prefix,
+			cursor:   true,
+			indexed:  true,
+			count:    1_000_000,
+			distinct: 100_000,
+			expected: strategyIndexScan,
+		},
+		"PrefixNoStatistics": {
+			q:        prefix,
+			cursor:   true,
+			indexed:  true,
+			count:    1_000_000,
+			expected: strategyDocumentDB,
+		},
+		"LargeCollectionNoIndex": {
+			q:        eq,
+			count:    1_000_000,
+			distinct: 1_000_000,
+			expected: strategyDocumentDB,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			plan := planCaseInsensitive(tc.q, defaultBatchSize, tc.cursor, tc.indexed, tc.count, tc.distinct)
+			assert.Equal(t, tc.expected, plan.strategy, "%+v", plan)
+		})
+	}
+}
+
+func TestQueryCostCache(t *testing.T) {
+	t.Parallel()
+
+	var c queryCostCache
+
+	now := time.Now()
+
+	_, ok := c.get("db.c", now)
+	assert.False(t, ok)
+
+	c.set("db.c", &queryCostInputs{indexed: []string{"email"}, count: 10}, now)
+
+	in, ok := c.get("db.c", now.Add(queryCostTTL/2))
+	require.True(t, ok)
+	assert.Equal(t, []string{"email"}, in.indexed)
+	assert.Equal(t, int64(10), in.count)
+
+	_, ok = c.get("db.c", now.Add(2*queryCostTTL))
+	assert.False(t, ok)
+
+	c.invalidate("db.c")
+
+	_, ok = c.get("db.c", now)
+	assert.False(t, ok)
+}
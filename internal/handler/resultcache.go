@@ -0,0 +1,389 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/FerretDB/FerretDB/v2/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// resultCacheBypass is the marker in the `comment` field that disables the result cache for the command.
+const resultCacheBypass = "ferretdb:nocache"
+
+// resultCacheVolatileFields contains command fields that do not affect the result,
+// so they are not a part of the cache key.
+var resultCacheVolatileFields = map[string]struct{}{
+	"lsid":                 {},
+	"$clusterTime":         {},
+	"$readPreference":      {},
+	"maxTimeMS":            {},
+	"comment":              {},
+	"apiVersion":           {},
+	"apiStrict":            {},
+	"apiDeprecationErrors": {},
+	"allowDiskUse":         {},
+	"noCursorTimeout":      {},
+}
+
+// resultCacheNonDeterministic contains operators and variables that make results
+// depend on something other than the data, so commands using them are not cached.
+var resultCacheNonDeterministic = [][]byte{
+	[]byte("$$NOW"),
+	[]byte("$$CLUSTER_TIME"),
+	[]byte("$rand"),
+	[]byte("$sample"),
+	[]byte("$currentOp"),
+	[]byte("$listSessions"),
+	[]byte("$listLocalSessions"),
+	[]byte("$collStats"),
+	[]byte("$indexStats"),
+	[]byte("$changeStream"),
+	[]byte("$out"),
+	[]byte("$merge"),
+	[]byte("$function"),
+	[]byte("$accumulator"),
+}
+
+// resultCacheEntry represents a single cached response.
+type resultCacheEntry struct {
+	key     string
+	db      string
+	res     wirebson.RawDocument
+	expires time.Time
+}
+
+// resultCache contains responses of read commands.
+//
+// Entries are invalidated per database, so results of views and `$lookup` stages are invalidated too.
+type resultCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element // by key; values are *resultCacheEntry
+	lru     *list.List               // most recently used entries first
+	size    int64
+
+	// gens contains invalidation generations by database.
+	// A result is not stored if the generation changed while the command was running,
+	// as it could be stale.
+	gens map[string]uint64
+	gen  uint64 // generation for all databases
+
+	hits   prometheus.Counter
+	misses prometheus.Counter
+}
+
+// newResultCache creates a new empty result cache.
+func newResultCache() *resultCache {
+	return &resultCache{
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+		gens:    map[string]uint64{},
+
+		hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "ferretdb",
+			Subsystem: "result_cache",
+			Name:      "hits_total",
+			Help:      "Total number of read commands answered from the result cache.",
+		}),
+		misses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "ferretdb",
+			Subsystem: "result_cache",
+			Name:      "misses_total",
+			Help:      "Total number of cacheable read commands not found in the result cache.",
+		}),
+	}
+}
+
+// generation returns the current invalidation generation of the given database.
+func (c *resultCache) generation(db string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.gen + c.gens[db]
+}
+
+// get returns the cached response for the given key, if any.
+func (c *resultCache) get(key string, now time.Time) (wirebson.RawDocument, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el := c.entries[key]
+	if el == nil {
+		return nil, false
+	}
+
+	e := el.Value.(*resultCacheEntry)
+	if now.After(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+
+	return e.res, true
+}
+
+// put stores the response for the given key if the database generation is still the same,
+// evicting least recently used entries to fit into maxSize bytes.
+func (c *resultCache) put(e *resultCacheEntry, gen uint64, maxSize int64) {
+	if int64(len(e.res)) > maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen+c.gens[e.db] != gen {
+		return
+	}
+
+	if el := c.entries[e.key]; el != nil {
+		c.remove(el)
+	}
+
+	c.entries[e.key] = c.lru.PushFront(e)
+	c.size += int64(len(e.res))
+
+	for c.size > maxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove removes the given element.
+// The caller should hold the lock.
+func (c *resultCache) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*resultCacheEntry)
+	delete(c.entries, e.key)
+	c.size -= int64(len(e.res))
+}
+
+// invalidate removes all entries of the given databases.
+func (c *resultCache) invalidate(dbs ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, db := range dbs {
+		c.gens[db]++
+	}
+
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+
+		for _, db := range dbs {
+			if el.Value.(*resultCacheEntry).db == db {
+				c.remove(el)
+				break
+			}
+		}
+
+		el = next
+	}
+}
+
+// invalidateAll removes all entries.
+func (c *resultCache) invalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+	c.size = 0
+}
+
+// Describe implements [prometheus.Collector].
+func (c *resultCache) Describe(ch chan<- *prometheus.Desc) {
+	c.hits.Describe(ch)
+	c.misses.Describe(ch)
+}
+
+// Collect implements [prometheus.Collector].
+func (c *resultCache) Collect(ch chan<- prometheus.Metric) {
+	c.hits.Collect(ch)
+	c.misses.Collect(ch)
+}
+
+// resultCacheKey returns the cache key and the database of the given read command,
+// or false if the command should not be cached.
+//
+// The key contains the user name, as results could depend on access policies.
+func resultCacheKey(username string, doc *wirebson.Document) (string, string, bool) {
+	if doc.Get("txnNumber") != nil {
+		return "", "", false
+	}
+
+	if comment, _ := doc.Get("comment").(string); strings.Contains(comment, resultCacheBypass) {
+		return "", "", false
+	}
+
+	db, _ := doc.Get("$db").(string)
+	if db == "" {
+		return "", "", false
+	}
+
+	key := wirebson.MakeDocument(doc.Len())
+
+	for k, v := range doc.All() {
+		if _, ok := resultCacheVolatileFields[k]; ok {
+			continue
+		}
+
+		if err := key.Add(k, v); err != nil {
+			return "", "", false
+		}
+	}
+
+	raw, err := key.Encode()
+	if err != nil {
+		return "", "", false
+	}
+
+	for _, b := range resultCacheNonDeterministic {
+		if bytes.Contains(raw, b) {
+			return "", "", false
+		}
+	}
+
+	return username + "\x00" + string(raw), db, true
+}
+
+// resultCacheable returns true if the response could be cached:
+// it does not have an open cursor that the client would continue with `getMore`.
+func resultCacheable(res wirebson.RawDocument) bool {
+	doc, err := res.Decode()
+	if err != nil {
+		return false
+	}
+
+	cursor, _ := doc.Get("cursor").(wirebson.AnyDocument)
+	if cursor == nil {
+		return true
+	}
+
+	c, err := cursor.Decode()
+	if err != nil {
+		return false
+	}
+
+	id, _ := c.Get("id").(int64)
+
+	return id == 0
+}
+
+// withResultCache wraps the read command handler with the result cache.
+// It does nothing if the cache is disabled.
+func (h *Handler) withResultCache(next middleware.HandleFunc) middleware.HandleFunc {
+	return func(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+		maxSize := h.paramValues.resultCacheSizeBytes.Load()
+		if maxSize == 0 || req.OpMsg == nil {
+			return next(ctx, req)
+		}
+
+		doc, err := req.OpMsg.Section0()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		key, db, ok := resultCacheKey(conninfo.Get(ctx).Conv().Username(), doc)
+		if !ok {
+			return next(ctx, req)
+		}
+
+		now := time.Now()
+
+		if raw, ok := h.resultCache.get(key, now); ok {
+			h.resultCache.hits.Inc()
+			return middleware.ResponseMsg(raw)
+		}
+
+		h.resultCache.misses.Inc()
+
+		gen := h.resultCache.generation(db)
+
+		res, err := next(ctx, req)
+		if err != nil || res.OpMsg == nil {
+			return res, err
+		}
+
+		raw, err := res.OpMsg.RawDocument()
+		if err != nil || !resultCacheable(raw) {
+			return res, nil
+		}
+
+		h.resultCache.put(&resultCacheEntry{
+			key:     key,
+			db:      db,
+			res:     raw,
+			expires: now.Add(h.resultCacheTTL()),
+		}, gen, maxSize)
+
+		return res, nil
+	}
+}
+
+// invalidatingResultCache wraps the write command handler so that it invalidates cached results
+// of the command's database (or all databases for commands sent to `admin`)
+// on this and other instances.
+func (h *Handler) invalidatingResultCache(next middleware.HandleFunc) middleware.HandleFunc {
+	return func(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+		res, err := next(ctx, req)
+
+		// invalidate even on errors, as some documents could be modified
+		var db string
+
+		if req.OpMsg != nil {
+			if doc, _ := req.OpMsg.Section0(); doc != nil {
+				db, _ = doc.Get("$db").(string)
+			}
+		}
+
+		h.invalidateResultCache(ctx, db)
+
+		return res, err
+	}
+}
+
+// invalidateResultCache invalidates cached results of the given database
+// (or all databases for empty name and `admin`) on this and other instances.
+func (h *Handler) invalidateResultCache(ctx context.Context, db string) {
+	msg := clusterMessage{Type: clusterInvalidateResultCache}
+
+	if db == "" || db == "admin" {
+		h.resultCache.invalidateAll()
+		msg.All = true
+	} else {
+		h.resultCache.invalidate(db)
+		msg.Databases = []string{db}
+	}
+
+	if h.paramValues.resultCacheSizeBytes.Load() > 0 {
+		h.notifyCluster(ctx, msg)
+	}
+}
+
+// resultCacheTTL returns the current maximal lifetime of cached results.
+func (h *Handler) resultCacheTTL() time.Duration {
+	return time.Duration(h.paramValues.resultCacheTTLMS.Load()) * time.Millisecond
+}
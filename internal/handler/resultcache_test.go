@@ -0,0 +1,118 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultCacheKey(t *testing.T) {
+	t.Parallel()
+
+	find := func(fields ...any) *wirebson.Document {
+		pairs := []any{"find", "c", "filter", wirebson.MustDocument("a", int32(1)), "$db", "db"}
+		return wirebson.MustDocument(append(pairs, fields...)...)
+	}
+
+	key, db, ok := resultCacheKey("user", find())
+	require.True(t, ok)
+	assert.Equal(t, "db", db)
+
+	key2, _, ok := resultCacheKey("user", find("lsid", wirebson.MustDocument("id", "x"), "comment", "dashboard"))
+	require.True(t, ok)
+	assert.Equal(t, key, key2, "volatile fields should not affect the key")
+
+	key2, _, ok = resultCacheKey("other", find())
+	require.True(t, ok)
+	assert.NotEqual(t, key, key2, "users should not share results")
+
+	_, _, ok = resultCacheKey("user", find("comment", "report ferretdb:nocache"))
+	assert.False(t, ok)
+
+	_, _, ok = resultCacheKey("user", find("txnNumber", int64(1)))
+	assert.False(t, ok)
+
+	agg := wirebson.MustDocument(
+		"aggregate", "c",
+		"pipeline", wirebson.MustArray(wirebson.MustDocument("$sample", wirebson.MustDocument("size", int32(1)))),
+		"$db", "db",
+	)
+	_, _, ok = resultCacheKey("user", agg)
+	assert.False(t, ok)
+}
+
+func TestResultCache(t *testing.T) {
+	t.Parallel()
+
+	c := newResultCache()
+	now := time.Now()
+
+	res := wirebson.MustDocument("n", int32(1), "ok", float64(1))
+	raw, err := res.Encode()
+	require.NoError(t, err)
+
+	c.put(&resultCacheEntry{key: "a", db: "db1", res: raw, expires: now.Add(time.Minute)}, c.generation("db1"), 1024)
+	c.put(&resultCacheEntry{key: "b", db: "db2", res: raw, expires: now.Add(time.Minute)}, c.generation("db2"), 1024)
+
+	_, ok := c.get("a", now)
+	assert.True(t, ok)
+
+	_, ok = c.get("a", now.Add(2*time.Minute))
+	assert.False(t, ok, "expired")
+
+	// stale result computed during a write is not stored
+	gen := c.generation("db2")
+	c.invalidate("db2")
+	c.put(&resultCacheEntry{key: "b", db: "db2", res: raw, expires: now.Add(time.Minute)}, gen, 1024)
+
+	_, ok = c.get("b", now)
+	assert.False(t, ok)
+
+	// least recently used entries are evicted
+	for _, key := range []string{"x", "y", "z"} {
+		e := &resultCacheEntry{key: key, db: "db3", res: raw, expires: now.Add(time.Minute)}
+		c.put(e, c.generation("db3"), int64(2*len(raw)))
+	}
+
+	_, ok = c.get("x", now)
+	assert.False(t, ok)
+
+	_, ok = c.get("z", now)
+	assert.True(t, ok)
+
+	c.invalidateAll()
+
+	_, ok = c.get("z", now)
+	assert.False(t, ok)
+}
+
+func TestResultCacheable(t *testing.T) {
+	t.Parallel()
+
+	res := wirebson.MustDocument("cursor", wirebson.MustDocument("id", int64(0), "ns", "db.c"), "ok", float64(1))
+	raw, err := res.Encode()
+	require.NoError(t, err)
+	assert.True(t, resultCacheable(raw))
+
+	res = wirebson.MustDocument("cursor", wirebson.MustDocument("id", int64(42), "ns", "db.c"), "ok", float64(1))
+	raw, err = res.Encode()
+	require.NoError(t, err)
+	assert.False(t, resultCacheable(raw))
+}
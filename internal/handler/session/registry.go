@@ -144,11 +144,15 @@ func (r *Registry) NewSession(ctx context.Context) uuid.UUID {
 // EndSessions marks sessions as ended.
 // If a session does not exist, it does nothing.
 func (r *Registry) EndSessions(ctx context.Context, sessionIDs []uuid.UUID) {
+	r.EndUserSessions(getUserID(ctx), sessionIDs)
+}
+
+// EndUserSessions marks sessions of the given user as ended.
+// If a session does not exist, it does nothing.
+func (r *Registry) EndUserSessions(userID UserID, sessionIDs []uuid.UUID) {
 	r.rw.Lock()
 	defer r.rw.Unlock()
 
-	userID := getUserID(ctx)
-
 	for _, sessionID := range sessionIDs {
 		if _, ok := r.sessions[userID][sessionID]; !ok {
 			continue
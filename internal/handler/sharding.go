@@ -0,0 +1,215 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+)
+
+// shardName is the name of the single logical shard reported by sharding-related commands.
+// Without experimental sharding, all data is stored in the same PostgreSQL database.
+const shardName = "ferretdb"
+
+// shard represents a shard reported by sharding-related commands.
+type shard struct {
+	name string
+	host string
+}
+
+// shardingRequest decodes the sharding-related command that could be run only against the admin database.
+func (h *Handler) shardingRequest(connCtx context.Context, req *middleware.Request) (*wirebson.Document, error) {
+	doc, err := req.OpMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, _, err = h.s.CreateOrUpdateByLSID(connCtx, doc); err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	dbName, err := getRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, mongoerrors.NewWithArgument(
+			mongoerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	return doc, nil
+}
+
+// shards returns shards for sharding-related commands.
+//
+// With `ferretdbExperimentalSharding` parameter set, PostgreSQL nodes of the Citus cluster are returned.
+// Otherwise (or if Citus is not installed), the single logical shard is returned.
+func (h *Handler) shards(ctx context.Context) []shard {
+	if h.paramValues.experimentalSharding.Load() {
+		var nodes []documentdb.ShardNode
+
+		err := h.Pool.WithConn(func(conn *pgx.Conn) error {
+			var err error
+			nodes, err = documentdb.ShardNodes(ctx, conn)
+
+			return err
+		})
+		if err != nil {
+			h.L.WarnContext(ctx, "Failed to get shard nodes", logging.Error(err))
+		}
+
+		if len(nodes) > 0 {
+			res := make([]shard, len(nodes))
+			for i, n := range nodes {
+				res[i] = shard{name: fmt.Sprintf("%s-%d", shardName, n.Group), host: n.Host}
+			}
+
+			return res
+		}
+	}
+
+	host := h.TCPHost
+
+	switch {
+	case host == "":
+		host = "localhost:27017"
+	case strings.HasPrefix(host, ":"):
+		host = "localhost" + host
+	}
+
+	if h.ReplSetName != "" {
+		host = h.ReplSetName + "/" + host
+	}
+
+	return []shard{{name: shardName, host: host}}
+}
+
+// shardingNotImplemented returns an error for commands that require sharding configuration.
+func shardingNotImplemented(command string) error {
+	return mongoerrors.NewWithArgument(
+		mongoerrors.ErrNotImplemented,
+		command+" is not supported: FerretDB stores all data in a single PostgreSQL database that acts as a single shard",
+		command,
+	)
+}
+
+// msgShardingNotImplemented implements commands that change the sharding configuration,
+// such as `addShard`, by returning a clear error.
+// Shard nodes are managed by Citus with experimental sharding.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgShardingNotImplemented(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) { //nolint:lll // for readability
+	doc, err := h.shardingRequest(connCtx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, shardingNotImplemented(doc.Command())
+}
+
+// msgEnableSharding implements `enableSharding` command.
+//
+// With experimental sharding, it does nothing, as collections of all databases could be sharded.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgEnableSharding(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := h.shardingRequest(connCtx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	if !h.paramValues.experimentalSharding.Load() {
+		return nil, shardingNotImplemented(command)
+	}
+
+	if _, err = getRequiredParam[string](doc, command); err != nil {
+		return nil, err
+	}
+
+	return middleware.ResponseMsg(wirebson.MustDocument(
+		"ok", float64(1),
+	))
+}
+
+// msgShardCollection implements `shardCollection`, `reshardCollection`, and `unshardCollection` commands.
+//
+// With experimental sharding, DocumentDB distributes the collection across Citus nodes by the hashed shard key.
+//
+// The passed context is canceled when the client connection is closed.
+func (h *Handler) msgShardCollection(connCtx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	doc, err := h.shardingRequest(connCtx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	command := doc.Command()
+
+	if !h.paramValues.experimentalSharding.Load() {
+		return nil, shardingNotImplemented(command)
+	}
+
+	ns, err := getRequiredParam[string](doc, command)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := req.OpMsg.RawDocument()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	err = h.Pool.WithConn(func(conn *pgx.Conn) error {
+		switch command {
+		case "shardCollection":
+			return documentdb_api.ShardCollection1(connCtx, conn, h.L, spec)
+		case "reshardCollection":
+			return documentdb_api.ReshardCollection(connCtx, conn, h.L, spec)
+		default:
+			return documentdb_api.UnshardCollection(connCtx, conn, h.L, spec)
+		}
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	h.L.InfoContext(connCtx, "Collection sharding changed", slog.String("command", command), slog.String("ns", ns))
+
+	res := wirebson.MustDocument("ok", float64(1))
+	if command == "shardCollection" {
+		res = wirebson.MustDocument("collectionsharded", ns, "ok", float64(1))
+	}
+
+	return middleware.ResponseMsg(res)
+}
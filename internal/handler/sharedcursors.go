@@ -0,0 +1,209 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/google/uuid"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/session"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// shareCursor stores the cursor in PostgreSQL if `ferretdbSharedCursors` parameter is set,
+// so `getMore` could be handled by any FerretDB instance.
+// Cursors with persisted connections are not shared.
+//
+// Errors are logged, not returned, as the cursor could still be used with this instance.
+func (h *Handler) shareCursor(ctx context.Context, userID session.UserID, sessionID uuid.UUID, cursorID int64, opts *findOptions, noTimeout bool) { //nolint:lll // for readability
+	if !h.paramValues.sharedCursors.Load() || cursorID == 0 {
+		return
+	}
+
+	continuation := h.Pool.ShareableContinuation(cursorID)
+	if continuation == nil {
+		return
+	}
+
+	c := &documentdb.SharedCursor{
+		UserID:       userID[:],
+		SessionID:    sessionID[:],
+		Continuation: continuation,
+		Data:         encodeFindOptions(opts),
+		NoTimeout:    noTimeout,
+	}
+
+	if err := h.Pool.SaveSharedCursor(ctx, cursorID, c); err != nil {
+		h.L.WarnContext(ctx, "Failed to share cursor", slog.Int64("cursor", cursorID), logging.Error(err))
+	}
+}
+
+// resumeSharedCursor loads the cursor stored by [Handler.shareCursor] (possibly by another instance),
+// registers its owner, and updates its continuation.
+// It does nothing if `ferretdbSharedCursors` parameter is not set or the cursor was not shared.
+func (h *Handler) resumeSharedCursor(ctx context.Context, cursorID int64) error {
+	if !h.paramValues.sharedCursors.Load() {
+		return nil
+	}
+
+	c, err := h.Pool.LoadSharedCursor(ctx, cursorID)
+	if err != nil || c == nil {
+		return err
+	}
+
+	var userID session.UserID
+	var sessionID uuid.UUID
+
+	if len(c.UserID) != len(userID) || len(c.SessionID) != len(sessionID) {
+		return lazyerrors.Errorf("invalid owner of shared cursor %d", cursorID)
+	}
+
+	copy(userID[:], c.UserID)
+	copy(sessionID[:], c.SessionID)
+
+	if h.Pool.ResumeCursor(cursorID, c.Continuation) {
+		return nil
+	}
+
+	h.s.AddCursor(ctx, userID, sessionID, cursorID)
+
+	if c.NoTimeout {
+		h.Pool.SetCursorNoTimeout(cursorID)
+	}
+
+	opts, err := decodeFindOptions(c.Data)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	h.Pool.SetCursorData(cursorID, &cursorData{opts: opts})
+
+	return nil
+}
+
+// updateSharedCursor stores the new continuation of the shared cursor after `getMore`,
+// or deletes it if the cursor was exhausted.
+func (h *Handler) updateSharedCursor(ctx context.Context, userID session.UserID, sessionID uuid.UUID, cursorID int64) {
+	if !h.paramValues.sharedCursors.Load() {
+		return
+	}
+
+	if h.Pool.ShareableContinuation(cursorID) != nil {
+		h.shareCursor(ctx, userID, sessionID, cursorID, nil, false)
+		return
+	}
+
+	h.deleteSharedCursors(ctx, []int64{cursorID})
+}
+
+// deleteSharedCursors deletes shared cursors with the given IDs.
+func (h *Handler) deleteSharedCursors(ctx context.Context, cursorIDs []int64) {
+	if !h.paramValues.sharedCursors.Load() {
+		return
+	}
+
+	if err := h.Pool.DeleteSharedCursors(ctx, cursorIDs); err != nil {
+		h.L.WarnContext(ctx, "Failed to delete shared cursors", logging.Error(err))
+	}
+}
+
+// deleteSessionSharedCursors deletes shared cursors of sessions described by the given message.
+func (h *Handler) deleteSessionSharedCursors(ctx context.Context, msg *clusterMessage) {
+	if !h.paramValues.sharedCursors.Load() {
+		return
+	}
+
+	var userIDs, sessionIDs [][]byte
+
+	if !msg.All {
+		userIDs = make([][]byte, len(msg.UserIDs))
+		for i, userID := range msg.UserIDs {
+			userIDs[i] = userID[:]
+		}
+
+		for _, sessionID := range msg.SessionIDs {
+			sessionIDs = append(sessionIDs, sessionID[:])
+		}
+	}
+
+	if err := h.Pool.DeleteUserSharedCursors(ctx, userIDs, sessionIDs); err != nil {
+		h.L.WarnContext(ctx, "Failed to delete shared cursors", logging.Error(err))
+	}
+}
+
+// encodeFindOptions encodes options for storing them with the shared cursor.
+func encodeFindOptions(opts *findOptions) wirebson.RawDocument {
+	if opts == nil {
+		return nil
+	}
+
+	doc := wirebson.MustDocument(
+		"db", opts.db,
+		"collection", opts.collection,
+		"returnKey", opts.returnKey,
+		"showRecordId", opts.showRecordID,
+	)
+
+	if opts.keyPattern != nil {
+		must.NoError(doc.Add("keyPattern", opts.keyPattern))
+	}
+
+	if opts.masks != nil {
+		masks := wirebson.MakeDocument(len(opts.masks))
+		for path, action := range opts.masks {
+			must.NoError(masks.Add(path, action))
+		}
+
+		must.NoError(doc.Add("masks", masks))
+	}
+
+	return must.NotFail(doc.Encode())
+}
+
+// decodeFindOptions decodes options encoded by [encodeFindOptions].
+// It returns nil for empty data.
+func decodeFindOptions(raw wirebson.RawDocument) (*findOptions, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	doc, err := raw.DecodeDeep()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	opts := new(findOptions)
+	opts.db, _ = doc.Get("db").(string)
+	opts.collection, _ = doc.Get("collection").(string)
+	opts.returnKey, _ = doc.Get("returnKey").(bool)
+	opts.showRecordID, _ = doc.Get("showRecordId").(bool)
+	opts.keyPattern, _ = doc.Get("keyPattern").(*wirebson.Document)
+
+	if masks, _ := doc.Get("masks").(*wirebson.Document); masks != nil {
+		opts.masks = make(fieldMasks, masks.Len())
+
+		for path, v := range masks.All() {
+			opts.masks[path], _ = v.(string)
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOptionsEncoding(t *testing.T) {
+	t.Parallel()
+
+	opts := &findOptions{
+		db:           "db",
+		collection:   "coll",
+		keyPattern:   wirebson.MustDocument("v", int32(1)),
+		masks:        fieldMasks{"ssn": maskRemove, "email": maskHash},
+		showRecordID: true,
+	}
+
+	actual, err := decodeFindOptions(encodeFindOptions(opts))
+	require.NoError(t, err)
+	assert.Equal(t, opts, actual)
+
+	actual, err = decodeFindOptions(encodeFindOptions(nil))
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+}
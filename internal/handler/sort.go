@@ -0,0 +1,40 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+// validateSort returns a protocol error if the given sort specification has invalid field paths.
+// Other problems, like invalid sort values, are handled by DocumentDB.
+//
+// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/241
+// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/265
+func validateSort(sort any) error {
+	doc, err := decodeDocument(sort)
+	if doc == nil || err != nil {
+		return err
+	}
+
+	for key := range doc.Fields() {
+		// empty keys and special sort fields are handled by DocumentDB
+		if key == "" || key == "$natural" {
+			continue
+		}
+
+		if err = validateFieldPath(key, "sort"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
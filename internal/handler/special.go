@@ -0,0 +1,150 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb"
+	"github.com/FerretDB/FerretDB/v2/internal/documentdb/documentdb_api"
+	"github.com/FerretDB/FerretDB/v2/internal/handler/middleware"
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// writableSystemCollections contains system collections that clients could modify directly, as in MongoDB.
+var writableSystemCollections = []string{
+	"system.js",
+	"system.profile",
+}
+
+// configStubCollections contains collections of the `config` database
+// that are reserved for sharding metadata.
+// FerretDB has no sharded collections, so they are always empty and read-only.
+var configStubCollections = []string{
+	"chunks",
+	"collections",
+}
+
+// protectedNamespace returns a protocol error if the given collection can't be written directly.
+func protectedNamespace(command, dbName, collection string) error {
+	protected := strings.HasPrefix(collection, "system.") && !slices.Contains(writableSystemCollections, collection)
+
+	if dbName == "config" && slices.Contains(configStubCollections, collection) {
+		protected = true
+	}
+
+	if !protected {
+		return nil
+	}
+
+	return mongoerrors.NewWithArgument(
+		mongoerrors.ErrInvalidNamespace,
+		fmt.Sprintf("cannot write to '%s.%s'", dbName, collection),
+		command,
+	)
+}
+
+// blockedProtectedNamespaces is a middleware that wraps the write command handler
+// with a check of the written namespace.
+func blockedProtectedNamespaces(next middleware.HandleFunc) middleware.HandleFunc {
+	return func(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+		if req.OpMsg == nil {
+			return next(ctx, req)
+		}
+
+		doc, err := req.OpMsg.Document()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for _, ns := range commandNamespaces(doc) {
+			if err = protectedNamespace(doc.Command(), ns[0], ns[1]); err != nil {
+				return nil, err
+			}
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// commandNamespaces returns namespaces given by the command value of the given command document.
+//
+// Most commands have a collection name as a command value;
+// `renameCollection` has full namespaces in the command value and the `to` field.
+func commandNamespaces(doc *wirebson.Document) [][2]string {
+	command := doc.Command()
+
+	dbName, _ := doc.Get("$db").(string)
+
+	var res [][2]string
+
+	switch command {
+	case "createUser", "dropAllUsersFromDatabase", "dropDatabase", "dropUser", "updateUser":
+		// command values are not collection names
+
+	case "renameCollection":
+		for _, v := range []any{doc.Get(command), doc.Get("to")} {
+			if ns, ok := v.(string); ok {
+				if db, collection, found := strings.Cut(ns, "."); found {
+					res = append(res, [2]string{db, collection})
+				}
+			}
+		}
+
+	default:
+		if collection, ok := doc.Get(command).(string); ok {
+			res = append(res, [2]string{dbName, collection})
+		}
+	}
+
+	return res
+}
+
+// usersExist returns true if there is at least one user.
+func (h *Handler) usersExist(ctx context.Context, conn *documentdb.Conn) (bool, error) {
+	spec := must.NotFail(must.NotFail(wirebson.NewDocument(
+		"usersInfo", int32(1),
+		"$db", "admin",
+	)).Encode())
+
+	res, err := documentdb_api.UsersInfo(ctx, conn.Conn(), h.L, spec)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	doc, err := res.Decode()
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	users, ok := doc.Get("users").(wirebson.AnyArray)
+	if !ok {
+		return false, nil
+	}
+
+	arr, err := users.Decode()
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return arr.Len() > 0, nil
+}
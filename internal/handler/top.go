@@ -0,0 +1,189 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// maxTopNamespaces is the maximal number of namespaces tracked for `top` command.
+// Commands for other namespaces are not tracked.
+const maxTopNamespaces = 10000
+
+// topCounter represents the total time and count of operations.
+type topCounter struct {
+	time  time.Duration
+	count int64
+}
+
+// add adds a single operation with the given duration.
+func (c *topCounter) add(d time.Duration) {
+	c.time += d
+	c.count++
+}
+
+// document returns `top` representation of the counter.
+func (c *topCounter) document() *wirebson.Document {
+	return wirebson.MustDocument(
+		"time", c.time.Microseconds(),
+		"count", c.count,
+	)
+}
+
+// topNamespace contains counters of a single namespace.
+//
+// FerretDB does not take MongoDB-like locks;
+// commands that modify data or metadata are counted as holding the write lock, other as holding the read lock.
+type topNamespace struct {
+	total     topCounter
+	readLock  topCounter
+	writeLock topCounter
+	queries   topCounter
+	getmore   topCounter
+	insert    topCounter
+	update    topCounter
+	remove    topCounter
+	commands  topCounter
+}
+
+// top collects per-namespace operation counters for `top` command.
+//
+// The zero value is ready to use.
+type top struct {
+	rw sync.RWMutex
+	ns map[string]*topNamespace
+}
+
+// topNamespaceName returns the namespace of the given command, or empty string if it is not a collection command.
+func topNamespaceName(doc *wirebson.Document) string {
+	db, _ := doc.Get("$db").(string)
+	if db == "" {
+		return ""
+	}
+
+	cmd := doc.Command()
+
+	field := cmd
+	if cmd == "getMore" {
+		field = "collection"
+	}
+
+	collection, _ := doc.Get(field).(string)
+	if collection == "" {
+		return ""
+	}
+
+	return db + "." + collection
+}
+
+// record tracks the given command that took the given time.
+// The write parameter is true for commands that modify data or metadata.
+func (t *top) record(doc *wirebson.Document, write bool, d time.Duration) {
+	cmd := doc.Command()
+
+	t.rw.Lock()
+	defer t.rw.Unlock()
+
+	if cmd == "dropDatabase" {
+		db, _ := doc.Get("$db").(string)
+		maps.DeleteFunc(t.ns, func(ns string, _ *topNamespace) bool {
+			return strings.HasPrefix(ns, db+".")
+		})
+
+		return
+	}
+
+	name := topNamespaceName(doc)
+	if name == "" {
+		return
+	}
+
+	if cmd == "drop" {
+		delete(t.ns, name)
+		return
+	}
+
+	ns := t.ns[name]
+	if ns == nil {
+		if len(t.ns) >= maxTopNamespaces {
+			return
+		}
+
+		if t.ns == nil {
+			t.ns = make(map[string]*topNamespace)
+		}
+
+		ns = new(topNamespace)
+		t.ns[name] = ns
+	}
+
+	ns.total.add(d)
+
+	if write {
+		ns.writeLock.add(d)
+	} else {
+		ns.readLock.add(d)
+	}
+
+	switch cmd {
+	case "find":
+		ns.queries.add(d)
+	case "getMore":
+		ns.getmore.add(d)
+	case "insert":
+		ns.insert.add(d)
+	case "update", "findAndModify", "findandmodify":
+		ns.update.add(d)
+	case "delete":
+		ns.remove.add(d)
+	default:
+		ns.commands.add(d)
+	}
+}
+
+// document returns `totals` field of `top` command response.
+func (t *top) document() *wirebson.Document {
+	t.rw.RLock()
+	defer t.rw.RUnlock()
+
+	res := wirebson.MakeDocument(len(t.ns) + 1)
+	must.NoError(res.Add("note", "all times in microseconds"))
+
+	for _, name := range slices.Sorted(maps.Keys(t.ns)) {
+		ns := t.ns[name]
+
+		must.NoError(res.Add(name, wirebson.MustDocument(
+			"total", ns.total.document(),
+			"readLock", ns.readLock.document(),
+			"writeLock", ns.writeLock.document(),
+			"queries", ns.queries.document(),
+			"getmore", ns.getmore.document(),
+			"insert", ns.insert.document(),
+			"update", ns.update.document(),
+			"remove", ns.remove.document(),
+			"commands", ns.commands.document(),
+		)))
+	}
+
+	return res
+}
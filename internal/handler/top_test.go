@@ -0,0 +1,52 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTop(t *testing.T) {
+	t.Parallel()
+
+	var top top
+
+	top.record(wirebson.MustDocument("find", "c", "$db", "db"), false, 2*time.Millisecond)
+	top.record(wirebson.MustDocument("getMore", int64(1), "collection", "c", "$db", "db"), false, time.Millisecond)
+	top.record(wirebson.MustDocument("insert", "c", "$db", "db"), true, 3*time.Millisecond)
+	top.record(wirebson.MustDocument("insert", "other", "$db", "db2"), true, time.Millisecond)
+	top.record(wirebson.MustDocument("ping", int32(1), "$db", "admin"), false, time.Millisecond)
+
+	totals := top.document()
+	assert.Equal(t, []string{"note", "db.c", "db2.other"}, totals.FieldNames())
+
+	ns := totals.Get("db.c").(*wirebson.Document)
+	assert.Equal(t, wirebson.MustDocument("time", int64(6000), "count", int64(3)), ns.Get("total"))
+	assert.Equal(t, wirebson.MustDocument("time", int64(3000), "count", int64(2)), ns.Get("readLock"))
+	assert.Equal(t, wirebson.MustDocument("time", int64(3000), "count", int64(1)), ns.Get("writeLock"))
+	assert.Equal(t, wirebson.MustDocument("time", int64(2000), "count", int64(1)), ns.Get("queries"))
+	assert.Equal(t, wirebson.MustDocument("time", int64(1000), "count", int64(1)), ns.Get("getmore"))
+	assert.Equal(t, wirebson.MustDocument("time", int64(0), "count", int64(0)), ns.Get("commands"))
+
+	top.record(wirebson.MustDocument("drop", "c", "$db", "db"), true, time.Millisecond)
+	assert.Equal(t, []string{"note", "db2.other"}, top.document().FieldNames())
+
+	top.record(wirebson.MustDocument("dropDatabase", int32(1), "$db", "db2"), true, time.Millisecond)
+	assert.Equal(t, []string{"note"}, top.document().FieldNames())
+}
@@ -0,0 +1,382 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/wire/wirebson"
+
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// decodeDocument returns decoded document for raw or decoded document value, or nil for other types.
+func decodeDocument(v any) (*wirebson.Document, error) {
+	switch v := v.(type) {
+	case *wirebson.Document:
+		return v, nil
+	case wirebson.RawDocument:
+		doc, err := v.Decode()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return doc, nil
+	default:
+		return nil, nil
+	}
+}
+
+// decodeArray returns decoded array for raw or decoded array value, or nil for other types.
+func decodeArray(v any) (*wirebson.Array, error) {
+	switch v := v.(type) {
+	case *wirebson.Array:
+		return v, nil
+	case wirebson.RawArray:
+		arr, err := v.Decode()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return arr, nil
+	default:
+		return nil, nil
+	}
+}
+
+// commandStatements returns deeply decoded statements of `update` or `delete` command
+// from the given field of the command document and from the document sequence.
+func commandStatements(doc *wirebson.Document, field string, seq []byte) ([]*wirebson.Document, error) {
+	var res []*wirebson.Document
+
+	arr, err := decodeArray(doc.Get(field))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if arr != nil {
+		for v := range arr.Values() {
+			var d *wirebson.Document
+
+			switch v := v.(type) {
+			case *wirebson.Document:
+				d = v
+			case wirebson.RawDocument:
+				if d, err = v.DecodeDeep(); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+			default:
+				// invalid statements are rejected by DocumentDB
+				continue
+			}
+
+			res = append(res, d)
+		}
+	}
+
+	_, err = updateSequence(seq, true, func(d *wirebson.Document) bool {
+		res = append(res, d)
+		return false
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// validateCurrentDate returns a protocol error if `$currentDate` operator of the given update document
+// has invalid field specifications.
+//
+// DocumentDB validates them only when some document is matched.
+// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/323
+func validateCurrentDate(update any) error {
+	doc, err := decodeDocument(update)
+	if doc == nil || err != nil {
+		return err
+	}
+
+	// other types are handled by DocumentDB
+	fields, err := decodeDocument(doc.Get("$currentDate"))
+	if fields == nil || err != nil {
+		return err
+	}
+
+	for _, v := range fields.All() {
+		if _, ok := v.(bool); ok {
+			continue
+		}
+
+		spec, err := decodeDocument(v)
+		if err != nil {
+			return err
+		}
+
+		if spec == nil {
+			msg := fmt.Sprintf(
+				"%s is not valid type for $currentDate. "+
+					"Please use a boolean ('true') or a $type expression ({$type: 'timestamp/date'}).",
+				aliasFromType(v),
+			)
+
+			return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, "$currentDate")
+		}
+
+		var validType bool
+
+		for option, t := range spec.All() {
+			if option != "$type" {
+				msg := fmt.Sprintf("Unrecognized $currentDate option: %s", option)
+				return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, "$currentDate")
+			}
+
+			if t == "date" || t == "timestamp" {
+				validType = true
+			}
+		}
+
+		if !validType {
+			return mongoerrors.NewWithArgument(
+				mongoerrors.ErrBadValue,
+				"The '$type' string field is required to be 'date' or 'timestamp': "+
+					"{$currentDate: {field : {$type: 'date'}}}",
+				"$currentDate",
+			)
+		}
+	}
+
+	return nil
+}
+
+// validateRename returns a protocol error if `$rename` operator of the given update document
+// has invalid or conflicting source and target fields.
+//
+// DocumentDB does not check them.
+// TODO https://github.com/FerretDB/FerretDB-DocumentDB/issues/324
+func validateRename(update any) error {
+	doc, err := decodeDocument(update)
+	if doc == nil || err != nil {
+		return err
+	}
+
+	// other types are handled by DocumentDB
+	fields, err := decodeDocument(doc.Get("$rename"))
+	if fields == nil || err != nil {
+		return err
+	}
+
+	var paths []string
+
+	for from, v := range fields.All() {
+		expr := fmt.Sprintf("%s: %v", from, v)
+
+		to, ok := v.(string)
+		if !ok {
+			msg := fmt.Sprintf("The 'to' field for $rename must be a string: %s", expr)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, "$rename")
+		}
+
+		if dynamicPath(from) {
+			msg := fmt.Sprintf("The source field for $rename may not be dynamic: %s", from)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, "$rename")
+		}
+
+		if dynamicPath(to) {
+			msg := fmt.Sprintf("The destination field for $rename may not be dynamic: %s", to)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, "$rename")
+		}
+
+		if from == to {
+			msg := fmt.Sprintf("The source and target field for $rename must differ: %s", expr)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, "$rename")
+		}
+
+		if pathPrefix(from, to) || pathPrefix(to, from) {
+			msg := fmt.Sprintf("The source and target field for $rename must not be on the same path: %s", expr)
+			return mongoerrors.NewWithArgument(mongoerrors.ErrBadValue, msg, "$rename")
+		}
+
+		for _, p := range []string{from, to} {
+			for _, prev := range paths {
+				if pathPrefix(prev, p) || pathPrefix(p, prev) {
+					msg := fmt.Sprintf("Updating the path '%s' would create a conflict at '%s'", p, prev)
+					return mongoerrors.NewWithArgument(mongoerrors.ErrConflictingUpdateOperators, msg, "$rename")
+				}
+			}
+		}
+
+		paths = append(paths, from, to)
+	}
+
+	return nil
+}
+
+// dynamicPath returns true if the dot notation path contains positional operators.
+func dynamicPath(path string) bool {
+	for part := range strings.SplitSeq(path, ".") {
+		if part == "$" || strings.HasPrefix(part, "$[") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pathPrefix returns true if the dot notation path prefix is equal to path or its prefix.
+func pathPrefix(prefix, path string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+".")
+}
+
+// validateUpdateStatements validates update documents of the given `update` command statements
+// with [validateCurrentDate] and [validateRename].
+//
+// It returns statements that should be executed by DocumentDB, their indexes in the command,
+// and write errors for invalid statements, as MongoDB reports them.
+// For ordered commands, statements after the first invalid one are not executed.
+func validateUpdateStatements(statements []*wirebson.Document, ordered bool) ([]*wirebson.Document, []int, []*wirebson.Document, error) { //nolint:lll // for readability
+	var valid, writeErrors []*wirebson.Document
+	var indexes []int
+
+	for i, st := range statements {
+		err := validateCurrentDate(st.Get("u"))
+		if err == nil {
+			err = validateRename(st.Get("u"))
+		}
+
+		if err == nil {
+			valid = append(valid, st)
+			indexes = append(indexes, i)
+
+			continue
+		}
+
+		var e *mongoerrors.Error
+		if !errors.As(err, &e) {
+			return nil, nil, nil, lazyerrors.Error(err)
+		}
+
+		writeErrors = append(writeErrors, wirebson.MustDocument(
+			"index", int32(i),
+			"code", e.Code,
+			"errmsg", e.Message,
+		))
+
+		if ordered {
+			break
+		}
+	}
+
+	return valid, indexes, writeErrors, nil
+}
+
+// addWriteErrors returns the `update` command response of executed statements with given indexes in the command,
+// with write errors of statements that were not executed.
+//
+// Indexes of statements in the response are replaced with indexes in the command.
+// For ordered commands, write errors of statements after the first failed executed statement are not added,
+// as execution stops there.
+func addWriteErrors(res wirebson.AnyDocument, indexes []int, writeErrors []*wirebson.Document, ordered bool) (*wirebson.Document, error) { //nolint:lll // for readability
+	doc, err := res.Decode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	remap := func(field any) ([]*wirebson.Document, error) {
+		arr, decodeErr := decodeArray(field)
+		if arr == nil || decodeErr != nil {
+			return nil, decodeErr
+		}
+
+		var docs []*wirebson.Document
+
+		for v := range arr.Values() {
+			d, docErr := decodeDocument(v)
+			if docErr != nil {
+				return nil, lazyerrors.Error(docErr)
+			}
+
+			if d == nil {
+				continue
+			}
+
+			if i, ok := d.Get("index").(int32); ok && int(i) < len(indexes) {
+				must.NoError(d.Replace("index", int32(indexes[i])))
+			}
+
+			docs = append(docs, d)
+		}
+
+		return docs, nil
+	}
+
+	upserted, err := remap(doc.Get("upserted"))
+	if err != nil {
+		return nil, err
+	}
+
+	executed, err := remap(doc.Get("writeErrors"))
+	if err != nil {
+		return nil, err
+	}
+
+	if ordered && len(executed) > 0 {
+		first, _ := executed[0].Get("index").(int32)
+
+		writeErrors = slices.DeleteFunc(slices.Clone(writeErrors), func(e *wirebson.Document) bool {
+			i, _ := e.Get("index").(int32)
+			return i > first
+		})
+	}
+
+	all := append(executed, writeErrors...)
+	slices.SortStableFunc(all, func(a, b *wirebson.Document) int {
+		ai, _ := a.Get("index").(int32)
+		bi, _ := b.Get("index").(int32)
+
+		return cmp.Compare(ai, bi)
+	})
+
+	toArray := func(docs []*wirebson.Document) *wirebson.Array {
+		arr := wirebson.MakeArray(len(docs))
+		for _, d := range docs {
+			must.NoError(arr.Add(d))
+		}
+
+		return arr
+	}
+
+	resDoc := wirebson.MakeDocument(doc.Len() + 1)
+
+	for k, v := range doc.All() {
+		switch k {
+		case "upserted":
+			v = toArray(upserted)
+		case "writeErrors":
+			continue
+		case "ok":
+			must.NoError(resDoc.Add("writeErrors", toArray(all)))
+		}
+
+		must.NoError(resDoc.Add(k, v))
+	}
+
+	return resDoc, nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/mongoerrors"
+)
+
+func TestValidateUpdateStatements(t *testing.T) {
+	t.Parallel()
+
+	statements := []*wirebson.Document{
+		wirebson.MustDocument("q", wirebson.MakeDocument(0), "u", wirebson.MustDocument(
+			"$set", wirebson.MustDocument("v", int32(1)),
+		)),
+		wirebson.MustDocument("q", wirebson.MakeDocument(0), "u", wirebson.MustDocument(
+			"$currentDate", wirebson.MustDocument("v", "date"),
+		)),
+		wirebson.MustDocument("q", wirebson.MakeDocument(0), "u", wirebson.MustDocument(
+			"$rename", wirebson.MustDocument("v", "v"),
+		)),
+		wirebson.MustDocument("q", wirebson.MakeDocument(0), "u", wirebson.MustDocument(
+			"$rename", wirebson.MustDocument("v", "w"),
+		)),
+	}
+
+	t.Run("Ordered", func(t *testing.T) {
+		t.Parallel()
+
+		valid, indexes, writeErrors, err := validateUpdateStatements(statements, true)
+		require.NoError(t, err)
+		assert.Equal(t, statements[:1], valid)
+		assert.Equal(t, []int{0}, indexes)
+		require.Len(t, writeErrors, 1)
+		assert.Equal(t, int32(1), writeErrors[0].Get("index"))
+		assert.Equal(t, int32(mongoerrors.ErrBadValue), writeErrors[0].Get("code"))
+	})
+
+	t.Run("Unordered", func(t *testing.T) {
+		t.Parallel()
+
+		valid, indexes, writeErrors, err := validateUpdateStatements(statements, false)
+		require.NoError(t, err)
+		assert.Equal(t, []*wirebson.Document{statements[0], statements[3]}, valid)
+		assert.Equal(t, []int{0, 3}, indexes)
+		require.Len(t, writeErrors, 2)
+		assert.Equal(t, int32(1), writeErrors[0].Get("index"))
+		assert.Equal(t, int32(2), writeErrors[1].Get("index"))
+	})
+}
+
+func TestAddWriteErrors(t *testing.T) {
+	t.Parallel()
+
+	res := wirebson.MustDocument(
+		"n", int32(2),
+		"nModified", int32(1),
+		"upserted", wirebson.MustArray(wirebson.MustDocument("index", int32(1), "_id", "new")),
+		"writeErrors", wirebson.MustArray(wirebson.MustDocument("index", int32(2), "code", int32(11000), "errmsg", "dup")),
+		"ok", float64(1),
+	)
+
+	writeErrors := []*wirebson.Document{
+		wirebson.MustDocument("index", int32(1), "code", int32(mongoerrors.ErrBadValue), "errmsg", "bad"),
+	}
+
+	actual, err := addWriteErrors(res, []int{0, 2, 3}, writeErrors, false)
+	require.NoError(t, err)
+
+	expected := wirebson.MustDocument(
+		"n", int32(2),
+		"nModified", int32(1),
+		"upserted", wirebson.MustArray(wirebson.MustDocument("index", int32(2), "_id", "new")),
+		"writeErrors", wirebson.MustArray(
+			wirebson.MustDocument("index", int32(1), "code", int32(mongoerrors.ErrBadValue), "errmsg", "bad"),
+			wirebson.MustDocument("index", int32(3), "code", int32(11000), "errmsg", "dup"),
+		),
+		"ok", float64(1),
+	)
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestAddWriteErrorsOrdered(t *testing.T) {
+	t.Parallel()
+
+	// duplicate key statement, then invalid $rename
+	statements := []*wirebson.Document{
+		wirebson.MustDocument("q", wirebson.MustDocument("_id", int32(2)), "u", wirebson.MustDocument(
+			"$set", wirebson.MustDocument("v", int32(1)),
+		)),
+		wirebson.MustDocument("q", wirebson.MustDocument("_id", int32(1)), "u", wirebson.MustDocument(
+			"$rename", wirebson.MustDocument("v", "v"),
+		)),
+	}
+
+	valid, indexes, writeErrors, err := validateUpdateStatements(statements, true)
+	require.NoError(t, err)
+	require.Len(t, valid, 1)
+	require.Len(t, writeErrors, 1)
+
+	dup := wirebson.MustDocument("index", int32(0), "code", int32(11000), "errmsg", "dup")
+	res := wirebson.MustDocument(
+		"n", int32(0),
+		"nModified", int32(0),
+		"writeErrors", wirebson.MustArray(dup),
+		"ok", float64(1),
+	)
+
+	actual, err := addWriteErrors(res, indexes, writeErrors, true)
+	require.NoError(t, err)
+	assert.Equal(t, res.LogMessage(), actual.LogMessage())
+
+	actual, err = addWriteErrors(res, indexes, writeErrors, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, actual.Get("writeErrors").(*wirebson.Array).Len())
+}
@@ -49,7 +49,9 @@ func _() {
 	_ = x[ErrIndexKeySpecsConflict-86]
 	_ = x[ErrOperationFailed-96]
 	_ = x[ErrNotExactValueField-111]
+	_ = x[ErrWriteConflict-112]
 	_ = x[ErrCommandNotSupported-115]
+	_ = x[ErrConflictingOperationInProgress-117]
 	_ = x[ErrNamespaceNotSharded-118]
 	_ = x[ErrDocumentFailedValidation-121]
 	_ = x[ErrExceededMemoryLimit-146]
@@ -144,6 +146,7 @@ func _() {
 	_ = x[ErrDollarCondBadParameter-17083]
 	_ = x[ErrDollarSizeRequiresArray-17124]
 	_ = x[ErrExactlyOneTextIndex-17194]
+	_ = x[ErrLocation17217-17217]
 	_ = x[ErrLocation17261-17261]
 	_ = x[ErrLocation17276-17276]
 	_ = x[ErrLocation17308-17308]
@@ -224,6 +227,7 @@ func _() {
 	_ = x[ErrLocation31308-31308]
 	_ = x[ErrLocation31325-31325]
 	_ = x[ErrLocation31393-31393]
+	_ = x[ErrLocation31394-31394]
 	_ = x[ErrLocation31395-31395]
 	_ = x[ErrLocation31441-31441]
 	_ = x[ErrLocation31465-31465]
@@ -318,6 +322,7 @@ func _() {
 	_ = x[ErrLocation40323-40323]
 	_ = x[ErrUnrecognizedCommand-40324]
 	_ = x[ErrLocation40352-40352]
+	_ = x[ErrLocation40353-40353]
 	_ = x[ErrDollarArrayToObjectRequiresArray-40386]
 	_ = x[ErrDollarObjectToArrayRequiresObject-40390]
 	_ = x[ErrDollarArrayToObjectAllMustBeObjects-40391]
@@ -396,6 +401,8 @@ func _() {
 	_ = x[ErrLocation51134-51134]
 	_ = x[ErrLocation51151-51151]
 	_ = x[ErrLocation51156-51156]
+	_ = x[ErrLocation51173-51173]
+	_ = x[ErrLocation51174-51174]
 	_ = x[ErrLocation51178-51178]
 	_ = x[ErrLocation51183-51183]
 	_ = x[ErrLocation51185-51185]
@@ -531,6 +538,7 @@ func _() {
 	_ = x[ErrLocation6050204-6050204]
 	_ = x[ErrLocation6053600-6053600]
 	_ = x[ErrLocation6586400-6586400]
+	_ = x[ErrLocation7369100-7369100]
 	_ = x[ErrLocation7429703-7429703]
 	_ = x[ErrLocation7436100-7436100]
 	_ = x[ErrLocation7555701-7555701]
@@ -542,7 +550,7 @@ func _() {
 	_ = x[ErrLocation8993000-8993000]
 }
 
-const _Code_name = "UnsetInternalErrorBadValueGraphContainsCycleFailedToParseUserNotFoundUnsupportedFormatUnauthorizedTypeMismatchOverflowInvalidLengthProtocolErrorAuthenticationFailedIllegalOperationAlreadyInitializedNamespaceNotFoundIndexNotFoundPathNotViableRoleNotFoundCannotBackfillArrayConflictingUpdateOperatorsCursorNotFoundNamespaceExistsMaxTimeMSExpiredDollarPrefixedFieldNameCanNotBeTypeArrayNotSingleValueFieldLocation55EmptyFieldNameDottedFieldNameCommandNotFoundShardKeyNotFoundImmutableFieldCannotCreateIndexIndexAlreadyExistsInvalidOptionsInvalidNamespaceIndexOptionsConflictIndexKeySpecsConflictOperationFailedNotExactValueFieldCommandNotSupportedNamespaceNotShardedDocumentFailedValidationExceededMemoryLimitDurationOverflowViewDepthLimitExceededCommandNotSupportedOnViewOptionNotSupportedOnViewAmbiguousIndexKeyPatternClientMetadataCannotBeMutatedInvalidIndexSpecificationOptionInvalidUUIDQueryFeatureNotAllowedMaxSubPipelineDepthExceededNotImplementedConversionFailureOperationNotSupportedInTransactionIndexBuildAbortedUnableToFindIndexMechanismUnavailableUnsupportedOpQueryCommandCollectionUUIDMismatchUserCountLimitExceededLocation10065BsonObjectTooLargeDuplicateKeyBackgroundOperationInProgressForNamespaceLocation13026Location13027Location13068Location13111MergeStageNoMatchingDocumentDbAlreadyExistsLocation13548Location15947Location15952Location15955Location15957Location15958Location15959Location15972Location15976Location15981Location15998Location16004Location16006Location16007Location16020Location16034Location16035Location16410Location16411Location16433DollarAddNumericOrDateTypesDollarModByZeroProhibitedDollarModOnlyNumericDollarAddOnlyOneDateLocation16702Location16747Location16748Location16749Location16755Location16764HashedIndexDoNotSupportArrayValuesLocation16800Location16801Location16804Location16874Location16875Location16876Location16878Location16879Location16880Location16882Location16883Location16979Location16990Location16994Location17040Location17041Location17042Location17043Location17044Location17045Location17046Location17047Location17048Location17049Location17053DollarCondMissingIfParameterDollarCondMissingThenParameterDollarCondMissingElseParameterDollarCondBadParameterDollarSizeRequiresArrayExactlyOneTextIndexLocation17261Location17276Location17308Location17310DocumentAfterUpdateLargerThanMaxSizeDocumentToUpsertLargerThanMaxSizeLocation18533Location18534Location18535Location18536Location18537Location18628Location18629Location28625Location28646Location28647Location28648Location28650Location28651Location28656Location28657Location28664RangeArgumentExpressionArgsOutOfRangeDollarAbsCantTakeLongMinValueArrayOperatorElemAtFirstArgMustBeArrayDollarArrayElemAtSecondArgArgMustBeNumericDollarArrayElemAtSecondArgArgMustBe32BitDollarSqrtGreaterOrEqualToZeroDollarSliceInvalidInputDollarSliceInvalidTypeSecondArgDollarSliceInvalidValueSecondArgDollarSliceInvalidTypeThirdArgDollarSliceInvalidValueThirdArgDollarSliceInvalidSignThirdArgLocation28745Location28746Location28747Location28748Location28749DollarLogArgumentMustBeNumericDollarLogBaseMustBeNumericDollarLogNumberMustBePositiveDollarLogBaseMustBeGreaterThanOneDollarLog10MustBePositiveNumberDollarPowBaseMustBeNumericDollarPowExponentMustBeNumericDollarPowExponentInvalidForZeroBaseLocation28765DollarLnMustBePositiveNumberLocation28769Location28803Location28808Location28809Location28810Location28811Location28812Location28818Location28822Location31002Location31022Location31023Location31024KeyCannotContainNullByteLocation31034Location31095Location31109Location31119Location31120Location31138Location31170Location31249Location31250Location31253Location31254Location31256Location31271Location31276Location31308Location31325Location31393Location31395Location31441Location31465Location34435Location34443Location34444Location34445Location34446Location34447Location34448Location34449Location34450Location34451Location34452Location34453Location34454Location34455Location34460Location34461Location34462Location34463Location34464Location34465Location34466Location34467Location34468Location34471Location34473DollarSwitchRequiresObjectDollarSwitchRequiresArrayForBranchesDollarSwitchRequiresObjectForEachBranchDollarSwitchUnknownArgumentForBranchDollarSwitchRequiresCaseExpressionForBranchDollarSwitchRequiresThenExpressionForBranchDollarSwitchNoMatchingBranchAndNoDefaultDollarSwitchBadArgumentDollarSwitchRequiresAtLeastOneBranchLocation40075Location40076Location40077Location40078Location40079Location40080DollarInRequiresArrayLocation40085Location40086Location40087Location40090Location40091Location40092Location40093Location40094Location40096Location40097Location40100Location40101Location40102Location40103Location40104Location40105Location40147Location40156Location40158Location40160Location40169Location40177Location40181Location40185Location40191Location40192Location40193Location40194Location40195Location40196Location40197Location40198Location40199Location40200Location40201Location40202Location40218Location40228Location40229Location40234Location40235Location40236Location40237Location40238Location40272Location40319Location40321Location40323UnrecognizedCommandLocation40352DollarArrayToObjectRequiresArrayDollarObjectToArrayRequiresObjectDollarArrayToObjectAllMustBeObjectsDollarArrayToObjectIncorrectNumberOfKeysDollarArrayToObjectRequiresObjectWithKAndVDollarArrayToObjectObjectKeyMustBeStringDollarArrayToObjectArrayKeyMustBeStringDollarArrayToObjectAllMustBeArraysDollarArrayToObjectIncorrectArrayLengthDollarArrayToObjectBadInputTypeFormatDollarMergeObjectsInvalidTypeLocation40414UnknownBsonFieldLocation40485Location40489Location40515Location40516Location40517Location40518Location40519Location40520Location40521Location40522Location40523Location40524Location40525Location40533Location40535Location40536Location40539Location40540Location40541Location40542Location40600Location40601Location40602Location40603Location40621ChangeStreamBadResumeTokenLocation40684InsufficientPrivilegeLocation50687Location50692Location50694Location50695Location50696Location50699Location50700Location50723Location50752Location50759Location50840Location50989Location51003Location51024Location51044Location51045Location51047Location51074Location51075DollarRoundOverflowInt64DollarRoundFirstArgMustBeNumericDollarRoundPrecisionMustBeIntegralDollarRoundPrecisionOutOfRangeLocation51091Location51103Location51104Location51105Location51106Location51107Location51108Location51109Location51110Location51111Location51132Location51134Location51151Location51156Location51178Location51183Location51185Location51186Location51187Location51191Location51246Location51247Location51276Location51743Location51744Location51745Location51746Location51747Location51748Location51749Location51750Location51751Location327391Location327392Location605001DollarIfNullRequiresAtLeastTwoArgsLocation2942500Location2942501Location2942502Location2942503Location2942504Location2942505Location2942506DollarRandNonEmptyArgumentLocation3041701Location3041702Location3041703Location3041704IntermediateResultTooLargeDollarSetFieldRequiresObjectDollarSetFieldUnknownArgumentLocation4161102Location4161103Location4161104Location4161105Location4161106Location4161107Location4161108Location4161109Location4341107Location4890500Location4940400Location4940401Location5107200Location5107201Location5166301Location5166302Location5166303Location5166304Location5166305Location5166307Location5166400Location5166401Location5166402Location5166403Location5166404Location5166405Location5166406Location5339900Location5339901Location5339902Location5371601Location5371602Location5371603Location5423900Location5423901Location5423902Location5429413Location5429414Location5429513Location5439007Location5439008Location5439009Location5439010Location5439012Location5439013Location5439014Location5439015Location5439016Location5439017Location5439018Location5490710Location5624900Location5624901Location5626500Location5654600Location5654601Location5654602Location5687301Location5687302Location5687400Location5687401Location5733201Location5733401Location5733402Location5733403Location5733406Location5733408Location5733409Location5739101Location5746102Location5787801Location5787900Location5787901Location5787902Location5787903Location5787906Location5787907Location5787908Location5788001Location5788002Location5788003Location5788004Location5788005Location5788200Location5788604Location5858203Location5860402Location5876900Location5897900Location5946802Location5976500Location6007200Location6045000Location6050106Location6050202Location6050204Location6053600Location6586400Location7429703Location7436100Location7555701Location7555702Location7749501Location7750301Location7750302Location7750303Location8993000"
+const _Code_name = "UnsetInternalErrorBadValueGraphContainsCycleFailedToParseUserNotFoundUnsupportedFormatUnauthorizedTypeMismatchOverflowInvalidLengthProtocolErrorAuthenticationFailedIllegalOperationAlreadyInitializedNamespaceNotFoundIndexNotFoundPathNotViableRoleNotFoundCannotBackfillArrayConflictingUpdateOperatorsCursorNotFoundNamespaceExistsMaxTimeMSExpiredDollarPrefixedFieldNameCanNotBeTypeArrayNotSingleValueFieldLocation55EmptyFieldNameDottedFieldNameCommandNotFoundShardKeyNotFoundImmutableFieldCannotCreateIndexIndexAlreadyExistsInvalidOptionsInvalidNamespaceIndexOptionsConflictIndexKeySpecsConflictOperationFailedNotExactValueFieldWriteConflictCommandNotSupportedConflictingOperationInProgressNamespaceNotShardedDocumentFailedValidationExceededMemoryLimitDurationOverflowViewDepthLimitExceededCommandNotSupportedOnViewOptionNotSupportedOnViewAmbiguousIndexKeyPatternClientMetadataCannotBeMutatedInvalidIndexSpecificationOptionInvalidUUIDQueryFeatureNotAllowedMaxSubPipelineDepthExceededNotImplementedConversionFailureOperationNotSupportedInTransactionIndexBuildAbortedUnableToFindIndexMechanismUnavailableUnsupportedOpQueryCommandCollectionUUIDMismatchUserCountLimitExceededLocation10065BsonObjectTooLargeDuplicateKeyBackgroundOperationInProgressForNamespaceLocation13026Location13027Location13068Location13111MergeStageNoMatchingDocumentDbAlreadyExistsLocation13548Location15947Location15952Location15955Location15957Location15958Location15959Location15972Location15976Location15981Location15998Location16004Location16006Location16007Location16020Location16034Location16035Location16410Location16411Location16433DollarAddNumericOrDateTypesDollarModByZeroProhibitedDollarModOnlyNumericDollarAddOnlyOneDateLocation16702Location16747Location16748Location16749Location16755Location16764HashedIndexDoNotSupportArrayValuesLocation16800Location16801Location16804Location16874Location16875Location16876Location16878Location16879Location16880Location16882Location16883Location16979Location16990Location16994Location17040Location17041Location17042Location17043Location17044Location17045Location17046Location17047Location17048Location17049Location17053DollarCondMissingIfParameterDollarCondMissingThenParameterDollarCondMissingElseParameterDollarCondBadParameterDollarSizeRequiresArrayExactlyOneTextIndexLocation17217Location17261Location17276Location17308Location17310DocumentAfterUpdateLargerThanMaxSizeDocumentToUpsertLargerThanMaxSizeLocation18533Location18534Location18535Location18536Location18537Location18628Location18629Location28625Location28646Location28647Location28648Location28650Location28651Location28656Location28657Location28664RangeArgumentExpressionArgsOutOfRangeDollarAbsCantTakeLongMinValueArrayOperatorElemAtFirstArgMustBeArrayDollarArrayElemAtSecondArgArgMustBeNumericDollarArrayElemAtSecondArgArgMustBe32BitDollarSqrtGreaterOrEqualToZeroDollarSliceInvalidInputDollarSliceInvalidTypeSecondArgDollarSliceInvalidValueSecondArgDollarSliceInvalidTypeThirdArgDollarSliceInvalidValueThirdArgDollarSliceInvalidSignThirdArgLocation28745Location28746Location28747Location28748Location28749DollarLogArgumentMustBeNumericDollarLogBaseMustBeNumericDollarLogNumberMustBePositiveDollarLogBaseMustBeGreaterThanOneDollarLog10MustBePositiveNumberDollarPowBaseMustBeNumericDollarPowExponentMustBeNumericDollarPowExponentInvalidForZeroBaseLocation28765DollarLnMustBePositiveNumberLocation28769Location28803Location28808Location28809Location28810Location28811Location28812Location28818Location28822Location31002Location31022Location31023Location31024KeyCannotContainNullByteLocation31034Location31095Location31109Location31119Location31120Location31138Location31170Location31249Location31250Location31253Location31254Location31256Location31271Location31276Location31308Location31325Location31393Location31394Location31395Location31441Location31465Location34435Location34443Location34444Location34445Location34446Location34447Location34448Location34449Location34450Location34451Location34452Location34453Location34454Location34455Location34460Location34461Location34462Location34463Location34464Location34465Location34466Location34467Location34468Location34471Location34473DollarSwitchRequiresObjectDollarSwitchRequiresArrayForBranchesDollarSwitchRequiresObjectForEachBranchDollarSwitchUnknownArgumentForBranchDollarSwitchRequiresCaseExpressionForBranchDollarSwitchRequiresThenExpressionForBranchDollarSwitchNoMatchingBranchAndNoDefaultDollarSwitchBadArgumentDollarSwitchRequiresAtLeastOneBranchLocation40075Location40076Location40077Location40078Location40079Location40080DollarInRequiresArrayLocation40085Location40086Location40087Location40090Location40091Location40092Location40093Location40094Location40096Location40097Location40100Location40101Location40102Location40103Location40104Location40105Location40147Location40156Location40158Location40160Location40169Location40177Location40181Location40185Location40191Location40192Location40193Location40194Location40195Location40196Location40197Location40198Location40199Location40200Location40201Location40202Location40218Location40228Location40229Location40234Location40235Location40236Location40237Location40238Location40272Location40319Location40321Location40323UnrecognizedCommandLocation40352Location40353DollarArrayToObjectRequiresArrayDollarObjectToArrayRequiresObjectDollarArrayToObjectAllMustBeObjectsDollarArrayToObjectIncorrectNumberOfKeysDollarArrayToObjectRequiresObjectWithKAndVDollarArrayToObjectObjectKeyMustBeStringDollarArrayToObjectArrayKeyMustBeStringDollarArrayToObjectAllMustBeArraysDollarArrayToObjectIncorrectArrayLengthDollarArrayToObjectBadInputTypeFormatDollarMergeObjectsInvalidTypeLocation40414UnknownBsonFieldLocation40485Location40489Location40515Location40516Location40517Location40518Location40519Location40520Location40521Location40522Location40523Location40524Location40525Location40533Location40535Location40536Location40539Location40540Location40541Location40542Location40600Location40601Location40602Location40603Location40621ChangeStreamBadResumeTokenLocation40684InsufficientPrivilegeLocation50687Location50692Location50694Location50695Location50696Location50699Location50700Location50723Location50752Location50759Location50840Location50989Location51003Location51024Location51044Location51045Location51047Location51074Location51075DollarRoundOverflowInt64DollarRoundFirstArgMustBeNumericDollarRoundPrecisionMustBeIntegralDollarRoundPrecisionOutOfRangeLocation51091Location51103Location51104Location51105Location51106Location51107Location51108Location51109Location51110Location51111Location51132Location51134Location51151Location51156Location51173Location51174Location51178Location51183Location51185Location51186Location51187Location51191Location51246Location51247Location51276Location51743Location51744Location51745Location51746Location51747Location51748Location51749Location51750Location51751Location327391Location327392Location605001DollarIfNullRequiresAtLeastTwoArgsLocation2942500Location2942501Location2942502Location2942503Location2942504Location2942505Location2942506DollarRandNonEmptyArgumentLocation3041701Location3041702Location3041703Location3041704IntermediateResultTooLargeDollarSetFieldRequiresObjectDollarSetFieldUnknownArgumentLocation4161102Location4161103Location4161104Location4161105Location4161106Location4161107Location4161108Location4161109Location4341107Location4890500Location4940400Location4940401Location5107200Location5107201Location5166301Location5166302Location5166303Location5166304Location5166305Location5166307Location5166400Location5166401Location5166402Location5166403Location5166404Location5166405Location5166406Location5339900Location5339901Location5339902Location5371601Location5371602Location5371603Location5423900Location5423901Location5423902Location5429413Location5429414Location5429513Location5439007Location5439008Location5439009Location5439010Location5439012Location5439013Location5439014Location5439015Location5439016Location5439017Location5439018Location5490710Location5624900Location5624901Location5626500Location5654600Location5654601Location5654602Location5687301Location5687302Location5687400Location5687401Location5733201Location5733401Location5733402Location5733403Location5733406Location5733408Location5733409Location5739101Location5746102Location5787801Location5787900Location5787901Location5787902Location5787903Location5787906Location5787907Location5787908Location5788001Location5788002Location5788003Location5788004Location5788005Location5788200Location5788604Location5858203Location5860402Location5876900Location5897900Location5946802Location5976500Location6007200Location6045000Location6050106Location6050202Location6050204Location6053600Location6586400Location7369100Location7429703Location7436100Location7555701Location7555702Location7749501Location7750301Location7750302Location7750303Location8993000"
 
 var _Code_map = map[Code]string{
 	0:       _Code_name[0:5],
@@ -586,497 +594,505 @@ func _() {
 	86:      _Code_name[571:592],
 	96:      _Code_name[592:607],
 	111:     _Code_name[607:625],
-	115:     _Code_name[625:644],
-	118:     _Code_name[644:663],
-	121:     _Code_name[663:687],
-	146:     _Code_name[687:706],
-	159:     _Code_name[706:722],
-	165:     _Code_name[722:744],
-	166:     _Code_name[744:769],
-	167:     _Code_name[769:793],
-	181:     _Code_name[793:817],
-	186:     _Code_name[817:846],
-	197:     _Code_name[846:877],
-	207:     _Code_name[877:888],
-	224:     _Code_name[888:910],
-	232:     _Code_name[910:937],
-	238:     _Code_name[937:951],
-	241:     _Code_name[951:968],
-	263:     _Code_name[968:1002],
-	276:     _Code_name[1002:1019],
-	291:     _Code_name[1019:1036],
-	334:     _Code_name[1036:1056],
-	352:     _Code_name[1056:1081],
-	361:     _Code_name[1081:1103],
-	8000:    _Code_name[1103:1125],
-	10065:   _Code_name[1125:1138],
-	10334:   _Code_name[1138:1156],
-	11000:   _Code_name[1156:1168],
-	12587:   _Code_name[1168:1209],
-	13026:   _Code_name[1209:1222],
-	13027:   _Code_name[1222:1235],
-	13068:   _Code_name[1235:1248],
-	13111:   _Code_name[1248:1261],
-	13113:   _Code_name[1261:1289],
-	13297:   _Code_name[1289:1304],
-	13548:   _Code_name[1304:1317],
-	15947:   _Code_name[1317:1330],
-	15952:   _Code_name[1330:1343],
-	15955:   _Code_name[1343:1356],
-	15957:   _Code_name[1356:1369],
-	15958:   _Code_name[1369:1382],
-	15959:   _Code_name[1382:1395],
-	15972:   _Code_name[1395:1408],
-	15976:   _Code_name[1408:1421],
-	15981:   _Code_name[1421:1434],
-	15998:   _Code_name[1434:1447],
-	16004:   _Code_name[1447:1460],
-	16006:   _Code_name[1460:1473],
-	16007:   _Code_name[1473:1486],
-	16020:   _Code_name[1486:1499],
-	16034:   _Code_name[1499:1512],
-	16035:   _Code_name[1512:1525],
-	16410:   _Code_name[1525:1538],
-	16411:   _Code_name[1538:1551],
-	16433:   _Code_name[1551:1564],
-	16554:   _Code_name[1564:1591],
-	16610:   _Code_name[1591:1616],
-	16611:   _Code_name[1616:1636],
-	16612:   _Code_name[1636:1656],
-	16702:   _Code_name[1656:1669],
-	16747:   _Code_name[1669:1682],
-	16748:   _Code_name[1682:1695],
-	16749:   _Code_name[1695:1708],
-	16755:   _Code_name[1708:1721],
-	16764:   _Code_name[1721:1734],
-	16766:   _Code_name[1734:1768],
-	16800:   _Code_name[1768:1781],
-	16801:   _Code_name[1781:1794],
-	16804:   _Code_name[1794:1807],
-	16874:   _Code_name[1807:1820],
-	16875:   _Code_name[1820:1833],
-	16876:   _Code_name[1833:1846],
-	16878:   _Code_name[1846:1859],
-	16879:   _Code_name[1859:1872],
-	16880:   _Code_name[1872:1885],
-	16882:   _Code_name[1885:1898],
-	16883:   _Code_name[1898:1911],
-	16979:   _Code_name[1911:1924],
-	16990:   _Code_name[1924:1937],
-	16994:   _Code_name[1937:1950],
-	17040:   _Code_name[1950:1963],
-	17041:   _Code_name[1963:1976],
-	17042:   _Code_name[1976:1989],
-	17043:   _Code_name[1989:2002],
-	17044:   _Code_name[2002:2015],
-	17045:   _Code_name[2015:2028],
-	17046:   _Code_name[2028:2041],
-	17047:   _Code_name[2041:2054],
-	17048:   _Code_name[2054:2067],
-	17049:   _Code_name[2067:2080],
-	17053:   _Code_name[2080:2093],
-	17080:   _Code_name[2093:2121],
-	17081:   _Code_name[2121:2151],
-	17082:   _Code_name[2151:2181],
-	17083:   _Code_name[2181:2203],
-	17124:   _Code_name[2203:2226],
-	17194:   _Code_name[2226:2245],
-	17261:   _Code_name[2245:2258],
-	17276:   _Code_name[2258:2271],
-	17308:   _Code_name[2271:2284],
-	17310:   _Code_name[2284:2297],
-	17419:   _Code_name[2297:2333],
-	17420:   _Code_name[2333:2366],
-	18533:   _Code_name[2366:2379],
-	18534:   _Code_name[2379:2392],
-	18535:   _Code_name[2392:2405],
-	18536:   _Code_name[2405:2418],
-	18537:   _Code_name[2418:2431],
-	18628:   _Code_name[2431:2444],
-	18629:   _Code_name[2444:2457],
-	28625:   _Code_name[2457:2470],
-	28646:   _Code_name[2470:2483],
-	28647:   _Code_name[2483:2496],
-	28648:   _Code_name[2496:2509],
-	28650:   _Code_name[2509:2522],
-	28651:   _Code_name[2522:2535],
-	28656:   _Code_name[2535:2548],
-	28657:   _Code_name[2548:2561],
-	28664:   _Code_name[2561:2574],
-	28667:   _Code_name[2574:2611],
-	28680:   _Code_name[2611:2640],
-	28689:   _Code_name[2640:2678],
-	28690:   _Code_name[2678:2720],
-	28691:   _Code_name[2720:2760],
-	28714:   _Code_name[2760:2790],
-	28724:   _Code_name[2790:2813],
-	28725:   _Code_name[2813:2844],
-	28726:   _Code_name[2844:2876],
-	28727:   _Code_name[2876:2906],
-	28728:   _Code_name[2906:2937],
-	28729:   _Code_name[2937:2967],
-	28745:   _Code_name[2967:2980],
-	28746:   _Code_name[2980:2993],
-	28747:   _Code_name[2993:3006],
-	28748:   _Code_name[3006:3019],
-	28749:   _Code_name[3019:3032],
-	28756:   _Code_name[3032:3062],
-	28757:   _Code_name[3062:3088],
-	28758:   _Code_name[3088:3117],
-	28759:   _Code_name[3117:3150],
-	28761:   _Code_name[3150:3181],
-	28762:   _Code_name[3181:3207],
-	28763:   _Code_name[3207:3237],
-	28764:   _Code_name[3237:3272],
-	28765:   _Code_name[3272:3285],
-	28766:   _Code_name[3285:3313],
-	28769:   _Code_name[3313:3326],
-	28803:   _Code_name[3326:3339],
-	28808:   _Code_name[3339:3352],
-	28809:   _Code_name[3352:3365],
-	28810:   _Code_name[3365:3378],
-	28811:   _Code_name[3378:3391],
-	28812:   _Code_name[3391:3404],
-	28818:   _Code_name[3404:3417],
-	28822:   _Code_name[3417:3430],
-	31002:   _Code_name[3430:3443],
-	31022:   _Code_name[3443:3456],
-	31023:   _Code_name[3456:3469],
-	31024:   _Code_name[3469:3482],
-	31032:   _Code_name[3482:3506],
-	31034:   _Code_name[3506:3519],
-	31095:   _Code_name[3519:3532],
-	31109:   _Code_name[3532:3545],
-	31119:   _Code_name[3545:3558],
-	31120:   _Code_name[3558:3571],
-	31138:   _Code_name[3571:3584],
-	31170:   _Code_name[3584:3597],
-	31249:   _Code_name[3597:3610],
-	31250:   _Code_name[3610:3623],
-	31253:   _Code_name[3623:3636],
-	31254:   _Code_name[3636:3649],
-	31256:   _Code_name[3649:3662],
-	31271:   _Code_name[3662:3675],
-	31276:   _Code_name[3675:3688],
-	31308:   _Code_name[3688:3701],
-	31325:   _Code_name[3701:3714],
-	31393:   _Code_name[3714:3727],
-	31395:   _Code_name[3727:3740],
-	31441:   _Code_name[3740:3753],
-	31465:   _Code_name[3753:3766],
-	34435:   _Code_name[3766:3779],
-	34443:   _Code_name[3779:3792],
-	34444:   _Code_name[3792:3805],
-	34445:   _Code_name[3805:3818],
-	34446:   _Code_name[3818:3831],
-	34447:   _Code_name[3831:3844],
-	34448:   _Code_name[3844:3857],
-	34449:   _Code_name[3857:3870],
-	34450:   _Code_name[3870:3883],
-	34451:   _Code_name[3883:3896],
-	34452:   _Code_name[3896:3909],
-	34453:   _Code_name[3909:3922],
-	34454:   _Code_name[3922:3935],
-	34455:   _Code_name[3935:3948],
-	34460:   _Code_name[3948:3961],
-	34461:   _Code_name[3961:3974],
-	34462:   _Code_name[3974:3987],
-	34463:   _Code_name[3987:4000],
-	34464:   _Code_name[4000:4013],
-	34465:   _Code_name[4013:4026],
-	34466:   _Code_name[4026:4039],
-	34467:   _Code_name[4039:4052],
-	34468:   _Code_name[4052:4065],
-	34471:   _Code_name[4065:4078],
-	34473:   _Code_name[4078:4091],
-	40060:   _Code_name[4091:4117],
-	40061:   _Code_name[4117:4153],
-	40062:   _Code_name[4153:4192],
-	40063:   _Code_name[4192:4228],
-	40064:   _Code_name[4228:4271],
-	40065:   _Code_name[4271:4314],
-	40066:   _Code_name[4314:4354],
-	40067:   _Code_name[4354:4377],
-	40068:   _Code_name[4377:4413],
-	40075:   _Code_name[4413:4426],
-	40076:   _Code_name[4426:4439],
-	40077:   _Code_name[4439:4452],
-	40078:   _Code_name[4452:4465],
-	40079:   _Code_name[4465:4478],
-	40080:   _Code_name[4478:4491],
-	40081:   _Code_name[4491:4512],
-	40085:   _Code_name[4512:4525],
-	40086:   _Code_name[4525:4538],
-	40087:   _Code_name[4538:4551],
-	40090:   _Code_name[4551:4564],
-	40091:   _Code_name[4564:4577],
-	40092:   _Code_name[4577:4590],
-	40093:   _Code_name[4590:4603],
-	40094:   _Code_name[4603:4616],
-	40096:   _Code_name[4616:4629],
-	40097:   _Code_name[4629:4642],
-	40100:   _Code_name[4642:4655],
-	40101:   _Code_name[4655:4668],
-	40102:   _Code_name[4668:4681],
-	40103:   _Code_name[4681:4694],
-	40104:   _Code_name[4694:4707],
-	40105:   _Code_name[4707:4720],
-	40147:   _Code_name[4720:4733],
-	40156:   _Code_name[4733:4746],
-	40158:   _Code_name[4746:4759],
-	40160:   _Code_name[4759:4772],
-	40169:   _Code_name[4772:4785],
-	40177:   _Code_name[4785:4798],
-	40181:   _Code_name[4798:4811],
-	40185:   _Code_name[4811:4824],
-	40191:   _Code_name[4824:4837],
-	40192:   _Code_name[4837:4850],
-	40193:   _Code_name[4850:4863],
-	40194:   _Code_name[4863:4876],
-	40195:   _Code_name[4876:4889],
-	40196:   _Code_name[4889:4902],
-	40197:   _Code_name[4902:4915],
-	40198:   _Code_name[4915:4928],
-	40199:   _Code_name[4928:4941],
-	40200:   _Code_name[4941:4954],
-	40201:   _Code_name[4954:4967],
-	40202:   _Code_name[4967:4980],
-	40218:   _Code_name[4980:4993],
-	40228:   _Code_name[4993:5006],
-	40229:   _Code_name[5006:5019],
-	40234:   _Code_name[5019:5032],
-	40235:   _Code_name[5032:5045],
-	40236:   _Code_name[5045:5058],
-	40237:   _Code_name[5058:5071],
-	40238:   _Code_name[5071:5084],
-	40272:   _Code_name[5084:5097],
-	40319:   _Code_name[5097:5110],
-	40321:   _Code_name[5110:5123],
-	40323:   _Code_name[5123:5136],
-	40324:   _Code_name[5136:5155],
-	40352:   _Code_name[5155:5168],
-	40386:   _Code_name[5168:5200],
-	40390:   _Code_name[5200:5233],
-	40391:   _Code_name[5233:5268],
-	40392:   _Code_name[5268:5308],
-	40393:   _Code_name[5308:5350],
-	40394:   _Code_name[5350:5390],
-	40395:   _Code_name[5390:5429],
-	40396:   _Code_name[5429:5463],
-	40397:   _Code_name[5463:5502],
-	40398:   _Code_name[5502:5539],
-	40400:   _Code_name[5539:5568],
-	40414:   _Code_name[5568:5581],
-	40415:   _Code_name[5581:5597],
-	40485:   _Code_name[5597:5610],
-	40489:   _Code_name[5610:5623],
-	40515:   _Code_name[5623:5636],
-	40516:   _Code_name[5636:5649],
-	40517:   _Code_name[5649:5662],
-	40518:   _Code_name[5662:5675],
-	40519:   _Code_name[5675:5688],
-	40520:   _Code_name[5688:5701],
-	40521:   _Code_name[5701:5714],
-	40522:   _Code_name[5714:5727],
-	40523:   _Code_name[5727:5740],
-	40524:   _Code_name[5740:5753],
-	40525:   _Code_name[5753:5766],
-	40533:   _Code_name[5766:5779],
-	40535:   _Code_name[5779:5792],
-	40536:   _Code_name[5792:5805],
-	40539:   _Code_name[5805:5818],
-	40540:   _Code_name[5818:5831],
-	40541:   _Code_name[5831:5844],
-	40542:   _Code_name[5844:5857],
-	40600:   _Code_name[5857:5870],
-	40601:   _Code_name[5870:5883],
-	40602:   _Code_name[5883:5896],
-	40603:   _Code_name[5896:5909],
-	40621:   _Code_name[5909:5922],
-	40647:   _Code_name[5922:5948],
-	40684:   _Code_name[5948:5961],
-	42501:   _Code_name[5961:5982],
-	50687:   _Code_name[5982:5995],
-	50692:   _Code_name[5995:6008],
-	50694:   _Code_name[6008:6021],
-	50695:   _Code_name[6021:6034],
-	50696:   _Code_name[6034:6047],
-	50699:   _Code_name[6047:6060],
-	50700:   _Code_name[6060:6073],
-	50723:   _Code_name[6073:6086],
-	50752:   _Code_name[6086:6099],
-	50759:   _Code_name[6099:6112],
-	50840:   _Code_name[6112:6125],
-	50989:   _Code_name[6125:6138],
-	51003:   _Code_name[6138:6151],
-	51024:   _Code_name[6151:6164],
-	51044:   _Code_name[6164:6177],
-	51045:   _Code_name[6177:6190],
-	51047:   _Code_name[6190:6203],
-	51074:   _Code_name[6203:6216],
-	51075:   _Code_name[6216:6229],
-	51080:   _Code_name[6229:6253],
-	51081:   _Code_name[6253:6285],
-	51082:   _Code_name[6285:6319],
-	51083:   _Code_name[6319:6349],
-	51091:   _Code_name[6349:6362],
-	51103:   _Code_name[6362:6375],
-	51104:   _Code_name[6375:6388],
-	51105:   _Code_name[6388:6401],
-	51106:   _Code_name[6401:6414],
-	51107:   _Code_name[6414:6427],
-	51108:   _Code_name[6427:6440],
-	51109:   _Code_name[6440:6453],
-	51110:   _Code_name[6453:6466],
-	51111:   _Code_name[6466:6479],
-	51132:   _Code_name[6479:6492],
-	51134:   _Code_name[6492:6505],
-	51151:   _Code_name[6505:6518],
-	51156:   _Code_name[6518:6531],
-	51178:   _Code_name[6531:6544],
-	51183:   _Code_name[6544:6557],
-	51185:   _Code_name[6557:6570],
-	51186:   _Code_name[6570:6583],
-	51187:   _Code_name[6583:6596],
-	51191:   _Code_name[6596:6609],
-	51246:   _Code_name[6609:6622],
-	51247:   _Code_name[6622:6635],
-	51276:   _Code_name[6635:6648],
-	51743:   _Code_name[6648:6661],
-	51744:   _Code_name[6661:6674],
-	51745:   _Code_name[6674:6687],
-	51746:   _Code_name[6687:6700],
-	51747:   _Code_name[6700:6713],
-	51748:   _Code_name[6713:6726],
-	51749:   _Code_name[6726:6739],
-	51750:   _Code_name[6739:6752],
-	51751:   _Code_name[6752:6765],
-	327391:  _Code_name[6765:6779],
-	327392:  _Code_name[6779:6793],
-	605001:  _Code_name[6793:6807],
-	1257300: _Code_name[6807:6841],
-	2942500: _Code_name[6841:6856],
-	2942501: _Code_name[6856:6871],
-	2942502: _Code_name[6871:6886],
-	2942503: _Code_name[6886:6901],
-	2942504: _Code_name[6901:6916],
-	2942505: _Code_name[6916:6931],
-	2942506: _Code_name[6931:6946],
-	3040501: _Code_name[6946:6972],
-	3041701: _Code_name[6972:6987],
-	3041702: _Code_name[6987:7002],
-	3041703: _Code_name[7002:7017],
-	3041704: _Code_name[7017:7032],
-	4031700: _Code_name[7032:7058],
-	4161100: _Code_name[7058:7086],
-	4161101: _Code_name[7086:7115],
-	4161102: _Code_name[7115:7130],
-	4161103: _Code_name[7130:7145],
-	4161104: _Code_name[7145:7160],
-	4161105: _Code_name[7160:7175],
-	4161106: _Code_name[7175:7190],
-	4161107: _Code_name[7190:7205],
-	4161108: _Code_name[7205:7220],
-	4161109: _Code_name[7220:7235],
-	4341107: _Code_name[7235:7250],
-	4890500: _Code_name[7250:7265],
-	4940400: _Code_name[7265:7280],
-	4940401: _Code_name[7280:7295],
-	5107200: _Code_name[7295:7310],
-	5107201: _Code_name[7310:7325],
-	5166301: _Code_name[7325:7340],
-	5166302: _Code_name[7340:7355],
-	5166303: _Code_name[7355:7370],
-	5166304: _Code_name[7370:7385],
-	5166305: _Code_name[7385:7400],
-	5166307: _Code_name[7400:7415],
-	5166400: _Code_name[7415:7430],
-	5166401: _Code_name[7430:7445],
-	5166402: _Code_name[7445:7460],
-	5166403: _Code_name[7460:7475],
-	5166404: _Code_name[7475:7490],
-	5166405: _Code_name[7490:7505],
-	5166406: _Code_name[7505:7520],
-	5339900: _Code_name[7520:7535],
-	5339901: _Code_name[7535:7550],
-	5339902: _Code_name[7550:7565],
-	5371601: _Code_name[7565:7580],
-	5371602: _Code_name[7580:7595],
-	5371603: _Code_name[7595:7610],
-	5423900: _Code_name[7610:7625],
-	5423901: _Code_name[7625:7640],
-	5423902: _Code_name[7640:7655],
-	5429413: _Code_name[7655:7670],
-	5429414: _Code_name[7670:7685],
-	5429513: _Code_name[7685:7700],
-	5439007: _Code_name[7700:7715],
-	5439008: _Code_name[7715:7730],
-	5439009: _Code_name[7730:7745],
-	5439010: _Code_name[7745:7760],
-	5439012: _Code_name[7760:7775],
-	5439013: _Code_name[7775:7790],
-	5439014: _Code_name[7790:7805],
-	5439015: _Code_name[7805:7820],
-	5439016: _Code_name[7820:7835],
-	5439017: _Code_name[7835:7850],
-	5439018: _Code_name[7850:7865],
-	5490710: _Code_name[7865:7880],
-	5624900: _Code_name[7880:7895],
-	5624901: _Code_name[7895:7910],
-	5626500: _Code_name[7910:7925],
-	5654600: _Code_name[7925:7940],
-	5654601: _Code_name[7940:7955],
-	5654602: _Code_name[7955:7970],
-	5687301: _Code_name[7970:7985],
-	5687302: _Code_name[7985:8000],
-	5687400: _Code_name[8000:8015],
-	5687401: _Code_name[8015:8030],
-	5733201: _Code_name[8030:8045],
-	5733401: _Code_name[8045:8060],
-	5733402: _Code_name[8060:8075],
-	5733403: _Code_name[8075:8090],
-	5733406: _Code_name[8090:8105],
-	5733408: _Code_name[8105:8120],
-	5733409: _Code_name[8120:8135],
-	5739101: _Code_name[8135:8150],
-	5746102: _Code_name[8150:8165],
-	5787801: _Code_name[8165:8180],
-	5787900: _Code_name[8180:8195],
-	5787901: _Code_name[8195:8210],
-	5787902: _Code_name[8210:8225],
-	5787903: _Code_name[8225:8240],
-	5787906: _Code_name[8240:8255],
-	5787907: _Code_name[8255:8270],
-	5787908: _Code_name[8270:8285],
-	5788001: _Code_name[8285:8300],
-	5788002: _Code_name[8300:8315],
-	5788003: _Code_name[8315:8330],
-	5788004: _Code_name[8330:8345],
-	5788005: _Code_name[8345:8360],
-	5788200: _Code_name[8360:8375],
-	5788604: _Code_name[8375:8390],
-	5858203: _Code_name[8390:8405],
-	5860402: _Code_name[8405:8420],
-	5876900: _Code_name[8420:8435],
-	5897900: _Code_name[8435:8450],
-	5946802: _Code_name[8450:8465],
-	5976500: _Code_name[8465:8480],
-	6007200: _Code_name[8480:8495],
-	6045000: _Code_name[8495:8510],
-	6050106: _Code_name[8510:8525],
-	6050202: _Code_name[8525:8540],
-	6050204: _Code_name[8540:8555],
-	6053600: _Code_name[8555:8570],
-	6586400: _Code_name[8570:8585],
-	7429703: _Code_name[8585:8600],
-	7436100: _Code_name[8600:8615],
-	7555701: _Code_name[8615:8630],
-	7555702: _Code_name[8630:8645],
-	7749501: _Code_name[8645:8660],
-	7750301: _Code_name[8660:8675],
-	7750302: _Code_name[8675:8690],
-	7750303: _Code_name[8690:8705],
-	8993000: _Code_name[8705:8720],
+	112:     _Code_name[625:638],
+	115:     _Code_name[638:657],
+	117:     _Code_name[657:687],
+	118:     _Code_name[687:706],
+	121:     _Code_name[706:730],
+	146:     _Code_name[730:749],
+	159:     _Code_name[749:765],
+	165:     _Code_name[765:787],
+	166:     _Code_name[787:812],
+	167:     _Code_name[812:836],
+	181:     _Code_name[836:860],
+	186:     _Code_name[860:889],
+	197:     _Code_name[889:920],
+	207:     _Code_name[920:931],
+	224:     _Code_name[931:953],
+	232:     _Code_name[953:980],
+	238:     _Code_name[980:994],
+	241:     _Code_name[994:1011],
+	263:     _Code_name[1011:1045],
+	276:     _Code_name[1045:1062],
+	291:     _Code_name[1062:1079],
+	334:     _Code_name[1079:1099],
+	352:     _Code_name[1099:1124],
+	361:     _Code_name[1124:1146],
+	8000:    _Code_name[1146:1168],
+	10065:   _Code_name[1168:1181],
+	10334:   _Code_name[1181:1199],
+	11000:   _Code_name[1199:1211],
+	12587:   _Code_name[1211:1252],
+	13026:   _Code_name[1252:1265],
+	13027:   _Code_name[1265:1278],
+	13068:   _Code_name[1278:1291],
+	13111:   _Code_name[1291:1304],
+	13113:   _Code_name[1304:1332],
+	13297:   _Code_name[1332:1347],
+	13548:   _Code_name[1347:1360],
+	15947:   _Code_name[1360:1373],
+	15952:   _Code_name[1373:1386],
+	15955:   _Code_name[1386:1399],
+	15957:   _Code_name[1399:1412],
+	15958:   _Code_name[1412:1425],
+	15959:   _Code_name[1425:1438],
+	15972:   _Code_name[1438:1451],
+	15976:   _Code_name[1451:1464],
+	15981:   _Code_name[1464:1477],
+	15998:   _Code_name[1477:1490],
+	16004:   _Code_name[1490:1503],
+	16006:   _Code_name[1503:1516],
+	16007:   _Code_name[1516:1529],
+	16020:   _Code_name[1529:1542],
+	16034:   _Code_name[1542:1555],
+	16035:   _Code_name[1555:1568],
+	16410:   _Code_name[1568:1581],
+	16411:   _Code_name[1581:1594],
+	16433:   _Code_name[1594:1607],
+	16554:   _Code_name[1607:1634],
+	16610:   _Code_name[1634:1659],
+	16611:   _Code_name[1659:1679],
+	16612:   _Code_name[1679:1699],
+	16702:   _Code_name[1699:1712],
+	16747:   _Code_name[1712:1725],
+	16748:   _Code_name[1725:1738],
+	16749:   _Code_name[1738:1751],
+	16755:   _Code_name[1751:1764],
+	16764:   _Code_name[1764:1777],
+	16766:   _Code_name[1777:1811],
+	16800:   _Code_name[1811:1824],
+	16801:   _Code_name[1824:1837],
+	16804:   _Code_name[1837:1850],
+	16874:   _Code_name[1850:1863],
+	16875:   _Code_name[1863:1876],
+	16876:   _Code_name[1876:1889],
+	16878:   _Code_name[1889:1902],
+	16879:   _Code_name[1902:1915],
+	16880:   _Code_name[1915:1928],
+	16882:   _Code_name[1928:1941],
+	16883:   _Code_name[1941:1954],
+	16979:   _Code_name[1954:1967],
+	16990:   _Code_name[1967:1980],
+	16994:   _Code_name[1980:1993],
+	17040:   _Code_name[1993:2006],
+	17041:   _Code_name[2006:2019],
+	17042:   _Code_name[2019:2032],
+	17043:   _Code_name[2032:2045],
+	17044:   _Code_name[2045:2058],
+	17045:   _Code_name[2058:2071],
+	17046:   _Code_name[2071:2084],
+	17047:   _Code_name[2084:2097],
+	17048:   _Code_name[2097:2110],
+	17049:   _Code_name[2110:2123],
+	17053:   _Code_name[2123:2136],
+	17080:   _Code_name[2136:2164],
+	17081:   _Code_name[2164:2194],
+	17082:   _Code_name[2194:2224],
+	17083:   _Code_name[2224:2246],
+	17124:   _Code_name[2246:2269],
+	17194:   _Code_name[2269:2288],
+	17217:   _Code_name[2288:2301],
+	17261:   _Code_name[2301:2314],
+	17276:   _Code_name[2314:2327],
+	17308:   _Code_name[2327:2340],
+	17310:   _Code_name[2340:2353],
+	17419:   _Code_name[2353:2389],
+	17420:   _Code_name[2389:2422],
+	18533:   _Code_name[2422:2435],
+	18534:   _Code_name[2435:2448],
+	18535:   _Code_name[2448:2461],
+	18536:   _Code_name[2461:2474],
+	18537:   _Code_name[2474:2487],
+	18628:   _Code_name[2487:2500],
+	18629:   _Code_name[2500:2513],
+	28625:   _Code_name[2513:2526],
+	28646:   _Code_name[2526:2539],
+	28647:   _Code_name[2539:2552],
+	28648:   _Code_name[2552:2565],
+	28650:   _Code_name[2565:2578],
+	28651:   _Code_name[2578:2591],
+	28656:   _Code_name[2591:2604],
+	28657:   _Code_name[2604:2617],
+	28664:   _Code_name[2617:2630],
+	28667:   _Code_name[2630:2667],
+	28680:   _Code_name[2667:2696],
+	28689:   _Code_name[2696:2734],
+	28690:   _Code_name[2734:2776],
+	28691:   _Code_name[2776:2816],
+	28714:   _Code_name[2816:2846],
+	28724:   _Code_name[2846:2869],
+	28725:   _Code_name[2869:2900],
+	28726:   _Code_name[2900:2932],
+	28727:   _Code_name[2932:2962],
+	28728:   _Code_name[2962:2993],
+	28729:   _Code_name[2993:3023],
+	28745:   _Code_name[3023:3036],
+	28746:   _Code_name[3036:3049],
+	28747:   _Code_name[3049:3062],
+	28748:   _Code_name[3062:3075],
+	28749:   _Code_name[3075:3088],
+	28756:   _Code_name[3088:3118],
+	28757:   _Code_name[3118:3144],
+	28758:   _Code_name[3144:3173],
+	28759:   _Code_name[3173:3206],
+	28761:   _Code_name[3206:3237],
+	28762:   _Code_name[3237:3263],
+	28763:   _Code_name[3263:3293],
+	28764:   _Code_name[3293:3328],
+	28765:   _Code_name[3328:3341],
+	28766:   _Code_name[3341:3369],
+	28769:   _Code_name[3369:3382],
+	28803:   _Code_name[3382:3395],
+	28808:   _Code_name[3395:3408],
+	28809:   _Code_name[3408:3421],
+	28810:   _Code_name[3421:3434],
+	28811:   _Code_name[3434:3447],
+	28812:   _Code_name[3447:3460],
+	28818:   _Code_name[3460:3473],
+	28822:   _Code_name[3473:3486],
+	31002:   _Code_name[3486:3499],
+	31022:   _Code_name[3499:3512],
+	31023:   _Code_name[3512:3525],
+	31024:   _Code_name[3525:3538],
+	31032:   _Code_name[3538:3562],
+	31034:   _Code_name[3562:3575],
+	31095:   _Code_name[3575:3588],
+	31109:   _Code_name[3588:3601],
+	31119:   _Code_name[3601:3614],
+	31120:   _Code_name[3614:3627],
+	31138:   _Code_name[3627:3640],
+	31170:   _Code_name[3640:3653],
+	31249:   _Code_name[3653:3666],
+	31250:   _Code_name[3666:3679],
+	31253:   _Code_name[3679:3692],
+	31254:   _Code_name[3692:3705],
+	31256:   _Code_name[3705:3718],
+	31271:   _Code_name[3718:3731],
+	31276:   _Code_name[3731:3744],
+	31308:   _Code_name[3744:3757],
+	31325:   _Code_name[3757:3770],
+	31393:   _Code_name[3770:3783],
+	31394:   _Code_name[3783:3796],
+	31395:   _Code_name[3796:3809],
+	31441:   _Code_name[3809:3822],
+	31465:   _Code_name[3822:3835],
+	34435:   _Code_name[3835:3848],
+	34443:   _Code_name[3848:3861],
+	34444:   _Code_name[3861:3874],
+	34445:   _Code_name[3874:3887],
+	34446:   _Code_name[3887:3900],
+	34447:   _Code_name[3900:3913],
+	34448:   _Code_name[3913:3926],
+	34449:   _Code_name[3926:3939],
+	34450:   _Code_name[3939:3952],
+	34451:   _Code_name[3952:3965],
+	34452:   _Code_name[3965:3978],
+	34453:   _Code_name[3978:3991],
+	34454:   _Code_name[3991:4004],
+	34455:   _Code_name[4004:4017],
+	34460:   _Code_name[4017:4030],
+	34461:   _Code_name[4030:4043],
+	34462:   _Code_name[4043:4056],
+	34463:   _Code_name[4056:4069],
+	34464:   _Code_name[4069:4082],
+	34465:   _Code_name[4082:4095],
+	34466:   _Code_name[4095:4108],
+	34467:   _Code_name[4108:4121],
+	34468:   _Code_name[4121:4134],
+	34471:   _Code_name[4134:4147],
+	34473:   _Code_name[4147:4160],
+	40060:   _Code_name[4160:4186],
+	40061:   _Code_name[4186:4222],
+	40062:   _Code_name[4222:4261],
+	40063:   _Code_name[4261:4297],
+	40064:   _Code_name[4297:4340],
+	40065:   _Code_name[4340:4383],
+	40066:   _Code_name[4383:4423],
+	40067:   _Code_name[4423:4446],
+	40068:   _Code_name[4446:4482],
+	40075:   _Code_name[4482:4495],
+	40076:   _Code_name[4495:4508],
+	40077:   _Code_name[4508:4521],
+	40078:   _Code_name[4521:4534],
+	40079:   _Code_name[4534:4547],
+	40080:   _Code_name[4547:4560],
+	40081:   _Code_name[4560:4581],
+	40085:   _Code_name[4581:4594],
+	40086:   _Code_name[4594:4607],
+	40087:   _Code_name[4607:4620],
+	40090:   _Code_name[4620:4633],
+	40091:   _Code_name[4633:4646],
+	40092:   _Code_name[4646:4659],
+	40093:   _Code_name[4659:4672],
+	40094:   _Code_name[4672:4685],
+	40096:   _Code_name[4685:4698],
+	40097:   _Code_name[4698:4711],
+	40100:   _Code_name[4711:4724],
+	40101:   _Code_name[4724:4737],
+	40102:   _Code_name[4737:4750],
+	40103:   _Code_name[4750:4763],
+	40104:   _Code_name[4763:4776],
+	40105:   _Code_name[4776:4789],
+	40147:   _Code_name[4789:4802],
+	40156:   _Code_name[4802:4815],
+	40158:   _Code_name[4815:4828],
+	40160:   _Code_name[4828:4841],
+	40169:   _Code_name[4841:4854],
+	40177:   _Code_name[4854:4867],
+	40181:   _Code_name[4867:4880],
+	40185:   _Code_name[4880:4893],
+	40191:   _Code_name[4893:4906],
+	40192:   _Code_name[4906:4919],
+	40193:   _Code_name[4919:4932],
+	40194:   _Code_name[4932:4945],
+	40195:   _Code_name[4945:4958],
+	40196:   _Code_name[4958:4971],
+	40197:   _Code_name[4971:4984],
+	40198:   _Code_name[4984:4997],
+	40199:   _Code_name[4997:5010],
+	40200:   _Code_name[5010:5023],
+	40201:   _Code_name[5023:5036],
+	40202:   _Code_name[5036:5049],
+	40218:   _Code_name[5049:5062],
+	40228:   _Code_name[5062:5075],
+	40229:   _Code_name[5075:5088],
+	40234:   _Code_name[5088:5101],
+	40235:   _Code_name[5101:5114],
+	40236:   _Code_name[5114:5127],
+	40237:   _Code_name[5127:5140],
+	40238:   _Code_name[5140:5153],
+	40272:   _Code_name[5153:5166],
+	40319:   _Code_name[5166:5179],
+	40321:   _Code_name[5179:5192],
+	40323:   _Code_name[5192:5205],
+	40324:   _Code_name[5205:5224],
+	40352:   _Code_name[5224:5237],
+	40353:   _Code_name[5237:5250],
+	40386:   _Code_name[5250:5282],
+	40390:   _Code_name[5282:5315],
+	40391:   _Code_name[5315:5350],
+	40392:   _Code_name[5350:5390],
+	40393:   _Code_name[5390:5432],
+	40394:   _Code_name[5432:5472],
+	40395:   _Code_name[5472:5511],
+	40396:   _Code_name[5511:5545],
+	40397:   _Code_name[5545:5584],
+	40398:   _Code_name[5584:5621],
+	40400:   _Code_name[5621:5650],
+	40414:   _Code_name[5650:5663],
+	40415:   _Code_name[5663:5679],
+	40485:   _Code_name[5679:5692],
+	40489:   _Code_name[5692:5705],
+	40515:   _Code_name[5705:5718],
+	40516:   _Code_name[5718:5731],
+	40517:   _Code_name[5731:5744],
+	40518:   _Code_name[5744:5757],
+	40519:   _Code_name[5757:5770],
+	40520:   _Code_name[5770:5783],
+	40521:   _Code_name[5783:5796],
+	40522:   _Code_name[5796:5809],
+	40523:   _Code_name[5809:5822],
+	40524:   _Code_name[5822:5835],
+	40525:   _Code_name[5835:5848],
+	40533:   _Code_name[5848:5861],
+	40535:   _Code_name[5861:5874],
+	40536:   _Code_name[5874:5887],
+	40539:   _Code_name[5887:5900],
+	40540:   _Code_name[5900:5913],
+	40541:   _Code_name[5913:5926],
+	40542:   _Code_name[5926:5939],
+	40600:   _Code_name[5939:5952],
+	40601:   _Code_name[5952:5965],
+	40602:   _Code_name[5965:5978],
+	40603:   _Code_name[5978:5991],
+	40621:   _Code_name[5991:6004],
+	40647:   _Code_name[6004:6030],
+	40684:   _Code_name[6030:6043],
+	42501:   _Code_name[6043:6064],
+	50687:   _Code_name[6064:6077],
+	50692:   _Code_name[6077:6090],
+	50694:   _Code_name[6090:6103],
+	50695:   _Code_name[6103:6116],
+	50696:   _Code_name[6116:6129],
+	50699:   _Code_name[6129:6142],
+	50700:   _Code_name[6142:6155],
+	50723:   _Code_name[6155:6168],
+	50752:   _Code_name[6168:6181],
+	50759:   _Code_name[6181:6194],
+	50840:   _Code_name[6194:6207],
+	50989:   _Code_name[6207:6220],
+	51003:   _Code_name[6220:6233],
+	51024:   _Code_name[6233:6246],
+	51044:   _Code_name[6246:6259],
+	51045:   _Code_name[6259:6272],
+	51047:   _Code_name[6272:6285],
+	51074:   _Code_name[6285:6298],
+	51075:   _Code_name[6298:6311],
+	51080:   _Code_name[6311:6335],
+	51081:   _Code_name[6335:6367],
+	51082:   _Code_name[6367:6401],
+	51083:   _Code_name[6401:6431],
+	51091:   _Code_name[6431:6444],
+	51103:   _Code_name[6444:6457],
+	51104:   _Code_name[6457:6470],
+	51105:   _Code_name[6470:6483],
+	51106:   _Code_name[6483:6496],
+	51107:   _Code_name[6496:6509],
+	51108:   _Code_name[6509:6522],
+	51109:   _Code_name[6522:6535],
+	51110:   _Code_name[6535:6548],
+	51111:   _Code_name[6548:6561],
+	51132:   _Code_name[6561:6574],
+	51134:   _Code_name[6574:6587],
+	51151:   _Code_name[6587:6600],
+	51156:   _Code_name[6600:6613],
+	51173:   _Code_name[6613:6626],
+	51174:   _Code_name[6626:6639],
+	51178:   _Code_name[6639:6652],
+	51183:   _Code_name[6652:6665],
+	51185:   _Code_name[6665:6678],
+	51186:   _Code_name[6678:6691],
+	51187:   _Code_name[6691:6704],
+	51191:   _Code_name[6704:6717],
+	51246:   _Code_name[6717:6730],
+	51247:   _Code_name[6730:6743],
+	51276:   _Code_name[6743:6756],
+	51743:   _Code_name[6756:6769],
+	51744:   _Code_name[6769:6782],
+	51745:   _Code_name[6782:6795],
+	51746:   _Code_name[6795:6808],
+	51747:   _Code_name[6808:6821],
+	51748:   _Code_name[6821:6834],
+	51749:   _Code_name[6834:6847],
+	51750:   _Code_name[6847:6860],
+	51751:   _Code_name[6860:6873],
+	327391:  _Code_name[6873:6887],
+	327392:  _Code_name[6887:6901],
+	605001:  _Code_name[6901:6915],
+	1257300: _Code_name[6915:6949],
+	2942500: _Code_name[6949:6964],
+	2942501: _Code_name[6964:6979],
+	2942502: _Code_name[6979:6994],
+	2942503: _Code_name[6994:7009],
+	2942504: _Code_name[7009:7024],
+	2942505: _Code_name[7024:7039],
+	2942506: _Code_name[7039:7054],
+	3040501: _Code_name[7054:7080],
+	3041701: _Code_name[7080:7095],
+	3041702: _Code_name[7095:7110],
+	3041703: _Code_name[7110:7125],
+	3041704: _Code_name[7125:7140],
+	4031700: _Code_name[7140:7166],
+	4161100: _Code_name[7166:7194],
+	4161101: _Code_name[7194:7223],
+	4161102: _Code_name[7223:7238],
+	4161103: _Code_name[7238:7253],
+	4161104: _Code_name[7253:7268],
+	4161105: _Code_name[7268:7283],
+	4161106: _Code_name[7283:7298],
+	4161107: _Code_name[7298:7313],
+	4161108: _Code_name[7313:7328],
+	4161109: _Code_name[7328:7343],
+	4341107: _Code_name[7343:7358],
+	4890500: _Code_name[7358:7373],
+	4940400: _Code_name[7373:7388],
+	4940401: _Code_name[7388:7403],
+	5107200: _Code_name[7403:7418],
+	5107201: _Code_name[7418:7433],
+	5166301: _Code_name[7433:7448],
+	5166302: _Code_name[7448:7463],
+	5166303: _Code_name[7463:7478],
+	5166304: _Code_name[7478:7493],
+	5166305: _Code_name[7493:7508],
+	5166307: _Code_name[7508:7523],
+	5166400: _Code_name[7523:7538],
+	5166401: _Code_name[7538:7553],
+	5166402: _Code_name[7553:7568],
+	5166403: _Code_name[7568:7583],
+	5166404: _Code_name[7583:7598],
+	5166405: _Code_name[7598:7613],
+	5166406: _Code_name[7613:7628],
+	5339900: _Code_name[7628:7643],
+	5339901: _Code_name[7643:7658],
+	5339902: _Code_name[7658:7673],
+	5371601: _Code_name[7673:7688],
+	5371602: _Code_name[7688:7703],
+	5371603: _Code_name[7703:7718],
+	5423900: _Code_name[7718:7733],
+	5423901: _Code_name[7733:7748],
+	5423902: _Code_name[7748:7763],
+	5429413: _Code_name[7763:7778],
+	5429414: _Code_name[7778:7793],
+	5429513: _Code_name[7793:7808],
+	5439007: _Code_name[7808:7823],
+	5439008: _Code_name[7823:7838],
+	5439009: _Code_name[7838:7853],
+	5439010: _Code_name[7853:7868],
+	5439012: _Code_name[7868:7883],
+	5439013: _Code_name[7883:7898],
+	5439014: _Code_name[7898:7913],
+	5439015: _Code_name[7913:7928],
+	5439016: _Code_name[7928:7943],
+	5439017: _Code_name[7943:7958],
+	5439018: _Code_name[7958:7973],
+	5490710: _Code_name[7973:7988],
+	5624900: _Code_name[7988:8003],
+	5624901: _Code_name[8003:8018],
+	5626500: _Code_name[8018:8033],
+	5654600: _Code_name[8033:8048],
+	5654601: _Code_name[8048:8063],
+	5654602: _Code_name[8063:8078],
+	5687301: _Code_name[8078:8093],
+	5687302: _Code_name[8093:8108],
+	5687400: _Code_name[8108:8123],
+	5687401: _Code_name[8123:8138],
+	5733201: _Code_name[8138:8153],
+	5733401: _Code_name[8153:8168],
+	5733402: _Code_name[8168:8183],
+	5733403: _Code_name[8183:8198],
+	5733406: _Code_name[8198:8213],
+	5733408: _Code_name[8213:8228],
+	5733409: _Code_name[8228:8243],
+	5739101: _Code_name[8243:8258],
+	5746102: _Code_name[8258:8273],
+	5787801: _Code_name[8273:8288],
+	5787900: _Code_name[8288:8303],
+	5787901: _Code_name[8303:8318],
+	5787902: _Code_name[8318:8333],
+	5787903: _Code_name[8333:8348],
+	5787906: _Code_name[8348:8363],
+	5787907: _Code_name[8363:8378],
+	5787908: _Code_name[8378:8393],
+	5788001: _Code_name[8393:8408],
+	5788002: _Code_name[8408:8423],
+	5788003: _Code_name[8423:8438],
+	5788004: _Code_name[8438:8453],
+	5788005: _Code_name[8453:8468],
+	5788200: _Code_name[8468:8483],
+	5788604: _Code_name[8483:8498],
+	5858203: _Code_name[8498:8513],
+	5860402: _Code_name[8513:8528],
+	5876900: _Code_name[8528:8543],
+	5897900: _Code_name[8543:8558],
+	5946802: _Code_name[8558:8573],
+	5976500: _Code_name[8573:8588],
+	6007200: _Code_name[8588:8603],
+	6045000: _Code_name[8603:8618],
+	6050106: _Code_name[8618:8633],
+	6050202: _Code_name[8633:8648],
+	6050204: _Code_name[8648:8663],
+	6053600: _Code_name[8663:8678],
+	6586400: _Code_name[8678:8693],
+	7369100: _Code_name[8693:8708],
+	7429703: _Code_name[8708:8723],
+	7436100: _Code_name[8723:8738],
+	7555701: _Code_name[8738:8753],
+	7555702: _Code_name[8753:8768],
+	7749501: _Code_name[8768:8783],
+	7750301: _Code_name[8783:8798],
+	7750302: _Code_name[8798:8813],
+	7750303: _Code_name[8813:8828],
+	8993000: _Code_name[8828:8843],
 }
 
 func (i Code) String() string {
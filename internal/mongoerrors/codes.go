@@ -45,7 +45,9 @@
 	ErrIndexKeySpecsConflict                       = Code(86)      // IndexKeySpecsConflict
 	ErrOperationFailed                             = Code(96)      // OperationFailed
 	ErrNotExactValueField                          = Code(111)     // NotExactValueField
+	ErrWriteConflict                               = Code(112)     // WriteConflict
 	ErrCommandNotSupported                         = Code(115)     // CommandNotSupported
+	ErrConflictingOperationInProgress              = Code(117)     // ConflictingOperationInProgress
 	ErrNamespaceNotSharded                         = Code(118)     // NamespaceNotSharded
 	ErrDocumentFailedValidation                    = Code(121)     // DocumentFailedValidation
 	ErrExceededMemoryLimit                         = Code(146)     // ExceededMemoryLimit
@@ -140,6 +142,7 @@
 	ErrDollarCondBadParameter                      = Code(17083)   // DollarCondBadParameter
 	ErrDollarSizeRequiresArray                     = Code(17124)   // DollarSizeRequiresArray
 	ErrExactlyOneTextIndex                         = Code(17194)   // ExactlyOneTextIndex
+	ErrLocation17217                               = Code(17217)   // Location17217
 	ErrLocation17261                               = Code(17261)   // Location17261
 	ErrLocation17276                               = Code(17276)   // Location17276
 	ErrLocation17308                               = Code(17308)   // Location17308
@@ -220,6 +223,7 @@
 	ErrLocation31308                               = Code(31308)   // Location31308
 	ErrLocation31325                               = Code(31325)   // Location31325
 	ErrLocation31393                               = Code(31393)   // Location31393
+	ErrLocation31394                               = Code(31394)   // Location31394
 	ErrLocation31395                               = Code(31395)   // Location31395
 	ErrLocation31441                               = Code(31441)   // Location31441
 	ErrLocation31465                               = Code(31465)   // Location31465
@@ -314,6 +318,7 @@
 	ErrLocation40323                               = Code(40323)   // Location40323
 	ErrUnrecognizedCommand                         = Code(40324)   // UnrecognizedCommand
 	ErrLocation40352                               = Code(40352)   // Location40352
+	ErrLocation40353                               = Code(40353)   // Location40353
 	ErrDollarArrayToObjectRequiresArray            = Code(40386)   // DollarArrayToObjectRequiresArray
 	ErrDollarObjectToArrayRequiresObject           = Code(40390)   // DollarObjectToArrayRequiresObject
 	ErrDollarArrayToObjectAllMustBeObjects         = Code(40391)   // DollarArrayToObjectAllMustBeObjects
@@ -392,6 +397,8 @@
 	ErrLocation51134                               = Code(51134)   // Location51134
 	ErrLocation51151                               = Code(51151)   // Location51151
 	ErrLocation51156                               = Code(51156)   // Location51156
+	ErrLocation51173                               = Code(51173)   // Location51173
+	ErrLocation51174                               = Code(51174)   // Location51174
 	ErrLocation51178                               = Code(51178)   // Location51178
 	ErrLocation51183                               = Code(51183)   // Location51183
 	ErrLocation51185                               = Code(51185)   // Location51185
@@ -527,6 +534,7 @@
 	ErrLocation6050204                             = Code(6050204) // Location6050204
 	ErrLocation6053600                             = Code(6053600) // Location6053600
 	ErrLocation6586400                             = Code(6586400) // Location6586400
+	ErrLocation7369100                             = Code(7369100) // Location7369100
 	ErrLocation7429703                             = Code(7429703) // Location7429703
 	ErrLocation7436100                             = Code(7436100) // Location7436100
 	ErrLocation7555701                             = Code(7555701) // Location7555701
@@ -44,26 +44,34 @@ type line struct {
 
 // extraMongoErrors contains MongoDB error codes FerretDB uses and error_mappings.csv does not include
 var extraMongoErrors = map[string]int{
-	"Unset":                         0,
-	"UserNotFound":                  11,
-	"UnsupportedFormat":             12,
-	"Unauthorized":                  13,
-	"ProtocolError":                 17,
-	"AuthenticationFailed":          18,
-	"MaxTimeMSExpired":              50,
-	"CommandNotFound":               59,
-	"OperationFailed":               96,
-	"ClientMetadataCannotBeMutated": 186,
-	"InvalidUUID":                   207,
-	"NotImplemented":                238,
-	"MechanismUnavailable":          334,
-	"UnsupportedOpQueryCommand":     352,
-	"Location16979":                 16979,
-	"Location40621":                 40621,
-	"Location50687":                 50687,
-	"Location50692":                 50692,
-	"Location50840":                 50840,
-	"Location5739101":               5739101,
+	"Unset":                          0,
+	"UserNotFound":                   11,
+	"UnsupportedFormat":              12,
+	"Unauthorized":                   13,
+	"ProtocolError":                  17,
+	"AuthenticationFailed":           18,
+	"MaxTimeMSExpired":               50,
+	"CommandNotFound":                59,
+	"OperationFailed":                96,
+	"WriteConflict":                  112,
+	"ConflictingOperationInProgress": 117,
+	"ClientMetadataCannotBeMutated":  186,
+	"InvalidUUID":                    207,
+	"NotImplemented":                 238,
+	"MechanismUnavailable":           334,
+	"UnsupportedOpQueryCommand":      352,
+	"Location16979":                  16979,
+	"Location17217":                  17217,
+	"Location31394":                  31394,
+	"Location40353":                  40353,
+	"Location40621":                  40621,
+	"Location50687":                  50687,
+	"Location50692":                  50692,
+	"Location50840":                  50840,
+	"Location51173":                  51173,
+	"Location51174":                  51174,
+	"Location5739101":                5739101,
+	"Location7369100":                7369100,
 }
 
 func main() {
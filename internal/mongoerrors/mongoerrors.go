@@ -120,6 +120,10 @@ func Make(ctx context.Context, err error, arg string, l *slog.Logger) *Error {
 	case pgerrcode.QueryCanceled:
 		code = ErrMaxTimeMSExpired
 
+	case pgerrcode.SerializationFailure, pgerrcode.DeadlockDetected:
+		// the operation could be retried, like MongoDB's write conflicts
+		code = ErrWriteConflict
+
 	case pgerrcode.ConnectionFailure:
 		// mainly for tests
 		l.ErrorContext(ctx, "Connection failure", slog.String("arg", arg), slog.String("error", goString(err)))
@@ -0,0 +1,269 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bsondiff provides structured comparison of BSON documents.
+package bsondiff
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/FerretDB/wire/wirebson"
+)
+
+// Kind represents a kind of difference.
+type Kind string
+
+// Kinds of differences.
+const (
+	// KindValue is used when values have the same type but are not equal.
+	KindValue Kind = "value"
+
+	// KindType is used when values have different types.
+	KindType Kind = "type"
+
+	// KindMissing is used when the field is present only in the expected document.
+	KindMissing Kind = "missing"
+
+	// KindExtra is used when the field is present only in the actual document.
+	KindExtra Kind = "extra"
+
+	// KindErrorCode is used when top-level error codes are not equal.
+	KindErrorCode Kind = "errorCode"
+)
+
+// Difference represents a single difference between actual and expected documents.
+//
+// Values are formatted with [wirebson.LogMessage].
+type Difference struct {
+	Path         string `json:"path"`
+	Kind         Kind   `json:"kind"`
+	Actual       string `json:"actual,omitempty"`
+	ActualType   string `json:"actualType,omitempty"`
+	Expected     string `json:"expected,omitempty"`
+	ExpectedType string `json:"expectedType,omitempty"`
+}
+
+// Compare returns differences between actual and expected documents in the expected fields order,
+// followed by extra fields of the actual document.
+//
+// Top-level fields with the given names are ignored.
+// Raw nested documents and arrays are compared as a whole,
+// so documents should be decoded with DecodeDeep to get path-level differences.
+func Compare(actual, expected *wirebson.Document, ignore ...string) []Difference {
+	var res []Difference
+
+	// fields with duplicate names are matched in order
+	expectedCount := make(map[string]int, expected.Len())
+
+	for name, e := range expected.All() {
+		if slices.Contains(ignore, name) {
+			continue
+		}
+
+		a := nthValue(actual, name, expectedCount[name])
+		expectedCount[name]++
+
+		if a == nil {
+			res = append(res, missing(name, e))
+			continue
+		}
+
+		if name == "code" && typeName(a) == typeName(e) && !wirebson.Equal(a, e) {
+			res = append(res, Difference{
+				Path:     name,
+				Kind:     KindErrorCode,
+				Actual:   wirebson.LogMessage(a),
+				Expected: wirebson.LogMessage(e),
+			})
+
+			continue
+		}
+
+		res = compareValues(res, name, a, e)
+	}
+
+	actualCount := make(map[string]int, actual.Len())
+
+	for name, a := range actual.All() {
+		if slices.Contains(ignore, name) {
+			continue
+		}
+
+		if actualCount[name] >= expectedCount[name] {
+			res = append(res, extra(name, a))
+		}
+
+		actualCount[name]++
+	}
+
+	return res
+}
+
+// compareValues appends differences between actual and expected values at the given path to res.
+func compareValues(res []Difference, path string, a, e any) []Difference {
+	if at, et := typeName(a), typeName(e); at != et {
+		return append(res, Difference{
+			Path:         path,
+			Kind:         KindType,
+			Actual:       wirebson.LogMessage(a),
+			ActualType:   at,
+			Expected:     wirebson.LogMessage(e),
+			ExpectedType: et,
+		})
+	}
+
+	ed, eok := e.(*wirebson.Document)
+	ad, aok := a.(*wirebson.Document)
+
+	if eok && aok {
+		// fields with duplicate names are matched in order
+		expectedCount := make(map[string]int, ed.Len())
+
+		for name, ev := range ed.All() {
+			p := path + "." + name
+
+			av := nthValue(ad, name, expectedCount[name])
+			expectedCount[name]++
+
+			if av == nil {
+				res = append(res, missing(p, ev))
+				continue
+			}
+
+			res = compareValues(res, p, av, ev)
+		}
+
+		actualCount := make(map[string]int, ad.Len())
+
+		for name, av := range ad.All() {
+			if actualCount[name] >= expectedCount[name] {
+				res = append(res, extra(path+"."+name, av))
+			}
+
+			actualCount[name]++
+		}
+
+		return res
+	}
+
+	ea, eok := e.(*wirebson.Array)
+	aa, aok := a.(*wirebson.Array)
+
+	if eok && aok {
+		for i := range max(aa.Len(), ea.Len()) {
+			p := path + "." + strconv.Itoa(i)
+
+			switch {
+			case i >= aa.Len():
+				res = append(res, missing(p, ea.Get(i)))
+			case i >= ea.Len():
+				res = append(res, extra(p, aa.Get(i)))
+			default:
+				res = compareValues(res, p, aa.Get(i), ea.Get(i))
+			}
+		}
+
+		return res
+	}
+
+	// raw documents and arrays are compared as a whole
+	if !wirebson.Equal(a, e) {
+		res = append(res, Difference{
+			Path:     path,
+			Kind:     KindValue,
+			Actual:   wirebson.LogMessage(a),
+			Expected: wirebson.LogMessage(e),
+		})
+	}
+
+	return res
+}
+
+// nthValue returns the value of the n-th (starting from 0) field with the given name, or nil.
+func nthValue(doc *wirebson.Document, name string, n int) any {
+	for k, v := range doc.All() {
+		if k != name {
+			continue
+		}
+
+		if n == 0 {
+			return v
+		}
+
+		n--
+	}
+
+	return nil
+}
+
+// missing returns a difference for the field present only in the expected document.
+func missing(path string, e any) Difference {
+	return Difference{
+		Path:         path,
+		Kind:         KindMissing,
+		Expected:     wirebson.LogMessage(e),
+		ExpectedType: typeName(e),
+	}
+}
+
+// extra returns a difference for the field present only in the actual document.
+func extra(path string, a any) Difference {
+	return Difference{
+		Path:       path,
+		Kind:       KindExtra,
+		Actual:     wirebson.LogMessage(a),
+		ActualType: typeName(a),
+	}
+}
+
+// typeName returns BSON type alias (as used by `$type` operator) of the given value.
+func typeName(v any) string {
+	switch v.(type) {
+	case *wirebson.Document, wirebson.RawDocument:
+		return "object"
+	case *wirebson.Array, wirebson.RawArray:
+		return "array"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	case wirebson.Binary:
+		return "binData"
+	case wirebson.UndefinedType:
+		return "undefined"
+	case wirebson.ObjectID:
+		return "objectId"
+	case bool:
+		return "bool"
+	case time.Time:
+		return "date"
+	case wirebson.NullType:
+		return "null"
+	case wirebson.Regex:
+		return "regex"
+	case int32:
+		return "int"
+	case wirebson.Timestamp:
+		return "timestamp"
+	case int64:
+		return "long"
+	case wirebson.Decimal128:
+		return "decimal"
+	default:
+		panic(fmt.Sprintf("unexpected type %T", v))
+	}
+}
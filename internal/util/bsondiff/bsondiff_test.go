@@ -0,0 +1,100 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bsondiff
+
+import (
+	"math"
+	"testing"
+
+	"github.com/FerretDB/wire/wirebson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	actual := wirebson.MustDocument(
+		"cursor", wirebson.MustDocument(
+			"firstBatch", wirebson.MustArray(
+				wirebson.MustDocument("_id", int32(1), "v", "foo"),
+			),
+			"id", int64(0),
+		),
+		"code", int32(2),
+		"extra", true,
+		"operationTime", int64(1),
+		"ok", float64(0),
+	)
+
+	expected := wirebson.MustDocument(
+		"cursor", wirebson.MustDocument(
+			"firstBatch", wirebson.MustArray(
+				wirebson.MustDocument("_id", int64(1), "v", "bar"),
+				wirebson.MustDocument("_id", int32(2)),
+			),
+			"id", int64(0),
+			"ns", "db.c",
+		),
+		"code", int32(9),
+		"operationTime", int64(2),
+		"ok", float64(0),
+	)
+
+	//nolint:lll // for readability
+	expectedDiff := []Difference{
+		{Path: "cursor.firstBatch.0._id", Kind: KindType, Actual: "1", ActualType: "int", Expected: "int64(1)", ExpectedType: "long"},
+		{Path: "cursor.firstBatch.0.v", Kind: KindValue, Actual: "`foo`", Expected: "`bar`"},
+		{Path: "cursor.firstBatch.1", Kind: KindMissing, Expected: "{`_id`: 2}", ExpectedType: "object"},
+		{Path: "cursor.ns", Kind: KindMissing, Expected: "`db.c`", ExpectedType: "string"},
+		{Path: "code", Kind: KindErrorCode, Actual: "2", Expected: "9"},
+		{Path: "extra", Kind: KindExtra, Actual: "true", ActualType: "bool"},
+	}
+	assert.Equal(t, expectedDiff, Compare(actual, expected, "operationTime"))
+
+	assert.Empty(t, Compare(expected, expected))
+}
+
+func FuzzCompare(f *testing.F) {
+	for _, doc := range []*wirebson.Document{
+		wirebson.MustDocument(),
+		wirebson.MustDocument("_id", int32(1), "v", "foo"),
+		wirebson.MustDocument(
+			"v", wirebson.MustArray(int64(42), 42.13, math.NaN(), wirebson.Null, true, wirebson.MustDocument("foo", "bar")),
+			"d", wirebson.MustDocument("nested", wirebson.MustDocument("v", wirebson.Binary{B: []byte{42}})),
+		),
+	} {
+		f.Add([]byte(must.NotFail(doc.Encode())))
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		t.Parallel()
+
+		doc, err := wirebson.RawDocument(b).DecodeDeep()
+		if err != nil {
+			return
+		}
+
+		raw, err := doc.Encode()
+		require.NoError(t, err)
+
+		actual, err := raw.DecodeDeep()
+		require.NoError(t, err)
+
+		assert.Empty(t, Compare(actual, doc))
+	})
+}
@@ -15,7 +15,9 @@
 package logging
 
 import (
+	"fmt"
 	"log/slog"
+	"maps"
 	"slices"
 )
 
@@ -79,3 +81,30 @@ func resolve(v slog.Value) any {
 
 	return m
 }
+
+// flatAttr is a single attribute with a string value.
+type flatAttr struct {
+	key   string
+	value string
+}
+
+// flatAttrs returns the same attributes as [attrs], but with groups flattened
+// into period-separated keys and values formatted as strings, sorted by key.
+func flatAttrs(r slog.Record, goas []groupOrAttrs) []flatAttr {
+	var res []flatAttr
+	flatten("", attrs(r, goas), &res)
+
+	return res
+}
+
+// flatten appends attributes from the given map to res with the given key prefix.
+func flatten(prefix string, m map[string]any, res *[]flatAttr) {
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		switch v := m[k].(type) {
+		case map[string]any:
+			flatten(prefix+k+".", v, res)
+		default:
+			*res = append(*res, flatAttr{key: prefix + k, value: fmt.Sprint(v)})
+		}
+	}
+}
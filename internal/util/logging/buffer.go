@@ -29,6 +29,7 @@ type circularBuffer struct {
 	mu      sync.RWMutex
 	records []*slog.Record
 	index   int
+	total   int64 // total number of added records, including overwritten ones
 }
 
 // newCircularBuffer creates a circular buffer for log records in memory.
@@ -49,6 +50,15 @@ func (cb *circularBuffer) add(record *slog.Record) {
 
 	cb.records[cb.index] = record
 	cb.index = (cb.index + 1) % len(cb.records)
+	cb.total++
+}
+
+// totalAdded returns the total number of added records, including overwritten ones.
+func (cb *circularBuffer) totalAdded() int64 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.total
 }
 
 // get returns entries from circularBuffer.
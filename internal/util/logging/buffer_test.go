@@ -94,4 +94,6 @@ func TestCircularBufferHandler(t *testing.T) {
 			assert.Equal(t, tc.expected, actual)
 		})
 	}
+
+	assert.Equal(t, int64(3), slog.Default().Handler().(*Handler).TotalEntries())
 }
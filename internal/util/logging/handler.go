@@ -47,7 +47,7 @@ type Handler struct {
 //
 //nolint:vet // for readability
 type NewHandlerOpts struct {
-	Base         string // base handler to create: "console", "text", "json" or "mongo"
+	Base         string // base handler to create: "console", "text", "json", "mongo", "syslog" or "journald"
 	Level        slog.Leveler
 	RemoveTime   bool
 	RemoveLevel  bool
@@ -140,6 +140,10 @@ func NewHandler(out io.Writer, opts *NewHandlerOpts) *Handler {
 		h = slog.NewTextHandler(out, stdOpts)
 	case "json":
 		h = slog.NewJSONHandler(out, stdOpts)
+	case "syslog":
+		h = newSyslogHandler(out, opts)
+	case "journald":
+		h = newJournaldHandler(out, opts)
 	default:
 		panic(fmt.Sprintf("invalid base handler %q", opts.Base))
 	}
@@ -187,7 +191,7 @@ func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
 
 	// Try to flush the full record before panicking with a message only.
 	// It may fail on stderr without TTY, so the error is ignored.
-	if f, ok := h.out.(*os.File); ok {
+	if f, ok := h.out.(interface{ Sync() error }); ok {
 		_ = f.Sync()
 	}
 
@@ -234,6 +238,11 @@ func (h *Handler) RecentEntries() (*wirebson.Array, error) {
 	return h.recentEntries.getArray()
 }
 
+// TotalEntries returns the total number of log entries handled, including those no longer available as recent ones.
+func (h *Handler) TotalEntries() int64 {
+	return h.recentEntries.totalAdded()
+}
+
 // check interfaces
 var (
 	_ slog.Handler = (*Handler)(nil)
@@ -0,0 +1,185 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+// journaldHandler is a [slog.Handler] that writes logs using journald native protocol.
+// Every log record is written with a single Write call; see [DialJournald].
+// Attributes are mapped to upper-case journal fields (`conn.id` becomes `CONN_ID`).
+//
+//nolint:vet // for readability
+type journaldHandler struct {
+	opts *NewHandlerOpts
+
+	ga []groupOrAttrs
+
+	m   *sync.Mutex
+	out io.Writer
+}
+
+// newJournaldHandler creates a new journald handler.
+func newJournaldHandler(out io.Writer, opts *NewHandlerOpts) *journaldHandler {
+	must.NotBeZero(opts)
+
+	return &journaldHandler{
+		opts: opts,
+		m:    new(sync.Mutex),
+		out:  out,
+	}
+}
+
+// Enabled implements [slog.Handler].
+func (h *journaldHandler) Enabled(_ context.Context, l slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+
+	return l >= minLevel
+}
+
+// Handle implements [slog.Handler].
+//
+// The journal records the time of receiving the entry itself, so record's time is not sent.
+func (h *journaldHandler) Handle(ctx context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+
+	writeJournaldField(&buf, "MESSAGE", r.Message)
+	writeJournaldField(&buf, "PRIORITY", strconv.Itoa(syslogSeverity(r.Level)))
+	writeJournaldField(&buf, "SYSLOG_IDENTIFIER", syslogAppName)
+
+	if !h.opts.RemoveSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		if f.File != "" {
+			writeJournaldField(&buf, "CODE_FILE", shortPath(f.File))
+			writeJournaldField(&buf, "CODE_LINE", strconv.Itoa(f.Line))
+			writeJournaldField(&buf, "CODE_FUNC", f.Function)
+		}
+	}
+
+	for _, a := range flatAttrs(r, h.ga) {
+		writeJournaldField(&buf, journaldFieldName(a.key), a.value)
+	}
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	_, err := buf.WriteTo(h.out)
+
+	return err
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *journaldHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	res := *h
+	res.ga = append(slices.Clone(h.ga), groupOrAttrs{attrs: attrs})
+
+	return &res
+}
+
+// WithGroup implements [slog.Handler].
+func (h *journaldHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	res := *h
+	res.ga = append(slices.Clone(h.ga), groupOrAttrs{group: name})
+
+	return &res
+}
+
+// journaldSocket is the path of journald native protocol socket.
+const journaldSocket = "/run/systemd/journal/socket"
+
+// DialJournald connects to the local journald socket.
+//
+// The returned writer should be used with "journald" base handler.
+func DialJournald() (io.WriteCloser, error) {
+	conn, err := net.Dial("unixgram", journaldSocket)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return conn, nil
+}
+
+// writeJournaldField writes a single field in journald native protocol format.
+// Values with newlines use the binary-safe encoding with explicit length.
+func writeJournaldField(buf *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(name + "=" + value + "\n")
+		return
+	}
+
+	buf.WriteString(name + "\n")
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
+
+// journaldFieldName returns a valid journal field name for the given attribute key.
+//
+// Field names consist of upper-case letters, digits, and underscores,
+// can't start with a digit or an underscore (that is reserved for trusted fields),
+// and are at most 64 characters long.
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	name = strings.TrimLeft(name, "_")
+
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F_" + name
+	}
+
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	return name
+}
+
+// check interfaces
+var (
+	_ slog.Handler = (*journaldHandler)(nil)
+)
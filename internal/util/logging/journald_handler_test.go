@@ -0,0 +1,66 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournaldHandler(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	opts := &NewHandlerOpts{
+		Level:        slog.LevelDebug,
+		RemoveSource: true,
+	}
+
+	l := slog.New(newJournaldHandler(&buf, opts)).With(slog.String("name", "test")).WithGroup("conn")
+
+	r := slog.NewRecord(time.Now(), slog.LevelError, "Message", 0)
+	r.AddAttrs(slog.Int("id", 42), slog.String("_query", "a\nb"), slog.Int("1st", 1))
+	require.NoError(t, l.Handler().Handle(context.Background(), r))
+
+	expected := "MESSAGE=Message\n" +
+		"PRIORITY=3\n" +
+		"SYSLOG_IDENTIFIER=ferretdb\n" +
+		"CONN_1ST=1\n" +
+		"CONN__QUERY\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n" +
+		"CONN_ID=42\n" +
+		"NAME=test\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	t.Parallel()
+
+	for key, expected := range map[string]string{
+		"conn.id":  "CONN_ID",
+		"_secret":  "SECRET",
+		"1st":      "F_1ST",
+		"":         "F_",
+		"üñí.code": "CODE",
+	} {
+		assert.Equal(t, expected, journaldFieldName(key), "%q", key)
+	}
+}
@@ -76,7 +76,12 @@ func Logger(out io.Writer, opts *NewHandlerOpts, uuid string) *slog.Logger {
 
 // SetupDefault initializes global slog logging with given options and UUID.
 func SetupDefault(opts *NewHandlerOpts, uuid string) {
-	l := Logger(os.Stderr, opts, uuid)
+	SetupDefaultOut(os.Stderr, opts, uuid)
+}
+
+// SetupDefaultOut is a variant of [SetupDefault] that writes logs to the given output instead of stderr.
+func SetupDefaultOut(out io.Writer, opts *NewHandlerOpts, uuid string) {
+	l := Logger(out, opts, uuid)
 
 	slog.SetDefault(l)
 	slog.SetLogLoggerLevel(slog.LevelInfo + 2)
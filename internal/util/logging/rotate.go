@@ -0,0 +1,300 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// rotatedTimeFormat is used for suffixes of rotated log files.
+// It is similar to the one used by MongoDB, but with milliseconds to avoid collisions.
+const rotatedTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFileOpts represents [OpenRotatingFile] options.
+type RotatingFileOpts struct {
+	Path string
+
+	// MaxSize is the maximum size of the file in bytes before it is rotated.
+	// Zero disables size-based rotation.
+	MaxSize int64
+
+	// MaxAge is the maximum age of rotated files before they are removed.
+	// Zero keeps them regardless of age.
+	MaxAge time.Duration
+
+	// MaxBackups is the maximum number of rotated files to keep.
+	// Zero keeps all of them.
+	MaxBackups int
+
+	// Compress enables gzip compression of rotated files.
+	Compress bool
+}
+
+// RotatingFile is an [io.Writer] for a log file that is rotated by size or by explicit [RotatingFile.Rotate] calls.
+//
+// Rotated files are renamed by adding a UTC timestamp suffix to their names.
+type RotatingFile struct {
+	opts *RotatingFileOpts
+
+	// protects f and size
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+
+	// serializes cleanups of rotated files
+	cleanupMu sync.Mutex
+}
+
+// OpenRotatingFile opens or creates a log file with the given options.
+func OpenRotatingFile(opts *RotatingFileOpts) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		opts: opts,
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return rf, nil
+}
+
+// open opens the log file for appending.
+//
+// Caller should hold the lock, if needed.
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.opts.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return lazyerrors.Error(err)
+	}
+
+	rf.f = f
+	rf.size = fi.Size()
+
+	return nil
+}
+
+// Write implements [io.Writer].
+//
+// It rotates the file first if the write would exceed the maximum size.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+
+	if rf.opts.MaxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.opts.MaxSize {
+		if err := rf.rotate(); err != nil {
+			rf.mu.Unlock()
+			return 0, lazyerrors.Error(err)
+		}
+
+		rf.mu.Unlock()
+
+		// do it without holding the main lock
+		if err := rf.cleanup(); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		rf.mu.Lock()
+	}
+
+	defer rf.mu.Unlock()
+
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+
+	return n, err
+}
+
+// Sync commits the current contents of the file to stable storage.
+func (rf *RotatingFile) Sync() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return rf.f.Sync()
+}
+
+// Rotate renames the current file, opens a new one,
+// and then compresses and removes old rotated files according to options.
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	err := rf.rotate()
+	rf.mu.Unlock()
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = rf.cleanup(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// Close closes the file.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return rf.f.Close()
+}
+
+// rotate renames the current file and opens a new one.
+//
+// Caller should hold the lock.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	rotated := rf.opts.Path + "." + time.Now().UTC().Format(rotatedTimeFormat)
+	if err := os.Rename(rf.opts.Path, rotated); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return rf.open()
+}
+
+// rotatedFile represents a rotated log file.
+type rotatedFile struct {
+	path string
+	t    time.Time
+}
+
+// cleanup compresses and removes rotated files according to options.
+func (rf *RotatingFile) cleanup() error {
+	rf.cleanupMu.Lock()
+	defer rf.cleanupMu.Unlock()
+
+	files, err := rf.rotatedFiles()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	// newest first
+	slices.SortFunc(files, func(a, b rotatedFile) int { return b.t.Compare(a.t) })
+
+	for i, f := range files {
+		remove := rf.opts.MaxBackups > 0 && i >= rf.opts.MaxBackups
+		remove = remove || (rf.opts.MaxAge > 0 && time.Since(f.t) > rf.opts.MaxAge)
+
+		switch {
+		case remove:
+			err = os.Remove(f.path)
+		case rf.opts.Compress && !strings.HasSuffix(f.path, ".gz"):
+			err = compressFile(f.path)
+		}
+
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// rotatedFiles returns all rotated files of the log file, compressed or not.
+func (rf *RotatingFile) rotatedFiles() ([]rotatedFile, error) {
+	dir, base := filepath.Split(rf.opts.Path)
+
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var res []rotatedFile
+
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+
+		suffix, ok := strings.CutPrefix(e.Name(), base+".")
+		if !ok {
+			continue
+		}
+
+		t, err := time.Parse(rotatedTimeFormat, strings.TrimSuffix(suffix, ".gz"))
+		if err != nil {
+			continue
+		}
+
+		res = append(res, rotatedFile{
+			path: filepath.Join(dir, e.Name()),
+			t:    t,
+		})
+	}
+
+	return res, nil
+}
+
+// compressFile replaces the given file with its gzip-compressed version.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer src.Close() //nolint:errcheck // we are only reading it
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer func() {
+		if e := dst.Close(); e != nil && err == nil {
+			err = lazyerrors.Error(e)
+		}
+
+		if err != nil {
+			_ = os.Remove(path + ".gz")
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+
+	if _, err = io.Copy(zw, src); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = zw.Close(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = os.Remove(path); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// check interfaces
+var (
+	_ io.WriteCloser = (*RotatingFile)(nil)
+)
@@ -0,0 +1,135 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rotatedNames returns sorted names of rotated files in the given directory.
+func rotatedNames(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var res []string
+
+	for _, e := range entries {
+		if e.Name() != "ferretdb.log" {
+			res = append(res, e.Name())
+		}
+	}
+
+	return res
+}
+
+func TestRotatingFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("MaxSize", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "ferretdb.log")
+
+		rf, err := OpenRotatingFile(&RotatingFileOpts{
+			Path:       path,
+			MaxSize:    10,
+			MaxBackups: 2,
+		})
+		require.NoError(t, err)
+
+		defer rf.Close() //nolint:errcheck // not important for the test
+
+		for _, s := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+			_, err = rf.Write([]byte(s))
+			require.NoError(t, err)
+
+			// rotated files should have different names
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "fourth\n", string(b))
+
+		names := rotatedNames(t, dir)
+		require.Len(t, names, 2)
+
+		b, err = os.ReadFile(filepath.Join(dir, names[1]))
+		require.NoError(t, err)
+		assert.Equal(t, "third\n", string(b))
+	})
+
+	t.Run("RotateCompress", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "ferretdb.log")
+
+		rf, err := OpenRotatingFile(&RotatingFileOpts{
+			Path:     path,
+			Compress: true,
+		})
+		require.NoError(t, err)
+
+		defer rf.Close() //nolint:errcheck // not important for the test
+
+		_, err = rf.Write([]byte("message\n"))
+		require.NoError(t, err)
+
+		require.NoError(t, rf.Rotate())
+
+		names := rotatedNames(t, dir)
+		require.Len(t, names, 1)
+		assert.True(t, strings.HasSuffix(names[0], ".gz"), "%s", names[0])
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Empty(t, b)
+	})
+
+	t.Run("MaxAge", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "ferretdb.log")
+
+		old := path + "." + time.Now().Add(-48*time.Hour).UTC().Format(rotatedTimeFormat)
+		require.NoError(t, os.WriteFile(old, []byte("old\n"), 0o644))
+
+		rf, err := OpenRotatingFile(&RotatingFileOpts{
+			Path:   path,
+			MaxAge: 24 * time.Hour,
+		})
+		require.NoError(t, err)
+
+		defer rf.Close() //nolint:errcheck // not important for the test
+
+		require.NoError(t, rf.Rotate())
+
+		names := rotatedNames(t, dir)
+		require.Len(t, names, 1)
+		assert.NotEqual(t, filepath.Base(old), names[0])
+	})
+}
@@ -0,0 +1,311 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
+)
+
+const (
+	// syslogFacility is the "daemon" facility.
+	syslogFacility = 3
+
+	// syslogAppName is used as RFC 5424 APP-NAME, and as journald SYSLOG_IDENTIFIER.
+	syslogAppName = "ferretdb"
+
+	// syslogSDID is RFC 5424 SD-ID of the structured data element with attributes.
+	// 32473 is the Private Enterprise Number reserved for documentation (RFC 5612).
+	syslogSDID = "ferretdb@32473"
+
+	// syslogTimeFormat is RFC 5424 TIMESTAMP format (RFC 3339 with at most 6 fraction digits).
+	syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// syslogSeverity maps logging levels to syslog severities.
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return 7 // debug
+	case level < slog.LevelWarn:
+		return 6 // informational
+	case level < slog.LevelError:
+		return 4 // warning
+	case level < LevelDPanic:
+		return 3 // error
+	default:
+		return 2 // critical
+	}
+}
+
+// syslogHandler is a [slog.Handler] that writes logs in RFC 5424 syslog format.
+// Every log record is written with a single Write call without framing or trailing newline;
+// see [DialSyslog].
+// Attributes are mapped to parameters of a single structured data element.
+//
+//nolint:vet // for readability
+type syslogHandler struct {
+	opts *NewHandlerOpts
+
+	ga       []groupOrAttrs
+	hostname string
+	procID   string
+
+	m   *sync.Mutex
+	out io.Writer
+}
+
+// newSyslogHandler creates a new syslog handler.
+func newSyslogHandler(out io.Writer, opts *NewHandlerOpts) *syslogHandler {
+	must.NotBeZero(opts)
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	return &syslogHandler{
+		opts:     opts,
+		hostname: hostname,
+		procID:   strconv.Itoa(os.Getpid()),
+		m:        new(sync.Mutex),
+		out:      out,
+	}
+}
+
+// Enabled implements [slog.Handler].
+func (h *syslogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+
+	return l >= minLevel
+}
+
+// Handle implements [slog.Handler].
+func (h *syslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "<%d>1 ", syslogFacility*8+syslogSeverity(r.Level))
+
+	if !h.opts.RemoveTime && !r.Time.IsZero() {
+		buf.WriteString(r.Time.Format(syslogTimeFormat))
+	} else {
+		buf.WriteByte('-')
+	}
+
+	// no MSGID
+	fmt.Fprintf(&buf, " %s %s %s - ", h.hostname, syslogAppName, h.procID)
+
+	params := flatAttrs(r, h.ga)
+
+	if !h.opts.RemoveSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		if f.File != "" {
+			params = slices.Insert(params, 0, flatAttr{
+				key:   slog.SourceKey,
+				value: shortPath(f.File) + ":" + strconv.Itoa(f.Line),
+			})
+		}
+	}
+
+	if len(params) == 0 {
+		buf.WriteByte('-')
+	} else {
+		buf.WriteString("[" + syslogSDID)
+
+		for _, p := range params {
+			fmt.Fprintf(&buf, ` %s="%s"`, syslogParamName(p.key), syslogParamValue(p.value))
+		}
+
+		buf.WriteByte(']')
+	}
+
+	if r.Message != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(r.Message)
+	}
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	_, err := buf.WriteTo(h.out)
+
+	return err
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	res := *h
+	res.ga = append(slices.Clone(h.ga), groupOrAttrs{attrs: attrs})
+
+	return &res
+}
+
+// WithGroup implements [slog.Handler].
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	res := *h
+	res.ga = append(slices.Clone(h.ga), groupOrAttrs{group: name})
+
+	return &res
+}
+
+// syslogParamName returns a valid RFC 5424 PARAM-NAME for the given attribute key.
+func syslogParamName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+
+		return r
+	}, key)
+
+	if len(name) > 32 {
+		name = name[:32]
+	}
+
+	return name
+}
+
+// syslogValueReplacer escapes characters in RFC 5424 PARAM-VALUE.
+var syslogValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogParamValue returns RFC 5424 PARAM-VALUE with escaped characters.
+func syslogParamValue(value string) string {
+	return syslogValueReplacer.Replace(value)
+}
+
+// syslogWriter is an [io.WriteCloser] that sends every write as a single syslog message.
+//
+// Messages are sent as datagrams over UDP and Unix sockets,
+// and with octet-counting framing (RFC 6587) over TCP.
+// Connection is re-established on write errors.
+type syslogWriter struct {
+	network string
+	addr    string
+
+	m    sync.Mutex
+	conn net.Conn
+}
+
+// DialSyslog connects to the syslog server with the given URL:
+// `udp://host:port`, `tcp://host:port` (port defaults to 514), or `unix:///path` (e.g. `unix:///dev/log`).
+//
+// The returned writer should be used with "syslog" base handler.
+func DialSyslog(rawURL string) (io.WriteCloser, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	w := new(syslogWriter)
+
+	switch u.Scheme {
+	case "udp", "tcp":
+		w.network = u.Scheme
+		w.addr = u.Host
+
+		if u.Port() == "" {
+			w.addr = net.JoinHostPort(u.Hostname(), "514")
+		}
+
+	case "unix":
+		w.network = "unixgram"
+		w.addr = u.Path
+
+	default:
+		return nil, lazyerrors.Errorf("unsupported syslog URL scheme %q", u.Scheme)
+	}
+
+	if w.conn, err = net.Dial(w.network, w.addr); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return w, nil
+}
+
+// Write implements [io.Writer].
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	msg := p
+	if w.network == "tcp" {
+		msg = append([]byte(strconv.Itoa(len(p))+" "), p...)
+	}
+
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	var err error
+
+	for range 2 {
+		if w.conn == nil {
+			if w.conn, err = net.Dial(w.network, w.addr); err != nil {
+				continue
+			}
+		}
+
+		if _, err = w.conn.Write(msg); err == nil {
+			return len(p), nil
+		}
+
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	return 0, lazyerrors.Error(err)
+}
+
+// Close implements [io.Closer].
+func (w *syslogWriter) Close() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+
+	return err
+}
+
+// check interfaces
+var (
+	_ slog.Handler   = (*syslogHandler)(nil)
+	_ io.WriteCloser = (*syslogWriter)(nil)
+)
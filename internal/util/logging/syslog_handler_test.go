@@ -0,0 +1,102 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogHandler(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	opts := &NewHandlerOpts{
+		Level:        slog.LevelDebug,
+		RemoveSource: true,
+	}
+
+	l := slog.New(newSyslogHandler(&buf, opts)).With(slog.String("name", "test")).WithGroup("conn")
+
+	r := slog.NewRecord(time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC), slog.LevelWarn, "Message", 0)
+	r.AddAttrs(slog.Int("id", 42), slog.String("peer", `"quoted" \ [bracket]`))
+	require.NoError(t, l.Handler().Handle(context.Background(), r))
+
+	hostname, _ := os.Hostname()
+	expected := fmt.Sprintf(
+		`<28>1 2025-01-02T03:04:05.000006Z %s ferretdb %d - `+
+			`[ferretdb@32473 conn.id="42" conn.peer="\"quoted\" \\ [bracket\]" name="test"] Message`,
+		hostname, os.Getpid(),
+	)
+	assert.Equal(t, expected, buf.String())
+
+	buf.Reset()
+
+	r = slog.NewRecord(time.Time{}, slog.LevelDebug, "", 0)
+	require.NoError(t, slog.New(newSyslogHandler(&buf, opts)).Handler().Handle(context.Background(), r))
+	assert.Equal(t, fmt.Sprintf("<31>1 - %s ferretdb %d - -", hostname, os.Getpid()), buf.String())
+}
+
+func TestSyslogWriter(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer ln.Close() //nolint:errcheck // not important for the test
+
+	w, err := DialSyslog("tcp://" + ln.Addr().String())
+	require.NoError(t, err)
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+
+	defer conn.Close() //nolint:errcheck // not important for the test
+
+	_, err = w.Write([]byte("<14>1 - - - - - - first"))
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("<14>1 - - - - - - second"))
+	require.NoError(t, err)
+
+	require.NoError(t, w.Close())
+
+	b := make([]byte, 1024)
+	var res []byte
+
+	for {
+		n, readErr := conn.Read(b)
+		res = append(res, b[:n]...)
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	assert.Equal(t, "23 <14>1 - - - - - - first24 <14>1 - - - - - - second", string(res))
+
+	_, err = DialSyslog("http://127.0.0.1")
+	require.Error(t, err)
+}
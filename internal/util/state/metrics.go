@@ -82,6 +82,23 @@ func (mc *metricsCollector) Collect(ch chan<- prometheus.Metric) {
 		s.PostgreSQLVersion,
 		s.DocumentDBVersion,
 	)
+
+	var updateAvailable float64
+	if s.UpdateAvailable {
+		updateAvailable = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "update_available"),
+			"Whether a newer FerretDB version is available (1) or not (0).",
+			[]string{"latest_version"},
+			nil,
+		),
+		prometheus.GaugeValue,
+		updateAvailable,
+		s.LatestVersion,
+	)
 }
 
 // check interfaces
@@ -56,6 +56,9 @@ func TestMetrics(t *testing.T) {
 				# HELP ferretdb_up FerretDB instance state.
 				# TYPE ferretdb_up gauge
 				ferretdb_up{branch=%q,commit=%q,dev="%t",dirty="%t",documentdb="documentdb",package=%q,postgresql="postgres",telemetry="undecided",update_available="false",uuid=%q,version=%q} 1
+				# HELP ferretdb_update_available Whether a newer FerretDB version is available (1) or not (0).
+				# TYPE ferretdb_update_available gauge
+				ferretdb_update_available{latest_version=""} 0
 			`,
 			info.Branch, info.Commit, info.DevBuild, info.Dirty, info.Package, uuid, info.Version,
 		)
@@ -76,6 +79,9 @@ func TestMetrics(t *testing.T) {
 				# HELP ferretdb_up FerretDB instance state.
 				# TYPE ferretdb_up gauge
 				ferretdb_up{branch=%q,commit=%q,dev="%t",dirty="%t",documentdb="documentdb",package=%q,postgresql="postgres",telemetry="undecided",update_available="false",version=%q} 1
+				# HELP ferretdb_update_available Whether a newer FerretDB version is available (1) or not (0).
+				# TYPE ferretdb_update_available gauge
+				ferretdb_update_available{latest_version=""} 0
 			`,
 			info.Branch, info.Commit, info.DevBuild, info.Dirty, info.Package, info.Version,
 		)
@@ -16,6 +16,7 @@
 package state
 
 import (
+	"slices"
 	"strconv"
 	"time"
 
@@ -28,14 +29,18 @@ type State struct {
 	UUID      string `json:"uuid"`
 	Telemetry *bool  `json:"telemetry,omitempty"` // nil for undecided
 
+	// set by `setFeatureCompatibilityVersion` command; empty for default
+	FeatureCompatibilityVersion string `json:"featureCompatibilityVersion,omitempty"`
+
 	// all following fields are never persisted
 
 	TelemetryLocked bool      `json:"-"`
 	Start           time.Time `json:"-"`
 
 	// may be empty if FerretDB did not connect to PostgreSQL yet
-	PostgreSQLVersion string `json:"-"`
-	DocumentDBVersion string `json:"-"`
+	PostgreSQLVersion string   `json:"-"`
+	DocumentDBVersion string   `json:"-"`
+	BackendWarnings   []string `json:"-"` // found by preflight checks
 
 	// as reported by beacon, if known
 	LatestVersion   string `json:"-"`
@@ -46,15 +51,17 @@ type State struct {
 // asMap return state as a map, including non-persisted fields.
 func (s *State) asMap() map[string]any {
 	return map[string]any{
-		"uuid":               s.UUID,
-		"telemetry":          s.TelemetryString(),
-		"telemetry_locked":   strconv.FormatBool(s.TelemetryLocked),
-		"start":              s.Start.Format(time.RFC3339),
-		"postgresql_version": s.PostgreSQLVersion,
-		"documentdb_version": s.DocumentDBVersion,
-		"latest_version":     s.LatestVersion,
-		"update_info":        s.UpdateInfo,
-		"update_available":   strconv.FormatBool(s.UpdateAvailable),
+		"uuid":                          s.UUID,
+		"telemetry":                     s.TelemetryString(),
+		"telemetry_locked":              strconv.FormatBool(s.TelemetryLocked),
+		"feature_compatibility_version": s.FeatureCompatibilityVersion,
+		"start":                         s.Start.Format(time.RFC3339),
+		"postgresql_version":            s.PostgreSQLVersion,
+		"documentdb_version":            s.DocumentDBVersion,
+		"backend_warnings":              s.BackendWarnings,
+		"latest_version":                s.LatestVersion,
+		"update_info":                   s.UpdateInfo,
+		"update_available":              strconv.FormatBool(s.UpdateAvailable),
 	}
 }
 
@@ -66,15 +73,17 @@ func (s *State) deepCopy() *State {
 	}
 
 	return &State{
-		UUID:              s.UUID,
-		Telemetry:         telemetry,
-		TelemetryLocked:   s.TelemetryLocked,
-		Start:             s.Start,
-		PostgreSQLVersion: s.PostgreSQLVersion,
-		DocumentDBVersion: s.DocumentDBVersion,
-		LatestVersion:     s.LatestVersion,
-		UpdateInfo:        s.UpdateInfo,
-		UpdateAvailable:   s.UpdateAvailable,
+		UUID:                        s.UUID,
+		Telemetry:                   telemetry,
+		TelemetryLocked:             s.TelemetryLocked,
+		FeatureCompatibilityVersion: s.FeatureCompatibilityVersion,
+		Start:                       s.Start,
+		PostgreSQLVersion:           s.PostgreSQLVersion,
+		DocumentDBVersion:           s.DocumentDBVersion,
+		BackendWarnings:             slices.Clone(s.BackendWarnings),
+		LatestVersion:               s.LatestVersion,
+		UpdateInfo:                  s.UpdateInfo,
+		UpdateAvailable:             s.UpdateAvailable,
 	}
 }
 
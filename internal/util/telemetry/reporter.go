@@ -24,12 +24,15 @@
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"time"
 
 	"github.com/FerretDB/FerretDB/v2/build/version"
 	"github.com/FerretDB/FerretDB/v2/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/v2/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/must"
 	"github.com/FerretDB/FerretDB/v2/internal/util/state"
 )
 
@@ -66,6 +69,27 @@ type report struct {
 	CommandMetrics map[string]map[string]map[string]map[string]int `json:"command_metrics"`
 }
 
+// requiredFields are report fields that can't be excluded.
+var requiredFields = []string{"version", "uuid"}
+
+// Fields returns report fields that could be excluded, sorted.
+func Fields() []string {
+	var m map[string]json.RawMessage
+	must.NoError(json.Unmarshal(must.NotFail(json.Marshal(new(report))), &m))
+
+	var res []string
+
+	for f := range m {
+		if !slices.Contains(requiredFields, f) {
+			res = append(res, f)
+		}
+	}
+
+	slices.Sort(res)
+
+	return res
+}
+
 // response represents Beacon's response.
 type response struct {
 	LatestVersion   string `json:"latest_version"`
@@ -76,9 +100,13 @@ type response struct {
 // Reporter converts already collected data (such as metrics) to the report,
 // sends it to the Beacon if telemetry reporting is enabled,
 // and writes it to a local file in the state directory.
+//
+// Reports that could not be sent are spooled in the state directory
+// and sent after the next successful report.
 type Reporter struct {
 	*NewReporterOpts
-	c *http.Client
+	c     *http.Client
+	spool *spool
 }
 
 // NewReporterOpts represents reporter options.
@@ -93,6 +121,9 @@ type NewReporterOpts struct {
 	L              *slog.Logger
 	UndecidedDelay time.Duration
 	ReportInterval time.Duration
+
+	// Exclude contains report fields (see [Fields]) that are never sent or written.
+	Exclude []string
 }
 
 // NewReporter creates a new reporter.
@@ -105,6 +136,14 @@ func NewReporter(opts *NewReporterOpts) (*Reporter, error) {
 		return nil, fmt.Errorf("dir is required")
 	}
 
+	fields := Fields()
+
+	for _, f := range opts.Exclude {
+		if !slices.Contains(fields, f) {
+			return nil, fmt.Errorf("telemetry field %q can't be excluded, expected one of %q", f, fields)
+		}
+	}
+
 	t, locked, err := initialState(opts.F, opts.DNT, opts.ExecName, opts.P.Get().Telemetry, opts.L)
 	if err != nil {
 		return nil, err
@@ -121,6 +160,7 @@ func NewReporter(opts *NewReporterOpts) (*Reporter, error) {
 	return &Reporter{
 		NewReporterOpts: opts,
 		c:               http.DefaultClient,
+		spool:           newSpool(opts.Dir),
 	}, nil
 }
 
@@ -139,6 +179,9 @@ func (r *Reporter) Run(ctx context.Context) {
 
 		if s := r.P.Get(); s.Telemetry == nil || *s.Telemetry {
 			r.sendReport(ctx, report)
+		} else if err := r.spool.clear(); err != nil {
+			// reports spooled before disabling should never be sent
+			r.L.ErrorContext(ctx, "Failed to clear telemetry spool", logging.Error(err))
 		}
 
 		r.writeReport(report)
@@ -243,46 +286,76 @@ func (r *Reporter) makeReport() *report {
 	}
 }
 
+// marshal returns JSON representation of the report without excluded fields.
+func (r *Reporter) marshal(report *report) ([]byte, error) {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(r.Exclude) == 0 {
+		return b, nil
+	}
+
+	var m map[string]json.RawMessage
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	for _, f := range r.Exclude {
+		delete(m, f)
+	}
+
+	if b, err = json.Marshal(m); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return b, nil
+}
+
+// Payload returns the indented JSON payload of the report that would be sent next.
+//
+// It is used by `ferretdb telemetry show` sub-command and `ferretTelemetry` command.
+func (r *Reporter) Payload() ([]byte, error) {
+	b, err := r.marshal(r.makeReport())
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var buf bytes.Buffer
+	if err = json.Indent(&buf, b, "", "  "); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
 // sendReport sends telemetry report to the Beacon.
 // It always set report.Comment field.
 //
 // It receives information about available updates and updates the state.
 // If update is available, it logs the message.
 func (r *Reporter) sendReport(ctx context.Context, report *report) {
-	r.L.InfoContext(ctx, "Sending telemetry report", slog.String("url", r.URL), slog.Any("data", report))
-	b, err := json.Marshal(report)
+	b, err := r.marshal(report)
 	report.Comment = fmt.Sprintf("Failed to send to %s at %s.", r.URL, time.Now().Format(fileTimeFormat))
 	if err != nil {
 		r.L.ErrorContext(ctx, "Failed to marshal telemetry report", logging.Error(err))
 		return
 	}
 
-	reqCtx, reqCancel := context.WithTimeout(ctx, 3*time.Second)
-	defer reqCancel()
-
-	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, r.URL, bytes.NewReader(b))
-	if err != nil {
-		r.L.ErrorContext(ctx, "Failed to create telemetry request", logging.Error(err))
-		return
-	}
-
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.L.InfoContext(ctx, "Sending telemetry report", slog.String("url", r.URL), slog.String("data", string(b)))
 
-	resp, err := r.c.Do(req)
+	res, err := r.send(ctx, b)
 	if err != nil {
 		r.L.DebugContext(ctx, "Failed to send telemetry report", logging.Error(err))
-		return
-	}
-	defer resp.Body.Close() //nolint:errcheck // safe to ignore
 
-	if resp.StatusCode != http.StatusCreated {
-		r.L.DebugContext(ctx, "Failed to send telemetry report", slog.Int("status", resp.StatusCode))
-		return
-	}
+		if err = r.spool.add(b); err != nil {
+			r.L.ErrorContext(ctx, "Failed to spool telemetry report", logging.Error(err))
+			return
+		}
+
+		report.Comment += " Spooled to be sent later."
 
-	var res response
-	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
-		r.L.DebugContext(ctx, "Failed to read telemetry response", logging.Error(err))
 		return
 	}
 
@@ -311,6 +384,68 @@ func (r *Reporter) sendReport(ctx context.Context, report *report) {
 	}
 
 	report.Comment = fmt.Sprintf("Sent to %s at %s.", r.URL, time.Now().Format(fileTimeFormat))
+
+	r.sendSpooled(ctx)
+}
+
+// sendSpooled sends spooled reports, the oldest first, until the first failure.
+// Responses are ignored because the current report was just sent.
+func (r *Reporter) sendSpooled(ctx context.Context) {
+	files, err := r.spool.list()
+	if err != nil {
+		r.L.ErrorContext(ctx, "Failed to list spooled telemetry reports", logging.Error(err))
+		return
+	}
+
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			r.L.ErrorContext(ctx, "Failed to read spooled telemetry report", logging.Error(err))
+			return
+		}
+
+		if _, err = r.send(ctx, b); err != nil {
+			r.L.DebugContext(ctx, "Failed to send spooled telemetry report", logging.Error(err))
+			return
+		}
+
+		if err = os.Remove(f); err != nil {
+			r.L.ErrorContext(ctx, "Failed to remove spooled telemetry report", logging.Error(err))
+			return
+		}
+
+		r.L.DebugContext(ctx, "Sent spooled telemetry report", slog.String("file", f))
+	}
+}
+
+// send sends the given payload to the Beacon and returns its response.
+func (r *Reporter) send(ctx context.Context, b []byte) (*response, error) {
+	reqCtx, reqCancel := context.WithTimeout(ctx, 3*time.Second)
+	defer reqCancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, r.URL, bytes.NewReader(b))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := r.c.Do(req)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // safe to ignore
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, lazyerrors.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var res response
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &res, nil
 }
 
 // writeReport writes telemetry report to the local files.
@@ -319,12 +454,20 @@ func (r *Reporter) writeReport(report *report) {
 		report.Comment = fmt.Sprintf("Created at %s, not sent because reporting is disabled.", time.Now().Format(fileTimeFormat))
 	}
 
-	b, err := json.MarshalIndent(report, "", "  ")
+	b, err := r.marshal(report)
 	if err != nil {
 		r.L.Error("Failed to marshal telemetry report", logging.Error(err))
 		return
 	}
 
+	var buf bytes.Buffer
+	if err = json.Indent(&buf, b, "", "  "); err != nil {
+		r.L.Error("Failed to indent telemetry report", logging.Error(err))
+		return
+	}
+
+	b = buf.Bytes()
+
 	file := filepath.Join(r.Dir, "telemetry.json")
 
 	if err = os.WriteFile(file, b, 0o666); err != nil {
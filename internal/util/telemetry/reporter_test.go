@@ -15,6 +15,12 @@
 package telemetry
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/AlekSi/pointer"
@@ -136,3 +142,91 @@ func TestMakeReport(t *testing.T) {
 	}
 	assert.Equal(t, expected, tr.makeReport().CommandMetrics)
 }
+
+func TestReporterExclude(t *testing.T) {
+	t.Parallel()
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	opts := &NewReporterOpts{
+		URL:         "http://127.0.0.1:1/",
+		Dir:         t.TempDir(),
+		F:           new(Flag),
+		ConnMetrics: connmetrics.NewListenerMetrics().ConnMetrics,
+		P:           sp,
+		L:           testutil.Logger(t),
+		Exclude:     []string{"command_metrics", "build_environment"},
+	}
+
+	tr, err := NewReporter(opts)
+	require.NoError(t, err)
+
+	b, err := tr.Payload()
+	require.NoError(t, err)
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(b, &payload))
+	assert.Contains(t, payload, "version")
+	assert.NotContains(t, payload, "command_metrics")
+	assert.NotContains(t, payload, "build_environment")
+
+	opts.Exclude = []string{"uuid"}
+	_, err = NewReporter(opts)
+	assert.Error(t, err)
+}
+
+func TestReporterSpool(t *testing.T) {
+	t.Parallel()
+
+	var fail atomic.Bool
+	var received atomic.Int32
+
+	fail.Store(true)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		received.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	tr, err := NewReporter(&NewReporterOpts{
+		URL:         srv.URL,
+		Dir:         t.TempDir(),
+		F:           new(Flag),
+		ConnMetrics: connmetrics.NewListenerMetrics().ConnMetrics,
+		P:           sp,
+		L:           testutil.Logger(t),
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	tr.sendReport(ctx, tr.makeReport())
+	tr.sendReport(ctx, tr.makeReport())
+
+	files, err := tr.spool.list()
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, int32(0), received.Load())
+
+	fail.Store(false)
+
+	tr.sendReport(ctx, tr.makeReport())
+
+	files, err = tr.spool.list()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	assert.Equal(t, int32(3), received.Load())
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+)
+
+// maxSpooled is the maximum number of spooled reports.
+// The oldest reports are removed first.
+const maxSpooled = 30
+
+// spool stores reports that were not sent (for example, because the instance is offline)
+// in the state directory, so they could be sent later.
+type spool struct {
+	dir string
+}
+
+// newSpool creates a new spool in the given state directory.
+func newSpool(stateDir string) *spool {
+	return &spool{
+		dir: filepath.Join(stateDir, "telemetry-spool"),
+	}
+}
+
+// add stores the given report payload and removes the oldest reports if there are too many.
+func (s *spool) add(b []byte) error {
+	if err := os.MkdirAll(s.dir, 0o777); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	// zero-padded names are sorted chronologically
+	name := filepath.Join(s.dir, fmt.Sprintf("%020d.json", time.Now().UnixNano()))
+
+	// write and rename, so a partially written report is never sent
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o666); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err := os.Rename(tmp, name); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	files, err := s.list()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for len(files) > maxSpooled {
+		if err = os.Remove(files[0]); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		files = files[1:]
+	}
+
+	return nil
+}
+
+// list returns paths of spooled reports, the oldest first.
+func (s *spool) list() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	var res []string
+
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasSuffix(e.Name(), ".json") {
+			res = append(res, filepath.Join(s.dir, e.Name()))
+		}
+	}
+
+	slices.Sort(res)
+
+	return res, nil
+}
+
+// clear removes all spooled reports.
+func (s *spool) clear() error {
+	if err := os.RemoveAll(s.dir); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
@@ -19,6 +19,7 @@
 	"encoding"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 
 	"github.com/AlekSi/pointer"
@@ -55,6 +56,15 @@ func (s *Flag) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// String returns the flag value: "true", "false", or "undecided".
+func (s Flag) String() string {
+	if s.v == nil {
+		return "undecided"
+	}
+
+	return strconv.FormatBool(*s.v)
+}
+
 // initialState returns initial telemetry state based on:
 //   - Kong flag value (including `FERRETDB_TELEMETRY` environment variable);
 //   - common DO_NOT_TRACK environment variable;
@@ -129,4 +139,5 @@ func initialState(f *Flag, dnt string, execName string, prev *bool, l *slog.Logg
 // check interfaces
 var (
 	_ encoding.TextUnmarshaler = (*Flag)(nil)
+	_ fmt.Stringer             = Flag{}
 )
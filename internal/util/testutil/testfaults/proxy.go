@@ -0,0 +1,492 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testfaults provides a PostgreSQL proxy that injects faults for testing.
+//
+// It is in a separate package to avoid import cycles.
+package testfaults
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/testutil"
+)
+
+// Request codes of untyped messages that the proxy answers itself.
+const (
+	sslRequestCode    = 80877103
+	gssEncRequestCode = 80877104
+)
+
+// Stats represents the number of injected faults.
+type Stats struct {
+	Delayed int64 // requests delayed by latency
+	Dropped int64 // dropped connections
+	Failed  int64 // requests failed with an injected error
+}
+
+// Step is a single step of the fault schedule.
+type Step struct {
+	After time.Duration // since the start of the schedule
+	Do    func(p *Proxy)
+}
+
+// Proxy is a TCP proxy between FerretDB and PostgreSQL that injects faults.
+//
+// It understands just enough of the PostgreSQL protocol to find request boundaries:
+// a simple query or an extended query cycle ending with Sync.
+// Latency is added to each request; injected errors are returned to the client
+// without passing the request to PostgreSQL, as if PostgreSQL rejected it.
+// TLS and GSS encryption requests are declined.
+type Proxy struct {
+	l      *slog.Logger
+	lis    net.Listener
+	target string
+	url    string
+
+	latency atomic.Int64 // time.Duration
+
+	failM    sync.Mutex
+	failN    int
+	failCode string
+
+	delayed atomic.Int64
+	dropped atomic.Int64
+	failed  atomic.Int64
+
+	connsM sync.Mutex
+	conns  map[*proxyConn]struct{}
+
+	wg sync.WaitGroup
+}
+
+// New starts a new proxy for the given PostgreSQL URL.
+// It is stopped when the test ends.
+func New(tb testing.TB, postgreSQLURL string) *Proxy {
+	tb.Helper()
+
+	u, err := url.Parse(postgreSQLURL)
+	require.NoError(tb, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(tb, err)
+
+	p := &Proxy{
+		l:      testutil.Logger(tb).With(slog.String("name", "testfaults")),
+		lis:    lis,
+		target: u.Host,
+		conns:  map[*proxyConn]struct{}{},
+	}
+
+	u.Host = lis.Addr().String()
+	p.url = u.String()
+
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+		p.run()
+	}()
+
+	tb.Cleanup(p.close)
+
+	return p
+}
+
+// URL returns PostgreSQL URL that connects via the proxy.
+func (p *Proxy) URL() string {
+	return p.url
+}
+
+// SetLatency sets the delay added to each request.
+// Zero disables it.
+func (p *Proxy) SetLatency(d time.Duration) {
+	p.latency.Store(int64(d))
+}
+
+// FailNext makes the next n requests fail with the given SQLSTATE error code
+// (for example, "40001" for serialization failure).
+// Requests are not passed to PostgreSQL.
+func (p *Proxy) FailNext(n int, code string) {
+	p.failM.Lock()
+	defer p.failM.Unlock()
+
+	p.failN = n
+	p.failCode = code
+}
+
+// DropConnections closes all current connections.
+// It returns the number of closed connections.
+func (p *Proxy) DropConnections() int {
+	p.connsM.Lock()
+	defer p.connsM.Unlock()
+
+	for c := range p.conns {
+		c.close()
+	}
+
+	n := len(p.conns)
+	p.dropped.Add(int64(n))
+
+	return n
+}
+
+// Schedule runs steps at the given times in the background.
+// Steps that did not run before ctx is canceled are skipped.
+func (p *Proxy) Schedule(ctx context.Context, steps ...Step) {
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+
+		start := time.Now()
+
+		for _, s := range steps {
+			t := time.NewTimer(time.Until(start.Add(s.After)))
+
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+
+			s.Do(p)
+		}
+	}()
+}
+
+// Stats returns the number of injected faults.
+func (p *Proxy) Stats() Stats {
+	return Stats{
+		Delayed: p.delayed.Load(),
+		Dropped: p.dropped.Load(),
+		Failed:  p.failed.Load(),
+	}
+}
+
+// takeFailure returns the error code for the next request, if it should fail.
+func (p *Proxy) takeFailure() (string, bool) {
+	p.failM.Lock()
+	defer p.failM.Unlock()
+
+	if p.failN == 0 {
+		return "", false
+	}
+
+	p.failN--
+
+	return p.failCode, true
+}
+
+// run accepts connections until the listener is closed.
+func (p *Proxy) run() {
+	for {
+		client, err := p.lis.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				p.l.Error("Failed to accept connection", slog.String("error", err.Error()))
+			}
+
+			return
+		}
+
+		p.wg.Add(1)
+
+		go func() {
+			defer p.wg.Done()
+			p.serve(client)
+		}()
+	}
+}
+
+// serve proxies a single client connection.
+func (p *Proxy) serve(client net.Conn) {
+	server, err := net.Dial("tcp", p.target)
+	if err != nil {
+		p.l.Error("Failed to connect to PostgreSQL", slog.String("error", err.Error()))
+		_ = client.Close()
+
+		return
+	}
+
+	c := &proxyConn{
+		p:      p,
+		client: client,
+		server: server,
+	}
+
+	p.connsM.Lock()
+	p.conns[c] = struct{}{}
+	p.connsM.Unlock()
+
+	defer func() {
+		p.connsM.Lock()
+		delete(p.conns, c)
+		p.connsM.Unlock()
+
+		c.close()
+	}()
+
+	if err = c.startup(); err != nil {
+		p.l.Debug("Startup failed", slog.String("error", err.Error()))
+		return
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		if err := c.pumpServer(); err != nil {
+			p.l.Debug("Server connection closed", slog.String("error", err.Error()))
+		}
+
+		c.close()
+	}()
+
+	if err = c.pumpClient(); err != nil {
+		p.l.Debug("Client connection closed", slog.String("error", err.Error()))
+	}
+
+	c.close()
+	<-done
+}
+
+// close stops the proxy and closes all connections.
+func (p *Proxy) close() {
+	_ = p.lis.Close()
+
+	p.connsM.Lock()
+	for c := range p.conns {
+		c.close()
+	}
+	p.connsM.Unlock()
+
+	p.wg.Wait()
+}
+
+// proxyConn represents a pair of client and server connections.
+type proxyConn struct {
+	p      *Proxy
+	client net.Conn
+	server net.Conn
+
+	// protects writes to client
+	clientM sync.Mutex
+
+	// the last transaction status sent by the server; zero before the first ReadyForQuery
+	status atomic.Uint32
+}
+
+// close closes both connections.
+func (c *proxyConn) close() {
+	_ = c.client.Close()
+	_ = c.server.Close()
+}
+
+// startup handles untyped messages that start the connection:
+// encryption requests are declined, and the startup (or cancel) message is passed to the server.
+func (c *proxyConn) startup() error {
+	for {
+		msg, err := readUntyped(c.client)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		switch binary.BigEndian.Uint32(msg[4:8]) {
+		case sslRequestCode, gssEncRequestCode:
+			if _, err = c.client.Write([]byte{'N'}); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+		default:
+			if _, err = c.server.Write(msg); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// pumpClient passes client messages to the server, injecting latency and errors.
+func (c *proxyConn) pumpClient() error {
+	var inRequest, failing bool
+	var code string
+
+	for {
+		msg, err := readTyped(c.client)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		typ := msg[0]
+
+		// Sync and simple Query end requests;
+		// Terminate and COPY data are never failed
+		end := typ == 'S' || typ == 'Q'
+
+		if !inRequest && c.status.Load() != 0 && typ != 'X' && typ != 'd' && typ != 'c' && typ != 'f' {
+			code, failing = c.p.takeFailure()
+		}
+
+		inRequest = !end
+
+		if failing {
+			if !end {
+				continue
+			}
+
+			failing = false
+			c.p.failed.Add(1)
+
+			if err = c.writeClient(errorResponse(code, byte(c.status.Load()))); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			continue
+		}
+
+		if d := time.Duration(c.p.latency.Load()); end && d > 0 {
+			c.p.delayed.Add(1)
+			time.Sleep(d)
+		}
+
+		if _, err = c.server.Write(msg); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+}
+
+// pumpServer passes server messages to the client, tracking the transaction status.
+func (c *proxyConn) pumpServer() error {
+	for {
+		msg, err := readTyped(c.server)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if msg[0] == 'Z' && len(msg) == 6 {
+			c.status.Store(uint32(msg[5]))
+		}
+
+		if err = c.writeClient(msg); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+}
+
+// writeClient writes the given messages to the client.
+func (c *proxyConn) writeClient(b []byte) error {
+	c.clientM.Lock()
+	defer c.clientM.Unlock()
+
+	_, err := c.client.Write(b)
+
+	return err
+}
+
+// readUntyped reads a message without the type byte (startup, SSL request, etc.).
+func readUntyped(r io.Reader) ([]byte, error) {
+	var l [4]byte
+	if _, err := io.ReadFull(r, l[:]); err != nil {
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint32(l[:])
+	if n < 8 || n > 10000 {
+		return nil, lazyerrors.Errorf("invalid startup message length %d", n)
+	}
+
+	msg := make([]byte, n)
+	copy(msg, l[:])
+
+	if _, err := io.ReadFull(r, msg[4:]); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// readTyped reads a regular message with the type byte.
+func readTyped(r io.Reader) ([]byte, error) {
+	var h [5]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint32(h[1:])
+	if n < 4 {
+		return nil, lazyerrors.Errorf("invalid message length %d", n)
+	}
+
+	msg := make([]byte, 1+n)
+	copy(msg, h[:])
+
+	if _, err := io.ReadFull(r, msg[5:]); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// errorResponse returns ErrorResponse and ReadyForQuery messages for the given error code,
+// as PostgreSQL would send them for a failed request.
+func errorResponse(code string, status byte) []byte {
+	// a failed statement aborts the current transaction
+	if status == 'T' {
+		status = 'E'
+	}
+
+	var fields []byte
+	for _, f := range []struct {
+		t byte
+		v string
+	}{
+		{'S', "ERROR"},
+		{'V', "ERROR"},
+		{'C', code},
+		{'M', "injected fault " + code},
+	} {
+		fields = append(fields, f.t)
+		fields = append(fields, f.v...)
+		fields = append(fields, 0)
+	}
+
+	fields = append(fields, 0)
+
+	res := []byte{'E'}
+	res = binary.BigEndian.AppendUint32(res, uint32(4+len(fields)))
+	res = append(res, fields...)
+
+	res = append(res, 'Z')
+	res = binary.BigEndian.AppendUint32(res, 5)
+	res = append(res, status)
+
+	return res
+}
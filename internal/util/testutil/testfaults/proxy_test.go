@@ -0,0 +1,140 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testfaults
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer starts a minimal PostgreSQL server that answers simple queries.
+// It returns the server URL and the number of received queries.
+func fakeServer(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = lis.Close() })
+
+	var queries atomic.Int32
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close() //nolint:errcheck // fake server
+
+				b := pgproto3.NewBackend(conn, conn)
+
+				if _, err := b.ReceiveStartupMessage(); err != nil {
+					return
+				}
+
+				b.Send(&pgproto3.AuthenticationOk{})
+				b.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+
+				if err := b.Flush(); err != nil {
+					return
+				}
+
+				for {
+					msg, err := b.Receive()
+					if err != nil {
+						return
+					}
+
+					if _, ok := msg.(*pgproto3.Query); !ok {
+						return
+					}
+
+					queries.Add(1)
+
+					b.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")})
+					b.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+
+					if err := b.Flush(); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return "postgres://username:password@" + lis.Addr().String() + "/postgres", &queries
+}
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	u, queries := fakeServer(t)
+	p := New(t, u)
+
+	conn, err := pgx.Connect(ctx, p.URL()+"?default_query_exec_mode=simple_protocol")
+	require.NoError(t, err)
+
+	_, err = conn.Exec(ctx, "SELECT 1")
+	require.NoError(t, err)
+
+	p.FailNext(1, pgerrcode.SerializationFailure)
+
+	_, err = conn.Exec(ctx, "SELECT 1")
+
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr), "%v", err)
+	assert.Equal(t, pgerrcode.SerializationFailure, pgErr.Code)
+
+	_, err = conn.Exec(ctx, "SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), queries.Load(), "failed request should not reach the server")
+
+	p.SetLatency(100 * time.Millisecond)
+
+	start := time.Now()
+	_, err = conn.Exec(ctx, "SELECT 1")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	p.SetLatency(0)
+
+	done := make(chan struct{})
+	p.Schedule(ctx, Step{After: 10 * time.Millisecond, Do: func(p *Proxy) {
+		p.DropConnections()
+		close(done)
+	}})
+	<-done
+
+	_, err = conn.Exec(ctx, "SELECT 1")
+	require.Error(t, err)
+
+	assert.Equal(t, Stats{Delayed: 1, Dropped: 1, Failed: 1}, p.Stats())
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package update provides an opt-in checker for new FerretDB releases.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/FerretDB/FerretDB/v2/build/version"
+	"github.com/FerretDB/FerretDB/v2/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/v2/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/v2/internal/util/logging"
+	"github.com/FerretDB/FerretDB/v2/internal/util/state"
+)
+
+// release represents the subset of release metadata returned by the endpoint
+// (GitHub's "get the latest release" API response).
+type release struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Checker periodically fetches the latest release metadata
+// and updates the state's latest version and update availability.
+//
+// Unlike telemetry reporting, it does not send any data about the instance.
+type Checker struct {
+	*NewCheckerOpts
+	c *http.Client
+}
+
+// NewCheckerOpts represents checker options.
+type NewCheckerOpts struct {
+	URL      string
+	P        *state.Provider
+	L        *slog.Logger
+	Interval time.Duration
+}
+
+// NewChecker creates a new checker.
+func NewChecker(opts *NewCheckerOpts) (*Checker, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("URL is required")
+	}
+
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("interval should be positive")
+	}
+
+	return &Checker{
+		NewCheckerOpts: opts,
+		c:              http.DefaultClient,
+	}, nil
+}
+
+// Run runs checker until context is canceled.
+func (c *Checker) Run(ctx context.Context) {
+	c.L.DebugContext(ctx, "Checker started")
+	defer c.L.DebugContext(ctx, "Checker stopped")
+
+	for context.Cause(ctx) == nil {
+		if err := c.check(ctx); err != nil {
+			c.L.DebugContext(ctx, "Failed to check for updates", logging.Error(err))
+		}
+
+		ctxutil.Sleep(ctx, c.Interval)
+	}
+}
+
+// check fetches the latest release once and updates the state.
+func (c *Checker) check(ctx context.Context) error {
+	reqCtx, reqCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer reqCancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // safe to ignore
+
+	if resp.StatusCode != http.StatusOK {
+		return lazyerrors.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var rel release
+	if err = json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	latest, err := version.ParseSemVer(rel.TagName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	available, err := Available(version.Get().Version, latest)
+	if err != nil {
+		// for example, for development builds without version.txt
+		c.L.DebugContext(ctx, "Failed to compare versions", logging.Error(err))
+	}
+
+	if available {
+		c.L.InfoContext(
+			ctx, "A new version is available",
+			slog.String("current_version", version.Get().Version),
+			slog.String("latest_version", latest.String()),
+			slog.String("url", rel.HTMLURL),
+		)
+	}
+
+	err = c.P.Update(func(s *state.State) {
+		s.LatestVersion = latest.String()
+		s.UpdateAvailable = available
+	})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// Available returns true if the latest version is newer than the current one.
+// Pre-releases are never reported as available updates.
+func Available(current string, latest *version.SemVer) (bool, error) {
+	if latest.Prerelease != "" {
+		return false, nil
+	}
+
+	cur, err := version.ParseSemVer(current)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return cur.Compare(latest) < 0, nil
+}
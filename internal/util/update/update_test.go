@@ -0,0 +1,83 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/v2/build/version"
+	"github.com/FerretDB/FerretDB/v2/internal/util/state"
+	"github.com/FerretDB/FerretDB/v2/internal/util/testutil"
+)
+
+func TestAvailable(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		current  string
+		latest   string
+		expected bool
+	}{
+		"Newer":      {current: "v2.0.0", latest: "v2.1.0", expected: true},
+		"Same":       {current: "v2.1.0", latest: "v2.1.0"},
+		"Older":      {current: "v2.2.0-rc.1", latest: "v2.1.0"},
+		"Prerelease": {current: "v2.1.0", latest: "v2.2.0-rc.1"},
+		"FromRC":     {current: "v2.2.0-rc.1", latest: "v2.2.0", expected: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			latest, err := version.ParseSemVer(tc.latest)
+			require.NoError(t, err)
+
+			actual, err := Available(tc.current, latest)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	_, err := Available("unknown", &version.SemVer{Major: 2})
+	assert.Error(t, err)
+}
+
+func TestChecker(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name": "v99.0.0", "html_url": "https://example.com/"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	c, err := NewChecker(&NewCheckerOpts{
+		URL:      srv.URL,
+		P:        sp,
+		L:        testutil.Logger(t),
+		Interval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, c.check(context.Background()))
+	assert.Equal(t, "v99.0.0", sp.Get().LatestVersion)
+}